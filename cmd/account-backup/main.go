@@ -0,0 +1,76 @@
+// Package main provides a command that backs up an account to an archive and restores
+// archives into empty accounts. Use it for disaster recovery, or to clone a template
+// store into the account of a new franchise location.
+//
+// Usage:
+//
+//	go run ./cmd/account-backup backup -account 7 -file store7.tar.gz
+//	go run ./cmd/account-backup restore -account 12 -file store7.tar.gz -user 31
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/mnadev/pantryos/internal/database"
+)
+
+func main() {
+	if len(os.Args) < 2 || (os.Args[1] != "backup" && os.Args[1] != "restore") {
+		fmt.Fprintln(os.Stderr, "usage: account-backup backup|restore -account ID -file ARCHIVE [-user ID]")
+		os.Exit(2)
+	}
+	command := os.Args[1]
+
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	accountID := flags.Int("account", 0, "the account to back up, or the empty account to restore into")
+	filename := flags.String("file", "", "the archive to write or read")
+	userID := flags.Int("user", 0, "restore: the user recorded as the author of restored records (default: keep the archived users)")
+	flags.Parse(os.Args[2:])
+	if *accountID == 0 || *filename == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	db, err := database.Initialize()
+	if err != nil {
+		log.Fatalf("Could not initialize database: %v", err)
+	}
+	defer db.Close()
+
+	service := database.NewService(db)
+
+	if command == "backup" {
+		file, err := os.Create(*filename)
+		if err != nil {
+			log.Fatalf("Could not create archive: %v", err)
+		}
+		manifest, err := service.BackupAccount(*accountID, file)
+		if err == nil {
+			err = file.Close()
+		}
+		if err != nil {
+			os.Remove(*filename)
+			log.Fatalf("Could not back up account %d: %v", *accountID, err)
+		}
+		log.Printf("Backed up account %d (%s) to %s: %v", *accountID, manifest.Account.Name, *filename, manifest.Records)
+		return
+	}
+
+	file, err := os.Open(*filename)
+	if err != nil {
+		log.Fatalf("Could not open archive: %v", err)
+	}
+	defer file.Close()
+
+	report, err := service.RestoreAccount(*accountID, *userID, file)
+	if err != nil {
+		log.Fatalf("Could not restore into account %d: %v", *accountID, err)
+	}
+	log.Printf("Restored %s into account %d: %v", report.Manifest.Account.Name, *accountID, report.Restored)
+	if len(report.Skipped) > 0 {
+		log.Printf("Skipped records whose references were missing: %v", report.Skipped)
+	}
+}
@@ -0,0 +1,43 @@
+// Package main provides a command that rebuilds the maintained inventory levels
+// from inventory history. Run it after sales or other stock movements were written
+// to the database outside the API, or whenever current stock looks wrong.
+//
+// Usage:
+//
+//	go run ./cmd/rebuild-inventory-levels            # every account
+//	go run ./cmd/rebuild-inventory-levels -account 7 # a single account
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/mnadev/pantryos/internal/database"
+)
+
+func main() {
+	accountID := flag.Int("account", 0, "rebuild only this account (default: all accounts)")
+	flag.Parse()
+
+	db, err := database.Initialize()
+	if err != nil {
+		log.Fatalf("Could not initialize database: %v", err)
+	}
+	defer db.Close()
+
+	service := database.NewService(db)
+
+	if *accountID != 0 {
+		if err := service.RebuildInventoryLevels(*accountID); err != nil {
+			log.Fatalf("Could not rebuild inventory levels: %v", err)
+		}
+		log.Printf("Rebuilt inventory levels for account %d", *accountID)
+		return
+	}
+
+	rebuilt, err := service.RebuildAllInventoryLevels()
+	if err != nil {
+		log.Fatalf("Could not rebuild inventory levels: %v", err)
+	}
+	log.Printf("Rebuilt inventory levels for %d accounts", rebuilt)
+}
@@ -0,0 +1,293 @@
+// Package handlers provides HTTP request handlers for the application's API endpoints.
+// This package includes handlers for category purchasing budgets and budget-vs-actual reports.
+// All handlers require authentication and operate within the context of user accounts.
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	helpers "github.com/mnadev/pantryos/internal/api/helper"
+
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BudgetHandler handles HTTP requests related to category purchasing budgets.
+// It manages the weekly and monthly budgets of categories and compares them
+// with what was actually spent on the category's items.
+type BudgetHandler struct {
+	// service provides access to the business logic layer for database operations
+	service *database.Service
+}
+
+// BudgetRequest represents the request body for creating or updating a budget
+type BudgetRequest struct {
+	CategoryID      int       `json:"category_id" binding:"required"`
+	Period          string    `json:"period" binding:"required"` // "weekly" or "monthly"
+	Amount          float64   `json:"amount" binding:"required"`
+	SpendSource     string    `json:"spend_source"`     // "deliveries" (default) or "orders"
+	AlertThresholds []float64 `json:"alert_thresholds"` // Percentages; defaults to 80 and 100, empty disables alerts
+}
+
+// NewBudgetHandler creates a new BudgetHandler instance with the provided database connection.
+// This function initializes the handler with a database service that handles all
+// business logic and data access operations.
+//
+// Parameters:
+//   - db: The database connection to use for operations
+//
+// Returns:
+//   - *BudgetHandler: A new handler instance ready to handle HTTP requests
+func NewBudgetHandler(db *database.DB) *BudgetHandler {
+	return &BudgetHandler{service: database.NewService(db)}
+}
+
+// GetBudgets lists the budgets of the authenticated user's account.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Status Codes:
+//   - 200 OK: Budgets retrieved successfully.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *BudgetHandler) GetBudgets(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	budgets, err := h.service.GetBudgetsByAccount(user.AccountID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to fetch budgets.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the list of budgets in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Budgets retrieved successfully.", budgets)
+}
+
+// CreateBudget sets a weekly or monthly purchasing budget for a category.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: The category must belong to the user's account
+//
+// Request Body: JSON object
+//   - category_id: The category budgeted (int, required)
+//   - period: "weekly" or "monthly" (string, required)
+//   - amount: The budget for each period (float64, required)
+//   - spend_source: Measure spend from "deliveries" (default) or "orders" (string, optional)
+//   - alert_thresholds: Percentages of the budget that send an alert email
+//     (array, optional, defaults to [80, 100]; an empty array disables alerts)
+//
+// Status Codes:
+//   - 201 Created: Budget created successfully. The 'data' field contains the new budget.
+//   - 400 Bad Request: Invalid request body or budget data.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+func (h *BudgetHandler) CreateBudget(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	var req BudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+		return
+	}
+
+	budget := &models.Budget{AccountID: user.AccountID}
+	req.apply(budget)
+
+	if err := h.service.CreateBudget(budget); err != nil {
+		errDetails := helpers.APIError{Code: "VALIDATION_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to create budget.", errDetails)
+		return
+	}
+
+	// Return a 201 Created response with the new budget in the data field.
+	helpers.Success(c.Writer, http.StatusCreated, "Budget created successfully.", budget)
+}
+
+// UpdateBudget changes a budget's category, period, amount, spend source or alert thresholds.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: The budget must belong to the user's account
+//
+// Path Parameters:
+//   - id: The budget ID (int, required)
+//
+// Request Body: JSON object with the same fields as CreateBudget
+//
+// Status Codes:
+//   - 200 OK: Budget updated successfully. The 'data' field contains the updated budget.
+//   - 400 Bad Request: Invalid budget ID, request body or budget data.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: The budget does not exist or belongs to another account.
+func (h *BudgetHandler) UpdateBudget(c *gin.Context) {
+	_, budget, ok := h.getOwnedBudget(c)
+	if !ok {
+		return
+	}
+
+	var req BudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+		return
+	}
+	req.apply(budget)
+
+	if err := h.service.UpdateBudget(budget); err != nil {
+		errDetails := helpers.APIError{Code: "VALIDATION_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to update budget.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the updated budget in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Budget updated successfully.", budget)
+}
+
+// DeleteBudget deletes a budget together with the alerts it raised.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: The budget must belong to the user's account
+//
+// Path Parameters:
+//   - id: The budget ID (int, required)
+//
+// Status Codes:
+//   - 200 OK: Budget deleted successfully.
+//   - 400 Bad Request: Invalid budget ID.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: The budget does not exist or belongs to another account.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *BudgetHandler) DeleteBudget(c *gin.Context) {
+	_, budget, ok := h.getOwnedBudget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteBudget(budget.ID); err != nil {
+		errDetails := helpers.APIError{Code: "DB_DELETE_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to delete budget.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with no data.
+	helpers.Success(c.Writer, http.StatusOK, "Budget deleted successfully.", nil)
+}
+
+// GetBudgetReport compares each budget of the authenticated user's account with the
+// spend of its category in the week or month containing the given date.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Query Parameters:
+//   - date: A day of the periods to report, in YYYY-MM-DD format (optional, defaults to today)
+//
+// Status Codes:
+//   - 200 OK: Report generated successfully. The 'data' field contains the budget statuses.
+//   - 400 Bad Request: Invalid date.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *BudgetHandler) GetBudgetReport(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	date := time.Now()
+	if value := c.Query("date"); value != "" {
+		parsed, err := time.Parse(dateQueryLayout, value)
+		if err != nil {
+			errDetails := helpers.APIError{Code: "INVALID_DATE", Details: "date must be in YYYY-MM-DD format."}
+			helpers.Error(c.Writer, http.StatusBadRequest, "Invalid date.", errDetails)
+			return
+		}
+		date = parsed
+	}
+
+	report, err := h.service.GetBudgetReport(user.AccountID, date)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "REPORT_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to generate budget report.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the report in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Budget report generated successfully.", report)
+}
+
+// apply copies the request fields onto a budget. Omitted alert thresholds are
+// left nil so that the defaults apply, while an empty list disables alerts.
+func (req BudgetRequest) apply(budget *models.Budget) {
+	budget.CategoryID = req.CategoryID
+	budget.Period = req.Period
+	budget.Amount = req.Amount
+	budget.SpendSource = req.SpendSource
+	budget.AlertThresholds = nil
+	if req.AlertThresholds != nil {
+		budget.AlertThresholds = append(models.ThresholdList{}, req.AlertThresholds...)
+	}
+}
+
+// getUser resolves the authenticated user, writing the error response and
+// returning false when the user is not authenticated or does not exist.
+func (h *BudgetHandler) getUser(c *gin.Context) (*models.User, bool) {
+	// Extract user ID from context (set by AuthMiddleware)
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		errDetails := helpers.APIError{Code: "UNAUTHORIZED", Details: "User ID not found in request context."}
+		helpers.Error(c.Writer, http.StatusUnauthorized, "User not authenticated.", errDetails)
+		return nil, false
+	}
+	userID := userIDInterface.(int)
+
+	// Retrieve user details from database to get the AccountID
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "USER_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "User not found.", errDetails)
+		return nil, false
+	}
+	return user, true
+}
+
+// getOwnedBudget resolves the authenticated user and the budget named by the ":id"
+// URL parameter, writing the error response and returning false when the budget
+// does not exist or belongs to another account.
+func (h *BudgetHandler) getOwnedBudget(c *gin.Context) (*models.User, *models.Budget, bool) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return nil, nil, false
+	}
+
+	// Parse and validate the budget ID from the URL parameter
+	budgetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: "Budget ID must be a valid integer."}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid Budget ID.", errDetails)
+		return nil, nil, false
+	}
+
+	budget, err := h.service.GetBudget(budgetID)
+	if err != nil || budget.AccountID != user.AccountID {
+		errDetails := helpers.APIError{Code: "BUDGET_NOT_FOUND", Details: fmt.Sprintf("Budget with ID %d not found.", budgetID)}
+		helpers.Error(c.Writer, http.StatusNotFound, "Budget not found.", errDetails)
+		return nil, nil, false
+	}
+
+	return user, budget, true
+}
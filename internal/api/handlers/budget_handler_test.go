@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupBudgetTestHandler(t *testing.T) (*gin.Engine, *database.Service, *models.User, func()) {
+	router, service, user, cleanup := setupAuthenticatedTestRouter(t, "budgets@example.com")
+	handler := NewBudgetHandler(service.DB())
+
+	api := router.Group("/api/v1")
+	api.GET("/budgets", handler.GetBudgets)
+	api.POST("/budgets", handler.CreateBudget)
+	api.PUT("/budgets/:id", handler.UpdateBudget)
+	api.DELETE("/budgets/:id", handler.DeleteBudget)
+	api.GET("/reports/budgets", handler.GetBudgetReport)
+
+	return router, service, user, cleanup
+}
+
+func TestBudgetHandler(t *testing.T) {
+	router, service, user, cleanup := setupBudgetTestHandler(t)
+	defer cleanup()
+
+	dairy := &models.Category{AccountID: user.AccountID, Name: "Dairy"}
+	require.NoError(t, service.CreateCategory(dairy))
+	milk := &models.InventoryItem{AccountID: user.AccountID, Name: "Milk", Unit: "liters", CategoryID: &dairy.ID}
+	require.NoError(t, service.CreateInventoryItem(milk))
+	require.NoError(t, service.CreateDelivery(&models.Delivery{AccountID: user.AccountID, InventoryItemID: milk.ID, Vendor: "Local Dairy", Quantity: 40, Cost: 90, DeliveryDate: time.Date(2026, time.March, 10, 9, 0, 0, 0, time.UTC)}))
+
+	var budgetID int
+	t.Run("Create Budget", func(t *testing.T) {
+		body := map[string]interface{}{"category_id": dairy.ID, "period": "monthly", "amount": 120}
+		req, w := createAuthenticatedRequest("POST", "/api/v1/budgets", body, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		budget := response["data"].(map[string]interface{})
+		assert.Equal(t, "deliveries", budget["spend_source"])
+		assert.Equal(t, []interface{}{80.0, 100.0}, budget["alert_thresholds"])
+		budgetID = int(budget["id"].(float64))
+
+		// A category has one monthly budget
+		req, w = createAuthenticatedRequest("POST", "/api/v1/budgets", body, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		req, w = createAuthenticatedRequest("POST", "/api/v1/budgets", map[string]interface{}{"category_id": dairy.ID, "period": "yearly", "amount": 120}, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Budget Report", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/reports/budgets?date=2026-03-20", nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		report := response["data"].([]interface{})
+		require.Len(t, report, 1)
+		status := report[0].(map[string]interface{})
+		assert.Equal(t, "Dairy", status["category_name"])
+		assert.InDelta(t, 90, status["spend"], 0.001)
+		assert.InDelta(t, 30, status["remaining"], 0.001)
+		assert.InDelta(t, 75, status["percent_used"], 0.001)
+		assert.Empty(t, status["crossed_thresholds"])
+
+		req, w = createAuthenticatedRequest("GET", "/api/v1/reports/budgets?date=20-03-2026", nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Update Budget", func(t *testing.T) {
+		body := map[string]interface{}{"category_id": dairy.ID, "period": "monthly", "amount": 100, "alert_thresholds": []float64{50}}
+		req, w := createAuthenticatedRequest("PUT", fmt.Sprintf("/api/v1/budgets/%d", budgetID), body, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		req, w = createAuthenticatedRequest("GET", "/api/v1/reports/budgets?date=2026-03-20", nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		status := response["data"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, []interface{}{50.0}, status["crossed_thresholds"])
+	})
+
+	t.Run("Budget Of Another Account", func(t *testing.T) {
+		other := &models.Account{Name: "Other Shop", Status: "active"}
+		require.NoError(t, service.CreateAccount(other))
+		produce := &models.Category{AccountID: other.ID, Name: "Produce"}
+		require.NoError(t, service.CreateCategory(produce))
+		otherBudget := &models.Budget{AccountID: other.ID, CategoryID: produce.ID, Period: models.BudgetPeriodWeekly, Amount: 50}
+		require.NoError(t, service.CreateBudget(otherBudget))
+
+		req, w := createAuthenticatedRequest("DELETE", fmt.Sprintf("/api/v1/budgets/%d", otherBudget.ID), nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		// Budgets can only be set on the account's own categories
+		body := map[string]interface{}{"category_id": produce.ID, "period": "weekly", "amount": 50}
+		req, w = createAuthenticatedRequest("POST", "/api/v1/budgets", body, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Delete Budget", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("DELETE", fmt.Sprintf("/api/v1/budgets/%d", budgetID), nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		req, w = createAuthenticatedRequest("GET", "/api/v1/budgets", nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Empty(t, response["data"])
+	})
+}
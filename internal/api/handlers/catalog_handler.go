@@ -0,0 +1,658 @@
+// Package handlers provides HTTP request handlers for the application's API endpoints.
+// This package includes handlers for organization master catalogs and catalog sync.
+// All handlers require authentication and operate within the context of user accounts.
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	helpers "github.com/mnadev/pantryos/internal/api/helper"
+
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CatalogHandler handles HTTP requests related to an organization's master catalog.
+// Organization admins maintain the catalog of categories, inventory items, and menu
+// items; accounts of the organization subscribe to it and receive its changes.
+type CatalogHandler struct {
+	// service provides access to the business logic layer for database operations
+	service *database.Service
+}
+
+// NewCatalogHandler creates a new CatalogHandler instance with the provided database connection.
+//
+// Parameters:
+//   - db: The database connection to use for operations
+//
+// Returns:
+//   - *CatalogHandler: A new handler instance ready to handle HTTP requests
+func NewCatalogHandler(db *database.DB) *CatalogHandler {
+	return &CatalogHandler{service: database.NewService(db)}
+}
+
+// GetCatalogCategories retrieves the categories of the user's organization catalog.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User's account must belong to an organization
+//
+// Status Codes:
+//   - 200 OK: Categories retrieved successfully. The 'data' field contains a list of categories.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The user's account does not belong to an organization.
+//   - 404 Not Found: User associated with token not found in the database.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *CatalogHandler) GetCatalogCategories(c *gin.Context) {
+	_, organizationID, ok := h.getOrganizationUser(c)
+	if !ok {
+		return
+	}
+
+	categories, err := h.service.GetCatalogCategories(organizationID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to fetch catalog categories.", errDetails)
+		return
+	}
+
+	helpers.Success(c.Writer, http.StatusOK, "Catalog categories retrieved successfully.", categories)
+}
+
+// CreateCatalogCategory adds a category to the organization catalog and pushes it to subscribed accounts.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be an organization admin
+//
+// Request Body: JSON object with category details
+//   - name: Category name, unique within the catalog (string, required)
+//   - description, color: Display details (string, optional)
+//
+// Status Codes:
+//   - 201 Created: Category created successfully. The 'data' field contains the new category.
+//   - 400 Bad Request: Invalid request body or validation error.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The user is not an organization admin.
+//   - 404 Not Found: User associated with token not found in the database.
+func (h *CatalogHandler) CreateCatalogCategory(c *gin.Context) {
+	organizationID, ok := h.requireCatalogAdmin(c)
+	if !ok {
+		return
+	}
+
+	var category models.CatalogCategory
+	if err := c.ShouldBindJSON(&category); err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+		return
+	}
+
+	category.ID = 0
+	category.OrganizationID = organizationID
+	category.IsActive = true
+
+	if err := h.service.CreateCatalogCategory(&category); err != nil {
+		errDetails := helpers.APIError{Code: "VALIDATION_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to create catalog category.", errDetails)
+		return
+	}
+
+	helpers.Success(c.Writer, http.StatusCreated, "Catalog category created successfully.", category)
+}
+
+// UpdateCatalogCategory updates a catalog category and pushes the change to subscribed accounts.
+// Setting is_active to false deactivates the linked local categories.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be an organization admin
+//
+// URL Parameters:
+//   - id: The catalog category ID to update (integer)
+//
+// Status Codes:
+//   - 200 OK: Category updated successfully. The 'data' field contains the updated category.
+//   - 400 Bad Request: Invalid ID, request body, or validation error.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The user is not an organization admin or the category belongs to another organization.
+//   - 404 Not Found: The user or the category could not be found.
+func (h *CatalogHandler) UpdateCatalogCategory(c *gin.Context) {
+	organizationID, ok := h.requireCatalogAdmin(c)
+	if !ok {
+		return
+	}
+	id, ok := parseCatalogID(c)
+	if !ok {
+		return
+	}
+
+	existing, err := h.service.GetCatalogCategory(id)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "CATEGORY_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "Catalog category not found.", errDetails)
+		return
+	}
+	if !h.ownsCatalogEntry(c, existing.OrganizationID, organizationID) {
+		return
+	}
+
+	var category models.CatalogCategory
+	if err := c.ShouldBindJSON(&category); err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+		return
+	}
+
+	// Preserve the original ID, OrganizationID and creation time to prevent them from being changed.
+	category.ID = existing.ID
+	category.OrganizationID = organizationID
+	category.CreatedAt = existing.CreatedAt
+
+	if err := h.service.UpdateCatalogCategory(&category); err != nil {
+		errDetails := helpers.APIError{Code: "VALIDATION_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to update catalog category.", errDetails)
+		return
+	}
+
+	helpers.Success(c.Writer, http.StatusOK, "Catalog category updated successfully.", category)
+}
+
+// GetCatalogItems retrieves the inventory items of the user's organization catalog.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User's account must belong to an organization
+//
+// Status Codes:
+//   - 200 OK: Items retrieved successfully. The 'data' field contains a list of catalog items.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The user's account does not belong to an organization.
+//   - 404 Not Found: User associated with token not found in the database.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *CatalogHandler) GetCatalogItems(c *gin.Context) {
+	_, organizationID, ok := h.getOrganizationUser(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.service.GetCatalogItems(organizationID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to fetch catalog items.", errDetails)
+		return
+	}
+
+	helpers.Success(c.Writer, http.StatusOK, "Catalog items retrieved successfully.", items)
+}
+
+// CreateCatalogItem adds an inventory item to the organization catalog and pushes it to subscribed accounts.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be an organization admin
+//
+// Request Body: JSON object with item details
+//   - name, unit: Item name and counting unit (string, required)
+//   - catalog_category_id: Catalog category (int, optional)
+//   - cost_per_unit, preferred_vendor: Defaults for new local items; accounts keep their own values (optional)
+//   - min_stock_level, max_stock_level, shelf_life_days: Stock settings synced to every account (optional)
+//
+// Status Codes:
+//   - 201 Created: Item created successfully. The 'data' field contains the new catalog item.
+//   - 400 Bad Request: Invalid request body or validation error.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The user is not an organization admin.
+//   - 404 Not Found: User associated with token not found in the database.
+func (h *CatalogHandler) CreateCatalogItem(c *gin.Context) {
+	organizationID, ok := h.requireCatalogAdmin(c)
+	if !ok {
+		return
+	}
+
+	var item models.CatalogItem
+	if err := c.ShouldBindJSON(&item); err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+		return
+	}
+
+	item.ID = 0
+	item.OrganizationID = organizationID
+	item.IsActive = true
+
+	if err := h.service.CreateCatalogItem(&item); err != nil {
+		errDetails := helpers.APIError{Code: "VALIDATION_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to create catalog item.", errDetails)
+		return
+	}
+
+	helpers.Success(c.Writer, http.StatusCreated, "Catalog item created successfully.", item)
+}
+
+// UpdateCatalogItem updates a catalog item and pushes the change to subscribed accounts.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be an organization admin
+//
+// URL Parameters:
+//   - id: The catalog item ID to update (integer)
+//
+// Status Codes:
+//   - 200 OK: Item updated successfully. The 'data' field contains the updated catalog item.
+//   - 400 Bad Request: Invalid ID, request body, or validation error.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The user is not an organization admin or the item belongs to another organization.
+//   - 404 Not Found: The user or the item could not be found.
+func (h *CatalogHandler) UpdateCatalogItem(c *gin.Context) {
+	organizationID, ok := h.requireCatalogAdmin(c)
+	if !ok {
+		return
+	}
+	id, ok := parseCatalogID(c)
+	if !ok {
+		return
+	}
+
+	existing, err := h.service.GetCatalogItem(id)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "ITEM_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "Catalog item not found.", errDetails)
+		return
+	}
+	if !h.ownsCatalogEntry(c, existing.OrganizationID, organizationID) {
+		return
+	}
+
+	var item models.CatalogItem
+	if err := c.ShouldBindJSON(&item); err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+		return
+	}
+
+	// Preserve the original ID, OrganizationID and creation time to prevent them from being changed.
+	item.ID = existing.ID
+	item.OrganizationID = organizationID
+	item.CreatedAt = existing.CreatedAt
+
+	if err := h.service.UpdateCatalogItem(&item); err != nil {
+		errDetails := helpers.APIError{Code: "VALIDATION_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to update catalog item.", errDetails)
+		return
+	}
+
+	helpers.Success(c.Writer, http.StatusOK, "Catalog item updated successfully.", item)
+}
+
+// GetCatalogMenuItems retrieves the menu items of the user's organization catalog with their recipes.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User's account must belong to an organization
+//
+// Status Codes:
+//   - 200 OK: Menu items retrieved successfully. The 'data' field contains a list of catalog menu items.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The user's account does not belong to an organization.
+//   - 404 Not Found: User associated with token not found in the database.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *CatalogHandler) GetCatalogMenuItems(c *gin.Context) {
+	_, organizationID, ok := h.getOrganizationUser(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.service.GetCatalogMenuItems(organizationID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to fetch catalog menu items.", errDetails)
+		return
+	}
+
+	helpers.Success(c.Writer, http.StatusOK, "Catalog menu items retrieved successfully.", items)
+}
+
+// CreateCatalogMenuItem adds a menu item with its recipe to the organization catalog
+// and pushes it to subscribed accounts.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be an organization admin
+//
+// Request Body: JSON object with menu item details
+//   - name: Menu item name (string, required)
+//   - price: Menu price (float64, optional)
+//   - catalog_category_id: Catalog category (int, optional)
+//   - ingredients: Recipe as a list of { "catalog_item_id": int, "quantity": float64 } (optional)
+//
+// Status Codes:
+//   - 201 Created: Menu item created successfully. The 'data' field contains the new menu item and recipe.
+//   - 400 Bad Request: Invalid request body or validation error.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The user is not an organization admin.
+//   - 404 Not Found: User associated with token not found in the database.
+func (h *CatalogHandler) CreateCatalogMenuItem(c *gin.Context) {
+	organizationID, ok := h.requireCatalogAdmin(c)
+	if !ok {
+		return
+	}
+
+	var item database.CatalogMenuItemWithRecipe
+	if err := c.ShouldBindJSON(&item); err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+		return
+	}
+
+	item.ID = 0
+	item.OrganizationID = organizationID
+	item.IsActive = true
+
+	if err := h.service.CreateCatalogMenuItem(&item); err != nil {
+		errDetails := helpers.APIError{Code: "VALIDATION_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to create catalog menu item.", errDetails)
+		return
+	}
+
+	helpers.Success(c.Writer, http.StatusCreated, "Catalog menu item created successfully.", item)
+}
+
+// GetCatalogMenuItem retrieves a catalog menu item with its recipe.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User's account must belong to the menu item's organization
+//
+// URL Parameters:
+//   - id: The catalog menu item ID to retrieve (integer)
+//
+// Status Codes:
+//   - 200 OK: Menu item retrieved successfully. The 'data' field contains the menu item and recipe.
+//   - 400 Bad Request: Invalid ID format.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The menu item belongs to another organization.
+//   - 404 Not Found: The user or the menu item could not be found.
+func (h *CatalogHandler) GetCatalogMenuItem(c *gin.Context) {
+	_, organizationID, ok := h.getOrganizationUser(c)
+	if !ok {
+		return
+	}
+	id, ok := parseCatalogID(c)
+	if !ok {
+		return
+	}
+
+	item, err := h.service.GetCatalogMenuItem(id)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "MENU_ITEM_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "Catalog menu item not found.", errDetails)
+		return
+	}
+	if !h.ownsCatalogEntry(c, item.OrganizationID, organizationID) {
+		return
+	}
+
+	helpers.Success(c.Writer, http.StatusOK, "Catalog menu item retrieved successfully.", item)
+}
+
+// UpdateCatalogMenuItem updates a catalog menu item, replacing its recipe, and pushes
+// the change to subscribed accounts.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be an organization admin
+//
+// URL Parameters:
+//   - id: The catalog menu item ID to update (integer)
+//
+// Status Codes:
+//   - 200 OK: Menu item updated successfully. The 'data' field contains the updated menu item and recipe.
+//   - 400 Bad Request: Invalid ID, request body, or validation error.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The user is not an organization admin or the menu item belongs to another organization.
+//   - 404 Not Found: The user or the menu item could not be found.
+func (h *CatalogHandler) UpdateCatalogMenuItem(c *gin.Context) {
+	organizationID, ok := h.requireCatalogAdmin(c)
+	if !ok {
+		return
+	}
+	id, ok := parseCatalogID(c)
+	if !ok {
+		return
+	}
+
+	existing, err := h.service.GetCatalogMenuItem(id)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "MENU_ITEM_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "Catalog menu item not found.", errDetails)
+		return
+	}
+	if !h.ownsCatalogEntry(c, existing.OrganizationID, organizationID) {
+		return
+	}
+
+	var item database.CatalogMenuItemWithRecipe
+	if err := c.ShouldBindJSON(&item); err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+		return
+	}
+
+	// Preserve the original ID, OrganizationID and creation time to prevent them from being changed.
+	item.ID = existing.ID
+	item.OrganizationID = organizationID
+	item.CreatedAt = existing.CreatedAt
+
+	if err := h.service.UpdateCatalogMenuItem(&item); err != nil {
+		errDetails := helpers.APIError{Code: "VALIDATION_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to update catalog menu item.", errDetails)
+		return
+	}
+
+	helpers.Success(c.Writer, http.StatusOK, "Catalog menu item updated successfully.", item)
+}
+
+// GetCatalogSubscription retrieves the catalog subscription of the user's account.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Status Codes:
+//   - 200 OK: Subscription retrieved successfully. The 'data' field contains the subscription.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: The user could not be found or the account never subscribed.
+func (h *CatalogHandler) GetCatalogSubscription(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.service.GetCatalogSubscription(user.AccountID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "SUBSCRIPTION_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "Catalog subscription not found.", errDetails)
+		return
+	}
+
+	helpers.Success(c.Writer, http.StatusOK, "Catalog subscription retrieved successfully.", subscription)
+}
+
+// SubscribeToCatalog subscribes the user's account to its organization catalog and runs the first sync.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User's account must belong to an organization
+//
+// Status Codes:
+//   - 200 OK: Subscribed successfully. The 'data' field contains the sync result with any conflicts.
+//   - 400 Bad Request: The account cannot subscribe or the sync failed.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+func (h *CatalogHandler) SubscribeToCatalog(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.SubscribeToCatalog(user.AccountID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "SUBSCRIBE_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to subscribe to catalog.", errDetails)
+		return
+	}
+
+	helpers.Success(c.Writer, http.StatusOK, "Subscribed to catalog successfully.", result)
+}
+
+// UnsubscribeFromCatalog stops catalog updates for the user's account.
+// Local copies of catalog entries are kept.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Status Codes:
+//   - 200 OK: Unsubscribed successfully.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: The user could not be found or the account never subscribed.
+func (h *CatalogHandler) UnsubscribeFromCatalog(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.UnsubscribeFromCatalog(user.AccountID); err != nil {
+		errDetails := helpers.APIError{Code: "SUBSCRIPTION_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "Failed to unsubscribe from catalog.", errDetails)
+		return
+	}
+
+	helpers.Success(c.Writer, http.StatusOK, "Unsubscribed from catalog successfully.", nil)
+}
+
+// SyncCatalog syncs the organization catalog into the user's account on demand.
+// Catalog changes are pushed automatically; this re-runs the sync after local
+// conflicts have been fixed.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User's account must be subscribed to its organization catalog
+//
+// Status Codes:
+//   - 200 OK: Sync completed. The 'data' field contains the sync result with any conflicts.
+//   - 400 Bad Request: The account is not subscribed or the sync failed.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+func (h *CatalogHandler) SyncCatalog(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.SyncCatalog(user.AccountID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "SYNC_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to sync catalog.", errDetails)
+		return
+	}
+
+	helpers.Success(c.Writer, http.StatusOK, "Catalog synced successfully.", result)
+}
+
+// GetCatalogSyncConflicts retrieves the conflicts found by the latest catalog sync of the user's account.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Status Codes:
+//   - 200 OK: Conflicts retrieved successfully. The 'data' field contains a list of conflicts.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *CatalogHandler) GetCatalogSyncConflicts(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	conflicts, err := h.service.GetCatalogSyncConflicts(user.AccountID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to fetch catalog sync conflicts.", errDetails)
+		return
+	}
+
+	helpers.Success(c.Writer, http.StatusOK, "Catalog sync conflicts retrieved successfully.", conflicts)
+}
+
+// getUser resolves the authenticated user, writing the error response and
+// returning false when the user is not authenticated or does not exist.
+func (h *CatalogHandler) getUser(c *gin.Context) (*models.User, bool) {
+	// Extract user ID from context (set by AuthMiddleware)
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		errDetails := helpers.APIError{Code: "UNAUTHORIZED", Details: "User ID not found in request context."}
+		helpers.Error(c.Writer, http.StatusUnauthorized, "User not authenticated.", errDetails)
+		return nil, false
+	}
+	userID := userIDInterface.(int)
+
+	// Retrieve user details from database to get the AccountID
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "USER_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "User not found.", errDetails)
+		return nil, false
+	}
+	return user, true
+}
+
+// getOrganizationUser resolves the authenticated user and the organization of the
+// user's account, writing the error response and returning false when the account
+// does not belong to an organization.
+func (h *CatalogHandler) getOrganizationUser(c *gin.Context) (*models.User, int, bool) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return nil, 0, false
+	}
+
+	account, err := h.service.GetAccount(user.AccountID)
+	if err != nil || account.OrganizationID == nil {
+		errDetails := helpers.APIError{Code: "NO_ORGANIZATION", Details: "The user's account does not belong to an organization."}
+		helpers.Error(c.Writer, http.StatusForbidden, "Access denied.", errDetails)
+		return nil, 0, false
+	}
+	return user, *account.OrganizationID, true
+}
+
+// requireCatalogAdmin resolves the organization of an organization admin, writing the
+// error response and returning false for any other user.
+func (h *CatalogHandler) requireCatalogAdmin(c *gin.Context) (int, bool) {
+	user, organizationID, ok := h.getOrganizationUser(c)
+	if !ok {
+		return 0, false
+	}
+
+	isAdmin, err := h.service.IsOrganizationAdmin(user.ID)
+	if err != nil || !isAdmin {
+		errDetails := helpers.APIError{Code: "FORBIDDEN", Details: "Only organization admins can change the catalog."}
+		helpers.Error(c.Writer, http.StatusForbidden, "Access denied.", errDetails)
+		return 0, false
+	}
+	return organizationID, true
+}
+
+// ownsCatalogEntry writes a forbidden response and returns false when a catalog
+// entry belongs to another organization.
+func (h *CatalogHandler) ownsCatalogEntry(c *gin.Context, entryOrganizationID, organizationID int) bool {
+	if entryOrganizationID != organizationID {
+		errDetails := helpers.APIError{Code: "FORBIDDEN", Details: "You do not have permission to access this catalog entry."}
+		helpers.Error(c.Writer, http.StatusForbidden, "Access denied.", errDetails)
+		return false
+	}
+	return true
+}
+
+// parseCatalogID reads the ":id" URL parameter, writing the error response and
+// returning false when it is not an integer.
+func parseCatalogID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: "Catalog ID must be a valid integer."}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid catalog ID.", errDetails)
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupCatalogTestHandler(t *testing.T) (*gin.Engine, *database.Service, *models.User, *models.User, func()) {
+	router, service, admin, cleanup := setupAuthenticatedTestRouter(t, "catalog0@example.com")
+	admin.Role = "org_admin"
+	require.NoError(t, service.UpdateUser(admin))
+	member := addTestStore(t, service, admin, "catalog1@example.com")
+	handler := NewCatalogHandler(service.DB())
+
+	api := router.Group("/api/v1")
+	api.GET("/catalog/categories", handler.GetCatalogCategories)
+	api.POST("/catalog/categories", handler.CreateCatalogCategory)
+	api.PUT("/catalog/categories/:id", handler.UpdateCatalogCategory)
+	api.GET("/catalog/items", handler.GetCatalogItems)
+	api.POST("/catalog/items", handler.CreateCatalogItem)
+	api.PUT("/catalog/items/:id", handler.UpdateCatalogItem)
+	api.GET("/catalog/menu-items", handler.GetCatalogMenuItems)
+	api.POST("/catalog/menu-items", handler.CreateCatalogMenuItem)
+	api.GET("/catalog/menu-items/:id", handler.GetCatalogMenuItem)
+	api.PUT("/catalog/menu-items/:id", handler.UpdateCatalogMenuItem)
+	api.GET("/catalog/subscription", handler.GetCatalogSubscription)
+	api.POST("/catalog/subscription", handler.SubscribeToCatalog)
+	api.DELETE("/catalog/subscription", handler.UnsubscribeFromCatalog)
+	api.POST("/catalog/sync", handler.SyncCatalog)
+	api.GET("/catalog/conflicts", handler.GetCatalogSyncConflicts)
+
+	return router, service, admin, member, cleanup
+}
+
+func TestCatalogHandler_ManageAndSubscribe(t *testing.T) {
+	router, service, admin, member, cleanup := setupCatalogTestHandler(t)
+	defer cleanup()
+
+	var itemID int
+
+	t.Run("Only Organization Admins Edit The Catalog", func(t *testing.T) {
+		itemData := map[string]interface{}{"name": "Flour", "unit": "kg", "cost_per_unit": 3, "preferred_vendor": "Mill Co"}
+		req, w := createAuthenticatedRequest("POST", "/api/v1/catalog/items", itemData, member.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		req, w = createAuthenticatedRequest("POST", "/api/v1/catalog/items", itemData, admin.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		itemID = int(response["data"].(map[string]interface{})["id"].(float64))
+
+		req, w = createAuthenticatedRequest("GET", "/api/v1/catalog/items", nil, member.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response["data"].([]interface{}), 1)
+	})
+
+	t.Run("Create Menu Item With Recipe", func(t *testing.T) {
+		menuData := map[string]interface{}{
+			"name":        "Bread",
+			"price":       4,
+			"ingredients": []map[string]interface{}{{"catalog_item_id": itemID, "quantity": 0.5}},
+		}
+		req, w := createAuthenticatedRequest("POST", "/api/v1/catalog/menu-items", menuData, admin.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		id := int(response["data"].(map[string]interface{})["id"].(float64))
+
+		req, w = createAuthenticatedRequest("GET", fmt.Sprintf("/api/v1/catalog/menu-items/%d", id), nil, member.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response["data"].(map[string]interface{})["ingredients"], 1)
+	})
+
+	t.Run("Subscribe And Receive Pushed Updates", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("POST", "/api/v1/catalog/subscription", nil, member.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 2.0, response["data"].(map[string]interface{})["created"])
+
+		items, err := service.GetInventoryItemsByAccount(member.AccountID)
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		items[0].CostPerUnit = 3.4
+		require.NoError(t, service.UpdateInventoryItem(&items[0]))
+
+		updateData := map[string]interface{}{"name": "Bread Flour", "unit": "kg", "cost_per_unit": 5, "is_active": true}
+		req, w = createAuthenticatedRequest("PUT", fmt.Sprintf("/api/v1/catalog/items/%d", itemID), updateData, admin.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		synced, err := service.GetInventoryItem(items[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Bread Flour", synced.Name)
+		assert.Equal(t, 3.4, synced.CostPerUnit, "the local cost is kept")
+	})
+
+	t.Run("Report Conflicts", func(t *testing.T) {
+		local := &models.InventoryItem{AccountID: member.AccountID, Name: "Sugar", Unit: "lb"}
+		require.NoError(t, service.CreateInventoryItem(local))
+
+		itemData := map[string]interface{}{"name": "Sugar", "unit": "kg"}
+		req, w := createAuthenticatedRequest("POST", "/api/v1/catalog/items", itemData, admin.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		req, w = createAuthenticatedRequest("GET", "/api/v1/catalog/conflicts", nil, member.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		conflicts := response["data"].([]interface{})
+		require.Len(t, conflicts, 1)
+		assert.Equal(t, "unit", conflicts[0].(map[string]interface{})["field"])
+	})
+
+	t.Run("Unsubscribe", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("DELETE", "/api/v1/catalog/subscription", nil, member.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		req, w = createAuthenticatedRequest("POST", "/api/v1/catalog/sync", nil, member.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
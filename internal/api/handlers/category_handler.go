@@ -35,21 +35,27 @@ func NewCategoryHandler(db *database.DB) *CategoryHandler {
 	return &CategoryHandler{service: database.NewService(db)}
 }
 
-// GetCategories retrieves all categories for the authenticated user's account.
+// GetCategories retrieves a page of categories for the authenticated user's account.
 // This endpoint requires authentication and returns categories scoped to the user's account.
-// The response includes all categories with their details and status.
+// The response includes active and inactive categories with their details and status.
 //
 // Authentication: Required (JWT token in Authorization header)
 // Authorization: User must be authenticated and have access to the account
 //
+// Query Parameters:
+//   - limit, offset: Page size and number of categories to skip (optional; pages hold 100 categories by default)
+//   - sort: name, created_at or id, prefixed with "-" for descending (optional)
+//   - search: Case-insensitive name search (optional)
+//
 // Response:
 //
 //	All responses are wrapped in the standard APIResponse structure.
-//	- Success: { "success": true, "message": "...", "data": [...] }
+//	- Success: { "success": true, "message": "...", "data": [...], "pagination": { "limit": ..., "offset": ..., "total": ..., "has_more": ... } }
 //	- Error:   { "success": false, "message": "...", "error": { "code": "...", "details": "..." } }
 //
 // Status Codes:
-//   - 200 OK: Categories retrieved successfully. The 'data' field contains a list of categories.
+//   - 200 OK: Categories retrieved successfully. The 'data' field contains a page of categories.
+//   - 400 Bad Request: Invalid pagination, sort or search parameter.
 //   - 401 Unauthorized: User not authenticated.
 //   - 404 Not Found: User associated with token not found in the database.
 //   - 500 Internal Server Error: Database or other service error.
@@ -71,16 +77,21 @@ func (h *CategoryHandler) GetCategories(c *gin.Context) {
 		return
 	}
 
-	// Get all categories for the account
-	categories, err := h.service.GetCategoriesByAccount(user.AccountID)
+	list, ok := parseListQuery(c, database.CategorySortFields)
+	if !ok {
+		return
+	}
+
+	// Get the requested page of categories for the account
+	categories, page, err := h.service.ListCategories(user.AccountID, list)
 	if err != nil {
 		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
 		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to fetch categories.", errDetails)
 		return
 	}
 
-	// Return a 200 OK response with the list of categories in the data field.
-	helpers.Success(c.Writer, http.StatusOK, "Categories retrieved successfully.", categories)
+	// Return a 200 OK response with the page of categories in the data field.
+	helpers.SuccessPage(c.Writer, http.StatusOK, "Categories retrieved successfully.", categories, pagination(page))
 }
 
 // GetActiveCategories retrieves only active categories for the authenticated user's account.
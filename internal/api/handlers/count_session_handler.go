@@ -0,0 +1,375 @@
+// Package handlers provides HTTP request handlers for the application's API endpoints.
+// This package includes handlers for guided physical stock counts.
+// All handlers require authentication and operate within the context of user accounts.
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	helpers "github.com/mnadev/pantryos/internal/api/helper"
+
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CountSessionHandler handles HTTP requests related to count sessions.
+// A session is opened, receives counts by zone from any number of staff,
+// is reviewed against expected stock, and is finalized into an inventory
+// snapshot or abandoned.
+type CountSessionHandler struct {
+	// service provides access to the business logic layer for database operations
+	service *database.Service
+}
+
+// StartCountSessionRequest represents the request body for opening a count session
+type StartCountSessionRequest struct {
+	Name  string `json:"name"`  // e.g. "Month-end count"
+	Notes string `json:"notes"` // Optional instructions for the counters
+}
+
+// RecordCountRequest represents the request body for recording a count in a session
+type RecordCountRequest struct {
+	InventoryItemID   int     `json:"inventory_item_id" binding:"required"`
+	StorageLocationID *int    `json:"storage_location_id"` // Defaults to the account's default location
+	Zone              string  `json:"zone"`                // Area within the storage location counted
+	Quantity          float64 `json:"quantity"`
+}
+
+// CountSessionResponse represents a count session together with its counts
+type CountSessionResponse struct {
+	models.CountSession
+	Entries []models.CountEntry `json:"entries"`
+}
+
+// NewCountSessionHandler creates a new CountSessionHandler instance with the provided database connection.
+// This function initializes the handler with a database service that handles all
+// business logic and data access operations.
+//
+// Parameters:
+//   - db: The database connection to use for operations
+//
+// Returns:
+//   - *CountSessionHandler: A new handler instance ready to handle HTTP requests
+func NewCountSessionHandler(db *database.DB) *CountSessionHandler {
+	return &CountSessionHandler{service: database.NewService(db)}
+}
+
+// StartCountSession opens a new count session for the authenticated user's account.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Request Body: Optional JSON object
+//   - name: A label for the session (string)
+//   - notes: Instructions for the counters (string)
+//
+// Response:
+//
+//	All responses are wrapped in the standard APIResponse structure.
+//	- Success: { "success": true, "message": "...", "data": ... }
+//	- Error:   { "success": false, "message": "...", "error": { "code": "...", "details": "..." } }
+//
+// Status Codes:
+//   - 201 Created: Session opened successfully. The 'data' field contains the new session.
+//   - 400 Bad Request: Invalid request body.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *CountSessionHandler) StartCountSession(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	var req StartCountSessionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+			helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+			return
+		}
+	}
+
+	session := &models.CountSession{
+		AccountID: user.AccountID,
+		Name:      req.Name,
+		Notes:     req.Notes,
+		StartedBy: &user.ID,
+	}
+	if err := h.service.StartCountSession(session); err != nil {
+		errDetails := helpers.APIError{Code: "DB_INSERT_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to start count session.", errDetails)
+		return
+	}
+
+	// Return a 201 Created response with the new session.
+	helpers.Success(c.Writer, http.StatusCreated, "Count session started successfully.", session)
+}
+
+// GetCountSessions retrieves the count sessions of the authenticated user's account, newest first.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Status Codes:
+//   - 200 OK: Sessions retrieved successfully. The 'data' field contains a list of sessions.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *CountSessionHandler) GetCountSessions(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.service.GetCountSessionsByAccount(user.AccountID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to fetch count sessions.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the list of sessions in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Count sessions retrieved successfully.", sessions)
+}
+
+// GetCountSession retrieves a count session together with the counts recorded so far.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: The session must belong to the user's account
+//
+// Status Codes:
+//   - 200 OK: Session retrieved successfully.
+//   - 400 Bad Request: Invalid session ID.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The session does not belong to the user's account.
+//   - 404 Not Found: The user or the session could not be found.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *CountSessionHandler) GetCountSession(c *gin.Context) {
+	_, session, ok := h.getOwnedSession(c)
+	if !ok {
+		return
+	}
+
+	entries, err := h.service.GetCountEntries(session.ID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to fetch counts.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the session and its counts in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Count session retrieved successfully.", CountSessionResponse{
+		CountSession: *session,
+		Entries:      entries,
+	})
+}
+
+// RecordCount records the quantity of an item counted in one zone of an open session.
+// Counting the same item in the same zone again replaces the earlier count.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: The session must belong to the user's account
+//
+// Request Body: JSON object
+//   - inventory_item_id: The counted item (int, required)
+//   - storage_location_id: The storage location counted (int, optional, defaults to the default location)
+//   - zone: The area within the location counted, e.g., a shelf (string, optional)
+//   - quantity: The quantity counted (float64)
+//
+// Status Codes:
+//   - 200 OK: Count recorded successfully. The 'data' field contains the count entry.
+//   - 400 Bad Request: Invalid request body, item, quantity, or the session is closed.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The session does not belong to the user's account.
+//   - 404 Not Found: The user or the session could not be found.
+func (h *CountSessionHandler) RecordCount(c *gin.Context) {
+	user, session, ok := h.getOwnedSession(c)
+	if !ok {
+		return
+	}
+
+	var req RecordCountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+		return
+	}
+
+	entry := &models.CountEntry{
+		CountSessionID:    session.ID,
+		InventoryItemID:   req.InventoryItemID,
+		StorageLocationID: req.StorageLocationID,
+		Zone:              req.Zone,
+		Quantity:          req.Quantity,
+		CountedBy:         &user.ID,
+	}
+	if err := h.service.RecordCount(entry); err != nil {
+		errDetails := helpers.APIError{Code: "VALIDATION_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to record count.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the recorded count in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Count recorded successfully.", entry)
+}
+
+// ReviewCountSession compares a session's counts with the expected stock and flags large deviations.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: The session must belong to the user's account
+//
+// Query Parameters:
+//   - threshold: Variance percentage that flags an item (optional, defaults to 10)
+//
+// Status Codes:
+//   - 200 OK: Review generated successfully.
+//   - 400 Bad Request: Invalid session ID or threshold.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The session does not belong to the user's account.
+//   - 404 Not Found: The user or the session could not be found.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *CountSessionHandler) ReviewCountSession(c *gin.Context) {
+	_, session, ok := h.getOwnedSession(c)
+	if !ok {
+		return
+	}
+
+	threshold := database.DefaultCountVarianceThresholdPct
+	if value := c.Query("threshold"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed <= 0 {
+			errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: "threshold must be a positive number."}
+			helpers.Error(c.Writer, http.StatusBadRequest, "Invalid threshold parameter.", errDetails)
+			return
+		}
+		threshold = parsed
+	}
+
+	review, err := h.service.ReviewCountSession(session.ID, threshold)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to review count session.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the review in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Count session reviewed successfully.", review)
+}
+
+// FinalizeCountSession closes an open session and produces its inventory snapshot.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: The session must belong to the user's account
+//
+// Status Codes:
+//   - 200 OK: Session finalized successfully. The 'data' field contains the new snapshot.
+//   - 400 Bad Request: Invalid session ID.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The session does not belong to the user's account.
+//   - 404 Not Found: The user or the session could not be found.
+//   - 409 Conflict: The session is closed or has no counts.
+func (h *CountSessionHandler) FinalizeCountSession(c *gin.Context) {
+	user, session, ok := h.getOwnedSession(c)
+	if !ok {
+		return
+	}
+
+	snapshot, err := h.service.FinalizeCountSession(session.ID, user.ID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "FINALIZE_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusConflict, "Failed to finalize count session.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the new snapshot in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Count session finalized successfully.", snapshot)
+}
+
+// AbandonCountSession closes an open session without producing a snapshot.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: The session must belong to the user's account
+//
+// Status Codes:
+//   - 200 OK: Session abandoned successfully. The 'data' field contains the session.
+//   - 400 Bad Request: Invalid session ID.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The session does not belong to the user's account.
+//   - 404 Not Found: The user or the session could not be found.
+//   - 409 Conflict: The session is already closed.
+func (h *CountSessionHandler) AbandonCountSession(c *gin.Context) {
+	user, session, ok := h.getOwnedSession(c)
+	if !ok {
+		return
+	}
+
+	abandoned, err := h.service.AbandonCountSession(session.ID, user.ID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "ABANDON_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusConflict, "Failed to abandon count session.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the abandoned session in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Count session abandoned successfully.", abandoned)
+}
+
+// getUser resolves the authenticated user, writing the error response and
+// returning false when the user is not authenticated or does not exist.
+func (h *CountSessionHandler) getUser(c *gin.Context) (*models.User, bool) {
+	// Extract user ID from context (set by AuthMiddleware)
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		errDetails := helpers.APIError{Code: "UNAUTHORIZED", Details: "User ID not found in request context."}
+		helpers.Error(c.Writer, http.StatusUnauthorized, "User not authenticated.", errDetails)
+		return nil, false
+	}
+	userID := userIDInterface.(int)
+
+	// Retrieve user details from database to get the AccountID
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "USER_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "User not found.", errDetails)
+		return nil, false
+	}
+	return user, true
+}
+
+// getOwnedSession resolves the authenticated user and the count session named by
+// the ":id" URL parameter, writing the error response and returning false when
+// the session does not exist or belongs to another account.
+func (h *CountSessionHandler) getOwnedSession(c *gin.Context) (*models.User, *models.CountSession, bool) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return nil, nil, false
+	}
+
+	// Parse and validate the session ID from the URL parameter
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: "Count session ID must be a valid integer."}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid count session ID.", errDetails)
+		return nil, nil, false
+	}
+
+	session, err := h.service.GetCountSession(id)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "COUNT_SESSION_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "Count session not found.", errDetails)
+		return nil, nil, false
+	}
+
+	// Authorization check: Ensure the session belongs to the user's account
+	if session.AccountID != user.AccountID {
+		errDetails := helpers.APIError{Code: "FORBIDDEN", Details: "You do not have permission to access this count session."}
+		helpers.Error(c.Writer, http.StatusForbidden, "Access denied.", errDetails)
+		return nil, nil, false
+	}
+
+	return user, session, true
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupCountSessionTestHandler(t *testing.T) (*gin.Engine, *database.Service, *models.User, func()) {
+	router, service, user, cleanup := setupAuthenticatedTestRouter(t, "counts@example.com")
+	handler := NewCountSessionHandler(service.DB())
+
+	api := router.Group("/api/v1")
+	api.GET("/count-sessions", handler.GetCountSessions)
+	api.POST("/count-sessions", handler.StartCountSession)
+	api.GET("/count-sessions/:id", handler.GetCountSession)
+	api.POST("/count-sessions/:id/counts", handler.RecordCount)
+	api.GET("/count-sessions/:id/review", handler.ReviewCountSession)
+	api.POST("/count-sessions/:id/finalize", handler.FinalizeCountSession)
+	api.POST("/count-sessions/:id/abandon", handler.AbandonCountSession)
+
+	return router, service, user, cleanup
+}
+
+func TestCountSessionHandler_CountAndFinalize(t *testing.T) {
+	router, service, user, cleanup := setupCountSessionTestHandler(t)
+	defer cleanup()
+
+	item := &models.InventoryItem{
+		AccountID: user.AccountID,
+		Name:      "Oat Milk",
+		Unit:      "cartons",
+	}
+	require.NoError(t, service.CreateInventoryItem(item))
+
+	var sessionID int
+
+	t.Run("Start Session", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("POST", "/api/v1/count-sessions", map[string]interface{}{"name": "Weekly count"}, user.ID)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		session := response["data"].(map[string]interface{})
+		assert.Equal(t, "open", session["status"])
+		assert.Equal(t, float64(user.ID), session["started_by"])
+		sessionID = int(session["id"].(float64))
+	})
+
+	t.Run("Record Counts", func(t *testing.T) {
+		for zone, quantity := range map[string]float64{"walk-in": 10, "bar": 4} {
+			countData := map[string]interface{}{"inventory_item_id": item.ID, "zone": zone, "quantity": quantity}
+			req, w := createAuthenticatedRequest("POST", fmt.Sprintf("/api/v1/count-sessions/%d/counts", sessionID), countData, user.ID)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusOK, w.Code)
+		}
+
+		req, w := createAuthenticatedRequest("GET", fmt.Sprintf("/api/v1/count-sessions/%d", sessionID), nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		assert.Len(t, response["data"].(map[string]interface{})["entries"].([]interface{}), 2)
+	})
+
+	t.Run("Review Session", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", fmt.Sprintf("/api/v1/count-sessions/%d/review?threshold=5", sessionID), nil, user.ID)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		review := response["data"].(map[string]interface{})
+		lines := review["lines"].([]interface{})
+		require.Len(t, lines, 1)
+		line := lines[0].(map[string]interface{})
+		assert.Equal(t, float64(14), line["counted"])
+		assert.Equal(t, true, line["flagged"]) // nothing was expected on hand
+
+		req, w = createAuthenticatedRequest("GET", fmt.Sprintf("/api/v1/count-sessions/%d/review?threshold=abc", sessionID), nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Finalize Session", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("POST", fmt.Sprintf("/api/v1/count-sessions/%d/finalize", sessionID), nil, user.ID)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		counts := response["data"].(map[string]interface{})["counts"].(map[string]interface{})
+		assert.Equal(t, float64(14), counts[strconv.Itoa(item.ID)])
+
+		// Closed sessions reject further counts and a second finalize
+		countData := map[string]interface{}{"inventory_item_id": item.ID, "quantity": 1}
+		req, w = createAuthenticatedRequest("POST", fmt.Sprintf("/api/v1/count-sessions/%d/counts", sessionID), countData, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		req, w = createAuthenticatedRequest("POST", fmt.Sprintf("/api/v1/count-sessions/%d/finalize", sessionID), nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Abandon Session", func(t *testing.T) {
+		session := &models.CountSession{AccountID: user.AccountID}
+		require.NoError(t, service.StartCountSession(session))
+
+		req, w := createAuthenticatedRequest("POST", fmt.Sprintf("/api/v1/count-sessions/%d/abandon", session.ID), nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		req, w = createAuthenticatedRequest("GET", "/api/v1/count-sessions", nil, user.ID)
+		router.ServeHTTP(w, req)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		assert.Len(t, response["data"].([]interface{}), 2)
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/mnadev/pantryos/internal/database"
 	"github.com/mnadev/pantryos/internal/email"
 	"github.com/mnadev/pantryos/internal/models"
+	"github.com/mnadev/pantryos/internal/pdf"
 )
 
 // EmailHandler handles email-related API endpoints
@@ -23,9 +24,12 @@ type EmailHandler struct {
 
 // NewEmailHandler creates a new email handler
 func NewEmailHandler(db *database.DB) *EmailHandler {
+	service := database.NewService(db)
+	emailService := email.NewEmailService()
+	emailService.SetTemplateStore(service)
 	return &EmailHandler{
-		service:      database.NewService(db),
-		emailService: email.NewEmailService(),
+		service:      service,
+		emailService: emailService,
 	}
 }
 
@@ -125,7 +129,7 @@ func (h *EmailHandler) VerifyEmail(c *gin.Context) {
 
 // SendWeeklyStockReport godoc
 // @Summary      Send weekly stock report
-// @Description  Triggers the sending of a weekly stock report email to all users in a specific account.
+// @Description  Triggers the sending of a weekly stock report email to all users in a specific account, with the report attached as a PDF.
 // @Tags         email
 // @Accept       json
 // @Produce      json
@@ -167,15 +171,24 @@ func (h *EmailHandler) SendWeeklyStockReport(c *gin.Context) {
 	}
 
 	// Generate the data for the stock report
-	stockData, err := h.generateStockReportData(accountID)
+	stockData, err := generateStockReportData(h.service, accountID)
 	if err != nil {
 		errDetails := helpers.APIError{Code: "REPORT_GENERATION_FAILED", Details: err.Error()}
 		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to generate stock report data.", errDetails)
 		return
 	}
 
+	// Attach the report as a PDF for mail clients that do not display HTML
+	document, err := pdf.StockReport(account.Name, stockData)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "PDF_RENDER_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to render PDF.", errDetails)
+		return
+	}
+	attachment := email.Attachment{Filename: "stock-report-" + stockData.ReportDate.Format(dateQueryLayout) + ".pdf", ContentType: pdf.ContentType, Data: document}
+
 	// Send the weekly stock report via the email service
-	if err := h.emailService.SendWeeklyStockReport(*account, users, stockData); err != nil {
+	if err := h.emailService.SendWeeklyStockReport(*account, users, stockData, attachment); err != nil {
 		errDetails := helpers.APIError{Code: "EMAIL_SEND_FAILED", Details: err.Error()}
 		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to send weekly stock report.", errDetails)
 		return
@@ -275,7 +288,7 @@ func (h *EmailHandler) SendLowStockAlert(c *gin.Context) {
 
 // SendWeeklySupplyChainReport godoc
 // @Summary      Send weekly supply chain report
-// @Description  Triggers the sending of a weekly supply chain report email to all users in a specific account.
+// @Description  Triggers the sending of a weekly supply chain report email to all users in a specific account, with the report attached as a PDF.
 // @Tags         email
 // @Accept       json
 // @Produce      json
@@ -317,15 +330,24 @@ func (h *EmailHandler) SendWeeklySupplyChainReport(c *gin.Context) {
 	}
 
 	// Generate the data for the supply chain report
-	supplyChainData, err := h.generateSupplyChainReportData(accountID)
+	supplyChainData, err := generateSupplyChainReportData(h.service, accountID)
 	if err != nil {
 		errDetails := helpers.APIError{Code: "REPORT_GENERATION_FAILED", Details: err.Error()}
 		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to generate supply chain report data.", errDetails)
 		return
 	}
 
+	// Attach the report as a PDF for mail clients that do not display HTML
+	document, err := pdf.SupplyChainReport(account.Name, supplyChainData)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "PDF_RENDER_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to render PDF.", errDetails)
+		return
+	}
+	attachment := email.Attachment{Filename: "supply-chain-report-" + supplyChainData.ReportDate.Format(dateQueryLayout) + ".pdf", ContentType: pdf.ContentType, Data: document}
+
 	// Send the weekly supply chain report via the email service
-	if err := h.emailService.SendWeeklySupplyChainReport(*account, users, supplyChainData); err != nil {
+	if err := h.emailService.SendWeeklySupplyChainReport(*account, users, supplyChainData, attachment); err != nil {
 		errDetails := helpers.APIError{Code: "EMAIL_SEND_FAILED", Details: err.Error()}
 		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to send weekly supply chain report.", errDetails)
 		return
@@ -364,15 +386,15 @@ func (h *EmailHandler) generateVerificationToken(userID int) (string, error) {
 }
 
 // generateStockReportData generates stock report data for an account
-func (h *EmailHandler) generateStockReportData(accountID int) (*email.StockReportData, error) {
+func generateStockReportData(service *database.Service, accountID int) (*email.StockReportData, error) {
 	// Get all inventory items for the account
-	items, err := h.service.GetInventoryItemsByAccount(accountID)
+	items, err := service.GetInventoryItemsByAccount(accountID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get latest inventory snapshot
-	latestSnapshot, err := h.service.GetLatestInventorySnapshot(accountID)
+	latestSnapshot, err := service.GetLatestInventorySnapshot(accountID)
 	if err != nil {
 		return nil, err
 	}
@@ -410,7 +432,7 @@ func (h *EmailHandler) generateStockReportData(accountID int) (*email.StockRepor
 		// Get category name
 		categoryName := ""
 		if item.CategoryID != nil {
-			category, err := h.service.GetCategory(*item.CategoryID)
+			category, err := service.GetCategory(*item.CategoryID)
 			if err == nil {
 				categoryName = category.Name
 			}
@@ -436,21 +458,21 @@ func (h *EmailHandler) generateStockReportData(accountID int) (*email.StockRepor
 }
 
 // generateSupplyChainReportData generates supply chain report data for an account
-func (h *EmailHandler) generateSupplyChainReportData(accountID int) (*email.SupplyChainData, error) {
+func generateSupplyChainReportData(service *database.Service, accountID int) (*email.SupplyChainData, error) {
 	// Get all inventory items for the account
-	items, err := h.service.GetInventoryItemsByAccount(accountID)
+	items, err := service.GetInventoryItemsByAccount(accountID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get latest inventory snapshot
-	latestSnapshot, err := h.service.GetLatestInventorySnapshot(accountID)
+	latestSnapshot, err := service.GetLatestInventorySnapshot(accountID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get recent deliveries for vendor information
-	recentDeliveries, err := h.service.GetDeliveriesByAccount(accountID)
+	recentDeliveries, err := service.GetDeliveriesByAccount(accountID)
 	if err != nil {
 		return nil, err
 	}
@@ -513,7 +535,7 @@ func (h *EmailHandler) generateSupplyChainReportData(accountID int) (*email.Supp
 		// Get category name
 		categoryName := ""
 		if item.CategoryID != nil {
-			category, err := h.service.GetCategory(*item.CategoryID)
+			category, err := service.GetCategory(*item.CategoryID)
 			if err == nil {
 				categoryName = category.Name
 			}
@@ -589,7 +611,7 @@ func getBaseURL(c *gin.Context) string {
 
 // EmailScheduleRequest represents the request body for creating/updating email schedules
 type EmailScheduleRequest struct {
-	EmailType  string `json:"email_type" binding:"required"`  // "weekly_stock_report", "weekly_supply_chain_report", "low_stock_alert"
+	EmailType  string `json:"email_type" binding:"required"`  // "weekly_stock_report", "weekly_supply_chain_report", "low_stock_alert", "monthly_vendor_scorecard"
 	Frequency  string `json:"frequency" binding:"required"`   // "weekly", "daily", "monthly"
 	DayOfWeek  *int   `json:"day_of_week"`                    // 0-6 (Sunday-Saturday) for weekly
 	DayOfMonth *int   `json:"day_of_month"`                   // 1-31 for monthly
@@ -1062,6 +1084,9 @@ func isValidEmailType(emailType string) bool {
 		models.EmailTypeWeeklyReport,
 		models.EmailTypeWeeklySupplyChain,
 		models.EmailTypeLowStockAlert,
+		models.EmailTypeVendorScorecard,
+		models.EmailTypeInventoryValuation,
+		models.EmailTypeExpiringItems,
 	}
 
 	for _, validType := range validTypes {
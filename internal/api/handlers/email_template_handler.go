@@ -0,0 +1,393 @@
+// Package handlers provides HTTP request handlers for the application's API endpoints.
+// This package includes handlers for email template overrides, previews, and languages.
+// All handlers require authentication and operate within the context of user accounts.
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	helpers "github.com/mnadev/pantryos/internal/api/helper"
+
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/email"
+	"github.com/mnadev/pantryos/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmailTemplateHandler handles HTTP requests related to email templates.
+// Accounts can replace any built-in template in any language, preview how
+// their emails will look, and choose the language each user receives.
+type EmailTemplateHandler struct {
+	// service provides access to the business logic layer for database operations
+	service *database.Service
+	// emailService renders templates, using the account's overrides
+	emailService *email.EmailService
+}
+
+// EmailTemplateRequest represents the request body for saving a template override
+type EmailTemplateRequest struct {
+	Source string `json:"source" binding:"required"` // html/template source; may define a "subject" template
+}
+
+// EmailLanguageRequest represents the request body for setting a user's email language
+type EmailLanguageRequest struct {
+	Language string `json:"language"` // e.g. "en" or "es-MX"; empty for the default
+}
+
+// EmailTemplateSummary describes one email template and the languages it is available in
+type EmailTemplateSummary struct {
+	Name            string   `json:"name"`
+	BuiltinLocales  []string `json:"builtin_locales"`
+	OverrideLocales []string `json:"override_locales"`
+}
+
+// NewEmailTemplateHandler creates a new EmailTemplateHandler instance with the provided database connection.
+// The handler's email service reads template overrides through the database service.
+//
+// Parameters:
+//   - db: The database connection to use for operations
+//
+// Returns:
+//   - *EmailTemplateHandler: A new handler instance ready to handle HTTP requests
+func NewEmailTemplateHandler(db *database.DB) *EmailTemplateHandler {
+	service := database.NewService(db)
+	emailService := email.NewEmailService()
+	emailService.SetTemplateStore(service)
+	return &EmailTemplateHandler{service: service, emailService: emailService}
+}
+
+// GetEmailTemplates lists the email templates with the languages they have built-in
+// translations in and the languages the user's account has overridden.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Response:
+//
+//	All responses are wrapped in the standard APIResponse structure.
+//	- Success: { "success": true, "message": "...", "data": [...] }
+//	- Error:   { "success": false, "message": "...", "error": { "code": "...", "details": "..." } }
+//
+// Status Codes:
+//   - 200 OK: Templates retrieved successfully.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: The user could not be found.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *EmailTemplateHandler) GetEmailTemplates(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	overrides, err := h.service.GetEmailTemplatesByAccount(user.AccountID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to fetch email templates.", errDetails)
+		return
+	}
+
+	overrideLocales := make(map[string][]string)
+	for _, override := range overrides {
+		overrideLocales[override.Name] = append(overrideLocales[override.Name], override.Locale)
+	}
+
+	templates := make([]EmailTemplateSummary, 0, len(email.TemplateNames))
+	for _, name := range email.TemplateNames {
+		locales := overrideLocales[name]
+		if locales == nil {
+			locales = []string{}
+		}
+		templates = append(templates, EmailTemplateSummary{
+			Name:            name,
+			BuiltinLocales:  email.BuiltinLocales(name),
+			OverrideLocales: locales,
+		})
+	}
+
+	// Return a 200 OK response with the templates in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Email templates retrieved successfully.", templates)
+}
+
+// PreviewEmailTemplate renders an email template with sample data, using the account's
+// overrides, so users can see an email before it is sent.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Query Parameters:
+//   - locale: The language to render, e.g. "es" (optional, defaults to the user's language)
+//   - format: "html" to return the rendered page instead of JSON (optional)
+//
+// Response:
+//
+//	All JSON responses are wrapped in the standard APIResponse structure.
+//	- Success: { "success": true, "message": "...", "data": { "subject": "...", "html": "...", "text": "..." } }
+//	- Error:   { "success": false, "message": "...", "error": { "code": "...", "details": "..." } }
+//
+// Status Codes:
+//   - 200 OK: Template rendered successfully.
+//   - 400 Bad Request: Invalid locale.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: The user, account, or template could not be found.
+//   - 500 Internal Server Error: Rendering or database error.
+func (h *EmailTemplateHandler) PreviewEmailTemplate(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	name, ok := h.getTemplateName(c)
+	if !ok {
+		return
+	}
+
+	account, err := h.service.GetAccount(user.AccountID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "ACCOUNT_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "Account not found.", errDetails)
+		return
+	}
+
+	locale := c.DefaultQuery("locale", user.Language)
+	if _, err := email.NormalizeLocale(locale); err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_LOCALE", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid locale.", errDetails)
+		return
+	}
+
+	preview, err := h.emailService.PreviewTemplate(*account, name, locale)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "TEMPLATE_RENDER_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to render email template.", errDetails)
+		return
+	}
+
+	if c.Query("format") == "html" {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(preview.HTML))
+		return
+	}
+
+	// Return a 200 OK response with the rendered email in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Email template rendered successfully.", preview)
+}
+
+// SaveEmailTemplate replaces a built-in email template in a language with the
+// account's own template. Saving a language that is already overridden replaces
+// the override.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Request Body: JSON object
+//   - source: The html/template source (string, required). A {{define "subject"}}
+//     template sets the subject line.
+//
+// Response:
+//
+//	All responses are wrapped in the standard APIResponse structure.
+//	- Success: { "success": true, "message": "...", "data": {...} }
+//	- Error:   { "success": false, "message": "...", "error": { "code": "...", "details": "..." } }
+//
+// Status Codes:
+//   - 200 OK: Template saved successfully. The 'data' field contains the override.
+//   - 400 Bad Request: Invalid locale or request body, or a template that fails to render the sample data.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: The user or template could not be found.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *EmailTemplateHandler) SaveEmailTemplate(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	name, ok := h.getTemplateName(c)
+	if !ok {
+		return
+	}
+
+	locale, ok := h.getLocale(c)
+	if !ok {
+		return
+	}
+
+	var req EmailTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+		return
+	}
+
+	// Reject templates that would fail when emails are sent
+	if err := email.ValidateTemplate(name, req.Source); err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_TEMPLATE", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid email template.", errDetails)
+		return
+	}
+
+	emailTemplate := &models.EmailTemplate{
+		AccountID: user.AccountID,
+		Name:      name,
+		Locale:    locale,
+		Source:    req.Source,
+	}
+	if err := h.service.SaveEmailTemplate(emailTemplate); err != nil {
+		errDetails := helpers.APIError{Code: "DB_SAVE_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to save email template.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the saved override in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Email template saved successfully.", emailTemplate)
+}
+
+// DeleteEmailTemplate removes the account's override of a template in a language,
+// so the built-in template is used again.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Response:
+//
+//	All responses are wrapped in the standard APIResponse structure.
+//	- Success: { "success": true, "message": "...", "data": null }
+//	- Error:   { "success": false, "message": "...", "error": { "code": "...", "details": "..." } }
+//
+// Status Codes:
+//   - 200 OK: Override deleted successfully.
+//   - 400 Bad Request: Invalid locale.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: The user, template, or override could not be found.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *EmailTemplateHandler) DeleteEmailTemplate(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	name, ok := h.getTemplateName(c)
+	if !ok {
+		return
+	}
+
+	locale, ok := h.getLocale(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteEmailTemplate(user.AccountID, name, locale); err != nil {
+		if errors.Is(err, database.ErrEmailTemplateNotFound) {
+			errDetails := helpers.APIError{Code: "TEMPLATE_NOT_FOUND", Details: "The account has not overridden this template in this language."}
+			helpers.Error(c.Writer, http.StatusNotFound, "Email template override not found.", errDetails)
+			return
+		}
+		errDetails := helpers.APIError{Code: "DB_DELETE_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to delete email template.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response confirming the deletion.
+	helpers.Success(c.Writer, http.StatusOK, "Email template deleted successfully.", nil)
+}
+
+// UpdateEmailLanguage sets the language the authenticated user receives emails in.
+// Templates without a translation in the language are sent in the default language.
+//
+// Authentication: Required (JWT token in Authorization header)
+//
+// Request Body: JSON object
+//   - language: A language tag such as "es" or "es-MX" (string, empty for the default)
+//
+// Response:
+//
+//	All responses are wrapped in the standard APIResponse structure.
+//	- Success: { "success": true, "message": "...", "data": {...} }
+//	- Error:   { "success": false, "message": "...", "error": { "code": "...", "details": "..." } }
+//
+// Status Codes:
+//   - 200 OK: Language updated successfully. The 'data' field contains the user.
+//   - 400 Bad Request: Invalid request body or language.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: The user could not be found.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *EmailTemplateHandler) UpdateEmailLanguage(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	var req EmailLanguageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+		return
+	}
+
+	language := ""
+	if req.Language != "" {
+		normalized, err := email.NormalizeLocale(req.Language)
+		if err != nil {
+			errDetails := helpers.APIError{Code: "INVALID_LOCALE", Details: err.Error()}
+			helpers.Error(c.Writer, http.StatusBadRequest, "Invalid language.", errDetails)
+			return
+		}
+		language = normalized
+	}
+
+	user.Language = language
+	if err := h.service.UpdateUser(user); err != nil {
+		errDetails := helpers.APIError{Code: "DB_UPDATE_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to update email language.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the updated user in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Email language updated successfully.", user)
+}
+
+// getUser resolves the authenticated user, writing the error response and
+// returning false when the user is not authenticated or does not exist.
+func (h *EmailTemplateHandler) getUser(c *gin.Context) (*models.User, bool) {
+	// Extract user ID from context (set by AuthMiddleware)
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		errDetails := helpers.APIError{Code: "UNAUTHORIZED", Details: "User ID not found in request context."}
+		helpers.Error(c.Writer, http.StatusUnauthorized, "User not authenticated.", errDetails)
+		return nil, false
+	}
+	userID := userIDInterface.(int)
+
+	// Retrieve user details from database to get the AccountID
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "USER_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "User not found.", errDetails)
+		return nil, false
+	}
+	return user, true
+}
+
+// getTemplateName reads the ":name" URL parameter, writing the error response and
+// returning false when it is not one of the email templates.
+func (h *EmailTemplateHandler) getTemplateName(c *gin.Context) (string, bool) {
+	name := c.Param("name")
+	if !email.IsTemplate(name) {
+		errDetails := helpers.APIError{Code: "TEMPLATE_NOT_FOUND", Details: "No email template is named " + name + "."}
+		helpers.Error(c.Writer, http.StatusNotFound, "Email template not found.", errDetails)
+		return "", false
+	}
+	return name, true
+}
+
+// getLocale reads and normalizes the ":locale" URL parameter, writing the error
+// response and returning false when it is not a language tag.
+func (h *EmailTemplateHandler) getLocale(c *gin.Context) (string, bool) {
+	locale, err := email.NormalizeLocale(c.Param("locale"))
+	if err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_LOCALE", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid locale.", errDetails)
+		return "", false
+	}
+	return locale, true
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupEmailTemplateTestHandler(t *testing.T) (*gin.Engine, *database.Service, *models.User, func()) {
+	router, service, user, cleanup := setupAuthenticatedTestRouter(t, "templates@example.com")
+	handler := NewEmailTemplateHandler(service.DB())
+
+	api := router.Group("/api/v1")
+	api.GET("/email/templates", handler.GetEmailTemplates)
+	api.GET("/email/templates/:name/preview", handler.PreviewEmailTemplate)
+	api.PUT("/email/templates/:name/:locale", handler.SaveEmailTemplate)
+	api.DELETE("/email/templates/:name/:locale", handler.DeleteEmailTemplate)
+	api.PUT("/settings/email-language", handler.UpdateEmailLanguage)
+
+	return router, service, user, cleanup
+}
+
+func TestEmailTemplateHandler_Overrides(t *testing.T) {
+	router, _, user, cleanup := setupEmailTemplateTestHandler(t)
+	defer cleanup()
+
+	t.Run("Save Override", func(t *testing.T) {
+		body := map[string]interface{}{"source": `{{define "subject"}}Running low at {{.AccountName}}{{end}}<p>{{len .LowStockItems}} items</p>`}
+		req, w := createAuthenticatedRequest("PUT", "/api/v1/email/templates/low_stock_alert/en", body, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Reject Broken Template", func(t *testing.T) {
+		body := map[string]interface{}{"source": `{{.NoSuchField}}`}
+		req, w := createAuthenticatedRequest("PUT", "/api/v1/email/templates/low_stock_alert/en", body, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Reject Unknown Template", func(t *testing.T) {
+		body := map[string]interface{}{"source": `<p></p>`}
+		req, w := createAuthenticatedRequest("PUT", "/api/v1/email/templates/newsletter/en", body, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("List Templates", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/email/templates", nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		for _, entry := range response["data"].([]interface{}) {
+			summary := entry.(map[string]interface{})
+			if summary["name"] == "low_stock_alert" {
+				assert.Equal(t, []interface{}{"en"}, summary["override_locales"])
+				assert.Equal(t, []interface{}{"en", "es"}, summary["builtin_locales"])
+			}
+		}
+	})
+
+	t.Run("Preview Override", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/email/templates/low_stock_alert/preview", nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		preview := response["data"].(map[string]interface{})
+		assert.Equal(t, "Running low at Test Shop", preview["subject"])
+		assert.Equal(t, "<p>2 items</p>", preview["html"])
+	})
+
+	t.Run("Delete Override", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("DELETE", "/api/v1/email/templates/low_stock_alert/en", nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		req, w = createAuthenticatedRequest("DELETE", "/api/v1/email/templates/low_stock_alert/en", nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestEmailTemplateHandler_Language(t *testing.T) {
+	router, service, user, cleanup := setupEmailTemplateTestHandler(t)
+	defer cleanup()
+
+	t.Run("Update Language", func(t *testing.T) {
+		body := map[string]interface{}{"language": "es_MX"}
+		req, w := createAuthenticatedRequest("PUT", "/api/v1/settings/email-language", body, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		updated, err := service.GetUser(user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "es-mx", updated.Language)
+	})
+
+	t.Run("Preview In User Language", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/email/templates/weekly_stock_report/preview?format=html", nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "text/html"))
+		assert.Contains(t, w.Body.String(), `lang="es"`)
+	})
+
+	t.Run("Reject Invalid Language", func(t *testing.T) {
+		body := map[string]interface{}{"language": "not a language"}
+		req, w := createAuthenticatedRequest("PUT", "/api/v1/settings/email-language", body, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
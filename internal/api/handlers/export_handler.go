@@ -0,0 +1,150 @@
+// Package handlers provides HTTP request handlers for the application's API endpoints.
+// This package includes the handler for exporting account data as spreadsheets.
+// All handlers require authentication and operate within the context of user accounts.
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	helpers "github.com/mnadev/pantryos/internal/api/helper"
+
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+	"github.com/mnadev/pantryos/internal/spreadsheet"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportHandler handles HTTP requests for data exports.
+// It streams deliveries, inventory snapshots, the inventory valuation, and sales of
+// the authenticated user's account as CSV, XLSX, or JSON files for bookkeeping.
+type ExportHandler struct {
+	// service provides access to the business logic layer for database operations
+	service *database.Service
+}
+
+// NewExportHandler creates a new ExportHandler instance with the provided database connection.
+//
+// Parameters:
+//   - db: The database connection to use for operations
+//
+// Returns:
+//   - *ExportHandler: A new handler instance ready to handle HTTP requests
+func NewExportHandler(db *database.DB) *ExportHandler {
+	return &ExportHandler{service: database.NewService(db)}
+}
+
+// Export streams a dataset of the authenticated user's account as a downloadable file.
+// Rows are written as they are read from the database, so exports of any size can be
+// downloaded without being held in memory.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: Data is scoped to the user's account
+//
+// Path Parameters:
+//   - dataset: deliveries, snapshots (one row per counted item), valuation (current stock at cost), or sales (one row per item sold)
+//
+// Query Parameters:
+//   - format: csv, xlsx, or json (string, optional, defaults to csv)
+//   - start_date: Only records on or after this date, YYYY-MM-DD (optional)
+//   - end_date: Only records on or before this date, YYYY-MM-DD (optional)
+//
+// Response:
+//
+//	The file itself, sent as an attachment. Errors are returned in the standard
+//	APIResponse structure when they happen before the first row is sent.
+//
+// Status Codes:
+//   - 200 OK: The file is streamed in the response body.
+//   - 400 Bad Request: Unknown dataset or format, or invalid dates.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *ExportHandler) Export(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	dataset := c.Param("dataset")
+	columns, err := database.ExportColumns(dataset)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Unknown export dataset.", errDetails)
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", spreadsheet.FormatCSV))
+	if !slices.Contains(spreadsheet.Formats, format) {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: fmt.Sprintf("format must be one of: %s.", strings.Join(spreadsheet.Formats, ", "))}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid format parameter.", errDetails)
+		return
+	}
+
+	startDate, endDate, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	// The response starts with the first row, so errors found before it can still be reported
+	var writer spreadsheet.Writer
+	start := func() error {
+		filename := fmt.Sprintf("%s-%s.%s", dataset, time.Now().Format(dateQueryLayout), format)
+		c.Header("Content-Type", spreadsheet.ContentType(format))
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Status(http.StatusOK)
+		writer, err = spreadsheet.NewWriter(c.Writer, format, dataset, columns)
+		return err
+	}
+
+	err = h.service.Export(user.AccountID, dataset, startDate, endDate, func(row []interface{}) error {
+		if writer == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		return writer.Write(row)
+	})
+	if err == nil && writer == nil {
+		err = start()
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		if writer != nil {
+			// The status has been sent, so the truncated file is all the client can be given
+			log.Printf("Export of %s for account %d failed after it started: %v", dataset, user.AccountID, err)
+			return
+		}
+		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to export data.", errDetails)
+	}
+}
+
+// getUser resolves the authenticated user, writing the error response and
+// returning false when the user is not authenticated or does not exist.
+func (h *ExportHandler) getUser(c *gin.Context) (*models.User, bool) {
+	// Extract user ID from context (set by AuthMiddleware)
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		errDetails := helpers.APIError{Code: "UNAUTHORIZED", Details: "User ID not found in request context."}
+		helpers.Error(c.Writer, http.StatusUnauthorized, "User not authenticated.", errDetails)
+		return nil, false
+	}
+	userID := userIDInterface.(int)
+
+	// Retrieve user details from database to get the AccountID
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "USER_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "User not found.", errDetails)
+		return nil, false
+	}
+	return user, true
+}
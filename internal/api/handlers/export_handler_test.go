@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+	"github.com/mnadev/pantryos/internal/spreadsheet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupExportTestHandler(t *testing.T) (*gin.Engine, *database.Service, *models.User, func()) {
+	router, service, user, cleanup := setupAuthenticatedTestRouter(t, "export@example.com")
+	handler := NewExportHandler(service.DB())
+
+	api := router.Group("/api/v1")
+	api.GET("/exports/:dataset", handler.Export)
+
+	return router, service, user, cleanup
+}
+
+func TestExportHandler_Export(t *testing.T) {
+	router, service, user, cleanup := setupExportTestHandler(t)
+	defer cleanup()
+
+	milk := &models.InventoryItem{AccountID: user.AccountID, Name: "Milk", Unit: "liters", CostPerUnit: 2.5}
+	require.NoError(t, service.CreateInventoryItem(milk))
+	march := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	for day := 0; day < 3; day++ {
+		require.NoError(t, service.CreateDelivery(&models.Delivery{AccountID: user.AccountID, InventoryItemID: milk.ID, Vendor: "Local Dairy", Quantity: 10, Cost: 25, DeliveryDate: march.AddDate(0, 0, day)}))
+	}
+
+	t.Run("CSV", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/exports/deliveries?start_date=2024-03-02&end_date=2024-03-02", nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment; filename=\"deliveries-")
+
+		records, err := spreadsheet.ReadCSV(strings.NewReader(w.Body.String()))
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, "delivery_id", records[0][0])
+		assert.Equal(t, "2024-03-02T09:00:00Z", records[1][1])
+	})
+
+	t.Run("XLSX", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/exports/valuation?format=xlsx", nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		records, err := spreadsheet.Read("valuation.xlsx", w.Body.Bytes())
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, []string{strconv.Itoa(milk.ID), "Milk", "", "liters", "30", "2.5", "75"}, records[1])
+	})
+
+	t.Run("Empty JSON", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/exports/sales?format=json", nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var rows []map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rows))
+		assert.Empty(t, rows)
+	})
+
+	t.Run("Bad Requests", func(t *testing.T) {
+		for _, path := range []string{"/api/v1/exports/payroll", "/api/v1/exports/sales?format=pdf", "/api/v1/exports/sales?start_date=March"} {
+			req, w := createAuthenticatedRequest("GET", path, nil, user.ID)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code, path)
+		}
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/exports/sales", nil, 0)
+		req.Header.Del("X-Test-User-ID")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
@@ -5,13 +5,18 @@
 package handlers
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	helpers "github.com/mnadev/pantryos/internal/api/helper"
 
 	"github.com/mnadev/pantryos/internal/database"
 	"github.com/mnadev/pantryos/internal/models"
+	"github.com/mnadev/pantryos/internal/spreadsheet"
 
 	"github.com/gin-gonic/gin"
 )
@@ -39,15 +44,24 @@ func NewInventoryHandler(db *database.DB) *InventoryHandler {
 
 // Inventory Item Handlers
 
-// GetInventoryItems retrieves all inventory items for the authenticated user's account.
+// GetInventoryItems retrieves a page of inventory items for the authenticated user's account.
 // This endpoint requires authentication and returns items scoped to the user's account.
-// The response includes all inventory items with their current stock levels and details.
+// The response includes the inventory items with their current stock levels and details,
+// along with pagination metadata.
 //
 // Authentication: Required (JWT token in Authorization header)
 // Authorization: User must be authenticated and have access to the account
 //
+// Query Parameters:
+//   - by_location: Break each item's current stock down by storage location (bool, optional)
+//   - limit, offset: Page size and number of items to skip (optional; pages hold 100 items by default)
+//   - sort: name, cost_per_unit, min_stock_level or id, prefixed with "-" for descending (optional)
+//   - search: Case-insensitive name search (optional)
+//   - category_id, vendor, vendor_id: Filter by category or preferred vendor (optional)
+//
 // Response:
-//   - 200 OK: List of inventory items for the user's account
+//   - 200 OK: Page of inventory items for the user's account
+//   - 400 Bad Request: Invalid by_location, pagination, sort or filter parameter
 //   - 401 Unauthorized: User not authenticated
 //   - 404 Not Found: User not found in database
 //   - 500 Internal Server Error: Database or service error
@@ -88,8 +102,38 @@ func (h *InventoryHandler) GetInventoryItems(c *gin.Context) {
 		return
 	}
 
-	// Get current stock levels from the latest snapshot.
-	itemsWithStock, err := h.service.GetInventoryItemsWithCurrentStock(user.AccountID)
+	byLocation := false
+	if raw := c.Query("by_location"); raw != "" {
+		byLocation, err = strconv.ParseBool(raw)
+		if err != nil {
+			errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: "by_location must be true or false."}
+			helpers.Error(c.Writer, http.StatusBadRequest, "Invalid by_location parameter.", errDetails)
+			return
+		}
+	}
+
+	list, ok := parseListQuery(c, database.InventoryItemSortFields)
+	if !ok {
+		return
+	}
+
+	if byLocation {
+		itemsWithLocationStock, page, err := h.service.ListInventoryItemsWithLocationStock(user.AccountID, list)
+		if err != nil {
+			errDetails := helpers.APIError{
+				Code:    "DB_FETCH_FAILED",
+				Details: err.Error(),
+			}
+			helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to retrieve inventory data.", errDetails)
+			return
+		}
+
+		helpers.SuccessPage(c.Writer, http.StatusOK, "Inventory items retrieved successfully.", itemsWithLocationStock, pagination(page))
+		return
+	}
+
+	// Get current stock levels from the maintained inventory levels.
+	itemsWithStock, page, err := h.service.ListInventoryItemsWithCurrentStock(user.AccountID, list)
 	if err != nil {
 		errDetails := helpers.APIError{
 			Code:    "DB_FETCH_FAILED",
@@ -99,7 +143,7 @@ func (h *InventoryHandler) GetInventoryItems(c *gin.Context) {
 		return
 	}
 
-	helpers.Success(c.Writer, http.StatusOK, "Inventory items retrieved successfully.", itemsWithStock)
+	helpers.SuccessPage(c.Writer, http.StatusOK, "Inventory items retrieved successfully.", itemsWithStock, pagination(page))
 }
 
 // GetLowStockItems retrieves inventory items that are currently low on stock.
@@ -228,6 +272,123 @@ func (h *InventoryHandler) CreateInventoryItem(c *gin.Context) {
 	helpers.Success(c.Writer, http.StatusCreated, "Inventory item created successfully.", item)
 }
 
+// maxImportFileSize is the largest spreadsheet accepted by ImportInventoryItems
+const maxImportFileSize = 10 << 20
+
+// ImportInventoryItems creates or updates inventory items in bulk from a CSV or XLSX file.
+// Items are matched to existing ones by name, every row is validated with the same rules
+// as creating an item, and the rows are committed together or not at all.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: Items are imported into the user's account
+//
+// Request Body: multipart/form-data
+//   - file: The .csv or .xlsx file; its first row names the columns (required)
+//   - mapping: JSON object mapping item fields to column names, e.g. {"name": "Item", "unit": "UOM"} (optional;
+//     fields not mapped are read from columns named after them)
+//
+// Query Parameters:
+//   - dry_run: Validate the file and report what would change without changing anything (bool, optional)
+//
+// Response:
+//
+//	All responses are wrapped in the standard APIResponse structure. The 'data' field contains the
+//	import report, with the action for each valid row and the errors for each invalid one.
+//
+// Status Codes:
+//   - 200 OK: Dry run completed, or all rows imported.
+//   - 400 Bad Request: Missing, oversized, or unreadable file; invalid mapping or dry_run parameter.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+//   - 422 Unprocessable Entity: Some rows are invalid, so nothing was imported.
+func (h *InventoryHandler) ImportInventoryItems(c *gin.Context) {
+	// Extract user ID from context (set by AuthMiddleware)
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		errDetails := helpers.APIError{Code: "UNAUTHORIZED", Details: "User ID not found in request context."}
+		helpers.Error(c.Writer, http.StatusUnauthorized, "User not authenticated.", errDetails)
+		return
+	}
+	userID := userIDInterface.(int)
+
+	// Retrieve user details from database to get the AccountID
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "USER_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "User not found.", errDetails)
+		return
+	}
+
+	dryRun := false
+	if raw := c.Query("dry_run"); raw != "" {
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: "dry_run must be true or false."}
+			helpers.Error(c.Writer, http.StatusBadRequest, "Invalid dry_run parameter.", errDetails)
+			return
+		}
+	}
+
+	var mapping map[string]string
+	if raw := c.PostForm("mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: "mapping must be a JSON object of field names to column names."}
+			helpers.Error(c.Writer, http.StatusBadRequest, "Invalid column mapping.", errDetails)
+			return
+		}
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: "Upload the spreadsheet in the file field."}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Missing import file.", errDetails)
+		return
+	}
+	if fileHeader.Size > maxImportFileSize {
+		errDetails := helpers.APIError{Code: "FILE_TOO_LARGE", Details: fmt.Sprintf("Import files are limited to %d MB.", maxImportFileSize>>20)}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Import file is too large.", errDetails)
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to read import file.", errDetails)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, maxImportFileSize))
+	if err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to read import file.", errDetails)
+		return
+	}
+
+	records, err := spreadsheet.Read(fileHeader.Filename, data)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_FILE", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to read import file.", errDetails)
+		return
+	}
+
+	report, err := h.service.ImportInventoryItems(user.AccountID, records, mapping, dryRun)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "VALIDATION_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to import inventory items.", errDetails)
+		return
+	}
+
+	if !report.DryRun && !report.Committed {
+		errDetails := helpers.APIError{Code: "VALIDATION_FAILED", Details: fmt.Sprintf("%d rows have errors; nothing was imported.", len(report.Errors))}
+		helpers.ErrorWithData(c.Writer, http.StatusUnprocessableEntity, "Inventory import has invalid rows.", errDetails, report)
+		return
+	}
+
+	message := "Inventory items imported successfully."
+	if report.DryRun {
+		message = "Inventory import validated; nothing was changed."
+	}
+	helpers.Success(c.Writer, http.StatusOK, message, report)
+}
+
 // GetInventoryItem retrieves a specific inventory item by ID.
 // This endpoint requires authentication and validates that the item belongs
 // to the authenticated user's account before returning it.
@@ -295,6 +456,86 @@ func (h *InventoryHandler) GetInventoryItem(c *gin.Context) {
 	helpers.Success(c.Writer, http.StatusOK, "Inventory item retrieved successfully.", item)
 }
 
+// GetInventoryItemLedger lists every movement of an inventory item's stock in chronological
+// order with its running balance, explaining how the current stock was reached.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: The item must belong to the user's account
+//
+// URL Parameters:
+//   - id: The inventory item ID (integer)
+//
+// Query Parameters:
+//   - since: First day to cover, YYYY-MM-DD (optional). The ledger starts at the latest
+//     stock count taken on or before this day; without it, at the latest stock count.
+//
+// Status Codes:
+//   - 200 OK: Ledger retrieved successfully. The 'data' field contains the movements and final balance.
+//   - 400 Bad Request: Invalid item ID or date.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The item does not belong to the user's account.
+//   - 404 Not Found: The user or the item could not be found.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *InventoryHandler) GetInventoryItemLedger(c *gin.Context) {
+	// Extract user ID from context (set by AuthMiddleware)
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		errDetails := helpers.APIError{Code: "UNAUTHORIZED", Details: "User ID not found in request context."}
+		helpers.Error(c.Writer, http.StatusUnauthorized, "User not authenticated.", errDetails)
+		return
+	}
+	userID := userIDInterface.(int)
+
+	// Retrieve user details from database
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "USER_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "User not found.", errDetails)
+		return
+	}
+
+	// Parse and validate the item ID from the URL parameter
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: "Item ID must be a valid integer."}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid Item ID.", errDetails)
+		return
+	}
+
+	var since time.Time
+	if value := c.Query("since"); value != "" {
+		if since, err = time.Parse(dateQueryLayout, value); err != nil {
+			errDetails := helpers.APIError{Code: "INVALID_DATE", Details: "since must be in YYYY-MM-DD format."}
+			helpers.Error(c.Writer, http.StatusBadRequest, "Invalid since date.", errDetails)
+			return
+		}
+	}
+
+	item, err := h.service.GetInventoryItem(id)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "ITEM_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "Inventory item not found.", errDetails)
+		return
+	}
+
+	// Authorization check: Ensure the item belongs to the user's account
+	if item.AccountID != user.AccountID {
+		errDetails := helpers.APIError{Code: "FORBIDDEN", Details: "You do not have permission to access this item."}
+		helpers.Error(c.Writer, http.StatusForbidden, "Access denied.", errDetails)
+		return
+	}
+
+	ledger, err := h.service.GetInventoryItemLedger(item.ID, since)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to build inventory ledger.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the ledger in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Inventory ledger retrieved successfully.", ledger)
+}
+
 // UpdateInventoryItem godoc
 // @Summary      Update inventory item
 // @Description  Update an existing inventory item by its ID. The user must be authenticated and own the item.
@@ -449,13 +690,19 @@ func (h *InventoryHandler) DeleteInventoryItem(c *gin.Context) {
 // Menu Item Handlers
 
 // GetMenuItems godoc
-// @Summary      Get all menu items
-// @Description  Retrieve all menu items for the authenticated user's account.
+// @Summary      Get menu items
+// @Description  Retrieve a page of menu items for the authenticated user's account.
 // @Tags         menu
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Param        limit        query     int     false  "Page size; defaults to 100"
+// @Param        offset       query     int     false  "Number of menu items to skip"
+// @Param        sort         query     string  false  "name, price, category or id; prefix with - for descending"
+// @Param        search       query     string  false  "Case-insensitive name search"
+// @Param        category_id  query     int     false  "Only menu items in this category"
 // @Success      200  {object}  helpers.APIResponse{data=[]models.MenuItem}  "Successfully retrieved list of menu items"
+// @Failure      400  {object}  helpers.APIResponse                          "Error: Invalid pagination, sort or filter parameter"
 // @Failure      401  {object}  helpers.APIResponse                          "Error: User not authenticated"
 // @Failure      404  {object}  helpers.APIResponse                          "Error: User not found"
 // @Failure      500  {object}  helpers.APIResponse                          "Error: Internal server error"
@@ -478,16 +725,21 @@ func (h *InventoryHandler) GetMenuItems(c *gin.Context) {
 		return
 	}
 
-	// Get all menu items for the user's account
-	items, err := h.service.GetMenuItemsByAccount(user.AccountID)
+	list, ok := parseListQuery(c, database.MenuItemSortFields)
+	if !ok {
+		return
+	}
+
+	// Get the requested page of menu items for the user's account
+	items, page, err := h.service.ListMenuItems(user.AccountID, list)
 	if err != nil {
 		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
 		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to fetch menu items.", errDetails)
 		return
 	}
 
-	// Return a 200 OK response with the list of menu items in the data field.
-	helpers.Success(c.Writer, http.StatusOK, "Menu items retrieved successfully.", items)
+	// Return a 200 OK response with the page of menu items in the data field.
+	helpers.SuccessPage(c.Writer, http.StatusOK, "Menu items retrieved successfully.", items, pagination(page))
 }
 
 // CreateMenuItem godoc
@@ -605,13 +857,24 @@ func (h *InventoryHandler) LogDelivery(c *gin.Context) {
 }
 
 // GetDeliveries godoc
-// @Summary      Get all deliveries
-// @Description  Retrieve all deliveries for the authenticated user's account.
+// @Summary      Get deliveries
+// @Description  Retrieve a page of deliveries for the authenticated user's account, newest first by default.
 // @Tags         deliveries
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Param        limit              query     int     false  "Page size; defaults to 100"
+// @Param        offset             query     int     false  "Number of deliveries to skip"
+// @Param        sort               query     string  false  "delivery_date, vendor, quantity, cost or id; prefix with - for descending"
+// @Param        search             query     string  false  "Case-insensitive search on the delivered item's name"
+// @Param        vendor             query     string  false  "Case-insensitive search on the vendor name"
+// @Param        vendor_id          query     int     false  "Only deliveries linked to this vendor"
+// @Param        inventory_item_id  query     int     false  "Only deliveries of this inventory item"
+// @Param        category_id        query     int     false  "Only deliveries of items in this category"
+// @Param        start_date         query     string  false  "Only deliveries on or after this date (YYYY-MM-DD)"
+// @Param        end_date           query     string  false  "Only deliveries on or before this date (YYYY-MM-DD)"
 // @Success      200  {object}  helpers.APIResponse{data=[]models.Delivery}  "Successfully retrieved list of deliveries"
+// @Failure      400  {object}  helpers.APIResponse                            "Error: Invalid pagination, sort or filter parameter"
 // @Failure      401  {object}  helpers.APIResponse                            "Error: User not authenticated"
 // @Failure      404  {object}  helpers.APIResponse                            "Error: User not found"
 // @Failure      500  {object}  helpers.APIResponse                            "Error: Internal server error"
@@ -634,16 +897,21 @@ func (h *InventoryHandler) GetDeliveries(c *gin.Context) {
 		return
 	}
 
-	// Get all deliveries for the user's account
-	deliveries, err := h.service.GetDeliveriesByAccount(user.AccountID)
+	list, ok := parseListQuery(c, database.DeliverySortFields)
+	if !ok {
+		return
+	}
+
+	// Get the requested page of deliveries for the user's account
+	deliveries, page, err := h.service.ListDeliveries(user.AccountID, list)
 	if err != nil {
 		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
 		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to fetch deliveries.", errDetails)
 		return
 	}
 
-	// Return a 200 OK response with the list of deliveries in the data field.
-	helpers.Success(c.Writer, http.StatusOK, "Deliveries retrieved successfully.", deliveries)
+	// Return a 200 OK response with the page of deliveries in the data field.
+	helpers.SuccessPage(c.Writer, http.StatusOK, "Deliveries retrieved successfully.", deliveries, pagination(page))
 }
 
 // Snapshot Handlers
@@ -704,13 +972,19 @@ func (h *InventoryHandler) CreateInventorySnapshot(c *gin.Context) {
 }
 
 // GetInventorySnapshots godoc
-// @Summary      Get all inventory snapshots
-// @Description  Retrieve all historical inventory snapshots for the authenticated user's account.
+// @Summary      Get inventory snapshots
+// @Description  Retrieve a page of historical inventory snapshots for the authenticated user's account, newest first by default.
 // @Tags         snapshots
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Param        limit       query     int     false  "Page size; defaults to 100"
+// @Param        offset      query     int     false  "Number of snapshots to skip"
+// @Param        sort        query     string  false  "timestamp or id; prefix with - for descending"
+// @Param        start_date  query     string  false  "Only snapshots on or after this date (YYYY-MM-DD)"
+// @Param        end_date    query     string  false  "Only snapshots on or before this date (YYYY-MM-DD)"
 // @Success      200  {object}  helpers.APIResponse{data=[]models.InventorySnapshot}  "Successfully retrieved list of snapshots"
+// @Failure      400  {object}  helpers.APIResponse                                  "Error: Invalid pagination, sort or filter parameter"
 // @Failure      401  {object}  helpers.APIResponse                                  "Error: User not authenticated"
 // @Failure      404  {object}  helpers.APIResponse                                  "Error: User not found"
 // @Failure      500  {object}  helpers.APIResponse                                  "Error: Internal server error"
@@ -733,16 +1007,21 @@ func (h *InventoryHandler) GetInventorySnapshots(c *gin.Context) {
 		return
 	}
 
-	// Get all inventory snapshots for the user's account
-	snapshots, err := h.service.GetInventorySnapshotsByAccount(user.AccountID)
+	list, ok := parseListQuery(c, database.InventorySnapshotSortFields)
+	if !ok {
+		return
+	}
+
+	// Get the requested page of inventory snapshots for the user's account
+	snapshots, page, err := h.service.ListInventorySnapshots(user.AccountID, list)
 	if err != nil {
 		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
 		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to fetch inventory snapshots.", errDetails)
 		return
 	}
 
-	// Return a 200 OK response with the list of snapshots in the data field.
-	helpers.Success(c.Writer, http.StatusOK, "Inventory snapshots retrieved successfully.", snapshots)
+	// Return a 200 OK response with the page of snapshots in the data field.
+	helpers.SuccessPage(c.Writer, http.StatusOK, "Inventory snapshots retrieved successfully.", snapshots, pagination(page))
 }
 
 // Vendor-based handlers
@@ -3,6 +3,8 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -82,9 +84,11 @@ func setupInventoryTestHandler(t *testing.T) (*gin.Engine, *database.Service, *m
 		{
 			inventory.GET("/items", handler.GetInventoryItems)
 			inventory.POST("/items", handler.CreateInventoryItem)
+			inventory.POST("/items/import", handler.ImportInventoryItems)
 			inventory.GET("/items/:id", handler.GetInventoryItem)
 			inventory.PUT("/items/:id", handler.UpdateInventoryItem)
 			inventory.DELETE("/items/:id", handler.DeleteInventoryItem)
+			inventory.GET("/items/:id/ledger", handler.GetInventoryItemLedger)
 			inventory.GET("/vendor/:vendor", handler.GetInventoryItemsByVendor)
 		}
 
@@ -136,6 +140,76 @@ func createAuthenticatedRequest(method, path string, body interface{}, userID in
 	return req, httptest.NewRecorder()
 }
 
+// Helper function to set up a test database with a user of the given email in a
+// "Test Shop" account, and a router that authenticates requests as the user named
+// by the X-Test-User-ID header. Callers register the routes they test on the router.
+func setupAuthenticatedTestRouter(t *testing.T, email string) (*gin.Engine, *database.Service, *models.User, func()) {
+	gin.SetMode(gin.TestMode)
+
+	db, cleanup := database.SetupTestDBLegacy(t)
+	service := database.NewService(db)
+
+	account := &models.Account{
+		Name:   "Test Shop",
+		Status: "active",
+	}
+	require.NoError(t, service.CreateAccount(account))
+
+	hashedPassword, err := utils.HashPassword("password123")
+	require.NoError(t, err)
+
+	user := &models.User{
+		Email:     email,
+		Password:  hashedPassword,
+		AccountID: account.ID,
+		Role:      "user",
+	}
+	require.NoError(t, service.CreateUser(user))
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if userID, err := strconv.Atoi(c.GetHeader("X-Test-User-ID")); err == nil {
+			c.Set("userID", userID)
+		}
+		c.Next()
+	})
+
+	return router, service, user, cleanup
+}
+
+// Helper function to group the user's account with a new store in a multi-location
+// organization, returning a user of the new store with the given email.
+func addTestStore(t *testing.T, service *database.Service, user *models.User, email string) *models.User {
+	account, err := service.GetAccount(user.AccountID)
+	require.NoError(t, err)
+	if account.OrganizationID == nil {
+		org := &models.Organization{Name: "Test Corp"}
+		require.NoError(t, service.CreateOrganization(org))
+
+		account.OrganizationID = &org.ID
+		account.BusinessType = models.BusinessTypeMultiLocation
+		require.NoError(t, service.UpdateAccount(account))
+	}
+
+	store := &models.Account{
+		OrganizationID: account.OrganizationID,
+		Name:           "Second Store",
+		BusinessType:   models.BusinessTypeMultiLocation,
+		Status:         "active",
+	}
+	require.NoError(t, service.CreateAccount(store))
+
+	member := &models.User{
+		Email:     email,
+		Password:  user.Password,
+		AccountID: store.ID,
+		Role:      "user",
+	}
+	require.NoError(t, service.CreateUser(member))
+
+	return member
+}
+
 // Test Inventory Items
 
 func TestGetInventoryItems(t *testing.T) {
@@ -312,6 +386,44 @@ func TestGetInventoryItem(t *testing.T) {
 	})
 }
 
+func TestGetInventoryItemLedger(t *testing.T) {
+	router, service, user, _, cleanup := setupInventoryTestHandler(t)
+	defer cleanup()
+
+	item := &models.InventoryItem{AccountID: user.AccountID, Name: "Oat Milk", Unit: "L", CostPerUnit: 2}
+	require.NoError(t, service.CreateInventoryItem(item))
+	require.NoError(t, service.CreateDelivery(&models.Delivery{AccountID: user.AccountID, InventoryItemID: item.ID, Vendor: "Dairy Co", Quantity: 6, DeliveryDate: time.Now().Add(-time.Hour)}))
+	require.NoError(t, service.RecordStockAdjustment(&models.StockAdjustment{AccountID: user.AccountID, InventoryItemID: item.ID, Quantity: -9, Reason: models.AdjustmentReasonCountCorrection}))
+
+	t.Run("Get Ledger", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/inventory/items/"+strconv.Itoa(item.ID)+"/ledger", nil, user.ID)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		ledger := response["data"].(map[string]interface{})
+		movements := ledger["movements"].([]interface{})
+		require.Len(t, movements, 2)
+		assert.Equal(t, "delivery", movements[0].(map[string]interface{})["type"])
+		assert.Equal(t, 6.0, movements[0].(map[string]interface{})["balance"])
+		assert.Equal(t, -3.0, ledger["balance"])
+	})
+
+	t.Run("Get Ledger with Invalid Date", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/inventory/items/"+strconv.Itoa(item.ID)+"/ledger?since=yesterday", nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Get Ledger of Non-Existent Item", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/inventory/items/999/ledger", nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
 func TestUpdateInventoryItem(t *testing.T) {
 	router, service, user, _, cleanup := setupInventoryTestHandler(t)
 	defer cleanup()
@@ -585,6 +697,76 @@ func TestGetDeliveries(t *testing.T) {
 	})
 }
 
+func TestGetDeliveriesPagination(t *testing.T) {
+	router, service, user, _, cleanup := setupInventoryTestHandler(t)
+	defer cleanup()
+
+	milk := &models.InventoryItem{AccountID: user.AccountID, Name: "Milk", Unit: "liters", CostPerUnit: 2.50}
+	require.NoError(t, service.CreateInventoryItem(milk))
+	sugar := &models.InventoryItem{AccountID: user.AccountID, Name: "Sugar", Unit: "kg", CostPerUnit: 1.20}
+	require.NoError(t, service.CreateInventoryItem(sugar))
+
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	for day := 0; day < 5; day++ {
+		require.NoError(t, service.CreateDelivery(&models.Delivery{AccountID: user.AccountID, InventoryItemID: milk.ID, Vendor: "Local Dairy", Quantity: float64(10 + day), DeliveryDate: start.AddDate(0, 0, day)}))
+	}
+	require.NoError(t, service.CreateDelivery(&models.Delivery{AccountID: user.AccountID, InventoryItemID: sugar.ID, Vendor: "Sweet Supplies", Quantity: 3, DeliveryDate: start}))
+
+	get := func(query string) ([]interface{}, map[string]interface{}) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/deliveries"+query, nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Contains(t, response, "pagination")
+		return response["data"].([]interface{}), response["pagination"].(map[string]interface{})
+	}
+
+	t.Run("Pages Newest First", func(t *testing.T) {
+		deliveries, page := get("?limit=2")
+		require.Len(t, deliveries, 2)
+		assert.Equal(t, 14.0, deliveries[0].(map[string]interface{})["quantity"])
+		assert.Equal(t, 6.0, page["total"])
+		assert.Equal(t, true, page["has_more"])
+
+		deliveries, page = get("?limit=2&offset=4")
+		assert.Len(t, deliveries, 2)
+		assert.Equal(t, false, page["has_more"])
+	})
+
+	t.Run("Default Page Size Without Limit", func(t *testing.T) {
+		deliveries, page := get("")
+		assert.Len(t, deliveries, 6)
+		assert.Equal(t, float64(database.DefaultPageSize), page["limit"])
+		assert.Equal(t, false, page["has_more"])
+	})
+
+	t.Run("Sort And Filter", func(t *testing.T) {
+		deliveries, page := get("?vendor=dairy&sort=quantity&start_date=2024-03-02&end_date=2024-03-04")
+		require.Len(t, deliveries, 3)
+		assert.Equal(t, 3.0, page["total"])
+		assert.Equal(t, 11.0, deliveries[0].(map[string]interface{})["quantity"])
+		assert.Equal(t, 13.0, deliveries[2].(map[string]interface{})["quantity"])
+
+		deliveries, _ = get("?search=SUG")
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, "Sweet Supplies", deliveries[0].(map[string]interface{})["vendor"])
+
+		deliveries, _ = get("?inventory_item_id=" + strconv.Itoa(milk.ID) + "&sort=-delivery_date&limit=1")
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, 14.0, deliveries[0].(map[string]interface{})["quantity"])
+	})
+
+	t.Run("Invalid Parameters", func(t *testing.T) {
+		for _, query := range []string{"?limit=0", "?limit=abc", "?offset=-1", "?sort=price", "?vendor_id=x", "?start_date=March"} {
+			req, w := createAuthenticatedRequest("GET", "/api/v1/deliveries"+query, nil, user.ID)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+	})
+}
+
 func TestGetDeliveriesByVendor(t *testing.T) {
 	router, service, user, _, cleanup := setupInventoryTestHandler(t)
 	defer cleanup()
@@ -808,6 +990,77 @@ func TestGetInventoryItemsByVendor(t *testing.T) {
 
 // Test Error Cases
 
+func TestImportInventoryItems(t *testing.T) {
+	router, service, user, _, cleanup := setupInventoryTestHandler(t)
+	defer cleanup()
+
+	upload := func(query, filename, content, mapping string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, err := form.CreateFormFile("file", filename)
+		require.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		require.NoError(t, err)
+		if mapping != "" {
+			require.NoError(t, form.WriteField("mapping", mapping))
+		}
+		require.NoError(t, form.Close())
+
+		req, w := createAuthenticatedRequest("POST", "/api/v1/inventory/items/import"+query, nil, user.ID)
+		req.Body = io.NopCloser(&body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		router.ServeHTTP(w, req)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w, response
+	}
+	content := "Product,unit,cost_per_unit\nFlour,kg,0.80\nSugar,kg,1.10\n"
+	mapping := `{"name": "Product"}`
+
+	t.Run("Dry Run", func(t *testing.T) {
+		w, response := upload("?dry_run=true", "items.csv", content, mapping)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		report := response["data"].(map[string]interface{})
+		assert.Equal(t, 2.0, report["created"])
+		assert.Equal(t, false, report["committed"])
+
+		items, err := service.GetInventoryItemsByAccount(user.AccountID)
+		require.NoError(t, err)
+		assert.Empty(t, items)
+	})
+
+	t.Run("Invalid Rows", func(t *testing.T) {
+		w, response := upload("", "items.csv", content+"Butter,,2\n", mapping)
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+		report := response["data"].(map[string]interface{})
+		errs := report["errors"].([]interface{})
+		require.Len(t, errs, 1)
+		assert.Equal(t, 4.0, errs[0].(map[string]interface{})["row"])
+	})
+
+	t.Run("Commit", func(t *testing.T) {
+		w, response := upload("", "items.csv", content, mapping)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, true, response["data"].(map[string]interface{})["committed"])
+
+		items, err := service.GetInventoryItemsByAccount(user.AccountID)
+		require.NoError(t, err)
+		assert.Len(t, items, 2)
+	})
+
+	t.Run("Bad Requests", func(t *testing.T) {
+		w, _ := upload("", "items.txt", content, mapping)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w, _ = upload("", "items.csv", content, `["name"]`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w, _ = upload("?dry_run=maybe", "items.csv", content, mapping)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w, _ = upload("", "items.csv", content, "")
+		assert.Equal(t, http.StatusBadRequest, w.Code, "no column is named name")
+	})
+}
+
 func TestInventoryHandlerErrors(t *testing.T) {
 	router, _, user, _, cleanup := setupInventoryTestHandler(t)
 	defer cleanup()
@@ -0,0 +1,404 @@
+// Package handlers provides HTTP request handlers for the application's API endpoints.
+// This package includes handlers for vendor invoices and their three-way match.
+// All handlers require authentication and operate within the context of user accounts.
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	helpers "github.com/mnadev/pantryos/internal/api/helper"
+
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InvoiceHandler handles HTTP requests related to vendor invoices.
+// It records invoices, matches them against what was ordered and received,
+// and lists the invoices whose mismatches need review before payment.
+type InvoiceHandler struct {
+	// service provides access to the business logic layer for database operations
+	service *database.Service
+}
+
+// CreateInvoiceRequest represents the request body for recording a vendor invoice
+type CreateInvoiceRequest struct {
+	Vendor        string               `json:"vendor"`    // Defaults to the vendor of the items billed
+	VendorID      *int                 `json:"vendor_id"` // Ignored when the vendor name names another vendor
+	InvoiceNumber string               `json:"invoice_number" binding:"required"`
+	OrderID       *int                 `json:"order_id"`     // Defaults to the order of the items billed
+	InvoiceDate   *time.Time           `json:"invoice_date"` // Defaults to now
+	DueDate       *time.Time           `json:"due_date"`
+	Notes         string               `json:"notes"`
+	Lines         []InvoiceLineRequest `json:"lines" binding:"required,min=1,dive"`
+}
+
+// InvoiceLineRequest represents a single charge on an invoice being recorded
+type InvoiceLineRequest struct {
+	OrderItemID *int    `json:"order_item_id"`
+	DeliveryID  *int    `json:"delivery_id"`
+	Description string  `json:"description"` // Defaults to the name of the item billed
+	Quantity    float64 `json:"quantity" binding:"required"`
+	UnitCost    float64 `json:"unit_cost"`
+}
+
+// InvoiceMatchSettingsRequest represents the request body for updating an account's invoice match tolerances
+type InvoiceMatchSettingsRequest struct {
+	QuantityTolerancePct float64 `json:"quantity_tolerance_pct"` // Accepted difference from the quantity received
+	PriceTolerancePct    float64 `json:"price_tolerance_pct"`    // Accepted difference from the ordered unit cost
+}
+
+// NewInvoiceHandler creates a new InvoiceHandler instance with the provided database connection.
+// This function initializes the handler with a database service that handles all
+// business logic and data access operations.
+//
+// Parameters:
+//   - db: The database connection to use for operations
+//
+// Returns:
+//   - *InvoiceHandler: A new handler instance ready to handle HTTP requests
+func NewInvoiceHandler(db *database.DB) *InvoiceHandler {
+	return &InvoiceHandler{service: database.NewService(db)}
+}
+
+// CreateInvoice records a vendor invoice and matches it against the order and
+// deliveries it bills. Invoices that match within the account's tolerances are
+// approved for payment; the others are marked for review.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Request Body: JSON object
+//   - invoice_number: The vendor's invoice number (string, required)
+//   - vendor / vendor_id: The vendor billing (optional, defaults to the vendor of the items billed)
+//   - order_id: The order billed (int, optional, defaults to the order of the items billed)
+//   - invoice_date, due_date: RFC 3339 timestamps (optional)
+//   - notes: Notes on the invoice (string, optional)
+//   - lines: The charges (array, required), each with quantity, unit_cost, and optionally
+//     order_item_id, delivery_id, and description
+//
+// Response:
+//
+//	All responses are wrapped in the standard APIResponse structure.
+//	- Success: { "success": true, "message": "...", "data": { "invoice": {...}, "lines": [...], ... } }
+//	- Error:   { "success": false, "message": "...", "error": { "code": "...", "details": "..." } }
+//
+// Status Codes:
+//   - 201 Created: Invoice recorded and matched; see the invoice status for the outcome.
+//   - 400 Bad Request: Invalid request body or invoice data.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+//   - 409 Conflict: The vendor's invoice number was already recorded.
+func (h *InvoiceHandler) CreateInvoice(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	var req CreateInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+		return
+	}
+
+	invoice := &models.Invoice{
+		AccountID:     user.AccountID,
+		Vendor:        req.Vendor,
+		VendorID:      req.VendorID,
+		InvoiceNumber: req.InvoiceNumber,
+		OrderID:       req.OrderID,
+		DueDate:       req.DueDate,
+		Notes:         req.Notes,
+		CreatedBy:     user.ID,
+	}
+	if req.InvoiceDate != nil {
+		invoice.InvoiceDate = *req.InvoiceDate
+	}
+	lines := make([]models.InvoiceLine, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		lines = append(lines, models.InvoiceLine{
+			OrderItemID: line.OrderItemID,
+			DeliveryID:  line.DeliveryID,
+			Description: line.Description,
+			Quantity:    line.Quantity,
+			UnitCost:    line.UnitCost,
+		})
+	}
+
+	match, err := h.service.CreateInvoice(invoice, lines)
+	if errors.Is(err, database.ErrDuplicateInvoice) {
+		errDetails := helpers.APIError{Code: "DUPLICATE_INVOICE", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusConflict, "Invoice already recorded.", errDetails)
+		return
+	}
+	if err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INVOICE", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to record invoice.", errDetails)
+		return
+	}
+
+	// Return a 201 Created response with the match outcome in the data field.
+	helpers.Success(c.Writer, http.StatusCreated, "Invoice recorded successfully.", match)
+}
+
+// GetInvoices lists the invoices of the authenticated user's account, newest first.
+// Filtering by the "needs_review" status lists the mismatches to resolve before payment.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Query Parameters:
+//   - status: Only list invoices with this status: pending, approved, or needs_review (optional)
+//
+// Status Codes:
+//   - 200 OK: Invoices retrieved successfully.
+//   - 400 Bad Request: Invalid status.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *InvoiceHandler) GetInvoices(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	status := c.Query("status")
+	switch status {
+	case "", models.InvoiceStatusPending, models.InvoiceStatusApproved, models.InvoiceStatusNeedsReview:
+	default:
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: fmt.Sprintf("Unknown invoice status %q.", status)}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid status.", errDetails)
+		return
+	}
+
+	invoices, err := h.service.GetInvoicesByAccount(user.AccountID, status)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to fetch invoices.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the list of invoices in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Invoices retrieved successfully.", invoices)
+}
+
+// GetInvoice retrieves an invoice together with its lines and their match status.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: The invoice must belong to the user's account
+//
+// Path Parameters:
+//   - id: The invoice ID (int, required)
+//
+// Status Codes:
+//   - 200 OK: Invoice retrieved successfully.
+//   - 400 Bad Request: Invalid invoice ID.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: The invoice does not exist or belongs to another account.
+func (h *InvoiceHandler) GetInvoice(c *gin.Context) {
+	_, invoice, ok := h.getOwnedInvoice(c)
+	if !ok {
+		return
+	}
+
+	// Return a 200 OK response with the invoice in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Invoice retrieved successfully.", invoice)
+}
+
+// MatchInvoice matches an invoice again, e.g. after the rest of its goods were received.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: The invoice must belong to the user's account
+//
+// Path Parameters:
+//   - id: The invoice ID (int, required)
+//
+// Status Codes:
+//   - 200 OK: Invoice matched; see the invoice status for the outcome.
+//   - 400 Bad Request: Invalid invoice ID.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: The invoice does not exist or belongs to another account.
+//   - 409 Conflict: The invoice is already approved for payment.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *InvoiceHandler) MatchInvoice(c *gin.Context) {
+	_, invoice, ok := h.getOwnedInvoice(c)
+	if !ok {
+		return
+	}
+
+	match, err := h.service.MatchInvoice(invoice)
+	if errors.Is(err, database.ErrInvoiceAlreadyApproved) {
+		errDetails := helpers.APIError{Code: "INVOICE_APPROVED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusConflict, "Invoice cannot be matched.", errDetails)
+		return
+	}
+	if err != nil {
+		errDetails := helpers.APIError{Code: "MATCH_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to match invoice.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the match outcome in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Invoice matched successfully.", match)
+}
+
+// ApproveInvoice approves an invoice for payment after its mismatches were reviewed.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: The invoice must belong to the user's account
+//
+// Path Parameters:
+//   - id: The invoice ID (int, required)
+//
+// Status Codes:
+//   - 200 OK: Invoice approved. The 'data' field contains the updated invoice.
+//   - 400 Bad Request: Invalid invoice ID.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: The invoice does not exist or belongs to another account.
+//   - 409 Conflict: The invoice is already approved for payment.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *InvoiceHandler) ApproveInvoice(c *gin.Context) {
+	user, invoice, ok := h.getOwnedInvoice(c)
+	if !ok {
+		return
+	}
+
+	err := h.service.ApproveInvoice(invoice, user.ID)
+	if errors.Is(err, database.ErrInvoiceAlreadyApproved) {
+		errDetails := helpers.APIError{Code: "INVOICE_APPROVED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusConflict, "Invoice is already approved.", errDetails)
+		return
+	}
+	if err != nil {
+		errDetails := helpers.APIError{Code: "DB_UPDATE_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to approve invoice.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the approved invoice in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Invoice approved successfully.", invoice)
+}
+
+// GetInvoiceMatchSettings retrieves the invoice match tolerances of the
+// authenticated user's account.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Status Codes:
+//   - 200 OK: Settings retrieved successfully.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: The user or the account could not be found.
+func (h *InvoiceHandler) GetInvoiceMatchSettings(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	account, err := h.service.GetAccount(user.AccountID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "ACCOUNT_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "Account not found.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the settings in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Invoice match settings retrieved successfully.", InvoiceMatchSettingsRequest{
+		QuantityTolerancePct: account.InvoiceQuantityTolerancePct,
+		PriceTolerancePct:    account.InvoicePriceTolerancePct,
+	})
+}
+
+// UpdateInvoiceMatchSettings changes how closely invoices of the authenticated user's
+// account must match what was ordered and received to be approved for payment.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Request Body: JSON object
+//   - quantity_tolerance_pct: Accepted difference from the quantity received, as a percentage (float64)
+//   - price_tolerance_pct: Accepted difference from the ordered unit cost, as a percentage (float64)
+//
+// Status Codes:
+//   - 200 OK: Settings updated successfully. The 'data' field contains the updated account.
+//   - 400 Bad Request: Invalid request body or settings.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+func (h *InvoiceHandler) UpdateInvoiceMatchSettings(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	var req InvoiceMatchSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+		return
+	}
+
+	account, err := h.service.UpdateInvoiceMatchSettings(user.AccountID, req.QuantityTolerancePct, req.PriceTolerancePct)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "VALIDATION_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to update invoice match settings.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the updated account in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Invoice match settings updated successfully.", account)
+}
+
+// getUser resolves the authenticated user, writing the error response and
+// returning false when the user is not authenticated or does not exist.
+func (h *InvoiceHandler) getUser(c *gin.Context) (*models.User, bool) {
+	// Extract user ID from context (set by AuthMiddleware)
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		errDetails := helpers.APIError{Code: "UNAUTHORIZED", Details: "User ID not found in request context."}
+		helpers.Error(c.Writer, http.StatusUnauthorized, "User not authenticated.", errDetails)
+		return nil, false
+	}
+	userID := userIDInterface.(int)
+
+	// Retrieve user details from database to get the AccountID
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "USER_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "User not found.", errDetails)
+		return nil, false
+	}
+	return user, true
+}
+
+// getOwnedInvoice resolves the authenticated user and the invoice named by the ":id"
+// URL parameter, writing the error response and returning false when the invoice
+// does not exist or belongs to another account.
+func (h *InvoiceHandler) getOwnedInvoice(c *gin.Context) (*models.User, *models.Invoice, bool) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return nil, nil, false
+	}
+
+	// Parse and validate the invoice ID from the URL parameter
+	invoiceID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: "Invoice ID must be a valid integer."}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid Invoice ID.", errDetails)
+		return nil, nil, false
+	}
+
+	invoice, err := h.service.GetInvoice(invoiceID)
+	if err != nil || invoice.AccountID != user.AccountID {
+		errDetails := helpers.APIError{Code: "INVOICE_NOT_FOUND", Details: fmt.Sprintf("Invoice with ID %d not found.", invoiceID)}
+		helpers.Error(c.Writer, http.StatusNotFound, "Invoice not found.", errDetails)
+		return nil, nil, false
+	}
+
+	return user, invoice, true
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupInvoiceTestHandler(t *testing.T) (*gin.Engine, *database.Service, *models.User, func()) {
+	router, service, user, cleanup := setupAuthenticatedTestRouter(t, "invoices@example.com")
+	handler := NewInvoiceHandler(service.DB())
+
+	api := router.Group("/api/v1")
+	api.GET("/invoices", handler.GetInvoices)
+	api.POST("/invoices", handler.CreateInvoice)
+	api.GET("/invoices/:id", handler.GetInvoice)
+	api.POST("/invoices/:id/match", handler.MatchInvoice)
+	api.POST("/invoices/:id/approve", handler.ApproveInvoice)
+	api.GET("/settings/invoice-matching", handler.GetInvoiceMatchSettings)
+	api.PUT("/settings/invoice-matching", handler.UpdateInvoiceMatchSettings)
+
+	return router, service, user, cleanup
+}
+
+func TestInvoiceHandler_ThreeWayMatch(t *testing.T) {
+	router, service, user, cleanup := setupInvoiceTestHandler(t)
+	defer cleanup()
+
+	milk := &models.InventoryItem{AccountID: user.AccountID, Name: "Milk", Unit: "liters"}
+	require.NoError(t, service.CreateInventoryItem(milk))
+
+	order := &models.Order{AccountID: user.AccountID, CreatedBy: user.ID, Status: "ordered"}
+	require.NoError(t, service.CreateOrder(order, []models.OrderItem{{InventoryItemID: milk.ID, Quantity: 20, UnitCost: 0.9, Vendor: "Local Dairy"}}))
+	items, err := service.GetOrderItems(order.ID)
+	require.NoError(t, err)
+	_, err = service.ReceiveOrder(order, &models.Receipt{ReceivedBy: user.ID}, []database.ReceiptLineInput{{OrderItemID: items[0].ID, Quantity: 20}})
+	require.NoError(t, err)
+
+	invoiceStatus := func(body []byte) string {
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(body, &response))
+		return response["data"].(map[string]interface{})["invoice"].(map[string]interface{})["status"].(string)
+	}
+
+	t.Run("Matched Invoice", func(t *testing.T) {
+		body := map[string]interface{}{
+			"invoice_number": "LD-1",
+			"lines":          []map[string]interface{}{{"order_item_id": items[0].ID, "quantity": 20, "unit_cost": 0.9}},
+		}
+		req, w := createAuthenticatedRequest("POST", "/api/v1/invoices", body, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, models.InvoiceStatusApproved, invoiceStatus(w.Body.Bytes()))
+
+		// The same invoice number cannot be recorded twice
+		req, w = createAuthenticatedRequest("POST", "/api/v1/invoices", body, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	var reviewID int
+	t.Run("Mismatch Listed For Review", func(t *testing.T) {
+		body := map[string]interface{}{
+			"invoice_number": "LD-2",
+			"lines":          []map[string]interface{}{{"order_item_id": items[0].ID, "quantity": 20, "unit_cost": 0.9}},
+		}
+		req, w := createAuthenticatedRequest("POST", "/api/v1/invoices", body, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, models.InvoiceStatusNeedsReview, invoiceStatus(w.Body.Bytes()), "the goods were already billed")
+
+		req, w = createAuthenticatedRequest("GET", "/api/v1/invoices?status=needs_review", nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		invoices := response["data"].([]interface{})
+		require.Len(t, invoices, 1)
+		invoice := invoices[0].(map[string]interface{})
+		assert.Equal(t, "LD-2", invoice["invoice_number"])
+		reviewID = int(invoice["id"].(float64))
+
+		req, w = createAuthenticatedRequest("GET", "/api/v1/invoices?status=paid", nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Rematch And Approve", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("POST", fmt.Sprintf("/api/v1/invoices/%d/match", reviewID), nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, models.InvoiceStatusNeedsReview, invoiceStatus(w.Body.Bytes()))
+
+		req, w = createAuthenticatedRequest("POST", fmt.Sprintf("/api/v1/invoices/%d/approve", reviewID), nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		req, w = createAuthenticatedRequest("POST", fmt.Sprintf("/api/v1/invoices/%d/approve", reviewID), nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		req, w = createAuthenticatedRequest("GET", fmt.Sprintf("/api/v1/invoices/%d", reviewID), nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		invoice := response["data"].(map[string]interface{})
+		assert.Equal(t, models.InvoiceStatusApproved, invoice["status"])
+		assert.Equal(t, float64(user.ID), invoice["approved_by"])
+		assert.Len(t, invoice["lines"], 1)
+	})
+
+	t.Run("Invalid Invoice", func(t *testing.T) {
+		body := map[string]interface{}{"invoice_number": "LD-3", "lines": []map[string]interface{}{{"description": "Freight", "quantity": 1, "unit_cost": 5}}}
+		req, w := createAuthenticatedRequest("POST", "/api/v1/invoices", body, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, "a vendor is required when no order item is billed")
+
+		req, w = createAuthenticatedRequest("POST", "/api/v1/invoices", map[string]interface{}{"invoice_number": "LD-4"}, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Match Settings", func(t *testing.T) {
+		body := map[string]interface{}{"quantity_tolerance_pct": 5, "price_tolerance_pct": 3}
+		req, w := createAuthenticatedRequest("PUT", "/api/v1/settings/invoice-matching", body, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		req, w = createAuthenticatedRequest("GET", "/api/v1/settings/invoice-matching", nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		settings := response["data"].(map[string]interface{})
+		assert.Equal(t, 5.0, settings["quantity_tolerance_pct"])
+		assert.Equal(t, 3.0, settings["price_tolerance_pct"])
+
+		body = map[string]interface{}{"quantity_tolerance_pct": -5, "price_tolerance_pct": 3}
+		req, w = createAuthenticatedRequest("PUT", "/api/v1/settings/invoice-matching", body, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,165 @@
+// Package handlers provides HTTP request handlers for the application's API endpoints.
+// This package includes handlers for inventory lot and expiration tracking operations.
+// All handlers require authentication and operate within the context of user accounts.
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	helpers "github.com/mnadev/pantryos/internal/api/helper"
+
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LotHandler handles HTTP requests related to inventory lots.
+// Lots are created from deliveries and track the received quantity,
+// the quantity still on hand, and the expiration date of perishable stock.
+type LotHandler struct {
+	// service provides access to the business logic layer for database operations
+	service *database.Service
+}
+
+// NewLotHandler creates a new LotHandler instance with the provided database connection.
+// This function initializes the handler with a database service that handles all
+// business logic and data access operations.
+//
+// Parameters:
+//   - db: The database connection to use for operations
+//
+// Returns:
+//   - *LotHandler: A new handler instance ready to handle HTTP requests
+func NewLotHandler(db *database.DB) *LotHandler {
+	return &LotHandler{service: database.NewService(db)}
+}
+
+// GetItemLots retrieves every lot of an inventory item, oldest first.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: The item must belong to the user's account
+//
+// Response:
+//
+//	All responses are wrapped in the standard APIResponse structure.
+//	- Success: { "success": true, "message": "...", "data": [...] }
+//	- Error:   { "success": false, "message": "...", "error": { "code": "...", "details": "..." } }
+//
+// Status Codes:
+//   - 200 OK: Lots retrieved successfully. The 'data' field contains a list of lots.
+//   - 400 Bad Request: Invalid item ID.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The item does not belong to the user's account.
+//   - 404 Not Found: The user or the item could not be found.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *LotHandler) GetItemLots(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	// Parse and validate the item ID from the URL parameter
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: "Item ID must be a valid integer."}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid Item ID.", errDetails)
+		return
+	}
+
+	item, err := h.service.GetInventoryItem(id)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "ITEM_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "Inventory item not found.", errDetails)
+		return
+	}
+
+	// Authorization check: Ensure the item belongs to the user's account
+	if item.AccountID != user.AccountID {
+		errDetails := helpers.APIError{Code: "FORBIDDEN", Details: "You do not have permission to access this item."}
+		helpers.Error(c.Writer, http.StatusForbidden, "Access denied.", errDetails)
+		return
+	}
+
+	lots, err := h.service.GetLotsByItem(item.ID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to fetch inventory lots.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the list of lots in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Inventory lots retrieved successfully.", lots)
+}
+
+// GetExpiringLots retrieves the lots in the user's account that still hold stock
+// and expire soon. Lots that have already expired are included.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Query Parameters:
+//   - days: How many days ahead to look (optional, defaults to 3)
+//
+// Response:
+//
+//	All responses are wrapped in the standard APIResponse structure.
+//	- Success: { "success": true, "message": "...", "data": [...] }
+//	- Error:   { "success": false, "message": "...", "error": { "code": "...", "details": "..." } }
+//
+// Status Codes:
+//   - 200 OK: Lots retrieved successfully. The 'data' field contains a list of expiring lots.
+//   - 400 Bad Request: Invalid days parameter.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *LotHandler) GetExpiringLots(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	days := database.ExpiringSoonDays
+	if value := c.Query("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: "days must be a non-negative integer."}
+			helpers.Error(c.Writer, http.StatusBadRequest, "Invalid days parameter.", errDetails)
+			return
+		}
+		days = parsed
+	}
+
+	lots, err := h.service.GetExpiringLots(user.AccountID, days)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to fetch expiring lots.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the list of expiring lots in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Expiring lots retrieved successfully.", lots)
+}
+
+// getUser resolves the authenticated user, writing the error response and
+// returning false when the user is not authenticated or does not exist.
+func (h *LotHandler) getUser(c *gin.Context) (*models.User, bool) {
+	// Extract user ID from context (set by AuthMiddleware)
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		errDetails := helpers.APIError{Code: "UNAUTHORIZED", Details: "User ID not found in request context."}
+		helpers.Error(c.Writer, http.StatusUnauthorized, "User not authenticated.", errDetails)
+		return nil, false
+	}
+	userID := userIDInterface.(int)
+
+	// Retrieve user details from database to get the AccountID
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "USER_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "User not found.", errDetails)
+		return nil, false
+	}
+	return user, true
+}
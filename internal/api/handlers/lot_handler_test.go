@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupLotTestHandler(t *testing.T) (*gin.Engine, *database.Service, *models.User, func()) {
+	router, service, user, cleanup := setupAuthenticatedTestRouter(t, "lots@example.com")
+	lotHandler := NewLotHandler(service.DB())
+	inventoryHandler := NewInventoryHandler(service.DB())
+
+	api := router.Group("/api/v1")
+	api.GET("/inventory/items/:id/lots", lotHandler.GetItemLots)
+	api.GET("/inventory/lots/expiring", lotHandler.GetExpiringLots)
+	api.POST("/deliveries", inventoryHandler.LogDelivery)
+
+	return router, service, user, cleanup
+}
+
+func TestLotHandler_LotsAndExpiring(t *testing.T) {
+	router, service, user, cleanup := setupLotTestHandler(t)
+	defer cleanup()
+
+	item := &models.InventoryItem{
+		AccountID:     user.AccountID,
+		Name:          "Yogurt",
+		Unit:          "cups",
+		ShelfLifeDays: 10,
+	}
+	require.NoError(t, service.CreateInventoryItem(item))
+
+	expiration := time.Now().AddDate(0, 0, 2).Format(time.RFC3339)
+
+	t.Run("Log Delivery With Lot", func(t *testing.T) {
+		deliveryData := map[string]interface{}{
+			"inventory_item_id": item.ID,
+			"vendor":            "Local Dairy",
+			"quantity":          24,
+			"delivery_date":     time.Now().Format(time.RFC3339),
+			"cost":              36.0,
+			"lot_number":        "YOG-42",
+			"expiration_date":   expiration,
+		}
+		req, w := createAuthenticatedRequest("POST", "/api/v1/deliveries", deliveryData, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		// A second delivery uses the item's ten day shelf life
+		deliveryData = map[string]interface{}{
+			"inventory_item_id": item.ID,
+			"vendor":            "Local Dairy",
+			"quantity":          12,
+			"delivery_date":     time.Now().Format(time.RFC3339),
+			"cost":              18.0,
+		}
+		req, w = createAuthenticatedRequest("POST", "/api/v1/deliveries", deliveryData, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("Get Item Lots", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", fmt.Sprintf("/api/v1/inventory/items/%d/lots", item.ID), nil, user.ID)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		assert.Len(t, response["data"].([]interface{}), 2)
+	})
+
+	t.Run("Get Expiring Lots", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/inventory/lots/expiring", nil, user.ID)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		lots := response["data"].([]interface{})
+		require.Len(t, lots, 1)
+		lot := lots[0].(map[string]interface{})
+		assert.Equal(t, "YOG-42", lot["lot_number"])
+		assert.Equal(t, "Yogurt", lot["item_name"])
+		assert.Equal(t, float64(2), lot["days_until_expiry"])
+
+		req, w = createAuthenticatedRequest("GET", "/api/v1/inventory/lots/expiring?days=14", nil, user.ID)
+		router.ServeHTTP(w, req)
+
+		err = json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		assert.Len(t, response["data"].([]interface{}), 2)
+	})
+
+	t.Run("Invalid Days", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/inventory/lots/expiring?days=-1", nil, user.ID)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
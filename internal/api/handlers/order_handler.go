@@ -0,0 +1,414 @@
+// Package handlers provides HTTP request handlers for the application's API endpoints.
+// This package includes handlers for sending purchase orders to vendors and receiving the goods.
+// All handlers require authentication and operate within the context of user accounts.
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	helpers "github.com/mnadev/pantryos/internal/api/helper"
+
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/email"
+	"github.com/mnadev/pantryos/internal/models"
+	"github.com/mnadev/pantryos/internal/pdf"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrderHandler handles HTTP requests related to purchase orders.
+// It emails each vendor on an order its part of the order, records every
+// purchase order sent, and checks goods received against the order.
+type OrderHandler struct {
+	// service provides access to the business logic layer for database operations
+	service *database.Service
+	// emailService renders and sends purchase order emails
+	emailService *email.EmailService
+}
+
+// SendOrderRequest represents the optional request body for sending an order to its vendors
+type SendOrderRequest struct {
+	ReplyToAccount *bool `json:"reply_to_account"` // Send vendor replies to the account's email; defaults to true
+}
+
+// SendOrderResponse describes the outcome of sending an order to its vendors
+type SendOrderResponse struct {
+	Order      models.Order           `json:"order"`
+	Dispatches []models.OrderDispatch `json:"dispatches"`
+	Sent       int                    `json:"sent"`
+	Failed     int                    `json:"failed"`
+}
+
+// ReceiveOrderRequest represents the request body for receiving goods against an order
+type ReceiveOrderRequest struct {
+	ReceivedAt *time.Time                  `json:"received_at"` // Defaults to now
+	Notes      string                      `json:"notes"`
+	Lines      []database.ReceiptLineInput `json:"lines" binding:"required,min=1"`
+}
+
+// NewOrderHandler creates a new OrderHandler instance with the provided database connection.
+// The handler's email service renders the account's template overrides.
+//
+// Parameters:
+//   - db: The database connection to use for operations
+//
+// Returns:
+//   - *OrderHandler: A new handler instance ready to handle HTTP requests
+func NewOrderHandler(db *database.DB) *OrderHandler {
+	service := database.NewService(db)
+	emailService := email.NewEmailService()
+	emailService.SetTemplateStore(service)
+	return &OrderHandler{service: service, emailService: emailService}
+}
+
+// SendOrder emails each vendor on an order its purchase order, with the purchase order
+// attached as a PDF, and marks the order as ordered once any vendor was sent its part.
+// This is how an order is placed: orders cannot be updated to ordered without being sent.
+// Vendors without an email address, and items ordered without a vendor, are recorded as
+// failed so they can be ordered by other means.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: The order must belong to the user's account
+//
+// Path Parameters:
+//   - id: The order ID (int, required)
+//
+// Request Body: Optional JSON object
+//   - reply_to_account: Whether vendor replies go to the account's email (bool, defaults to true)
+//
+// Response:
+//
+//	All responses are wrapped in the standard APIResponse structure.
+//	- Success: { "success": true, "message": "...", "data": { "order": {...}, "dispatches": [...], "sent": 1, "failed": 0 } }
+//	- Error:   { "success": false, "message": "...", "error": { "code": "...", "details": "..." } }
+//
+// Status Codes:
+//   - 200 OK: Every purchase order was sent.
+//   - 207 Multi-Status: Some purchase orders could not be sent; see the dispatches.
+//   - 400 Bad Request: Invalid order ID or request body.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: The order does not exist or belongs to another account.
+//   - 409 Conflict: The order was received or cancelled.
+//   - 502 Bad Gateway: No purchase order could be sent.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *OrderHandler) SendOrder(c *gin.Context) {
+	user, order, ok := h.getOwnedOrder(c)
+	if !ok {
+		return
+	}
+
+	var req SendOrderRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+			helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+			return
+		}
+	}
+
+	if err := h.service.ValidateOrderDispatch(order); err != nil {
+		errDetails := helpers.APIError{Code: "ORDER_NOT_SENDABLE", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusConflict, "Order cannot be sent.", errDetails)
+		return
+	}
+
+	account, err := h.service.GetAccount(order.AccountID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "ACCOUNT_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "Account not found.", errDetails)
+		return
+	}
+
+	purchaseOrders, err := h.service.GetPurchaseOrders(order.ID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to fetch purchase orders.", errDetails)
+		return
+	}
+
+	replyTo := ""
+	if req.ReplyToAccount == nil || *req.ReplyToAccount {
+		replyTo = account.Email
+	}
+
+	response := SendOrderResponse{Dispatches: make([]models.OrderDispatch, 0, len(purchaseOrders))}
+	for _, purchaseOrder := range purchaseOrders {
+		dispatch := h.sendPurchaseOrder(*account, purchaseOrder, replyTo)
+		dispatch.SentBy = user.ID
+		if dispatch.Status == models.EmailStatusSent {
+			response.Sent++
+		} else {
+			response.Failed++
+		}
+		response.Dispatches = append(response.Dispatches, dispatch)
+	}
+
+	if err := h.service.RecordOrderDispatches(order, response.Dispatches); err != nil {
+		errDetails := helpers.APIError{Code: "DB_SAVE_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to record sent purchase orders.", errDetails)
+		return
+	}
+	response.Order = *order
+
+	switch {
+	case response.Failed == 0:
+		helpers.Success(c.Writer, http.StatusOK, "Purchase orders sent successfully.", response)
+	case response.Sent > 0:
+		helpers.Success(c.Writer, http.StatusMultiStatus, "Some purchase orders could not be sent.", response)
+	default:
+		errDetails := helpers.APIError{Code: "EMAIL_SEND_FAILED", Details: "No purchase order could be sent; see the order's dispatches."}
+		helpers.Error(c.Writer, http.StatusBadGateway, "Failed to send purchase orders.", errDetails)
+	}
+}
+
+// GetOrderDispatches lists the purchase orders of an order that were sent to vendors,
+// including the ones that failed.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: The order must belong to the user's account
+//
+// Path Parameters:
+//   - id: The order ID (int, required)
+//
+// Response:
+//
+//	All responses are wrapped in the standard APIResponse structure.
+//	- Success: { "success": true, "message": "...", "data": [...] }
+//	- Error:   { "success": false, "message": "...", "error": { "code": "...", "details": "..." } }
+//
+// Status Codes:
+//   - 200 OK: Dispatches retrieved successfully.
+//   - 400 Bad Request: Invalid order ID.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: The order does not exist or belongs to another account.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *OrderHandler) GetOrderDispatches(c *gin.Context) {
+	_, order, ok := h.getOwnedOrder(c)
+	if !ok {
+		return
+	}
+
+	dispatches, err := h.service.GetOrderDispatches(order.ID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to fetch order dispatches.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the dispatches in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Order dispatches retrieved successfully.", dispatches)
+}
+
+// ReceiveOrder records goods that arrived against an order. Each line gives the quantity
+// received and, when it differs from the order, the unit cost charged or the item that
+// arrived in its place. The goods are booked as deliveries, and the response reports
+// shorts, overs, substitutions, and price discrepancies. The order is marked delivered
+// once every item was received in full, and partially received otherwise.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: The order must belong to the user's account
+//
+// Path Parameters:
+//   - id: The order ID (int, required)
+//
+// Request Body: JSON object
+//   - received_at: When the goods arrived (RFC 3339 timestamp, defaults to now)
+//   - notes: Notes on the receipt (string, optional)
+//   - lines: What arrived for each order item checked in (array, required), each with
+//     order_item_id, quantity, and optionally unit_cost, substitute_item_id, lot_number,
+//     expiration_date, storage_location_id, and notes
+//
+// Response:
+//
+//	All responses are wrapped in the standard APIResponse structure.
+//	- Success: { "success": true, "message": "...", "data": { "receipt": {...}, "order": {...}, "total_cost": 12.5, "discrepancies": [...], "outstanding": [...] } }
+//	- Error:   { "success": false, "message": "...", "error": { "code": "...", "details": "..." } }
+//
+// Status Codes:
+//   - 201 Created: Receipt recorded successfully.
+//   - 400 Bad Request: Invalid order ID or receipt lines.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: The order does not exist or belongs to another account.
+//   - 409 Conflict: The order was not approved yet, or was already delivered or cancelled.
+func (h *OrderHandler) ReceiveOrder(c *gin.Context) {
+	user, order, ok := h.getOwnedOrder(c)
+	if !ok {
+		return
+	}
+
+	var req ReceiveOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+		return
+	}
+
+	receipt := &models.Receipt{ReceivedBy: user.ID, Notes: req.Notes}
+	if req.ReceivedAt != nil {
+		receipt.ReceivedAt = *req.ReceivedAt
+	}
+
+	report, err := h.service.ReceiveOrder(order, receipt, req.Lines)
+	if errors.Is(err, database.ErrOrderNotReceivable) {
+		errDetails := helpers.APIError{Code: "ORDER_NOT_RECEIVABLE", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusConflict, "Order cannot be received.", errDetails)
+		return
+	}
+	if err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_RECEIPT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to receive order.", errDetails)
+		return
+	}
+
+	// Return a 201 Created response with the receipt report in the data field.
+	helpers.Success(c.Writer, http.StatusCreated, "Order received successfully.", report)
+}
+
+// GetOrderReceipts lists the receipts of an order, each with its discrepancies.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: The order must belong to the user's account
+//
+// Path Parameters:
+//   - id: The order ID (int, required)
+//
+// Response:
+//
+//	All responses are wrapped in the standard APIResponse structure.
+//	- Success: { "success": true, "message": "...", "data": [...] }
+//	- Error:   { "success": false, "message": "...", "error": { "code": "...", "details": "..." } }
+//
+// Status Codes:
+//   - 200 OK: Receipts retrieved successfully.
+//   - 400 Bad Request: Invalid order ID.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: The order does not exist or belongs to another account.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *OrderHandler) GetOrderReceipts(c *gin.Context) {
+	_, order, ok := h.getOwnedOrder(c)
+	if !ok {
+		return
+	}
+
+	receipts, err := h.service.GetOrderReceipts(order.ID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to fetch order receipts.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the receipts in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Order receipts retrieved successfully.", receipts)
+}
+
+// sendPurchaseOrder emails a purchase order to its vendor, returning the record of the attempt.
+func (h *OrderHandler) sendPurchaseOrder(account models.Account, purchaseOrder database.PurchaseOrder, replyTo string) models.OrderDispatch {
+	dispatch := models.OrderDispatch{Number: purchaseOrder.Number, ReplyTo: replyTo, Status: models.EmailStatusFailed}
+	if purchaseOrder.Vendor == nil {
+		dispatch.Vendor = "No vendor"
+		dispatch.ErrorMsg = "Items ordered without a vendor cannot be sent."
+		return dispatch
+	}
+	dispatch.VendorID = &purchaseOrder.Vendor.ID
+	dispatch.Vendor = purchaseOrder.Vendor.Name
+	dispatch.ToEmail = purchaseOrder.Vendor.Email
+	if dispatch.ToEmail == "" {
+		dispatch.ErrorMsg = fmt.Sprintf("Vendor %s has no email address.", purchaseOrder.Vendor.Name)
+		return dispatch
+	}
+
+	document, err := pdf.PurchaseOrders([]database.PurchaseOrder{purchaseOrder})
+	if err != nil {
+		dispatch.ErrorMsg = "Failed to render PDF: " + err.Error()
+		return dispatch
+	}
+	attachment := email.Attachment{Filename: "purchase-order-" + purchaseOrder.Number + ".pdf", ContentType: pdf.ContentType, Data: document}
+
+	dispatch.Subject, err = h.emailService.SendPurchaseOrder(account, dispatch.ToEmail, replyTo, purchaseOrderEmailData(purchaseOrder), attachment)
+	if err != nil {
+		dispatch.ErrorMsg = err.Error()
+		return dispatch
+	}
+	dispatch.Status = models.EmailStatusSent
+	return dispatch
+}
+
+// purchaseOrderEmailData converts a purchase order into the data of a purchase order email.
+func purchaseOrderEmailData(purchaseOrder database.PurchaseOrder) *email.PurchaseOrderData {
+	data := &email.PurchaseOrderData{
+		Number:          purchaseOrder.Number,
+		OrderDate:       purchaseOrder.Order.OrderDate,
+		ExpectedDate:    purchaseOrder.Order.ExpectedDate,
+		DeliveryAddress: purchaseOrder.Account.Location,
+		Notes:           purchaseOrder.Order.Notes,
+		Total:           purchaseOrder.Total,
+	}
+	if purchaseOrder.Vendor != nil {
+		data.VendorName = purchaseOrder.Vendor.Name
+		data.ContactName = purchaseOrder.Vendor.ContactName
+	}
+	for _, line := range purchaseOrder.Lines {
+		data.Lines = append(data.Lines, email.PurchaseOrderLineData{
+			ItemName:  line.ItemName,
+			Unit:      line.Unit,
+			Quantity:  line.Quantity,
+			UnitCost:  line.UnitCost,
+			TotalCost: line.TotalCost,
+			Notes:     line.Notes,
+		})
+	}
+	return data
+}
+
+// getUser resolves the authenticated user, writing the error response and
+// returning false when the user is not authenticated or does not exist.
+func (h *OrderHandler) getUser(c *gin.Context) (*models.User, bool) {
+	// Extract user ID from context (set by AuthMiddleware)
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		errDetails := helpers.APIError{Code: "UNAUTHORIZED", Details: "User ID not found in request context."}
+		helpers.Error(c.Writer, http.StatusUnauthorized, "User not authenticated.", errDetails)
+		return nil, false
+	}
+	userID := userIDInterface.(int)
+
+	// Retrieve user details from database to get the AccountID
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "USER_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "User not found.", errDetails)
+		return nil, false
+	}
+	return user, true
+}
+
+// getOwnedOrder resolves the authenticated user and the order named by the ":id"
+// URL parameter, writing the error response and returning false when the order
+// does not exist or belongs to another account.
+func (h *OrderHandler) getOwnedOrder(c *gin.Context) (*models.User, *models.Order, bool) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return nil, nil, false
+	}
+
+	// Parse and validate the order ID from the URL parameter
+	orderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: "Order ID must be a valid integer."}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid Order ID.", errDetails)
+		return nil, nil, false
+	}
+
+	order, err := h.service.GetOrder(orderID)
+	if err != nil || order.AccountID != user.AccountID {
+		errDetails := helpers.APIError{Code: "ORDER_NOT_FOUND", Details: fmt.Sprintf("Order with ID %d not found.", orderID)}
+		helpers.Error(c.Writer, http.StatusNotFound, "Order not found.", errDetails)
+		return nil, nil, false
+	}
+
+	return user, order, true
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupOrderTestHandler(t *testing.T) (*gin.Engine, *database.Service, *models.User, func()) {
+	router, service, user, cleanup := setupAuthenticatedTestRouter(t, "orders@example.com")
+	account, err := service.GetAccount(user.AccountID)
+	require.NoError(t, err)
+	account.Email = "owner@testshop.example.com"
+	require.NoError(t, service.UpdateAccount(account))
+	handler := NewOrderHandler(service.DB())
+
+	api := router.Group("/api/v1")
+	api.POST("/orders/:id/send", handler.SendOrder)
+	api.GET("/orders/:id/dispatches", handler.GetOrderDispatches)
+	api.POST("/orders/:id/receive", handler.ReceiveOrder)
+	api.GET("/orders/:id/receipts", handler.GetOrderReceipts)
+
+	return router, service, user, cleanup
+}
+
+func TestOrderHandler_SendOrder(t *testing.T) {
+	router, service, user, cleanup := setupOrderTestHandler(t)
+	defer cleanup()
+
+	dairy := &models.Vendor{AccountID: user.AccountID, Name: "Local Dairy", Email: "orders@dairy.example.com"}
+	require.NoError(t, service.CreateVendor(dairy))
+	milk := &models.InventoryItem{AccountID: user.AccountID, Name: "Milk", Unit: "liters"}
+	require.NoError(t, service.CreateInventoryItem(milk))
+	flour := &models.InventoryItem{AccountID: user.AccountID, Name: "Flour", Unit: "kg"}
+	require.NoError(t, service.CreateInventoryItem(flour))
+
+	order := &models.Order{AccountID: user.AccountID, CreatedBy: user.ID, Status: "approved"}
+	require.NoError(t, service.CreateOrder(order, []models.OrderItem{
+		{InventoryItemID: milk.ID, Quantity: 20, UnitCost: 0.9, VendorID: &dairy.ID},
+		{InventoryItemID: flour.ID, Quantity: 10, UnitCost: 1.2, Vendor: "Mill Co"},
+	}))
+
+	t.Run("Failed Sends Are Recorded", func(t *testing.T) {
+		// SMTP is not configured in tests, and Mill Co has no email address
+		body := map[string]interface{}{"reply_to_account": true}
+		req, w := createAuthenticatedRequest("POST", fmt.Sprintf("/api/v1/orders/%d/send", order.ID), body, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadGateway, w.Code)
+
+		req, w = createAuthenticatedRequest("GET", fmt.Sprintf("/api/v1/orders/%d/dispatches", order.ID), nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		dispatches := response["data"].([]interface{})
+		require.Len(t, dispatches, 2)
+
+		first := dispatches[0].(map[string]interface{})
+		assert.Equal(t, "Local Dairy", first["vendor"])
+		assert.Equal(t, "orders@dairy.example.com", first["to_email"])
+		assert.Equal(t, "owner@testshop.example.com", first["reply_to"])
+		assert.Equal(t, models.EmailStatusFailed, first["status"])
+		assert.Contains(t, first["error_msg"], "SMTP")
+
+		second := dispatches[1].(map[string]interface{})
+		assert.Equal(t, "Mill Co", second["vendor"])
+		assert.Contains(t, second["error_msg"], "no email address")
+
+		// Nothing was sent, so the order was not placed
+		stored, err := service.GetOrder(order.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "approved", stored.Status)
+		assert.Nil(t, stored.DispatchedAt)
+	})
+
+	t.Run("Delivered Order", func(t *testing.T) {
+		delivered := &models.Order{AccountID: user.AccountID, CreatedBy: user.ID, Status: "delivered"}
+		require.NoError(t, service.CreateOrder(delivered, []models.OrderItem{{InventoryItemID: milk.ID, Quantity: 1, UnitCost: 0.9, VendorID: &dairy.ID}}))
+
+		req, w := createAuthenticatedRequest("POST", fmt.Sprintf("/api/v1/orders/%d/send", delivered.ID), nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Order Of Another Account", func(t *testing.T) {
+		other := &models.Account{Name: "Other Shop", Status: "active"}
+		require.NoError(t, service.CreateAccount(other))
+		otherItem := &models.InventoryItem{AccountID: other.ID, Name: "Sugar", Unit: "kg"}
+		require.NoError(t, service.CreateInventoryItem(otherItem))
+		otherOrder := &models.Order{AccountID: other.ID, CreatedBy: user.ID}
+		require.NoError(t, service.CreateOrder(otherOrder, []models.OrderItem{{InventoryItemID: otherItem.ID, Quantity: 1, UnitCost: 1}}))
+
+		req, w := createAuthenticatedRequest("POST", fmt.Sprintf("/api/v1/orders/%d/send", otherOrder.ID), nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestOrderHandler_ReceiveOrder(t *testing.T) {
+	router, service, user, cleanup := setupOrderTestHandler(t)
+	defer cleanup()
+
+	milk := &models.InventoryItem{AccountID: user.AccountID, Name: "Milk", Unit: "liters"}
+	require.NoError(t, service.CreateInventoryItem(milk))
+	flour := &models.InventoryItem{AccountID: user.AccountID, Name: "Flour", Unit: "kg"}
+	require.NoError(t, service.CreateInventoryItem(flour))
+
+	order := &models.Order{AccountID: user.AccountID, CreatedBy: user.ID, Status: "ordered"}
+	require.NoError(t, service.CreateOrder(order, []models.OrderItem{
+		{InventoryItemID: milk.ID, Quantity: 20, UnitCost: 0.9, Vendor: "Local Dairy"},
+		{InventoryItemID: flour.ID, Quantity: 10, UnitCost: 1.2, Vendor: "Mill Co"},
+	}))
+	items, err := service.GetOrderItems(order.ID)
+	require.NoError(t, err)
+
+	t.Run("Partial Receipt", func(t *testing.T) {
+		body := map[string]interface{}{
+			"notes": "Morning drop",
+			"lines": []map[string]interface{}{
+				{"order_item_id": items[0].ID, "quantity": 18, "unit_cost": 0.95},
+			},
+		}
+		req, w := createAuthenticatedRequest("POST", fmt.Sprintf("/api/v1/orders/%d/receive", order.ID), body, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, "partially_received", data["order"].(map[string]interface{})["status"])
+		assert.Len(t, data["outstanding"], 2)
+
+		var types []string
+		for _, discrepancy := range data["discrepancies"].([]interface{}) {
+			types = append(types, discrepancy.(map[string]interface{})["type"].(string))
+		}
+		assert.ElementsMatch(t, []string{"short", "price"}, types)
+
+		stored, err := service.GetOrder(order.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "partially_received", stored.Status)
+	})
+
+	t.Run("Invalid Line", func(t *testing.T) {
+		body := map[string]interface{}{"lines": []map[string]interface{}{{"order_item_id": items[1].ID, "quantity": -2}}}
+		req, w := createAuthenticatedRequest("POST", fmt.Sprintf("/api/v1/orders/%d/receive", order.ID), body, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		req, w = createAuthenticatedRequest("POST", fmt.Sprintf("/api/v1/orders/%d/receive", order.ID), map[string]interface{}{}, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Pending Order", func(t *testing.T) {
+		pending := &models.Order{AccountID: user.AccountID, CreatedBy: user.ID}
+		require.NoError(t, service.CreateOrder(pending, []models.OrderItem{{InventoryItemID: milk.ID, Quantity: 1, UnitCost: 0.9}}))
+		pendingItems, err := service.GetOrderItems(pending.ID)
+		require.NoError(t, err)
+
+		body := map[string]interface{}{"lines": []map[string]interface{}{{"order_item_id": pendingItems[0].ID, "quantity": 1}}}
+		req, w := createAuthenticatedRequest("POST", fmt.Sprintf("/api/v1/orders/%d/receive", pending.ID), body, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("List Receipts", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", fmt.Sprintf("/api/v1/orders/%d/receipts", order.ID), nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		receipts := response["data"].([]interface{})
+		require.Len(t, receipts, 1)
+		receipt := receipts[0].(map[string]interface{})["receipt"].(map[string]interface{})
+		assert.Equal(t, "Morning drop", receipt["notes"])
+		assert.Len(t, receipt["lines"], 1)
+	})
+}
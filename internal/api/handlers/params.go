@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	helpers "github.com/mnadev/pantryos/internal/api/helper"
+	"github.com/mnadev/pantryos/internal/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dateQueryLayout is the format accepted for date query parameters
+const dateQueryLayout = "2006-01-02"
+
+// monthQueryLayout is the format accepted for month query parameters
+const monthQueryLayout = "2006-01"
+
+// parseDateRange reads the optional start_date and end_date query parameters.
+// A missing parameter is returned as the zero time, and an end date is extended
+// to cover the whole of that day. If either value is malformed an error response
+// is written and ok is false.
+func parseDateRange(c *gin.Context) (startDate, endDate time.Time, ok bool) {
+	var err error
+	if value := c.Query("start_date"); value != "" {
+		if startDate, err = time.Parse(dateQueryLayout, value); err != nil {
+			errDetails := helpers.APIError{Code: "INVALID_DATE", Details: "start_date must be in YYYY-MM-DD format."}
+			helpers.Error(c.Writer, http.StatusBadRequest, "Invalid start date.", errDetails)
+			return time.Time{}, time.Time{}, false
+		}
+	}
+	if value := c.Query("end_date"); value != "" {
+		if endDate, err = time.Parse(dateQueryLayout, value); err != nil {
+			errDetails := helpers.APIError{Code: "INVALID_DATE", Details: "end_date must be in YYYY-MM-DD format."}
+			helpers.Error(c.Writer, http.StatusBadRequest, "Invalid end date.", errDetails)
+			return time.Time{}, time.Time{}, false
+		}
+		endDate = endDate.Add(24*time.Hour - time.Nanosecond)
+	}
+	return startDate, endDate, true
+}
+
+// parseListQuery reads the pagination, sorting and filter query parameters shared by list
+// endpoints: limit, offset, sort (a field name, prefixed with "-" for descending order),
+// search, category_id, vendor, vendor_id, inventory_item_id, start_date and end_date.
+// sortFields are the fields the endpoint can be sorted by. If a value is malformed an
+// error response is written and ok is false.
+//
+// Without a limit a page holds database.DefaultPageSize rows, so clients that want more
+// must page through the list using the pagination metadata of each response.
+func parseListQuery(c *gin.Context, sortFields []string) (list database.ListQuery, ok bool) {
+	invalid := func(param, details string) (database.ListQuery, bool) {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: details}
+		helpers.Error(c.Writer, http.StatusBadRequest, fmt.Sprintf("Invalid %s parameter.", param), errDetails)
+		return database.ListQuery{}, false
+	}
+
+	var err error
+	if raw := c.Query("limit"); raw != "" {
+		if list.Limit, err = strconv.Atoi(raw); err != nil || list.Limit < 1 || list.Limit > database.MaxPageSize {
+			return invalid("limit", fmt.Sprintf("limit must be between 1 and %d.", database.MaxPageSize))
+		}
+	}
+	if raw := c.Query("offset"); raw != "" {
+		if list.Offset, err = strconv.Atoi(raw); err != nil || list.Offset < 0 {
+			return invalid("offset", "offset must be a non-negative integer.")
+		}
+	}
+
+	if sort := c.Query("sort"); sort != "" {
+		list.Desc = strings.HasPrefix(sort, "-")
+		list.Sort = strings.TrimPrefix(sort, "-")
+		if !database.IsSortField(sortFields, list.Sort) {
+			return invalid("sort", fmt.Sprintf("sort must be one of: %s, optionally prefixed with '-'.", strings.Join(sortFields, ", ")))
+		}
+	}
+
+	parseID := func(param string) (*int, bool) {
+		raw := c.Query(param)
+		if raw == "" {
+			return nil, true
+		}
+		id, err := strconv.Atoi(raw)
+		if err != nil || id <= 0 {
+			invalid(param, fmt.Sprintf("%s must be a positive integer.", param))
+			return nil, false
+		}
+		return &id, true
+	}
+	if list.CategoryID, ok = parseID("category_id"); !ok {
+		return database.ListQuery{}, false
+	}
+	if list.VendorID, ok = parseID("vendor_id"); !ok {
+		return database.ListQuery{}, false
+	}
+	if list.InventoryItemID, ok = parseID("inventory_item_id"); !ok {
+		return database.ListQuery{}, false
+	}
+
+	list.Search = strings.TrimSpace(c.Query("search"))
+	list.Vendor = strings.TrimSpace(c.Query("vendor"))
+
+	if list.StartDate, list.EndDate, ok = parseDateRange(c); !ok {
+		return database.ListQuery{}, false
+	}
+	return list, true
+}
+
+// pagination converts the pagination metadata of a list query for the response
+func pagination(page database.Page) helpers.Pagination {
+	return helpers.Pagination{Limit: page.Limit, Offset: page.Offset, Total: page.Total, HasMore: page.HasMore}
+}
@@ -0,0 +1,379 @@
+// Package handlers provides HTTP request handlers for the application's API endpoints.
+// This package includes handlers for price history and item costing operations.
+// All handlers require authentication and operate within the context of user accounts.
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	helpers "github.com/mnadev/pantryos/internal/api/helper"
+
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PriceHandler handles HTTP requests related to item prices and costing.
+// It exposes the price history recorded from deliveries, the account's
+// costing settings, and the alerts raised when vendor prices change.
+type PriceHandler struct {
+	// service provides access to the business logic layer for database operations
+	service *database.Service
+}
+
+// CostSettingsRequest represents the request body for updating an account's costing settings
+type CostSettingsRequest struct {
+	CostingMethod string  `json:"costing_method" binding:"required"` // "last", "moving_average", "fifo"
+	PriceAlertPct float64 `json:"price_alert_pct"`                   // 0 disables price alerts
+}
+
+// RecomputeCostRequest represents the optional request body for recomputing an item's cost
+type RecomputeCostRequest struct {
+	CostingMethod string `json:"costing_method"` // Defaults to the account's costing method
+}
+
+// NewPriceHandler creates a new PriceHandler instance with the provided database connection.
+// This function initializes the handler with a database service that handles all
+// business logic and data access operations.
+//
+// Parameters:
+//   - db: The database connection to use for operations
+//
+// Returns:
+//   - *PriceHandler: A new handler instance ready to handle HTTP requests
+func NewPriceHandler(db *database.DB) *PriceHandler {
+	return &PriceHandler{service: database.NewService(db)}
+}
+
+// GetPriceHistory returns the price trend of an inventory item for charting.
+// The response contains every recorded price point in the period along with
+// the minimum, maximum, weighted average, and latest price.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: The item must belong to the user's account
+//
+// Query Parameters:
+//   - start_date: First day of the period, YYYY-MM-DD (optional)
+//   - end_date: Last day of the period, YYYY-MM-DD (optional)
+//
+// Response:
+//
+//	All responses are wrapped in the standard APIResponse structure.
+//	- Success: { "success": true, "message": "...", "data": ... }
+//	- Error:   { "success": false, "message": "...", "error": { "code": "...", "details": "..." } }
+//
+// Status Codes:
+//   - 200 OK: Price trend retrieved successfully.
+//   - 400 Bad Request: Invalid item ID or date parameters.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The item does not belong to the user's account.
+//   - 404 Not Found: The user or the item could not be found.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *PriceHandler) GetPriceHistory(c *gin.Context) {
+	_, item, ok := h.getOwnedItem(c)
+	if !ok {
+		return
+	}
+
+	startDate, endDate, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	trend, err := h.service.GetPriceTrend(item.ID, startDate, endDate)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to fetch price history.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the price trend in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Price history retrieved successfully.", trend)
+}
+
+// RecomputeItemCost recalculates an inventory item's unit cost from its price history.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: The item must belong to the user's account
+//
+// Request Body: Optional JSON object
+//   - costing_method: "last", "moving_average", or "fifo" (string, defaults to the account's method)
+//
+// Response:
+//
+//	All responses are wrapped in the standard APIResponse structure.
+//	- Success: { "success": true, "message": "...", "data": ... }
+//	- Error:   { "success": false, "message": "...", "error": { "code": "...", "details": "..." } }
+//
+// Status Codes:
+//   - 200 OK: Cost recomputed successfully. The 'data' field contains the updated item.
+//   - 400 Bad Request: Invalid item ID, request body, or costing method.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The item does not belong to the user's account.
+//   - 404 Not Found: The user or the item could not be found.
+func (h *PriceHandler) RecomputeItemCost(c *gin.Context) {
+	_, item, ok := h.getOwnedItem(c)
+	if !ok {
+		return
+	}
+
+	var req RecomputeCostRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+			helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+			return
+		}
+	}
+
+	updated, err := h.service.RecomputeItemCost(item.ID, req.CostingMethod)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "VALIDATION_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to recompute item cost.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the updated item in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Item cost recomputed successfully.", updated)
+}
+
+// GetPriceAlerts retrieves the vendor price alerts for the authenticated user's account.
+// Alerts are returned newest first.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Response:
+//
+//	All responses are wrapped in the standard APIResponse structure.
+//	- Success: { "success": true, "message": "...", "data": [...] }
+//	- Error:   { "success": false, "message": "...", "error": { "code": "...", "details": "..." } }
+//
+// Status Codes:
+//   - 200 OK: Alerts retrieved successfully. The 'data' field contains a list of alerts.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *PriceHandler) GetPriceAlerts(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	alerts, err := h.service.GetPriceAlertsByAccount(user.AccountID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to fetch price alerts.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the list of alerts in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Price alerts retrieved successfully.", alerts)
+}
+
+// GetCostSettings retrieves the costing method and price alert threshold of the
+// authenticated user's account.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Status Codes:
+//   - 200 OK: Settings retrieved successfully.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: The user or the account could not be found.
+func (h *PriceHandler) GetCostSettings(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	account, err := h.service.GetAccount(user.AccountID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "ACCOUNT_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "Account not found.", errDetails)
+		return
+	}
+
+	costingMethod := account.CostingMethod
+	if costingMethod == "" {
+		costingMethod = models.CostingMethodLast
+	}
+
+	// Return a 200 OK response with the settings in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Cost settings retrieved successfully.", CostSettingsRequest{
+		CostingMethod: costingMethod,
+		PriceAlertPct: account.PriceAlertPct,
+	})
+}
+
+// UpdateCostSettings changes the costing method and price alert threshold of the
+// authenticated user's account. All item costs are recomputed with the new method.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Request Body: JSON object
+//   - costing_method: "last", "moving_average", or "fifo" (string, required)
+//   - price_alert_pct: Price change percentage that raises an alert (float64, 0 disables)
+//
+// Status Codes:
+//   - 200 OK: Settings updated successfully. The 'data' field contains the updated account.
+//   - 400 Bad Request: Invalid request body or settings.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+func (h *PriceHandler) UpdateCostSettings(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	var req CostSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+		return
+	}
+
+	account, err := h.service.UpdateCostSettings(user.AccountID, req.CostingMethod, req.PriceAlertPct)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "VALIDATION_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to update cost settings.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the updated account in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Cost settings updated successfully.", account)
+}
+
+// GetInventoryValuation values the authenticated user's inventory at the end of an
+// accounting period and reports the cost of goods sold (opening value plus purchases
+// less closing value). Counts are priced with the delivery costs recorded up to the
+// time they were taken, so past months keep the value they had at month end.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Query Parameters:
+//   - month: The calendar month to report, YYYY-MM (optional, defaults to last month)
+//   - start_date: First day of the period, YYYY-MM-DD (optional, used instead of month)
+//   - end_date: Last day of the period, YYYY-MM-DD (optional, used instead of month)
+//   - method: "fifo" or "weighted_average" (optional, defaults from the account's costing method)
+//
+// Response:
+//
+//	All responses are wrapped in the standard APIResponse structure.
+//	- Success: { "success": true, "message": "...", "data": {...} }
+//	- Error:   { "success": false, "message": "...", "error": { "code": "...", "details": "..." } }
+//
+// Status Codes:
+//   - 200 OK: Valuation generated successfully. The 'data' field contains the valuation.
+//   - 400 Bad Request: Invalid period or method.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: The user was not found, or no inventory snapshot was taken during the period.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *PriceHandler) GetInventoryValuation(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	startDate, endDate, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+	if month := c.Query("month"); month != "" {
+		first, err := time.Parse(monthQueryLayout, month)
+		if err != nil {
+			errDetails := helpers.APIError{Code: "INVALID_DATE", Details: "month must be in YYYY-MM format."}
+			helpers.Error(c.Writer, http.StatusBadRequest, "Invalid month.", errDetails)
+			return
+		}
+		startDate, endDate = first, first.AddDate(0, 1, 0).Add(-time.Nanosecond)
+	}
+
+	// Default to the last complete calendar month
+	if startDate.IsZero() && endDate.IsZero() {
+		now := time.Now()
+		startDate = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+		endDate = startDate.AddDate(0, 1, 0).Add(-time.Nanosecond)
+	} else if startDate.IsZero() || endDate.IsZero() {
+		errDetails := helpers.APIError{Code: "INVALID_DATE", Details: "start_date and end_date must be given together."}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid period.", errDetails)
+		return
+	}
+
+	valuation, err := h.service.GetInventoryValuation(user.AccountID, startDate, endDate, c.Query("method"))
+	if errors.Is(err, database.ErrNoClosingCount) {
+		errDetails := helpers.APIError{Code: "SNAPSHOT_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "No inventory count to value.", errDetails)
+		return
+	}
+	if err != nil {
+		errDetails := helpers.APIError{Code: "VALIDATION_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to generate inventory valuation.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the valuation in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Inventory valuation generated successfully.", valuation)
+}
+
+// getUser resolves the authenticated user, writing the error response and
+// returning false when the user is not authenticated or does not exist.
+func (h *PriceHandler) getUser(c *gin.Context) (*models.User, bool) {
+	// Extract user ID from context (set by AuthMiddleware)
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		errDetails := helpers.APIError{Code: "UNAUTHORIZED", Details: "User ID not found in request context."}
+		helpers.Error(c.Writer, http.StatusUnauthorized, "User not authenticated.", errDetails)
+		return nil, false
+	}
+	userID := userIDInterface.(int)
+
+	// Retrieve user details from database to get the AccountID
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "USER_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "User not found.", errDetails)
+		return nil, false
+	}
+	return user, true
+}
+
+// getOwnedItem resolves the authenticated user and the inventory item named by
+// the ":id" URL parameter, writing the error response and returning false when
+// the item does not exist or belongs to another account.
+func (h *PriceHandler) getOwnedItem(c *gin.Context) (*models.User, *models.InventoryItem, bool) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return nil, nil, false
+	}
+
+	// Parse and validate the item ID from the URL parameter
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: "Item ID must be a valid integer."}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid Item ID.", errDetails)
+		return nil, nil, false
+	}
+
+	item, err := h.service.GetInventoryItem(id)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "ITEM_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "Inventory item not found.", errDetails)
+		return nil, nil, false
+	}
+
+	// Authorization check: Ensure the item belongs to the user's account
+	if item.AccountID != user.AccountID {
+		errDetails := helpers.APIError{Code: "FORBIDDEN", Details: "You do not have permission to access this item."}
+		helpers.Error(c.Writer, http.StatusForbidden, "Access denied.", errDetails)
+		return nil, nil, false
+	}
+
+	return user, item, true
+}
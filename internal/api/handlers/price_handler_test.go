@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupPriceTestHandler(t *testing.T) (*gin.Engine, *database.Service, *models.User, func()) {
+	router, service, user, cleanup := setupAuthenticatedTestRouter(t, "prices@example.com")
+	handler := NewPriceHandler(service.DB())
+
+	api := router.Group("/api/v1")
+	api.GET("/inventory/items/:id/price-history", handler.GetPriceHistory)
+	api.POST("/inventory/items/:id/recompute-cost", handler.RecomputeItemCost)
+	api.GET("/price-alerts", handler.GetPriceAlerts)
+	api.GET("/settings/costing", handler.GetCostSettings)
+	api.PUT("/settings/costing", handler.UpdateCostSettings)
+	api.GET("/reports/valuation", handler.GetInventoryValuation)
+
+	return router, service, user, cleanup
+}
+
+func TestPriceHandler_PriceHistoryAndAlerts(t *testing.T) {
+	router, service, user, cleanup := setupPriceTestHandler(t)
+	defer cleanup()
+
+	item := &models.InventoryItem{
+		AccountID: user.AccountID,
+		Name:      "Coffee Beans",
+		Unit:      "kg",
+	}
+	require.NoError(t, service.CreateInventoryItem(item))
+
+	for i, cost := range []float64{150.0, 180.0} {
+		delivery := &models.Delivery{
+			AccountID:       user.AccountID,
+			InventoryItemID: item.ID,
+			Vendor:          "Coffee Supply Co.",
+			Quantity:        10,
+			DeliveryDate:    time.Date(2024, time.April, 1+i*7, 9, 0, 0, 0, time.UTC),
+			Cost:            cost,
+		}
+		require.NoError(t, service.CreateDelivery(delivery))
+	}
+
+	t.Run("Price History", func(t *testing.T) {
+		path := fmt.Sprintf("/api/v1/inventory/items/%d/price-history?start_date=2024-04-01&end_date=2024-04-30", item.ID)
+		req, w := createAuthenticatedRequest("GET", path, nil, user.ID)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		trend := response["data"].(map[string]interface{})
+		assert.Len(t, trend["points"].([]interface{}), 2)
+		assert.Equal(t, float64(18), trend["latest_price"])
+		assert.InDelta(t, 20.0, trend["change_percent"].(float64), 0.0001)
+	})
+
+	t.Run("Price Alerts", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/price-alerts", nil, user.ID)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		assert.Len(t, response["data"].([]interface{}), 1)
+	})
+
+	t.Run("Recompute Cost With Method", func(t *testing.T) {
+		body := map[string]interface{}{"costing_method": "moving_average"}
+		req, w := createAuthenticatedRequest("POST", fmt.Sprintf("/api/v1/inventory/items/%d/recompute-cost", item.ID), body, user.ID)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		assert.InDelta(t, 16.5, response["data"].(map[string]interface{})["cost_per_unit"].(float64), 0.0001)
+	})
+}
+
+func TestPriceHandler_CostSettings(t *testing.T) {
+	router, _, user, cleanup := setupPriceTestHandler(t)
+	defer cleanup()
+
+	t.Run("Update Settings", func(t *testing.T) {
+		body := map[string]interface{}{"costing_method": "fifo", "price_alert_pct": 5}
+		req, w := createAuthenticatedRequest("PUT", "/api/v1/settings/costing", body, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		req, w = createAuthenticatedRequest("GET", "/api/v1/settings/costing", nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		settings := response["data"].(map[string]interface{})
+		assert.Equal(t, "fifo", settings["costing_method"])
+		assert.Equal(t, float64(5), settings["price_alert_pct"])
+	})
+
+	t.Run("Reject Unknown Method", func(t *testing.T) {
+		body := map[string]interface{}{"costing_method": "lifo"}
+		req, w := createAuthenticatedRequest("PUT", "/api/v1/settings/costing", body, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestPriceHandler_InventoryValuation(t *testing.T) {
+	router, service, user, cleanup := setupPriceTestHandler(t)
+	defer cleanup()
+
+	item := &models.InventoryItem{AccountID: user.AccountID, Name: "Flour", Unit: "kg", CostPerUnit: 1}
+	require.NoError(t, service.CreateInventoryItem(item))
+	require.NoError(t, service.CreateDelivery(&models.Delivery{
+		AccountID:       user.AccountID,
+		InventoryItemID: item.ID,
+		Vendor:          "Mill Co",
+		Quantity:        10,
+		Cost:            15,
+		DeliveryDate:    time.Date(2024, 5, 3, 9, 0, 0, 0, time.UTC),
+	}))
+	require.NoError(t, service.CreateInventorySnapshot(&models.InventorySnapshot{
+		AccountID: user.AccountID,
+		Timestamp: time.Date(2024, 5, 31, 20, 0, 0, 0, time.UTC),
+		Counts:    models.CountsMap{item.ID: 4},
+	}))
+
+	t.Run("Month", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/reports/valuation?month=2024-05&method=fifo", nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		valuation := response["data"].(map[string]interface{})
+		assert.Equal(t, "fifo", valuation["method"])
+		assert.InDelta(t, 15, valuation["purchases"], 0.001)
+		assert.InDelta(t, 6, valuation["closing_value"], 0.001)
+		assert.InDelta(t, 9, valuation["cogs"], 0.001)
+		assert.Len(t, valuation["items"], 1)
+	})
+
+	t.Run("No Count In Period", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/reports/valuation?start_date=2024-06-01&end_date=2024-06-30", nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Invalid Parameters", func(t *testing.T) {
+		for _, query := range []string{"month=May", "month=2024-05&method=lifo", "start_date=2024-05-01"} {
+			req, w := createAuthenticatedRequest("GET", "/api/v1/reports/valuation?"+query, nil, user.ID)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+	})
+}
@@ -0,0 +1,229 @@
+// Package handlers provides HTTP request handlers for the application's API endpoints.
+// This package includes the handler for downloading reports and purchase orders as PDFs.
+// All handlers require authentication and operate within the context of user accounts.
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	helpers "github.com/mnadev/pantryos/internal/api/helper"
+
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+	"github.com/mnadev/pantryos/internal/pdf"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReportHandler handles HTTP requests for printable documents.
+// It renders the weekly stock and supply chain reports of the authenticated
+// user's account, and the purchase orders sent to vendors, as PDF files.
+type ReportHandler struct {
+	// service provides access to the business logic layer for database operations
+	service *database.Service
+}
+
+// NewReportHandler creates a new ReportHandler instance with the provided database connection.
+//
+// Parameters:
+//   - db: The database connection to use for operations
+//
+// Returns:
+//   - *ReportHandler: A new handler instance ready to handle HTTP requests
+func NewReportHandler(db *database.DB) *ReportHandler {
+	return &ReportHandler{service: database.NewService(db)}
+}
+
+// GetStockReportPDF downloads the current stock report of the authenticated user's
+// account, with the same content as the weekly stock report email.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: Data is scoped to the user's account
+//
+// Response:
+//
+//	The PDF file, sent as an attachment. Errors are returned in the standard APIResponse structure.
+//
+// Status Codes:
+//   - 200 OK: The PDF is in the response body.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: The user or the account could not be found.
+//   - 500 Internal Server Error: The report could not be generated, e.g. before the first inventory count.
+func (h *ReportHandler) GetStockReportPDF(c *gin.Context) {
+	user, account, ok := h.getUserAndAccount(c)
+	if !ok {
+		return
+	}
+
+	stockData, err := generateStockReportData(h.service, user.AccountID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "REPORT_GENERATION_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to generate stock report data.", errDetails)
+		return
+	}
+
+	document, err := pdf.StockReport(account.Name, stockData)
+	h.sendPDF(c, "stock-report-"+time.Now().Format(dateQueryLayout), document, err)
+}
+
+// GetSupplyChainReportPDF downloads the current supply chain report of the authenticated
+// user's account, with the same content as the weekly supply chain report email.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: Data is scoped to the user's account
+//
+// Response:
+//
+//	The PDF file, sent as an attachment. Errors are returned in the standard APIResponse structure.
+//
+// Status Codes:
+//   - 200 OK: The PDF is in the response body.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: The user or the account could not be found.
+//   - 500 Internal Server Error: The report could not be generated, e.g. before the first inventory count.
+func (h *ReportHandler) GetSupplyChainReportPDF(c *gin.Context) {
+	user, account, ok := h.getUserAndAccount(c)
+	if !ok {
+		return
+	}
+
+	supplyChainData, err := generateSupplyChainReportData(h.service, user.AccountID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "REPORT_GENERATION_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to generate supply chain report data.", errDetails)
+		return
+	}
+
+	document, err := pdf.SupplyChainReport(account.Name, supplyChainData)
+	h.sendPDF(c, "supply-chain-report-"+time.Now().Format(dateQueryLayout), document, err)
+}
+
+// GetPurchaseOrderPDF downloads the purchase orders of an order as a PDF, with a page
+// for each vendor the order was placed with.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: The order must belong to the user's account
+//
+// Path Parameters:
+//   - id: The order ID (int, required)
+//
+// Query Parameters:
+//   - vendor_id: Only include the purchase order for this vendor (int, optional)
+//
+// Response:
+//
+//	The PDF file, sent as an attachment. Errors are returned in the standard APIResponse structure.
+//
+// Status Codes:
+//   - 200 OK: The PDF is in the response body.
+//   - 400 Bad Request: Invalid order or vendor ID.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: The order does not exist, belongs to another account, or has no items from the vendor.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *ReportHandler) GetPurchaseOrderPDF(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	// Parse and validate the order ID from the URL parameter
+	orderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: "Order ID must be a valid integer."}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid Order ID.", errDetails)
+		return
+	}
+
+	order, err := h.service.GetOrder(orderID)
+	if err != nil || order.AccountID != user.AccountID {
+		errDetails := helpers.APIError{Code: "ORDER_NOT_FOUND", Details: fmt.Sprintf("Order with ID %d not found.", orderID)}
+		helpers.Error(c.Writer, http.StatusNotFound, "Order not found.", errDetails)
+		return
+	}
+
+	purchaseOrders, err := h.service.GetPurchaseOrders(order.ID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to fetch purchase orders.", errDetails)
+		return
+	}
+
+	filename := fmt.Sprintf("purchase-order-%d", order.ID)
+	if raw := c.Query("vendor_id"); raw != "" {
+		vendorID, err := strconv.Atoi(raw)
+		if err != nil {
+			errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: "vendor_id must be a valid integer."}
+			helpers.Error(c.Writer, http.StatusBadRequest, "Invalid vendor_id parameter.", errDetails)
+			return
+		}
+		var selected []database.PurchaseOrder
+		for _, purchaseOrder := range purchaseOrders {
+			if purchaseOrder.Vendor != nil && purchaseOrder.Vendor.ID == vendorID {
+				selected = append(selected, purchaseOrder)
+				filename = "purchase-order-" + purchaseOrder.Number
+			}
+		}
+		if len(selected) == 0 {
+			errDetails := helpers.APIError{Code: "VENDOR_NOT_ON_ORDER", Details: fmt.Sprintf("Order %d has no items from vendor %d.", order.ID, vendorID)}
+			helpers.Error(c.Writer, http.StatusNotFound, "Purchase order not found.", errDetails)
+			return
+		}
+		purchaseOrders = selected
+	}
+
+	document, err := pdf.PurchaseOrders(purchaseOrders)
+	h.sendPDF(c, filename, document, err)
+}
+
+// sendPDF sends a rendered document as a download, or the error that prevented rendering it.
+func (h *ReportHandler) sendPDF(c *gin.Context, filename string, document []byte, err error) {
+	if err != nil {
+		errDetails := helpers.APIError{Code: "PDF_RENDER_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to render PDF.", errDetails)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".pdf"))
+	c.Data(http.StatusOK, pdf.ContentType, document)
+}
+
+// getUserAndAccount resolves the authenticated user and their account, writing the
+// error response and returning false when either cannot be found.
+func (h *ReportHandler) getUserAndAccount(c *gin.Context) (*models.User, *models.Account, bool) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return nil, nil, false
+	}
+
+	account, err := h.service.GetAccount(user.AccountID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "ACCOUNT_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "Account not found.", errDetails)
+		return nil, nil, false
+	}
+	return user, account, true
+}
+
+// getUser resolves the authenticated user, writing the error response and
+// returning false when the user is not authenticated or does not exist.
+func (h *ReportHandler) getUser(c *gin.Context) (*models.User, bool) {
+	// Extract user ID from context (set by AuthMiddleware)
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		errDetails := helpers.APIError{Code: "UNAUTHORIZED", Details: "User ID not found in request context."}
+		helpers.Error(c.Writer, http.StatusUnauthorized, "User not authenticated.", errDetails)
+		return nil, false
+	}
+	userID := userIDInterface.(int)
+
+	// Retrieve user details from database to get the AccountID
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "USER_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "User not found.", errDetails)
+		return nil, false
+	}
+	return user, true
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupReportTestHandler(t *testing.T) (*gin.Engine, *database.Service, *models.User, func()) {
+	router, service, user, cleanup := setupAuthenticatedTestRouter(t, "reports@example.com")
+	handler := NewReportHandler(service.DB())
+
+	api := router.Group("/api/v1")
+	api.GET("/reports/stock/pdf", handler.GetStockReportPDF)
+	api.GET("/reports/supply-chain/pdf", handler.GetSupplyChainReportPDF)
+	api.GET("/orders/:id/purchase-order/pdf", handler.GetPurchaseOrderPDF)
+
+	return router, service, user, cleanup
+}
+
+func TestReportHandler_ReportPDFs(t *testing.T) {
+	router, service, user, cleanup := setupReportTestHandler(t)
+	defer cleanup()
+
+	t.Run("No Inventory Count", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/reports/stock/pdf", nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	item := &models.InventoryItem{AccountID: user.AccountID, Name: "Flour", Unit: "kg", CostPerUnit: 1.5, MinStockLevel: 5, MaxStockLevel: 20}
+	require.NoError(t, service.CreateInventoryItem(item))
+	require.NoError(t, service.CreateInventorySnapshot(&models.InventorySnapshot{AccountID: user.AccountID, Counts: models.CountsMap{item.ID: 3}}))
+
+	for _, path := range []string{"/api/v1/reports/stock/pdf", "/api/v1/reports/supply-chain/pdf"} {
+		req, w := createAuthenticatedRequest("GET", path, nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, path)
+		assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), ".pdf")
+		assert.True(t, bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF-")), path)
+	}
+}
+
+func TestReportHandler_PurchaseOrderPDF(t *testing.T) {
+	router, service, user, cleanup := setupReportTestHandler(t)
+	defer cleanup()
+
+	flour := &models.InventoryItem{AccountID: user.AccountID, Name: "Flour", Unit: "kg"}
+	require.NoError(t, service.CreateInventoryItem(flour))
+	milk := &models.InventoryItem{AccountID: user.AccountID, Name: "Milk", Unit: "liters"}
+	require.NoError(t, service.CreateInventoryItem(milk))
+
+	order := &models.Order{AccountID: user.AccountID, CreatedBy: user.ID, ExpectedDate: time.Now().AddDate(0, 0, 3)}
+	require.NoError(t, service.CreateOrder(order, []models.OrderItem{
+		{InventoryItemID: flour.ID, Quantity: 10, UnitCost: 1.2, Vendor: "Mill Co"},
+		{InventoryItemID: milk.ID, Quantity: 20, UnitCost: 0.9, Vendor: "Local Dairy"},
+	}))
+	purchaseOrders, err := service.GetPurchaseOrders(order.ID)
+	require.NoError(t, err)
+	require.Len(t, purchaseOrders, 2)
+
+	t.Run("All Vendors", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", fmt.Sprintf("/api/v1/orders/%d/purchase-order/pdf", order.ID), nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Disposition"), fmt.Sprintf("purchase-order-%d.pdf", order.ID))
+		assert.Contains(t, w.Body.String(), "/Count 2")
+	})
+
+	t.Run("One Vendor", func(t *testing.T) {
+		path := fmt.Sprintf("/api/v1/orders/%d/purchase-order/pdf?vendor_id=%d", order.ID, purchaseOrders[1].Vendor.ID)
+		req, w := createAuthenticatedRequest("GET", path, nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Disposition"), purchaseOrders[1].Number+".pdf")
+		assert.Contains(t, w.Body.String(), "/Count 1")
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		otherAccount := &models.Account{Name: "Other Shop", Status: "active"}
+		require.NoError(t, service.CreateAccount(otherAccount))
+		other := &models.User{Email: "other-reports@example.com", Password: "hashed", AccountID: otherAccount.ID, Role: "user"}
+		require.NoError(t, service.CreateUser(other))
+
+		cases := []struct {
+			path   string
+			userID int
+			status int
+		}{
+			{fmt.Sprintf("/api/v1/orders/%d/purchase-order/pdf?vendor_id=9999", order.ID), user.ID, http.StatusNotFound},
+			{fmt.Sprintf("/api/v1/orders/%d/purchase-order/pdf?vendor_id=abc", order.ID), user.ID, http.StatusBadRequest},
+			{"/api/v1/orders/abc/purchase-order/pdf", user.ID, http.StatusBadRequest},
+			{fmt.Sprintf("/api/v1/orders/%d/purchase-order/pdf", order.ID), other.ID, http.StatusNotFound},
+		}
+		for _, tc := range cases {
+			req, w := createAuthenticatedRequest("GET", tc.path, nil, tc.userID)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tc.status, w.Code, tc.path)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+// Package handlers provides HTTP request handlers for the application's API endpoints.
+// This package includes the handler for searching across an account's records.
+// All handlers require authentication and operate within the context of user accounts.
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	helpers "github.com/mnadev/pantryos/internal/api/helper"
+
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchHandler handles HTTP requests for the global search box.
+// It searches inventory items, menu items, categories, vendors, and the notes on
+// orders and deliveries of the authenticated user's account.
+type SearchHandler struct {
+	// service provides access to the business logic layer for database operations
+	service *database.Service
+}
+
+// NewSearchHandler creates a new SearchHandler instance with the provided database connection.
+//
+// Parameters:
+//   - db: The database connection to use for operations
+//
+// Returns:
+//   - *SearchHandler: A new handler instance ready to handle HTTP requests
+func NewSearchHandler(db *database.DB) *SearchHandler {
+	return &SearchHandler{service: database.NewService(db)}
+}
+
+// Search finds the records of the authenticated user's account that match a query,
+// most relevant first.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: Results are scoped to the user's account
+//
+// Query Parameters:
+//   - q: The text to search for; every word must appear in a result (string, required)
+//   - limit: Maximum number of results (int, optional, defaults to 20, at most 50)
+//
+// Status Codes:
+//   - 200 OK: Search completed. The 'data' field contains a list of results with their type, ID, title, and score.
+//   - 400 Bad Request: Missing or empty query, or invalid limit.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *SearchHandler) Search(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: "The q query parameter is required."}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Missing search query.", errDetails)
+		return
+	}
+
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		var err error
+		if limit, err = strconv.Atoi(raw); err != nil || limit < 1 || limit > database.MaxSearchLimit {
+			errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: fmt.Sprintf("limit must be between 1 and %d.", database.MaxSearchLimit)}
+			helpers.Error(c.Writer, http.StatusBadRequest, "Invalid limit parameter.", errDetails)
+			return
+		}
+	}
+
+	results, err := h.service.Search(user.AccountID, query, limit)
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidSearchQuery) {
+			errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+			helpers.Error(c.Writer, http.StatusBadRequest, "Invalid search query.", errDetails)
+			return
+		}
+		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to search.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the ranked results in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Search completed successfully.", results)
+}
+
+// getUser resolves the authenticated user, writing the error response and
+// returning false when the user is not authenticated or does not exist.
+func (h *SearchHandler) getUser(c *gin.Context) (*models.User, bool) {
+	// Extract user ID from context (set by AuthMiddleware)
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		errDetails := helpers.APIError{Code: "UNAUTHORIZED", Details: "User ID not found in request context."}
+		helpers.Error(c.Writer, http.StatusUnauthorized, "User not authenticated.", errDetails)
+		return nil, false
+	}
+	userID := userIDInterface.(int)
+
+	// Retrieve user details from database to get the AccountID
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "USER_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "User not found.", errDetails)
+		return nil, false
+	}
+	return user, true
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupSearchTestHandler(t *testing.T) (*gin.Engine, *database.Service, *models.User, func()) {
+	router, service, user, cleanup := setupAuthenticatedTestRouter(t, "search@example.com")
+	handler := NewSearchHandler(service.DB())
+
+	api := router.Group("/api/v1")
+	api.GET("/search", handler.Search)
+
+	return router, service, user, cleanup
+}
+
+func TestSearchHandler_Search(t *testing.T) {
+	router, service, user, cleanup := setupSearchTestHandler(t)
+	defer cleanup()
+
+	require.NoError(t, service.CreateInventoryItem(&models.InventoryItem{AccountID: user.AccountID, Name: "Espresso Beans", Unit: "kg", CostPerUnit: 18}))
+	require.NoError(t, service.CreateMenuItem(&models.MenuItem{AccountID: user.AccountID, Name: "Espresso", Price: 3}))
+
+	t.Run("Search", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/search?q=espresso", nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response struct {
+			Data []database.SearchResult `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Data, 2)
+		assert.Equal(t, database.SearchResultMenuItem, response.Data[0].Type)
+		assert.Equal(t, "Espresso", response.Data[0].Title)
+		assert.Equal(t, database.SearchResultInventoryItem, response.Data[1].Type)
+	})
+
+	t.Run("No Matches", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/search?q=matcha", nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Empty(t, response["data"])
+	})
+
+	t.Run("Invalid Requests", func(t *testing.T) {
+		for _, query := range []string{"", "?q=", "?q=%20--%20", "?q=espresso&limit=0", "?q=espresso&limit=100"} {
+			req, w := createAuthenticatedRequest("GET", "/api/v1/search"+query, nil, user.ID)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+
+		req, w := createAuthenticatedRequest("GET", "/api/v1/search?q=espresso", nil, 0)
+		req.Header.Del("X-Test-User-ID")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
@@ -0,0 +1,204 @@
+// Package handlers provides HTTP request handlers for the application's API endpoints.
+// This package includes handlers for ad-hoc stock adjustments and their audit trail.
+// All handlers require authentication and operate within the context of user accounts.
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	helpers "github.com/mnadev/pantryos/internal/api/helper"
+
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StockAdjustmentHandler handles HTTP requests related to ad-hoc stock adjustments.
+// Staff record corrections, breakage, internal use, and samples with a reason; the
+// adjustments change current stock and cannot be edited or deleted afterwards.
+type StockAdjustmentHandler struct {
+	// service provides access to the business logic layer for database operations
+	service *database.Service
+}
+
+// NewStockAdjustmentHandler creates a new StockAdjustmentHandler instance with the provided database connection.
+//
+// Parameters:
+//   - db: The database connection to use for operations
+//
+// Returns:
+//   - *StockAdjustmentHandler: A new handler instance ready to handle HTTP requests
+func NewStockAdjustmentHandler(db *database.DB) *StockAdjustmentHandler {
+	return &StockAdjustmentHandler{service: database.NewService(db)}
+}
+
+// RecordStockAdjustment records an ad-hoc change to the stock of an inventory item.
+// The adjustment is automatically associated with the authenticated user and account.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: The item must belong to the user's account
+//
+// Request Body: JSON object
+//   - inventory_item_id: The adjusted item (int, required)
+//   - quantity: The signed change in stock, negative when stock is removed (float64, required)
+//   - reason: "count_correction", "breakage", "internal_use", or "sample" (string, required)
+//   - notes: Free-text details (string, optional)
+//   - storage_location_id: The location whose stock changed (int, optional, defaults to the default location)
+//   - adjusted_at: When the stock changed (RFC 3339, optional, defaults to now)
+//
+// Status Codes:
+//   - 201 Created: Adjustment recorded successfully. The 'data' field contains the new adjustment.
+//   - 400 Bad Request: Invalid request body, reason, quantity, or item.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+func (h *StockAdjustmentHandler) RecordStockAdjustment(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	// Parse and validate the JSON request body
+	var adjustment models.StockAdjustment
+	if err := c.ShouldBindJSON(&adjustment); err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+		return
+	}
+
+	// Scope the adjustment to the authenticated user's account
+	adjustment.ID = 0
+	adjustment.AccountID = user.AccountID
+	adjustment.AdjustedBy = &user.ID
+
+	if err := h.service.RecordStockAdjustment(&adjustment); err != nil {
+		errDetails := helpers.APIError{Code: "VALIDATION_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to record stock adjustment.", errDetails)
+		return
+	}
+
+	// Return a 201 Created response with the new adjustment.
+	helpers.Success(c.Writer, http.StatusCreated, "Stock adjustment recorded successfully.", adjustment)
+}
+
+// GetStockAdjustments retrieves the stock adjustments of the authenticated user's account, newest first.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Query Parameters:
+//   - start_date: First day of the period, YYYY-MM-DD (optional, defaults to 30 days before end_date)
+//   - end_date: Last day of the period, YYYY-MM-DD (optional, defaults to today)
+//
+// Status Codes:
+//   - 200 OK: Adjustments retrieved successfully. The 'data' field contains a list of adjustments.
+//   - 400 Bad Request: Invalid date parameters.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+func (h *StockAdjustmentHandler) GetStockAdjustments(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	startDate, endDate, ok := parseAdjustmentPeriod(c)
+	if !ok {
+		return
+	}
+
+	adjustments, err := h.service.GetStockAdjustments(user.AccountID, startDate, endDate)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_DATE", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to fetch stock adjustments.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the list of adjustments in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Stock adjustments retrieved successfully.", adjustments)
+}
+
+// GetStockAdjustment retrieves a specific stock adjustment by ID.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: The adjustment must belong to the user's account
+//
+// URL Parameters:
+//   - id: The adjustment ID to retrieve (integer)
+//
+// Status Codes:
+//   - 200 OK: Adjustment retrieved successfully. The 'data' field contains the adjustment.
+//   - 400 Bad Request: Invalid adjustment ID format in URL.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The adjustment does not belong to the user's account.
+//   - 404 Not Found: The user or the adjustment could not be found.
+func (h *StockAdjustmentHandler) GetStockAdjustment(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	// Parse and validate the adjustment ID from the URL parameter
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: "Stock adjustment ID must be a valid integer."}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid stock adjustment ID.", errDetails)
+		return
+	}
+
+	adjustment, err := h.service.GetStockAdjustment(id)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "ADJUSTMENT_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "Stock adjustment not found.", errDetails)
+		return
+	}
+
+	// Authorization check: Ensure the adjustment belongs to the user's account
+	if adjustment.AccountID != user.AccountID {
+		errDetails := helpers.APIError{Code: "FORBIDDEN", Details: "You do not have permission to access this stock adjustment."}
+		helpers.Error(c.Writer, http.StatusForbidden, "Access denied.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the adjustment in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Stock adjustment retrieved successfully.", adjustment)
+}
+
+// parseAdjustmentPeriod reads the listing period from the query string, defaulting to the last 30 days.
+func parseAdjustmentPeriod(c *gin.Context) (startDate, endDate time.Time, ok bool) {
+	startDate, endDate, ok = parseDateRange(c)
+	if !ok {
+		return startDate, endDate, false
+	}
+
+	if endDate.IsZero() {
+		endDate = time.Now()
+	}
+	if startDate.IsZero() {
+		startDate = endDate.AddDate(0, 0, -30)
+	}
+	return startDate, endDate, true
+}
+
+// getUser resolves the authenticated user, writing the error response and
+// returning false when the user is not authenticated or does not exist.
+func (h *StockAdjustmentHandler) getUser(c *gin.Context) (*models.User, bool) {
+	// Extract user ID from context (set by AuthMiddleware)
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		errDetails := helpers.APIError{Code: "UNAUTHORIZED", Details: "User ID not found in request context."}
+		helpers.Error(c.Writer, http.StatusUnauthorized, "User not authenticated.", errDetails)
+		return nil, false
+	}
+	userID := userIDInterface.(int)
+
+	// Retrieve user details from database to get the AccountID
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "USER_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "User not found.", errDetails)
+		return nil, false
+	}
+	return user, true
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupStockAdjustmentTestHandler(t *testing.T) (*gin.Engine, *database.Service, *models.User, func()) {
+	router, service, user, cleanup := setupAuthenticatedTestRouter(t, "adjustments@example.com")
+	handler := NewStockAdjustmentHandler(service.DB())
+
+	api := router.Group("/api/v1")
+	api.GET("/stock-adjustments", handler.GetStockAdjustments)
+	api.POST("/stock-adjustments", handler.RecordStockAdjustment)
+	api.GET("/stock-adjustments/:id", handler.GetStockAdjustment)
+
+	return router, service, user, cleanup
+}
+
+func TestStockAdjustmentHandler_RecordAndList(t *testing.T) {
+	router, service, user, cleanup := setupStockAdjustmentTestHandler(t)
+	defer cleanup()
+
+	item := &models.InventoryItem{AccountID: user.AccountID, Name: "Wine", Unit: "bottle", CostPerUnit: 12}
+	require.NoError(t, service.CreateInventoryItem(item))
+
+	var adjustmentID int
+
+	t.Run("Record Adjustment", func(t *testing.T) {
+		adjustmentData := map[string]interface{}{
+			"inventory_item_id": item.ID,
+			"quantity":          -2,
+			"reason":            "breakage",
+			"notes":             "Dropped a case",
+		}
+		req, w := createAuthenticatedRequest("POST", "/api/v1/stock-adjustments", adjustmentData, user.ID)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		adjustment := response["data"].(map[string]interface{})
+		assert.Equal(t, float64(user.ID), adjustment["adjusted_by"])
+		assert.Equal(t, 12.0, adjustment["unit_cost"])
+		adjustmentID = int(adjustment["id"].(float64))
+
+		items, err := service.GetInventoryItemsWithCurrentStock(user.AccountID)
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		assert.Equal(t, -2.0, items[0].CurrentStock)
+	})
+
+	t.Run("Reject Invalid Reason", func(t *testing.T) {
+		adjustmentData := map[string]interface{}{
+			"inventory_item_id": item.ID,
+			"quantity":          3,
+			"reason":            "sample",
+		}
+		req, w := createAuthenticatedRequest("POST", "/api/v1/stock-adjustments", adjustmentData, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("List And Get Adjustments", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/stock-adjustments", nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response["data"].([]interface{}), 1)
+
+		req, w = createAuthenticatedRequest("GET", fmt.Sprintf("/api/v1/stock-adjustments/%d", adjustmentID), nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		req, w = createAuthenticatedRequest("GET", "/api/v1/stock-adjustments?start_date=bad", nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,285 @@
+// Package handlers provides HTTP request handlers for the application's API endpoints.
+// This package includes handlers for stock transfers between the accounts of an organization.
+// All handlers require authentication and operate within the context of user accounts.
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	helpers "github.com/mnadev/pantryos/internal/api/helper"
+
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StockTransferHandler handles HTTP requests related to stock transfers.
+// The sending account creates and may cancel a transfer; the receiving account
+// records its arrival.
+type StockTransferHandler struct {
+	// service provides access to the business logic layer for database operations
+	service *database.Service
+}
+
+// NewStockTransferHandler creates a new StockTransferHandler instance with the provided database connection.
+//
+// Parameters:
+//   - db: The database connection to use for operations
+//
+// Returns:
+//   - *StockTransferHandler: A new handler instance ready to handle HTTP requests
+func NewStockTransferHandler(db *database.DB) *StockTransferHandler {
+	return &StockTransferHandler{service: database.NewService(db)}
+}
+
+// GetStockTransfers retrieves the transfers the authenticated user's account sent or received, newest first.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Status Codes:
+//   - 200 OK: Transfers retrieved successfully. The 'data' field contains a list of transfers.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *StockTransferHandler) GetStockTransfers(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	transfers, err := h.service.GetStockTransfersByAccount(user.AccountID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to fetch stock transfers.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the list of transfers in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Stock transfers retrieved successfully.", transfers)
+}
+
+// SendStockTransfer sends stock from the authenticated user's account to another
+// account of the same organization. The stock leaves the sending account immediately.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Request Body: JSON object with transfer details
+//   - to_account_id: The receiving account (int, required)
+//   - from_inventory_item_id: The item being sent (int, required)
+//   - quantity: Amount sent, in the item's unit (float64, required)
+//   - to_inventory_item_id: The receiving account's item (int, optional, defaults to the item with the same name)
+//   - from_storage_location_id: The location the stock leaves (int, optional, defaults to the default location)
+//   - notes: Free-form notes (string, optional)
+//
+// Status Codes:
+//   - 201 Created: Transfer sent successfully. The 'data' field contains the new transfer.
+//   - 400 Bad Request: Invalid request body or validation error.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+func (h *StockTransferHandler) SendStockTransfer(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	// Parse and validate the JSON request body
+	var transfer models.StockTransfer
+	if err := c.ShouldBindJSON(&transfer); err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+		return
+	}
+
+	// The authenticated user's account is always the sender
+	transfer.ID = 0
+	transfer.FromAccountID = user.AccountID
+	transfer.SentBy = &user.ID
+
+	if err := h.service.SendStockTransfer(&transfer); err != nil {
+		errDetails := helpers.APIError{Code: "VALIDATION_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to send stock transfer.", errDetails)
+		return
+	}
+
+	// Return a 201 Created response with the new transfer.
+	helpers.Success(c.Writer, http.StatusCreated, "Stock transfer sent successfully.", transfer)
+}
+
+// GetStockTransfer retrieves a specific stock transfer by ID.
+// Both the sending and the receiving account can view a transfer.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// URL Parameters:
+//   - id: The transfer ID to retrieve (integer)
+//
+// Status Codes:
+//   - 200 OK: Transfer retrieved successfully. The 'data' field contains the transfer.
+//   - 400 Bad Request: Invalid transfer ID format in URL.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The user's account is not a party to the transfer.
+//   - 404 Not Found: The user or the transfer could not be found.
+func (h *StockTransferHandler) GetStockTransfer(c *gin.Context) {
+	_, transfer, ok := h.getStockTransfer(c)
+	if !ok {
+		return
+	}
+
+	// Return a 200 OK response with the transfer in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Stock transfer retrieved successfully.", transfer)
+}
+
+// ReceiveStockTransfer records the arrival of a transfer at the authenticated user's account.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User's account must be the receiving account
+//
+// URL Parameters:
+//   - id: The transfer ID to receive (integer)
+//
+// Request Body: Optional JSON object
+//   - received_quantity: Amount that arrived (float64, optional, defaults to the quantity sent)
+//   - to_inventory_item_id: The item receiving the stock (int, optional, defaults to the item with the same name)
+//   - to_storage_location_id: The location receiving the stock (int, optional, defaults to the default location)
+//
+// Status Codes:
+//   - 200 OK: Transfer received successfully. The 'data' field contains the updated transfer.
+//   - 400 Bad Request: Invalid transfer ID, request body, or validation error.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The user's account is not the receiving account.
+//   - 404 Not Found: The user or the transfer could not be found.
+//   - 409 Conflict: The transfer was already received or cancelled.
+func (h *StockTransferHandler) ReceiveStockTransfer(c *gin.Context) {
+	user, transfer, ok := h.getStockTransfer(c)
+	if !ok {
+		return
+	}
+	if transfer.ToAccountID != user.AccountID {
+		errDetails := helpers.APIError{Code: "FORBIDDEN", Details: "Only the receiving account can receive this transfer."}
+		helpers.Error(c.Writer, http.StatusForbidden, "Access denied.", errDetails)
+		return
+	}
+	if transfer.Status != models.StockTransferStatusInTransit {
+		errDetails := helpers.APIError{Code: "TRANSFER_CLOSED", Details: "Stock transfer is " + transfer.Status + "."}
+		helpers.Error(c.Writer, http.StatusConflict, "Failed to receive stock transfer.", errDetails)
+		return
+	}
+
+	// The receipt details are optional
+	var receipt database.StockTransferReceipt
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&receipt); err != nil {
+			errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+			helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+			return
+		}
+	}
+
+	received, err := h.service.ReceiveStockTransfer(transfer.ID, receipt, user.ID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "VALIDATION_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to receive stock transfer.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the received transfer.
+	helpers.Success(c.Writer, http.StatusOK, "Stock transfer received successfully.", received)
+}
+
+// CancelStockTransfer cancels a transfer that has not been received yet.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User's account must be the sending account
+//
+// URL Parameters:
+//   - id: The transfer ID to cancel (integer)
+//
+// Status Codes:
+//   - 200 OK: Transfer cancelled successfully. The 'data' field contains the updated transfer.
+//   - 400 Bad Request: Invalid transfer ID format.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The user's account is not the sending account.
+//   - 404 Not Found: The user or the transfer could not be found.
+//   - 409 Conflict: The transfer was already received or cancelled.
+func (h *StockTransferHandler) CancelStockTransfer(c *gin.Context) {
+	user, transfer, ok := h.getStockTransfer(c)
+	if !ok {
+		return
+	}
+	if transfer.FromAccountID != user.AccountID {
+		errDetails := helpers.APIError{Code: "FORBIDDEN", Details: "Only the sending account can cancel this transfer."}
+		helpers.Error(c.Writer, http.StatusForbidden, "Access denied.", errDetails)
+		return
+	}
+
+	cancelled, err := h.service.CancelStockTransfer(transfer.ID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "TRANSFER_CLOSED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusConflict, "Failed to cancel stock transfer.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the cancelled transfer.
+	helpers.Success(c.Writer, http.StatusOK, "Stock transfer cancelled successfully.", cancelled)
+}
+
+// getUser resolves the authenticated user, writing the error response and
+// returning false when the user is not authenticated or does not exist.
+func (h *StockTransferHandler) getUser(c *gin.Context) (*models.User, bool) {
+	// Extract user ID from context (set by AuthMiddleware)
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		errDetails := helpers.APIError{Code: "UNAUTHORIZED", Details: "User ID not found in request context."}
+		helpers.Error(c.Writer, http.StatusUnauthorized, "User not authenticated.", errDetails)
+		return nil, false
+	}
+	userID := userIDInterface.(int)
+
+	// Retrieve user details from database to get the AccountID
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "USER_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "User not found.", errDetails)
+		return nil, false
+	}
+	return user, true
+}
+
+// getStockTransfer resolves the authenticated user and the transfer named by the
+// ":id" URL parameter, writing the error response and returning false when the
+// transfer does not exist or the user's account is not a party to it.
+func (h *StockTransferHandler) getStockTransfer(c *gin.Context) (*models.User, *models.StockTransfer, bool) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return nil, nil, false
+	}
+
+	// Parse and validate the transfer ID from the URL parameter
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: "Stock transfer ID must be a valid integer."}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid stock transfer ID.", errDetails)
+		return nil, nil, false
+	}
+
+	transfer, err := h.service.GetStockTransfer(id)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "TRANSFER_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "Stock transfer not found.", errDetails)
+		return nil, nil, false
+	}
+
+	// Authorization check: Ensure the user's account sent or receives the transfer
+	if transfer.FromAccountID != user.AccountID && transfer.ToAccountID != user.AccountID {
+		errDetails := helpers.APIError{Code: "FORBIDDEN", Details: "You do not have permission to access this stock transfer."}
+		helpers.Error(c.Writer, http.StatusForbidden, "Access denied.", errDetails)
+		return nil, nil, false
+	}
+
+	return user, transfer, true
+}
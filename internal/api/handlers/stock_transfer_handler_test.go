@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupStockTransferTestHandler(t *testing.T) (*gin.Engine, *database.Service, *models.User, *models.User, func()) {
+	router, service, sender, cleanup := setupAuthenticatedTestRouter(t, "transfers0@example.com")
+	receiver := addTestStore(t, service, sender, "transfers1@example.com")
+	handler := NewStockTransferHandler(service.DB())
+
+	api := router.Group("/api/v1")
+	api.GET("/stock-transfers", handler.GetStockTransfers)
+	api.POST("/stock-transfers", handler.SendStockTransfer)
+	api.GET("/stock-transfers/:id", handler.GetStockTransfer)
+	api.POST("/stock-transfers/:id/receive", handler.ReceiveStockTransfer)
+	api.POST("/stock-transfers/:id/cancel", handler.CancelStockTransfer)
+
+	return router, service, sender, receiver, cleanup
+}
+
+func TestStockTransferHandler_SendAndReceive(t *testing.T) {
+	router, service, sender, receiver, cleanup := setupStockTransferTestHandler(t)
+	defer cleanup()
+
+	beans := &models.InventoryItem{AccountID: sender.AccountID, Name: "Coffee Beans", Unit: "kg", CostPerUnit: 18}
+	require.NoError(t, service.CreateInventoryItem(beans))
+	receiverBeans := &models.InventoryItem{AccountID: receiver.AccountID, Name: "Espresso Beans", Unit: "kg", CostPerUnit: 20}
+	require.NoError(t, service.CreateInventoryItem(receiverBeans))
+
+	var transferID int
+
+	t.Run("Send Transfer", func(t *testing.T) {
+		transferData := map[string]interface{}{
+			"to_account_id":          receiver.AccountID,
+			"from_inventory_item_id": beans.ID,
+			"to_inventory_item_id":   receiverBeans.ID,
+			"quantity":               4,
+		}
+		req, w := createAuthenticatedRequest("POST", "/api/v1/stock-transfers", transferData, sender.ID)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		transfer := response["data"].(map[string]interface{})
+		assert.Equal(t, float64(sender.AccountID), transfer["from_account_id"])
+		assert.Equal(t, 18.0, transfer["unit_cost"])
+		assert.Equal(t, models.StockTransferStatusInTransit, transfer["status"])
+		transferID = int(transfer["id"].(float64))
+	})
+
+	t.Run("Only The Receiver Can Receive", func(t *testing.T) {
+		path := fmt.Sprintf("/api/v1/stock-transfers/%d/receive", transferID)
+		req, w := createAuthenticatedRequest("POST", path, nil, sender.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		req, w = createAuthenticatedRequest("POST", path, nil, receiver.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		transfer := response["data"].(map[string]interface{})
+		assert.Equal(t, models.StockTransferStatusReceived, transfer["status"])
+		assert.Equal(t, 4.0, transfer["received_quantity"])
+
+		req, w = createAuthenticatedRequest("POST", path, nil, receiver.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Both Accounts See The Transfer", func(t *testing.T) {
+		for _, user := range []*models.User{sender, receiver} {
+			req, w := createAuthenticatedRequest("GET", "/api/v1/stock-transfers", nil, user.ID)
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Len(t, response["data"].([]interface{}), 1)
+		}
+	})
+
+	t.Run("Cancel Transfer", func(t *testing.T) {
+		transferData := map[string]interface{}{
+			"to_account_id":          receiver.AccountID,
+			"from_inventory_item_id": beans.ID,
+			"to_inventory_item_id":   receiverBeans.ID,
+			"quantity":               1,
+		}
+		req, w := createAuthenticatedRequest("POST", "/api/v1/stock-transfers", transferData, sender.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		id := int(response["data"].(map[string]interface{})["id"].(float64))
+
+		path := fmt.Sprintf("/api/v1/stock-transfers/%d/cancel", id)
+		req, w = createAuthenticatedRequest("POST", path, nil, receiver.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		req, w = createAuthenticatedRequest("POST", path, nil, sender.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
@@ -0,0 +1,340 @@
+// Package handlers provides HTTP request handlers for the application's API endpoints.
+// This package includes handlers for storage locations and transfers between them.
+// All handlers require authentication and operate within the context of user accounts.
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	helpers "github.com/mnadev/pantryos/internal/api/helper"
+
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StorageLocationHandler handles HTTP requests related to storage locations.
+// Locations such as a walk-in, dry storage or the bar hold the stock of an
+// account, and transfers move stock between them.
+type StorageLocationHandler struct {
+	// service provides access to the business logic layer for database operations
+	service *database.Service
+}
+
+// NewStorageLocationHandler creates a new StorageLocationHandler instance with the provided database connection.
+//
+// Parameters:
+//   - db: The database connection to use for operations
+//
+// Returns:
+//   - *StorageLocationHandler: A new handler instance ready to handle HTTP requests
+func NewStorageLocationHandler(db *database.DB) *StorageLocationHandler {
+	return &StorageLocationHandler{service: database.NewService(db)}
+}
+
+// GetStorageLocations retrieves all storage locations for the authenticated user's account.
+// Locations are returned in alphabetical order.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Status Codes:
+//   - 200 OK: Locations retrieved successfully. The 'data' field contains a list of locations.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *StorageLocationHandler) GetStorageLocations(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	locations, err := h.service.GetStorageLocationsByAccount(user.AccountID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to fetch storage locations.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the list of locations in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Storage locations retrieved successfully.", locations)
+}
+
+// CreateStorageLocation creates a new storage location for the authenticated user's account.
+// The first location of an account becomes its default location.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Request Body: JSON object with location details
+//   - name: Location name, unique within the account (string, required)
+//   - description: Free-form description (string, optional)
+//   - is_default: Receive deliveries and sales consumption by default (bool, optional)
+//
+// Status Codes:
+//   - 201 Created: Location created successfully. The 'data' field contains the new location.
+//   - 400 Bad Request: Invalid request body or validation error.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+func (h *StorageLocationHandler) CreateStorageLocation(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	// Parse and validate the JSON request body
+	var location models.StorageLocation
+	if err := c.ShouldBindJSON(&location); err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+		return
+	}
+
+	// Set account ID from the authenticated user to ensure proper scoping
+	location.ID = 0
+	location.AccountID = user.AccountID
+	location.IsActive = true
+
+	if err := h.service.CreateStorageLocation(&location); err != nil {
+		errDetails := helpers.APIError{Code: "VALIDATION_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to create storage location.", errDetails)
+		return
+	}
+
+	// Return a 201 Created response with the newly created location.
+	helpers.Success(c.Writer, http.StatusCreated, "Storage location created successfully.", location)
+}
+
+// GetStorageLocation retrieves a specific storage location by ID.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// URL Parameters:
+//   - id: The location ID to retrieve (integer)
+//
+// Status Codes:
+//   - 200 OK: Location retrieved successfully. The 'data' field contains the location object.
+//   - 400 Bad Request: Invalid location ID format in URL.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The location does not belong to the user's account.
+//   - 404 Not Found: The user or the location could not be found.
+func (h *StorageLocationHandler) GetStorageLocation(c *gin.Context) {
+	_, location, ok := h.getOwnedStorageLocation(c)
+	if !ok {
+		return
+	}
+
+	// Return a 200 OK response with the location object in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Storage location retrieved successfully.", location)
+}
+
+// UpdateStorageLocation updates an existing storage location.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// URL Parameters:
+//   - id: The location ID to update (integer)
+//
+// Status Codes:
+//   - 200 OK: Location updated successfully. The 'data' field contains the updated location.
+//   - 400 Bad Request: Invalid location ID format, invalid request body, or validation error.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The location does not belong to the user's account.
+//   - 404 Not Found: The user or the location could not be found.
+func (h *StorageLocationHandler) UpdateStorageLocation(c *gin.Context) {
+	user, existingLocation, ok := h.getOwnedStorageLocation(c)
+	if !ok {
+		return
+	}
+
+	// Parse and validate the JSON request body with the updates
+	var location models.StorageLocation
+	if err := c.ShouldBindJSON(&location); err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+		return
+	}
+
+	// Preserve the original ID, AccountID and creation time to prevent them from being changed.
+	location.ID = existingLocation.ID
+	location.AccountID = user.AccountID
+	location.CreatedAt = existingLocation.CreatedAt
+
+	if err := h.service.UpdateStorageLocation(&location); err != nil {
+		errDetails := helpers.APIError{Code: "VALIDATION_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to update storage location.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the updated location object.
+	helpers.Success(c.Writer, http.StatusOK, "Storage location updated successfully.", location)
+}
+
+// DeleteStorageLocation deletes a storage location by ID.
+// Locations that still hold stock cannot be deleted; transfer the stock out or
+// deactivate the location instead.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// URL Parameters:
+//   - id: The location ID to delete (integer)
+//
+// Status Codes:
+//   - 200 OK: Location deleted successfully.
+//   - 400 Bad Request: Invalid location ID format.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The location does not belong to the user's account.
+//   - 404 Not Found: The user or the location could not be found.
+//   - 409 Conflict: The location still holds stock.
+func (h *StorageLocationHandler) DeleteStorageLocation(c *gin.Context) {
+	_, location, ok := h.getOwnedStorageLocation(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteStorageLocation(location.ID); err != nil {
+		errDetails := helpers.APIError{Code: "LOCATION_IN_USE", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusConflict, "Failed to delete storage location.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with a success message.
+	helpers.Success(c.Writer, http.StatusOK, "Storage location deleted successfully.", nil)
+}
+
+// GetLocationTransfers retrieves the transfers between the storage locations of
+// the authenticated user's account, newest first.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Status Codes:
+//   - 200 OK: Transfers retrieved successfully. The 'data' field contains a list of transfers.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *StorageLocationHandler) GetLocationTransfers(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	transfers, err := h.service.GetLocationTransfers(user.AccountID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to fetch location transfers.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the list of transfers in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Location transfers retrieved successfully.", transfers)
+}
+
+// TransferBetweenLocations moves stock of an item from one storage location to another.
+// Transfers change the stock at each location but not the account's total stock.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Request Body: JSON object with transfer details
+//   - inventory_item_id: The item being moved (int, required)
+//   - from_location_id: The location the stock leaves (int, required)
+//   - to_location_id: The location the stock arrives at (int, required)
+//   - quantity: Amount moved, in the item's unit (float64, required)
+//   - notes: Free-form notes (string, optional)
+//   - transferred_at: When the stock was moved (RFC 3339 timestamp, optional, defaults to now)
+//
+// Status Codes:
+//   - 201 Created: Transfer recorded successfully. The 'data' field contains the new transfer.
+//   - 400 Bad Request: Invalid request body or validation error.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+func (h *StorageLocationHandler) TransferBetweenLocations(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	// Parse and validate the JSON request body
+	var transfer models.LocationTransfer
+	if err := c.ShouldBindJSON(&transfer); err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+		return
+	}
+
+	// Scope the transfer to the authenticated user's account
+	transfer.ID = 0
+	transfer.AccountID = user.AccountID
+	transfer.TransferredBy = &user.ID
+
+	if err := h.service.TransferBetweenLocations(&transfer); err != nil {
+		errDetails := helpers.APIError{Code: "VALIDATION_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to record transfer.", errDetails)
+		return
+	}
+
+	// Return a 201 Created response with the new transfer.
+	helpers.Success(c.Writer, http.StatusCreated, "Transfer recorded successfully.", transfer)
+}
+
+// getUser resolves the authenticated user, writing the error response and
+// returning false when the user is not authenticated or does not exist.
+func (h *StorageLocationHandler) getUser(c *gin.Context) (*models.User, bool) {
+	// Extract user ID from context (set by AuthMiddleware)
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		errDetails := helpers.APIError{Code: "UNAUTHORIZED", Details: "User ID not found in request context."}
+		helpers.Error(c.Writer, http.StatusUnauthorized, "User not authenticated.", errDetails)
+		return nil, false
+	}
+	userID := userIDInterface.(int)
+
+	// Retrieve user details from database to get the AccountID
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "USER_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "User not found.", errDetails)
+		return nil, false
+	}
+	return user, true
+}
+
+// getOwnedStorageLocation resolves the authenticated user and the storage location
+// named by the ":id" URL parameter, writing the error response and returning false
+// when the location does not exist or belongs to another account.
+func (h *StorageLocationHandler) getOwnedStorageLocation(c *gin.Context) (*models.User, *models.StorageLocation, bool) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return nil, nil, false
+	}
+
+	// Parse and validate the location ID from the URL parameter
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: "Storage location ID must be a valid integer."}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid storage location ID.", errDetails)
+		return nil, nil, false
+	}
+
+	location, err := h.service.GetStorageLocation(id)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "LOCATION_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "Storage location not found.", errDetails)
+		return nil, nil, false
+	}
+
+	// Authorization check: Ensure the location belongs to the user's account
+	if location.AccountID != user.AccountID {
+		errDetails := helpers.APIError{Code: "FORBIDDEN", Details: "You do not have permission to access this storage location."}
+		helpers.Error(c.Writer, http.StatusForbidden, "Access denied.", errDetails)
+		return nil, nil, false
+	}
+
+	return user, location, true
+}
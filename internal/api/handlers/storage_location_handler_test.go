@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupStorageLocationTestHandler(t *testing.T) (*gin.Engine, *database.Service, *models.User, func()) {
+	router, service, user, cleanup := setupAuthenticatedTestRouter(t, "locations@example.com")
+	handler := NewStorageLocationHandler(service.DB())
+	inventoryHandler := NewInventoryHandler(service.DB())
+
+	api := router.Group("/api/v1")
+	api.GET("/locations", handler.GetStorageLocations)
+	api.POST("/locations", handler.CreateStorageLocation)
+	api.GET("/locations/:id", handler.GetStorageLocation)
+	api.PUT("/locations/:id", handler.UpdateStorageLocation)
+	api.DELETE("/locations/:id", handler.DeleteStorageLocation)
+	api.GET("/location-transfers", handler.GetLocationTransfers)
+	api.POST("/location-transfers", handler.TransferBetweenLocations)
+	api.GET("/inventory/items", inventoryHandler.GetInventoryItems)
+
+	return router, service, user, cleanup
+}
+
+func TestStorageLocationHandler_LocationsAndTransfers(t *testing.T) {
+	router, service, user, cleanup := setupStorageLocationTestHandler(t)
+	defer cleanup()
+
+	item := &models.InventoryItem{
+		AccountID:   user.AccountID,
+		Name:        "Lemons",
+		Unit:        "pieces",
+		CostPerUnit: 0.3,
+	}
+	require.NoError(t, service.CreateInventoryItem(item))
+
+	createLocation := func(name string) int {
+		req, w := createAuthenticatedRequest("POST", "/api/v1/locations", map[string]interface{}{"name": name}, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return int(response["data"].(map[string]interface{})["id"].(float64))
+	}
+	walkInID := createLocation("Walk-in")
+	barID := createLocation("Bar")
+
+	t.Run("Reject Duplicate Name", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("POST", "/api/v1/locations", map[string]interface{}{"name": "bar"}, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Get Location", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", fmt.Sprintf("/api/v1/locations/%d", walkInID), nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		location := response["data"].(map[string]interface{})
+		assert.Equal(t, true, location["is_default"])
+	})
+
+	t.Run("Transfer And Break Down Stock", func(t *testing.T) {
+		delivery := &models.Delivery{AccountID: user.AccountID, InventoryItemID: item.ID, Vendor: "Citrus Co", Quantity: 40, DeliveryDate: time.Now(), Cost: 12}
+		require.NoError(t, service.CreateDelivery(delivery))
+
+		transferData := map[string]interface{}{
+			"inventory_item_id": item.ID,
+			"from_location_id":  walkInID,
+			"to_location_id":    barID,
+			"quantity":          15,
+		}
+		req, w := createAuthenticatedRequest("POST", "/api/v1/location-transfers", transferData, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		req, w = createAuthenticatedRequest("GET", "/api/v1/location-transfers", nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		var transfers map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &transfers))
+		assert.Len(t, transfers["data"].([]interface{}), 1)
+
+		req, w = createAuthenticatedRequest("GET", "/api/v1/inventory/items?by_location=true", nil, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		items := response["data"].([]interface{})
+		require.Len(t, items, 1)
+		lemons := items[0].(map[string]interface{})
+		assert.Equal(t, 40.0, lemons["current_stock"])
+		locations := lemons["locations"].([]interface{})
+		require.Len(t, locations, 2)
+		assert.Equal(t, "Bar", locations[0].(map[string]interface{})["location_name"])
+		assert.Equal(t, 15.0, locations[0].(map[string]interface{})["quantity"])
+		assert.Equal(t, 25.0, locations[1].(map[string]interface{})["quantity"])
+	})
+
+	t.Run("Reject Invalid By Location", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/inventory/items?by_location=maybe", nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Delete Location Holding Stock", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("DELETE", fmt.Sprintf("/api/v1/locations/%d", barID), nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Delete Empty Location", func(t *testing.T) {
+		emptyID := createLocation("Patio")
+
+		req, w := createAuthenticatedRequest("DELETE", fmt.Sprintf("/api/v1/locations/%d", emptyID), nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		req, w = createAuthenticatedRequest("GET", fmt.Sprintf("/api/v1/locations/%d", emptyID), nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,392 @@
+// Package handlers provides HTTP request handlers for the application's API endpoints.
+// This package includes handlers for vendor management operations.
+// All handlers require authentication and operate within the context of user accounts.
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	helpers "github.com/mnadev/pantryos/internal/api/helper"
+
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VendorHandler handles HTTP requests related to vendor management operations.
+// It provides CRUD operations for the suppliers that deliver inventory items
+// to an account.
+type VendorHandler struct {
+	// service provides access to the business logic layer for database operations
+	service *database.Service
+}
+
+// NewVendorHandler creates a new VendorHandler instance with the provided database connection.
+// This function initializes the handler with a database service that handles all
+// business logic and data access operations.
+//
+// Parameters:
+//   - db: The database connection to use for operations
+//
+// Returns:
+//   - *VendorHandler: A new handler instance ready to handle HTTP requests
+func NewVendorHandler(db *database.DB) *VendorHandler {
+	return &VendorHandler{service: database.NewService(db)}
+}
+
+// GetVendors retrieves all vendors for the authenticated user's account.
+// Vendors are returned in alphabetical order.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Response:
+//
+//	All responses are wrapped in the standard APIResponse structure.
+//	- Success: { "success": true, "message": "...", "data": [...] }
+//	- Error:   { "success": false, "message": "...", "error": { "code": "...", "details": "..." } }
+//
+// Status Codes:
+//   - 200 OK: Vendors retrieved successfully. The 'data' field contains a list of vendors.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *VendorHandler) GetVendors(c *gin.Context) {
+	// Extract user ID from context (set by AuthMiddleware)
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		errDetails := helpers.APIError{Code: "UNAUTHORIZED", Details: "User ID not found in request context."}
+		helpers.Error(c.Writer, http.StatusUnauthorized, "User not authenticated.", errDetails)
+		return
+	}
+	userID := userIDInterface.(int)
+
+	// Retrieve user details from database to get the AccountID
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "USER_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "User not found.", errDetails)
+		return
+	}
+
+	// Get all vendors for the account
+	vendors, err := h.service.GetVendorsByAccount(user.AccountID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "DB_FETCH_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to fetch vendors.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the list of vendors in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Vendors retrieved successfully.", vendors)
+}
+
+// CreateVendor creates a new vendor for the authenticated user's account.
+// This endpoint accepts vendor details in JSON format. The account ID is
+// automatically set from the authenticated user.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Request Body: JSON object with vendor details
+//   - name: Vendor name (string, required)
+//   - contact_name, email, phone: Contact details (string, optional)
+//   - lead_time_days: Days between ordering and delivery (int, optional)
+//   - delivery_days: Comma-separated weekdays, e.g., "mon,thu" (string, optional)
+//   - minimum_order_value: Minimum order total (float64, optional)
+//
+// Response:
+//
+//	All responses are wrapped in the standard APIResponse structure.
+//	- Success: { "success": true, "message": "...", "data": ... }
+//	- Error:   { "success": false, "message": "...", "error": { "code": "...", "details": "..." } }
+//
+// Status Codes:
+//   - 201 Created: Vendor created successfully. The 'data' field contains the new vendor.
+//   - 400 Bad Request: Invalid request body or validation error.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+func (h *VendorHandler) CreateVendor(c *gin.Context) {
+	// Extract user ID from context (set by AuthMiddleware)
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		errDetails := helpers.APIError{Code: "UNAUTHORIZED", Details: "User ID not found in request context."}
+		helpers.Error(c.Writer, http.StatusUnauthorized, "User not authenticated.", errDetails)
+		return
+	}
+	userID := userIDInterface.(int)
+
+	// Retrieve user details from database to get the AccountID
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "USER_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "User not found.", errDetails)
+		return
+	}
+
+	// Parse and validate the JSON request body
+	var vendor models.Vendor
+	if err := c.ShouldBindJSON(&vendor); err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+		return
+	}
+
+	// Set account ID from the authenticated user to ensure proper scoping
+	vendor.ID = 0
+	vendor.AccountID = user.AccountID
+
+	// Create the vendor in the database
+	err = h.service.CreateVendor(&vendor)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "VALIDATION_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to create vendor.", errDetails)
+		return
+	}
+
+	// Return a 201 Created response with the newly created vendor.
+	helpers.Success(c.Writer, http.StatusCreated, "Vendor created successfully.", vendor)
+}
+
+// GetVendor retrieves a specific vendor by ID.
+// This endpoint validates that the vendor belongs to the authenticated user's
+// account before returning it.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// URL Parameters:
+//   - id: The vendor ID to retrieve (integer)
+//
+// Response:
+//
+//	All responses are wrapped in the standard APIResponse structure.
+//	- Success: { "success": true, "message": "...", "data": {...} }
+//	- Error:   { "success": false, "message": "...", "error": { "code": "...", "details": "..." } }
+//
+// Status Codes:
+//   - 200 OK: Vendor retrieved successfully. The 'data' field contains the vendor object.
+//   - 400 Bad Request: Invalid vendor ID format in URL.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The requested vendor does not belong to the user's account.
+//   - 404 Not Found: The user or the vendor could not be found.
+func (h *VendorHandler) GetVendor(c *gin.Context) {
+	_, vendor, ok := h.getOwnedVendor(c)
+	if !ok {
+		return
+	}
+
+	// Return a 200 OK response with the vendor object in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Vendor retrieved successfully.", vendor)
+}
+
+// UpdateVendor updates an existing vendor.
+// Fields left out of the request body keep their current values. Renaming a vendor
+// also updates the vendor name shown on its inventory items, deliveries, order items
+// and invoices.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// URL Parameters:
+//   - id: The vendor ID to update (integer)
+//
+// Response:
+//
+//	All responses are wrapped in the standard APIResponse structure.
+//	- Success: { "success": true, "message": "...", "data": {...} }
+//	- Error:   { "success": false, "message": "...", "error": { "code": "...", "details": "..." } }
+//
+// Status Codes:
+//   - 200 OK: Vendor updated successfully. The 'data' field contains the updated vendor object.
+//   - 400 Bad Request: Invalid vendor ID format, invalid request body, or validation error.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The vendor does not belong to the user's account.
+//   - 404 Not Found: The user or the vendor could not be found.
+func (h *VendorHandler) UpdateVendor(c *gin.Context) {
+	user, existingVendor, ok := h.getOwnedVendor(c)
+	if !ok {
+		return
+	}
+
+	// Apply the JSON request body over the stored vendor so omitted fields are kept
+	vendor := *existingVendor
+	if err := c.ShouldBindJSON(&vendor); err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+		return
+	}
+
+	// Preserve the original ID, AccountID and creation time to prevent them from being changed.
+	vendor.ID = existingVendor.ID
+	vendor.AccountID = user.AccountID
+	vendor.CreatedAt = existingVendor.CreatedAt
+
+	// Update the vendor in the database
+	err := h.service.UpdateVendor(&vendor)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "VALIDATION_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to update vendor.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the updated vendor object.
+	helpers.Success(c.Writer, http.StatusOK, "Vendor updated successfully.", vendor)
+}
+
+// DeleteVendor deletes a vendor by ID.
+// Vendors that are still assigned to inventory items, or that have recorded deliveries,
+// orders or invoices, cannot be deleted; deactivate them instead.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// URL Parameters:
+//   - id: The vendor ID to delete (integer)
+//
+// Response:
+//
+//	All responses are wrapped in the standard APIResponse structure.
+//	- Success: { "success": true, "message": "...", "data": null }
+//	- Error:   { "success": false, "message": "...", "error": { "code": "...", "details": "..." } }
+//
+// Status Codes:
+//   - 200 OK: Vendor deleted successfully.
+//   - 400 Bad Request: Invalid vendor ID format.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The vendor does not belong to the user's account.
+//   - 404 Not Found: The user or the vendor could not be found.
+//   - 409 Conflict: The vendor is still referenced by items or deliveries.
+func (h *VendorHandler) DeleteVendor(c *gin.Context) {
+	_, vendor, ok := h.getOwnedVendor(c)
+	if !ok {
+		return
+	}
+
+	// Attempt to delete the vendor
+	err := h.service.DeleteVendor(vendor.ID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "VENDOR_IN_USE", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusConflict, "Failed to delete vendor.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with a success message.
+	helpers.Success(c.Writer, http.StatusOK, "Vendor deleted successfully.", nil)
+}
+
+// GetVendorScorecards reports delivery performance for each vendor in the
+// authenticated user's account. Each scorecard compares order expected dates to
+// actual delivery dates, ordered to received quantities (fill rate), and the
+// unit prices paid over the period.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Query Parameters:
+//   - start_date: First day of the period, YYYY-MM-DD (optional, defaults to 30 days ago)
+//   - end_date: Last day of the period, YYYY-MM-DD (optional, defaults to today)
+//
+// Response:
+//
+//	All responses are wrapped in the standard APIResponse structure.
+//	- Success: { "success": true, "message": "...", "data": [...] }
+//	- Error:   { "success": false, "message": "...", "error": { "code": "...", "details": "..." } }
+//
+// Status Codes:
+//   - 200 OK: Scorecards generated successfully. The 'data' field contains a list of scorecards.
+//   - 400 Bad Request: Invalid date parameters.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *VendorHandler) GetVendorScorecards(c *gin.Context) {
+	// Extract user ID from context (set by AuthMiddleware)
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		errDetails := helpers.APIError{Code: "UNAUTHORIZED", Details: "User ID not found in request context."}
+		helpers.Error(c.Writer, http.StatusUnauthorized, "User not authenticated.", errDetails)
+		return
+	}
+	userID := userIDInterface.(int)
+
+	// Retrieve user details from database to get the AccountID
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "USER_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "User not found.", errDetails)
+		return
+	}
+
+	startDate, endDate, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	// Default to the last 30 days
+	if endDate.IsZero() {
+		endDate = time.Now()
+	}
+	if startDate.IsZero() {
+		startDate = endDate.AddDate(0, 0, -30)
+	}
+
+	scorecards, err := h.service.GetVendorScorecards(user.AccountID, startDate, endDate)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_DATE", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to generate vendor scorecards.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the scorecards in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Vendor scorecards generated successfully.", scorecards)
+}
+
+// getOwnedVendor resolves the authenticated user and the vendor named by the
+// ":id" URL parameter, writing the error response and returning false when the
+// user is not authenticated, the vendor does not exist, or it belongs to another account.
+func (h *VendorHandler) getOwnedVendor(c *gin.Context) (*models.User, *models.Vendor, bool) {
+	// Extract user ID from context (set by AuthMiddleware)
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		errDetails := helpers.APIError{Code: "UNAUTHORIZED", Details: "User ID not found in request context."}
+		helpers.Error(c.Writer, http.StatusUnauthorized, "User not authenticated.", errDetails)
+		return nil, nil, false
+	}
+	userID := userIDInterface.(int)
+
+	// Retrieve user details from database
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "USER_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "User not found.", errDetails)
+		return nil, nil, false
+	}
+
+	// Parse and validate the vendor ID from the URL parameter
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: "Vendor ID must be a valid integer."}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid Vendor ID.", errDetails)
+		return nil, nil, false
+	}
+
+	// Retrieve the vendor from the database
+	vendor, err := h.service.GetVendor(id)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "VENDOR_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "Vendor not found.", errDetails)
+		return nil, nil, false
+	}
+
+	// Authorization check: Ensure the vendor belongs to the user's account
+	if vendor.AccountID != user.AccountID {
+		errDetails := helpers.APIError{Code: "FORBIDDEN", Details: "You do not have permission to access this vendor."}
+		helpers.Error(c.Writer, http.StatusForbidden, "Access denied.", errDetails)
+		return nil, nil, false
+	}
+
+	return user, vendor, true
+}
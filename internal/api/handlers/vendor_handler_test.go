@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupVendorTestHandler(t *testing.T) (*gin.Engine, *database.Service, *models.User, func()) {
+	router, service, user, cleanup := setupAuthenticatedTestRouter(t, "vendor@example.com")
+	vendorHandler := NewVendorHandler(service.DB())
+	inventoryHandler := NewInventoryHandler(service.DB())
+
+	api := router.Group("/api/v1")
+	api.GET("/vendors", vendorHandler.GetVendors)
+	api.POST("/vendors", vendorHandler.CreateVendor)
+	api.GET("/vendors/:id", vendorHandler.GetVendor)
+	api.PUT("/vendors/:id", vendorHandler.UpdateVendor)
+	api.DELETE("/vendors/:id", vendorHandler.DeleteVendor)
+	api.GET("/reports/vendor-scorecard", vendorHandler.GetVendorScorecards)
+	api.GET("/deliveries/vendor/:vendor", inventoryHandler.GetDeliveriesByVendor)
+
+	return router, service, user, cleanup
+}
+
+func TestVendorHandler_CRUD(t *testing.T) {
+	router, _, user, cleanup := setupVendorTestHandler(t)
+	defer cleanup()
+
+	var vendorID int
+
+	t.Run("Create Vendor", func(t *testing.T) {
+		vendorData := map[string]interface{}{
+			"name":                "  Local   Dairy ",
+			"email":               "orders@localdairy.com",
+			"phone":               "555-0100",
+			"lead_time_days":      2,
+			"delivery_days":       "Mon, Thu",
+			"minimum_order_value": 50.0,
+		}
+
+		req, w := createAuthenticatedRequest("POST", "/api/v1/vendors", vendorData, user.ID)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		vendor := response["data"].(map[string]interface{})
+		assert.Equal(t, "Local Dairy", vendor["name"])
+		assert.Equal(t, "mon,thu", vendor["delivery_days"])
+		assert.Equal(t, float64(user.AccountID), vendor["account_id"])
+		vendorID = int(vendor["id"].(float64))
+	})
+
+	t.Run("Reject Duplicate Vendor Name", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("POST", "/api/v1/vendors", map[string]interface{}{"name": "LOCAL DAIRY"}, user.ID)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Reject Invalid Delivery Day", func(t *testing.T) {
+		vendorData := map[string]interface{}{"name": "Bakery", "delivery_days": "mon,someday"}
+		req, w := createAuthenticatedRequest("POST", "/api/v1/vendors", vendorData, user.ID)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Update Vendor", func(t *testing.T) {
+		vendorData := map[string]interface{}{"name": "Local Dairy Co.", "lead_time_days": 3, "is_active": true}
+		req, w := createAuthenticatedRequest("PUT", fmt.Sprintf("/api/v1/vendors/%d", vendorID), vendorData, user.ID)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		vendor := response["data"].(map[string]interface{})
+		assert.Equal(t, "Local Dairy Co.", vendor["name"])
+		assert.Equal(t, float64(3), vendor["lead_time_days"])
+		assert.Equal(t, "orders@localdairy.com", vendor["email"], "omitted fields keep their values")
+	})
+
+	t.Run("Partial Update Keeps Vendor Active", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("PUT", fmt.Sprintf("/api/v1/vendors/%d", vendorID), map[string]interface{}{"phone": "555-0199"}, user.ID)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		vendor := response["data"].(map[string]interface{})
+		assert.Equal(t, "555-0199", vendor["phone"])
+		assert.Equal(t, "Local Dairy Co.", vendor["name"])
+		assert.Equal(t, "mon,thu", vendor["delivery_days"])
+		assert.Equal(t, true, vendor["is_active"])
+	})
+
+	t.Run("List Vendors", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/vendors", nil, user.ID)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		assert.Len(t, response["data"].([]interface{}), 1)
+	})
+
+	t.Run("Delete Vendor", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("DELETE", fmt.Sprintf("/api/v1/vendors/%d", vendorID), nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		req, w = createAuthenticatedRequest("GET", fmt.Sprintf("/api/v1/vendors/%d", vendorID), nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestVendorHandler_DeliveriesMatchVendorSpellings(t *testing.T) {
+	router, service, user, cleanup := setupVendorTestHandler(t)
+	defer cleanup()
+
+	item := &models.InventoryItem{
+		AccountID:       user.AccountID,
+		Name:            "Milk",
+		Unit:            "liters",
+		PreferredVendor: "Local Dairy",
+	}
+	require.NoError(t, service.CreateInventoryItem(item))
+	require.NotNil(t, item.VendorID)
+
+	// Record deliveries with inconsistent spellings of the same vendor
+	for _, vendorName := range []string{"Local Dairy", "local dairy", " LOCAL  DAIRY"} {
+		delivery := &models.Delivery{
+			AccountID:       user.AccountID,
+			InventoryItemID: item.ID,
+			Vendor:          vendorName,
+			Quantity:        10,
+		}
+		require.NoError(t, service.CreateDelivery(delivery))
+		assert.Equal(t, *item.VendorID, *delivery.VendorID)
+		assert.Equal(t, "Local Dairy", delivery.Vendor)
+	}
+
+	req, w := createAuthenticatedRequest("GET", "/api/v1/deliveries/vendor/local%20DAIRY", nil, user.ID)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	assert.Len(t, response["data"].([]interface{}), 3)
+
+	// A vendor that still has deliveries cannot be deleted
+	req, w = createAuthenticatedRequest("DELETE", fmt.Sprintf("/api/v1/vendors/%d", *item.VendorID), nil, user.ID)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestVendorHandler_GetVendorScorecards(t *testing.T) {
+	router, service, user, cleanup := setupVendorTestHandler(t)
+	defer cleanup()
+
+	item := &models.InventoryItem{
+		AccountID:       user.AccountID,
+		Name:            "Milk",
+		Unit:            "liters",
+		PreferredVendor: "Local Dairy",
+	}
+	require.NoError(t, service.CreateInventoryItem(item))
+
+	order := &models.Order{
+		AccountID:    user.AccountID,
+		OrderDate:    time.Date(2024, time.March, 4, 9, 0, 0, 0, time.UTC),
+		ExpectedDate: time.Date(2024, time.March, 6, 9, 0, 0, 0, time.UTC),
+		CreatedBy:    user.ID,
+	}
+	require.NoError(t, service.CreateOrder(order, []models.OrderItem{{InventoryItemID: item.ID, Quantity: 20, UnitCost: 1.5}}))
+
+	delivery := &models.Delivery{
+		AccountID:       user.AccountID,
+		InventoryItemID: item.ID,
+		Vendor:          "Local Dairy",
+		Quantity:        15,
+		DeliveryDate:    time.Date(2024, time.March, 6, 7, 0, 0, 0, time.UTC),
+		Cost:            22.5,
+	}
+	require.NoError(t, service.CreateDelivery(delivery))
+
+	t.Run("Scorecard For Period", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/reports/vendor-scorecard?start_date=2024-03-01&end_date=2024-03-31", nil, user.ID)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		scorecards := response["data"].([]interface{})
+		require.Len(t, scorecards, 1)
+		scorecard := scorecards[0].(map[string]interface{})
+		assert.Equal(t, "Local Dairy", scorecard["vendor_name"])
+		assert.Equal(t, float64(100), scorecard["on_time_rate"])
+		assert.Equal(t, float64(75), scorecard["fill_rate"])
+	})
+
+	t.Run("Invalid Date", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/reports/vendor-scorecard?start_date=03/01/2024", nil, user.ID)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,251 @@
+// Package handlers provides HTTP request handlers for the application's API endpoints.
+// This package includes handlers for waste logging and waste cost reporting.
+// All handlers require authentication and operate within the context of user accounts.
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	helpers "github.com/mnadev/pantryos/internal/api/helper"
+
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WasteHandler handles HTTP requests related to discarded stock.
+// Staff record waste with a reason, which reduces current stock, updates the
+// item's wastage rate, and feeds the waste cost report.
+type WasteHandler struct {
+	// service provides access to the business logic layer for database operations
+	service *database.Service
+}
+
+// NewWasteHandler creates a new WasteHandler instance with the provided database connection.
+// This function initializes the handler with a database service that handles all
+// business logic and data access operations.
+//
+// Parameters:
+//   - db: The database connection to use for operations
+//
+// Returns:
+//   - *WasteHandler: A new handler instance ready to handle HTTP requests
+func NewWasteHandler(db *database.DB) *WasteHandler {
+	return &WasteHandler{service: database.NewService(db)}
+}
+
+// LogWaste records a discarded quantity of an inventory item.
+// The waste is automatically associated with the authenticated user's account.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: The item must belong to the user's account
+//
+// Request Body: JSON object
+//   - inventory_item_id: The discarded item (int, required)
+//   - quantity: The discarded quantity (float64, required)
+//   - reason: "expired", "spilled", "comped", or "overproduced" (string, required)
+//   - notes: Free-text details (string, optional)
+//   - wasted_at: When the stock was discarded (RFC 3339, optional, defaults to now)
+//
+// Response:
+//
+//	All responses are wrapped in the standard APIResponse structure.
+//	- Success: { "success": true, "message": "...", "data": ... }
+//	- Error:   { "success": false, "message": "...", "error": { "code": "...", "details": "..." } }
+//
+// Status Codes:
+//   - 201 Created: Waste recorded successfully. The 'data' field contains the new entry.
+//   - 400 Bad Request: Invalid request body, reason, quantity, or item.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+func (h *WasteHandler) LogWaste(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	// Parse and validate the JSON request body
+	var wasteLog models.WasteLog
+	if err := c.ShouldBindJSON(&wasteLog); err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid request body.", errDetails)
+		return
+	}
+
+	// Scope the entry to the authenticated user's account
+	wasteLog.ID = 0
+	wasteLog.AccountID = user.AccountID
+	wasteLog.RecordedBy = &user.ID
+
+	if err := h.service.LogWaste(&wasteLog); err != nil {
+		errDetails := helpers.APIError{Code: "VALIDATION_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to record waste.", errDetails)
+		return
+	}
+
+	// Return a 201 Created response with the new entry.
+	helpers.Success(c.Writer, http.StatusCreated, "Waste recorded successfully.", wasteLog)
+}
+
+// GetWasteLogs retrieves the waste entries of the authenticated user's account, newest first.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Query Parameters:
+//   - start_date: First day of the period, YYYY-MM-DD (optional, defaults to 30 days before end_date)
+//   - end_date: Last day of the period, YYYY-MM-DD (optional, defaults to today)
+//
+// Status Codes:
+//   - 200 OK: Entries retrieved successfully. The 'data' field contains a list of entries.
+//   - 400 Bad Request: Invalid date parameters.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+func (h *WasteHandler) GetWasteLogs(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	startDate, endDate, ok := parseWastePeriod(c)
+	if !ok {
+		return
+	}
+
+	wasteLogs, err := h.service.GetWasteLogs(user.AccountID, startDate, endDate)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_DATE", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to fetch waste entries.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the list of entries in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Waste entries retrieved successfully.", wasteLogs)
+}
+
+// DeleteWasteLog removes a waste entry that was recorded in error.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: The entry must belong to the user's account
+//
+// Status Codes:
+//   - 200 OK: Entry deleted successfully.
+//   - 400 Bad Request: Invalid entry ID.
+//   - 401 Unauthorized: User not authenticated.
+//   - 403 Forbidden: The entry does not belong to the user's account.
+//   - 404 Not Found: The user or the entry could not be found.
+//   - 500 Internal Server Error: Database or other service error.
+func (h *WasteHandler) DeleteWasteLog(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	// Parse and validate the entry ID from the URL parameter
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_INPUT", Details: "Waste entry ID must be a valid integer."}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Invalid waste entry ID.", errDetails)
+		return
+	}
+
+	wasteLog, err := h.service.GetWasteLog(id)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "WASTE_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "Waste entry not found.", errDetails)
+		return
+	}
+
+	// Authorization check: Ensure the entry belongs to the user's account
+	if wasteLog.AccountID != user.AccountID {
+		errDetails := helpers.APIError{Code: "FORBIDDEN", Details: "You do not have permission to delete this waste entry."}
+		helpers.Error(c.Writer, http.StatusForbidden, "Access denied.", errDetails)
+		return
+	}
+
+	if err := h.service.DeleteWasteLog(id); err != nil {
+		errDetails := helpers.APIError{Code: "DB_DELETE_FAILED", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusInternalServerError, "Failed to delete waste entry.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response confirming deletion.
+	helpers.Success(c.Writer, http.StatusOK, "Waste entry deleted successfully.", nil)
+}
+
+// GetWasteCostReport returns the cost of waste in a period grouped by reason and by item category.
+//
+// Authentication: Required (JWT token in Authorization header)
+// Authorization: User must be authenticated and have access to the account
+//
+// Query Parameters:
+//   - start_date: First day of the period, YYYY-MM-DD (optional, defaults to 30 days before end_date)
+//   - end_date: Last day of the period, YYYY-MM-DD (optional, defaults to today)
+//
+// Status Codes:
+//   - 200 OK: Report generated successfully.
+//   - 400 Bad Request: Invalid date parameters.
+//   - 401 Unauthorized: User not authenticated.
+//   - 404 Not Found: User associated with token not found in the database.
+func (h *WasteHandler) GetWasteCostReport(c *gin.Context) {
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	startDate, endDate, ok := parseWastePeriod(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.GetWasteCostReport(user.AccountID, startDate, endDate)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "INVALID_DATE", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusBadRequest, "Failed to generate waste cost report.", errDetails)
+		return
+	}
+
+	// Return a 200 OK response with the report in the data field.
+	helpers.Success(c.Writer, http.StatusOK, "Waste cost report generated successfully.", report)
+}
+
+// parseWastePeriod reads the report period from the query string, defaulting to the last 30 days.
+func parseWastePeriod(c *gin.Context) (startDate, endDate time.Time, ok bool) {
+	startDate, endDate, ok = parseDateRange(c)
+	if !ok {
+		return startDate, endDate, false
+	}
+
+	if endDate.IsZero() {
+		endDate = time.Now()
+	}
+	if startDate.IsZero() {
+		startDate = endDate.AddDate(0, 0, -30)
+	}
+	return startDate, endDate, true
+}
+
+// getUser resolves the authenticated user, writing the error response and
+// returning false when the user is not authenticated or does not exist.
+func (h *WasteHandler) getUser(c *gin.Context) (*models.User, bool) {
+	// Extract user ID from context (set by AuthMiddleware)
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		errDetails := helpers.APIError{Code: "UNAUTHORIZED", Details: "User ID not found in request context."}
+		helpers.Error(c.Writer, http.StatusUnauthorized, "User not authenticated.", errDetails)
+		return nil, false
+	}
+	userID := userIDInterface.(int)
+
+	// Retrieve user details from database to get the AccountID
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		errDetails := helpers.APIError{Code: "USER_NOT_FOUND", Details: err.Error()}
+		helpers.Error(c.Writer, http.StatusNotFound, "User not found.", errDetails)
+		return nil, false
+	}
+	return user, true
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupWasteTestHandler(t *testing.T) (*gin.Engine, *database.Service, *models.User, func()) {
+	router, service, user, cleanup := setupAuthenticatedTestRouter(t, "waste@example.com")
+	handler := NewWasteHandler(service.DB())
+
+	api := router.Group("/api/v1")
+	api.GET("/waste", handler.GetWasteLogs)
+	api.POST("/waste", handler.LogWaste)
+	api.DELETE("/waste/:id", handler.DeleteWasteLog)
+	api.GET("/reports/waste", handler.GetWasteCostReport)
+
+	return router, service, user, cleanup
+}
+
+func TestWasteHandler_LogAndReport(t *testing.T) {
+	router, service, user, cleanup := setupWasteTestHandler(t)
+	defer cleanup()
+
+	item := &models.InventoryItem{
+		AccountID:   user.AccountID,
+		Name:        "Croissants",
+		Unit:        "pieces",
+		CostPerUnit: 1.25,
+	}
+	require.NoError(t, service.CreateInventoryItem(item))
+
+	var wasteID int
+
+	t.Run("Log Waste", func(t *testing.T) {
+		wasteData := map[string]interface{}{
+			"inventory_item_id": item.ID,
+			"quantity":          8,
+			"reason":            "overproduced",
+			"notes":             "End of day",
+		}
+		req, w := createAuthenticatedRequest("POST", "/api/v1/waste", wasteData, user.ID)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		wasteLog := response["data"].(map[string]interface{})
+		assert.Equal(t, float64(user.AccountID), wasteLog["account_id"])
+		assert.Equal(t, float64(user.ID), wasteLog["recorded_by"])
+		assert.Equal(t, 1.25, wasteLog["unit_cost"])
+		wasteID = int(wasteLog["id"].(float64))
+	})
+
+	t.Run("Reject Invalid Reason", func(t *testing.T) {
+		wasteData := map[string]interface{}{"inventory_item_id": item.ID, "quantity": 1, "reason": "lost"}
+		req, w := createAuthenticatedRequest("POST", "/api/v1/waste", wasteData, user.ID)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("List Waste", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/waste", nil, user.ID)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		assert.Len(t, response["data"].([]interface{}), 1)
+	})
+
+	t.Run("Waste Cost Report", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("GET", "/api/v1/reports/waste", nil, user.ID)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		report := response["data"].(map[string]interface{})
+		assert.Equal(t, 10.0, report["total_cost"])
+		byReason := report["by_reason"].([]interface{})
+		require.Len(t, byReason, 1)
+		assert.Equal(t, "overproduced", byReason[0].(map[string]interface{})["name"])
+	})
+
+	t.Run("Delete Waste", func(t *testing.T) {
+		req, w := createAuthenticatedRequest("DELETE", fmt.Sprintf("/api/v1/waste/%d", wasteID), nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		req, w = createAuthenticatedRequest("DELETE", fmt.Sprintf("/api/v1/waste/%d", wasteID), nil, user.ID)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
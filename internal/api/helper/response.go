@@ -12,11 +12,20 @@ type APIError struct {
 	Details string `json:"details,omitempty"`
 }
 
+// Pagination describes the page of a list returned in an APIResponse's data
+type Pagination struct {
+	Limit   int   `json:"limit"`
+	Offset  int   `json:"offset"`
+	Total   int64 `json:"total"`
+	HasMore bool  `json:"has_more"`
+}
+
 type APIResponse struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   *APIError   `json:"error,omitempty"`
+	Success    bool        `json:"success"`
+	Message    string      `json:"message"`
+	Data       interface{} `json:"data,omitempty"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+	Error      *APIError   `json:"error,omitempty"`
 }
 
 func sendJSON(w http.ResponseWriter, statusCode int, payload interface{}) {
@@ -42,6 +51,17 @@ func Success(w http.ResponseWriter, statusCode int, message string, data interfa
 	sendJSON(w, statusCode, response)
 }
 
+// SuccessPage sends one page of a list along with its pagination metadata
+func SuccessPage(w http.ResponseWriter, statusCode int, message string, data interface{}, pagination Pagination) {
+	response := APIResponse{
+		Success:    true,
+		Message:    message,
+		Data:       data,
+		Pagination: &pagination,
+	}
+	sendJSON(w, statusCode, response)
+}
+
 func Error(w http.ResponseWriter, statusCode int, message string, errDetails APIError) {
 	response := APIResponse{
 		Success: false,
@@ -51,6 +71,17 @@ func Error(w http.ResponseWriter, statusCode int, message string, errDetails API
 	sendJSON(w, statusCode, response)
 }
 
+// ErrorWithData sends an error along with data describing it, such as per-row validation errors
+func ErrorWithData(w http.ResponseWriter, statusCode int, message string, errDetails APIError, data interface{}) {
+	response := APIResponse{
+		Success: false,
+		Message: message,
+		Data:    data,
+		Error:   &errDetails,
+	}
+	sendJSON(w, statusCode, response)
+}
+
 type GetUserSuccessData struct {
 	ID        int       `json:"id"`
 	Email     string    `json:"email"`
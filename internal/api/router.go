@@ -14,6 +14,22 @@ func SetupRouter(router *gin.Engine, db *database.DB) {
 	inventoryHandler := handlers.NewInventoryHandler(db)
 	categoryHandler := handlers.NewCategoryHandler(db)
 	emailHandler := handlers.NewEmailHandler(db)
+	emailTemplateHandler := handlers.NewEmailTemplateHandler(db)
+	vendorHandler := handlers.NewVendorHandler(db)
+	priceHandler := handlers.NewPriceHandler(db)
+	lotHandler := handlers.NewLotHandler(db)
+	wasteHandler := handlers.NewWasteHandler(db)
+	stockAdjustmentHandler := handlers.NewStockAdjustmentHandler(db)
+	countSessionHandler := handlers.NewCountSessionHandler(db)
+	storageLocationHandler := handlers.NewStorageLocationHandler(db)
+	stockTransferHandler := handlers.NewStockTransferHandler(db)
+	catalogHandler := handlers.NewCatalogHandler(db)
+	searchHandler := handlers.NewSearchHandler(db)
+	exportHandler := handlers.NewExportHandler(db)
+	reportHandler := handlers.NewReportHandler(db)
+	orderHandler := handlers.NewOrderHandler(db)
+	invoiceHandler := handlers.NewInvoiceHandler(db)
+	budgetHandler := handlers.NewBudgetHandler(db)
 
 	// Public routes for authentication
 	authRoutes := router.Group("/auth")
@@ -48,9 +64,67 @@ func SetupRouter(router *gin.Engine, db *database.DB) {
 		v1.GET("/inventory/items", inventoryHandler.GetInventoryItems)
 		v1.GET("/inventory/items/low-stock", inventoryHandler.GetLowStockItems)
 		v1.POST("/inventory/items", inventoryHandler.CreateInventoryItem)
+		v1.POST("/inventory/items/import", inventoryHandler.ImportInventoryItems)
 		v1.GET("/inventory/items/:id", inventoryHandler.GetInventoryItem)
 		v1.PUT("/inventory/items/:id", inventoryHandler.UpdateInventoryItem)
 		v1.DELETE("/inventory/items/:id", inventoryHandler.DeleteInventoryItem)
+		v1.GET("/inventory/items/:id/ledger", inventoryHandler.GetInventoryItemLedger)
+
+		// Price history and costing routes
+		v1.GET("/inventory/items/:id/price-history", priceHandler.GetPriceHistory)
+		v1.POST("/inventory/items/:id/recompute-cost", priceHandler.RecomputeItemCost)
+		v1.GET("/price-alerts", priceHandler.GetPriceAlerts)
+		v1.GET("/settings/costing", priceHandler.GetCostSettings)
+		v1.PUT("/settings/costing", priceHandler.UpdateCostSettings)
+		v1.GET("/reports/valuation", priceHandler.GetInventoryValuation)
+
+		// Inventory lot and expiration routes
+		v1.GET("/inventory/items/:id/lots", lotHandler.GetItemLots)
+		v1.GET("/inventory/lots/expiring", lotHandler.GetExpiringLots)
+
+		// Waste routes
+		v1.GET("/waste", wasteHandler.GetWasteLogs)
+		v1.POST("/waste", wasteHandler.LogWaste)
+		v1.DELETE("/waste/:id", wasteHandler.DeleteWasteLog)
+		v1.GET("/reports/waste", wasteHandler.GetWasteCostReport)
+
+		// Search across the account's records
+		v1.GET("/search", searchHandler.Search)
+
+		// Spreadsheet exports of the account's records
+		v1.GET("/exports/:dataset", exportHandler.Export)
+
+		// PDF reports and purchase orders
+		v1.GET("/reports/stock/pdf", reportHandler.GetStockReportPDF)
+		v1.GET("/reports/supply-chain/pdf", reportHandler.GetSupplyChainReportPDF)
+		v1.GET("/orders/:id/purchase-order/pdf", reportHandler.GetPurchaseOrderPDF)
+
+		// Purchase orders emailed to vendors; sending an order places it
+		v1.POST("/orders/:id/send", orderHandler.SendOrder)
+		v1.GET("/orders/:id/dispatches", orderHandler.GetOrderDispatches)
+		v1.POST("/orders/:id/receive", orderHandler.ReceiveOrder)
+		v1.GET("/orders/:id/receipts", orderHandler.GetOrderReceipts)
+
+		// Vendor invoices matched against orders and deliveries
+		v1.GET("/invoices", invoiceHandler.GetInvoices)
+		v1.POST("/invoices", invoiceHandler.CreateInvoice)
+		v1.GET("/invoices/:id", invoiceHandler.GetInvoice)
+		v1.POST("/invoices/:id/match", invoiceHandler.MatchInvoice)
+		v1.POST("/invoices/:id/approve", invoiceHandler.ApproveInvoice)
+		v1.GET("/settings/invoice-matching", invoiceHandler.GetInvoiceMatchSettings)
+		v1.PUT("/settings/invoice-matching", invoiceHandler.UpdateInvoiceMatchSettings)
+
+		// Category purchasing budgets and budget-vs-actual reports
+		v1.GET("/budgets", budgetHandler.GetBudgets)
+		v1.POST("/budgets", budgetHandler.CreateBudget)
+		v1.PUT("/budgets/:id", budgetHandler.UpdateBudget)
+		v1.DELETE("/budgets/:id", budgetHandler.DeleteBudget)
+		v1.GET("/reports/budgets", budgetHandler.GetBudgetReport)
+
+		// Stock adjustment routes
+		v1.GET("/stock-adjustments", stockAdjustmentHandler.GetStockAdjustments)
+		v1.POST("/stock-adjustments", stockAdjustmentHandler.RecordStockAdjustment)
+		v1.GET("/stock-adjustments/:id", stockAdjustmentHandler.GetStockAdjustment)
 
 		// Menu item routes
 		v1.GET("/menu/items", inventoryHandler.GetMenuItems)
@@ -62,12 +136,60 @@ func SetupRouter(router *gin.Engine, db *database.DB) {
 		v1.GET("/deliveries/vendor/:vendor", inventoryHandler.GetDeliveriesByVendor)
 
 		// Vendor routes
+		v1.GET("/vendors", vendorHandler.GetVendors)
+		v1.POST("/vendors", vendorHandler.CreateVendor)
+		v1.GET("/vendors/:id", vendorHandler.GetVendor)
+		v1.PUT("/vendors/:id", vendorHandler.UpdateVendor)
+		v1.DELETE("/vendors/:id", vendorHandler.DeleteVendor)
+		v1.GET("/reports/vendor-scorecard", vendorHandler.GetVendorScorecards)
 		v1.GET("/inventory/vendor/:vendor", inventoryHandler.GetInventoryItemsByVendor)
 
 		// Snapshot routes for inventory counts
 		v1.GET("/snapshots", inventoryHandler.GetInventorySnapshots)
 		v1.POST("/snapshots", inventoryHandler.CreateInventorySnapshot)
 
+		// Count session routes for guided physical counts
+		v1.GET("/count-sessions", countSessionHandler.GetCountSessions)
+		v1.POST("/count-sessions", countSessionHandler.StartCountSession)
+		v1.GET("/count-sessions/:id", countSessionHandler.GetCountSession)
+		v1.POST("/count-sessions/:id/counts", countSessionHandler.RecordCount)
+		v1.GET("/count-sessions/:id/review", countSessionHandler.ReviewCountSession)
+		v1.POST("/count-sessions/:id/finalize", countSessionHandler.FinalizeCountSession)
+		v1.POST("/count-sessions/:id/abandon", countSessionHandler.AbandonCountSession)
+
+		// Storage location routes
+		v1.GET("/locations", storageLocationHandler.GetStorageLocations)
+		v1.POST("/locations", storageLocationHandler.CreateStorageLocation)
+		v1.GET("/locations/:id", storageLocationHandler.GetStorageLocation)
+		v1.PUT("/locations/:id", storageLocationHandler.UpdateStorageLocation)
+		v1.DELETE("/locations/:id", storageLocationHandler.DeleteStorageLocation)
+		v1.GET("/location-transfers", storageLocationHandler.GetLocationTransfers)
+		v1.POST("/location-transfers", storageLocationHandler.TransferBetweenLocations)
+
+		// Stock transfer routes between the accounts of an organization
+		v1.GET("/stock-transfers", stockTransferHandler.GetStockTransfers)
+		v1.POST("/stock-transfers", stockTransferHandler.SendStockTransfer)
+		v1.GET("/stock-transfers/:id", stockTransferHandler.GetStockTransfer)
+		v1.POST("/stock-transfers/:id/receive", stockTransferHandler.ReceiveStockTransfer)
+		v1.POST("/stock-transfers/:id/cancel", stockTransferHandler.CancelStockTransfer)
+
+		// Organization catalog routes
+		v1.GET("/catalog/categories", catalogHandler.GetCatalogCategories)
+		v1.POST("/catalog/categories", catalogHandler.CreateCatalogCategory)
+		v1.PUT("/catalog/categories/:id", catalogHandler.UpdateCatalogCategory)
+		v1.GET("/catalog/items", catalogHandler.GetCatalogItems)
+		v1.POST("/catalog/items", catalogHandler.CreateCatalogItem)
+		v1.PUT("/catalog/items/:id", catalogHandler.UpdateCatalogItem)
+		v1.GET("/catalog/menu-items", catalogHandler.GetCatalogMenuItems)
+		v1.POST("/catalog/menu-items", catalogHandler.CreateCatalogMenuItem)
+		v1.GET("/catalog/menu-items/:id", catalogHandler.GetCatalogMenuItem)
+		v1.PUT("/catalog/menu-items/:id", catalogHandler.UpdateCatalogMenuItem)
+		v1.GET("/catalog/subscription", catalogHandler.GetCatalogSubscription)
+		v1.POST("/catalog/subscription", catalogHandler.SubscribeToCatalog)
+		v1.DELETE("/catalog/subscription", catalogHandler.UnsubscribeFromCatalog)
+		v1.POST("/catalog/sync", catalogHandler.SyncCatalog)
+		v1.GET("/catalog/conflicts", catalogHandler.GetCatalogSyncConflicts)
+
 		// Email routes
 		v1.POST("/email/verification/:user_id", emailHandler.SendVerificationEmail)
 		v1.GET("/email/verify", emailHandler.VerifyEmail)
@@ -75,6 +197,13 @@ func SetupRouter(router *gin.Engine, db *database.DB) {
 		v1.POST("/email/weekly-supply-chain/:account_id", emailHandler.SendWeeklySupplyChainReport)
 		v1.POST("/email/low-stock-alert/:account_id", emailHandler.SendLowStockAlert)
 
+		// Email template and language routes
+		v1.GET("/email/templates", emailTemplateHandler.GetEmailTemplates)
+		v1.GET("/email/templates/:name/preview", emailTemplateHandler.PreviewEmailTemplate)
+		v1.PUT("/email/templates/:name/:locale", emailTemplateHandler.SaveEmailTemplate)
+		v1.DELETE("/email/templates/:name/:locale", emailTemplateHandler.DeleteEmailTemplate)
+		v1.PUT("/settings/email-language", emailTemplateHandler.UpdateEmailLanguage)
+
 		// Email schedule management routes
 		v1.GET("/accounts/:account_id/email-schedules", handlers.GetEmailSchedules)
 		v1.GET("/accounts/:account_id/email-schedules/:emailType", handlers.GetEmailSchedule)
@@ -0,0 +1,788 @@
+package database
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/mnadev/pantryos/internal/models"
+	"gorm.io/gorm"
+)
+
+// Account backup archives
+// A backup is a gzip-compressed tarball holding manifest.json followed by one JSON Lines
+// file per kind of record, in the order of backupSections. Records keep the IDs they had
+// in the backed up account; restoring assigns new IDs and rewrites every reference to
+// them, so an archive can be restored into any empty account, including one in another
+// organization or database.
+
+// BackupFormat identifies account backup archives in their manifest
+const BackupFormat = "pantryos-account-backup"
+
+// BackupFormatVersion is the version of the archives written by BackupAccount. Restoring
+// accepts archives of this version or older.
+const BackupFormatVersion = 1
+
+// backupManifestName is the name of the first file of an archive
+const backupManifestName = "manifest.json"
+
+// backupSections are the kinds of records in an archive, each stored in <section>.jsonl.
+// Records only refer to records of earlier sections, which is the order they are restored in,
+// except for deliveries, which are linked to the order items they were received against once
+// those are restored.
+var backupSections = []string{
+	"categories", "vendors", "storage_locations", "inventory_items", "menu_items", "recipe_ingredients",
+	"inventory_snapshots", "deliveries", "inventory_lots", "price_history", "waste_logs",
+	"stock_adjustments", "location_transfers", "orders", "order_items", "sales", "email_schedules", "budgets",
+	"invoices", "invoice_lines",
+}
+
+// ErrInvalidBackup is returned when an archive is not a readable account backup
+var ErrInvalidBackup = errors.New("invalid account backup")
+
+// ErrAccountNotEmpty is returned when restoring into an account that already has records
+var ErrAccountNotEmpty = errors.New("backups can only be restored into an empty account")
+
+// BackupManifest describes the contents of an account backup archive
+type BackupManifest struct {
+	Format    string         `json:"format"`
+	Version   int            `json:"version"`
+	CreatedAt time.Time      `json:"created_at"`
+	Account   models.Account `json:"account"` // The account as it was when backed up
+	Records   map[string]int `json:"records"` // Number of records in each section
+}
+
+// BackupRestoreReport describes the outcome of restoring an account backup
+type BackupRestoreReport struct {
+	AccountID int            `json:"account_id"`
+	Manifest  BackupManifest `json:"manifest"`
+	Restored  map[string]int `json:"restored"` // Number of records restored in each section
+	// Skipped counts the records of each section left out because a record they require,
+	// such as the inventory item of a delivery, was not in the archive
+	Skipped map[string]int `json:"skipped"`
+}
+
+// BackupAccount writes an archive of every record of an account: its categories, vendors,
+// storage locations, inventory and menu items with their recipes, snapshots, deliveries,
+// lots, price history, waste, adjustments, transfers between its locations, orders, sales,
+// email schedules, budgets, and vendor invoices. Each section is staged in a temporary file so that accounts of any
+// size are archived without being held in memory.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account to back up
+//   - w: Where the archive is written
+//
+// Returns:
+//   - *BackupManifest: The manifest written at the start of the archive
+//   - error: An invalid account, a database error, or an error writing the archive
+//
+// Business rules:
+//   - Users, invitations, and transfers to other accounts belong to more than one account and are not included
+//   - Current stock levels are not included; restoring rebuilds them from the restored history
+//   - Order receipts and dispatches are not included; the deliveries and received quantities they recorded are
+//   - Budget alerts are not included
+func (s *Service) BackupAccount(accountID int, w io.Writer) (*BackupManifest, error) {
+	account, err := s.accounts.GetByID(accountID)
+	if err != nil {
+		return nil, errors.New("invalid account ID")
+	}
+
+	dir, err := os.MkdirTemp("", "pantryos-backup-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	manifest := &BackupManifest{
+		Format:    BackupFormat,
+		Version:   BackupFormatVersion,
+		CreatedAt: time.Now().UTC(),
+		Account:   *account,
+		Records:   make(map[string]int, len(backupSections)),
+	}
+	for _, section := range backupSections {
+		if manifest.Records[section], err = s.stageBackupSection(accountID, section, filepath.Join(dir, section+".jsonl")); err != nil {
+			return nil, fmt.Errorf("backing up %s: %w", section, err)
+		}
+	}
+
+	compressed := gzip.NewWriter(w)
+	archive := tar.NewWriter(compressed)
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	header := &tar.Header{Name: backupManifestName, Mode: 0o644, Size: int64(len(manifestJSON)), ModTime: manifest.CreatedAt}
+	if err := archive.WriteHeader(header); err != nil {
+		return nil, err
+	}
+	if _, err := archive.Write(manifestJSON); err != nil {
+		return nil, err
+	}
+
+	for _, section := range backupSections {
+		if err := addBackupFile(archive, filepath.Join(dir, section+".jsonl"), manifest.CreatedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	if err := compressed.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// stageBackupSection writes the records of a section to a JSON Lines file, a batch at a
+// time, and returns how many there were
+func (s *Service) stageBackupSection(accountID int, section, filename string) (int, error) {
+	file, err := os.Create(filename)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	buffered := bufio.NewWriter(file)
+	encoder := json.NewEncoder(buffered)
+
+	db := s.db.DB
+	ofAccount := func(model interface{}) *gorm.DB { return db.Model(model).Where("account_id = ?", accountID) }
+	var query *gorm.DB
+	var batch interface{}
+	switch section {
+	case "categories":
+		query, batch = ofAccount(&models.Category{}), &[]models.Category{}
+	case "vendors":
+		query, batch = ofAccount(&models.Vendor{}), &[]models.Vendor{}
+	case "storage_locations":
+		query, batch = ofAccount(&models.StorageLocation{}), &[]models.StorageLocation{}
+	case "inventory_items":
+		query, batch = ofAccount(&models.InventoryItem{}), &[]models.InventoryItem{}
+	case "menu_items":
+		query, batch = ofAccount(&models.MenuItem{}), &[]models.MenuItem{}
+	case "recipe_ingredients":
+		menuItemIDs := db.Model(&models.MenuItem{}).Select("id").Where("account_id = ?", accountID)
+		query, batch = db.Model(&models.RecipeIngredient{}).Where("menu_item_id IN (?)", menuItemIDs), &[]models.RecipeIngredient{}
+	case "inventory_snapshots":
+		query, batch = ofAccount(&models.InventorySnapshot{}), &[]models.InventorySnapshot{}
+	case "deliveries":
+		query, batch = ofAccount(&models.Delivery{}), &[]models.Delivery{}
+	case "inventory_lots":
+		query, batch = ofAccount(&models.InventoryLot{}), &[]models.InventoryLot{}
+	case "price_history":
+		query, batch = ofAccount(&models.PriceHistory{}), &[]models.PriceHistory{}
+	case "waste_logs":
+		query, batch = ofAccount(&models.WasteLog{}), &[]models.WasteLog{}
+	case "stock_adjustments":
+		query, batch = ofAccount(&models.StockAdjustment{}), &[]models.StockAdjustment{}
+	case "location_transfers":
+		query, batch = ofAccount(&models.LocationTransfer{}), &[]models.LocationTransfer{}
+	case "orders":
+		query, batch = ofAccount(&models.Order{}), &[]models.Order{}
+	case "order_items":
+		orderIDs := db.Model(&models.Order{}).Select("id").Where("account_id = ?", accountID)
+		query, batch = db.Model(&models.OrderItem{}).Where("order_id IN (?)", orderIDs), &[]models.OrderItem{}
+	case "sales":
+		query, batch = ofAccount(&models.Sale{}).Preload("Items"), &[]models.Sale{}
+	case "email_schedules":
+		query, batch = ofAccount(&models.EmailSchedule{}), &[]models.EmailSchedule{}
+	case "budgets":
+		query, batch = ofAccount(&models.Budget{}), &[]models.Budget{}
+	case "invoices":
+		query, batch = ofAccount(&models.Invoice{}), &[]models.Invoice{}
+	case "invoice_lines":
+		invoiceIDs := db.Model(&models.Invoice{}).Select("id").Where("account_id = ?", accountID)
+		query, batch = db.Model(&models.InvoiceLine{}).Where("invoice_id IN (?)", invoiceIDs), &[]models.InvoiceLine{}
+	default:
+		return 0, fmt.Errorf("unknown backup section %q", section)
+	}
+
+	count := 0
+	err = inBatches(query, "id", func(page *gorm.DB) (int, error) {
+		// Reuse the slice for every batch; it is emptied before each load
+		rows := reflect.ValueOf(batch).Elem()
+		rows.Set(rows.Slice(0, 0))
+		if err := page.Find(batch).Error; err != nil {
+			return 0, err
+		}
+		for i := 0; i < rows.Len(); i++ {
+			if err := encoder.Encode(rows.Index(i).Interface()); err != nil {
+				return 0, err
+			}
+		}
+		count += rows.Len()
+		return rows.Len(), nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := buffered.Flush(); err != nil {
+		return 0, err
+	}
+	return count, file.Close()
+}
+
+// addBackupFile copies a staged section file into the archive
+func addBackupFile(archive *tar.Writer, filename string, modTime time.Time) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	header := &tar.Header{Name: filepath.Base(filename), Mode: 0o644, Size: info.Size(), ModTime: modTime}
+	if err := archive.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(archive, file)
+	return err
+}
+
+// RestoreAccount restores an account backup archive into an empty account, in a single
+// transaction. Every record gets a new ID and every reference between records, including
+// the item and location keys of snapshot counts, is rewritten to the new IDs. The account's
+// costing method and price alert threshold are taken from the backup, and its stock levels
+// are rebuilt from the restored history.
+//
+// Parameters:
+//   - accountID: The unique identifier of the empty account to restore into
+//   - userID: The user recorded as the author of restored orders, waste, adjustments, and
+//     transfers; 0 keeps the user IDs stored in the archive, as when recovering an account
+//     in its original database
+//   - r: The archive, as written by BackupAccount
+//
+// Returns:
+//   - *BackupRestoreReport: The number of records restored and skipped in each section
+//   - error: ErrInvalidBackup for unreadable archives, ErrAccountNotEmpty, or a database error
+//
+// Business rules:
+//   - The account must not have any categories, vendors, storage locations, inventory items, or menu items
+//   - Links to organization catalog entries are dropped, since the account may belong to another organization
+//   - Records whose required references are missing from the archive are skipped and counted
+func (s *Service) RestoreAccount(accountID int, userID int, r io.Reader) (*BackupRestoreReport, error) {
+	account, err := s.accounts.GetByID(accountID)
+	if err != nil {
+		return nil, errors.New("invalid account ID")
+	}
+	if err := s.checkAccountEmpty(accountID); err != nil {
+		return nil, err
+	}
+
+	compressed, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	defer compressed.Close()
+	archive := tar.NewReader(compressed)
+
+	header, err := archive.Next()
+	if err != nil || header.Name != backupManifestName {
+		return nil, fmt.Errorf("%w: the archive does not start with %s", ErrInvalidBackup, backupManifestName)
+	}
+	var manifest BackupManifest
+	if err := json.NewDecoder(archive).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidBackup, backupManifestName, err)
+	}
+	if manifest.Format != BackupFormat {
+		return nil, fmt.Errorf("%w: not an account backup", ErrInvalidBackup)
+	}
+	if manifest.Version < 1 || manifest.Version > BackupFormatVersion {
+		return nil, fmt.Errorf("%w: version %d archives are not supported; the newest supported version is %d", ErrInvalidBackup, manifest.Version, BackupFormatVersion)
+	}
+
+	report := &BackupRestoreReport{
+		AccountID: accountID,
+		Manifest:  manifest,
+		Restored:  make(map[string]int, len(backupSections)),
+		Skipped:   make(map[string]int),
+	}
+	err = s.withTransaction(func(tx *Service) error {
+		restore := &accountRestore{tx: tx.db.DB, accountID: accountID, userID: userID, ids: make(map[string]map[int]int), receivedOrderItems: make(map[int][]int), report: report}
+
+		next := 0
+		for {
+			header, err := archive.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+			}
+
+			section := strings.TrimSuffix(header.Name, ".jsonl")
+			index := -1
+			for i := next; i < len(backupSections); i++ {
+				if backupSections[i] == section {
+					index = i
+					break
+				}
+			}
+			if index < 0 || !strings.HasSuffix(header.Name, ".jsonl") {
+				return fmt.Errorf("%w: unexpected file %s", ErrInvalidBackup, header.Name)
+			}
+			next = index + 1
+
+			if err := restore.section(section, archive); err != nil {
+				return err
+			}
+			if total := report.Restored[section] + report.Skipped[section]; total != manifest.Records[section] {
+				return fmt.Errorf("%w: %s has %d records but the manifest lists %d", ErrInvalidBackup, section, total, manifest.Records[section])
+			}
+		}
+		for _, section := range backupSections {
+			if _, read := restore.ids[section]; !read && manifest.Records[section] > 0 {
+				return fmt.Errorf("%w: %s is missing from the archive", ErrInvalidBackup, section)
+			}
+		}
+
+		account.CostingMethod = manifest.Account.CostingMethod
+		account.PriceAlertPct = manifest.Account.PriceAlertPct
+		account.InvoiceQuantityTolerancePct = manifest.Account.InvoiceQuantityTolerancePct
+		account.InvoicePriceTolerancePct = manifest.Account.InvoicePriceTolerancePct
+		if err := tx.accounts.Update(account); err != nil {
+			return err
+		}
+		return tx.RebuildInventoryLevels(accountID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// checkAccountEmpty returns ErrAccountNotEmpty if the account has any of the records a
+// restore would clash with
+func (s *Service) checkAccountEmpty(accountID int) error {
+	for _, model := range []interface{}{&models.Category{}, &models.Vendor{}, &models.StorageLocation{}, &models.InventoryItem{}, &models.MenuItem{}} {
+		var count int64
+		if err := s.db.Model(model).Where("account_id = ?", accountID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrAccountNotEmpty
+		}
+	}
+	return nil
+}
+
+// accountRestore holds the state of a restore in progress
+type accountRestore struct {
+	tx        *gorm.DB
+	accountID int
+	userID    int
+	ids       map[string]map[int]int // New record IDs by section and archived ID
+	// receivedOrderItems holds the new IDs of deliveries by the archived ID of the order item
+	// they were received against, as order items are restored after deliveries
+	receivedOrderItems map[int][]int
+	report             *BackupRestoreReport
+}
+
+// section restores the records of a JSON Lines file
+func (r *accountRestore) section(section string, file io.Reader) error {
+	r.ids[section] = make(map[int]int)
+	reader := bufio.NewReader(file)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if len(strings.TrimSpace(string(data))) > 0 {
+			if restoreErr := r.record(section, data); restoreErr != nil {
+				var syntaxErr *json.SyntaxError
+				var typeErr *json.UnmarshalTypeError
+				if errors.As(restoreErr, &syntaxErr) || errors.As(restoreErr, &typeErr) {
+					return fmt.Errorf("%w: %s.jsonl line %d: %v", ErrInvalidBackup, section, line, restoreErr)
+				}
+				return fmt.Errorf("restoring %s.jsonl line %d: %w", section, line, restoreErr)
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+		}
+	}
+}
+
+// ref returns the new ID of a record of a section, and false if it was not restored
+func (r *accountRestore) ref(section string, id int) (int, bool) {
+	newID, ok := r.ids[section][id]
+	return newID, ok
+}
+
+// optionalRef returns the new ID of an optional reference, or nil if it was not restored
+func (r *accountRestore) optionalRef(section string, id *int) *int {
+	if id == nil {
+		return nil
+	}
+	if newID, ok := r.ids[section][*id]; ok {
+		return &newID
+	}
+	return nil
+}
+
+// user returns the user a restored record is attributed to
+func (r *accountRestore) user(id int) int {
+	if r.userID != 0 {
+		return r.userID
+	}
+	return id
+}
+
+// optionalUser returns the user a restored record is attributed to, if it was attributed to one
+func (r *accountRestore) optionalUser(id *int) *int {
+	if id == nil || r.userID == 0 {
+		return id
+	}
+	userID := r.userID
+	return &userID
+}
+
+// skip counts a record left out of the restore
+func (r *accountRestore) skip(section string) error {
+	r.report.Skipped[section]++
+	return nil
+}
+
+// create inserts a restored record and records its new ID. Inserting replaces zero values
+// with column defaults, such as an inactive flag with true, so the archived values are
+// written back when they differ.
+func (r *accountRestore) create(section string, oldID int, record interface{}, newID func() int) error {
+	archived := reflect.New(reflect.TypeOf(record).Elem())
+	archived.Elem().Set(reflect.ValueOf(record).Elem())
+
+	if err := r.tx.Create(record).Error; err != nil {
+		return err
+	}
+	primaryKey := archived.Elem().FieldByName("ID")
+	primaryKey.Set(reflect.ValueOf(record).Elem().FieldByName("ID"))
+	if !reflect.DeepEqual(archived.Interface(), record) {
+		if err := r.tx.Model(record).Select("*").Omit("id", "updated_at").Updates(archived.Interface()).Error; err != nil {
+			return err
+		}
+	}
+	r.ids[section][oldID] = newID()
+	r.report.Restored[section]++
+	return nil
+}
+
+// record restores a single archived record of a section
+func (r *accountRestore) record(section string, data []byte) error {
+	var ok bool
+	switch section {
+	case "categories":
+		var category models.Category
+		if err := json.Unmarshal(data, &category); err != nil {
+			return err
+		}
+		oldID := category.ID
+		category.ID, category.AccountID, category.CatalogCategoryID = 0, r.accountID, nil
+		return r.create(section, oldID, &category, func() int { return category.ID })
+
+	case "vendors":
+		var vendor models.Vendor
+		if err := json.Unmarshal(data, &vendor); err != nil {
+			return err
+		}
+		oldID := vendor.ID
+		vendor.ID, vendor.AccountID = 0, r.accountID
+		return r.create(section, oldID, &vendor, func() int { return vendor.ID })
+
+	case "storage_locations":
+		var location models.StorageLocation
+		if err := json.Unmarshal(data, &location); err != nil {
+			return err
+		}
+		oldID := location.ID
+		location.ID, location.AccountID = 0, r.accountID
+		return r.create(section, oldID, &location, func() int { return location.ID })
+
+	case "inventory_items":
+		var item models.InventoryItem
+		if err := json.Unmarshal(data, &item); err != nil {
+			return err
+		}
+		oldID := item.ID
+		item.ID, item.AccountID, item.CatalogItemID = 0, r.accountID, nil
+		item.CategoryID = r.optionalRef("categories", item.CategoryID)
+		item.VendorID = r.optionalRef("vendors", item.VendorID)
+		return r.create(section, oldID, &item, func() int { return item.ID })
+
+	case "menu_items":
+		var menuItem models.MenuItem
+		if err := json.Unmarshal(data, &menuItem); err != nil {
+			return err
+		}
+		oldID := menuItem.ID
+		menuItem.ID, menuItem.AccountID, menuItem.CatalogMenuItemID = 0, r.accountID, nil
+		menuItem.CategoryID = r.optionalRef("categories", menuItem.CategoryID)
+		return r.create(section, oldID, &menuItem, func() int { return menuItem.ID })
+
+	case "recipe_ingredients":
+		var ingredient models.RecipeIngredient
+		if err := json.Unmarshal(data, &ingredient); err != nil {
+			return err
+		}
+		oldID := ingredient.ID
+		if ingredient.MenuItemID, ok = r.ref("menu_items", ingredient.MenuItemID); !ok {
+			return r.skip(section)
+		}
+		if ingredient.InventoryItemID, ok = r.ref("inventory_items", ingredient.InventoryItemID); !ok {
+			return r.skip(section)
+		}
+		ingredient.ID = 0
+		return r.create(section, oldID, &ingredient, func() int { return ingredient.ID })
+
+	case "inventory_snapshots":
+		var snapshot models.InventorySnapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			return err
+		}
+		oldID := snapshot.ID
+		snapshot.ID, snapshot.AccountID = 0, r.accountID
+		snapshot.Counts = r.counts(snapshot.Counts)
+		if snapshot.LocationCounts != nil {
+			locationCounts := make(models.LocationCountsMap, len(snapshot.LocationCounts))
+			for locationID, counts := range snapshot.LocationCounts {
+				if newID, ok := r.ref("storage_locations", locationID); ok {
+					locationCounts[newID] = r.counts(counts)
+				}
+			}
+			snapshot.LocationCounts = locationCounts
+		}
+		return r.create(section, oldID, &snapshot, func() int { return snapshot.ID })
+
+	case "deliveries":
+		var delivery models.Delivery
+		if err := json.Unmarshal(data, &delivery); err != nil {
+			return err
+		}
+		oldID := delivery.ID
+		if delivery.InventoryItemID, ok = r.ref("inventory_items", delivery.InventoryItemID); !ok {
+			return r.skip(section)
+		}
+		delivery.ID, delivery.AccountID = 0, r.accountID
+		delivery.VendorID = r.optionalRef("vendors", delivery.VendorID)
+		delivery.StorageLocationID = r.optionalRef("storage_locations", delivery.StorageLocationID)
+		orderItemID := delivery.OrderItemID
+		delivery.OrderItemID = nil
+		if err := r.create(section, oldID, &delivery, func() int { return delivery.ID }); err != nil || orderItemID == nil {
+			return err
+		}
+		r.receivedOrderItems[*orderItemID] = append(r.receivedOrderItems[*orderItemID], delivery.ID)
+		return nil
+
+	case "inventory_lots":
+		var lot models.InventoryLot
+		if err := json.Unmarshal(data, &lot); err != nil {
+			return err
+		}
+		oldID := lot.ID
+		if lot.InventoryItemID, ok = r.ref("inventory_items", lot.InventoryItemID); !ok {
+			return r.skip(section)
+		}
+		lot.ID, lot.AccountID = 0, r.accountID
+		lot.DeliveryID = r.optionalRef("deliveries", lot.DeliveryID)
+		return r.create(section, oldID, &lot, func() int { return lot.ID })
+
+	case "price_history":
+		var price models.PriceHistory
+		if err := json.Unmarshal(data, &price); err != nil {
+			return err
+		}
+		oldID := price.ID
+		if price.InventoryItemID, ok = r.ref("inventory_items", price.InventoryItemID); !ok {
+			return r.skip(section)
+		}
+		price.ID, price.AccountID = 0, r.accountID
+		price.VendorID = r.optionalRef("vendors", price.VendorID)
+		price.DeliveryID = r.optionalRef("deliveries", price.DeliveryID)
+		return r.create(section, oldID, &price, func() int { return price.ID })
+
+	case "waste_logs":
+		var wasteLog models.WasteLog
+		if err := json.Unmarshal(data, &wasteLog); err != nil {
+			return err
+		}
+		oldID := wasteLog.ID
+		if wasteLog.InventoryItemID, ok = r.ref("inventory_items", wasteLog.InventoryItemID); !ok {
+			return r.skip(section)
+		}
+		wasteLog.ID, wasteLog.AccountID = 0, r.accountID
+		wasteLog.StorageLocationID = r.optionalRef("storage_locations", wasteLog.StorageLocationID)
+		wasteLog.RecordedBy = r.optionalUser(wasteLog.RecordedBy)
+		return r.create(section, oldID, &wasteLog, func() int { return wasteLog.ID })
+
+	case "stock_adjustments":
+		var adjustment models.StockAdjustment
+		if err := json.Unmarshal(data, &adjustment); err != nil {
+			return err
+		}
+		oldID := adjustment.ID
+		if adjustment.InventoryItemID, ok = r.ref("inventory_items", adjustment.InventoryItemID); !ok {
+			return r.skip(section)
+		}
+		adjustment.ID, adjustment.AccountID = 0, r.accountID
+		adjustment.StorageLocationID = r.optionalRef("storage_locations", adjustment.StorageLocationID)
+		adjustment.AdjustedBy = r.optionalUser(adjustment.AdjustedBy)
+		return r.create(section, oldID, &adjustment, func() int { return adjustment.ID })
+
+	case "location_transfers":
+		var transfer models.LocationTransfer
+		if err := json.Unmarshal(data, &transfer); err != nil {
+			return err
+		}
+		oldID := transfer.ID
+		if transfer.InventoryItemID, ok = r.ref("inventory_items", transfer.InventoryItemID); !ok {
+			return r.skip(section)
+		}
+		if transfer.FromLocationID, ok = r.ref("storage_locations", transfer.FromLocationID); !ok {
+			return r.skip(section)
+		}
+		if transfer.ToLocationID, ok = r.ref("storage_locations", transfer.ToLocationID); !ok {
+			return r.skip(section)
+		}
+		transfer.ID, transfer.AccountID = 0, r.accountID
+		transfer.TransferredBy = r.optionalUser(transfer.TransferredBy)
+		return r.create(section, oldID, &transfer, func() int { return transfer.ID })
+
+	case "orders":
+		var order models.Order
+		if err := json.Unmarshal(data, &order); err != nil {
+			return err
+		}
+		oldID := order.ID
+		order.ID, order.AccountID = 0, r.accountID
+		order.CreatedBy = r.user(order.CreatedBy)
+		order.ApprovedBy = r.optionalUser(order.ApprovedBy)
+		return r.create(section, oldID, &order, func() int { return order.ID })
+
+	case "order_items":
+		var orderItem models.OrderItem
+		if err := json.Unmarshal(data, &orderItem); err != nil {
+			return err
+		}
+		oldID := orderItem.ID
+		if orderItem.OrderID, ok = r.ref("orders", orderItem.OrderID); !ok {
+			return r.skip(section)
+		}
+		if orderItem.InventoryItemID, ok = r.ref("inventory_items", orderItem.InventoryItemID); !ok {
+			return r.skip(section)
+		}
+		orderItem.ID = 0
+		orderItem.VendorID = r.optionalRef("vendors", orderItem.VendorID)
+		if err := r.create(section, oldID, &orderItem, func() int { return orderItem.ID }); err != nil {
+			return err
+		}
+		if deliveryIDs := r.receivedOrderItems[oldID]; len(deliveryIDs) > 0 {
+			return r.tx.Model(&models.Delivery{}).Where("id IN ?", deliveryIDs).Update("order_item_id", orderItem.ID).Error
+		}
+		return nil
+
+	case "sales":
+		var sale models.Sale
+		if err := json.Unmarshal(data, &sale); err != nil {
+			return err
+		}
+		oldID := int(sale.ID)
+		items := make([]models.SaleItem, 0, len(sale.Items))
+		for _, item := range sale.Items {
+			// Items of deleted menu items are dropped; the sale keeps its totals
+			menuItemID, ok := r.ref("menu_items", int(item.MenuItemID))
+			if !ok {
+				continue
+			}
+			item.SaleID, item.MenuItemID, item.MenuItem = 0, uint(menuItemID), models.MenuItem{}
+			items = append(items, item)
+		}
+		sale.ID, sale.AccountID, sale.Items = 0, r.accountID, nil
+
+		// Items are created separately so the menu items they embed are not inserted with them
+		if err := r.create(section, oldID, &sale, func() int { return int(sale.ID) }); err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].SaleID = sale.ID
+		}
+		if len(items) > 0 {
+			return r.tx.Omit("MenuItem").Create(&items).Error
+		}
+		return nil
+
+	case "email_schedules":
+		var schedule models.EmailSchedule
+		if err := json.Unmarshal(data, &schedule); err != nil {
+			return err
+		}
+		oldID := schedule.ID
+		schedule.ID, schedule.AccountID = 0, r.accountID
+		return r.create(section, oldID, &schedule, func() int { return schedule.ID })
+
+	case "budgets":
+		var budget models.Budget
+		if err := json.Unmarshal(data, &budget); err != nil {
+			return err
+		}
+		oldID := budget.ID
+		if budget.CategoryID, ok = r.ref("categories", budget.CategoryID); !ok {
+			return r.skip(section)
+		}
+		budget.ID, budget.AccountID = 0, r.accountID
+		return r.create(section, oldID, &budget, func() int { return budget.ID })
+
+	case "invoices":
+		var invoice models.Invoice
+		if err := json.Unmarshal(data, &invoice); err != nil {
+			return err
+		}
+		oldID := invoice.ID
+		invoice.ID, invoice.AccountID, invoice.Lines = 0, r.accountID, nil
+		invoice.VendorID = r.optionalRef("vendors", invoice.VendorID)
+		invoice.OrderID = r.optionalRef("orders", invoice.OrderID)
+		invoice.CreatedBy = r.user(invoice.CreatedBy)
+		invoice.ApprovedBy = r.optionalUser(invoice.ApprovedBy)
+		return r.create(section, oldID, &invoice, func() int { return invoice.ID })
+
+	case "invoice_lines":
+		var line models.InvoiceLine
+		if err := json.Unmarshal(data, &line); err != nil {
+			return err
+		}
+		oldID := line.ID
+		if line.InvoiceID, ok = r.ref("invoices", line.InvoiceID); !ok {
+			return r.skip(section)
+		}
+		line.ID = 0
+		line.OrderItemID = r.optionalRef("order_items", line.OrderItemID)
+		line.DeliveryID = r.optionalRef("deliveries", line.DeliveryID)
+		return r.create(section, oldID, &line, func() int { return line.ID })
+	}
+	return fmt.Errorf("unknown backup section %q", section)
+}
+
+// counts rewrites the inventory item keys of snapshot counts, dropping items that were not restored
+func (r *accountRestore) counts(counts models.CountsMap) models.CountsMap {
+	if counts == nil {
+		return nil
+	}
+	restored := make(models.CountsMap, len(counts))
+	for itemID, quantity := range counts {
+		if newID, ok := r.ref("inventory_items", itemID); ok {
+			restored[newID] += quantity
+		}
+	}
+	return restored
+}
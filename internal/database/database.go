@@ -74,7 +74,7 @@ func getGormLogger() logger.Interface {
 }
 
 func runMigrations(db *gorm.DB) error {
-	return db.AutoMigrate(
+	if err := db.AutoMigrate(
 		&models.Organization{},
 		&models.Account{},
 		&models.User{},
@@ -87,10 +87,193 @@ func runMigrations(db *gorm.DB) error {
 		&models.SaleItem{},
 		&models.Order{},
 		&models.OrderItem{},
+		&models.OrderDispatch{},
+		&models.Receipt{},
+		&models.ReceiptLine{},
+		&models.Invoice{},
+		&models.InvoiceLine{},
 		&models.Delivery{},
 		&models.AccountInvitation{},
 		&models.EmailSchedule{},
-	)
+		&models.EmailTemplate{},
+		&models.Vendor{},
+		&models.PriceHistory{},
+		&models.PriceAlert{},
+		&models.Budget{},
+		&models.BudgetAlert{},
+		&models.InventoryLot{},
+		&models.WasteLog{},
+		&models.CountSession{},
+		&models.CountEntry{},
+		&models.StorageLocation{},
+		&models.LocationTransfer{},
+		&models.StockTransfer{},
+		&models.StockTransferLot{},
+		&models.CatalogCategory{},
+		&models.CatalogItem{},
+		&models.CatalogMenuItem{},
+		&models.CatalogRecipeIngredient{},
+		&models.CatalogSubscription{},
+		&models.CatalogSyncConflict{},
+		&models.StockAdjustment{},
+		&models.InventoryLevel{},
+	); err != nil {
+		return err
+	}
+
+	if err := backfillVendors(db); err != nil {
+		return err
+	}
+
+	if err := backfillPriceHistory(db); err != nil {
+		return err
+	}
+
+	return backfillInventoryLevels(db)
+}
+
+// backfillVendors converts the free-text vendor names stored on inventory items,
+// deliveries, and order items into Vendor rows and links each record to its vendor.
+// Names are matched case- and whitespace-insensitively, so "Local Dairy" and
+// "local  dairy" end up as one vendor. Records that already reference a vendor are
+// skipped, which makes the backfill safe to run on every startup.
+func backfillVendors(db *gorm.DB) error {
+	// accountID -> normalized vendor name -> vendor ID
+	vendorIDs := make(map[int]map[string]int)
+
+	resolve := func(accountID int, name string) (int, error) {
+		byName, ok := vendorIDs[accountID]
+		if !ok {
+			var vendors []models.Vendor
+			if err := db.Where("account_id = ?", accountID).Find(&vendors).Error; err != nil {
+				return 0, err
+			}
+			byName = make(map[string]int)
+			for _, vendor := range vendors {
+				byName[normalizeVendorName(vendor.Name)] = vendor.ID
+			}
+			vendorIDs[accountID] = byName
+		}
+
+		key := normalizeVendorName(name)
+		if id, ok := byName[key]; ok {
+			return id, nil
+		}
+
+		vendor := models.Vendor{AccountID: accountID, Name: cleanVendorName(name)}
+		if err := db.Create(&vendor).Error; err != nil {
+			return 0, err
+		}
+		byName[key] = vendor.ID
+		return vendor.ID, nil
+	}
+
+	var items []models.InventoryItem
+	if err := db.Where("vendor_id IS NULL AND preferred_vendor <> ''").Find(&items).Error; err != nil {
+		return fmt.Errorf("failed to load inventory item vendors: %w", err)
+	}
+	for _, item := range items {
+		vendorID, err := resolve(item.AccountID, item.PreferredVendor)
+		if err != nil {
+			return fmt.Errorf("failed to backfill vendor for inventory item %d: %w", item.ID, err)
+		}
+		if err := db.Model(&models.InventoryItem{}).Where("id = ?", item.ID).Update("vendor_id", vendorID).Error; err != nil {
+			return err
+		}
+	}
+
+	var deliveries []models.Delivery
+	if err := db.Where("vendor_id IS NULL AND vendor <> ''").Find(&deliveries).Error; err != nil {
+		return fmt.Errorf("failed to load delivery vendors: %w", err)
+	}
+	for _, delivery := range deliveries {
+		vendorID, err := resolve(delivery.AccountID, delivery.Vendor)
+		if err != nil {
+			return fmt.Errorf("failed to backfill vendor for delivery %d: %w", delivery.ID, err)
+		}
+		if err := db.Model(&models.Delivery{}).Where("id = ?", delivery.ID).Update("vendor_id", vendorID).Error; err != nil {
+			return err
+		}
+	}
+
+	// Order items are scoped to an account through their parent order
+	var orderItems []struct {
+		ID        int
+		Vendor    string
+		AccountID int
+	}
+	err := db.Table("order_items").
+		Select("order_items.id, order_items.vendor, orders.account_id").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("order_items.vendor_id IS NULL AND order_items.vendor <> ''").
+		Scan(&orderItems).Error
+	if err != nil {
+		return fmt.Errorf("failed to load order item vendors: %w", err)
+	}
+	for _, orderItem := range orderItems {
+		vendorID, err := resolve(orderItem.AccountID, orderItem.Vendor)
+		if err != nil {
+			return fmt.Errorf("failed to backfill vendor for order item %d: %w", orderItem.ID, err)
+		}
+		if err := db.Model(&models.OrderItem{}).Where("id = ?", orderItem.ID).Update("vendor_id", vendorID).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backfillPriceHistory records a price history entry for every delivery that
+// predates price tracking. Item costs are left as they are until the next
+// delivery or an explicit recompute.
+func backfillPriceHistory(db *gorm.DB) error {
+	var deliveries []models.Delivery
+	err := db.Where("quantity > 0 AND id NOT IN (?)",
+		db.Model(&models.PriceHistory{}).Select("delivery_id").Where("delivery_id IS NOT NULL")).
+		Order("delivery_date ASC").Find(&deliveries).Error
+	if err != nil {
+		return fmt.Errorf("failed to load deliveries for price history: %w", err)
+	}
+
+	for _, delivery := range deliveries {
+		deliveryID := delivery.ID
+		entry := models.PriceHistory{
+			AccountID:       delivery.AccountID,
+			InventoryItemID: delivery.InventoryItemID,
+			VendorID:        delivery.VendorID,
+			DeliveryID:      &deliveryID,
+			Quantity:        delivery.Quantity,
+			UnitPrice:       delivery.Cost / delivery.Quantity,
+			RecordedAt:      delivery.DeliveryDate,
+		}
+		if err := db.Create(&entry).Error; err != nil {
+			return fmt.Errorf("failed to backfill price history for delivery %d: %w", delivery.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// backfillInventoryLevels builds the inventory levels of accounts whose items have none
+// yet, such as every account when the levels table is first created. Accounts that
+// already have levels are skipped; cmd/rebuild-inventory-levels recomputes those.
+func backfillInventoryLevels(db *gorm.DB) error {
+	var accountIDs []int
+	err := db.Model(&models.InventoryItem{}).Distinct("account_id").
+		Where("account_id NOT IN (?)", db.Model(&models.InventoryLevel{}).Select("account_id")).
+		Pluck("account_id", &accountIDs).Error
+	if err != nil {
+		return fmt.Errorf("failed to load accounts for inventory levels: %w", err)
+	}
+
+	service := NewService(&DB{db})
+	for _, accountID := range accountIDs {
+		if err := service.RebuildInventoryLevels(accountID); err != nil {
+			return fmt.Errorf("failed to backfill inventory levels for account %d: %w", accountID, err)
+		}
+	}
+
+	return nil
 }
 
 func getEnv(key, fallback string) string {
@@ -1,6 +1,12 @@
 package database
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -220,6 +226,2683 @@ func TestDeliveryOperations(t *testing.T) {
 	assert.Equal(t, delivery.Cost, retrievedDelivery.Cost)
 }
 
+func TestVendorOperations(t *testing.T) {
+	db, cleanup := SetupTestDBLegacy(t)
+	defer cleanup()
+
+	service := NewService(db)
+
+	// Create organization and account
+	org := createTestOrganizationLegacy(t, service, "Vendor Test Corp")
+	account := createTestAccountLegacy(t, service, org.ID, "Test Coffee Shop")
+
+	// Test creating a vendor
+	vendor := &models.Vendor{
+		AccountID:         account.ID,
+		Name:              " Coffee  Supply Co. ",
+		LeadTimeDays:      3,
+		DeliveryDays:      "Tue, FRI",
+		MinimumOrderValue: 100.0,
+	}
+
+	err := service.CreateVendor(vendor)
+	require.NoError(t, err)
+	assert.NotZero(t, vendor.ID)
+	assert.Equal(t, "Coffee Supply Co.", vendor.Name)
+	assert.Equal(t, "tue,fri", vendor.DeliveryDays)
+
+	// Test that names are unique per account regardless of case and spacing
+	err = service.CreateVendor(&models.Vendor{AccountID: account.ID, Name: "coffee supply co."})
+	assert.Error(t, err)
+
+	// Test that free-text vendor names link to the existing vendor
+	item := &models.InventoryItem{
+		AccountID:       account.ID,
+		Name:            "Coffee Beans",
+		Unit:            "kg",
+		PreferredVendor: "COFFEE SUPPLY CO.",
+	}
+	err = service.CreateInventoryItem(item)
+	require.NoError(t, err)
+	require.NotNil(t, item.VendorID)
+	assert.Equal(t, vendor.ID, *item.VendorID)
+
+	items, err := service.GetInventoryItemsByVendor(account.ID, "coffee supply co.")
+	require.NoError(t, err)
+	assert.Len(t, items, 1)
+
+	// Test that renaming a vendor propagates to linked items
+	vendor.Name = "Coffee Supply Company"
+	err = service.UpdateVendor(vendor)
+	require.NoError(t, err)
+
+	retrievedItem, err := service.GetInventoryItem(item.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Coffee Supply Company", retrievedItem.PreferredVendor)
+
+	// Test that renaming a vendor propagates to order items and invoices
+	user := createTestUserLegacy(t, service, account.ID, "vendors@example.com", "manager")
+	order := &models.Order{AccountID: account.ID, CreatedBy: user.ID}
+	require.NoError(t, service.CreateOrder(order, []models.OrderItem{{InventoryItemID: item.ID, Quantity: 5, UnitCost: 12}}))
+	orderItems, err := service.GetOrderItems(order.ID)
+	require.NoError(t, err)
+	_, err = service.CreateInvoice(&models.Invoice{AccountID: account.ID, InvoiceNumber: "CS-1", CreatedBy: user.ID}, []models.InvoiceLine{{OrderItemID: &orderItems[0].ID, Quantity: 5, UnitCost: 12}})
+	require.NoError(t, err)
+
+	vendor.Name = "Coffee Supply Ltd"
+	require.NoError(t, service.UpdateVendor(vendor))
+	orderItems, err = service.GetOrderItems(order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Coffee Supply Ltd", orderItems[0].Vendor)
+	invoices, err := service.GetInvoicesByAccount(account.ID, "")
+	require.NoError(t, err)
+	require.Len(t, invoices, 1)
+	assert.Equal(t, "Coffee Supply Ltd", invoices[0].Vendor)
+	retrievedItem, err = service.GetInventoryItem(item.ID)
+	require.NoError(t, err)
+
+	// Test that a changed vendor name wins over the vendor ID sent back with it
+	retrievedItem.PreferredVendor = "Bean Brokers"
+	err = service.UpdateInventoryItem(retrievedItem)
+	require.NoError(t, err)
+	require.NotNil(t, retrievedItem.VendorID)
+	assert.NotEqual(t, vendor.ID, *retrievedItem.VendorID)
+	assert.Equal(t, "Bean Brokers", retrievedItem.PreferredVendor)
+
+	retrievedItem.PreferredVendor = "coffee supply ltd"
+	retrievedItem.VendorID = &vendor.ID
+	err = service.UpdateInventoryItem(retrievedItem)
+	require.NoError(t, err)
+	assert.Equal(t, vendor.ID, *retrievedItem.VendorID)
+	assert.Equal(t, "Coffee Supply Ltd", retrievedItem.PreferredVendor)
+
+	// Test that a vendor in use cannot be deleted
+	err = service.DeleteVendor(vendor.ID)
+	assert.Error(t, err)
+
+	// Test that vendors only referenced by orders or invoices cannot be deleted either
+	require.NoError(t, service.CreateOrder(&models.Order{AccountID: account.ID, CreatedBy: user.ID}, []models.OrderItem{{InventoryItemID: item.ID, Quantity: 1, UnitCost: 12, Vendor: "Roastery"}}))
+	roastery, err := service.FindVendorByName(account.ID, "Roastery")
+	require.NoError(t, err)
+	assert.Error(t, service.DeleteVendor(roastery.ID), "items were ordered from the vendor")
+
+	_, err = service.CreateInvoice(&models.Invoice{AccountID: account.ID, Vendor: "Freight Co", InvoiceNumber: "F-1", CreatedBy: user.ID}, []models.InvoiceLine{{Description: "Freight", Quantity: 1, UnitCost: 15}})
+	require.NoError(t, err)
+	freight, err := service.FindVendorByName(account.ID, "Freight Co")
+	require.NoError(t, err)
+	assert.Error(t, service.DeleteVendor(freight.ID), "the vendor has invoices")
+
+	unused := &models.Vendor{AccountID: account.ID, Name: "Unused Supplies"}
+	require.NoError(t, service.CreateVendor(unused))
+	assert.NoError(t, service.DeleteVendor(unused.ID))
+}
+
+func TestBackfillVendors(t *testing.T) {
+	db, cleanup := SetupTestDBLegacy(t)
+	defer cleanup()
+
+	service := NewService(db)
+
+	// Create organization, account, and inventory item
+	org := createTestOrganizationLegacy(t, service, "Backfill Test Corp")
+	account := createTestAccountLegacy(t, service, org.ID, "Test Coffee Shop")
+	item := createTestInventoryItemLegacy(t, service, account.ID, "Milk")
+
+	// Insert legacy rows directly so they bypass vendor linking
+	for _, vendorName := range []string{"Local Dairy", "local dairy", "LOCAL  DAIRY "} {
+		delivery := &models.Delivery{
+			AccountID:       account.ID,
+			InventoryItemID: item.ID,
+			Vendor:          vendorName,
+			Quantity:        5.0,
+			DeliveryDate:    time.Now(),
+		}
+		require.NoError(t, db.Create(delivery).Error)
+		assert.Nil(t, delivery.VendorID)
+	}
+
+	err := backfillVendors(db.DB)
+	require.NoError(t, err)
+
+	vendors, err := service.GetVendorsByAccount(account.ID)
+	require.NoError(t, err)
+	assert.Len(t, vendors, 2) // the item's preferred vendor plus the dairy
+
+	vendor, err := service.FindVendorByName(account.ID, "local dairy")
+	require.NoError(t, err)
+	assert.Equal(t, "Local Dairy", vendor.Name)
+
+	deliveries, err := service.GetDeliveriesByVendor(account.ID, "Local Dairy")
+	require.NoError(t, err)
+	assert.Len(t, deliveries, 3)
+	for _, delivery := range deliveries {
+		require.NotNil(t, delivery.VendorID)
+		assert.Equal(t, vendor.ID, *delivery.VendorID)
+	}
+}
+
+func TestVendorScorecards(t *testing.T) {
+	db, cleanup := SetupTestDBLegacy(t)
+	defer cleanup()
+
+	service := NewService(db)
+
+	// Create organization, account, and an item supplied by one vendor
+	org := createTestOrganizationLegacy(t, service, "Scorecard Test Corp")
+	account := createTestAccountLegacy(t, service, org.ID, "Test Coffee Shop")
+	item := &models.InventoryItem{
+		AccountID:       account.ID,
+		Name:            "Milk",
+		Unit:            "liters",
+		PreferredVendor: "Local Dairy",
+	}
+	require.NoError(t, service.CreateInventoryItem(item))
+
+	day := func(d int) time.Time { return time.Date(2024, time.January, d, 10, 0, 0, 0, time.UTC) }
+
+	// Two orders placed during the period plus a cancelled one that must be ignored
+	orders := []struct {
+		orderDate, expectedDate time.Time
+		status                  string
+	}{
+		{day(1), day(3), "ordered"},
+		{day(10), day(12), "ordered"},
+		{day(11), day(13), "cancelled"},
+	}
+	for _, o := range orders {
+		order := &models.Order{AccountID: account.ID, OrderDate: o.orderDate, ExpectedDate: o.expectedDate, Status: o.status, CreatedBy: 1}
+		err := service.CreateOrder(order, []models.OrderItem{{InventoryItemID: item.ID, Quantity: 10, UnitCost: 2.0}})
+		require.NoError(t, err)
+		assert.Equal(t, 20.0, order.TotalCost)
+	}
+
+	// The first order arrives on time and in full; the second is 3 days late and short
+	for _, d := range []struct {
+		date     time.Time
+		quantity float64
+		cost     float64
+	}{
+		{day(3), 10, 20.0},
+		{day(15), 8, 17.6},
+	} {
+		delivery := &models.Delivery{AccountID: account.ID, InventoryItemID: item.ID, Vendor: "local dairy", Quantity: d.quantity, DeliveryDate: d.date, Cost: d.cost}
+		require.NoError(t, service.CreateDelivery(delivery))
+	}
+
+	scorecards, err := service.GetVendorScorecards(account.ID, day(1), day(31))
+	require.NoError(t, err)
+	require.Len(t, scorecards, 1)
+
+	scorecard := scorecards[0]
+	assert.Equal(t, "Local Dairy", scorecard.VendorName)
+	assert.Equal(t, 2, scorecard.OrderLines)
+	assert.Equal(t, 2, scorecard.DeliveredLines)
+	assert.Equal(t, 1, scorecard.OnTimeLines)
+	assert.Equal(t, 1, scorecard.LateLines)
+	assert.InDelta(t, 50.0, scorecard.OnTimeRate, 0.001)
+	assert.InDelta(t, 3.0, scorecard.AverageDaysLate, 0.001)
+	assert.InDelta(t, 20.0, scorecard.QuantityOrdered, 0.001)
+	assert.InDelta(t, 18.0, scorecard.QuantityReceived, 0.001)
+	assert.InDelta(t, 90.0, scorecard.FillRate, 0.001)
+	require.Len(t, scorecard.PriceDrift, 1)
+	assert.InDelta(t, 10.0, scorecard.PriceDrift[0].ChangePercent, 0.001)
+	assert.InDelta(t, 10.0, scorecard.AveragePriceDrift, 0.001)
+
+	// Test that an inverted period is rejected
+	_, err = service.GetVendorScorecards(account.ID, day(31), day(1))
+	assert.Error(t, err)
+}
+
+func TestPriceHistoryAndCosting(t *testing.T) {
+	db, cleanup := SetupTestDBLegacy(t)
+	defer cleanup()
+
+	service := NewService(db)
+
+	// Create organization, account, and inventory item
+	org := createTestOrganizationLegacy(t, service, "Pricing Test Corp")
+	account := createTestAccountLegacy(t, service, org.ID, "Test Coffee Shop")
+	item := createTestInventoryItemLegacy(t, service, account.ID, "Milk")
+	_, err := service.UpdateCostSettings(account.ID, models.CostingMethodLast, 10)
+	require.NoError(t, err)
+
+	now := time.Now()
+	deliver := func(quantity, cost float64, date time.Time) {
+		delivery := &models.Delivery{
+			AccountID:       account.ID,
+			InventoryItemID: item.ID,
+			Vendor:          "Local Dairy",
+			Quantity:        quantity,
+			DeliveryDate:    date,
+			Cost:            cost,
+		}
+		require.NoError(t, service.CreateDelivery(delivery))
+	}
+
+	// First delivery at 2.00 per unit, then a count leaves 4 units on hand
+	deliver(10, 20.0, now.Add(-48*time.Hour))
+	snapshot := &models.InventorySnapshot{
+		AccountID: account.ID,
+		Timestamp: now.Add(-24 * time.Hour),
+		Counts:    map[int]float64{item.ID: 4},
+	}
+	require.NoError(t, db.Create(snapshot).Error) // bypass the repository so the count can be backdated
+	require.NoError(t, service.RebuildInventoryLevels(account.ID))
+
+	// Second delivery at 2.50 per unit is a 25% jump
+	deliver(10, 25.0, now.Add(-time.Hour))
+
+	retrievedItem, err := service.GetInventoryItem(item.ID)
+	require.NoError(t, err)
+	assert.InDelta(t, 2.5, retrievedItem.CostPerUnit, 0.0001)
+
+	history, err := service.GetPriceHistory(item.ID, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.InDelta(t, 2.0, history[0].UnitPrice, 0.0001)
+
+	alerts, err := service.GetUnnotifiedPriceAlerts(account.ID)
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.InDelta(t, 25.0, alerts[0].ChangePercent, 0.0001)
+
+	// Test the costing methods
+	cost, err := service.CalculateItemCost(retrievedItem, models.CostingMethodMovingAverage)
+	require.NoError(t, err)
+	assert.InDelta(t, 2.25, cost, 0.0001)
+
+	// 14 units on hand: the 10 just delivered plus 4 from the first delivery
+	cost, err = service.CalculateItemCost(retrievedItem, models.CostingMethodFIFO)
+	require.NoError(t, err)
+	assert.InDelta(t, (10*2.5+4*2.0)/14, cost, 0.0001)
+
+	_, err = service.CalculateItemCost(retrievedItem, "lifo")
+	assert.Error(t, err)
+
+	// Switching the account to FIFO recomputes stored costs and disabling alerts stops new ones
+	_, err = service.UpdateCostSettings(account.ID, models.CostingMethodFIFO, 0)
+	require.NoError(t, err)
+	retrievedItem, err = service.GetInventoryItem(item.ID)
+	require.NoError(t, err)
+	assert.InDelta(t, (10*2.5+4*2.0)/14, retrievedItem.CostPerUnit, 0.0001)
+
+	deliver(10, 50.0, now)
+	alerts, err = service.GetPriceAlertsByAccount(account.ID)
+	require.NoError(t, err)
+	assert.Len(t, alerts, 1)
+
+	// Test the price trend summary
+	trend, err := service.GetPriceTrend(item.ID, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, trend.Points, 3)
+	assert.InDelta(t, 2.0, trend.MinPrice, 0.0001)
+	assert.InDelta(t, 5.0, trend.MaxPrice, 0.0001)
+	assert.InDelta(t, 150.0, trend.ChangePercent, 0.0001)
+
+	// Test that sent alerts are no longer pending
+	require.NoError(t, service.MarkPriceAlertsNotified([]int{alerts[0].ID}, now))
+	alerts, err = service.GetUnnotifiedPriceAlerts(account.ID)
+	require.NoError(t, err)
+	assert.Empty(t, alerts)
+}
+
+func TestDeliveryCorrectionsUpdatePrices(t *testing.T) {
+	db, cleanup := SetupTestDBLegacy(t)
+	defer cleanup()
+
+	service := NewService(db)
+
+	account := createTestStandaloneAccountLegacy(t, service, "Correction Cafe")
+	item := createTestInventoryItemLegacy(t, service, account.ID, "Milk")
+
+	now := time.Now()
+	first := &models.Delivery{AccountID: account.ID, InventoryItemID: item.ID, Vendor: "Local Dairy", Quantity: 10, Cost: 20, DeliveryDate: now.Add(-48 * time.Hour)}
+	require.NoError(t, service.CreateDelivery(first))
+	second := &models.Delivery{AccountID: account.ID, InventoryItemID: item.ID, Vendor: "Local Dairy", Quantity: 10, Cost: 25, DeliveryDate: now.Add(-time.Hour)}
+	require.NoError(t, service.CreateDelivery(second))
+
+	t.Run("Edit Delivery Cost", func(t *testing.T) {
+		second.Cost = 30
+		require.NoError(t, service.UpdateDelivery(second))
+
+		history, err := service.GetPriceHistory(item.ID, time.Time{}, time.Time{})
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		assert.InDelta(t, 3.0, history[1].UnitPrice, 0.0001)
+
+		retrievedItem, err := service.GetInventoryItem(item.ID)
+		require.NoError(t, err)
+		assert.InDelta(t, 3.0, retrievedItem.CostPerUnit, 0.0001, "the corrected price drives the item's cost")
+	})
+
+	t.Run("Edit Delivery Vendor", func(t *testing.T) {
+		farm := &models.Vendor{AccountID: account.ID, Name: "Farm Fresh"}
+		require.NoError(t, service.CreateVendor(farm))
+
+		// The client echoes the vendor ID it loaded along with the corrected name
+		require.NotNil(t, second.VendorID)
+		second.Vendor = "  farm   FRESH "
+		require.NoError(t, service.UpdateDelivery(second))
+		require.NotNil(t, second.VendorID)
+		assert.Equal(t, farm.ID, *second.VendorID)
+		assert.Equal(t, "Farm Fresh", second.Vendor)
+
+		stored, err := service.GetDelivery(second.ID)
+		require.NoError(t, err)
+		require.NotNil(t, stored.VendorID)
+		assert.Equal(t, farm.ID, *stored.VendorID)
+
+		history, err := service.GetPriceHistory(item.ID, time.Time{}, time.Time{})
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		require.NotNil(t, history[1].VendorID)
+		assert.Equal(t, farm.ID, *history[1].VendorID, "the price is filed under the new vendor")
+
+		deliveries, err := service.GetDeliveriesByVendor(account.ID, "Farm Fresh")
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, second.ID, deliveries[0].ID)
+	})
+
+	t.Run("Delete Delivery", func(t *testing.T) {
+		require.NoError(t, service.DeleteDelivery(second.ID))
+
+		history, err := service.GetPriceHistory(item.ID, time.Time{}, time.Time{})
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		assert.Equal(t, first.ID, *history[0].DeliveryID)
+
+		retrievedItem, err := service.GetInventoryItem(item.ID)
+		require.NoError(t, err)
+		assert.InDelta(t, 2.0, retrievedItem.CostPerUnit, 0.0001, "the deleted delivery no longer sets the item's cost")
+	})
+}
+
+func TestInventoryLotOperations(t *testing.T) {
+	db, cleanup := SetupTestDBLegacy(t)
+	defer cleanup()
+
+	service := NewService(db)
+
+	// Create organization, account, and a perishable inventory item
+	org := createTestOrganizationLegacy(t, service, "Lot Test Corp")
+	account := createTestAccountLegacy(t, service, org.ID, "Test Coffee Shop")
+	item := createTestInventoryItemLegacy(t, service, account.ID, "Milk")
+	item.ShelfLifeDays = 7
+	require.NoError(t, service.UpdateInventoryItem(item))
+
+	today := dateOnly(time.Now())
+
+	t.Run("Delivery Creates Lot", func(t *testing.T) {
+		// Older delivery with an explicit lot number and expiration date
+		expiresTomorrow := today.AddDate(0, 0, 1)
+		older := &models.Delivery{
+			AccountID:       account.ID,
+			InventoryItemID: item.ID,
+			Vendor:          "Test Vendor",
+			Quantity:        10,
+			DeliveryDate:    today.AddDate(0, 0, -6),
+			Cost:            20,
+			LotNumber:       "MILK-001",
+			ExpirationDate:  &expiresTomorrow,
+		}
+		require.NoError(t, service.CreateDelivery(older))
+
+		// Newer delivery falls back to the item's shelf life and a generated lot number
+		newer := &models.Delivery{
+			AccountID:       account.ID,
+			InventoryItemID: item.ID,
+			Vendor:          "Test Vendor",
+			Quantity:        5,
+			DeliveryDate:    today,
+			Cost:            10,
+		}
+		require.NoError(t, service.CreateDelivery(newer))
+		require.NotNil(t, newer.ExpirationDate)
+		assert.True(t, newer.ExpirationDate.Equal(today.AddDate(0, 0, 7)))
+
+		lots, err := service.GetLotsByItem(item.ID)
+		require.NoError(t, err)
+		require.Len(t, lots, 2)
+		assert.Equal(t, "MILK-001", lots[0].LotNumber)
+		assert.Equal(t, fmt.Sprintf("DEL-%d", newer.ID), lots[1].LotNumber)
+		assert.Equal(t, 5.0, lots[1].RemainingQuantity)
+	})
+
+	t.Run("Consume Oldest Lot First", func(t *testing.T) {
+		shortfall, err := service.ConsumeFromLots(item.ID, 12)
+		require.NoError(t, err)
+		assert.Equal(t, 0.0, shortfall)
+
+		lots, err := service.GetLotsByItem(item.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 0.0, lots[0].RemainingQuantity)
+		assert.Equal(t, 3.0, lots[1].RemainingQuantity)
+
+		shortfall, err = service.ConsumeFromLots(item.ID, 5)
+		require.NoError(t, err)
+		assert.Equal(t, 2.0, shortfall)
+
+		_, err = service.ConsumeFromLots(item.ID, -1)
+		assert.Error(t, err)
+	})
+
+	t.Run("Count Reconciles Lots", func(t *testing.T) {
+		delivery := &models.Delivery{
+			AccountID:       account.ID,
+			InventoryItemID: item.ID,
+			Vendor:          "Test Vendor",
+			Quantity:        8,
+			DeliveryDate:    today.AddDate(0, 0, -5),
+			Cost:            16,
+		}
+		require.NoError(t, service.CreateDelivery(delivery))
+
+		// Only 6 units are counted, so 2 were used
+		snapshot := &models.InventorySnapshot{
+			AccountID: account.ID,
+			Counts:    map[int]float64{item.ID: 6},
+		}
+		require.NoError(t, service.CreateInventorySnapshot(snapshot))
+
+		lots, err := service.GetLotsByItem(item.ID)
+		require.NoError(t, err)
+		require.Len(t, lots, 3)
+		assert.Equal(t, 6.0, lots[1].RemainingQuantity)
+	})
+
+	t.Run("Expiring Lots", func(t *testing.T) {
+		// The lot delivered five days ago expires in two days; the empty lot is ignored
+		lots, err := service.GetExpiringLots(account.ID, ExpiringSoonDays)
+		require.NoError(t, err)
+		require.Len(t, lots, 1)
+		assert.Equal(t, "Milk", lots[0].ItemName)
+		assert.Equal(t, 2, lots[0].DaysUntilExpiry)
+		assert.Equal(t, 6.0, lots[0].RemainingQuantity)
+
+		lots, err = service.GetExpiringLots(account.ID, 1)
+		require.NoError(t, err)
+		assert.Empty(t, lots)
+
+		_, err = service.GetExpiringLots(account.ID, -1)
+		assert.Error(t, err)
+	})
+
+	t.Run("Delivery Corrections Follow Lots", func(t *testing.T) {
+		delivery := &models.Delivery{AccountID: account.ID, InventoryItemID: item.ID, Vendor: "Test Vendor", Quantity: 4, DeliveryDate: today, Cost: 8}
+		require.NoError(t, service.CreateDelivery(delivery))
+
+		// An untouched lot is resized with its delivery
+		delivery.Quantity = 3
+		require.NoError(t, service.UpdateDelivery(delivery))
+		lots, err := service.GetLotsByItem(item.ID)
+		require.NoError(t, err)
+		require.Len(t, lots, 4)
+		assert.Equal(t, 3.0, lots[3].InitialQuantity)
+		assert.Equal(t, 3.0, lots[3].RemainingQuantity)
+
+		// and deleted with it
+		require.NoError(t, service.DeleteDelivery(delivery.ID))
+		lots, err = service.GetLotsByItem(item.ID)
+		require.NoError(t, err)
+		assert.Len(t, lots, 3)
+
+		// The lot counted down to 6 units was partly used
+		var used models.Delivery
+		for _, lot := range lots {
+			if lot.RemainingQuantity > 0 && lot.RemainingQuantity < lot.InitialQuantity {
+				stored, err := service.GetDelivery(*lot.DeliveryID)
+				require.NoError(t, err)
+				used = *stored
+			}
+		}
+		require.NotZero(t, used.ID)
+
+		changed := used
+		changed.Quantity = 10
+		assert.ErrorIs(t, service.UpdateDelivery(&changed), ErrDeliveryLotConsumed)
+		assert.ErrorIs(t, service.DeleteDelivery(used.ID), ErrDeliveryLotConsumed)
+
+		// Corrections that leave the quantity alone are still accepted
+		changed = used
+		changed.Cost = 20
+		require.NoError(t, service.UpdateDelivery(&changed))
+		lots, err = service.GetLotsByItem(item.ID)
+		require.NoError(t, err)
+		require.Len(t, lots, 3)
+		assert.Equal(t, 6.0, lots[1].RemainingQuantity)
+	})
+}
+
+func TestWasteOperations(t *testing.T) {
+	db, cleanup := SetupTestDBLegacy(t)
+	defer cleanup()
+
+	service := NewService(db)
+
+	// Create organization, account, category, and inventory item
+	org := createTestOrganizationLegacy(t, service, "Waste Test Corp")
+	account := createTestAccountLegacy(t, service, org.ID, "Test Coffee Shop")
+	category := &models.Category{AccountID: account.ID, Name: "Dairy", IsActive: true}
+	require.NoError(t, service.CreateCategory(category))
+	item := createTestInventoryItemLegacy(t, service, account.ID, "Milk")
+	item.CategoryID = &category.ID
+	item.CostPerUnit = 2.0
+	require.NoError(t, service.UpdateInventoryItem(item))
+	other := createTestInventoryItemLegacy(t, service, account.ID, "Cups")
+	other.CostPerUnit = 0.5
+	require.NoError(t, service.UpdateInventoryItem(other))
+
+	now := time.Now()
+	snapshot := &models.InventorySnapshot{
+		AccountID: account.ID,
+		Timestamp: now.Add(-48 * time.Hour),
+		Counts:    map[int]float64{item.ID: 20, other.ID: 100},
+	}
+	require.NoError(t, db.Create(snapshot).Error) // bypass the repository so the count can be backdated
+
+	// A latte uses 0.25 of milk, and 24 lattes were sold
+	menuItem := createTestMenuItemLegacy(t, service, account.ID, "Latte")
+	require.NoError(t, db.Create(&models.RecipeIngredient{MenuItemID: menuItem.ID, InventoryItemID: item.ID, Quantity: 0.25}).Error)
+	sale := &models.Sale{AccountID: account.ID, SaleDate: now.Add(-time.Hour)}
+	require.NoError(t, db.Create(sale).Error)
+	require.NoError(t, db.Create(&models.SaleItem{SaleID: sale.ID, MenuItemID: uint(menuItem.ID), Quantity: 24}).Error)
+	require.NoError(t, service.RebuildInventoryLevels(account.ID)) // levels do not see rows written outside the service
+
+	t.Run("Log Waste", func(t *testing.T) {
+		wasteLog := &models.WasteLog{
+			AccountID:       account.ID,
+			InventoryItemID: item.ID,
+			Quantity:        2,
+			Reason:          " Expired ",
+		}
+		require.NoError(t, service.LogWaste(wasteLog))
+		assert.Equal(t, models.WasteReasonExpired, wasteLog.Reason)
+		assert.Equal(t, 2.0, wasteLog.UnitCost)
+		assert.False(t, wasteLog.WastedAt.IsZero())
+
+		require.NoError(t, service.LogWaste(&models.WasteLog{
+			AccountID:       account.ID,
+			InventoryItemID: other.ID,
+			Quantity:        10,
+			Reason:          models.WasteReasonSpilled,
+		}))
+	})
+
+	t.Run("Reject Invalid Waste", func(t *testing.T) {
+		err := service.LogWaste(&models.WasteLog{AccountID: account.ID, InventoryItemID: item.ID, Quantity: 1, Reason: "stolen"})
+		assert.Error(t, err)
+
+		err = service.LogWaste(&models.WasteLog{AccountID: account.ID, InventoryItemID: item.ID, Quantity: 0, Reason: models.WasteReasonSpilled})
+		assert.Error(t, err)
+
+		err = service.LogWaste(&models.WasteLog{AccountID: account.ID + 1, InventoryItemID: item.ID, Quantity: 1, Reason: models.WasteReasonSpilled})
+		assert.Error(t, err)
+	})
+
+	t.Run("Waste Reduces Current Stock", func(t *testing.T) {
+		items, err := service.GetInventoryItemsWithCurrentStock(account.ID)
+		require.NoError(t, err)
+
+		stock := make(map[int]float64)
+		for _, withStock := range items {
+			stock[withStock.ID] = withStock.CurrentStock
+		}
+		assert.Equal(t, 12.0, stock[item.ID]) // 20 counted - 6 sold - 2 wasted
+		assert.Equal(t, 90.0, stock[other.ID])
+	})
+
+	t.Run("Wastage Rate From Usage", func(t *testing.T) {
+		// 2 wasted against 6 used is 25% of the stock consumed
+		retrieved, err := service.GetInventoryItem(item.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 25.0, retrieved.WastageRate)
+
+		// Nothing of the other item was used, so all of it was waste
+		retrieved, err = service.GetInventoryItem(other.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 100.0, retrieved.WastageRate)
+	})
+
+	t.Run("Sales Change The Wastage Rate", func(t *testing.T) {
+		// 8 more lattes use 2 more milk: 2 wasted against 8 used is 20%
+		require.NoError(t, service.RecordSale(&models.Sale{AccountID: account.ID, Items: []models.SaleItem{{MenuItemID: uint(menuItem.ID), Quantity: 8, PriceAtSale: 4}}}))
+
+		items, err := service.GetInventoryItemsWithCurrentStock(account.ID)
+		require.NoError(t, err)
+		rates := make(map[int]float64)
+		for _, withStock := range items {
+			rates[withStock.ID] = withStock.WastageRate
+		}
+		assert.Equal(t, 20.0, rates[item.ID])
+		assert.Equal(t, 100.0, rates[other.ID])
+	})
+
+	t.Run("Waste Cost Report", func(t *testing.T) {
+		report, err := service.GetWasteCostReport(account.ID, now.Add(-24*time.Hour), now.Add(time.Hour))
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, report.Entries)
+		assert.InDelta(t, 9.0, report.TotalCost, 0.0001)
+		require.Len(t, report.ByReason, 2)
+		assert.Equal(t, models.WasteReasonSpilled, report.ByReason[0].Name)
+		assert.InDelta(t, 5.0, report.ByReason[0].Cost, 0.0001)
+		assert.Equal(t, 55.56, report.ByReason[0].Share)
+		require.Len(t, report.ByCategory, 2)
+		assert.Equal(t, "Uncategorized", report.ByCategory[0].Name)
+		assert.Equal(t, "Dairy", report.ByCategory[1].Name)
+
+		_, err = service.GetWasteCostReport(account.ID, now, now.Add(-time.Hour))
+		assert.Error(t, err)
+	})
+
+	t.Run("Delete Waste", func(t *testing.T) {
+		wasteLogs, err := service.GetWasteLogs(account.ID, now.Add(-24*time.Hour), now.Add(time.Hour))
+		require.NoError(t, err)
+		require.Len(t, wasteLogs, 2)
+
+		for _, wasteLog := range wasteLogs {
+			if wasteLog.InventoryItemID == item.ID {
+				require.NoError(t, service.DeleteWasteLog(wasteLog.ID))
+			}
+		}
+
+		retrieved, err := service.GetInventoryItem(item.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 0.0, retrieved.WastageRate)
+	})
+}
+
+func TestCountSessionOperations(t *testing.T) {
+	db, cleanup := SetupTestDBLegacy(t)
+	defer cleanup()
+
+	service := NewService(db)
+
+	// Create organization, account, and inventory items with a starting count
+	org := createTestOrganizationLegacy(t, service, "Count Test Corp")
+	account := createTestAccountLegacy(t, service, org.ID, "Test Coffee Shop")
+	milk := createTestInventoryItemLegacy(t, service, account.ID, "Milk")
+	beans := createTestInventoryItemLegacy(t, service, account.ID, "Coffee Beans")
+	cups := createTestInventoryItemLegacy(t, service, account.ID, "Cups")
+	snapshot := &models.InventorySnapshot{
+		AccountID: account.ID,
+		Timestamp: time.Now().Add(-24 * time.Hour),
+		Counts:    map[int]float64{milk.ID: 20, beans.ID: 10, cups.ID: 200},
+	}
+	require.NoError(t, db.Create(snapshot).Error) // bypass the repository so the count can be backdated
+	require.NoError(t, service.RebuildInventoryLevels(account.ID))
+
+	session := &models.CountSession{AccountID: account.ID, Name: " Month-end "}
+	require.NoError(t, service.StartCountSession(session))
+	assert.Equal(t, models.CountSessionStatusOpen, session.Status)
+	assert.Equal(t, "Month-end", session.Name)
+
+	t.Run("Record Counts By Zone", func(t *testing.T) {
+		// Two staff count milk in different zones; beans are recounted in the same zone
+		require.NoError(t, service.RecordCount(&models.CountEntry{CountSessionID: session.ID, InventoryItemID: milk.ID, Zone: "walk-in", Quantity: 12}))
+		require.NoError(t, service.RecordCount(&models.CountEntry{CountSessionID: session.ID, InventoryItemID: milk.ID, Zone: "bar", Quantity: 7}))
+		require.NoError(t, service.RecordCount(&models.CountEntry{CountSessionID: session.ID, InventoryItemID: beans.ID, Zone: "dry store", Quantity: 4}))
+		require.NoError(t, service.RecordCount(&models.CountEntry{CountSessionID: session.ID, InventoryItemID: beans.ID, Zone: " dry store ", Quantity: 6}))
+
+		entries, err := service.GetCountEntries(session.ID)
+		require.NoError(t, err)
+		assert.Len(t, entries, 3)
+
+		err = service.RecordCount(&models.CountEntry{CountSessionID: session.ID, InventoryItemID: milk.ID, Quantity: -1})
+		assert.Error(t, err)
+	})
+
+	t.Run("Review Flags Large Deviations", func(t *testing.T) {
+		review, err := service.ReviewCountSession(session.ID, 0)
+		require.NoError(t, err)
+		assert.Equal(t, DefaultCountVarianceThresholdPct, review.ThresholdPct)
+
+		require.Len(t, review.Lines, 2)
+		beansLine, milkLine := review.Lines[0], review.Lines[1]
+		assert.Equal(t, 6.0, beansLine.Counted)
+		assert.Equal(t, -40.0, beansLine.VariancePct)
+		assert.True(t, beansLine.Flagged)
+		assert.Equal(t, 19.0, milkLine.Counted)
+		assert.Len(t, milkLine.Zones, 2)
+		assert.False(t, milkLine.Flagged)
+		assert.Equal(t, 1, review.FlaggedLines)
+
+		require.Len(t, review.Uncounted, 1)
+		assert.Equal(t, cups.ID, review.Uncounted[0].InventoryItemID)
+	})
+
+	t.Run("Abandoned Session Produces No Snapshot", func(t *testing.T) {
+		abandoned := &models.CountSession{AccountID: account.ID}
+		require.NoError(t, service.StartCountSession(abandoned))
+		require.NoError(t, service.RecordCount(&models.CountEntry{CountSessionID: abandoned.ID, InventoryItemID: milk.ID, Quantity: 1}))
+
+		closed, err := service.AbandonCountSession(abandoned.ID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, models.CountSessionStatusAbandoned, closed.Status)
+
+		_, err = service.FinalizeCountSession(abandoned.ID, 1)
+		assert.Error(t, err)
+		err = service.RecordCount(&models.CountEntry{CountSessionID: abandoned.ID, InventoryItemID: milk.ID, Quantity: 2})
+		assert.Error(t, err)
+
+		snapshots, err := service.GetInventorySnapshotsByAccount(account.ID)
+		require.NoError(t, err)
+		assert.Len(t, snapshots, 1)
+	})
+
+	t.Run("Finalize Produces Snapshot", func(t *testing.T) {
+		result, err := service.FinalizeCountSession(session.ID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 19.0, result.Counts[milk.ID])
+		assert.Equal(t, 6.0, result.Counts[beans.ID])
+		assert.Equal(t, 200.0, result.Counts[cups.ID]) // uncounted stock is carried forward
+
+		finalized, err := service.GetCountSession(session.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.CountSessionStatusFinalized, finalized.Status)
+		require.NotNil(t, finalized.SnapshotID)
+		assert.Equal(t, result.ID, *finalized.SnapshotID)
+
+		// A finalized session cannot be finalized again
+		_, err = service.FinalizeCountSession(session.ID, 1)
+		assert.Error(t, err)
+
+		snapshots, err := service.GetInventorySnapshotsByAccount(account.ID)
+		require.NoError(t, err)
+		assert.Len(t, snapshots, 2)
+	})
+
+	t.Run("Empty Session Cannot Be Finalized", func(t *testing.T) {
+		empty := &models.CountSession{AccountID: account.ID}
+		require.NoError(t, service.StartCountSession(empty))
+
+		_, err := service.FinalizeCountSession(empty.ID, 1)
+		assert.Error(t, err)
+
+		reloaded, err := service.GetCountSession(empty.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.CountSessionStatusOpen, reloaded.Status)
+	})
+
+	t.Run("Failed Transaction Rolls Back", func(t *testing.T) {
+		err := service.withTransaction(func(tx *Service) error {
+			require.NoError(t, tx.CreateInventorySnapshot(&models.InventorySnapshot{
+				AccountID: account.ID,
+				Counts:    map[int]float64{milk.ID: 1},
+			}))
+			return errors.New("finalize failed")
+		})
+		assert.Error(t, err)
+
+		snapshots, err := service.GetInventorySnapshotsByAccount(account.ID)
+		require.NoError(t, err)
+		assert.Len(t, snapshots, 2)
+	})
+}
+
+func TestStorageLocationOperations(t *testing.T) {
+	db, cleanup := SetupTestDBLegacy(t)
+	defer cleanup()
+
+	service := NewService(db)
+
+	org := createTestOrganizationLegacy(t, service, "Location Test Corp")
+	account := createTestAccountLegacy(t, service, org.ID, "Test Bistro")
+	milk := createTestInventoryItemLegacy(t, service, account.ID, "Milk")
+	beans := createTestInventoryItemLegacy(t, service, account.ID, "Coffee Beans")
+
+	walkIn := &models.StorageLocation{AccountID: account.ID, Name: "Walk-in", IsActive: true}
+	bar := &models.StorageLocation{AccountID: account.ID, Name: "Bar", IsActive: true}
+
+	t.Run("Create Locations", func(t *testing.T) {
+		require.NoError(t, service.CreateStorageLocation(walkIn))
+		require.NoError(t, service.CreateStorageLocation(bar))
+		assert.True(t, walkIn.IsDefault, "the first location becomes the default")
+		assert.False(t, bar.IsDefault)
+
+		err := service.CreateStorageLocation(&models.StorageLocation{AccountID: account.ID, Name: " walk-in ", IsActive: true})
+		assert.Error(t, err, "location names are unique within an account")
+
+		locations, err := service.GetStorageLocationsByAccount(account.ID)
+		require.NoError(t, err)
+		require.Len(t, locations, 2)
+		assert.Equal(t, "Bar", locations[0].Name)
+	})
+
+	// Start from a count where part of the milk was counted without a location
+	snapshot := &models.InventorySnapshot{
+		AccountID:      account.ID,
+		Timestamp:      time.Now().Add(-24 * time.Hour),
+		Counts:         models.CountsMap{milk.ID: 20, beans.ID: 5},
+		LocationCounts: models.LocationCountsMap{walkIn.ID: {milk.ID: 15}},
+	}
+	require.NoError(t, db.Create(snapshot).Error) // bypass the repository so the count can be backdated
+	require.NoError(t, service.RebuildInventoryLevels(account.ID))
+
+	t.Run("Stock Moves Between Locations", func(t *testing.T) {
+		// Deliveries without a location go to the default location
+		delivery := &models.Delivery{AccountID: account.ID, InventoryItemID: milk.ID, Vendor: "Test Vendor", Quantity: 10, DeliveryDate: time.Now(), Cost: 10}
+		require.NoError(t, service.CreateDelivery(delivery))
+		require.NotNil(t, delivery.StorageLocationID)
+		assert.Equal(t, walkIn.ID, *delivery.StorageLocationID)
+
+		transfer := &models.LocationTransfer{AccountID: account.ID, InventoryItemID: milk.ID, FromLocationID: walkIn.ID, ToLocationID: bar.ID, Quantity: 8}
+		require.NoError(t, service.TransferBetweenLocations(transfer))
+		assert.False(t, transfer.TransferredAt.IsZero())
+
+		err := service.TransferBetweenLocations(&models.LocationTransfer{AccountID: account.ID, InventoryItemID: milk.ID, FromLocationID: bar.ID, ToLocationID: bar.ID, Quantity: 1})
+		assert.Error(t, err)
+
+		require.NoError(t, service.LogWaste(&models.WasteLog{AccountID: account.ID, InventoryItemID: milk.ID, Quantity: 2, Reason: models.WasteReasonSpilled, StorageLocationID: &bar.ID}))
+
+		items, err := service.GetInventoryItemsWithLocationStock(account.ID)
+		require.NoError(t, err)
+		byItem := make(map[int]InventoryItemWithLocationStock)
+		for _, item := range items {
+			byItem[item.ID] = item
+		}
+
+		// Transfers move stock without changing the total
+		milkStock := byItem[milk.ID]
+		assert.Equal(t, 28.0, milkStock.CurrentStock)
+		require.Len(t, milkStock.Locations, 3)
+		assert.Equal(t, "", milkStock.Locations[0].LocationName)
+		assert.Nil(t, milkStock.Locations[0].StorageLocationID)
+		assert.Equal(t, 5.0, milkStock.Locations[0].Quantity)
+		assert.Equal(t, "Bar", milkStock.Locations[1].LocationName)
+		assert.Equal(t, 6.0, milkStock.Locations[1].Quantity)
+		assert.Equal(t, "Walk-in", milkStock.Locations[2].LocationName)
+		assert.Equal(t, 17.0, milkStock.Locations[2].Quantity)
+
+		totals, err := service.GetInventoryItemsWithCurrentStock(account.ID)
+		require.NoError(t, err)
+		for _, item := range totals {
+			assert.Equal(t, item.CurrentStock, byItem[item.ID].CurrentStock)
+		}
+
+		err = service.DeleteStorageLocation(bar.ID)
+		assert.Error(t, err, "a location holding stock cannot be deleted")
+	})
+
+	t.Run("Count Session By Location", func(t *testing.T) {
+		session := &models.CountSession{AccountID: account.ID}
+		require.NoError(t, service.StartCountSession(session))
+		require.NoError(t, service.RecordCount(&models.CountEntry{CountSessionID: session.ID, InventoryItemID: milk.ID, StorageLocationID: &bar.ID, Zone: "fridge", Quantity: 6}))
+		require.NoError(t, service.RecordCount(&models.CountEntry{CountSessionID: session.ID, InventoryItemID: milk.ID, Zone: "fridge", Quantity: 21}))
+		require.NoError(t, service.RecordCount(&models.CountEntry{CountSessionID: session.ID, InventoryItemID: milk.ID, Zone: "fridge", Quantity: 22}))
+
+		// The same zone name at another location is a separate count
+		entries, err := service.GetCountEntries(session.ID)
+		require.NoError(t, err)
+		assert.Len(t, entries, 2)
+
+		review, err := service.ReviewCountSession(session.ID, 0)
+		require.NoError(t, err)
+		require.Len(t, review.Lines, 1)
+		assert.Equal(t, 28.0, review.Lines[0].Counted)
+		assert.Equal(t, 28.0, review.Lines[0].Expected)
+
+		finalized, err := service.FinalizeCountSession(session.ID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 28.0, finalized.Counts[milk.ID])
+		assert.Equal(t, 5.0, finalized.Counts[beans.ID], "uncounted items are carried forward")
+		assert.Equal(t, 6.0, finalized.LocationCounts[bar.ID][milk.ID])
+		assert.Equal(t, 22.0, finalized.LocationCounts[walkIn.ID][milk.ID])
+
+		latest, err := service.GetLatestInventorySnapshot(account.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 6.0, latest.LocationCounts[bar.ID][milk.ID])
+	})
+
+	t.Run("Snapshot Location Counts", func(t *testing.T) {
+		snapshot := &models.InventorySnapshot{
+			AccountID:      account.ID,
+			LocationCounts: models.LocationCountsMap{bar.ID: {beans.ID: 3}},
+		}
+		require.NoError(t, service.CreateInventorySnapshot(snapshot))
+		assert.Equal(t, 3.0, snapshot.Counts[beans.ID], "totals are filled from the location counts")
+
+		err := service.CreateInventorySnapshot(&models.InventorySnapshot{
+			AccountID:      account.ID,
+			Counts:         models.CountsMap{beans.ID: 1},
+			LocationCounts: models.LocationCountsMap{bar.ID: {beans.ID: 3}},
+		})
+		assert.Error(t, err, "a total cannot be less than its location counts")
+	})
+
+	t.Run("Change Default Location", func(t *testing.T) {
+		bar.IsDefault = true
+		require.NoError(t, service.UpdateStorageLocation(bar))
+
+		updated, err := service.GetStorageLocation(walkIn.ID)
+		require.NoError(t, err)
+		assert.False(t, updated.IsDefault)
+
+		bar.IsActive = false
+		assert.Error(t, service.UpdateStorageLocation(bar), "the default location cannot be deactivated")
+	})
+}
+
+func TestStockTransferOperations(t *testing.T) {
+	db, cleanup := SetupTestDBLegacy(t)
+	defer cleanup()
+
+	service := NewService(db)
+
+	org := createTestOrganizationLegacy(t, service, "Transfer Test Corp")
+	downtown := createTestAccountLegacy(t, service, org.ID, "Downtown")
+	uptown := createTestAccountLegacy(t, service, org.ID, "Uptown")
+	otherOrg := createTestOrganizationLegacy(t, service, "Other Corp")
+	stranger := createTestAccountLegacy(t, service, otherOrg.ID, "Stranger")
+
+	downtownMilk := createTestInventoryItemLegacy(t, service, downtown.ID, "Milk")
+	uptownMilk := createTestInventoryItemLegacy(t, service, uptown.ID, " milk ")
+	createTestInventoryItemLegacy(t, service, stranger.ID, "Milk")
+
+	snapshot := &models.InventorySnapshot{
+		AccountID: downtown.ID,
+		Timestamp: time.Now().Add(-24 * time.Hour),
+		Counts:    models.CountsMap{downtownMilk.ID: 30},
+	}
+	require.NoError(t, db.Create(snapshot).Error) // bypass the repository so the count can be backdated
+	require.NoError(t, service.RebuildInventoryLevels(downtown.ID))
+
+	stockOf := func(accountID, itemID int) float64 {
+		items, err := service.GetInventoryItemsWithCurrentStock(accountID)
+		require.NoError(t, err)
+		for _, item := range items {
+			if item.ID == itemID {
+				return item.CurrentStock
+			}
+		}
+		t.Fatalf("item %d not found in account %d", itemID, accountID)
+		return 0
+	}
+
+	t.Run("Reject Transfers Outside The Organization", func(t *testing.T) {
+		err := service.SendStockTransfer(&models.StockTransfer{FromAccountID: downtown.ID, ToAccountID: stranger.ID, FromInventoryItemID: downtownMilk.ID, Quantity: 5})
+		assert.Error(t, err)
+
+		err = service.SendStockTransfer(&models.StockTransfer{FromAccountID: downtown.ID, ToAccountID: downtown.ID, FromInventoryItemID: downtownMilk.ID, Quantity: 5})
+		assert.Error(t, err)
+
+		err = service.SendStockTransfer(&models.StockTransfer{FromAccountID: uptown.ID, ToAccountID: downtown.ID, FromInventoryItemID: downtownMilk.ID, Quantity: 5})
+		assert.Error(t, err, "the source item must belong to the sending account")
+	})
+
+	t.Run("Send And Receive", func(t *testing.T) {
+		transfer := &models.StockTransfer{FromAccountID: downtown.ID, ToAccountID: uptown.ID, FromInventoryItemID: downtownMilk.ID, Quantity: 12}
+		require.NoError(t, service.SendStockTransfer(transfer))
+		assert.Equal(t, models.StockTransferStatusInTransit, transfer.Status)
+		assert.Equal(t, org.ID, transfer.OrganizationID)
+		assert.Equal(t, downtownMilk.CostPerUnit, transfer.UnitCost)
+
+		// Stock leaves the source when sent but only reaches the destination when received
+		assert.Equal(t, 18.0, stockOf(downtown.ID, downtownMilk.ID))
+		assert.Equal(t, 0.0, stockOf(uptown.ID, uptownMilk.ID))
+
+		_, err := service.ReceiveStockTransfer(transfer.ID, StockTransferReceipt{ReceivedQuantity: 13}, 1)
+		assert.Error(t, err, "cannot receive more than was sent")
+
+		received, err := service.ReceiveStockTransfer(transfer.ID, StockTransferReceipt{ReceivedQuantity: 11}, 1)
+		require.NoError(t, err)
+		assert.Equal(t, models.StockTransferStatusReceived, received.Status)
+		require.NotNil(t, received.ToInventoryItemID)
+		assert.Equal(t, uptownMilk.ID, *received.ToInventoryItemID, "the destination item is matched by name")
+		assert.Equal(t, 11.0, stockOf(uptown.ID, uptownMilk.ID))
+
+		lots, err := service.GetLotsByItem(uptownMilk.ID)
+		require.NoError(t, err)
+		require.Len(t, lots, 1)
+		assert.Equal(t, fmt.Sprintf("TRF-%d", transfer.ID), lots[0].LotNumber)
+
+		_, err = service.ReceiveStockTransfer(transfer.ID, StockTransferReceipt{}, 1)
+		assert.Error(t, err, "a transfer can only be received once")
+		_, err = service.CancelStockTransfer(transfer.ID)
+		assert.Error(t, err)
+
+		transfers, err := service.GetStockTransfersByAccount(uptown.ID)
+		require.NoError(t, err)
+		assert.Len(t, transfers, 1)
+	})
+
+	t.Run("Cancel Returns Stock To The Source", func(t *testing.T) {
+		require.NoError(t, service.CreateDelivery(&models.Delivery{AccountID: downtown.ID, InventoryItemID: downtownMilk.ID, Vendor: "Local Dairy", Quantity: 3, DeliveryDate: time.Now().Add(-2 * time.Hour)}))
+		require.NoError(t, service.CreateDelivery(&models.Delivery{AccountID: downtown.ID, InventoryItemID: downtownMilk.ID, Vendor: "Local Dairy", Quantity: 4, DeliveryDate: time.Now().Add(-time.Hour)}))
+		assert.Equal(t, 25.0, stockOf(downtown.ID, downtownMilk.ID))
+
+		transfer := &models.StockTransfer{FromAccountID: downtown.ID, ToAccountID: uptown.ID, FromInventoryItemID: downtownMilk.ID, Quantity: 5}
+		require.NoError(t, service.SendStockTransfer(transfer))
+		assert.Equal(t, 20.0, stockOf(downtown.ID, downtownMilk.ID))
+
+		lots, err := service.GetLotsByItem(downtownMilk.ID)
+		require.NoError(t, err)
+		require.Len(t, lots, 2)
+		assert.Equal(t, 0.0, lots[0].RemainingQuantity)
+		assert.Equal(t, 2.0, lots[1].RemainingQuantity)
+
+		cancelled, err := service.CancelStockTransfer(transfer.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.StockTransferStatusCancelled, cancelled.Status)
+		assert.Equal(t, 25.0, stockOf(downtown.ID, downtownMilk.ID))
+
+		// The lots the transfer took from are refilled
+		lots, err = service.GetLotsByItem(downtownMilk.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 3.0, lots[0].RemainingQuantity)
+		assert.Equal(t, 4.0, lots[1].RemainingQuantity)
+	})
+
+	t.Run("Count Review Expects Transferred Stock", func(t *testing.T) {
+		session := &models.CountSession{AccountID: uptown.ID}
+		require.NoError(t, service.StartCountSession(session))
+		require.NoError(t, service.RecordCount(&models.CountEntry{CountSessionID: session.ID, InventoryItemID: uptownMilk.ID, Quantity: 11}))
+
+		review, err := service.ReviewCountSession(session.ID, 0)
+		require.NoError(t, err)
+		require.Len(t, review.Lines, 1)
+		assert.Equal(t, 11.0, review.Lines[0].Expected)
+		assert.Equal(t, 0.0, review.Lines[0].Variance)
+	})
+}
+
+func TestCatalogOperations(t *testing.T) {
+	db, cleanup := SetupTestDBLegacy(t)
+	defer cleanup()
+
+	service := NewService(db)
+
+	org := createTestOrganizationLegacy(t, service, "Franchise Corp")
+	store := createTestAccountLegacy(t, service, org.ID, "Franchise Store")
+	unsubscribed := createTestAccountLegacy(t, service, org.ID, "Independent Store")
+	standalone := createTestStandaloneAccountLegacy(t, service, "Standalone Cafe")
+
+	localFlour := createTestInventoryItemLegacy(t, service, store.ID, "flour")
+	localSugar := &models.InventoryItem{AccountID: store.ID, Name: "Sugar", Unit: "lb", CostPerUnit: 2}
+	require.NoError(t, service.CreateInventoryItem(localSugar))
+
+	baking := &models.CatalogCategory{OrganizationID: org.ID, Name: "Baking", IsActive: true}
+	require.NoError(t, service.CreateCatalogCategory(baking))
+	flour := &models.CatalogItem{OrganizationID: org.ID, CatalogCategoryID: &baking.ID, Name: "Flour", Unit: "kg", CostPerUnit: 3, PreferredVendor: "Mill Co", IsActive: true}
+	require.NoError(t, service.CreateCatalogItem(flour))
+	butter := &models.CatalogItem{OrganizationID: org.ID, CatalogCategoryID: &baking.ID, Name: "Butter", Unit: "kg", CostPerUnit: 8, PreferredVendor: "Dairy Co", IsActive: true}
+	require.NoError(t, service.CreateCatalogItem(butter))
+	sugar := &models.CatalogItem{OrganizationID: org.ID, Name: "Sugar", Unit: "kg", CostPerUnit: 1, IsActive: true}
+	require.NoError(t, service.CreateCatalogItem(sugar))
+
+	croissant := &CatalogMenuItemWithRecipe{
+		CatalogMenuItem: models.CatalogMenuItem{OrganizationID: org.ID, CatalogCategoryID: &baking.ID, Name: "Croissant", Price: 3.5, IsActive: true},
+		Ingredients: []models.CatalogRecipeIngredient{
+			{CatalogItemID: flour.ID, Quantity: 0.1},
+			{CatalogItemID: butter.ID, Quantity: 0.05},
+		},
+	}
+	require.NoError(t, service.CreateCatalogMenuItem(croissant))
+
+	localItem := func(accountID int, name string) *models.InventoryItem {
+		items, err := service.GetInventoryItemsByAccount(accountID)
+		require.NoError(t, err)
+		for i := range items {
+			if items[i].Name == name {
+				return &items[i]
+			}
+		}
+		return nil
+	}
+	localMenuItem := func(accountID int, name string) *models.MenuItem {
+		items, err := service.GetMenuItemsByAccount(accountID)
+		require.NoError(t, err)
+		for i := range items {
+			if items[i].Name == name {
+				return &items[i]
+			}
+		}
+		return nil
+	}
+
+	t.Run("Reject Invalid Catalog Entries", func(t *testing.T) {
+		assert.Error(t, service.CreateCatalogCategory(&models.CatalogCategory{OrganizationID: org.ID, Name: " baking "}), "names are unique within the catalog")
+		assert.Error(t, service.CreateCatalogItem(&models.CatalogItem{OrganizationID: org.ID, Name: "Eggs"}), "unit is required")
+
+		other := createTestOrganizationLegacy(t, service, "Other Corp")
+		err := service.CreateCatalogMenuItem(&CatalogMenuItemWithRecipe{
+			CatalogMenuItem: models.CatalogMenuItem{OrganizationID: other.ID, Name: "Bread"},
+			Ingredients:     []models.CatalogRecipeIngredient{{CatalogItemID: flour.ID, Quantity: 1}},
+		})
+		assert.Error(t, err, "recipes may only use items of the same catalog")
+	})
+
+	t.Run("Only Organization Accounts Can Subscribe", func(t *testing.T) {
+		_, err := service.SubscribeToCatalog(standalone.ID)
+		assert.Error(t, err)
+		_, err = service.SyncCatalog(unsubscribed.ID)
+		assert.Error(t, err)
+	})
+
+	t.Run("Subscribe Adopts And Creates Local Entries", func(t *testing.T) {
+		result, err := service.SubscribeToCatalog(store.ID)
+		require.NoError(t, err)
+		assert.Equal(t, store.ID, result.AccountID)
+
+		// The local flour is adopted by name and keeps its own cost and vendor
+		adopted := localItem(store.ID, "Flour")
+		require.NotNil(t, adopted)
+		assert.Equal(t, localFlour.ID, adopted.ID)
+		require.NotNil(t, adopted.CatalogItemID)
+		assert.Equal(t, flour.ID, *adopted.CatalogItemID)
+		assert.Equal(t, localFlour.CostPerUnit, adopted.CostPerUnit)
+		assert.Equal(t, localFlour.PreferredVendor, adopted.PreferredVendor)
+
+		// New items are seeded with the catalog cost and vendor
+		created := localItem(store.ID, "Butter")
+		require.NotNil(t, created)
+		assert.Equal(t, 8.0, created.CostPerUnit)
+		assert.Equal(t, "Dairy Co", created.PreferredVendor)
+		require.NotNil(t, created.CategoryID)
+
+		// The local sugar is counted in another unit and is left alone
+		require.Len(t, result.Conflicts, 1)
+		assert.Equal(t, models.CatalogEntityItem, result.Conflicts[0].EntityType)
+		assert.Equal(t, sugar.ID, result.Conflicts[0].CatalogEntityID)
+		assert.Equal(t, "unit", result.Conflicts[0].Field)
+		sugarItem := localItem(store.ID, "Sugar")
+		require.NotNil(t, sugarItem)
+		assert.Nil(t, sugarItem.CatalogItemID)
+		assert.Equal(t, "lb", sugarItem.Unit)
+
+		menuItem := localMenuItem(store.ID, "Croissant")
+		require.NotNil(t, menuItem)
+		assert.Equal(t, 3.5, menuItem.Price)
+		recipe, err := service.recipes.GetIngredientsByMenuItemID(uint(menuItem.ID))
+		require.NoError(t, err)
+		assert.Len(t, recipe, 2)
+
+		conflicts, err := service.GetCatalogSyncConflicts(store.ID)
+		require.NoError(t, err)
+		assert.Len(t, conflicts, 1)
+
+		// Accounts that did not subscribe receive nothing
+		assert.Nil(t, localItem(unsubscribed.ID, "Butter"))
+	})
+
+	t.Run("Catalog Changes Are Pushed", func(t *testing.T) {
+		flour.MinStockLevel = 20
+		flour.CostPerUnit = 4
+		require.NoError(t, service.UpdateCatalogItem(flour))
+
+		pushed := localItem(store.ID, "Flour")
+		require.NotNil(t, pushed)
+		assert.Equal(t, 20.0, pushed.MinStockLevel)
+		assert.Equal(t, localFlour.CostPerUnit, pushed.CostPerUnit, "local cost overrides the catalog")
+
+		croissant.Price = 3.75
+		croissant.Ingredients = []models.CatalogRecipeIngredient{{CatalogItemID: flour.ID, Quantity: 0.12}}
+		require.NoError(t, service.UpdateCatalogMenuItem(croissant))
+
+		menuItem := localMenuItem(store.ID, "Croissant")
+		require.NotNil(t, menuItem)
+		assert.Equal(t, 3.75, menuItem.Price)
+		recipe, err := service.recipes.GetIngredientsByMenuItemID(uint(menuItem.ID))
+		require.NoError(t, err)
+		require.Len(t, recipe, 1)
+		assert.Equal(t, 0.12, recipe[0].Quantity)
+	})
+
+	t.Run("Report Recipe And Name Conflicts", func(t *testing.T) {
+		bun := &CatalogMenuItemWithRecipe{
+			CatalogMenuItem: models.CatalogMenuItem{OrganizationID: org.ID, Name: "Sweet Bun", Price: 2, IsActive: true},
+			Ingredients:     []models.CatalogRecipeIngredient{{CatalogItemID: sugar.ID, Quantity: 0.02}},
+		}
+		require.NoError(t, service.CreateCatalogMenuItem(bun))
+
+		localButter := localItem(store.ID, "Butter")
+		require.NotNil(t, localButter)
+		localButter.Name = "Ghee"
+		require.NoError(t, service.UpdateInventoryItem(localButter))
+		localGhee := &models.InventoryItem{AccountID: store.ID, Name: "Butter", Unit: "kg"}
+		require.NoError(t, service.CreateInventoryItem(localGhee))
+
+		result, err := service.SyncCatalog(store.ID)
+		require.NoError(t, err)
+
+		fields := make(map[string]bool)
+		for _, conflict := range result.Conflicts {
+			fields[conflict.Field] = true
+		}
+		assert.True(t, fields["unit"])
+		assert.True(t, fields["recipe"], "the sweet bun uses sugar, which is not synced")
+		assert.True(t, fields["name"], "the linked butter cannot take the name of another local item")
+		assert.Equal(t, "Ghee", localItem(store.ID, "Ghee").Name)
+
+		// Fixing the local unit resolves the conflicts on the next sync
+		localSugar.Unit = "kg"
+		require.NoError(t, service.UpdateInventoryItem(localSugar))
+		require.NoError(t, service.DeleteInventoryItem(localGhee.ID))
+		result, err = service.SyncCatalog(store.ID)
+		require.NoError(t, err)
+		assert.Empty(t, result.Conflicts)
+		assert.NotNil(t, localItem(store.ID, "Butter"))
+	})
+
+	t.Run("Unsubscribe Stops Updates", func(t *testing.T) {
+		require.NoError(t, service.UnsubscribeFromCatalog(store.ID))
+
+		flour.MinStockLevel = 40
+		require.NoError(t, service.UpdateCatalogItem(flour))
+		assert.Equal(t, 20.0, localItem(store.ID, "Flour").MinStockLevel)
+
+		subscription, err := service.GetCatalogSubscription(store.ID)
+		require.NoError(t, err)
+		assert.False(t, subscription.IsActive)
+	})
+}
+
+func TestStockAdjustmentOperations(t *testing.T) {
+	db, cleanup := SetupTestDBLegacy(t)
+	defer cleanup()
+
+	service := NewService(db)
+
+	org := createTestOrganizationLegacy(t, service, "Adjustment Test Corp")
+	account := createTestAccountLegacy(t, service, org.ID, "Adjustment Store")
+	otherAccount := createTestAccountLegacy(t, service, org.ID, "Other Store")
+	item := createTestInventoryItemLegacy(t, service, account.ID, "Olive Oil")
+	user := createTestUserLegacy(t, service, account.ID, "adjuster@example.com", "user")
+
+	snapshot := &models.InventorySnapshot{
+		AccountID: account.ID,
+		Timestamp: time.Now().Add(-24 * time.Hour),
+		Counts:    models.CountsMap{item.ID: 20},
+	}
+	require.NoError(t, db.Create(snapshot).Error) // bypass the repository so the count can be backdated
+	require.NoError(t, service.RebuildInventoryLevels(account.ID))
+
+	stockOf := func() float64 {
+		items, err := service.GetInventoryItemsWithCurrentStock(account.ID)
+		require.NoError(t, err)
+		for _, stocked := range items {
+			if stocked.ID == item.ID {
+				return stocked.CurrentStock
+			}
+		}
+		t.Fatalf("item %d not found", item.ID)
+		return 0
+	}
+
+	t.Run("Reject Invalid Adjustments", func(t *testing.T) {
+		assert.Error(t, service.RecordStockAdjustment(&models.StockAdjustment{AccountID: account.ID, InventoryItemID: item.ID, Quantity: 0, Reason: models.AdjustmentReasonBreakage}))
+		assert.Error(t, service.RecordStockAdjustment(&models.StockAdjustment{AccountID: account.ID, InventoryItemID: item.ID, Quantity: -1, Reason: "theft"}))
+		assert.Error(t, service.RecordStockAdjustment(&models.StockAdjustment{AccountID: account.ID, InventoryItemID: item.ID, Quantity: 2, Reason: models.AdjustmentReasonSample}), "only count corrections add stock")
+		assert.Error(t, service.RecordStockAdjustment(&models.StockAdjustment{AccountID: otherAccount.ID, InventoryItemID: item.ID, Quantity: -1, Reason: models.AdjustmentReasonBreakage}))
+	})
+
+	t.Run("Adjustments Change Current Stock", func(t *testing.T) {
+		breakage := &models.StockAdjustment{AccountID: account.ID, InventoryItemID: item.ID, Quantity: -3, Reason: " Breakage ", AdjustedBy: &user.ID}
+		require.NoError(t, service.RecordStockAdjustment(breakage))
+		assert.Equal(t, models.AdjustmentReasonBreakage, breakage.Reason)
+		assert.Equal(t, item.CostPerUnit, breakage.UnitCost)
+		assert.False(t, breakage.AdjustedAt.IsZero())
+		assert.Equal(t, 17.0, stockOf())
+
+		correction := &models.StockAdjustment{AccountID: account.ID, InventoryItemID: item.ID, Quantity: 1.5, Reason: models.AdjustmentReasonCountCorrection, AdjustedBy: &user.ID}
+		require.NoError(t, service.RecordStockAdjustment(correction))
+		assert.Equal(t, 18.5, stockOf())
+	})
+
+	t.Run("Adjustments Before The Snapshot Are Ignored", func(t *testing.T) {
+		old := &models.StockAdjustment{AccountID: account.ID, InventoryItemID: item.ID, Quantity: -5, Reason: models.AdjustmentReasonInternalUse, AdjustedAt: time.Now().Add(-48 * time.Hour)}
+		require.NoError(t, service.RecordStockAdjustment(old))
+		assert.Equal(t, 18.5, stockOf())
+	})
+
+	t.Run("List Adjustments As An Audit Trail", func(t *testing.T) {
+		adjustments, err := service.GetStockAdjustments(account.ID, time.Now().Add(-time.Hour), time.Now())
+		require.NoError(t, err)
+		require.Len(t, adjustments, 2)
+		assert.Equal(t, models.AdjustmentReasonCountCorrection, adjustments[0].Reason, "newest first")
+		require.NotNil(t, adjustments[0].AdjustedBy)
+		assert.Equal(t, user.ID, *adjustments[0].AdjustedBy)
+
+		_, err = service.GetStockAdjustments(account.ID, time.Now(), time.Now().Add(-time.Hour))
+		assert.Error(t, err)
+
+		fetched, err := service.GetStockAdjustment(adjustments[1].ID)
+		require.NoError(t, err)
+		assert.Equal(t, -3.0, fetched.Quantity)
+	})
+}
+
+func TestInventoryItemLedger(t *testing.T) {
+	db, cleanup := SetupTestDBLegacy(t)
+	defer cleanup()
+
+	service := NewService(db)
+
+	org := createTestOrganizationLegacy(t, service, "Ledger Test Corp")
+	account := createTestAccountLegacy(t, service, org.ID, "Ledger Cafe")
+	oatMilk := createTestInventoryItemLegacy(t, service, account.ID, "Oat Milk")
+	menuItem := createTestMenuItemLegacy(t, service, account.ID, "Oat Latte")
+	require.NoError(t, db.Create(&models.RecipeIngredient{MenuItemID: menuItem.ID, InventoryItemID: oatMilk.ID, Quantity: 0.5}).Error)
+
+	now := time.Now()
+	olderCount := &models.InventorySnapshot{AccountID: account.ID, Timestamp: now.Add(-72 * time.Hour), Counts: models.CountsMap{oatMilk.ID: 10}}
+	require.NoError(t, db.Create(olderCount).Error) // bypass the repository so the counts can be backdated
+	latestCount := &models.InventorySnapshot{AccountID: account.ID, Timestamp: now.Add(-48 * time.Hour), Counts: models.CountsMap{oatMilk.ID: 4}}
+	require.NoError(t, db.Create(latestCount).Error)
+
+	require.NoError(t, service.CreateDelivery(&models.Delivery{AccountID: account.ID, InventoryItemID: oatMilk.ID, Vendor: "Test Vendor", Quantity: 2, DeliveryDate: now.Add(-36 * time.Hour)}))
+	sale := &models.Sale{AccountID: account.ID, SaleDate: now.Add(-24 * time.Hour)}
+	require.NoError(t, db.Create(sale).Error)
+	require.NoError(t, db.Create(&models.SaleItem{SaleID: sale.ID, MenuItemID: uint(menuItem.ID), Quantity: 14}).Error)
+	require.NoError(t, service.RebuildInventoryLevels(account.ID)) // levels do not see rows written outside the service
+	require.NoError(t, service.RecordStockAdjustment(&models.StockAdjustment{AccountID: account.ID, InventoryItemID: oatMilk.ID, Quantity: -2, Reason: models.AdjustmentReasonBreakage, AdjustedAt: now.Add(-12 * time.Hour)}))
+
+	t.Run("Ledger Explains Current Stock", func(t *testing.T) {
+		ledger, err := service.GetInventoryItemLedger(oatMilk.ID, time.Time{})
+		require.NoError(t, err)
+		assert.True(t, ledger.StartsAt.Equal(latestCount.Timestamp), "the ledger starts at the latest count by default")
+
+		types := make([]string, len(ledger.Movements))
+		for i, movement := range ledger.Movements {
+			types[i] = movement.Type
+		}
+		assert.Equal(t, []string{LedgerMovementSnapshot, LedgerMovementDelivery, LedgerMovementSale, LedgerMovementAdjustment}, types)
+		assert.Equal(t, 4.0, ledger.Movements[0].Balance)
+		assert.Equal(t, 6.0, ledger.Movements[1].Balance)
+		assert.Equal(t, -7.0, ledger.Movements[2].Quantity)
+		assert.Equal(t, "Sold 14 x Oat Latte", ledger.Movements[2].Description)
+		assert.Equal(t, -1.0, ledger.Movements[2].Balance)
+		assert.Equal(t, -3.0, ledger.Balance)
+
+		items, err := service.GetInventoryItemsWithCurrentStock(account.ID)
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		assert.Equal(t, items[0].CurrentStock, ledger.Balance)
+	})
+
+	t.Run("Since Starts At The Preceding Count", func(t *testing.T) {
+		ledger, err := service.GetInventoryItemLedger(oatMilk.ID, now.Add(-60*time.Hour))
+		require.NoError(t, err)
+		assert.True(t, ledger.StartsAt.Equal(olderCount.Timestamp))
+		require.Len(t, ledger.Movements, 5)
+
+		// The later count resets the balance and shows the difference it made
+		recount := ledger.Movements[1]
+		assert.Equal(t, LedgerMovementSnapshot, recount.Type)
+		assert.Equal(t, -6.0, recount.Quantity)
+		assert.Equal(t, 4.0, recount.Balance)
+		assert.Equal(t, -3.0, ledger.Balance)
+	})
+
+	t.Run("Without A Preceding Count The Ledger Starts From Zero", func(t *testing.T) {
+		ledger, err := service.GetInventoryItemLedger(oatMilk.ID, now.Add(-100*time.Hour))
+		require.NoError(t, err)
+		assert.True(t, ledger.StartsAt.IsZero())
+		require.Len(t, ledger.Movements, 5)
+		assert.Equal(t, 10.0, ledger.Movements[0].Quantity)
+		assert.Equal(t, -3.0, ledger.Balance)
+	})
+
+	t.Run("Unknown Item", func(t *testing.T) {
+		_, err := service.GetInventoryItemLedger(99999, time.Time{})
+		assert.Error(t, err)
+	})
+}
+
+func TestInventoryLevelOperations(t *testing.T) {
+	db, cleanup := SetupTestDBLegacy(t)
+	defer cleanup()
+
+	service := NewService(db)
+
+	org := createTestOrganizationLegacy(t, service, "Level Test Corp")
+	account := createTestAccountLegacy(t, service, org.ID, "Level Cafe")
+	milk := createTestInventoryItemLegacy(t, service, account.ID, "Milk")
+	beans := createTestInventoryItemLegacy(t, service, account.ID, "Coffee Beans")
+	latte := createTestMenuItemLegacy(t, service, account.ID, "Latte")
+	require.NoError(t, db.Create(&models.RecipeIngredient{MenuItemID: latte.ID, InventoryItemID: milk.ID, Quantity: 0.25}).Error)
+	require.NoError(t, db.Create(&models.RecipeIngredient{MenuItemID: latte.ID, InventoryItemID: beans.ID, Quantity: 0.02}).Error)
+
+	stockOf := func(itemID int) float64 {
+		items, err := service.GetInventoryItemsWithCurrentStock(account.ID)
+		require.NoError(t, err)
+		for _, item := range items {
+			if item.ID == itemID {
+				return item.CurrentStock
+			}
+		}
+		t.Fatalf("item %d not found", itemID)
+		return 0
+	}
+
+	t.Run("Movements Update Levels", func(t *testing.T) {
+		require.NoError(t, service.CreateDelivery(&models.Delivery{AccountID: account.ID, InventoryItemID: milk.ID, Vendor: "Test Vendor", Quantity: 20, DeliveryDate: time.Now()}))
+		require.NoError(t, service.CreateDelivery(&models.Delivery{AccountID: account.ID, InventoryItemID: beans.ID, Vendor: "Test Vendor", Quantity: 5, DeliveryDate: time.Now()}))
+		assert.Equal(t, 20.0, stockOf(milk.ID))
+
+		require.NoError(t, service.LogWaste(&models.WasteLog{AccountID: account.ID, InventoryItemID: milk.ID, Quantity: 1, Reason: models.WasteReasonSpilled}))
+		assert.Equal(t, 19.0, stockOf(milk.ID))
+	})
+
+	t.Run("Record Sale", func(t *testing.T) {
+		assert.Error(t, service.RecordSale(&models.Sale{AccountID: account.ID}), "a sale needs items")
+		assert.Error(t, service.RecordSale(&models.Sale{AccountID: account.ID, Items: []models.SaleItem{{MenuItemID: uint(latte.ID), Quantity: 0}}}))
+
+		sale := &models.Sale{AccountID: account.ID, Items: []models.SaleItem{{MenuItemID: uint(latte.ID), Quantity: 8}, {MenuItemID: uint(latte.ID), Quantity: 4}}}
+		require.NoError(t, service.RecordSale(sale))
+		assert.False(t, sale.SaleDate.IsZero())
+		assert.Equal(t, 16.0, stockOf(milk.ID))
+		assert.InDelta(t, 4.76, stockOf(beans.ID), 1e-9)
+	})
+
+	t.Run("Rebuild Matches Maintained Levels", func(t *testing.T) {
+		before := map[int]float64{milk.ID: stockOf(milk.ID), beans.ID: stockOf(beans.ID)}
+
+		require.NoError(t, db.Model(&models.InventoryLevel{}).Where("account_id = ?", account.ID).Update("quantity", 0).Error)
+		assert.Equal(t, 0.0, stockOf(milk.ID))
+
+		rebuilt, err := service.RebuildAllInventoryLevels()
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, rebuilt, 1)
+		assert.Equal(t, before[milk.ID], stockOf(milk.ID))
+		assert.InDelta(t, before[beans.ID], stockOf(beans.ID), 1e-9)
+	})
+
+	t.Run("Snapshots Reset Levels", func(t *testing.T) {
+		require.NoError(t, service.CreateInventorySnapshot(&models.InventorySnapshot{AccountID: account.ID, Counts: models.CountsMap{milk.ID: 12}}))
+		assert.Equal(t, 12.0, stockOf(milk.ID))
+		assert.Equal(t, 0.0, stockOf(beans.ID), "items missing from the count have no stock")
+	})
+
+	t.Run("Add Upserts Levels", func(t *testing.T) {
+		sugar := createTestInventoryItemLegacy(t, service, account.ID, "Sugar")
+		levels := NewInventoryLevelRepository(db)
+
+		// The first movement inserts the level and the next one adds to it
+		require.NoError(t, levels.Add(account.ID, sugar.ID, 3))
+		require.NoError(t, levels.Add(account.ID, sugar.ID, -1.5))
+
+		level, err := levels.GetByItemID(sugar.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1.5, level.Quantity)
+		assert.Equal(t, account.ID, level.AccountID)
+	})
+}
+
+func TestListQueries(t *testing.T) {
+	db, cleanup := SetupTestDBLegacy(t)
+	defer cleanup()
+
+	service := NewService(db)
+
+	account := createTestStandaloneAccountLegacy(t, service, "List Cafe")
+	otherAccount := createTestStandaloneAccountLegacy(t, service, "Other Cafe")
+	dairy := &models.Category{AccountID: account.ID, Name: "Dairy"}
+	require.NoError(t, service.CreateCategory(dairy))
+	require.NoError(t, service.CreateCategory(&models.Category{AccountID: account.ID, Name: "Dry Goods"}))
+
+	for i, name := range []string{"Whole Milk", "Oat Milk", "Butter", "Flour", "Sugar"} {
+		item := &models.InventoryItem{AccountID: account.ID, Name: name, Unit: "kg", CostPerUnit: float64(5 - i)}
+		if i < 3 {
+			item.CategoryID = &dairy.ID
+		}
+		require.NoError(t, service.CreateInventoryItem(item))
+	}
+	createTestInventoryItemLegacy(t, service, otherAccount.ID, "Skim Milk")
+
+	t.Run("Pages Are Counted And Stable", func(t *testing.T) {
+		items, page, err := service.ListInventoryItemsWithCurrentStock(account.ID, ListQuery{Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+		assert.Equal(t, []string{"Butter", "Flour"}, []string{items[0].Name, items[1].Name}, "items are sorted by name by default")
+		assert.Equal(t, Page{Limit: 2, Offset: 0, Total: 5, HasMore: true}, page)
+
+		items, page, err = service.ListInventoryItemsWithCurrentStock(account.ID, ListQuery{Limit: 2, Offset: 4})
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		assert.Equal(t, "Whole Milk", items[0].Name)
+		assert.False(t, page.HasMore)
+
+		_, page, err = service.ListInventoryItemsWithCurrentStock(account.ID, ListQuery{Limit: MaxPageSize + 1})
+		require.NoError(t, err)
+		assert.Equal(t, MaxPageSize, page.Limit)
+	})
+
+	t.Run("Without A Limit Pages Hold The Default Size", func(t *testing.T) {
+		bulkAccount := createTestStandaloneAccountLegacy(t, service, "Bulk Cafe")
+		for i := 0; i < DefaultPageSize+5; i++ {
+			createTestInventoryItemLegacy(t, service, bulkAccount.ID, fmt.Sprintf("Item %03d", i))
+		}
+
+		items, page, err := service.ListInventoryItemsWithCurrentStock(bulkAccount.ID, ListQuery{})
+		require.NoError(t, err)
+		assert.Len(t, items, DefaultPageSize)
+		assert.Equal(t, Page{Limit: DefaultPageSize, Offset: 0, Total: DefaultPageSize + 5, HasMore: true}, page)
+
+		items, page, err = service.ListInventoryItemsWithCurrentStock(bulkAccount.ID, ListQuery{Offset: DefaultPageSize})
+		require.NoError(t, err)
+		assert.Len(t, items, 5)
+		assert.Equal(t, Page{Limit: DefaultPageSize, Offset: DefaultPageSize, Total: DefaultPageSize + 5, HasMore: false}, page)
+	})
+
+	t.Run("Filter And Sort", func(t *testing.T) {
+		items, page, err := service.ListInventoryItemsWithCurrentStock(account.ID, ListQuery{Search: "milk", CategoryID: &dairy.ID, Sort: "cost_per_unit", Desc: true})
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+		assert.Equal(t, int64(2), page.Total, "items of other accounts are never listed")
+		assert.Equal(t, "Whole Milk", items[0].Name)
+		assert.Equal(t, "Oat Milk", items[1].Name)
+
+		categories, _, err := service.ListCategories(account.ID, ListQuery{Search: "dry"})
+		require.NoError(t, err)
+		require.Len(t, categories, 1)
+		assert.Equal(t, "Dry Goods", categories[0].Name)
+	})
+
+	t.Run("Unknown Sort Falls Back To The Default Order", func(t *testing.T) {
+		snapshotTimes := []time.Time{time.Now().Add(-72 * time.Hour), time.Now().Add(-24 * time.Hour), time.Now().Add(-48 * time.Hour)}
+		for _, timestamp := range snapshotTimes {
+			require.NoError(t, db.Create(&models.InventorySnapshot{AccountID: account.ID, Timestamp: timestamp, Counts: models.CountsMap{}}).Error)
+		}
+
+		snapshots, page, err := service.ListInventorySnapshots(account.ID, ListQuery{Sort: "counts", StartDate: time.Now().Add(-60 * time.Hour)})
+		require.NoError(t, err)
+		require.Len(t, snapshots, 2)
+		assert.Equal(t, int64(2), page.Total)
+		assert.True(t, snapshots[0].Timestamp.After(snapshots[1].Timestamp), "snapshots are newest first by default")
+	})
+
+	t.Run("Wildcards In Searches Match Themselves", func(t *testing.T) {
+		bakery := createTestStandaloneAccountLegacy(t, service, "Wildcard Bakery")
+		for _, name := range []string{"50% Cocoa", "500g Cocoa", "Flour a_b", "Flour axb", `Back\slash`} {
+			createTestInventoryItemLegacy(t, service, bakery.ID, name)
+		}
+
+		for search, want := range map[string]string{"50%": "50% Cocoa", "a_b": "Flour a_b", `k\s`: `Back\slash`} {
+			items, _, err := service.ListInventoryItemsWithCurrentStock(bakery.ID, ListQuery{Search: search})
+			require.NoError(t, err)
+			require.Len(t, items, 1, search)
+			assert.Equal(t, want, items[0].Name)
+		}
+	})
+}
+
+func TestSearch(t *testing.T) {
+	db, cleanup := SetupTestDBLegacy(t)
+	defer cleanup()
+
+	service := NewService(db)
+
+	account := createTestStandaloneAccountLegacy(t, service, "Search Cafe")
+	otherAccount := createTestStandaloneAccountLegacy(t, service, "Other Cafe")
+	user := createTestUserLegacy(t, service, account.ID, "searcher@example.com", "user")
+
+	oatMilk := createTestInventoryItemLegacy(t, service, account.ID, "Oat Milk")
+	milk := createTestInventoryItemLegacy(t, service, account.ID, "Milk")
+	createTestInventoryItemLegacy(t, service, account.ID, "Buttermilk")
+	createTestInventoryItemLegacy(t, service, otherAccount.ID, "Milk")
+	require.NoError(t, service.CreateMenuItem(&models.MenuItem{AccountID: account.ID, Name: "Milk Tea", Price: 4}))
+	require.NoError(t, service.CreateCategory(&models.Category{AccountID: account.ID, Name: "Dairy", Description: "Milk, cream and cheese"}))
+	require.NoError(t, service.CreateVendor(&models.Vendor{AccountID: account.ID, Name: "Local Dairy", Notes: "Raw milk on Fridays"}))
+	require.NoError(t, service.CreateOrder(&models.Order{AccountID: account.ID, CreatedBy: user.ID, Notes: "Rush the oat milk"}, []models.OrderItem{{InventoryItemID: oatMilk.ID, Quantity: 5, UnitCost: 2}}))
+	require.NoError(t, service.CreateDelivery(&models.Delivery{AccountID: account.ID, InventoryItemID: milk.ID, Vendor: "Local Dairy", Quantity: 10, DeliveryDate: time.Now(), Notes: "Two crates of milk arrived warm"}))
+
+	t.Run("Ranks Matches Across Kinds", func(t *testing.T) {
+		results, err := service.Search(account.ID, "milk", 0)
+		require.NoError(t, err)
+		require.NotEmpty(t, results)
+
+		assert.Equal(t, SearchResultInventoryItem, results[0].Type)
+		assert.Equal(t, milk.ID, results[0].ID, "an exact name match ranks first")
+		assert.Equal(t, 1.0, results[0].Score)
+		assert.Equal(t, "Milk Tea", results[1].Title, "names starting with the query rank next")
+
+		types := make(map[string]int)
+		for i, result := range results {
+			types[result.Type]++
+			if i > 0 {
+				assert.GreaterOrEqual(t, results[i-1].Score, result.Score)
+			}
+		}
+		assert.Equal(t, 3, types[SearchResultInventoryItem], "items of other accounts are never found")
+		assert.Equal(t, 1, types[SearchResultMenuItem])
+		assert.Equal(t, 1, types[SearchResultCategory])
+		assert.Equal(t, 1, types[SearchResultVendor])
+		assert.Equal(t, 1, types[SearchResultOrder])
+		assert.Equal(t, 1, types[SearchResultDelivery])
+	})
+
+	t.Run("Every Term Must Match", func(t *testing.T) {
+		results, err := service.Search(account.ID, "  OAT, milk ", 0)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, "Oat Milk", results[0].Title)
+		assert.Equal(t, SearchResultOrder, results[1].Type)
+		assert.Equal(t, "Rush the oat milk", results[1].Snippet)
+	})
+
+	t.Run("Terms Match Across Fields", func(t *testing.T) {
+		wholeMilk := &models.InventoryItem{AccountID: account.ID, Name: "Whole Milk", Unit: "liters", PreferredVendor: "Local Dairy"}
+		require.NoError(t, service.CreateInventoryItem(wholeMilk))
+
+		results, err := service.Search(account.ID, "milk dairy", 0)
+		require.NoError(t, err)
+		var found *SearchResult
+		for i := range results {
+			if results[i].Type == SearchResultInventoryItem && results[i].ID == wholeMilk.ID {
+				found = &results[i]
+			}
+		}
+		require.NotNil(t, found, "the name and the vendor together hold every term")
+		assert.InDelta(t, 0.6*(1+0.5)/2, found.Score, 0.0001, "each term scores at the weight of the field it is in")
+		assert.Empty(t, found.Snippet, "the name matched best")
+	})
+
+	t.Run("Limit And Invalid Queries", func(t *testing.T) {
+		results, err := service.Search(account.ID, "milk", 2)
+		require.NoError(t, err)
+		assert.Len(t, results, 2)
+
+		_, err = service.Search(account.ID, " -- ", 0)
+		assert.ErrorIs(t, err, ErrInvalidSearchQuery)
+	})
+}
+
+func TestInventoryImport(t *testing.T) {
+	db, cleanup := SetupTestDBLegacy(t)
+	defer cleanup()
+
+	service := NewService(db)
+
+	account := createTestStandaloneAccountLegacy(t, service, "Import Cafe")
+	dairy := &models.Category{AccountID: account.ID, Name: "Dairy"}
+	require.NoError(t, service.CreateCategory(dairy))
+	milk := &models.InventoryItem{AccountID: account.ID, Name: "Milk", Unit: "liters", CostPerUnit: 2, MinStockLevel: 5}
+	require.NoError(t, service.CreateInventoryItem(milk))
+
+	records := [][]string{
+		{"Item Name", "Unit", "Cost Per Unit", "Category", "Supplier"},
+		{"Milk", "", "2.25", "dairy", ""},
+		{"Flour", "kg", "0.80", "", "Mill Co"},
+		{"", "", "", "", ""},
+	}
+	mapping := map[string]string{"name": "item name", "preferred_vendor": "Supplier"}
+
+	t.Run("Dry Run Changes Nothing", func(t *testing.T) {
+		report, err := service.ImportInventoryItems(account.ID, records, mapping, true)
+		require.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.False(t, report.Committed)
+		assert.Empty(t, report.Errors)
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, 1, report.Updated)
+		require.Len(t, report.Rows, 2)
+		assert.Equal(t, InventoryImportRow{Row: 2, Name: "Milk", Action: InventoryImportUpdate, InventoryItemID: milk.ID}, report.Rows[0])
+		assert.Equal(t, InventoryImportRow{Row: 3, Name: "Flour", Action: InventoryImportCreate}, report.Rows[1])
+		assert.Equal(t, "Cost Per Unit", report.Columns["cost_per_unit"])
+		assert.Equal(t, "Supplier", report.Columns["preferred_vendor"])
+
+		items, err := service.GetInventoryItemsByAccount(account.ID)
+		require.NoError(t, err)
+		assert.Len(t, items, 1)
+		unchanged, err := service.GetInventoryItem(milk.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2.0, unchanged.CostPerUnit)
+	})
+
+	t.Run("Invalid Rows Roll Back Everything", func(t *testing.T) {
+		invalid := append([][]string{}, records...)
+		invalid = append(invalid,
+			[]string{"Sugar", "", "1", "", ""},
+			[]string{"Butter", "kg", "-3", "Frozen", ""},
+			[]string{"Flour", "kg", "0.90", "", ""},
+		)
+		report, err := service.ImportInventoryItems(account.ID, invalid, mapping, false)
+		require.NoError(t, err)
+		assert.False(t, report.Committed)
+		assert.Equal(t, []InventoryImportError{
+			{Row: 5, Message: "unit is required"},
+			{Row: 6, Field: "cost_per_unit", Message: "cost_per_unit must be a non-negative number"},
+			{Row: 6, Field: "category", Message: `category "Frozen" does not exist in this account`},
+			{Row: 7, Field: "name", Message: `item "Flour" already appears on row 3`},
+		}, report.Errors)
+
+		items, err := service.GetInventoryItemsByAccount(account.ID)
+		require.NoError(t, err)
+		assert.Len(t, items, 1)
+	})
+
+	t.Run("Invalid Mapping", func(t *testing.T) {
+		_, err := service.ImportInventoryItems(account.ID, records, map[string]string{"price": "Cost Per Unit"}, false)
+		assert.ErrorContains(t, err, "unknown field")
+		_, err = service.ImportInventoryItems(account.ID, records, map[string]string{"name": "Product"}, false)
+		assert.ErrorContains(t, err, "not in the file")
+		_, err = service.ImportInventoryItems(account.ID, records[:1], nil, false)
+		assert.ErrorContains(t, err, "no name column")
+	})
+
+	t.Run("Commit Upserts By Name", func(t *testing.T) {
+		report, err := service.ImportInventoryItems(account.ID, records, mapping, false)
+		require.NoError(t, err)
+		assert.True(t, report.Committed)
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, 1, report.Updated)
+
+		updated, err := service.GetInventoryItem(milk.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2.25, updated.CostPerUnit)
+		assert.Equal(t, "liters", updated.Unit, "empty cells keep the existing value")
+		assert.Equal(t, 5.0, updated.MinStockLevel)
+		require.NotNil(t, updated.CategoryID)
+		assert.Equal(t, dairy.ID, *updated.CategoryID)
+
+		flour, err := service.GetInventoryItem(report.Rows[1].InventoryItemID)
+		require.NoError(t, err)
+		assert.Equal(t, "Flour", flour.Name)
+		assert.Equal(t, 0.8, flour.CostPerUnit)
+		assert.Equal(t, "Mill Co", flour.PreferredVendor)
+		assert.NotNil(t, flour.VendorID, "the vendor is linked like a created item's")
+	})
+}
+
+func TestExport(t *testing.T) {
+	db, cleanup := SetupTestDBLegacy(t)
+	defer cleanup()
+
+	service := NewService(db)
+
+	account := createTestStandaloneAccountLegacy(t, service, "Export Cafe")
+	dairy := &models.Category{AccountID: account.ID, Name: "Dairy"}
+	require.NoError(t, service.CreateCategory(dairy))
+	milk := &models.InventoryItem{AccountID: account.ID, Name: "Milk", Unit: "liters", CostPerUnit: 2.5, CategoryID: &dairy.ID}
+	require.NoError(t, service.CreateInventoryItem(milk))
+	beans := createTestInventoryItemLegacy(t, service, account.ID, "Beans")
+	latte := &models.MenuItem{AccountID: account.ID, Name: "Latte", Price: 4.5}
+	require.NoError(t, service.CreateMenuItem(latte))
+
+	march := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	for day := 0; day < 3; day++ {
+		require.NoError(t, service.CreateDelivery(&models.Delivery{AccountID: account.ID, InventoryItemID: milk.ID, Vendor: "Local Dairy", Quantity: 10, Cost: 25, DeliveryDate: march.AddDate(0, 0, day)}))
+	}
+	require.NoError(t, service.CreateInventorySnapshot(&models.InventorySnapshot{AccountID: account.ID, Timestamp: march.AddDate(0, 0, 3), Counts: models.CountsMap{milk.ID: 24, beans.ID: 3}}))
+	require.NoError(t, service.RecordSale(&models.Sale{AccountID: account.ID, SaleDate: march.AddDate(0, 0, 4), Items: []models.SaleItem{{MenuItemID: uint(latte.ID), Quantity: 2, PriceAtSale: 4.5, CostAtSale: 1}}}))
+
+	export := func(dataset string, startDate, endDate time.Time) [][]interface{} {
+		var rows [][]interface{}
+		require.NoError(t, service.Export(account.ID, dataset, startDate, endDate, func(row []interface{}) error {
+			columns, err := ExportColumns(dataset)
+			require.NoError(t, err)
+			require.Len(t, row, len(columns))
+			rows = append(rows, row)
+			return nil
+		}))
+		return rows
+	}
+
+	t.Run("Deliveries In Range", func(t *testing.T) {
+		rows := export(ExportDeliveries, march.AddDate(0, 0, 1), time.Time{})
+		require.Len(t, rows, 2)
+		assert.Equal(t, march.AddDate(0, 0, 1), rows[0][1].(time.Time).UTC(), "oldest first")
+		assert.Equal(t, "Milk", rows[0][3])
+		assert.Equal(t, "Local Dairy", rows[0][5])
+	})
+
+	t.Run("Snapshots Expand Counts", func(t *testing.T) {
+		rows := export(ExportSnapshots, time.Time{}, time.Time{})
+		require.Len(t, rows, 2)
+		assert.Equal(t, []interface{}{beans.ID, "Beans"}, rows[0][2:4])
+		assert.Equal(t, []interface{}{milk.ID, "Milk", "liters", 24.0}, rows[1][2:])
+	})
+
+	t.Run("Valuation", func(t *testing.T) {
+		rows := export(ExportValuation, time.Time{}, time.Time{})
+		require.Len(t, rows, 2)
+		assert.Equal(t, []interface{}{milk.ID, "Milk", "Dairy", "liters", 24.0, 2.5, 60.0}, rows[1])
+		assert.Nil(t, rows[0][2], "items without a category have an empty category cell")
+	})
+
+	t.Run("Sales", func(t *testing.T) {
+		rows := export(ExportSales, time.Time{}, march.AddDate(0, 0, 3))
+		assert.Empty(t, rows)
+
+		rows = export(ExportSales, time.Time{}, time.Time{})
+		require.Len(t, rows, 1)
+		assert.Equal(t, []interface{}{uint(latte.ID), "Latte", 2, 4.5, 1.0, 9.0, 2.0, 7.0}, rows[0][2:10])
+	})
+
+	t.Run("Unknown Dataset", func(t *testing.T) {
+		err := service.Export(account.ID, "payroll", time.Time{}, time.Time{}, func([]interface{}) error { return nil })
+		assert.ErrorIs(t, err, ErrUnknownExportDataset)
+	})
+
+	t.Run("Write Error Stops Export", func(t *testing.T) {
+		calls := 0
+		err := service.Export(account.ID, ExportDeliveries, time.Time{}, time.Time{}, func([]interface{}) error {
+			calls++
+			return errors.New("client went away")
+		})
+		assert.EqualError(t, err, "client went away")
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestAccountBackup(t *testing.T) {
+	db, cleanup := SetupTestDBLegacy(t)
+	defer cleanup()
+
+	service := NewService(db)
+
+	source := createTestStandaloneAccountLegacy(t, service, "Template Cafe")
+	source.CostingMethod = models.CostingMethodFIFO
+	require.NoError(t, service.UpdateAccount(source))
+	user := createTestUserLegacy(t, service, source.ID, "owner@example.com", "admin")
+
+	dairy := &models.Category{AccountID: source.ID, Name: "Dairy", IsActive: true}
+	require.NoError(t, service.CreateCategory(dairy))
+	retired := &models.Category{AccountID: source.ID, Name: "Retired"}
+	require.NoError(t, service.CreateCategory(retired))
+	require.NoError(t, db.Model(retired).Update("is_active", false).Error)
+	walkIn := &models.StorageLocation{AccountID: source.ID, Name: "Walk-in", IsDefault: true}
+	require.NoError(t, service.CreateStorageLocation(walkIn))
+	bar := &models.StorageLocation{AccountID: source.ID, Name: "Bar"}
+	require.NoError(t, service.CreateStorageLocation(bar))
+
+	milk := &models.InventoryItem{AccountID: source.ID, Name: "Milk", Unit: "liters", CostPerUnit: 2, CategoryID: &dairy.ID, PreferredVendor: "Local Dairy"}
+	require.NoError(t, service.CreateInventoryItem(milk))
+	beans := createTestInventoryItemLegacy(t, service, source.ID, "Beans")
+	latte := &models.MenuItem{AccountID: source.ID, Name: "Latte", Price: 4.5, CategoryID: &dairy.ID}
+	require.NoError(t, service.CreateMenuItem(latte))
+	require.NoError(t, db.Create(&models.RecipeIngredient{MenuItemID: latte.ID, InventoryItemID: milk.ID, Quantity: 0.25}).Error)
+
+	start := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, service.CreateInventorySnapshot(&models.InventorySnapshot{
+		AccountID:      source.ID,
+		Timestamp:      start,
+		Counts:         models.CountsMap{milk.ID: 10, beans.ID: 4},
+		LocationCounts: models.LocationCountsMap{walkIn.ID: {milk.ID: 8, beans.ID: 4}, bar.ID: {milk.ID: 2}},
+	}))
+	require.NoError(t, service.CreateDelivery(&models.Delivery{AccountID: source.ID, InventoryItemID: milk.ID, Vendor: "Local Dairy", Quantity: 12, Cost: 30, DeliveryDate: start.Add(time.Hour)}))
+	require.NoError(t, service.LogWaste(&models.WasteLog{AccountID: source.ID, InventoryItemID: milk.ID, Quantity: 1, Reason: models.WasteReasonSpilled, RecordedBy: &user.ID}))
+	require.NoError(t, service.RecordStockAdjustment(&models.StockAdjustment{AccountID: source.ID, InventoryItemID: beans.ID, Quantity: -1, Reason: models.AdjustmentReasonBreakage}))
+	weeklyOrder := &models.Order{AccountID: source.ID, CreatedBy: user.ID, Status: "ordered", Notes: "Weekly dairy"}
+	require.NoError(t, service.CreateOrder(weeklyOrder, []models.OrderItem{{InventoryItemID: milk.ID, Quantity: 20, UnitCost: 2, Vendor: "Local Dairy"}}))
+	weeklyItems, err := service.GetOrderItems(weeklyOrder.ID)
+	require.NoError(t, err)
+	receipt, err := service.ReceiveOrder(weeklyOrder, &models.Receipt{ReceivedBy: user.ID}, []ReceiptLineInput{{OrderItemID: weeklyItems[0].ID, Quantity: 15}})
+	require.NoError(t, err)
+	_, err = service.CreateInvoice(&models.Invoice{AccountID: source.ID, InvoiceNumber: "LD-1", CreatedBy: user.ID}, []models.InvoiceLine{
+		{DeliveryID: receipt.Receipt.Lines[0].DeliveryID, Quantity: 15, UnitCost: 2},
+		{Description: "Freight", Quantity: 1, UnitCost: 5},
+	})
+	require.NoError(t, err)
+	require.NoError(t, service.RecordSale(&models.Sale{AccountID: source.ID, Items: []models.SaleItem{{MenuItemID: uint(latte.ID), Quantity: 4, PriceAtSale: 4.5}}}))
+	require.NoError(t, service.CreateEmailSchedule(&models.EmailSchedule{AccountID: source.ID, EmailType: models.EmailTypeWeeklyReport, Frequency: "weekly", TimeOfDay: "09:00", IsActive: true}))
+	require.NoError(t, service.CreateBudget(&models.Budget{AccountID: source.ID, CategoryID: dairy.ID, Period: models.BudgetPeriodMonthly, Amount: 400, AlertThresholds: models.ThresholdList{90}}))
+
+	sourceStock, err := service.GetInventoryItemsWithCurrentStock(source.ID)
+	require.NoError(t, err)
+
+	var archive bytes.Buffer
+	manifest, err := service.BackupAccount(source.ID, &archive)
+	require.NoError(t, err)
+	assert.Equal(t, BackupFormatVersion, manifest.Version)
+	assert.Equal(t, 2, manifest.Records["categories"])
+	assert.Equal(t, 1, manifest.Records["recipe_ingredients"])
+	assert.Equal(t, 1, manifest.Records["order_items"])
+	assert.Equal(t, 1, manifest.Records["sales"])
+	assert.Equal(t, 1, manifest.Records["budgets"])
+	assert.Equal(t, 1, manifest.Records["invoices"])
+	assert.Equal(t, 2, manifest.Records["invoice_lines"])
+
+	t.Run("Restore Into Fresh Account", func(t *testing.T) {
+		franchise := createTestStandaloneAccountLegacy(t, service, "New Franchise")
+		franchiseUser := createTestUserLegacy(t, service, franchise.ID, "franchisee@example.com", "admin")
+
+		report, err := service.RestoreAccount(franchise.ID, franchiseUser.ID, bytes.NewReader(archive.Bytes()))
+		require.NoError(t, err)
+		assert.Empty(t, report.Skipped)
+		for section, count := range manifest.Records {
+			assert.Equal(t, count, report.Restored[section], section)
+		}
+
+		restoredAccount, err := service.GetAccount(franchise.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.CostingMethodFIFO, restoredAccount.CostingMethod)
+
+		items, err := service.GetInventoryItemsWithCurrentStock(franchise.ID)
+		require.NoError(t, err)
+		require.Len(t, items, len(sourceStock))
+		itemIDs := make(map[string]int)
+		for i, item := range items {
+			itemIDs[item.Name] = item.ID
+			assert.NotEqual(t, sourceStock[i].ID, item.ID)
+			assert.Equal(t, sourceStock[i].Name, item.Name)
+			assert.InDelta(t, sourceStock[i].CurrentStock, item.CurrentStock, 0.0001, "stock is rebuilt from the restored history")
+		}
+		restoredMilk, err := service.GetInventoryItem(itemIDs["Milk"])
+		require.NoError(t, err)
+		require.NotNil(t, restoredMilk.CategoryID)
+		require.NotNil(t, restoredMilk.VendorID)
+		assert.NotEqual(t, dairy.ID, *restoredMilk.CategoryID)
+
+		categories, err := service.GetCategoriesByAccount(franchise.ID)
+		require.NoError(t, err)
+		require.Len(t, categories, 2)
+		for _, category := range categories {
+			assert.Equal(t, category.Name == "Dairy", category.IsActive, "inactive categories stay inactive")
+		}
+
+		budgets, err := service.GetBudgetsByAccount(franchise.ID)
+		require.NoError(t, err)
+		require.Len(t, budgets, 1)
+		assert.Equal(t, *restoredMilk.CategoryID, budgets[0].CategoryID)
+		assert.Equal(t, models.ThresholdList{90}, budgets[0].AlertThresholds)
+
+		snapshot, err := service.GetLatestInventorySnapshot(franchise.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.CountsMap{itemIDs["Milk"]: 10, itemIDs["Beans"]: 4}, snapshot.Counts)
+		locations, err := service.GetStorageLocationsByAccount(franchise.ID)
+		require.NoError(t, err)
+		require.Len(t, locations, 2)
+		for _, location := range locations {
+			assert.Contains(t, snapshot.LocationCounts, location.ID)
+		}
+
+		orders, err := service.GetOrdersByAccount(franchise.ID)
+		require.NoError(t, err)
+		require.Len(t, orders, 1)
+		assert.Equal(t, franchiseUser.ID, orders[0].CreatedBy)
+		assert.Equal(t, "partially_received", orders[0].Status)
+
+		// The received delivery is linked to the restored order item
+		orderItems, err := service.GetOrderItems(orders[0].ID)
+		require.NoError(t, err)
+		require.Len(t, orderItems, 1)
+		assert.Equal(t, 15.0, orderItems[0].ReceivedQuantity)
+		deliveries, err := service.GetDeliveriesByAccount(franchise.ID)
+		require.NoError(t, err)
+		received := 0
+		for _, delivery := range deliveries {
+			if delivery.OrderItemID != nil {
+				assert.Equal(t, orderItems[0].ID, *delivery.OrderItemID)
+				received++
+			}
+		}
+		assert.Equal(t, 1, received)
+
+		// The invoice bills the restored order, order item and delivery
+		invoices, err := service.GetInvoicesByAccount(franchise.ID, "")
+		require.NoError(t, err)
+		require.Len(t, invoices, 1)
+		assert.Equal(t, "LD-1", invoices[0].InvoiceNumber)
+		assert.Equal(t, models.InvoiceStatusNeedsReview, invoices[0].Status)
+		assert.Equal(t, franchiseUser.ID, invoices[0].CreatedBy)
+		require.NotNil(t, invoices[0].OrderID)
+		assert.Equal(t, orders[0].ID, *invoices[0].OrderID)
+		require.NotNil(t, invoices[0].VendorID)
+		assert.Equal(t, *restoredMilk.VendorID, *invoices[0].VendorID)
+		invoice, err := service.GetInvoice(invoices[0].ID)
+		require.NoError(t, err)
+		require.Len(t, invoice.Lines, 2)
+		require.NotNil(t, invoice.Lines[0].OrderItemID)
+		assert.Equal(t, orderItems[0].ID, *invoice.Lines[0].OrderItemID)
+		require.NotNil(t, invoice.Lines[0].DeliveryID)
+		restoredDelivery, err := service.GetDelivery(*invoice.Lines[0].DeliveryID)
+		require.NoError(t, err)
+		assert.Equal(t, franchise.ID, restoredDelivery.AccountID)
+		assert.Nil(t, invoice.Lines[1].OrderItemID)
+	})
+
+	t.Run("Account Must Be Empty", func(t *testing.T) {
+		_, err := service.RestoreAccount(source.ID, 0, bytes.NewReader(archive.Bytes()))
+		assert.ErrorIs(t, err, ErrAccountNotEmpty)
+	})
+
+	t.Run("Invalid Archives", func(t *testing.T) {
+		empty := createTestStandaloneAccountLegacy(t, service, "Empty Cafe")
+
+		_, err := service.RestoreAccount(empty.ID, 0, strings.NewReader("not an archive"))
+		assert.ErrorIs(t, err, ErrInvalidBackup)
+
+		truncated := archive.Bytes()[:archive.Len()/2]
+		_, err = service.RestoreAccount(empty.ID, 0, bytes.NewReader(truncated))
+		assert.Error(t, err)
+
+		var future bytes.Buffer
+		compressed := gzip.NewWriter(&future)
+		tarball := tar.NewWriter(compressed)
+		manifestJSON := []byte(fmt.Sprintf(`{"format": %q, "version": %d}`, BackupFormat, BackupFormatVersion+1))
+		require.NoError(t, tarball.WriteHeader(&tar.Header{Name: "manifest.json", Mode: 0o644, Size: int64(len(manifestJSON))}))
+		_, err = tarball.Write(manifestJSON)
+		require.NoError(t, err)
+		require.NoError(t, tarball.Close())
+		require.NoError(t, compressed.Close())
+		_, err = service.RestoreAccount(empty.ID, 0, &future)
+		assert.ErrorIs(t, err, ErrInvalidBackup)
+
+		items, err := service.GetInventoryItemsByAccount(empty.ID)
+		require.NoError(t, err)
+		assert.Empty(t, items, "failed restores change nothing")
+	})
+}
+
+func TestInventoryValuation(t *testing.T) {
+	db, cleanup := SetupTestDBLegacy(t)
+	defer cleanup()
+
+	service := NewService(db)
+
+	account := createTestStandaloneAccountLegacy(t, service, "Valuation Cafe")
+	milk := &models.InventoryItem{AccountID: account.ID, Name: "Milk", Unit: "liters", CostPerUnit: 1}
+	require.NoError(t, service.CreateInventoryItem(milk))
+	sugar := &models.InventoryItem{AccountID: account.ID, Name: "Sugar", Unit: "kg", CostPerUnit: 1.5}
+	require.NoError(t, service.CreateInventoryItem(sugar))
+
+	deliver := func(quantity, cost float64, date time.Time) {
+		require.NoError(t, service.CreateDelivery(&models.Delivery{AccountID: account.ID, InventoryItemID: milk.ID, Vendor: "Local Dairy", Quantity: quantity, Cost: cost, DeliveryDate: date}))
+	}
+	count := func(counts models.CountsMap, date time.Time) {
+		require.NoError(t, service.CreateInventorySnapshot(&models.InventorySnapshot{AccountID: account.ID, Timestamp: date, Counts: counts}))
+	}
+
+	// January closes with 10 liters bought at 2.00; February buys 20 more at 3.00
+	deliver(10, 20, time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC))
+	count(models.CountsMap{milk.ID: 10}, time.Date(2024, 1, 31, 22, 0, 0, 0, time.UTC))
+	deliver(20, 60, time.Date(2024, 2, 10, 9, 0, 0, 0, time.UTC))
+	count(models.CountsMap{milk.ID: 15, sugar.ID: 4}, time.Date(2024, 2, 29, 22, 0, 0, 0, time.UTC))
+	// Prices paid after the period do not change its valuation
+	deliver(10, 50, time.Date(2024, 3, 5, 9, 0, 0, 0, time.UTC))
+
+	periodStart := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond)
+
+	t.Run("Weighted Average", func(t *testing.T) {
+		valuation, err := service.GetInventoryValuation(account.ID, periodStart, periodEnd, ValuationMethodWeightedAverage)
+		require.NoError(t, err)
+		require.NotNil(t, valuation.OpeningSnapshotID)
+		require.Len(t, valuation.Items, 2)
+
+		line := valuation.Items[0]
+		assert.Equal(t, "Milk", line.ItemName)
+		assert.InDelta(t, 20, line.OpeningValue, 0.001)
+		assert.InDelta(t, 60, line.Purchases, 0.001)
+		assert.InDelta(t, 20, line.PurchasedQuantity, 0.001)
+		assert.InDelta(t, 80.0/30, line.ClosingUnitCost, 0.001)
+		assert.InDelta(t, 40, line.ClosingValue, 0.001)
+		assert.InDelta(t, 40, line.COGS, 0.001)
+
+		// Sugar was never delivered, so it is valued at its cost per unit
+		assert.Equal(t, "Sugar", valuation.Items[1].ItemName)
+		assert.InDelta(t, 6, valuation.Items[1].ClosingValue, 0.001)
+
+		assert.InDelta(t, 20, valuation.OpeningValue, 0.001)
+		assert.InDelta(t, 60, valuation.Purchases, 0.001)
+		assert.InDelta(t, 46, valuation.ClosingValue, 0.001)
+		assert.InDelta(t, 34, valuation.COGS, 0.001)
+	})
+
+	t.Run("FIFO", func(t *testing.T) {
+		valuation, err := service.GetInventoryValuation(account.ID, periodStart, periodEnd, ValuationMethodFIFO)
+		require.NoError(t, err)
+		line := valuation.Items[0]
+		assert.InDelta(t, 3, line.ClosingUnitCost, 0.001, "the stock on hand is the newest delivery")
+		assert.InDelta(t, 45, line.ClosingValue, 0.001)
+		assert.InDelta(t, 35, line.COGS, 0.001)
+	})
+
+	t.Run("Method Defaults From Account", func(t *testing.T) {
+		valuation, err := service.GetInventoryValuation(account.ID, periodStart, periodEnd, "")
+		require.NoError(t, err)
+		assert.Equal(t, ValuationMethodWeightedAverage, valuation.Method)
+
+		_, err = service.UpdateCostSettings(account.ID, models.CostingMethodFIFO, 0)
+		require.NoError(t, err)
+		valuation, err = service.GetInventoryValuation(account.ID, periodStart, periodEnd, "")
+		require.NoError(t, err)
+		assert.Equal(t, ValuationMethodFIFO, valuation.Method)
+	})
+
+	t.Run("No Opening Count", func(t *testing.T) {
+		valuation, err := service.GetInventoryValuation(account.ID, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 31, 23, 59, 0, 0, time.UTC), ValuationMethodWeightedAverage)
+		require.NoError(t, err)
+		assert.Nil(t, valuation.OpeningSnapshotID)
+		assert.InDelta(t, 0, valuation.OpeningValue, 0.001)
+		assert.InDelta(t, 20, valuation.Purchases, 0.001)
+		assert.InDelta(t, 0, valuation.COGS, 0.001)
+	})
+
+	t.Run("Invalid Requests", func(t *testing.T) {
+		_, err := service.GetInventoryValuation(account.ID, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC), "")
+		assert.ErrorIs(t, err, ErrNoClosingCount)
+		_, err = service.GetInventoryValuation(account.ID, periodStart, periodEnd, models.CostingMethodLast)
+		assert.Error(t, err)
+		_, err = service.GetInventoryValuation(account.ID, periodEnd, periodStart, "")
+		assert.Error(t, err)
+	})
+}
+
+func TestGetPurchaseOrders(t *testing.T) {
+	db, cleanup := SetupTestDBLegacy(t)
+	defer cleanup()
+
+	service := NewService(db)
+
+	account := createTestStandaloneAccountLegacy(t, service, "Purchase Order Cafe")
+	user := createTestUserLegacy(t, service, account.ID, "po@example.com", "manager")
+	flour := createTestInventoryItemLegacy(t, service, account.ID, "Flour")
+	milk := createTestInventoryItemLegacy(t, service, account.ID, "Milk")
+	sugar := createTestInventoryItemLegacy(t, service, account.ID, "Sugar")
+
+	order := &models.Order{AccountID: account.ID, CreatedBy: user.ID}
+	require.NoError(t, service.CreateOrder(order, []models.OrderItem{
+		{InventoryItemID: milk.ID, Quantity: 20, UnitCost: 0.9, Vendor: "Local Dairy"},
+		{InventoryItemID: flour.ID, Quantity: 10, UnitCost: 1.2, Vendor: "Mill Co", Notes: "Unbleached"},
+		{InventoryItemID: sugar.ID, Quantity: 5, UnitCost: 2, Vendor: "Mill Co"},
+	}))
+
+	purchaseOrders, err := service.GetPurchaseOrders(order.ID)
+	require.NoError(t, err)
+	require.Len(t, purchaseOrders, 2)
+
+	dairy, mill := purchaseOrders[0], purchaseOrders[1]
+	require.NotNil(t, dairy.Vendor)
+	assert.Equal(t, "Local Dairy", dairy.Vendor.Name)
+	assert.Equal(t, fmt.Sprintf("PO-%d-1", order.ID), dairy.Number)
+	assert.Equal(t, "Purchase Order Cafe", dairy.Account.Name)
+	assert.InDelta(t, 18, dairy.Total, 0.001)
+
+	assert.Equal(t, "Mill Co", mill.Vendor.Name)
+	assert.Equal(t, fmt.Sprintf("PO-%d-2", order.ID), mill.Number)
+	require.Len(t, mill.Lines, 2)
+	assert.Equal(t, "Flour", mill.Lines[0].ItemName)
+	assert.Equal(t, "Unbleached", mill.Lines[0].Notes)
+	assert.InDelta(t, 22, mill.Total, 0.001)
+
+	single := &models.Order{AccountID: account.ID, CreatedBy: user.ID}
+	require.NoError(t, service.CreateOrder(single, []models.OrderItem{{InventoryItemID: flour.ID, Quantity: 1, UnitCost: 1.2, Vendor: "Mill Co"}}))
+	purchaseOrders, err = service.GetPurchaseOrders(single.ID)
+	require.NoError(t, err)
+	require.Len(t, purchaseOrders, 1)
+	assert.Equal(t, fmt.Sprintf("PO-%d", single.ID), purchaseOrders[0].Number)
+
+	_, err = service.GetPurchaseOrders(9999)
+	assert.Error(t, err)
+}
+
+func TestRecordOrderDispatches(t *testing.T) {
+	db, cleanup := SetupTestDBLegacy(t)
+	defer cleanup()
+
+	service := NewService(db)
+
+	account := createTestStandaloneAccountLegacy(t, service, "Dispatch Cafe")
+	user := createTestUserLegacy(t, service, account.ID, "dispatch@example.com", "manager")
+	flour := createTestInventoryItemLegacy(t, service, account.ID, "Flour")
+
+	order := &models.Order{AccountID: account.ID, CreatedBy: user.ID, Status: "approved"}
+	require.NoError(t, service.CreateOrder(order, []models.OrderItem{{InventoryItemID: flour.ID, Quantity: 10, UnitCost: 1.2, Vendor: "Mill Co"}}))
+
+	// Orders are placed by sending them, not by setting their status
+	unsent := *order
+	unsent.Status = "ordered"
+	assert.ErrorIs(t, service.UpdateOrder(&unsent), ErrOrderNotSent)
+
+	// A failed send is recorded without placing the order
+	failed := []models.OrderDispatch{{Vendor: "Mill Co", Number: "PO-1", Status: models.EmailStatusFailed, ErrorMsg: "Vendor Mill Co has no email address.", SentBy: user.ID}}
+	require.NoError(t, service.RecordOrderDispatches(order, failed))
+	assert.Equal(t, "approved", order.Status)
+	assert.Nil(t, order.DispatchedAt)
+
+	sent := []models.OrderDispatch{{Vendor: "Mill Co", Number: "PO-1", ToEmail: "orders@mill.example.com", Status: models.EmailStatusSent, SentBy: user.ID}}
+	require.NoError(t, service.RecordOrderDispatches(order, sent))
+
+	stored, err := service.GetOrder(order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "ordered", stored.Status)
+	require.NotNil(t, stored.DispatchedAt)
+
+	dispatches, err := service.GetOrderDispatches(order.ID)
+	require.NoError(t, err)
+	require.Len(t, dispatches, 2)
+	assert.Equal(t, models.EmailStatusFailed, dispatches[0].Status)
+	assert.Equal(t, models.EmailStatusSent, dispatches[1].Status)
+
+	// Delivered orders are not sent again
+	stored.Status = "delivered"
+	require.NoError(t, service.UpdateOrder(stored))
+	assert.ErrorIs(t, service.RecordOrderDispatches(stored, sent), ErrOrderNotDispatchable)
+}
+
+func TestReceiveOrder(t *testing.T) {
+	db, cleanup := SetupTestDBLegacy(t)
+	defer cleanup()
+
+	service := NewService(db)
+
+	account := createTestStandaloneAccountLegacy(t, service, "Receiving Cafe")
+	user := createTestUserLegacy(t, service, account.ID, "receiving@example.com", "manager")
+	flour := createTestInventoryItemLegacy(t, service, account.ID, "Flour")
+	milk := createTestInventoryItemLegacy(t, service, account.ID, "Milk")
+	oatMilk := createTestInventoryItemLegacy(t, service, account.ID, "Oat Milk")
+	sugar := createTestInventoryItemLegacy(t, service, account.ID, "Sugar")
+
+	order := &models.Order{AccountID: account.ID, CreatedBy: user.ID, Status: "ordered"}
+	require.NoError(t, service.CreateOrder(order, []models.OrderItem{
+		{InventoryItemID: flour.ID, Quantity: 10, UnitCost: 1.2, Vendor: "Mill Co"},
+		{InventoryItemID: milk.ID, Quantity: 20, UnitCost: 0.9, Vendor: "Local Dairy"},
+		{InventoryItemID: sugar.ID, Quantity: 5, UnitCost: 2, Vendor: "Mill Co"},
+	}))
+	items, err := service.GetOrderItems(order.ID)
+	require.NoError(t, err)
+	flourLine, milkLine, sugarLine := items[0], items[1], items[2]
+
+	t.Run("Pending Order", func(t *testing.T) {
+		pending := &models.Order{AccountID: account.ID, CreatedBy: user.ID}
+		require.NoError(t, service.CreateOrder(pending, []models.OrderItem{{InventoryItemID: flour.ID, Quantity: 1, UnitCost: 1}}))
+		_, err := service.ReceiveOrder(pending, &models.Receipt{ReceivedBy: user.ID}, []ReceiptLineInput{{OrderItemID: flourLine.ID, Quantity: 1}})
+		assert.ErrorIs(t, err, ErrOrderNotReceivable)
+	})
+
+	t.Run("Invalid Lines", func(t *testing.T) {
+		_, err := service.ReceiveOrder(order, &models.Receipt{ReceivedBy: user.ID}, nil)
+		assert.Error(t, err)
+		_, err = service.ReceiveOrder(order, &models.Receipt{ReceivedBy: user.ID}, []ReceiptLineInput{{OrderItemID: flourLine.ID, Quantity: 1}, {OrderItemID: flourLine.ID, Quantity: 1}})
+		assert.Error(t, err)
+		_, err = service.ReceiveOrder(order, &models.Receipt{ReceivedBy: user.ID}, []ReceiptLineInput{{OrderItemID: flourLine.ID, Quantity: -1}})
+		assert.Error(t, err)
+		_, err = service.ReceiveOrder(order, &models.Receipt{ReceivedBy: user.ID}, []ReceiptLineInput{{OrderItemID: 999999, Quantity: 1}})
+		assert.Error(t, err)
+
+		receipts, err := service.GetOrderReceipts(order.ID)
+		require.NoError(t, err)
+		assert.Empty(t, receipts)
+	})
+
+	var firstDelivery int
+	t.Run("Partial Receipt", func(t *testing.T) {
+		higherPrice := 1.0
+		report, err := service.ReceiveOrder(order, &models.Receipt{ReceivedBy: user.ID, Notes: "Dairy van"}, []ReceiptLineInput{
+			{OrderItemID: flourLine.ID, Quantity: 12, UnitCost: &higherPrice},
+			{OrderItemID: milkLine.ID, Quantity: 15, SubstituteItemID: &oatMilk.ID, LotNumber: "OAT-7"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "partially_received", order.Status)
+		assert.InDelta(t, 12*1.0+15*0.9, report.TotalCost, 0.0001)
+
+		types := make(map[string]ReceiptDiscrepancy)
+		for _, discrepancy := range report.Discrepancies {
+			types[discrepancy.Type+":"+discrepancy.ItemName] = discrepancy
+		}
+		assert.Len(t, report.Discrepancies, 4)
+		assert.Equal(t, 10.0, types["over:Flour"].Expected)
+		assert.Equal(t, 12.0, types["over:Flour"].Received)
+		assert.Equal(t, 1.2, types["price:Flour"].Expected)
+		assert.Equal(t, 1.0, types["price:Flour"].Received)
+		assert.InDelta(t, -2.0, types["price:Flour"].CostImpact, 0.0001)
+		assert.Equal(t, 20.0, types["short:Milk"].Expected)
+		assert.Equal(t, 15.0, types["short:Milk"].Received)
+		assert.InDelta(t, -4.5, types["short:Milk"].CostImpact, 0.0001)
+		assert.Equal(t, "Oat Milk", types["substitution:Milk"].SubstituteName)
+		assert.Equal(t, oatMilk.ID, types["substitution:Milk"].InventoryItemID)
+
+		require.Len(t, report.Outstanding, 2)
+		assert.Equal(t, milkLine.ID, report.Outstanding[0].ID)
+		assert.Equal(t, sugarLine.ID, report.Outstanding[1].ID)
+
+		// Each line that received goods created a delivery of what arrived
+		require.Len(t, report.Receipt.Lines, 2)
+		require.NotNil(t, report.Receipt.Lines[1].DeliveryID)
+		delivery, err := service.GetDelivery(*report.Receipt.Lines[1].DeliveryID)
+		require.NoError(t, err)
+		assert.Equal(t, oatMilk.ID, delivery.InventoryItemID)
+		assert.Equal(t, "Local Dairy", delivery.Vendor)
+		assert.Equal(t, "OAT-7", delivery.LotNumber)
+		assert.InDelta(t, 13.5, delivery.Cost, 0.0001)
+		require.NotNil(t, delivery.OrderItemID)
+		assert.Equal(t, milkLine.ID, *delivery.OrderItemID)
+		firstDelivery = delivery.ID
+	})
+
+	t.Run("Final Receipt Closes Order", func(t *testing.T) {
+		report, err := service.ReceiveOrder(order, &models.Receipt{ReceivedBy: user.ID}, []ReceiptLineInput{
+			{OrderItemID: milkLine.ID, Quantity: 5},
+			{OrderItemID: sugarLine.ID, Quantity: 5},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, report.Discrepancies)
+		assert.Empty(t, report.Outstanding)
+		assert.Equal(t, "delivered", order.Status)
+
+		stored, err := service.GetOrder(order.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "delivered", stored.Status)
+
+		receipts, err := service.GetOrderReceipts(order.ID)
+		require.NoError(t, err)
+		require.Len(t, receipts, 2)
+		assert.Equal(t, "Dairy van", receipts[0].Receipt.Notes)
+		assert.Len(t, receipts[0].Discrepancies, 4)
+		assert.Equal(t, firstDelivery, *receipts[0].Receipt.Lines[1].DeliveryID)
+
+		_, err = service.ReceiveOrder(order, &models.Receipt{ReceivedBy: user.ID}, []ReceiptLineInput{{OrderItemID: sugarLine.ID, Quantity: 1}})
+		assert.ErrorIs(t, err, ErrOrderNotReceivable)
+	})
+
+	t.Run("Received Deliveries Cannot Be Changed", func(t *testing.T) {
+		delivery, err := service.GetDelivery(firstDelivery)
+		require.NoError(t, err)
+		changed := *delivery
+		changed.Quantity = 10
+		assert.ErrorIs(t, service.UpdateDelivery(&changed), ErrDeliveryReceivedAgainstOrder)
+		assert.ErrorIs(t, service.DeleteDelivery(delivery.ID), ErrDeliveryReceivedAgainstOrder)
+
+		stored, err := service.GetDelivery(delivery.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 15.0, stored.Quantity)
+		line, err := service.orderItems.GetByID(milkLine.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 20.0, line.ReceivedQuantity, "the order still counts what was received")
+	})
+}
+
+func TestInvoiceMatching(t *testing.T) {
+	db, cleanup := SetupTestDBLegacy(t)
+	defer cleanup()
+
+	service := NewService(db)
+
+	account := createTestStandaloneAccountLegacy(t, service, "Invoice Cafe")
+	user := createTestUserLegacy(t, service, account.ID, "invoices@example.com", "manager")
+	flour := createTestInventoryItemLegacy(t, service, account.ID, "Flour")
+	sugar := createTestInventoryItemLegacy(t, service, account.ID, "Sugar")
+
+	stored, err := service.GetAccount(account.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, stored.InvoicePriceTolerancePct)
+
+	order := &models.Order{AccountID: account.ID, CreatedBy: user.ID, Status: "ordered"}
+	require.NoError(t, service.CreateOrder(order, []models.OrderItem{
+		{InventoryItemID: flour.ID, Quantity: 10, UnitCost: 1.2, Vendor: "Mill Co"},
+		{InventoryItemID: sugar.ID, Quantity: 5, UnitCost: 2, Vendor: "Mill Co"},
+	}))
+	items, err := service.GetOrderItems(order.ID)
+	require.NoError(t, err)
+	flourLine, sugarLine := items[0], items[1]
+
+	report, err := service.ReceiveOrder(order, &models.Receipt{ReceivedBy: user.ID}, []ReceiptLineInput{
+		{OrderItemID: flourLine.ID, Quantity: 10},
+		{OrderItemID: sugarLine.ID, Quantity: 4},
+	})
+	require.NoError(t, err)
+	flourDelivery := *report.Receipt.Lines[0].DeliveryID
+
+	t.Run("Matched Invoice Is Approved", func(t *testing.T) {
+		// The delivery links the line to its order item, and the order to the invoice
+		invoice := &models.Invoice{AccountID: account.ID, InvoiceNumber: "MC-100", CreatedBy: user.ID}
+		match, err := service.CreateInvoice(invoice, []models.InvoiceLine{
+			{DeliveryID: &flourDelivery, Quantity: 10, UnitCost: 1.21},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, models.InvoiceStatusApproved, match.Invoice.Status)
+		assert.NotNil(t, match.Invoice.ApprovedAt)
+		assert.Nil(t, match.Invoice.ApprovedBy)
+		require.NotNil(t, match.Invoice.OrderID)
+		assert.Equal(t, order.ID, *match.Invoice.OrderID)
+		assert.Equal(t, "Mill Co", match.Invoice.Vendor)
+		assert.InDelta(t, 12.1, match.Invoice.Total, 0.0001)
+
+		require.Len(t, match.Lines, 1)
+		assert.Equal(t, models.InvoiceLineMatchMatched, match.Lines[0].Status)
+		assert.Equal(t, "Flour", match.Lines[0].Description)
+		assert.Empty(t, match.Lines[0].Issues)
+
+		_, err = service.CreateInvoice(&models.Invoice{AccountID: account.ID, Vendor: "mill co", InvoiceNumber: "MC-100", CreatedBy: user.ID}, []models.InvoiceLine{
+			{OrderItemID: &flourLine.ID, Quantity: 1, UnitCost: 1.2},
+		})
+		assert.ErrorIs(t, err, ErrDuplicateInvoice)
+	})
+
+	var review *models.Invoice
+	t.Run("Mismatches Need Review", func(t *testing.T) {
+		review = &models.Invoice{AccountID: account.ID, InvoiceNumber: "MC-101", CreatedBy: user.ID}
+		match, err := service.CreateInvoice(review, []models.InvoiceLine{
+			{OrderItemID: &flourLine.ID, Quantity: 2, UnitCost: 1.2}, // Already billed in full
+			{OrderItemID: &sugarLine.ID, Quantity: 5, UnitCost: 2.5}, // One bag short, and overpriced
+			{Description: "Freight", Quantity: 1, UnitCost: 15},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, models.InvoiceStatusNeedsReview, match.Invoice.Status)
+		assert.Nil(t, match.Invoice.ApprovedAt)
+
+		require.Len(t, match.Lines, 3)
+		assert.Equal(t, 0.0, match.Lines[0].ReceivedQuantity)
+		assert.Equal(t, []string{InvoiceIssueQuantity}, match.Lines[0].Issues)
+		assert.Equal(t, 4.0, match.Lines[1].ReceivedQuantity)
+		assert.Equal(t, 5.0, match.Lines[1].OrderedQuantity)
+		assert.Equal(t, []string{InvoiceIssueQuantity, InvoiceIssuePrice}, match.Lines[1].Issues)
+		assert.Equal(t, []string{InvoiceIssueNotOrdered}, match.Lines[2].Issues)
+
+		invoices, err := service.GetInvoicesByAccount(account.ID, models.InvoiceStatusNeedsReview)
+		require.NoError(t, err)
+		require.Len(t, invoices, 1)
+		assert.Equal(t, "MC-101", invoices[0].InvoiceNumber)
+
+		stored, err := service.GetInvoice(review.ID)
+		require.NoError(t, err)
+		require.Len(t, stored.Lines, 3)
+		assert.Equal(t, models.InvoiceLineMatchMismatch, stored.Lines[1].MatchStatus)
+	})
+
+	t.Run("Lines Are Matched Against Their Delivery", func(t *testing.T) {
+		butter := createTestInventoryItemLegacy(t, service, account.ID, "Butter")
+		butterOrder := &models.Order{AccountID: account.ID, CreatedBy: user.ID, Status: "ordered"}
+		require.NoError(t, service.CreateOrder(butterOrder, []models.OrderItem{{InventoryItemID: butter.ID, Quantity: 10, UnitCost: 3, Vendor: "Mill Co"}}))
+		butterItems, err := service.GetOrderItems(butterOrder.ID)
+		require.NoError(t, err)
+
+		// The driver's paperwork recorded a discounted price, but the vendor bills the ordered price
+		receivedCost := 2.5
+		report, err := service.ReceiveOrder(butterOrder, &models.Receipt{ReceivedBy: user.ID}, []ReceiptLineInput{
+			{OrderItemID: butterItems[0].ID, Quantity: 10, UnitCost: &receivedCost},
+		})
+		require.NoError(t, err)
+		butterDelivery := report.Receipt.Lines[0].DeliveryID
+
+		invoice := &models.Invoice{AccountID: account.ID, InvoiceNumber: "MC-103", CreatedBy: user.ID}
+		match, err := service.CreateInvoice(invoice, []models.InvoiceLine{{DeliveryID: butterDelivery, Quantity: 10, UnitCost: 3}})
+		require.NoError(t, err)
+		assert.Equal(t, models.InvoiceStatusNeedsReview, match.Invoice.Status)
+
+		require.Len(t, match.Lines, 1)
+		assert.Equal(t, 3.0, match.Lines[0].OrderedUnitCost)
+		assert.Equal(t, 10.0, match.Lines[0].DeliveredQuantity)
+		assert.InDelta(t, 2.5, match.Lines[0].DeliveredUnitCost, 0.0001)
+		assert.Equal(t, []string{InvoiceIssueDeliveredUnitCost}, match.Lines[0].Issues)
+	})
+
+	t.Run("Tolerances Are Configurable", func(t *testing.T) {
+		_, err := service.UpdateInvoiceMatchSettings(account.ID, -1, 2)
+		assert.Error(t, err)
+
+		updated, err := service.UpdateInvoiceMatchSettings(account.ID, 25, 30)
+		require.NoError(t, err)
+		assert.Equal(t, 25.0, updated.InvoiceQuantityTolerancePct)
+
+		invoice := &models.Invoice{AccountID: account.ID, InvoiceNumber: "MC-102", CreatedBy: user.ID}
+		match, err := service.CreateInvoice(invoice, []models.InvoiceLine{{OrderItemID: &sugarLine.ID, Quantity: 5, UnitCost: 2.5}})
+		require.NoError(t, err)
+		assert.Equal(t, models.InvoiceStatusApproved, match.Invoice.Status)
+	})
+
+	t.Run("Review Approval", func(t *testing.T) {
+		require.NoError(t, service.ApproveInvoice(review, user.ID))
+		stored, err := service.GetInvoice(review.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.InvoiceStatusApproved, stored.Status)
+		require.NotNil(t, stored.ApprovedBy)
+		assert.Equal(t, user.ID, *stored.ApprovedBy)
+
+		assert.ErrorIs(t, service.ApproveInvoice(stored, user.ID), ErrInvoiceAlreadyApproved)
+		_, err = service.MatchInvoice(stored)
+		assert.ErrorIs(t, err, ErrInvoiceAlreadyApproved)
+	})
+
+	t.Run("Invalid Invoices", func(t *testing.T) {
+		_, err := service.CreateInvoice(&models.Invoice{AccountID: account.ID, Vendor: "Mill Co", CreatedBy: user.ID}, []models.InvoiceLine{{Quantity: 1}})
+		assert.Error(t, err, "invoice number is required")
+		_, err = service.CreateInvoice(&models.Invoice{AccountID: account.ID, InvoiceNumber: "X-1", CreatedBy: user.ID}, []models.InvoiceLine{{Description: "Freight", Quantity: 1}})
+		assert.Error(t, err, "vendor is required without an order item")
+		_, err = service.CreateInvoice(&models.Invoice{AccountID: account.ID, Vendor: "Mill Co", InvoiceNumber: "X-2", CreatedBy: user.ID}, []models.InvoiceLine{{OrderItemID: &flourLine.ID, Quantity: 0}})
+		assert.Error(t, err)
+
+		other := &models.Order{AccountID: account.ID, CreatedBy: user.ID}
+		require.NoError(t, service.CreateOrder(other, []models.OrderItem{{InventoryItemID: flour.ID, Quantity: 1, UnitCost: 1.2}}))
+		_, err = service.CreateInvoice(&models.Invoice{AccountID: account.ID, OrderID: &other.ID, InvoiceNumber: "X-3", CreatedBy: user.ID}, []models.InvoiceLine{{OrderItemID: &flourLine.ID, Quantity: 1, UnitCost: 1.2}})
+		assert.Error(t, err, "order items must be on the invoice's order")
+	})
+}
+
+func TestBudgetOperations(t *testing.T) {
+	db, cleanup := SetupTestDBLegacy(t)
+	defer cleanup()
+
+	service := NewService(db)
+
+	account := createTestStandaloneAccountLegacy(t, service, "Budget Cafe")
+	user := createTestUserLegacy(t, service, account.ID, "budgets@example.com", "manager")
+	dairy := &models.Category{AccountID: account.ID, Name: "Dairy"}
+	require.NoError(t, service.CreateCategory(dairy))
+	dryGoods := &models.Category{AccountID: account.ID, Name: "Dry Goods"}
+	require.NoError(t, service.CreateCategory(dryGoods))
+	milk := &models.InventoryItem{AccountID: account.ID, Name: "Milk", Unit: "liters", CategoryID: &dairy.ID}
+	require.NoError(t, service.CreateInventoryItem(milk))
+	flour := &models.InventoryItem{AccountID: account.ID, Name: "Flour", Unit: "kg", CategoryID: &dryGoods.ID}
+	require.NoError(t, service.CreateInventoryItem(flour))
+
+	// Wednesday 11 March 2026, in the week starting Monday 9 March
+	today := time.Date(2026, time.March, 11, 12, 0, 0, 0, time.UTC)
+
+	monthly := &models.Budget{AccountID: account.ID, CategoryID: dairy.ID, Period: models.BudgetPeriodMonthly, Amount: 100}
+	weekly := &models.Budget{AccountID: account.ID, CategoryID: dairy.ID, Period: models.BudgetPeriodWeekly, Amount: 50, SpendSource: models.BudgetSpendOrders, AlertThresholds: models.ThresholdList{}}
+
+	t.Run("Create Budgets", func(t *testing.T) {
+		require.NoError(t, service.CreateBudget(monthly))
+		assert.Equal(t, models.BudgetSpendDeliveries, monthly.SpendSource)
+		assert.Equal(t, models.ThresholdList{80, 100}, monthly.AlertThresholds)
+
+		require.NoError(t, service.CreateBudget(weekly))
+		stored, err := service.GetBudget(weekly.ID)
+		require.NoError(t, err)
+		assert.Empty(t, stored.AlertThresholds, "an empty list disables alerts")
+
+		budget := &models.Budget{AccountID: account.ID, CategoryID: dryGoods.ID, Period: models.BudgetPeriodMonthly, Amount: 200, AlertThresholds: models.ThresholdList{100, 50, 50}}
+		require.NoError(t, service.CreateBudget(budget))
+		assert.Equal(t, models.ThresholdList{50, 100}, budget.AlertThresholds)
+	})
+
+	t.Run("Invalid Budgets", func(t *testing.T) {
+		assert.Error(t, service.CreateBudget(&models.Budget{AccountID: account.ID, CategoryID: dairy.ID, Period: models.BudgetPeriodMonthly, Amount: 300}), "dairy already has a monthly budget")
+		assert.Error(t, service.CreateBudget(&models.Budget{AccountID: account.ID, CategoryID: dairy.ID, Period: "daily", Amount: 10}))
+		assert.Error(t, service.CreateBudget(&models.Budget{AccountID: account.ID, CategoryID: dryGoods.ID, Period: models.BudgetPeriodWeekly, Amount: 0}))
+		assert.Error(t, service.CreateBudget(&models.Budget{AccountID: account.ID, CategoryID: dryGoods.ID, Period: models.BudgetPeriodWeekly, Amount: 10, SpendSource: "sales"}))
+		assert.Error(t, service.CreateBudget(&models.Budget{AccountID: account.ID, CategoryID: dryGoods.ID, Period: models.BudgetPeriodWeekly, Amount: 10, AlertThresholds: models.ThresholdList{-5}}))
+
+		other := createTestStandaloneAccountLegacy(t, service, "Other Cafe")
+		assert.Error(t, service.CreateBudget(&models.Budget{AccountID: other.ID, CategoryID: dairy.ID, Period: models.BudgetPeriodWeekly, Amount: 10}), "the category belongs to another account")
+	})
+
+	// Delivered this month, but before this week
+	require.NoError(t, service.CreateDelivery(&models.Delivery{AccountID: account.ID, InventoryItemID: milk.ID, Vendor: "Local Dairy", Quantity: 20, Cost: 60, DeliveryDate: today.AddDate(0, 0, -9)}))
+	require.NoError(t, service.CreateDelivery(&models.Delivery{AccountID: account.ID, InventoryItemID: milk.ID, Vendor: "Local Dairy", Quantity: 10, Cost: 25, DeliveryDate: today.AddDate(0, 0, -1)}))
+	require.NoError(t, service.CreateDelivery(&models.Delivery{AccountID: account.ID, InventoryItemID: flour.ID, Vendor: "Mill Co", Quantity: 25, Cost: 40, DeliveryDate: today}))
+	// Delivered next month
+	require.NoError(t, service.CreateDelivery(&models.Delivery{AccountID: account.ID, InventoryItemID: milk.ID, Vendor: "Local Dairy", Quantity: 30, Cost: 99, DeliveryDate: time.Date(2026, time.April, 1, 9, 0, 0, 0, time.UTC)}))
+
+	require.NoError(t, service.CreateOrder(&models.Order{AccountID: account.ID, CreatedBy: user.ID, OrderDate: today.AddDate(0, 0, -1)}, []models.OrderItem{{InventoryItemID: milk.ID, Quantity: 10, UnitCost: 3}}))
+	require.NoError(t, service.CreateOrder(&models.Order{AccountID: account.ID, CreatedBy: user.ID, OrderDate: today, Status: "cancelled"}, []models.OrderItem{{InventoryItemID: milk.ID, Quantity: 100, UnitCost: 3}}))
+
+	t.Run("Budget Report", func(t *testing.T) {
+		report, err := service.GetBudgetReport(account.ID, today)
+		require.NoError(t, err)
+		require.Len(t, report, 3)
+
+		assert.Equal(t, "Dairy", report[0].CategoryName)
+		assert.Equal(t, models.BudgetPeriodWeekly, report[0].Budget.Period)
+		assert.Equal(t, time.Date(2026, time.March, 9, 0, 0, 0, 0, time.UTC), report[0].PeriodStart)
+		assert.Equal(t, time.Date(2026, time.March, 16, 0, 0, 0, 0, time.UTC), report[0].PeriodEnd)
+		assert.InDelta(t, 30, report[0].Spend, 0.001, "the cancelled order is not spend")
+		assert.Empty(t, report[0].CrossedThresholds)
+
+		assert.Equal(t, models.BudgetPeriodMonthly, report[1].Budget.Period)
+		assert.Equal(t, time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC), report[1].PeriodStart)
+		assert.InDelta(t, 85, report[1].Spend, 0.001)
+		assert.InDelta(t, 15, report[1].Remaining, 0.001)
+		assert.InDelta(t, 85, report[1].PercentUsed, 0.001)
+		assert.Equal(t, []float64{80}, report[1].CrossedThresholds)
+
+		assert.Equal(t, "Dry Goods", report[2].CategoryName)
+		assert.InDelta(t, 40, report[2].Spend, 0.001)
+	})
+
+	t.Run("Alerts Are Raised Once Per Threshold", func(t *testing.T) {
+		alerts, err := service.CheckBudgetAlerts(account.ID, today)
+		require.NoError(t, err)
+		require.Len(t, alerts, 1)
+		assert.Equal(t, monthly.ID, alerts[0].BudgetID)
+		assert.Equal(t, 80.0, alerts[0].Threshold)
+		assert.InDelta(t, 85, alerts[0].Spend, 0.001)
+
+		alerts, err = service.CheckBudgetAlerts(account.ID, today)
+		require.NoError(t, err)
+		assert.Empty(t, alerts)
+
+		require.NoError(t, service.CreateDelivery(&models.Delivery{AccountID: account.ID, InventoryItemID: milk.ID, Vendor: "Local Dairy", Quantity: 8, Cost: 20, DeliveryDate: today}))
+		alerts, err = service.CheckBudgetAlerts(account.ID, today)
+		require.NoError(t, err)
+		require.Len(t, alerts, 1)
+		assert.Equal(t, 100.0, alerts[0].Threshold)
+
+		pending, err := service.GetUnnotifiedBudgetAlerts(account.ID)
+		require.NoError(t, err)
+		require.Len(t, pending, 2)
+		require.NoError(t, service.MarkBudgetAlertsNotified([]int{pending[0].ID, pending[1].ID}, today))
+		pending, err = service.GetUnnotifiedBudgetAlerts(account.ID)
+		require.NoError(t, err)
+		assert.Empty(t, pending)
+
+		// A new month starts over
+		alerts, err = service.CheckBudgetAlerts(account.ID, time.Date(2026, time.April, 2, 12, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		require.Len(t, alerts, 1)
+		assert.Equal(t, 80.0, alerts[0].Threshold)
+		assert.Equal(t, time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC), alerts[0].PeriodStart)
+	})
+
+	t.Run("Update And Delete", func(t *testing.T) {
+		monthly.Amount = 500
+		require.NoError(t, service.UpdateBudget(monthly))
+		weekly.Period = models.BudgetPeriodMonthly
+		assert.Error(t, service.UpdateBudget(weekly), "dairy already has a monthly budget")
+
+		produce := &models.Category{AccountID: account.ID, Name: "Produce"}
+		require.NoError(t, service.CreateCategory(produce))
+		produceBudget := &models.Budget{AccountID: account.ID, CategoryID: produce.ID, Period: models.BudgetPeriodWeekly, Amount: 75}
+		require.NoError(t, service.CreateBudget(produceBudget))
+		assert.Error(t, service.DeleteCategory(produce.ID), "the category still has budgets")
+		require.NoError(t, service.DeleteBudget(produceBudget.ID))
+		assert.NoError(t, service.DeleteCategory(produce.ID))
+
+		require.NoError(t, service.DeleteBudget(monthly.ID))
+		require.NoError(t, service.DeleteBudget(weekly.ID))
+		_, err := service.GetBudget(monthly.ID)
+		assert.Error(t, err)
+		pending, err := service.GetUnnotifiedBudgetAlerts(account.ID)
+		require.NoError(t, err)
+		assert.Empty(t, pending, "the budget's alerts are deleted with it")
+	})
+}
+
 func TestInventorySnapshotOperations(t *testing.T) {
 	db, cleanup := SetupTestDBLegacy(t)
 	defer cleanup()
@@ -253,6 +2936,42 @@ func TestInventorySnapshotOperations(t *testing.T) {
 	assert.Equal(t, len(snapshot.Counts), len(retrievedSnapshot.Counts))
 }
 
+func TestEmailTemplateOperations(t *testing.T) {
+	db, cleanup := SetupTestDBLegacy(t)
+	defer cleanup()
+
+	service := NewService(db)
+
+	org := createTestOrganizationLegacy(t, service, "Template Test Corp")
+	account := createTestAccountLegacy(t, service, org.ID, "Test Coffee Shop")
+
+	// A missing override is not an error, so the built-in template is used
+	override, err := service.GetEmailTemplateOverride(account.ID, "low_stock_alert", "es")
+	require.NoError(t, err)
+	assert.Nil(t, override)
+
+	emailTemplate := &models.EmailTemplate{AccountID: account.ID, Name: "low_stock_alert", Locale: "es", Source: "<p>Uno</p>"}
+	require.NoError(t, service.SaveEmailTemplate(emailTemplate))
+	assert.NotZero(t, emailTemplate.ID)
+
+	// Saving the same template and language again replaces the source
+	replacement := &models.EmailTemplate{AccountID: account.ID, Name: "low_stock_alert", Locale: "es", Source: "<p>Dos</p>"}
+	require.NoError(t, service.SaveEmailTemplate(replacement))
+	assert.Equal(t, emailTemplate.ID, replacement.ID)
+
+	override, err = service.GetEmailTemplateOverride(account.ID, "low_stock_alert", "es")
+	require.NoError(t, err)
+	require.NotNil(t, override)
+	assert.Equal(t, "<p>Dos</p>", override.Source)
+
+	templates, err := service.GetEmailTemplatesByAccount(account.ID)
+	require.NoError(t, err)
+	assert.Len(t, templates, 1)
+
+	require.NoError(t, service.DeleteEmailTemplate(account.ID, "low_stock_alert", "es"))
+	assert.ErrorIs(t, service.DeleteEmailTemplate(account.ID, "low_stock_alert", "es"), ErrEmailTemplateNotFound)
+}
+
 func TestOrganizationOperations(t *testing.T) {
 	db, cleanup := SetupTestDBLegacy(t)
 	defer cleanup()
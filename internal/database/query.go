@@ -0,0 +1,138 @@
+package database
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Page size limits for list queries
+const (
+	DefaultPageSize = 100
+	MaxPageSize     = 500
+)
+
+// exportBatchSize is the number of rows inBatches loads at a time
+const exportBatchSize = 500
+
+// Fields each list can be sorted by. The first field is the list's default order.
+var (
+	InventoryItemSortFields     = []string{"name", "cost_per_unit", "min_stock_level", "id"}
+	MenuItemSortFields          = []string{"name", "price", "category", "id"}
+	DeliverySortFields          = []string{"delivery_date", "vendor", "quantity", "cost", "id"}
+	InventorySnapshotSortFields = []string{"timestamp", "id"}
+	CategorySortFields          = []string{"name", "created_at", "id"}
+)
+
+// ListQuery describes which page of a list to return, in what order, and how to filter it.
+// Filters that do not apply to a list are ignored by it.
+type ListQuery struct {
+	Limit  int    // Page size; defaults to DefaultPageSize and is capped at MaxPageSize
+	Offset int    // Number of rows to skip
+	Sort   string // Field to sort by; defaults to the list's first sort field
+	Desc   bool   // Sort in descending order
+
+	Search          string    // Case-insensitive match on the name, or the item's name for deliveries
+	CategoryID      *int      // Only rows assigned to this category, or deliveries of its items
+	Vendor          string    // Only rows whose vendor name contains this, ignoring case
+	VendorID        *int      // Only rows linked to this vendor record
+	InventoryItemID *int      // Only deliveries of this inventory item
+	StartDate       time.Time // Only rows dated on or after this time; zero for no bound
+	EndDate         time.Time // Only rows dated on or before this time; zero for no bound
+}
+
+// Page is the pagination metadata of a list query's result
+type Page struct {
+	Limit   int   `json:"limit"`
+	Offset  int   `json:"offset"`
+	Total   int64 `json:"total"`    // Number of rows matching the filters across all pages
+	HasMore bool  `json:"has_more"` // Whether rows remain after this page
+}
+
+// IsSortField reports whether field is one of the given sort fields
+func IsSortField(fields []string, field string) bool {
+	for _, candidate := range fields {
+		if candidate == field {
+			return true
+		}
+	}
+	return false
+}
+
+// likeEscaper escapes the LIKE wildcards, and the escape character itself, with a backslash
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// searchPattern turns a search term into a LIKE pattern matching it anywhere in a lower-cased
+// column. Wildcards in the term match only themselves, so the pattern must be used with ESCAPE '\'.
+func searchPattern(search string) string {
+	return "%" + likeEscaper.Replace(strings.ToLower(strings.TrimSpace(search))) + "%"
+}
+
+// filterDateRange restricts query to rows whose column falls within the query's date range
+func filterDateRange(query *gorm.DB, column string, list ListQuery) *gorm.DB {
+	if !list.StartDate.IsZero() {
+		query = query.Where(column+" >= ?", list.StartDate)
+	}
+	if !list.EndDate.IsZero() {
+		query = query.Where(column+" <= ?", list.EndDate)
+	}
+	return query
+}
+
+// paginate counts the rows matched by query, then loads the requested page of them into
+// dest. Rows are sorted by the requested field, or the first of sortFields when it is not
+// one of them, with the ID as a tie breaker so pages do not overlap. Dates default to
+// newest first, everything else to ascending order.
+func paginate(query *gorm.DB, list ListQuery, sortFields []string, dest interface{}) (Page, error) {
+	page := Page{Limit: list.Limit, Offset: list.Offset}
+	if page.Limit <= 0 {
+		page.Limit = DefaultPageSize
+	}
+	if page.Limit > MaxPageSize {
+		page.Limit = MaxPageSize
+	}
+	if page.Offset < 0 {
+		page.Offset = 0
+	}
+
+	// A new session lets the count and the page share the filters without sharing a statement
+	query = query.Session(&gorm.Session{})
+	if err := query.Count(&page.Total).Error; err != nil {
+		return Page{}, err
+	}
+
+	sortField, desc := list.Sort, list.Desc
+	if !IsSortField(sortFields, sortField) {
+		sortField = sortFields[0]
+		desc = sortField == "delivery_date" || sortField == "timestamp"
+	}
+	direction := " ASC"
+	if desc {
+		direction = " DESC"
+	}
+	order := sortField + direction
+	if sortField != "id" {
+		order += ", id" + direction
+	}
+
+	if err := query.Order(order).Limit(page.Limit).Offset(page.Offset).Find(dest).Error; err != nil {
+		return Page{}, err
+	}
+	page.HasMore = int64(page.Offset+page.Limit) < page.Total
+	return page, nil
+}
+
+// inBatches walks the rows matched by query in the given order, a batch at a time, so that
+// large results never have to be loaded at once. load receives the query for each batch,
+// loads and handles its rows, and returns how many there were; walking stops after a short
+// batch or an error.
+func inBatches(query *gorm.DB, order string, load func(batch *gorm.DB) (int, error)) error {
+	query = query.Session(&gorm.Session{})
+	for offset := 0; ; offset += exportBatchSize {
+		count, err := load(query.Order(order).Limit(exportBatchSize).Offset(offset))
+		if err != nil || count < exportBatchSize {
+			return err
+		}
+	}
+}
@@ -2,10 +2,12 @@ package database
 
 import (
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/mnadev/pantryos/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Repository interfaces for better testability
@@ -42,7 +44,9 @@ type InventoryItemRepository interface {
 	GetByID(id int) (*models.InventoryItem, error)
 	GetByAccountID(accountID int) ([]models.InventoryItem, error)
 	GetByVendor(accountID int, vendor string) ([]models.InventoryItem, error)
+	GetByVendorID(accountID int, vendorID int) ([]models.InventoryItem, error)
 	GetLowStockItems(accountID int) ([]models.InventoryItem, error)
+	List(accountID int, list ListQuery) ([]models.InventoryItem, Page, error)
 	Update(item *models.InventoryItem) error
 	Delete(id int) error
 }
@@ -52,6 +56,7 @@ type MenuItemRepository interface {
 	GetByID(id int) (*models.MenuItem, error)
 	GetByAccountID(accountID int) ([]models.MenuItem, error)
 	GetByCategory(accountID int, category string) ([]models.MenuItem, error)
+	List(accountID int, list ListQuery) ([]models.MenuItem, Page, error)
 	Update(item *models.MenuItem) error
 	Delete(id int) error
 	GetWithIngredients(id int) (*models.MenuItem, error)
@@ -62,18 +67,24 @@ type DeliveryRepository interface {
 	GetByID(id int) (*models.Delivery, error)
 	GetByAccountID(accountID int) ([]models.Delivery, error)
 	GetByVendor(accountID int, vendor string) ([]models.Delivery, error)
+	GetByVendorID(accountID int, vendorID int) ([]models.Delivery, error)
 	GetByDateRange(accountID int, startDate, endDate time.Time) ([]models.Delivery, error)
+	List(accountID int, list ListQuery) ([]models.Delivery, Page, error)
+	EachBatch(accountID int, startDate, endDate time.Time, fn func([]models.Delivery) error) error
 	Update(delivery *models.Delivery) error
 	Delete(id int) error
 	GetByAccountIDAfterDate(id int, timestamp time.Time) ([]models.Delivery, error)
 }
 
 type SaleRepository interface {
+	Create(sale *models.Sale) error
 	GetByAccountIDAfterDate(accountID int, afterDate time.Time) ([]models.Sale, error)
+	EachBatch(accountID int, startDate, endDate time.Time, fn func([]models.Sale) error) error
 }
 
 type RecipeRepository interface {
 	GetIngredientsByMenuItemID(menuItemID uint) ([]models.RecipeIngredient, error)
+	ReplaceIngredients(menuItemID int, ingredients []models.RecipeIngredient) error
 }
 
 type InventorySnapshotRepository interface {
@@ -81,7 +92,10 @@ type InventorySnapshotRepository interface {
 	GetByID(id int) (*models.InventorySnapshot, error)
 	GetByAccountID(accountID int) ([]models.InventorySnapshot, error)
 	GetLatestByAccountID(accountID int) (*models.InventorySnapshot, error)
+	GetLatestAsOf(accountID int, asOf time.Time) (*models.InventorySnapshot, error)
 	GetByDateRange(accountID int, startDate, endDate time.Time) ([]models.InventorySnapshot, error)
+	List(accountID int, list ListQuery) ([]models.InventorySnapshot, Page, error)
+	EachBatch(accountID int, startDate, endDate time.Time, fn func([]models.InventorySnapshot) error) error
 	Update(snapshot *models.InventorySnapshot) error
 	Delete(id int) error
 }
@@ -96,6 +110,49 @@ type OrderRepository interface {
 	GetWithItems(id int) (*models.Order, error)
 }
 
+type OrderItemRepository interface {
+	Create(item *models.OrderItem) error
+	GetByID(id int) (*models.OrderItem, error)
+	GetByOrderID(orderID int) ([]models.OrderItem, error)
+	GetByVendorID(vendorID int) ([]models.OrderItem, error)
+	Update(item *models.OrderItem) error
+	Delete(id int) error
+}
+
+type OrderDispatchRepository interface {
+	Create(dispatch *models.OrderDispatch) error
+	GetByOrderID(orderID int) ([]models.OrderDispatch, error)
+}
+
+type ReceiptRepository interface {
+	Create(receipt *models.Receipt) error
+	GetByID(id int) (*models.Receipt, error)
+	GetByOrderID(orderID int) ([]models.Receipt, error)
+}
+
+type ReceiptLineRepository interface {
+	Create(line *models.ReceiptLine) error
+	GetByReceiptID(receiptID int) ([]models.ReceiptLine, error)
+}
+
+type InvoiceRepository interface {
+	Create(invoice *models.Invoice) error
+	GetByID(id int) (*models.Invoice, error)
+	GetByAccountID(accountID int) ([]models.Invoice, error)
+	GetByStatus(accountID int, status string) ([]models.Invoice, error)
+	GetByNumber(accountID int, vendor string, invoiceNumber string) (*models.Invoice, error)
+	GetByVendorID(accountID int, vendorID int) ([]models.Invoice, error)
+	Update(invoice *models.Invoice) error
+}
+
+type InvoiceLineRepository interface {
+	Create(line *models.InvoiceLine) error
+	GetByInvoiceID(invoiceID int) ([]models.InvoiceLine, error)
+	Update(line *models.InvoiceLine) error
+	// GetApprovedQuantity sums the quantity of an order item billed by approved invoices other than one
+	GetApprovedQuantity(orderItemID int, excludeInvoiceID int) (float64, error)
+}
+
 type OrderRequestRepository interface {
 	Create(request *models.OrderRequest) error
 	GetByID(id int) (*models.OrderRequest, error)
@@ -122,10 +179,184 @@ type CategoryRepository interface {
 	GetByID(id int) (*models.Category, error)
 	GetByAccountID(accountID int) ([]models.Category, error)
 	GetActiveByAccountID(accountID int) ([]models.Category, error)
+	List(accountID int, list ListQuery) ([]models.Category, Page, error)
 	Update(category *models.Category) error
 	Delete(id int) error
 }
 
+type VendorRepository interface {
+	Create(vendor *models.Vendor) error
+	GetByID(id int) (*models.Vendor, error)
+	GetByAccountID(accountID int) ([]models.Vendor, error)
+	Update(vendor *models.Vendor) error
+	Delete(id int) error
+}
+
+type PriceHistoryRepository interface {
+	Create(entry *models.PriceHistory) error
+	GetByItemID(itemID int) ([]models.PriceHistory, error)
+	GetByItemIDAndDateRange(itemID int, startDate, endDate time.Time) ([]models.PriceHistory, error)
+	GetByAccountIDAsOf(accountID int, asOf time.Time) ([]models.PriceHistory, error)
+	GetLatestByItemAndVendor(itemID int, vendorID int) (*models.PriceHistory, error)
+	GetByDeliveryID(deliveryID int) (*models.PriceHistory, error)
+	Update(entry *models.PriceHistory) error
+	Delete(id int) error
+}
+
+type PriceAlertRepository interface {
+	Create(alert *models.PriceAlert) error
+	GetByAccountID(accountID int) ([]models.PriceAlert, error)
+	GetUnnotifiedByAccountID(accountID int) ([]models.PriceAlert, error)
+	MarkNotified(ids []int, notifiedAt time.Time) error
+}
+
+type BudgetRepository interface {
+	Create(budget *models.Budget) error
+	GetByID(id int) (*models.Budget, error)
+	GetByAccountID(accountID int) ([]models.Budget, error)
+	GetByCategoryID(categoryID int) ([]models.Budget, error)
+	Update(budget *models.Budget) error
+	Delete(id int) error
+}
+
+type BudgetAlertRepository interface {
+	Create(alert *models.BudgetAlert) error
+	GetByBudgetPeriod(budgetID int, periodStart time.Time) ([]models.BudgetAlert, error)
+	GetUnnotifiedByAccountID(accountID int) ([]models.BudgetAlert, error)
+	MarkNotified(ids []int, notifiedAt time.Time) error
+	DeleteByBudgetID(budgetID int) error
+}
+
+type InventoryLotRepository interface {
+	Create(lot *models.InventoryLot) error
+	GetByID(id int) (*models.InventoryLot, error)
+	GetByItemID(itemID int) ([]models.InventoryLot, error)
+	GetOpenByItemID(itemID int) ([]models.InventoryLot, error)
+	GetExpiringByAccountID(accountID int, before time.Time) ([]models.InventoryLot, error)
+	GetByDeliveryID(deliveryID int) (*models.InventoryLot, error)
+	Update(lot *models.InventoryLot) error
+	Delete(id int) error
+}
+
+type WasteLogRepository interface {
+	Create(wasteLog *models.WasteLog) error
+	GetByID(id int) (*models.WasteLog, error)
+	GetByAccountIDAndDateRange(accountID int, startDate, endDate time.Time) ([]models.WasteLog, error)
+	GetByAccountIDAfterDate(accountID int, afterDate time.Time) ([]models.WasteLog, error)
+	GetByItemIDAfterDate(itemID int, afterDate time.Time) ([]models.WasteLog, error)
+	Delete(id int) error
+}
+
+type InventoryLevelRepository interface {
+	GetByAccountID(accountID int) ([]models.InventoryLevel, error)
+	GetByItemID(itemID int) (*models.InventoryLevel, error)
+	Add(accountID, itemID int, delta float64) error
+	ReplaceForAccount(accountID int, levels []models.InventoryLevel) error
+	DeleteByItemID(itemID int) error
+}
+
+type StockAdjustmentRepository interface {
+	Create(adjustment *models.StockAdjustment) error
+	GetByID(id int) (*models.StockAdjustment, error)
+	GetByAccountIDAndDateRange(accountID int, startDate, endDate time.Time) ([]models.StockAdjustment, error)
+	GetByAccountIDAfterDate(accountID int, afterDate time.Time) ([]models.StockAdjustment, error)
+}
+
+type CountSessionRepository interface {
+	Create(session *models.CountSession) error
+	GetByID(id int) (*models.CountSession, error)
+	GetByAccountID(accountID int) ([]models.CountSession, error)
+	Update(session *models.CountSession) error
+}
+
+type CountEntryRepository interface {
+	Create(entry *models.CountEntry) error
+	GetBySessionID(sessionID int) ([]models.CountEntry, error)
+	GetBySessionItemAndPlace(sessionID, itemID int, locationID *int, zone string) (*models.CountEntry, error)
+	Update(entry *models.CountEntry) error
+}
+
+type StorageLocationRepository interface {
+	Create(location *models.StorageLocation) error
+	GetByID(id int) (*models.StorageLocation, error)
+	GetByAccountID(accountID int) ([]models.StorageLocation, error)
+	Update(location *models.StorageLocation) error
+	Delete(id int) error
+}
+
+type LocationTransferRepository interface {
+	Create(transfer *models.LocationTransfer) error
+	GetByAccountID(accountID int) ([]models.LocationTransfer, error)
+	GetByAccountIDAfterDate(accountID int, afterDate time.Time) ([]models.LocationTransfer, error)
+}
+
+type StockTransferRepository interface {
+	Create(transfer *models.StockTransfer) error
+	GetByID(id int) (*models.StockTransfer, error)
+	GetByAccountID(accountID int) ([]models.StockTransfer, error)
+	GetSentAfterDate(accountID int, afterDate time.Time) ([]models.StockTransfer, error)
+	GetReceivedAfterDate(accountID int, afterDate time.Time) ([]models.StockTransfer, error)
+	Update(transfer *models.StockTransfer) error
+}
+
+type StockTransferLotRepository interface {
+	Create(transferLot *models.StockTransferLot) error
+	GetByTransferID(transferID int) ([]models.StockTransferLot, error)
+}
+
+type CatalogCategoryRepository interface {
+	Create(category *models.CatalogCategory) error
+	GetByID(id int) (*models.CatalogCategory, error)
+	GetByOrganizationID(organizationID int) ([]models.CatalogCategory, error)
+	Update(category *models.CatalogCategory) error
+}
+
+type CatalogItemRepository interface {
+	Create(item *models.CatalogItem) error
+	GetByID(id int) (*models.CatalogItem, error)
+	GetByOrganizationID(organizationID int) ([]models.CatalogItem, error)
+	Update(item *models.CatalogItem) error
+}
+
+type CatalogMenuItemRepository interface {
+	Create(item *models.CatalogMenuItem) error
+	GetByID(id int) (*models.CatalogMenuItem, error)
+	GetByOrganizationID(organizationID int) ([]models.CatalogMenuItem, error)
+	Update(item *models.CatalogMenuItem) error
+}
+
+type CatalogRecipeRepository interface {
+	GetByCatalogMenuItemID(catalogMenuItemID int) ([]models.CatalogRecipeIngredient, error)
+	ReplaceIngredients(catalogMenuItemID int, ingredients []models.CatalogRecipeIngredient) error
+}
+
+type CatalogSubscriptionRepository interface {
+	Create(subscription *models.CatalogSubscription) error
+	GetByAccountID(accountID int) (*models.CatalogSubscription, error)
+	GetActiveByOrganizationID(organizationID int) ([]models.CatalogSubscription, error)
+	Update(subscription *models.CatalogSubscription) error
+}
+
+type CatalogSyncConflictRepository interface {
+	Create(conflict *models.CatalogSyncConflict) error
+	GetByAccountID(accountID int) ([]models.CatalogSyncConflict, error)
+	DeleteByAccountID(accountID int) error
+}
+
+// SearchCandidates holds the records of an account matching a search, by kind
+type SearchCandidates struct {
+	InventoryItems []models.InventoryItem
+	MenuItems      []models.MenuItem
+	Categories     []models.Category
+	Vendors        []models.Vendor
+	Orders         []models.Order
+	Deliveries     []models.Delivery
+}
+
+type SearchRepository interface {
+	Search(accountID int, terms []string, limit int) (*SearchCandidates, error)
+}
+
 type EmailScheduleRepository interface {
 	Create(schedule *models.EmailSchedule) error
 	GetByID(id int) (*models.EmailSchedule, error)
@@ -137,6 +368,14 @@ type EmailScheduleRepository interface {
 	UpdateLastSentAt(id int, lastSentAt time.Time) error
 }
 
+type EmailTemplateRepository interface {
+	Create(emailTemplate *models.EmailTemplate) error
+	GetByAccountID(accountID int) ([]models.EmailTemplate, error)
+	GetByAccountIDNameAndLocale(accountID int, name, locale string) (*models.EmailTemplate, error)
+	Update(emailTemplate *models.EmailTemplate) error
+	Delete(id int) error
+}
+
 // Repository implementations
 type organizationRepository struct {
 	db *DB
@@ -329,12 +568,38 @@ func (r *inventoryItemRepository) GetByAccountID(accountID int) ([]models.Invent
 	return items, err
 }
 
+func (r *inventoryItemRepository) List(accountID int, list ListQuery) ([]models.InventoryItem, Page, error) {
+	query := r.db.Model(&models.InventoryItem{}).Where("account_id = ?", accountID)
+	if list.Search != "" {
+		query = query.Where("LOWER(name) LIKE ? ESCAPE '\\'", searchPattern(list.Search))
+	}
+	if list.CategoryID != nil {
+		query = query.Where("category_id = ?", *list.CategoryID)
+	}
+	if list.Vendor != "" {
+		query = query.Where("LOWER(preferred_vendor) LIKE ? ESCAPE '\\'", searchPattern(list.Vendor))
+	}
+	if list.VendorID != nil {
+		query = query.Where("vendor_id = ?", *list.VendorID)
+	}
+
+	var items []models.InventoryItem
+	page, err := paginate(query, list, InventoryItemSortFields, &items)
+	return items, page, err
+}
+
 func (r *inventoryItemRepository) GetByVendor(accountID int, vendor string) ([]models.InventoryItem, error) {
 	var items []models.InventoryItem
 	err := r.db.Where("account_id = ? AND preferred_vendor = ?", accountID, vendor).Find(&items).Error
 	return items, err
 }
 
+func (r *inventoryItemRepository) GetByVendorID(accountID int, vendorID int) ([]models.InventoryItem, error) {
+	var items []models.InventoryItem
+	err := r.db.Where("account_id = ? AND vendor_id = ?", accountID, vendorID).Find(&items).Error
+	return items, err
+}
+
 func (r *inventoryItemRepository) GetLowStockItems(accountID int) ([]models.InventoryItem, error) {
 	var items []models.InventoryItem
 	err := r.db.Where("account_id = ? AND min_stock_level > 0", accountID).Find(&items).Error
@@ -381,6 +646,20 @@ func (r *menuItemRepository) GetByAccountID(accountID int) ([]models.MenuItem, e
 	return items, err
 }
 
+func (r *menuItemRepository) List(accountID int, list ListQuery) ([]models.MenuItem, Page, error) {
+	query := r.db.Model(&models.MenuItem{}).Where("account_id = ?", accountID)
+	if list.Search != "" {
+		query = query.Where("LOWER(name) LIKE ? ESCAPE '\\'", searchPattern(list.Search))
+	}
+	if list.CategoryID != nil {
+		query = query.Where("category_id = ?", *list.CategoryID)
+	}
+
+	var items []models.MenuItem
+	page, err := paginate(query, list, MenuItemSortFields, &items)
+	return items, page, err
+}
+
 func (r *menuItemRepository) GetByCategory(accountID int, category string) ([]models.MenuItem, error) {
 	var items []models.MenuItem
 	err := r.db.Where("account_id = ? AND category = ?", accountID, category).Find(&items).Error
@@ -436,12 +715,42 @@ func (r *deliveryRepository) GetByAccountID(accountID int) ([]models.Delivery, e
 	return deliveries, err
 }
 
+func (r *deliveryRepository) List(accountID int, list ListQuery) ([]models.Delivery, Page, error) {
+	query := r.db.Model(&models.Delivery{}).Where("account_id = ?", accountID)
+	if list.Search != "" {
+		query = query.Where("inventory_item_id IN (?)", r.db.Model(&models.InventoryItem{}).Select("id").Where("LOWER(name) LIKE ? ESCAPE '\\'", searchPattern(list.Search)))
+	}
+	if list.Vendor != "" {
+		query = query.Where("LOWER(vendor) LIKE ? ESCAPE '\\'", searchPattern(list.Vendor))
+	}
+	if list.VendorID != nil {
+		query = query.Where("vendor_id = ?", *list.VendorID)
+	}
+	if list.InventoryItemID != nil {
+		query = query.Where("inventory_item_id = ?", *list.InventoryItemID)
+	}
+	if list.CategoryID != nil {
+		query = query.Where("inventory_item_id IN (?)", r.db.Model(&models.InventoryItem{}).Select("id").Where("category_id = ?", *list.CategoryID))
+	}
+	query = filterDateRange(query, "delivery_date", list)
+
+	var deliveries []models.Delivery
+	page, err := paginate(query, list, DeliverySortFields, &deliveries)
+	return deliveries, page, err
+}
+
 func (r *deliveryRepository) GetByVendor(accountID int, vendor string) ([]models.Delivery, error) {
 	var deliveries []models.Delivery
 	err := r.db.Where("account_id = ? AND vendor = ?", accountID, vendor).Order("delivery_date DESC").Find(&deliveries).Error
 	return deliveries, err
 }
 
+func (r *deliveryRepository) GetByVendorID(accountID int, vendorID int) ([]models.Delivery, error) {
+	var deliveries []models.Delivery
+	err := r.db.Where("account_id = ? AND vendor_id = ?", accountID, vendorID).Order("delivery_date DESC").Find(&deliveries).Error
+	return deliveries, err
+}
+
 func (r *deliveryRepository) GetByDateRange(accountID int, startDate, endDate time.Time) ([]models.Delivery, error) {
 	var deliveries []models.Delivery
 	err := r.db.Where("account_id = ? AND delivery_date BETWEEN ? AND ?",
@@ -449,6 +758,19 @@ func (r *deliveryRepository) GetByDateRange(accountID int, startDate, endDate ti
 	return deliveries, err
 }
 
+// EachBatch calls fn with the deliveries of an account in a date range, oldest first,
+// a batch at a time. Zero dates leave that end of the range open.
+func (r *deliveryRepository) EachBatch(accountID int, startDate, endDate time.Time, fn func([]models.Delivery) error) error {
+	query := filterDateRange(r.db.Where("account_id = ?", accountID), "delivery_date", ListQuery{StartDate: startDate, EndDate: endDate})
+	return inBatches(query, "delivery_date, id", func(batch *gorm.DB) (int, error) {
+		var deliveries []models.Delivery
+		if err := batch.Find(&deliveries).Error; err != nil || len(deliveries) == 0 {
+			return 0, err
+		}
+		return len(deliveries), fn(deliveries)
+	})
+}
+
 func (r *deliveryRepository) Update(delivery *models.Delivery) error {
 	return r.db.Save(delivery).Error
 }
@@ -485,7 +807,9 @@ func NewInventorySnapshotRepository(db *DB) InventorySnapshotRepository {
 }
 
 func (r *inventorySnapshotRepository) Create(snapshot *models.InventorySnapshot) error {
-	snapshot.Timestamp = time.Now()
+	if snapshot.Timestamp.IsZero() {
+		snapshot.Timestamp = time.Now()
+	}
 	return r.db.Create(snapshot).Error
 }
 
@@ -508,6 +832,14 @@ func (r *inventorySnapshotRepository) GetByAccountID(accountID int) ([]models.In
 	return snapshots, err
 }
 
+func (r *inventorySnapshotRepository) List(accountID int, list ListQuery) ([]models.InventorySnapshot, Page, error) {
+	query := filterDateRange(r.db.Model(&models.InventorySnapshot{}).Where("account_id = ?", accountID), "timestamp", list)
+
+	var snapshots []models.InventorySnapshot
+	page, err := paginate(query, list, InventorySnapshotSortFields, &snapshots)
+	return snapshots, page, err
+}
+
 func (r *inventorySnapshotRepository) GetLatestByAccountID(accountID int) (*models.InventorySnapshot, error) {
 	var snapshots []models.InventorySnapshot
 	// Use Find instead of First to avoid LIMIT clause that ramsql doesn't support
@@ -521,6 +853,20 @@ func (r *inventorySnapshotRepository) GetLatestByAccountID(accountID int) (*mode
 	return &snapshots[0], nil
 }
 
+// GetLatestAsOf returns the most recent snapshot of an account taken at or before asOf.
+func (r *inventorySnapshotRepository) GetLatestAsOf(accountID int, asOf time.Time) (*models.InventorySnapshot, error) {
+	var snapshots []models.InventorySnapshot
+	// Use Find instead of First to avoid LIMIT clause that ramsql doesn't support
+	err := r.db.Where("account_id = ? AND timestamp <= ?", accountID, asOf).Order("timestamp DESC, id DESC").Find(&snapshots).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &snapshots[0], nil
+}
+
 func (r *inventorySnapshotRepository) GetByDateRange(accountID int, startDate, endDate time.Time) ([]models.InventorySnapshot, error) {
 	var snapshots []models.InventorySnapshot
 	err := r.db.Where("account_id = ? AND timestamp BETWEEN ? AND ?",
@@ -528,6 +874,19 @@ func (r *inventorySnapshotRepository) GetByDateRange(accountID int, startDate, e
 	return snapshots, err
 }
 
+// EachBatch calls fn with the snapshots of an account in a date range, oldest first,
+// a batch at a time. Zero dates leave that end of the range open.
+func (r *inventorySnapshotRepository) EachBatch(accountID int, startDate, endDate time.Time, fn func([]models.InventorySnapshot) error) error {
+	query := filterDateRange(r.db.Where("account_id = ?", accountID), "timestamp", ListQuery{StartDate: startDate, EndDate: endDate})
+	return inBatches(query, "timestamp, id", func(batch *gorm.DB) (int, error) {
+		var snapshots []models.InventorySnapshot
+		if err := batch.Find(&snapshots).Error; err != nil || len(snapshots) == 0 {
+			return 0, err
+		}
+		return len(snapshots), fn(snapshots)
+	})
+}
+
 func (r *inventorySnapshotRepository) Update(snapshot *models.InventorySnapshot) error {
 	return r.db.Save(snapshot).Error
 }
@@ -545,6 +904,10 @@ func NewSaleRepository(db *DB) SaleRepository {
 	return &saleRepository{db: db}
 }
 
+func (r *saleRepository) Create(sale *models.Sale) error {
+	return r.db.Create(sale).Error
+}
+
 func (r *saleRepository) GetByAccountIDAfterDate(accountID int, afterDate time.Time) ([]models.Sale, error) {
 	var sales []models.Sale
 
@@ -560,6 +923,19 @@ func (r *saleRepository) GetByAccountIDAfterDate(accountID int, afterDate time.T
 	return sales, nil
 }
 
+// EachBatch calls fn with the sales of an account in a date range, oldest first, a batch
+// at a time, with their items loaded. Zero dates leave that end of the range open.
+func (r *saleRepository) EachBatch(accountID int, startDate, endDate time.Time, fn func([]models.Sale) error) error {
+	query := filterDateRange(r.db.Preload("Items").Where("account_id = ?", accountID), "sale_date", ListQuery{StartDate: startDate, EndDate: endDate})
+	return inBatches(query, "sale_date, id", func(batch *gorm.DB) (int, error) {
+		var sales []models.Sale
+		if err := batch.Find(&sales).Error; err != nil || len(sales) == 0 {
+			return 0, err
+		}
+		return len(sales), fn(sales)
+	})
+}
+
 // Order repository implementation
 type orderRepository struct {
 	db *DB
@@ -584,6 +960,23 @@ func (r *recipeRepository) GetIngredientsByMenuItemID(menuItemID uint) ([]models
 	return ingredients, nil
 }
 
+// ReplaceIngredients replaces the whole recipe of a menu item
+func (r *recipeRepository) ReplaceIngredients(menuItemID int, ingredients []models.RecipeIngredient) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("menu_item_id = ?", menuItemID).Delete(&models.RecipeIngredient{}).Error; err != nil {
+			return err
+		}
+		for i := range ingredients {
+			ingredients[i].ID = 0
+			ingredients[i].MenuItemID = menuItemID
+			if err := tx.Create(&ingredients[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func NewOrderRepository(db *DB) OrderRepository {
 	return &orderRepository{db: db}
 }
@@ -637,6 +1030,217 @@ func (r *orderRepository) GetWithItems(id int) (*models.Order, error) {
 	return &order, nil
 }
 
+// Order item repository implementation
+type orderItemRepository struct {
+	db *DB
+}
+
+func NewOrderItemRepository(db *DB) OrderItemRepository {
+	return &orderItemRepository{db: db}
+}
+
+func (r *orderItemRepository) Create(item *models.OrderItem) error {
+	return r.db.Create(item).Error
+}
+
+func (r *orderItemRepository) GetByID(id int) (*models.OrderItem, error) {
+	var item models.OrderItem
+	// Use Find instead of First to avoid LIMIT clause that ramsql doesn't support
+	err := r.db.Where("id = ?", id).Find(&item).Error
+	if err != nil {
+		return nil, err
+	}
+	if item.ID == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &item, nil
+}
+
+func (r *orderItemRepository) GetByOrderID(orderID int) ([]models.OrderItem, error) {
+	var items []models.OrderItem
+	err := r.db.Where("order_id = ?", orderID).Order("id ASC").Find(&items).Error
+	return items, err
+}
+
+func (r *orderItemRepository) GetByVendorID(vendorID int) ([]models.OrderItem, error) {
+	var items []models.OrderItem
+	err := r.db.Where("vendor_id = ?", vendorID).Order("id ASC").Find(&items).Error
+	return items, err
+}
+
+func (r *orderItemRepository) Update(item *models.OrderItem) error {
+	return r.db.Save(item).Error
+}
+
+func (r *orderItemRepository) Delete(id int) error {
+	return r.db.Delete(&models.OrderItem{}, id).Error
+}
+
+// Order dispatch repository implementation
+type orderDispatchRepository struct {
+	db *DB
+}
+
+func NewOrderDispatchRepository(db *DB) OrderDispatchRepository {
+	return &orderDispatchRepository{db: db}
+}
+
+func (r *orderDispatchRepository) Create(dispatch *models.OrderDispatch) error {
+	if dispatch.SentAt.IsZero() {
+		dispatch.SentAt = time.Now()
+	}
+	return r.db.Create(dispatch).Error
+}
+
+func (r *orderDispatchRepository) GetByOrderID(orderID int) ([]models.OrderDispatch, error) {
+	var dispatches []models.OrderDispatch
+	err := r.db.Where("order_id = ?", orderID).Order("sent_at ASC, id ASC").Find(&dispatches).Error
+	return dispatches, err
+}
+
+// Receipt repository implementation
+type receiptRepository struct {
+	db *DB
+}
+
+func NewReceiptRepository(db *DB) ReceiptRepository {
+	return &receiptRepository{db: db}
+}
+
+func (r *receiptRepository) Create(receipt *models.Receipt) error {
+	if receipt.ReceivedAt.IsZero() {
+		receipt.ReceivedAt = time.Now()
+	}
+	return r.db.Create(receipt).Error
+}
+
+func (r *receiptRepository) GetByID(id int) (*models.Receipt, error) {
+	var receipt models.Receipt
+	err := r.db.First(&receipt, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &receipt, nil
+}
+
+func (r *receiptRepository) GetByOrderID(orderID int) ([]models.Receipt, error) {
+	var receipts []models.Receipt
+	err := r.db.Where("order_id = ?", orderID).Order("received_at ASC, id ASC").Find(&receipts).Error
+	return receipts, err
+}
+
+// Receipt line repository implementation
+type receiptLineRepository struct {
+	db *DB
+}
+
+func NewReceiptLineRepository(db *DB) ReceiptLineRepository {
+	return &receiptLineRepository{db: db}
+}
+
+func (r *receiptLineRepository) Create(line *models.ReceiptLine) error {
+	return r.db.Create(line).Error
+}
+
+func (r *receiptLineRepository) GetByReceiptID(receiptID int) ([]models.ReceiptLine, error) {
+	var lines []models.ReceiptLine
+	err := r.db.Where("receipt_id = ?", receiptID).Order("id ASC").Find(&lines).Error
+	return lines, err
+}
+
+// Invoice repository implementation
+type invoiceRepository struct {
+	db *DB
+}
+
+func NewInvoiceRepository(db *DB) InvoiceRepository {
+	return &invoiceRepository{db: db}
+}
+
+func (r *invoiceRepository) Create(invoice *models.Invoice) error {
+	return r.db.Create(invoice).Error
+}
+
+func (r *invoiceRepository) GetByID(id int) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := r.db.First(&invoice, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+func (r *invoiceRepository) GetByAccountID(accountID int) ([]models.Invoice, error) {
+	var invoices []models.Invoice
+	err := r.db.Where("account_id = ?", accountID).Order("invoice_date DESC, id DESC").Find(&invoices).Error
+	return invoices, err
+}
+
+func (r *invoiceRepository) GetByStatus(accountID int, status string) ([]models.Invoice, error) {
+	var invoices []models.Invoice
+	err := r.db.Where("account_id = ? AND status = ?", accountID, status).Order("invoice_date DESC, id DESC").Find(&invoices).Error
+	return invoices, err
+}
+
+func (r *invoiceRepository) GetByVendorID(accountID int, vendorID int) ([]models.Invoice, error) {
+	var invoices []models.Invoice
+	err := r.db.Where("account_id = ? AND vendor_id = ?", accountID, vendorID).Order("invoice_date DESC, id DESC").Find(&invoices).Error
+	return invoices, err
+}
+
+func (r *invoiceRepository) GetByNumber(accountID int, vendor string, invoiceNumber string) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := r.db.Where("account_id = ? AND LOWER(vendor) = LOWER(?) AND invoice_number = ?", accountID, vendor, invoiceNumber).First(&invoice).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+func (r *invoiceRepository) Update(invoice *models.Invoice) error {
+	invoice.UpdatedAt = time.Now()
+	return r.db.Save(invoice).Error
+}
+
+// Invoice line repository implementation
+type invoiceLineRepository struct {
+	db *DB
+}
+
+func NewInvoiceLineRepository(db *DB) InvoiceLineRepository {
+	return &invoiceLineRepository{db: db}
+}
+
+func (r *invoiceLineRepository) Create(line *models.InvoiceLine) error {
+	return r.db.Create(line).Error
+}
+
+func (r *invoiceLineRepository) GetByInvoiceID(invoiceID int) ([]models.InvoiceLine, error) {
+	var lines []models.InvoiceLine
+	err := r.db.Where("invoice_id = ?", invoiceID).Order("id ASC").Find(&lines).Error
+	return lines, err
+}
+
+func (r *invoiceLineRepository) Update(line *models.InvoiceLine) error {
+	return r.db.Save(line).Error
+}
+
+func (r *invoiceLineRepository) GetApprovedQuantity(orderItemID int, excludeInvoiceID int) (float64, error) {
+	var lines []models.InvoiceLine
+	err := r.db.Joins("JOIN invoices ON invoices.id = invoice_lines.invoice_id").
+		Where("invoice_lines.order_item_id = ? AND invoices.id <> ? AND invoices.status = ?", orderItemID, excludeInvoiceID, models.InvoiceStatusApproved).
+		Find(&lines).Error
+	if err != nil {
+		return 0, err
+	}
+
+	quantity := 0.0
+	for _, line := range lines {
+		quantity += line.Quantity
+	}
+	return quantity, nil
+}
+
 // Order request repository implementation
 type orderRequestRepository struct {
 	db *DB
@@ -802,6 +1406,17 @@ func (r *categoryRepository) GetByAccountID(accountID int) ([]models.Category, e
 	return categories, err
 }
 
+func (r *categoryRepository) List(accountID int, list ListQuery) ([]models.Category, Page, error) {
+	query := r.db.Model(&models.Category{}).Where("account_id = ?", accountID)
+	if list.Search != "" {
+		query = query.Where("LOWER(name) LIKE ? ESCAPE '\\'", searchPattern(list.Search))
+	}
+
+	var categories []models.Category
+	page, err := paginate(query, list, CategorySortFields, &categories)
+	return categories, page, err
+}
+
 func (r *categoryRepository) GetActiveByAccountID(accountID int) ([]models.Category, error) {
 	var categories []models.Category
 	err := r.db.Where("account_id = ? AND is_active = true", accountID).Find(&categories).Error
@@ -817,43 +1432,1006 @@ func (r *categoryRepository) Delete(id int) error {
 	return r.db.Delete(&models.Category{}, id).Error
 }
 
-// Email schedule repository implementation
-type emailScheduleRepository struct {
+// Vendor repository implementation
+type vendorRepository struct {
 	db *DB
 }
 
-func NewEmailScheduleRepository(db *DB) EmailScheduleRepository {
-	return &emailScheduleRepository{db: db}
+func NewVendorRepository(db *DB) VendorRepository {
+	return &vendorRepository{db: db}
 }
 
-func (r *emailScheduleRepository) Create(schedule *models.EmailSchedule) error {
-	schedule.CreatedAt = time.Now()
-	schedule.UpdatedAt = time.Now()
-	return r.db.Create(schedule).Error
+func (r *vendorRepository) Create(vendor *models.Vendor) error {
+	vendor.CreatedAt = time.Now()
+	vendor.UpdatedAt = time.Now()
+	return r.db.Create(vendor).Error
 }
 
-func (r *emailScheduleRepository) GetByID(id int) (*models.EmailSchedule, error) {
-	var schedule models.EmailSchedule
+func (r *vendorRepository) GetByID(id int) (*models.Vendor, error) {
+	var vendor models.Vendor
 	// Use Find instead of First to avoid LIMIT clause that ramsql doesn't support
-	err := r.db.Where("id = ?", id).Find(&schedule).Error
+	err := r.db.Where("id = ?", id).Find(&vendor).Error
 	if err != nil {
 		return nil, err
 	}
-	if schedule.ID == 0 {
+	if vendor.ID == 0 {
 		return nil, gorm.ErrRecordNotFound
 	}
-	return &schedule, nil
+	return &vendor, nil
 }
 
-func (r *emailScheduleRepository) GetByAccountID(accountID int) ([]models.EmailSchedule, error) {
-	var schedules []models.EmailSchedule
-	err := r.db.Where("account_id = ?", accountID).Order("created_at DESC").Find(&schedules).Error
-	return schedules, err
+func (r *vendorRepository) GetByAccountID(accountID int) ([]models.Vendor, error) {
+	var vendors []models.Vendor
+	err := r.db.Where("account_id = ?", accountID).Order("name ASC").Find(&vendors).Error
+	return vendors, err
 }
 
-func (r *emailScheduleRepository) GetByAccountIDAndType(accountID int, emailType string) (*models.EmailSchedule, error) {
-	var schedule models.EmailSchedule
-	err := r.db.Where("account_id = ? AND email_type = ?", accountID, emailType).First(&schedule).Error
+func (r *vendorRepository) Update(vendor *models.Vendor) error {
+	vendor.UpdatedAt = time.Now()
+	return r.db.Save(vendor).Error
+}
+
+func (r *vendorRepository) Delete(id int) error {
+	return r.db.Delete(&models.Vendor{}, id).Error
+}
+
+// Price history repository implementation
+type priceHistoryRepository struct {
+	db *DB
+}
+
+func NewPriceHistoryRepository(db *DB) PriceHistoryRepository {
+	return &priceHistoryRepository{db: db}
+}
+
+func (r *priceHistoryRepository) Create(entry *models.PriceHistory) error {
+	entry.CreatedAt = time.Now()
+	return r.db.Create(entry).Error
+}
+
+func (r *priceHistoryRepository) GetByItemID(itemID int) ([]models.PriceHistory, error) {
+	var entries []models.PriceHistory
+	err := r.db.Where("inventory_item_id = ?", itemID).Order("recorded_at ASC, id ASC").Find(&entries).Error
+	return entries, err
+}
+
+func (r *priceHistoryRepository) GetByItemIDAndDateRange(itemID int, startDate, endDate time.Time) ([]models.PriceHistory, error) {
+	var entries []models.PriceHistory
+	err := r.db.Where("inventory_item_id = ? AND recorded_at BETWEEN ? AND ?", itemID, startDate, endDate).
+		Order("recorded_at ASC, id ASC").Find(&entries).Error
+	return entries, err
+}
+
+// GetByAccountIDAsOf returns every price recorded for an account's items at or before asOf, oldest first.
+func (r *priceHistoryRepository) GetByAccountIDAsOf(accountID int, asOf time.Time) ([]models.PriceHistory, error) {
+	var entries []models.PriceHistory
+	err := r.db.Where("account_id = ? AND recorded_at <= ?", accountID, asOf).
+		Order("recorded_at ASC, id ASC").Find(&entries).Error
+	return entries, err
+}
+
+func (r *priceHistoryRepository) GetLatestByItemAndVendor(itemID int, vendorID int) (*models.PriceHistory, error) {
+	var entries []models.PriceHistory
+	// Use Find instead of First to avoid LIMIT clause that ramsql doesn't support
+	err := r.db.Where("inventory_item_id = ? AND vendor_id = ?", itemID, vendorID).
+		Order("recorded_at DESC, id DESC").Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &entries[0], nil
+}
+
+func (r *priceHistoryRepository) GetByDeliveryID(deliveryID int) (*models.PriceHistory, error) {
+	var entries []models.PriceHistory
+	// Use Find instead of First to avoid LIMIT clause that ramsql doesn't support
+	err := r.db.Where("delivery_id = ?", deliveryID).Order("id ASC").Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &entries[0], nil
+}
+
+func (r *priceHistoryRepository) Update(entry *models.PriceHistory) error {
+	return r.db.Save(entry).Error
+}
+
+func (r *priceHistoryRepository) Delete(id int) error {
+	return r.db.Delete(&models.PriceHistory{}, id).Error
+}
+
+// Price alert repository implementation
+type priceAlertRepository struct {
+	db *DB
+}
+
+func NewPriceAlertRepository(db *DB) PriceAlertRepository {
+	return &priceAlertRepository{db: db}
+}
+
+func (r *priceAlertRepository) Create(alert *models.PriceAlert) error {
+	alert.CreatedAt = time.Now()
+	return r.db.Create(alert).Error
+}
+
+func (r *priceAlertRepository) GetByAccountID(accountID int) ([]models.PriceAlert, error) {
+	var alerts []models.PriceAlert
+	err := r.db.Where("account_id = ?", accountID).Order("created_at DESC").Find(&alerts).Error
+	return alerts, err
+}
+
+func (r *priceAlertRepository) GetUnnotifiedByAccountID(accountID int) ([]models.PriceAlert, error) {
+	var alerts []models.PriceAlert
+	err := r.db.Where("account_id = ? AND notified_at IS NULL", accountID).Order("created_at ASC").Find(&alerts).Error
+	return alerts, err
+}
+
+func (r *priceAlertRepository) MarkNotified(ids []int, notifiedAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&models.PriceAlert{}).Where("id IN ?", ids).Update("notified_at", notifiedAt).Error
+}
+
+// Budget repository implementation
+type budgetRepository struct {
+	db *DB
+}
+
+func NewBudgetRepository(db *DB) BudgetRepository {
+	return &budgetRepository{db: db}
+}
+
+func (r *budgetRepository) Create(budget *models.Budget) error {
+	return r.db.Create(budget).Error
+}
+
+func (r *budgetRepository) GetByID(id int) (*models.Budget, error) {
+	var budget models.Budget
+	err := r.db.First(&budget, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+func (r *budgetRepository) GetByAccountID(accountID int) ([]models.Budget, error) {
+	var budgets []models.Budget
+	err := r.db.Where("account_id = ?", accountID).Order("id ASC").Find(&budgets).Error
+	return budgets, err
+}
+
+func (r *budgetRepository) GetByCategoryID(categoryID int) ([]models.Budget, error) {
+	var budgets []models.Budget
+	err := r.db.Where("category_id = ?", categoryID).Order("id ASC").Find(&budgets).Error
+	return budgets, err
+}
+
+func (r *budgetRepository) Update(budget *models.Budget) error {
+	budget.UpdatedAt = time.Now()
+	return r.db.Save(budget).Error
+}
+
+func (r *budgetRepository) Delete(id int) error {
+	return r.db.Delete(&models.Budget{}, id).Error
+}
+
+// Budget alert repository implementation
+type budgetAlertRepository struct {
+	db *DB
+}
+
+func NewBudgetAlertRepository(db *DB) BudgetAlertRepository {
+	return &budgetAlertRepository{db: db}
+}
+
+func (r *budgetAlertRepository) Create(alert *models.BudgetAlert) error {
+	alert.CreatedAt = time.Now()
+	return r.db.Create(alert).Error
+}
+
+func (r *budgetAlertRepository) GetByBudgetPeriod(budgetID int, periodStart time.Time) ([]models.BudgetAlert, error) {
+	var alerts []models.BudgetAlert
+	err := r.db.Where("budget_id = ? AND period_start = ?", budgetID, periodStart).Order("threshold ASC").Find(&alerts).Error
+	return alerts, err
+}
+
+func (r *budgetAlertRepository) GetUnnotifiedByAccountID(accountID int) ([]models.BudgetAlert, error) {
+	var alerts []models.BudgetAlert
+	err := r.db.Where("account_id = ? AND notified_at IS NULL", accountID).Order("created_at ASC, id ASC").Find(&alerts).Error
+	return alerts, err
+}
+
+func (r *budgetAlertRepository) MarkNotified(ids []int, notifiedAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&models.BudgetAlert{}).Where("id IN ?", ids).Update("notified_at", notifiedAt).Error
+}
+
+func (r *budgetAlertRepository) DeleteByBudgetID(budgetID int) error {
+	return r.db.Where("budget_id = ?", budgetID).Delete(&models.BudgetAlert{}).Error
+}
+
+// Inventory lot repository implementation
+type inventoryLotRepository struct {
+	db *DB
+}
+
+func NewInventoryLotRepository(db *DB) InventoryLotRepository {
+	return &inventoryLotRepository{db: db}
+}
+
+func (r *inventoryLotRepository) Create(lot *models.InventoryLot) error {
+	lot.CreatedAt = time.Now()
+	lot.UpdatedAt = time.Now()
+	return r.db.Create(lot).Error
+}
+
+func (r *inventoryLotRepository) GetByID(id int) (*models.InventoryLot, error) {
+	var lot models.InventoryLot
+	// Use Find instead of First to avoid LIMIT clause that ramsql doesn't support
+	err := r.db.Where("id = ?", id).Find(&lot).Error
+	if err != nil {
+		return nil, err
+	}
+	if lot.ID == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &lot, nil
+}
+
+func (r *inventoryLotRepository) GetByItemID(itemID int) ([]models.InventoryLot, error) {
+	var lots []models.InventoryLot
+	err := r.db.Where("inventory_item_id = ?", itemID).Order("received_at ASC, id ASC").Find(&lots).Error
+	return lots, err
+}
+
+// GetOpenByItemID returns the lots of an item that still hold stock, oldest first,
+// which is the order they are consumed in.
+func (r *inventoryLotRepository) GetOpenByItemID(itemID int) ([]models.InventoryLot, error) {
+	var lots []models.InventoryLot
+	err := r.db.Where("inventory_item_id = ? AND remaining_quantity > 0", itemID).
+		Order("received_at ASC, id ASC").Find(&lots).Error
+	return lots, err
+}
+
+func (r *inventoryLotRepository) GetExpiringByAccountID(accountID int, before time.Time) ([]models.InventoryLot, error) {
+	var lots []models.InventoryLot
+	err := r.db.Where("account_id = ? AND remaining_quantity > 0 AND expiration_date IS NOT NULL AND expiration_date <= ?", accountID, before).
+		Order("expiration_date ASC, id ASC").Find(&lots).Error
+	return lots, err
+}
+
+func (r *inventoryLotRepository) GetByDeliveryID(deliveryID int) (*models.InventoryLot, error) {
+	var lots []models.InventoryLot
+	// Use Find instead of First to avoid LIMIT clause that ramsql doesn't support
+	err := r.db.Where("delivery_id = ?", deliveryID).Order("id ASC").Find(&lots).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(lots) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &lots[0], nil
+}
+
+func (r *inventoryLotRepository) Update(lot *models.InventoryLot) error {
+	lot.UpdatedAt = time.Now()
+	return r.db.Save(lot).Error
+}
+
+func (r *inventoryLotRepository) Delete(id int) error {
+	return r.db.Delete(&models.InventoryLot{}, id).Error
+}
+
+// Waste log repository implementation
+type wasteLogRepository struct {
+	db *DB
+}
+
+func NewWasteLogRepository(db *DB) WasteLogRepository {
+	return &wasteLogRepository{db: db}
+}
+
+func (r *wasteLogRepository) Create(wasteLog *models.WasteLog) error {
+	wasteLog.CreatedAt = time.Now()
+	return r.db.Create(wasteLog).Error
+}
+
+func (r *wasteLogRepository) GetByID(id int) (*models.WasteLog, error) {
+	var wasteLog models.WasteLog
+	// Use Find instead of First to avoid LIMIT clause that ramsql doesn't support
+	err := r.db.Where("id = ?", id).Find(&wasteLog).Error
+	if err != nil {
+		return nil, err
+	}
+	if wasteLog.ID == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &wasteLog, nil
+}
+
+func (r *wasteLogRepository) GetByAccountIDAndDateRange(accountID int, startDate, endDate time.Time) ([]models.WasteLog, error) {
+	var wasteLogs []models.WasteLog
+	err := r.db.Where("account_id = ? AND wasted_at >= ? AND wasted_at <= ?", accountID, startDate, endDate).
+		Order("wasted_at DESC, id DESC").Find(&wasteLogs).Error
+	return wasteLogs, err
+}
+
+func (r *wasteLogRepository) GetByAccountIDAfterDate(accountID int, afterDate time.Time) ([]models.WasteLog, error) {
+	var wasteLogs []models.WasteLog
+	err := r.db.Where("account_id = ? AND wasted_at > ?", accountID, afterDate).Find(&wasteLogs).Error
+	return wasteLogs, err
+}
+
+func (r *wasteLogRepository) GetByItemIDAfterDate(itemID int, afterDate time.Time) ([]models.WasteLog, error) {
+	var wasteLogs []models.WasteLog
+	err := r.db.Where("inventory_item_id = ? AND wasted_at > ?", itemID, afterDate).Find(&wasteLogs).Error
+	return wasteLogs, err
+}
+
+func (r *wasteLogRepository) Delete(id int) error {
+	return r.db.Delete(&models.WasteLog{}, id).Error
+}
+
+// Inventory level repository implementation
+type inventoryLevelRepository struct {
+	db *DB
+}
+
+func NewInventoryLevelRepository(db *DB) InventoryLevelRepository {
+	return &inventoryLevelRepository{db: db}
+}
+
+func (r *inventoryLevelRepository) GetByAccountID(accountID int) ([]models.InventoryLevel, error) {
+	var levels []models.InventoryLevel
+	err := r.db.Where("account_id = ?", accountID).Find(&levels).Error
+	return levels, err
+}
+
+func (r *inventoryLevelRepository) GetByItemID(itemID int) (*models.InventoryLevel, error) {
+	var level models.InventoryLevel
+	// Use Find instead of First to avoid LIMIT clause that ramsql doesn't support
+	err := r.db.Where("inventory_item_id = ?", itemID).Find(&level).Error
+	if err != nil {
+		return nil, err
+	}
+	if level.ID == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &level, nil
+}
+
+// Add changes an item's level by delta in a single upsert, creating the level if needed,
+// so concurrent movements on an item without a level cannot both try to insert it
+func (r *inventoryLevelRepository) Add(accountID, itemID int, delta float64) error {
+	level := &models.InventoryLevel{AccountID: accountID, InventoryItemID: itemID, Quantity: delta, UpdatedAt: time.Now()}
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "inventory_item_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"quantity":   gorm.Expr("inventory_levels.quantity + excluded.quantity"),
+			"updated_at": gorm.Expr("excluded.updated_at"),
+		}),
+	}).Create(level).Error
+}
+
+// ReplaceForAccount swaps all levels of an account for the given levels
+func (r *inventoryLevelRepository) ReplaceForAccount(accountID int, levels []models.InventoryLevel) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("account_id = ?", accountID).Delete(&models.InventoryLevel{}).Error; err != nil {
+			return err
+		}
+		if len(levels) == 0 {
+			return nil
+		}
+		now := time.Now()
+		for i := range levels {
+			levels[i].AccountID = accountID
+			levels[i].UpdatedAt = now
+		}
+		return tx.Create(&levels).Error
+	})
+}
+
+func (r *inventoryLevelRepository) DeleteByItemID(itemID int) error {
+	return r.db.Where("inventory_item_id = ?", itemID).Delete(&models.InventoryLevel{}).Error
+}
+
+// Stock adjustment repository implementation
+type stockAdjustmentRepository struct {
+	db *DB
+}
+
+func NewStockAdjustmentRepository(db *DB) StockAdjustmentRepository {
+	return &stockAdjustmentRepository{db: db}
+}
+
+func (r *stockAdjustmentRepository) Create(adjustment *models.StockAdjustment) error {
+	adjustment.CreatedAt = time.Now()
+	return r.db.Create(adjustment).Error
+}
+
+func (r *stockAdjustmentRepository) GetByID(id int) (*models.StockAdjustment, error) {
+	var adjustment models.StockAdjustment
+	// Use Find instead of First to avoid LIMIT clause that ramsql doesn't support
+	err := r.db.Where("id = ?", id).Find(&adjustment).Error
+	if err != nil {
+		return nil, err
+	}
+	if adjustment.ID == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &adjustment, nil
+}
+
+func (r *stockAdjustmentRepository) GetByAccountIDAndDateRange(accountID int, startDate, endDate time.Time) ([]models.StockAdjustment, error) {
+	var adjustments []models.StockAdjustment
+	err := r.db.Where("account_id = ? AND adjusted_at >= ? AND adjusted_at <= ?", accountID, startDate, endDate).
+		Order("adjusted_at DESC, id DESC").Find(&adjustments).Error
+	return adjustments, err
+}
+
+func (r *stockAdjustmentRepository) GetByAccountIDAfterDate(accountID int, afterDate time.Time) ([]models.StockAdjustment, error) {
+	var adjustments []models.StockAdjustment
+	err := r.db.Where("account_id = ? AND adjusted_at > ?", accountID, afterDate).Find(&adjustments).Error
+	return adjustments, err
+}
+
+// Count session repository implementation
+type countSessionRepository struct {
+	db *DB
+}
+
+func NewCountSessionRepository(db *DB) CountSessionRepository {
+	return &countSessionRepository{db: db}
+}
+
+func (r *countSessionRepository) Create(session *models.CountSession) error {
+	session.CreatedAt = time.Now()
+	session.UpdatedAt = time.Now()
+	return r.db.Create(session).Error
+}
+
+func (r *countSessionRepository) GetByID(id int) (*models.CountSession, error) {
+	var session models.CountSession
+	// Use Find instead of First to avoid LIMIT clause that ramsql doesn't support
+	err := r.db.Where("id = ?", id).Find(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	if session.ID == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &session, nil
+}
+
+func (r *countSessionRepository) GetByAccountID(accountID int) ([]models.CountSession, error) {
+	var sessions []models.CountSession
+	err := r.db.Where("account_id = ?", accountID).Order("started_at DESC, id DESC").Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *countSessionRepository) Update(session *models.CountSession) error {
+	session.UpdatedAt = time.Now()
+	return r.db.Save(session).Error
+}
+
+// Count entry repository implementation
+type countEntryRepository struct {
+	db *DB
+}
+
+func NewCountEntryRepository(db *DB) CountEntryRepository {
+	return &countEntryRepository{db: db}
+}
+
+func (r *countEntryRepository) Create(entry *models.CountEntry) error {
+	entry.CreatedAt = time.Now()
+	entry.UpdatedAt = time.Now()
+	return r.db.Create(entry).Error
+}
+
+func (r *countEntryRepository) GetBySessionID(sessionID int) ([]models.CountEntry, error) {
+	var entries []models.CountEntry
+	err := r.db.Where("count_session_id = ?", sessionID).Order("zone ASC, inventory_item_id ASC").Find(&entries).Error
+	return entries, err
+}
+
+// GetBySessionItemAndPlace finds the count of an item at one location and zone of a session.
+// A nil location matches counts of unassigned stock.
+func (r *countEntryRepository) GetBySessionItemAndPlace(sessionID, itemID int, locationID *int, zone string) (*models.CountEntry, error) {
+	var entry models.CountEntry
+	query := r.db.Where("count_session_id = ? AND inventory_item_id = ? AND zone = ?", sessionID, itemID, zone)
+	if locationID != nil {
+		query = query.Where("storage_location_id = ?", *locationID)
+	} else {
+		query = query.Where("storage_location_id IS NULL")
+	}
+	// Use Find instead of First to avoid LIMIT clause that ramsql doesn't support
+	err := query.Find(&entry).Error
+	if err != nil {
+		return nil, err
+	}
+	if entry.ID == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &entry, nil
+}
+
+func (r *countEntryRepository) Update(entry *models.CountEntry) error {
+	entry.UpdatedAt = time.Now()
+	return r.db.Save(entry).Error
+}
+
+// Storage location repository implementation
+type storageLocationRepository struct {
+	db *DB
+}
+
+func NewStorageLocationRepository(db *DB) StorageLocationRepository {
+	return &storageLocationRepository{db: db}
+}
+
+func (r *storageLocationRepository) Create(location *models.StorageLocation) error {
+	location.CreatedAt = time.Now()
+	location.UpdatedAt = time.Now()
+	return r.db.Create(location).Error
+}
+
+func (r *storageLocationRepository) GetByID(id int) (*models.StorageLocation, error) {
+	var location models.StorageLocation
+	// Use Find instead of First to avoid LIMIT clause that ramsql doesn't support
+	err := r.db.Where("id = ?", id).Find(&location).Error
+	if err != nil {
+		return nil, err
+	}
+	if location.ID == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &location, nil
+}
+
+func (r *storageLocationRepository) GetByAccountID(accountID int) ([]models.StorageLocation, error) {
+	var locations []models.StorageLocation
+	err := r.db.Where("account_id = ?", accountID).Order("name ASC").Find(&locations).Error
+	return locations, err
+}
+
+func (r *storageLocationRepository) Update(location *models.StorageLocation) error {
+	location.UpdatedAt = time.Now()
+	return r.db.Save(location).Error
+}
+
+func (r *storageLocationRepository) Delete(id int) error {
+	return r.db.Delete(&models.StorageLocation{}, id).Error
+}
+
+// Location transfer repository implementation
+type locationTransferRepository struct {
+	db *DB
+}
+
+func NewLocationTransferRepository(db *DB) LocationTransferRepository {
+	return &locationTransferRepository{db: db}
+}
+
+func (r *locationTransferRepository) Create(transfer *models.LocationTransfer) error {
+	transfer.CreatedAt = time.Now()
+	return r.db.Create(transfer).Error
+}
+
+func (r *locationTransferRepository) GetByAccountID(accountID int) ([]models.LocationTransfer, error) {
+	var transfers []models.LocationTransfer
+	err := r.db.Where("account_id = ?", accountID).Order("transferred_at DESC, id DESC").Find(&transfers).Error
+	return transfers, err
+}
+
+func (r *locationTransferRepository) GetByAccountIDAfterDate(accountID int, afterDate time.Time) ([]models.LocationTransfer, error) {
+	var transfers []models.LocationTransfer
+	err := r.db.Where("account_id = ? AND transferred_at > ?", accountID, afterDate).Find(&transfers).Error
+	return transfers, err
+}
+
+// Stock transfer repository implementation
+type stockTransferRepository struct {
+	db *DB
+}
+
+func NewStockTransferRepository(db *DB) StockTransferRepository {
+	return &stockTransferRepository{db: db}
+}
+
+func (r *stockTransferRepository) Create(transfer *models.StockTransfer) error {
+	transfer.CreatedAt = time.Now()
+	transfer.UpdatedAt = time.Now()
+	return r.db.Create(transfer).Error
+}
+
+func (r *stockTransferRepository) GetByID(id int) (*models.StockTransfer, error) {
+	var transfer models.StockTransfer
+	// Use Find instead of First to avoid LIMIT clause that ramsql doesn't support
+	err := r.db.Where("id = ?", id).Find(&transfer).Error
+	if err != nil {
+		return nil, err
+	}
+	if transfer.ID == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &transfer, nil
+}
+
+// GetByAccountID returns the transfers an account sent or received, newest first
+func (r *stockTransferRepository) GetByAccountID(accountID int) ([]models.StockTransfer, error) {
+	var transfers []models.StockTransfer
+	err := r.db.Where("from_account_id = ? OR to_account_id = ?", accountID, accountID).Order("sent_at DESC, id DESC").Find(&transfers).Error
+	return transfers, err
+}
+
+// GetSentAfterDate returns the transfers an account sent after a date that were not cancelled
+func (r *stockTransferRepository) GetSentAfterDate(accountID int, afterDate time.Time) ([]models.StockTransfer, error) {
+	var transfers []models.StockTransfer
+	err := r.db.Where("from_account_id = ? AND sent_at > ? AND status <> ?", accountID, afterDate, models.StockTransferStatusCancelled).Find(&transfers).Error
+	return transfers, err
+}
+
+// GetReceivedAfterDate returns the transfers an account received after a date
+func (r *stockTransferRepository) GetReceivedAfterDate(accountID int, afterDate time.Time) ([]models.StockTransfer, error) {
+	var transfers []models.StockTransfer
+	err := r.db.Where("to_account_id = ? AND status = ? AND received_at > ?", accountID, models.StockTransferStatusReceived, afterDate).Find(&transfers).Error
+	return transfers, err
+}
+
+func (r *stockTransferRepository) Update(transfer *models.StockTransfer) error {
+	transfer.UpdatedAt = time.Now()
+	return r.db.Save(transfer).Error
+}
+
+// Stock transfer lot repository implementation
+type stockTransferLotRepository struct {
+	db *DB
+}
+
+func NewStockTransferLotRepository(db *DB) StockTransferLotRepository {
+	return &stockTransferLotRepository{db: db}
+}
+
+func (r *stockTransferLotRepository) Create(transferLot *models.StockTransferLot) error {
+	return r.db.Create(transferLot).Error
+}
+
+func (r *stockTransferLotRepository) GetByTransferID(transferID int) ([]models.StockTransferLot, error) {
+	var transferLots []models.StockTransferLot
+	err := r.db.Where("stock_transfer_id = ?", transferID).Order("id ASC").Find(&transferLots).Error
+	return transferLots, err
+}
+
+// Catalog category repository implementation
+type catalogCategoryRepository struct {
+	db *DB
+}
+
+func NewCatalogCategoryRepository(db *DB) CatalogCategoryRepository {
+	return &catalogCategoryRepository{db: db}
+}
+
+func (r *catalogCategoryRepository) Create(category *models.CatalogCategory) error {
+	category.CreatedAt = time.Now()
+	category.UpdatedAt = time.Now()
+	return r.db.Create(category).Error
+}
+
+func (r *catalogCategoryRepository) GetByID(id int) (*models.CatalogCategory, error) {
+	var category models.CatalogCategory
+	// Use Find instead of First to avoid LIMIT clause that ramsql doesn't support
+	err := r.db.Where("id = ?", id).Find(&category).Error
+	if err != nil {
+		return nil, err
+	}
+	if category.ID == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &category, nil
+}
+
+func (r *catalogCategoryRepository) GetByOrganizationID(organizationID int) ([]models.CatalogCategory, error) {
+	var categories []models.CatalogCategory
+	err := r.db.Where("organization_id = ?", organizationID).Order("name ASC").Find(&categories).Error
+	return categories, err
+}
+
+func (r *catalogCategoryRepository) Update(category *models.CatalogCategory) error {
+	category.UpdatedAt = time.Now()
+	return r.db.Save(category).Error
+}
+
+// Catalog item repository implementation
+type catalogItemRepository struct {
+	db *DB
+}
+
+func NewCatalogItemRepository(db *DB) CatalogItemRepository {
+	return &catalogItemRepository{db: db}
+}
+
+func (r *catalogItemRepository) Create(item *models.CatalogItem) error {
+	item.CreatedAt = time.Now()
+	item.UpdatedAt = time.Now()
+	return r.db.Create(item).Error
+}
+
+func (r *catalogItemRepository) GetByID(id int) (*models.CatalogItem, error) {
+	var item models.CatalogItem
+	// Use Find instead of First to avoid LIMIT clause that ramsql doesn't support
+	err := r.db.Where("id = ?", id).Find(&item).Error
+	if err != nil {
+		return nil, err
+	}
+	if item.ID == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &item, nil
+}
+
+func (r *catalogItemRepository) GetByOrganizationID(organizationID int) ([]models.CatalogItem, error) {
+	var items []models.CatalogItem
+	err := r.db.Where("organization_id = ?", organizationID).Order("name ASC").Find(&items).Error
+	return items, err
+}
+
+func (r *catalogItemRepository) Update(item *models.CatalogItem) error {
+	item.UpdatedAt = time.Now()
+	return r.db.Save(item).Error
+}
+
+// Catalog menu item repository implementation
+type catalogMenuItemRepository struct {
+	db *DB
+}
+
+func NewCatalogMenuItemRepository(db *DB) CatalogMenuItemRepository {
+	return &catalogMenuItemRepository{db: db}
+}
+
+func (r *catalogMenuItemRepository) Create(item *models.CatalogMenuItem) error {
+	item.CreatedAt = time.Now()
+	item.UpdatedAt = time.Now()
+	return r.db.Create(item).Error
+}
+
+func (r *catalogMenuItemRepository) GetByID(id int) (*models.CatalogMenuItem, error) {
+	var item models.CatalogMenuItem
+	// Use Find instead of First to avoid LIMIT clause that ramsql doesn't support
+	err := r.db.Where("id = ?", id).Find(&item).Error
+	if err != nil {
+		return nil, err
+	}
+	if item.ID == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &item, nil
+}
+
+func (r *catalogMenuItemRepository) GetByOrganizationID(organizationID int) ([]models.CatalogMenuItem, error) {
+	var items []models.CatalogMenuItem
+	err := r.db.Where("organization_id = ?", organizationID).Order("name ASC").Find(&items).Error
+	return items, err
+}
+
+func (r *catalogMenuItemRepository) Update(item *models.CatalogMenuItem) error {
+	item.UpdatedAt = time.Now()
+	return r.db.Save(item).Error
+}
+
+// Catalog recipe repository implementation
+type catalogRecipeRepository struct {
+	db *DB
+}
+
+func NewCatalogRecipeRepository(db *DB) CatalogRecipeRepository {
+	return &catalogRecipeRepository{db: db}
+}
+
+func (r *catalogRecipeRepository) GetByCatalogMenuItemID(catalogMenuItemID int) ([]models.CatalogRecipeIngredient, error) {
+	var ingredients []models.CatalogRecipeIngredient
+	err := r.db.Where("catalog_menu_item_id = ?", catalogMenuItemID).Order("id ASC").Find(&ingredients).Error
+	return ingredients, err
+}
+
+// ReplaceIngredients replaces the whole recipe of a catalog menu item
+func (r *catalogRecipeRepository) ReplaceIngredients(catalogMenuItemID int, ingredients []models.CatalogRecipeIngredient) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("catalog_menu_item_id = ?", catalogMenuItemID).Delete(&models.CatalogRecipeIngredient{}).Error; err != nil {
+			return err
+		}
+		for i := range ingredients {
+			ingredients[i].ID = 0
+			ingredients[i].CatalogMenuItemID = catalogMenuItemID
+			if err := tx.Create(&ingredients[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Catalog subscription repository implementation
+type catalogSubscriptionRepository struct {
+	db *DB
+}
+
+func NewCatalogSubscriptionRepository(db *DB) CatalogSubscriptionRepository {
+	return &catalogSubscriptionRepository{db: db}
+}
+
+func (r *catalogSubscriptionRepository) Create(subscription *models.CatalogSubscription) error {
+	subscription.CreatedAt = time.Now()
+	subscription.UpdatedAt = time.Now()
+	return r.db.Create(subscription).Error
+}
+
+func (r *catalogSubscriptionRepository) GetByAccountID(accountID int) (*models.CatalogSubscription, error) {
+	var subscription models.CatalogSubscription
+	// Use Find instead of First to avoid LIMIT clause that ramsql doesn't support
+	err := r.db.Where("account_id = ?", accountID).Find(&subscription).Error
+	if err != nil {
+		return nil, err
+	}
+	if subscription.ID == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &subscription, nil
+}
+
+func (r *catalogSubscriptionRepository) GetActiveByOrganizationID(organizationID int) ([]models.CatalogSubscription, error) {
+	var subscriptions []models.CatalogSubscription
+	err := r.db.Where("organization_id = ? AND is_active = ?", organizationID, true).Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *catalogSubscriptionRepository) Update(subscription *models.CatalogSubscription) error {
+	subscription.UpdatedAt = time.Now()
+	return r.db.Save(subscription).Error
+}
+
+// Catalog sync conflict repository implementation
+type catalogSyncConflictRepository struct {
+	db *DB
+}
+
+func NewCatalogSyncConflictRepository(db *DB) CatalogSyncConflictRepository {
+	return &catalogSyncConflictRepository{db: db}
+}
+
+func (r *catalogSyncConflictRepository) Create(conflict *models.CatalogSyncConflict) error {
+	return r.db.Create(conflict).Error
+}
+
+func (r *catalogSyncConflictRepository) GetByAccountID(accountID int) ([]models.CatalogSyncConflict, error) {
+	var conflicts []models.CatalogSyncConflict
+	err := r.db.Where("account_id = ?", accountID).Order("entity_type ASC, catalog_entity_id ASC").Find(&conflicts).Error
+	return conflicts, err
+}
+
+func (r *catalogSyncConflictRepository) DeleteByAccountID(accountID int) error {
+	return r.db.Where("account_id = ?", accountID).Delete(&models.CatalogSyncConflict{}).Error
+}
+
+// Search repository implementation
+type searchRepository struct {
+	db *DB
+}
+
+func NewSearchRepository(db *DB) SearchRepository {
+	return &searchRepository{db: db}
+}
+
+// Search finds up to limit records of each kind whose text columns contain every term.
+// Terms must consist of letters and digits only.
+func (r *searchRepository) Search(accountID int, terms []string, limit int) (*SearchCandidates, error) {
+	candidates := &SearchCandidates{}
+	kinds := []struct {
+		dest    interface{}
+		columns []string
+	}{
+		{&candidates.InventoryItems, []string{"name", "preferred_vendor"}},
+		{&candidates.MenuItems, []string{"name", "category"}},
+		{&candidates.Categories, []string{"name", "description"}},
+		{&candidates.Vendors, []string{"name", "contact_name", "notes"}},
+		{&candidates.Orders, []string{"notes"}},
+		{&candidates.Deliveries, []string{"vendor", "lot_number", "notes"}},
+	}
+
+	for _, kind := range kinds {
+		query := r.db.Where("account_id = ?", accountID)
+		if r.db.Dialector.Name() == "postgres" {
+			query = r.matchFullText(query, terms, kind.columns)
+		} else {
+			query = r.matchLike(query, terms, kind.columns)
+		}
+		if err := query.Limit(limit).Find(kind.dest).Error; err != nil {
+			return nil, err
+		}
+	}
+	return candidates, nil
+}
+
+// matchFullText uses Postgres full-text search, where each term matches the start of a word
+// in any of the columns, and puts the best ranked records first
+func (r *searchRepository) matchFullText(query *gorm.DB, terms []string, columns []string) *gorm.DB {
+	coalesced := make([]string, len(columns))
+	for i, column := range columns {
+		coalesced[i] = "coalesce(" + column + ", '')"
+	}
+	document := "to_tsvector('simple', " + strings.Join(coalesced, " || ' ' || ") + ")"
+
+	prefixes := make([]string, len(terms))
+	for i, term := range terms {
+		prefixes[i] = term + ":*"
+	}
+	tsQuery := strings.Join(prefixes, " & ")
+
+	return query.Where(document+" @@ to_tsquery('simple', ?)", tsQuery).
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: "ts_rank(" + document + ", to_tsquery('simple', ?)) DESC", Vars: []interface{}{tsQuery}, WithoutParentheses: true}})
+}
+
+// matchLike requires each term to appear anywhere in one of the columns, ignoring case.
+// It is the fallback for databases without full-text search, such as SQLite in tests.
+func (r *searchRepository) matchLike(query *gorm.DB, terms []string, columns []string) *gorm.DB {
+	for _, term := range terms {
+		conditions := make([]string, len(columns))
+		args := make([]interface{}, len(columns))
+		for i, column := range columns {
+			conditions[i] = "LOWER(" + column + ") LIKE ? ESCAPE '\\'"
+			args[i] = searchPattern(term)
+		}
+		query = query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+	return query
+}
+
+// Email schedule repository implementation
+type emailScheduleRepository struct {
+	db *DB
+}
+
+func NewEmailScheduleRepository(db *DB) EmailScheduleRepository {
+	return &emailScheduleRepository{db: db}
+}
+
+func (r *emailScheduleRepository) Create(schedule *models.EmailSchedule) error {
+	schedule.CreatedAt = time.Now()
+	schedule.UpdatedAt = time.Now()
+	return r.db.Create(schedule).Error
+}
+
+func (r *emailScheduleRepository) GetByID(id int) (*models.EmailSchedule, error) {
+	var schedule models.EmailSchedule
+	// Use Find instead of First to avoid LIMIT clause that ramsql doesn't support
+	err := r.db.Where("id = ?", id).Find(&schedule).Error
+	if err != nil {
+		return nil, err
+	}
+	if schedule.ID == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &schedule, nil
+}
+
+func (r *emailScheduleRepository) GetByAccountID(accountID int) ([]models.EmailSchedule, error) {
+	var schedules []models.EmailSchedule
+	err := r.db.Where("account_id = ?", accountID).Order("created_at DESC").Find(&schedules).Error
+	return schedules, err
+}
+
+func (r *emailScheduleRepository) GetByAccountIDAndType(accountID int, emailType string) (*models.EmailSchedule, error) {
+	var schedule models.EmailSchedule
+	err := r.db.Where("account_id = ? AND email_type = ?", accountID, emailType).First(&schedule).Error
 	if err != nil {
 		return nil, err
 	}
@@ -882,6 +2460,48 @@ func (r *emailScheduleRepository) UpdateLastSentAt(id int, lastSentAt time.Time)
 	return r.db.Model(&models.EmailSchedule{}).Where("id = ?", id).Update("last_sent_at", lastSentAt).Error
 }
 
+// Email template repository implementation
+type emailTemplateRepository struct {
+	db *DB
+}
+
+func NewEmailTemplateRepository(db *DB) EmailTemplateRepository {
+	return &emailTemplateRepository{db: db}
+}
+
+func (r *emailTemplateRepository) Create(emailTemplate *models.EmailTemplate) error {
+	emailTemplate.CreatedAt = time.Now()
+	emailTemplate.UpdatedAt = time.Now()
+	return r.db.Create(emailTemplate).Error
+}
+
+func (r *emailTemplateRepository) GetByAccountID(accountID int) ([]models.EmailTemplate, error) {
+	var emailTemplates []models.EmailTemplate
+	err := r.db.Where("account_id = ?", accountID).Order("name ASC, locale ASC").Find(&emailTemplates).Error
+	return emailTemplates, err
+}
+
+func (r *emailTemplateRepository) GetByAccountIDNameAndLocale(accountID int, name, locale string) (*models.EmailTemplate, error) {
+	var emailTemplate models.EmailTemplate
+	err := r.db.Where("account_id = ? AND name = ? AND locale = ?", accountID, name, locale).Find(&emailTemplate).Error
+	if err != nil {
+		return nil, err
+	}
+	if emailTemplate.ID == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &emailTemplate, nil
+}
+
+func (r *emailTemplateRepository) Update(emailTemplate *models.EmailTemplate) error {
+	emailTemplate.UpdatedAt = time.Now()
+	return r.db.Save(emailTemplate).Error
+}
+
+func (r *emailTemplateRepository) Delete(id int) error {
+	return r.db.Delete(&models.EmailTemplate{}, id).Error
+}
+
 // Business logic functions
 func (db *DB) GetInventoryVariance(accountID int, startDate, endDate time.Time) (map[int]float64, error) {
 	// This will be implemented when we add Toast POS integration
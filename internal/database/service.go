@@ -9,7 +9,14 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/mnadev/pantryos/internal/models"
 	"gorm.io/gorm"
@@ -24,6 +31,8 @@ import (
 // data access layer, ensuring that business rules are enforced and data integrity
 // is maintained across all operations.
 type Service struct {
+	// db is the connection the repositories use, kept for running operations in a transaction
+	db *DB
 	// organizations handles operations for the top-level multi-tenant entities
 	organizations OrganizationRepository
 	// accounts handles operations for business locations within organizations
@@ -48,6 +57,61 @@ type Service struct {
 	categories CategoryRepository
 	// emailSchedules handles email scheduling configuration
 	emailSchedules EmailScheduleRepository
+	// emailTemplates handles account overrides of the built-in email templates
+	emailTemplates EmailTemplateRepository
+	// vendors handles supplier records referenced by items, deliveries, and orders
+	vendors VendorRepository
+	// orders handles purchase orders placed with vendors
+	orders OrderRepository
+	// orderItems handles the individual lines on purchase orders
+	orderItems OrderItemRepository
+	// orderDispatches records purchase orders emailed to vendors
+	orderDispatches OrderDispatchRepository
+	// receipts and receiptLines record goods received against orders
+	receipts     ReceiptRepository
+	receiptLines ReceiptLineRepository
+	// invoices and invoiceLines handle vendor invoices matched against orders and deliveries
+	invoices     InvoiceRepository
+	invoiceLines InvoiceLineRepository
+	// priceHistory handles the unit prices recorded from deliveries
+	priceHistory PriceHistoryRepository
+	// priceAlerts handles notifications about vendor price changes
+	priceAlerts PriceAlertRepository
+	// budgets handles the purchasing budgets of categories
+	budgets BudgetRepository
+	// budgetAlerts handles notifications about spend crossing budget thresholds
+	budgetAlerts BudgetAlertRepository
+	// inventoryLots handles received stock tracked by lot and expiration date
+	inventoryLots InventoryLotRepository
+	// wasteLogs handles discarded stock recorded by staff
+	wasteLogs WasteLogRepository
+	// stockAdjustments handles ad-hoc stock changes recorded by staff
+	stockAdjustments StockAdjustmentRepository
+	// inventoryLevels handles the maintained current stock of each inventory item
+	inventoryLevels InventoryLevelRepository
+	// countSessions handles guided physical stock counts
+	countSessions CountSessionRepository
+	// countEntries handles the per-zone counts recorded during count sessions
+	countEntries CountEntryRepository
+	// storageLocations handles the places within an account where stock is kept
+	storageLocations StorageLocationRepository
+	// locationTransfers handles stock moved between storage locations
+	locationTransfers LocationTransferRepository
+	// stockTransfers handles stock moved between accounts of an organization
+	stockTransfers StockTransferRepository
+	// stockTransferLots records the lots each stock transfer took its stock from
+	stockTransferLots StockTransferLotRepository
+	// catalogCategories, catalogItems, catalogMenuItems and catalogRecipes hold organization master catalogs
+	catalogCategories CatalogCategoryRepository
+	catalogItems      CatalogItemRepository
+	catalogMenuItems  CatalogMenuItemRepository
+	catalogRecipes    CatalogRecipeRepository
+	// catalogSubscriptions handles the accounts that receive catalog updates
+	catalogSubscriptions CatalogSubscriptionRepository
+	// catalogSyncConflicts handles catalog changes that could not be applied to an account
+	catalogSyncConflicts CatalogSyncConflictRepository
+	// search finds records of an account by the text they contain
+	search SearchRepository
 }
 
 // NewService creates a new database service with all repositories initialized.
@@ -61,21 +125,66 @@ type Service struct {
 //   - *Service: A fully initialized service instance ready for business operations
 func NewService(db *DB) *Service {
 	return &Service{
-		organizations:      NewOrganizationRepository(db),
-		accounts:           NewAccountRepository(db),
-		users:              NewUserRepository(db),
-		inventoryItems:     NewInventoryItemRepository(db),
-		menuItems:          NewMenuItemRepository(db),
-		deliveries:         NewDeliveryRepository(db),
-		inventorySnapshots: NewInventorySnapshotRepository(db),
-		sales:              NewSaleRepository(db),
-		recipes:            NewRecipeRepository(db),
-		accountInvitations: NewAccountInvitationRepository(db),
-		categories:         NewCategoryRepository(db),
-		emailSchedules:     NewEmailScheduleRepository(db),
+		db:                   db,
+		organizations:        NewOrganizationRepository(db),
+		accounts:             NewAccountRepository(db),
+		users:                NewUserRepository(db),
+		inventoryItems:       NewInventoryItemRepository(db),
+		menuItems:            NewMenuItemRepository(db),
+		deliveries:           NewDeliveryRepository(db),
+		inventorySnapshots:   NewInventorySnapshotRepository(db),
+		sales:                NewSaleRepository(db),
+		recipes:              NewRecipeRepository(db),
+		accountInvitations:   NewAccountInvitationRepository(db),
+		categories:           NewCategoryRepository(db),
+		emailSchedules:       NewEmailScheduleRepository(db),
+		emailTemplates:       NewEmailTemplateRepository(db),
+		vendors:              NewVendorRepository(db),
+		orders:               NewOrderRepository(db),
+		orderItems:           NewOrderItemRepository(db),
+		orderDispatches:      NewOrderDispatchRepository(db),
+		receipts:             NewReceiptRepository(db),
+		receiptLines:         NewReceiptLineRepository(db),
+		invoices:             NewInvoiceRepository(db),
+		invoiceLines:         NewInvoiceLineRepository(db),
+		priceHistory:         NewPriceHistoryRepository(db),
+		priceAlerts:          NewPriceAlertRepository(db),
+		budgets:              NewBudgetRepository(db),
+		budgetAlerts:         NewBudgetAlertRepository(db),
+		inventoryLots:        NewInventoryLotRepository(db),
+		wasteLogs:            NewWasteLogRepository(db),
+		stockAdjustments:     NewStockAdjustmentRepository(db),
+		inventoryLevels:      NewInventoryLevelRepository(db),
+		countSessions:        NewCountSessionRepository(db),
+		countEntries:         NewCountEntryRepository(db),
+		storageLocations:     NewStorageLocationRepository(db),
+		locationTransfers:    NewLocationTransferRepository(db),
+		stockTransfers:       NewStockTransferRepository(db),
+		stockTransferLots:    NewStockTransferLotRepository(db),
+		catalogCategories:    NewCatalogCategoryRepository(db),
+		catalogItems:         NewCatalogItemRepository(db),
+		catalogMenuItems:     NewCatalogMenuItemRepository(db),
+		catalogRecipes:       NewCatalogRecipeRepository(db),
+		catalogSubscriptions: NewCatalogSubscriptionRepository(db),
+		catalogSyncConflicts: NewCatalogSyncConflictRepository(db),
+		search:               NewSearchRepository(db),
 	}
 }
 
+// DB returns the database connection the service operates on, so that other
+// components can be built on the same connection.
+func (s *Service) DB() *DB {
+	return s.db
+}
+
+// withTransaction runs fn with a service whose repositories all share one database
+// transaction. The transaction is committed when fn returns nil and rolled back otherwise.
+func (s *Service) withTransaction(fn func(tx *Service) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return fn(NewService(&DB{tx}))
+	})
+}
+
 // Organization operations
 // These methods handle the top-level entity in the multi-tenant architecture.
 // Organizations are the parent entities that contain multiple business accounts
@@ -497,6 +606,11 @@ func (s *Service) CreateInventoryItem(item *models.InventoryItem) error {
 		}
 	}
 
+	// Link the preferred vendor to a vendor record
+	if err := s.linkItemVendor(item); err != nil {
+		return err
+	}
+
 	return s.inventoryItems.Create(item)
 }
 
@@ -528,14 +642,18 @@ func (s *Service) GetInventoryItemsByAccount(accountID int) ([]models.InventoryI
 	return s.inventoryItems.GetByAccountID(accountID)
 }
 
-// InventoryItemWithStock represents an inventory item with its current stock level
+// InventoryItemWithStock represents an inventory item with its current stock level.
+// Its wastage rate is computed when the item is read, as sales change the usage it is
+// measured against.
 type InventoryItemWithStock struct {
 	models.InventoryItem
 	CurrentStock float64 `json:"current_stock"`
 }
 
 // GetInventoryItemsWithCurrentStock retrieves all inventory items for a specific account
-// along with their current stock levels calculated from the latest inventory snapshot.
+// along with their current stock levels. Stock is read from the maintained inventory
+// levels, which every stock movement keeps up to date; see calculateCurrentStock for
+// how the levels are derived from history.
 // This method provides a comprehensive view of inventory status for the frontend.
 //
 // Parameters:
@@ -545,12 +663,66 @@ type InventoryItemWithStock struct {
 //   - []InventoryItemWithStock: List of inventory items with current stock levels
 //   - error: Any error that occurred during retrieval
 func (s *Service) GetInventoryItemsWithCurrentStock(accountID int) ([]InventoryItemWithStock, error) {
-	// === LANGKAH DASAR: Ambil semua master item ===
 	items, err := s.inventoryItems.GetByAccountID(accountID)
 	if err != nil {
 		return nil, err
 	}
+	return s.withCurrentStock(accountID, items)
+}
+
+// ListInventoryItemsWithCurrentStock retrieves one page of an account's inventory items,
+// filtered and sorted as requested, along with their current stock levels.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//   - list: The page, sort order and filters (search, category, vendor) to apply
+//
+// Returns:
+//   - []InventoryItemWithStock: The page of inventory items with current stock levels
+//   - Page: Pagination metadata for the page
+//   - error: Any error that occurred during retrieval
+func (s *Service) ListInventoryItemsWithCurrentStock(accountID int, list ListQuery) ([]InventoryItemWithStock, Page, error) {
+	items, page, err := s.inventoryItems.List(accountID, list)
+	if err != nil {
+		return nil, Page{}, err
+	}
+	itemsWithStock, err := s.withCurrentStock(accountID, items)
+	return itemsWithStock, page, err
+}
+
+// withCurrentStock pairs inventory items of an account with their inventory levels and
+// current wastage rates
+func (s *Service) withCurrentStock(accountID int, items []models.InventoryItem) ([]InventoryItemWithStock, error) {
+	levels, err := s.inventoryLevels.GetByAccountID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	stockMap := make(map[int]float64, len(levels))
+	for _, level := range levels {
+		stockMap[level.InventoryItemID] = level.Quantity
+	}
+	rates, err := s.wastageRates(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]InventoryItemWithStock, len(items))
+	for i, item := range items {
+		item.WastageRate = rates[item.ID]
+		result[i] = InventoryItemWithStock{
+			InventoryItem: item,
+			CurrentStock:  stockMap[item.ID],
+		}
+	}
+
+	return result, nil
+}
 
+// calculateCurrentStock derives the current stock of every item of an account from history:
+// the latest inventory snapshot, plus deliveries and less sales and waste recorded since
+// that snapshot, with stock adjustments applied. Stock transfers count against the sender
+// when sent and for the receiver when received. Inventory levels are rebuilt from this.
+func (s *Service) calculateCurrentStock(accountID int) (map[int]float64, error) {
 	// Buat map untuk menampung stok yang akan dihitung.
 	// Ini akan menjadi "buku besar" sementara kita.
 	stockMap := make(map[int]float64)
@@ -582,47 +754,385 @@ func (s *Service) GetInventoryItemsWithCurrentStock(accountID int) ([]InventoryI
 	}
 
 	// === LANGKAH 3: Kurangi Semua Penjualan (SALE) Sejak Snapshot ===
-	// Asumsi: Anda membuat fungsi ini di repository sale Anda.
-	sales, err := s.sales.GetByAccountIDAfterDate(accountID, snapshotTimestamp)
+	// Resep setiap menu item hanya diambil sekali, bukan sekali per item terjual.
+	consumed, err := s.salesConsumptionSince(accountID, snapshotTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	for itemID, quantity := range consumed {
+		stockMap[itemID] -= quantity
+	}
+
+	// === LANGKAH 4: Kurangi Semua Pembuangan (WASTE) Sejak Snapshot ===
+	wasteLogs, err := s.wasteLogs.GetByAccountIDAfterDate(accountID, snapshotTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	for _, wasteLog := range wasteLogs {
+		stockMap[wasteLog.InventoryItemID] -= wasteLog.Quantity
+	}
+
+	// === LANGKAH 5: Kurangi Transfer Keluar ke Lokasi Lain Sejak Snapshot ===
+	sentTransfers, err := s.stockTransfers.GetSentAfterDate(accountID, snapshotTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	for _, transfer := range sentTransfers {
+		stockMap[transfer.FromInventoryItemID] -= transfer.Quantity
+	}
+
+	// === LANGKAH 6: Tambahkan Transfer Masuk yang Sudah Diterima Sejak Snapshot ===
+	receivedTransfers, err := s.stockTransfers.GetReceivedAfterDate(accountID, snapshotTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	for _, transfer := range receivedTransfers {
+		if transfer.ToInventoryItemID != nil {
+			stockMap[*transfer.ToInventoryItemID] += transfer.ReceivedQuantity
+		}
+	}
+
+	// === LANGKAH 7: Terapkan Penyesuaian Stok (ADJUSTMENT) Sejak Snapshot ===
+	adjustments, err := s.stockAdjustments.GetByAccountIDAfterDate(accountID, snapshotTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	for _, adjustment := range adjustments {
+		stockMap[adjustment.InventoryItemID] += adjustment.Quantity
+	}
+
+	return stockMap, nil
+}
+
+// Inventory level operations
+// These methods maintain the current stock table. Every stock movement adjusts the level
+// of its item in the same transaction that records the movement; snapshots, and history
+// written outside the service, are folded in by rebuilding an account's levels.
+
+// RebuildInventoryLevels recomputes the current stock of every item of an account from its
+// snapshots and movements, and replaces the account's inventory levels with the result.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//
+// Returns:
+//   - error: Any error that occurred during calculation or the update
+func (s *Service) RebuildInventoryLevels(accountID int) error {
+	items, err := s.inventoryItems.GetByAccountID(accountID)
+	if err != nil {
+		return err
+	}
+	stock, err := s.calculateCurrentStock(accountID)
+	if err != nil {
+		return err
+	}
+
+	levels := make([]models.InventoryLevel, 0, len(items))
+	for _, item := range items {
+		levels = append(levels, models.InventoryLevel{InventoryItemID: item.ID, Quantity: stock[item.ID]})
+	}
+	return s.inventoryLevels.ReplaceForAccount(accountID, levels)
+}
+
+// RebuildAllInventoryLevels rebuilds the inventory levels of every account.
+//
+// Returns:
+//   - int: The number of accounts rebuilt
+//   - error: The first error that occurred; accounts before it stay rebuilt
+func (s *Service) RebuildAllInventoryLevels() (int, error) {
+	accounts, err := s.accounts.GetAll()
+	if err != nil {
+		return 0, err
+	}
+
+	for i, account := range accounts {
+		if err := s.RebuildInventoryLevels(account.ID); err != nil {
+			return i, fmt.Errorf("failed to rebuild inventory levels for account %d: %w", account.ID, err)
+		}
+	}
+	return len(accounts), nil
+}
+
+// applyStockMovement adds a stock movement to its item's inventory level. A movement at
+// or before the account's latest snapshot is already part of that count and leaves the
+// level unchanged, exactly as calculateCurrentStock ignores it.
+func (s *Service) applyStockMovement(accountID, itemID int, at time.Time, delta float64) error {
+	if delta == 0 {
+		return nil
+	}
+
+	snapshotTimestamp := time.Time{}
+	latestSnapshot, err := s.inventorySnapshots.GetLatestByAccountID(accountID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err == nil {
+		snapshotTimestamp = latestSnapshot.Timestamp
+	}
+	if !at.After(snapshotTimestamp) {
+		return nil
+	}
+
+	return s.inventoryLevels.Add(accountID, itemID, delta)
+}
+
+// RecordSale records a sale with the menu items sold and draws their ingredients from
+// inventory through the menu items' recipes, all in one transaction.
+//
+// Parameters:
+//   - sale: The sale to record, including its items
+//
+// Returns:
+//   - error: Any error that occurred during validation or creation
+//
+// Business rules:
+//   - Account must exist and the sale must contain at least one item
+//   - Every item must have a positive quantity and a menu item of the same account
+//   - SaleDate defaults to now if not provided
+//   - Ingredients leave the oldest lots first, like any other consumption
+func (s *Service) RecordSale(sale *models.Sale) error {
+	if _, err := s.accounts.GetByID(sale.AccountID); err != nil {
+		return errors.New("invalid account ID")
+	}
+	if len(sale.Items) == 0 {
+		return errors.New("sale must contain at least one item")
+	}
+	for _, saleItem := range sale.Items {
+		if saleItem.Quantity <= 0 {
+			return errors.New("sale item quantities must be positive")
+		}
+		menuItem, err := s.menuItems.GetByID(int(saleItem.MenuItemID))
+		if err != nil || menuItem.AccountID != sale.AccountID {
+			return fmt.Errorf("invalid menu item ID: %d", saleItem.MenuItemID)
+		}
+	}
+	if sale.SaleDate.IsZero() {
+		sale.SaleDate = time.Now()
+	}
+
+	return s.withTransaction(func(tx *Service) error {
+		if err := tx.sales.Create(sale); err != nil {
+			return err
+		}
+
+		// Each menu item's recipe is looked up once, however often it appears on the sale
+		recipes := make(map[uint][]models.RecipeIngredient)
+		consumed := make(map[int]float64)
+		for _, saleItem := range sale.Items {
+			ingredients, ok := recipes[saleItem.MenuItemID]
+			if !ok {
+				var err error
+				if ingredients, err = tx.recipes.GetIngredientsByMenuItemID(saleItem.MenuItemID); err != nil {
+					return err
+				}
+				recipes[saleItem.MenuItemID] = ingredients
+			}
+			for _, ingredient := range ingredients {
+				consumed[ingredient.InventoryItemID] += ingredient.Quantity * float64(saleItem.Quantity)
+			}
+		}
+
+		for itemID, quantity := range consumed {
+			if err := tx.applyStockMovement(sale.AccountID, itemID, sale.SaleDate, -quantity); err != nil {
+				return err
+			}
+			if _, err := tx.ConsumeFromLots(itemID, quantity); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Ledger movement type constants
+const (
+	LedgerMovementSnapshot    = "snapshot"
+	LedgerMovementDelivery    = "delivery"
+	LedgerMovementSale        = "sale"
+	LedgerMovementWaste       = "waste"
+	LedgerMovementAdjustment  = "adjustment"
+	LedgerMovementTransferOut = "transfer_out"
+	LedgerMovementTransferIn  = "transfer_in"
+)
+
+// InventoryLedger explains the stock of an inventory item as a chronological list of
+// movements, each with the balance after it
+type InventoryLedger struct {
+	InventoryItemID int              `json:"inventory_item_id"`
+	Unit            string           `json:"unit"`
+	StartsAt        time.Time        `json:"starts_at"` // Time of the count the ledger starts from; zero when no count precedes it
+	Movements       []LedgerMovement `json:"movements"`
+	Balance         float64          `json:"balance"` // Stock after the last movement, which is the item's current stock
+}
+
+// LedgerMovement is a single change to the stock of an inventory item
+type LedgerMovement struct {
+	Type        string    `json:"type"`
+	OccurredAt  time.Time `json:"occurred_at"`
+	Quantity    float64   `json:"quantity"` // Signed change in stock; for counts, the difference from the running balance
+	Balance     float64   `json:"balance"`
+	ReferenceID int       `json:"reference_id"` // ID of the snapshot, delivery, sale, waste entry, adjustment, or transfer
+	Description string    `json:"description"`
+}
+
+// GetInventoryItemLedger lists every movement of an inventory item's stock with its running
+// balance, using the same sources as GetInventoryItemsWithCurrentStock so the final balance
+// matches the item's current stock.
+//
+// Parameters:
+//   - itemID: The unique identifier of the inventory item
+//   - since: The date the ledger should cover from; zero starts from the latest count
+//
+// Returns:
+//   - *InventoryLedger: The movements with their running balances
+//   - error: Any error that occurred during retrieval
+//
+// Business rules:
+//   - A balance is only known from a count, so the ledger starts at the latest snapshot taken
+//     at or before since; without such a snapshot it starts from zero at the beginning
+//   - Later snapshots reset the balance and are listed with the difference they made
+//   - Sales are listed once per menu item sold, with the quantity consumed through its recipe
+func (s *Service) GetInventoryItemLedger(itemID int, since time.Time) (*InventoryLedger, error) {
+	item, err := s.inventoryItems.GetByID(itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Snapshots are returned newest first; the ledger starts at the first one not after since
+	snapshots, err := s.inventorySnapshots.GetByAccountID(item.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	ledger := &InventoryLedger{InventoryItemID: item.ID, Unit: item.Unit, Movements: []LedgerMovement{}}
+	for _, snapshot := range snapshots {
+		if since.IsZero() || !snapshot.Timestamp.After(since) {
+			ledger.StartsAt = snapshot.Timestamp
+			break
+		}
+	}
+
+	var movements []LedgerMovement
+	for _, snapshot := range snapshots {
+		if snapshot.Timestamp.Before(ledger.StartsAt) {
+			continue
+		}
+		// A snapshot's quantity is its count until the running balance is known
+		movements = append(movements, LedgerMovement{Type: LedgerMovementSnapshot, OccurredAt: snapshot.Timestamp, Quantity: snapshot.Counts[item.ID], ReferenceID: snapshot.ID, Description: "Stock count"})
+	}
+
+	deliveries, err := s.deliveries.GetByAccountIDAfterDate(item.AccountID, ledger.StartsAt)
 	if err != nil {
 		return nil, err
 	}
+	for _, delivery := range deliveries {
+		if delivery.InventoryItemID == item.ID {
+			movements = append(movements, LedgerMovement{Type: LedgerMovementDelivery, OccurredAt: delivery.DeliveryDate, Quantity: delivery.Quantity, ReferenceID: delivery.ID, Description: "Delivery from " + delivery.Vendor})
+		}
+	}
 
-	// Ini adalah bagian yang paling kompleks.
-	// Untuk setiap item yang terjual, kita perlu tahu resepnya untuk mengurangi stok bahan baku.
+	sales, err := s.sales.GetByAccountIDAfterDate(item.AccountID, ledger.StartsAt)
+	if err != nil {
+		return nil, err
+	}
+	// Cache recipes and menu item names so each menu item is only looked up once
+	recipes := make(map[uint][]models.RecipeIngredient)
+	menuItemNames := make(map[uint]string)
 	for _, sale := range sales {
 		for _, saleItem := range sale.Items {
-			// Asumsi: Anda punya fungsi untuk mengambil resep sebuah menu item.
-			// Ini perlu dioptimalkan agar tidak query ke DB di dalam loop (N+1 problem).
-			recipeIngredients, err := s.recipes.GetIngredientsByMenuItemID(saleItem.MenuItemID)
-			if err != nil {
-				// Log error tapi jangan hentikan proses, agar stok lain tetap terhitung
-				log.Printf("Warning: could not get recipe for menu item %d: %v", saleItem.MenuItemID, err)
-				continue
+			ingredients, ok := recipes[saleItem.MenuItemID]
+			if !ok {
+				ingredients, err = s.recipes.GetIngredientsByMenuItemID(saleItem.MenuItemID)
+				if err != nil {
+					log.Printf("Warning: could not get recipe for menu item %d: %v", saleItem.MenuItemID, err)
+				}
+				recipes[saleItem.MenuItemID] = ingredients
 			}
 
-			for _, ingredient := range recipeIngredients {
-				totalConsumed := ingredient.Quantity * float64(saleItem.Quantity)
-				stockMap[int(ingredient.InventoryItemID)] -= totalConsumed
+			for _, ingredient := range ingredients {
+				if ingredient.InventoryItemID != item.ID {
+					continue
+				}
+				name, ok := menuItemNames[saleItem.MenuItemID]
+				if !ok {
+					name = fmt.Sprintf("menu item %d", saleItem.MenuItemID)
+					if menuItem, err := s.menuItems.GetByID(int(saleItem.MenuItemID)); err == nil {
+						name = menuItem.Name
+					}
+					menuItemNames[saleItem.MenuItemID] = name
+				}
+				movements = append(movements, LedgerMovement{
+					Type:        LedgerMovementSale,
+					OccurredAt:  sale.SaleDate,
+					Quantity:    -ingredient.Quantity * float64(saleItem.Quantity),
+					ReferenceID: int(sale.ID),
+					Description: fmt.Sprintf("Sold %d x %s", saleItem.Quantity, name),
+				})
 			}
 		}
 	}
 
-	// === LANGKAH FINAL: Gabungkan Hasil ===
-	result := make([]InventoryItemWithStock, len(items))
-	for i, item := range items {
-		currentStock := 0.0
-		if stock, exists := stockMap[item.ID]; exists {
-			currentStock = stock
+	wasteLogs, err := s.wasteLogs.GetByItemIDAfterDate(item.ID, ledger.StartsAt)
+	if err != nil {
+		return nil, err
+	}
+	for _, wasteLog := range wasteLogs {
+		movements = append(movements, LedgerMovement{Type: LedgerMovementWaste, OccurredAt: wasteLog.WastedAt, Quantity: -wasteLog.Quantity, ReferenceID: wasteLog.ID, Description: "Waste: " + wasteLog.Reason})
+	}
+
+	adjustments, err := s.stockAdjustments.GetByAccountIDAfterDate(item.AccountID, ledger.StartsAt)
+	if err != nil {
+		return nil, err
+	}
+	for _, adjustment := range adjustments {
+		if adjustment.InventoryItemID == item.ID {
+			movements = append(movements, LedgerMovement{Type: LedgerMovementAdjustment, OccurredAt: adjustment.AdjustedAt, Quantity: adjustment.Quantity, ReferenceID: adjustment.ID, Description: "Adjustment: " + adjustment.Reason})
 		}
+	}
 
-		result[i] = InventoryItemWithStock{
-			InventoryItem: item,
-			CurrentStock:  currentStock,
+	sentTransfers, err := s.stockTransfers.GetSentAfterDate(item.AccountID, ledger.StartsAt)
+	if err != nil {
+		return nil, err
+	}
+	for _, transfer := range sentTransfers {
+		if transfer.FromInventoryItemID == item.ID {
+			movements = append(movements, LedgerMovement{Type: LedgerMovementTransferOut, OccurredAt: transfer.SentAt, Quantity: -transfer.Quantity, ReferenceID: transfer.ID, Description: fmt.Sprintf("Transfer to account %d", transfer.ToAccountID)})
 		}
 	}
 
-	return result, nil
+	receivedTransfers, err := s.stockTransfers.GetReceivedAfterDate(item.AccountID, ledger.StartsAt)
+	if err != nil {
+		return nil, err
+	}
+	for _, transfer := range receivedTransfers {
+		if transfer.ToInventoryItemID != nil && *transfer.ToInventoryItemID == item.ID {
+			movements = append(movements, LedgerMovement{Type: LedgerMovementTransferIn, OccurredAt: *transfer.ReceivedAt, Quantity: transfer.ReceivedQuantity, ReferenceID: transfer.ID, Description: fmt.Sprintf("Transfer from account %d", transfer.FromAccountID)})
+		}
+	}
+
+	// Movements at the same time as a count are part of that count, so counts sort last
+	sort.SliceStable(movements, func(i, j int) bool {
+		if !movements[i].OccurredAt.Equal(movements[j].OccurredAt) {
+			return movements[i].OccurredAt.Before(movements[j].OccurredAt)
+		}
+		return movements[i].Type != LedgerMovementSnapshot && movements[j].Type == LedgerMovementSnapshot
+	})
+
+	balance := 0.0
+	for i := range movements {
+		if movements[i].Type == LedgerMovementSnapshot {
+			count := movements[i].Quantity
+			movements[i].Quantity = count - balance
+			balance = count
+		} else {
+			balance += movements[i].Quantity
+		}
+		movements[i].Balance = balance
+	}
+	if movements != nil {
+		ledger.Movements = movements
+	}
+	ledger.Balance = balance
+	return ledger, nil
 }
 
 // GetInventoryItemsByVendor retrieves inventory items from a specific vendor.
@@ -636,6 +1146,14 @@ func (s *Service) GetInventoryItemsWithCurrentStock(accountID int) ([]InventoryI
 //   - []models.InventoryItem: List of inventory items from the specified vendor
 //   - error: Any error that occurred during retrieval
 func (s *Service) GetInventoryItemsByVendor(accountID int, vendor string) ([]models.InventoryItem, error) {
+	// Prefer the vendor record so that spelling differences still match
+	record, err := s.FindVendorByName(accountID, vendor)
+	if err == nil {
+		return s.inventoryItems.GetByVendorID(accountID, record.ID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
 	return s.inventoryItems.GetByVendor(accountID, vendor)
 }
 
@@ -698,6 +1216,11 @@ func (s *Service) UpdateInventoryItem(item *models.InventoryItem) error {
 		}
 	}
 
+	// Link the preferred vendor to a vendor record
+	if err := s.linkItemVendor(item); err != nil {
+		return err
+	}
+
 	return s.inventoryItems.Update(item)
 }
 
@@ -715,7 +1238,12 @@ func (s *Service) UpdateInventoryItem(item *models.InventoryItem) error {
 //   - Cannot delete inventory items with existing deliveries
 //   - Maintains referential integrity across the system
 func (s *Service) DeleteInventoryItem(id int) error {
-	return s.inventoryItems.Delete(id)
+	return s.withTransaction(func(tx *Service) error {
+		if err := tx.inventoryItems.Delete(id); err != nil {
+			return err
+		}
+		return tx.inventoryLevels.DeleteByItemID(id)
+	})
 }
 
 // Menu operations
@@ -809,6 +1337,20 @@ func (s *Service) GetMenuItemsByAccount(accountID int) ([]models.MenuItem, error
 	return s.menuItems.GetByAccountID(accountID)
 }
 
+// ListMenuItems retrieves one page of an account's menu items, filtered and sorted as requested.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//   - list: The page, sort order and filters (search, category) to apply
+//
+// Returns:
+//   - []models.MenuItem: The page of menu items
+//   - Page: Pagination metadata for the page
+//   - error: Any error that occurred during retrieval
+func (s *Service) ListMenuItems(accountID int, list ListQuery) ([]models.MenuItem, Page, error) {
+	return s.menuItems.List(accountID, list)
+}
+
 // GetMenuItemsByCategory retrieves menu items filtered by category.
 // This method provides a way to filter menu items by their category.
 //
@@ -902,6 +1444,10 @@ func (s *Service) DeleteMenuItem(id int) error {
 // Business rules:
 //   - Both account and inventory item must exist
 //   - Deliveries are created with default status "pending"
+//   - Each delivery's unit price is added to the item's price history and the
+//     item's cost is recomputed with the account's costing method
+//   - Each delivery creates an inventory lot; the expiration date defaults to
+//     the delivery date plus the item's shelf life
 func (s *Service) CreateDelivery(delivery *models.Delivery) error {
 	// Validate that the account exists
 	_, err := s.accounts.GetByID(delivery.AccountID)
@@ -910,12 +1456,47 @@ func (s *Service) CreateDelivery(delivery *models.Delivery) error {
 	}
 
 	// Validate that the inventory item exists
-	_, err = s.inventoryItems.GetByID(delivery.InventoryItemID)
+	item, err := s.inventoryItems.GetByID(delivery.InventoryItemID)
 	if err != nil {
 		return errors.New("invalid inventory item ID")
 	}
 
-	return s.deliveries.Create(delivery)
+	// Perishable items expire a fixed number of days after delivery unless a date is given
+	if delivery.ExpirationDate == nil && item.ShelfLifeDays > 0 {
+		receivedAt := delivery.DeliveryDate
+		if receivedAt.IsZero() {
+			receivedAt = time.Now()
+		}
+		expirationDate := receivedAt.AddDate(0, 0, item.ShelfLifeDays)
+		delivery.ExpirationDate = &expirationDate
+	}
+
+	// Link the delivery to a vendor record
+	if err := s.linkDeliveryVendor(delivery); err != nil {
+		return err
+	}
+
+	// Put the delivery away at the given location or the account's default location
+	if delivery.StorageLocationID, err = s.resolveStorageLocation(delivery.AccountID, delivery.StorageLocationID); err != nil {
+		return err
+	}
+
+	return s.withTransaction(func(tx *Service) error {
+		if err := tx.deliveries.Create(delivery); err != nil {
+			return err
+		}
+		if err := tx.applyStockMovement(delivery.AccountID, delivery.InventoryItemID, delivery.DeliveryDate, delivery.Quantity); err != nil {
+			return err
+		}
+
+		// Receive the delivered stock as a new lot
+		if err := tx.createDeliveryLot(delivery); err != nil {
+			return err
+		}
+
+		// Feed the item's price history and cost
+		return tx.recordDeliveryPrice(delivery)
+	})
 }
 
 // GetDelivery retrieves a delivery by its unique identifier.
@@ -946,6 +1527,21 @@ func (s *Service) GetDeliveriesByAccount(accountID int) ([]models.Delivery, erro
 	return s.deliveries.GetByAccountID(accountID)
 }
 
+// ListDeliveries retrieves one page of an account's deliveries, filtered and sorted as
+// requested. Deliveries are listed newest first unless another order is requested.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//   - list: The page, sort order and filters (vendor, item, category, date range) to apply
+//
+// Returns:
+//   - []models.Delivery: The page of deliveries
+//   - Page: Pagination metadata for the page
+//   - error: Any error that occurred during retrieval
+func (s *Service) ListDeliveries(accountID int, list ListQuery) ([]models.Delivery, Page, error) {
+	return s.deliveries.List(accountID, list)
+}
+
 // GetDeliveriesByDateRange retrieves deliveries within a specific date range.
 // This method provides a way to filter deliveries by their date.
 //
@@ -972,6 +1568,14 @@ func (s *Service) GetDeliveriesByDateRange(accountID int, startDate, endDate tim
 //   - []models.Delivery: List of deliveries from the specified vendor
 //   - error: Any error that occurred during retrieval
 func (s *Service) GetDeliveriesByVendor(accountID int, vendor string) ([]models.Delivery, error) {
+	// Prefer the vendor record so that spelling differences still match
+	record, err := s.FindVendorByName(accountID, vendor)
+	if err == nil {
+		return s.deliveries.GetByVendorID(accountID, record.ID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
 	return s.deliveries.GetByVendor(accountID, vendor)
 }
 
@@ -984,8 +1588,40 @@ func (s *Service) GetDeliveriesByVendor(accountID int, vendor string) ([]models.
 //
 // Returns:
 //   - error: Any error that occurred during the update
+//
+// Business rules:
+//   - Deliveries received against an order cannot be updated (ErrDeliveryReceivedAgainstOrder)
+//   - The delivery is relinked to the vendor its vendor name refers to
+//   - The delivery's lot is resized; deliveries whose lot was partly used cannot change quantity or item
+//   - The delivery's price history entry is corrected and the item's cost recomputed
 func (s *Service) UpdateDelivery(delivery *models.Delivery) error {
-	return s.deliveries.Update(delivery)
+	previous, err := s.deliveries.GetByID(delivery.ID)
+	if err != nil {
+		return err
+	}
+	if previous.OrderItemID != nil {
+		return ErrDeliveryReceivedAgainstOrder
+	}
+
+	return s.withTransaction(func(tx *Service) error {
+		if err := tx.linkDeliveryVendor(delivery); err != nil {
+			return err
+		}
+		if err := tx.deliveries.Update(delivery); err != nil {
+			return err
+		}
+		// Take the delivery out of stock as it was and put it back as it is now
+		if err := tx.applyStockMovement(previous.AccountID, previous.InventoryItemID, previous.DeliveryDate, -previous.Quantity); err != nil {
+			return err
+		}
+		if err := tx.applyStockMovement(delivery.AccountID, delivery.InventoryItemID, delivery.DeliveryDate, delivery.Quantity); err != nil {
+			return err
+		}
+		if err := tx.syncDeliveryLot(previous, delivery); err != nil {
+			return err
+		}
+		return tx.syncDeliveryPrice(previous, delivery)
+	})
 }
 
 // DeleteDelivery deletes a delivery by its unique identifier.
@@ -1001,520 +1637,6582 @@ func (s *Service) UpdateDelivery(delivery *models.Delivery) error {
 // Business rules:
 //   - Cannot delete deliveries with existing inventory items
 //   - Maintains referential integrity across the system
+//   - Cannot delete deliveries received against an order (ErrDeliveryReceivedAgainstOrder)
+//   - Cannot delete deliveries whose lot was partly used; the lot is deleted with the delivery
+//   - The delivery's price history entry is removed and the item's cost recomputed
 func (s *Service) DeleteDelivery(id int) error {
-	return s.deliveries.Delete(id)
+	delivery, err := s.deliveries.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if delivery.OrderItemID != nil {
+		return ErrDeliveryReceivedAgainstOrder
+	}
+
+	return s.withTransaction(func(tx *Service) error {
+		if err := tx.deliveries.Delete(id); err != nil {
+			return err
+		}
+		if err := tx.applyStockMovement(delivery.AccountID, delivery.InventoryItemID, delivery.DeliveryDate, -delivery.Quantity); err != nil {
+			return err
+		}
+		if err := tx.syncDeliveryLot(delivery, nil); err != nil {
+			return err
+		}
+		return tx.syncDeliveryPrice(delivery, nil)
+	})
 }
 
-// Inventory Snapshot operations
-// These methods handle historical inventory tracking.
-// Inventory snapshots represent a point-in-time view of inventory levels.
+// Vendor operations
+// These methods handle supplier management.
+// Vendors are the canonical records behind the vendor names shown on items and deliveries.
 
-// CreateInventorySnapshot creates a new inventory snapshot.
-// This method validates that the account exists and ensures the snapshot has valid data.
+// validWeekdays lists the accepted tokens for a vendor's delivery days
+var validWeekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// CreateVendor creates a new vendor for an account.
+// This method validates the vendor details and prevents duplicate vendors
+// whose names differ only by case or spacing.
 //
 // Parameters:
-//   - snapshot: The inventory snapshot data to create
+//   - vendor: The vendor data to create
 //
 // Returns:
 //   - error: Any error that occurred during creation
 //
 // Business rules:
 //   - Account must exist
-//   - Timestamp must be set (defaults to now if not provided)
-//   - Counts map must not be empty
-func (s *Service) CreateInventorySnapshot(snapshot *models.InventorySnapshot) error {
+//   - Vendor names must be unique within an account (case- and whitespace-insensitive)
+//   - Lead time and minimum order value cannot be negative
+//   - Delivery days must be a comma-separated list of weekdays (e.g., "mon,thu")
+func (s *Service) CreateVendor(vendor *models.Vendor) error {
 	// Validate that the account exists
-	_, err := s.accounts.GetByID(snapshot.AccountID)
+	_, err := s.accounts.GetByID(vendor.AccountID)
 	if err != nil {
 		return errors.New("invalid account ID")
 	}
 
-	// Ensure timestamp is set
-	if snapshot.Timestamp.IsZero() {
-		snapshot.Timestamp = time.Now()
-	}
-
-	// Validate that counts map is not empty
-	if len(snapshot.Counts) == 0 {
-		return errors.New("snapshot must contain at least one inventory count")
+	if err := s.validateVendor(vendor); err != nil {
+		return err
 	}
 
-	return s.inventorySnapshots.Create(snapshot)
+	return s.vendors.Create(vendor)
 }
 
-// GetInventorySnapshot retrieves an inventory snapshot by its unique identifier.
-// This method provides access to inventory snapshot details for validation
+// GetVendor retrieves a vendor by its unique identifier.
+// This method provides access to vendor details for validation
 // and business logic operations.
 //
 // Parameters:
-//   - id: The unique identifier of the inventory snapshot to retrieve
+//   - id: The unique identifier of the vendor to retrieve
 //
 // Returns:
-//   - *models.InventorySnapshot: The inventory snapshot data if found
+//   - *models.Vendor: The vendor data if found
 //   - error: Any error that occurred during retrieval
-func (s *Service) GetInventorySnapshot(id int) (*models.InventorySnapshot, error) {
-	return s.inventorySnapshots.GetByID(id)
+func (s *Service) GetVendor(id int) (*models.Vendor, error) {
+	return s.vendors.GetByID(id)
 }
 
-// GetInventorySnapshotsByAccount retrieves all inventory snapshots for a specific account.
-// This method provides a way to enumerate all inventory snapshots within
-// a single account for management purposes.
+// GetVendorsByAccount retrieves all vendors for a specific account.
+// Vendors are returned in alphabetical order.
 //
 // Parameters:
 //   - accountID: The unique identifier of the account
 //
 // Returns:
-//   - []models.InventorySnapshot: List of inventory snapshots belonging to the account
+//   - []models.Vendor: List of vendors belonging to the account
 //   - error: Any error that occurred during retrieval
-func (s *Service) GetInventorySnapshotsByAccount(accountID int) ([]models.InventorySnapshot, error) {
-	return s.inventorySnapshots.GetByAccountID(accountID)
+func (s *Service) GetVendorsByAccount(accountID int) ([]models.Vendor, error) {
+	return s.vendors.GetByAccountID(accountID)
 }
 
-// GetLatestInventorySnapshot retrieves the most recent inventory snapshot for an account.
-// This method provides a way to fetch the latest inventory snapshot for
-// a specific account for reporting and analysis.
+// FindVendorByName looks up a vendor in an account by name.
+// Names are compared case-insensitively with surrounding and repeated
+// whitespace ignored, so "Local Dairy" matches "local  dairy".
 //
 // Parameters:
 //   - accountID: The unique identifier of the account
+//   - name: The vendor name to look up
 //
 // Returns:
-//   - *models.InventorySnapshot: The most recent inventory snapshot
-//   - error: Any error that occurred during retrieval
-func (s *Service) GetLatestInventorySnapshot(accountID int) (*models.InventorySnapshot, error) {
-	return s.inventorySnapshots.GetLatestByAccountID(accountID)
-}
+//   - *models.Vendor: The matching vendor
+//   - error: gorm.ErrRecordNotFound if no vendor matches, or any retrieval error
+func (s *Service) FindVendorByName(accountID int, name string) (*models.Vendor, error) {
+	vendors, err := s.vendors.GetByAccountID(accountID)
+	if err != nil {
+		return nil, err
+	}
 
-// GetInventorySnapshotsByDateRange retrieves inventory snapshots within a specific date range.
-// This method provides a way to filter inventory snapshots by their date.
-//
-// Parameters:
-//   - accountID: The unique identifier of the account
-//   - startDate: The start date of the range
-//   - endDate: The end date of the range
-//
-// Returns:
-//   - []models.InventorySnapshot: List of inventory snapshots within the date range
-//   - error: Any error that occurred during retrieval
-func (s *Service) GetInventorySnapshotsByDateRange(accountID int, startDate, endDate time.Time) ([]models.InventorySnapshot, error) {
-	return s.inventorySnapshots.GetByDateRange(accountID, startDate, endDate)
+	key := normalizeVendorName(name)
+	for i := range vendors {
+		if normalizeVendorName(vendors[i].Name) == key {
+			return &vendors[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
 }
 
-// UpdateInventorySnapshot updates an existing inventory snapshot's information.
-// This method allows modification of inventory snapshot details while
-// maintaining data integrity and business rules.
+// UpdateVendor updates an existing vendor's information.
+// When the vendor is renamed, the vendor name stored on linked inventory items,
+// deliveries, order items and invoices is updated as well so that legacy
+// name-based lookups keep working.
 //
 // Parameters:
-//   - snapshot: The updated inventory snapshot data
+//   - vendor: The updated vendor data
 //
 // Returns:
 //   - error: Any error that occurred during the update
-func (s *Service) UpdateInventorySnapshot(snapshot *models.InventorySnapshot) error {
-	return s.inventorySnapshots.Update(snapshot)
+func (s *Service) UpdateVendor(vendor *models.Vendor) error {
+	existing, err := s.vendors.GetByID(vendor.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.validateVendor(vendor); err != nil {
+		return err
+	}
+
+	return s.withTransaction(func(tx *Service) error {
+		if err := tx.vendors.Update(vendor); err != nil {
+			return err
+		}
+
+		if existing.Name == vendor.Name {
+			return nil
+		}
+
+		// Propagate the new name to records that still carry the vendor name
+		items, err := tx.inventoryItems.GetByVendorID(vendor.AccountID, vendor.ID)
+		if err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].PreferredVendor = vendor.Name
+			if err := tx.inventoryItems.Update(&items[i]); err != nil {
+				return err
+			}
+		}
+
+		deliveries, err := tx.deliveries.GetByVendorID(vendor.AccountID, vendor.ID)
+		if err != nil {
+			return err
+		}
+		for i := range deliveries {
+			deliveries[i].Vendor = vendor.Name
+			if err := tx.deliveries.Update(&deliveries[i]); err != nil {
+				return err
+			}
+		}
+
+		orderItems, err := tx.orderItems.GetByVendorID(vendor.ID)
+		if err != nil {
+			return err
+		}
+		for i := range orderItems {
+			orderItems[i].Vendor = vendor.Name
+			if err := tx.orderItems.Update(&orderItems[i]); err != nil {
+				return err
+			}
+		}
+
+		invoices, err := tx.invoices.GetByVendorID(vendor.AccountID, vendor.ID)
+		if err != nil {
+			return err
+		}
+		for i := range invoices {
+			invoices[i].Vendor = vendor.Name
+			if err := tx.invoices.Update(&invoices[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
 
-// DeleteInventorySnapshot deletes an inventory snapshot by its unique identifier.
-// This method enforces referential integrity by preventing deletion of
-// inventory snapshots that still have active inventory items.
+// DeleteVendor deletes a vendor if nothing references it.
+// Vendors that are still linked to inventory items, deliveries, orders or invoices
+// should be deactivated instead so that their history is preserved.
 //
 // Parameters:
-//   - id: The unique identifier of the inventory snapshot to delete
+//   - id: The unique identifier of the vendor to delete
 //
 // Returns:
 //   - error: Any error that occurred during deletion
 //
 // Business rules:
-//   - Cannot delete inventory snapshots with existing inventory items
-//   - Maintains referential integrity across the system
-func (s *Service) DeleteInventorySnapshot(id int) error {
-	return s.inventorySnapshots.Delete(id)
+//   - Cannot delete vendors assigned to inventory items
+//   - Cannot delete vendors with recorded deliveries
+//   - Cannot delete vendors that items were ordered from
+//   - Cannot delete vendors with recorded invoices
+func (s *Service) DeleteVendor(id int) error {
+	vendor, err := s.vendors.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	items, err := s.inventoryItems.GetByVendorID(vendor.AccountID, vendor.ID)
+	if err != nil {
+		return err
+	}
+	if len(items) > 0 {
+		return errors.New("cannot delete vendor: it is still assigned to inventory items")
+	}
+
+	deliveries, err := s.deliveries.GetByVendorID(vendor.AccountID, vendor.ID)
+	if err != nil {
+		return err
+	}
+	if len(deliveries) > 0 {
+		return errors.New("cannot delete vendor: it has recorded deliveries")
+	}
+
+	orderItems, err := s.orderItems.GetByVendorID(vendor.ID)
+	if err != nil {
+		return err
+	}
+	if len(orderItems) > 0 {
+		return errors.New("cannot delete vendor: it is still referenced by orders")
+	}
+
+	invoices, err := s.invoices.GetByVendorID(vendor.AccountID, vendor.ID)
+	if err != nil {
+		return err
+	}
+	if len(invoices) > 0 {
+		return errors.New("cannot delete vendor: it has recorded invoices")
+	}
+
+	return s.vendors.Delete(id)
 }
 
-// GetInventoryVariance calculates the variance in inventory levels between two snapshots.
-// This method is useful for identifying inventory discrepancies and losses.
-//
-// Parameters:
-//   - accountID: The unique identifier of the account
-//   - startDate: The start date of the range for the first snapshot
-//   - endDate: The end date of the range for the first snapshot
+// validateVendor checks vendor fields and normalizes the name and delivery days.
+func (s *Service) validateVendor(vendor *models.Vendor) error {
+	vendor.Name = cleanVendorName(vendor.Name)
+	if vendor.Name == "" {
+		return errors.New("vendor name is required")
+	}
+	if vendor.LeadTimeDays < 0 {
+		return errors.New("lead time cannot be negative")
+	}
+	if vendor.MinimumOrderValue < 0 {
+		return errors.New("minimum order value cannot be negative")
+	}
+
+	// Normalize delivery days to lowercase tokens and reject unknown days
+	if vendor.DeliveryDays != "" {
+		var days []string
+		for _, day := range strings.Split(vendor.DeliveryDays, ",") {
+			day = strings.ToLower(strings.TrimSpace(day))
+			if day == "" {
+				continue
+			}
+			if !isValidWeekday(day) {
+				return fmt.Errorf("invalid delivery day: %s", day)
+			}
+			days = append(days, day)
+		}
+		vendor.DeliveryDays = strings.Join(days, ",")
+	}
+
+	// Check if a vendor with the same name already exists in the account
+	existing, err := s.FindVendorByName(vendor.AccountID, vendor.Name)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err == nil && existing.ID != vendor.ID {
+		return errors.New("vendor name already exists in this account")
+	}
+
+	return nil
+}
+
+// resolveVendor maps a vendor ID or free-text vendor name to a vendor record.
+// An explicit ID must belong to the account. A name is matched against existing
+// vendors and a new vendor is created when none matches, so every item and
+// delivery ends up pointing at a single canonical vendor.
 //
-// Returns:
-//   - map[int]float64: A map of inventory item IDs to their variance
-//   - error: Any error that occurred during calculation
+// When both are supplied and the name is not the name of the vendor with that ID,
+// the name wins: clients that send back a whole record change the vendor name
+// but echo the vendor ID they loaded.
 //
-// Business logic:
-//   - This will be implemented when we add Toast POS integration
-//   - For now, it returns an empty map as a placeholder
-func (s *Service) GetInventoryVariance(accountID int, startDate, endDate time.Time) (map[int]float64, error) {
-	// This will be implemented when we add Toast POS integration
-	// For now, return empty map
-	return make(map[int]float64), nil
+// Returns nil when neither an ID nor a name is supplied.
+func (s *Service) resolveVendor(accountID int, vendorID *int, name string) (*models.Vendor, error) {
+	if vendorID != nil {
+		vendor, err := s.vendors.GetByID(*vendorID)
+		if err != nil {
+			return nil, errors.New("invalid vendor ID")
+		}
+		if vendor.AccountID != accountID {
+			return nil, errors.New("vendor does not belong to the same account")
+		}
+		if cleanVendorName(name) == "" || normalizeVendorName(name) == normalizeVendorName(vendor.Name) {
+			return vendor, nil
+		}
+	}
+
+	if cleanVendorName(name) == "" {
+		return nil, nil
+	}
+
+	vendor, err := s.FindVendorByName(accountID, name)
+	if err == nil {
+		return vendor, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	vendor = &models.Vendor{AccountID: accountID, Name: cleanVendorName(name)}
+	if err := s.vendors.Create(vendor); err != nil {
+		return nil, err
+	}
+	return vendor, nil
 }
 
-// Access Control operations
-// These methods handle user permissions and access validation.
-// Users are the primary actors in the system and are associated with accounts.
+// linkItemVendor sets an inventory item's vendor link and canonical vendor name.
+func (s *Service) linkItemVendor(item *models.InventoryItem) error {
+	vendor, err := s.resolveVendor(item.AccountID, item.VendorID, item.PreferredVendor)
+	if err != nil {
+		return err
+	}
+	if vendor != nil {
+		item.VendorID = &vendor.ID
+		item.PreferredVendor = vendor.Name
+	}
+	return nil
+}
 
-// ValidateAccountAccess checks if a user has access to a specific account.
-// This method ensures that users can only access resources within their own account.
-//
-// Parameters:
-//   - accountID: The unique identifier of the account to check access for
-//   - userAccountID: The unique identifier of the user's account
-//
-// Returns:
-//   - error: Any error that occurred during validation
-//
-// Business rules:
-//   - Users can only access resources within their own account
-//   - Access is denied if the user's account does not match the requested account
-func (s *Service) ValidateAccountAccess(accountID int, userAccountID int) error {
-	if accountID != userAccountID {
-		return errors.New("access denied: user can only access their own account")
+// linkDeliveryVendor sets a delivery's vendor link and canonical vendor name.
+func (s *Service) linkDeliveryVendor(delivery *models.Delivery) error {
+	vendor, err := s.resolveVendor(delivery.AccountID, delivery.VendorID, delivery.Vendor)
+	if err != nil {
+		return err
+	}
+	if vendor != nil {
+		delivery.VendorID = &vendor.ID
+		delivery.Vendor = vendor.Name
 	}
 	return nil
 }
 
-// ValidateOrganizationAccess checks if a user has access to a specific organization.
-// This method ensures that users can access resources within their organization
-// if they have appropriate permissions.
+// isValidWeekday checks if a token is an accepted delivery day.
+func isValidWeekday(day string) bool {
+	for _, weekday := range validWeekdays {
+		if day == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanVendorName trims a vendor name and collapses repeated whitespace.
+func cleanVendorName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// normalizeVendorName returns the key used to compare vendor names.
+func normalizeVendorName(name string) string {
+	return strings.ToLower(cleanVendorName(name))
+}
+
+// Order operations
+// These methods handle purchase orders placed with vendors.
+// Each order holds one or more order items, and each item may come from a different vendor.
+
+// validOrderStatuses lists the lifecycle states an order can be in
+var validOrderStatuses = []string{"pending", "approved", "ordered", "partially_received", "delivered", "cancelled"}
+
+// CreateOrder creates a new order together with its items.
+// This method validates every item, links each item to its vendor record,
+// and computes the item and order totals from quantities and unit costs.
 //
 // Parameters:
-//   - organizationID: The unique identifier of the organization to check access for
-//   - userID: The unique identifier of the user
+//   - order: The order data to create
+//   - items: The items to place on the order
 //
 // Returns:
-//   - bool: True if access is granted, false otherwise
-//   - error: Any error that occurred during validation
+//   - error: Any error that occurred during creation
 //
 // Business rules:
-//   - Users can access resources within their organization if they have appropriate permissions
-//   - Access is denied if the user's account does not belong to the organization
-func (s *Service) ValidateOrganizationAccess(organizationID int, userID int) (bool, error) {
-	// Get the user
-	user, err := s.users.GetByID(userID)
+//   - Account must exist and the order must contain at least one item
+//   - Each inventory item must exist and belong to the order's account
+//   - Quantities must be positive and unit costs cannot be negative
+//   - Items without a vendor default to the inventory item's preferred vendor
+//   - Order date defaults to now and status defaults to "pending"
+func (s *Service) CreateOrder(order *models.Order, items []models.OrderItem) error {
+	// Validate that the account exists
+	_, err := s.accounts.GetByID(order.AccountID)
 	if err != nil {
-		return false, err
+		return errors.New("invalid account ID")
 	}
 
-	// Get the user's account
-	account, err := s.accounts.GetByID(user.AccountID)
-	if err != nil {
-		return false, err
+	if len(items) == 0 {
+		return errors.New("order must contain at least one item")
 	}
 
-	// Check if the user's account belongs to the organization
-	if account.OrganizationID == nil || *account.OrganizationID != organizationID {
-		return false, errors.New("access denied: user does not belong to this organization")
+	if order.Status == "" {
+		order.Status = "pending"
+	}
+	if !isValidOrderStatus(order.Status) {
+		return fmt.Errorf("invalid order status: %s", order.Status)
+	}
+	if order.OrderDate.IsZero() {
+		order.OrderDate = time.Now()
 	}
 
-	return true, nil
+	order.TotalCost = 0
+	for i := range items {
+		item := &items[i]
+
+		inventoryItem, err := s.inventoryItems.GetByID(item.InventoryItemID)
+		if err != nil || inventoryItem.AccountID != order.AccountID {
+			return errors.New("invalid inventory item ID")
+		}
+		if item.Quantity <= 0 {
+			return errors.New("order item quantity must be positive")
+		}
+		if item.UnitCost < 0 {
+			return errors.New("order item unit cost cannot be negative")
+		}
+
+		// Fall back to the item's preferred vendor when none is given
+		if item.VendorID == nil && strings.TrimSpace(item.Vendor) == "" {
+			item.VendorID = inventoryItem.VendorID
+			item.Vendor = inventoryItem.PreferredVendor
+		}
+		vendor, err := s.resolveVendor(order.AccountID, item.VendorID, item.Vendor)
+		if err != nil {
+			return err
+		}
+		if vendor != nil {
+			item.VendorID = &vendor.ID
+			item.Vendor = vendor.Name
+		}
+
+		item.TotalCost = item.Quantity * item.UnitCost
+		order.TotalCost += item.TotalCost
+	}
+
+	if err := s.orders.Create(order); err != nil {
+		return err
+	}
+
+	for i := range items {
+		items[i].OrderID = order.ID
+		if err := s.orderItems.Create(&items[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-// IsOrganizationAdmin checks if a user is an organization admin.
-// Organization admins have elevated permissions across all accounts in their organization.
+// GetOrder retrieves an order by its unique identifier.
 //
 // Parameters:
-//   - userID: The unique identifier of the user
+//   - id: The unique identifier of the order to retrieve
 //
 // Returns:
-//   - bool: True if the user is an organization admin, false otherwise
+//   - *models.Order: The order data if found
 //   - error: Any error that occurred during retrieval
-func (s *Service) IsOrganizationAdmin(userID int) (bool, error) {
+func (s *Service) GetOrder(id int) (*models.Order, error) {
+	return s.orders.GetByID(id)
+}
+
+// GetOrdersByAccount retrieves all orders for a specific account.
+// Orders are returned newest first.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//
+// Returns:
+//   - []models.Order: List of orders belonging to the account
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetOrdersByAccount(accountID int) ([]models.Order, error) {
+	return s.orders.GetByAccountID(accountID)
+}
+
+// GetOrderItems retrieves the items placed on an order.
+//
+// Parameters:
+//   - orderID: The unique identifier of the order
+//
+// Returns:
+//   - []models.OrderItem: List of items on the order
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetOrderItems(orderID int) ([]models.OrderItem, error) {
+	return s.orderItems.GetByOrderID(orderID)
+}
+
+// ErrOrderNotSent is returned when updating an order to "ordered" without sending it; an
+// order is placed by emailing its purchase orders to its vendors, see RecordOrderDispatches
+var ErrOrderNotSent = errors.New("orders are marked ordered by sending them to their vendors")
+
+// UpdateOrder updates an existing order's information.
+//
+// Parameters:
+//   - order: The updated order data
+//
+// Returns:
+//   - error: ErrOrderNotSent, or any error that occurred during the update
+//
+// Business rules:
+//   - Status must be one of pending, approved, ordered, partially_received, delivered, or cancelled
+//   - Orders only become "ordered" once they are sent to their vendors (ErrOrderNotSent)
+func (s *Service) UpdateOrder(order *models.Order) error {
+	if !isValidOrderStatus(order.Status) {
+		return fmt.Errorf("invalid order status: %s", order.Status)
+	}
+	previous, err := s.orders.GetByID(order.ID)
+	if err != nil {
+		return err
+	}
+	if order.Status == "ordered" && previous.Status != "ordered" {
+		return ErrOrderNotSent
+	}
+	return s.orders.Update(order)
+}
+
+// isValidOrderStatus checks if a status is an accepted order status.
+func isValidOrderStatus(status string) bool {
+	for _, validStatus := range validOrderStatuses {
+		if status == validStatus {
+			return true
+		}
+	}
+	return false
+}
+
+// PurchaseOrder is the part of an order placed with a single vendor, as sent to that vendor.
+type PurchaseOrder struct {
+	Number  string              `json:"number"` // e.g., "PO-42", with a suffix per vendor when an order has several
+	Order   models.Order        `json:"order"`
+	Account models.Account      `json:"account"`
+	Vendor  *models.Vendor      `json:"vendor"` // Nil for items ordered without a vendor
+	Lines   []PurchaseOrderLine `json:"lines"`
+	Total   float64             `json:"total"`
+}
+
+// PurchaseOrderLine is a single item on a purchase order.
+type PurchaseOrderLine struct {
+	InventoryItemID int     `json:"inventory_item_id"`
+	ItemName        string  `json:"item_name"`
+	Unit            string  `json:"unit"`
+	Quantity        float64 `json:"quantity"`
+	UnitCost        float64 `json:"unit_cost"`
+	TotalCost       float64 `json:"total_cost"`
+	Notes           string  `json:"notes"`
+}
+
+// GetPurchaseOrders splits an order into a purchase order for each vendor it was placed with.
+//
+// Parameters:
+//   - orderID: The unique identifier of the order
+//
+// Returns:
+//   - []PurchaseOrder: The purchase orders ordered by vendor name, with items without a vendor last
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetPurchaseOrders(orderID int) ([]PurchaseOrder, error) {
+	order, err := s.orders.GetByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	account, err := s.accounts.GetByID(order.AccountID)
+	if err != nil {
+		return nil, errors.New("invalid account ID")
+	}
+	items, err := s.orderItems.GetByOrderID(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	purchaseOrders := make(map[int]*PurchaseOrder) // Keyed by vendor ID, with 0 for items without a vendor
+	for _, item := range items {
+		vendorID := 0
+		if item.VendorID != nil {
+			vendorID = *item.VendorID
+		}
+		purchaseOrder, ok := purchaseOrders[vendorID]
+		if !ok {
+			purchaseOrder = &PurchaseOrder{Order: *order, Account: *account}
+			if vendorID != 0 {
+				if purchaseOrder.Vendor, err = s.vendors.GetByID(vendorID); err != nil {
+					return nil, err
+				}
+			}
+			purchaseOrders[vendorID] = purchaseOrder
+		}
+
+		line := PurchaseOrderLine{
+			InventoryItemID: item.InventoryItemID,
+			Quantity:        item.Quantity,
+			UnitCost:        item.UnitCost,
+			TotalCost:       item.TotalCost,
+			Notes:           item.Notes,
+		}
+		if inventoryItem, err := s.inventoryItems.GetByID(item.InventoryItemID); err == nil {
+			line.ItemName = inventoryItem.Name
+			line.Unit = inventoryItem.Unit
+		}
+		purchaseOrder.Lines = append(purchaseOrder.Lines, line)
+		purchaseOrder.Total += line.TotalCost
+	}
+
+	result := make([]PurchaseOrder, 0, len(purchaseOrders))
+	for _, purchaseOrder := range purchaseOrders {
+		result = append(result, *purchaseOrder)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if (result[i].Vendor == nil) != (result[j].Vendor == nil) {
+			return result[j].Vendor == nil
+		}
+		return result[i].Vendor == nil || result[i].Vendor.Name < result[j].Vendor.Name
+	})
+	for i := range result {
+		result[i].Number = fmt.Sprintf("PO-%d", order.ID)
+		if len(result) > 1 {
+			result[i].Number += fmt.Sprintf("-%d", i+1)
+		}
+	}
+
+	return result, nil
+}
+
+// Order dispatch operations
+// These methods record purchase orders emailed to vendors. Rendering and sending the
+// emails is left to the caller, which owns the email service. Sending an order is the
+// only way it becomes "ordered", so every placed order was emailed to a vendor.
+
+// dispatchableOrderStatuses lists the statuses an order can be sent to its vendors in.
+// Orders already marked "ordered" can be sent again, e.g. after fixing a vendor's email address.
+var dispatchableOrderStatuses = []string{"pending", "approved", "ordered"}
+
+// ErrOrderNotDispatchable is returned when sending an order that was received or cancelled
+var ErrOrderNotDispatchable = errors.New("only pending, approved, or ordered orders can be sent to vendors")
+
+// ValidateOrderDispatch checks that an order can be sent to its vendors.
+//
+// Parameters:
+//   - order: The order to send
+//
+// Returns:
+//   - error: ErrOrderNotDispatchable if the order was received or cancelled
+func (s *Service) ValidateOrderDispatch(order *models.Order) error {
+	for _, status := range dispatchableOrderStatuses {
+		if order.Status == status {
+			return nil
+		}
+	}
+	return ErrOrderNotDispatchable
+}
+
+// RecordOrderDispatches records the purchase orders of an order emailed to its vendors.
+// Once any purchase order was sent, the order is marked "ordered" and its dispatch time set.
+//
+// Parameters:
+//   - order: The order that was sent; updated in place
+//   - dispatches: The outcome of sending each purchase order
+//
+// Returns:
+//   - error: ErrOrderNotDispatchable, or any error that occurred while saving
+func (s *Service) RecordOrderDispatches(order *models.Order, dispatches []models.OrderDispatch) error {
+	if err := s.ValidateOrderDispatch(order); err != nil {
+		return err
+	}
+
+	var sentAt *time.Time
+	for i := range dispatches {
+		dispatches[i].OrderID = order.ID
+		if err := s.orderDispatches.Create(&dispatches[i]); err != nil {
+			return err
+		}
+		if dispatches[i].Status == models.EmailStatusSent {
+			sentAt = &dispatches[i].SentAt
+		}
+	}
+	if sentAt == nil {
+		return nil
+	}
+
+	order.Status = "ordered"
+	order.DispatchedAt = sentAt
+	return s.orders.Update(order)
+}
+
+// GetOrderDispatches retrieves the record of every purchase order of an order sent to a vendor.
+//
+// Parameters:
+//   - orderID: The unique identifier of the order
+//
+// Returns:
+//   - []models.OrderDispatch: The dispatches, oldest first
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetOrderDispatches(orderID int) ([]models.OrderDispatch, error) {
+	return s.orderDispatches.GetByOrderID(orderID)
+}
+
+// Order receiving operations
+// These methods check goods that arrive against the order they were placed on. Each receipt
+// books what arrived as deliveries and reports where it differs from what was ordered.
+
+// receivableOrderStatuses lists the statuses an order can be received in
+var receivableOrderStatuses = []string{"approved", "ordered", "partially_received"}
+
+// ErrOrderNotReceivable is returned when receiving an order that was not yet approved, or was closed
+var ErrOrderNotReceivable = errors.New("only approved, ordered, or partially received orders can be received")
+
+// ErrDeliveryReceivedAgainstOrder is returned when editing or deleting a delivery that a receipt
+// created; the order's received quantities depend on it, so it must be corrected with another receipt
+var ErrDeliveryReceivedAgainstOrder = errors.New("deliveries received against an order cannot be changed; record a correcting receipt instead")
+
+// receiptCostTolerance is the largest difference from the ordered unit cost that is not a price discrepancy
+const receiptCostTolerance = 0.005
+
+// Receipt discrepancy types
+const (
+	DiscrepancyShort        = "short"        // Less arrived than was outstanding
+	DiscrepancyOver         = "over"         // More arrived than was outstanding
+	DiscrepancySubstitution = "substitution" // A different inventory item arrived in place of the ordered one
+	DiscrepancyPrice        = "price"        // The unit cost charged differs from the ordered unit cost
+)
+
+// ReceiptLineInput is what arrived for one item of an order.
+type ReceiptLineInput struct {
+	OrderItemID       int        `json:"order_item_id"`
+	Quantity          float64    `json:"quantity"`
+	UnitCost          *float64   `json:"unit_cost"`          // Defaults to the ordered unit cost
+	SubstituteItemID  *int       `json:"substitute_item_id"` // The inventory item that arrived in place of the ordered one
+	LotNumber         string     `json:"lot_number"`
+	ExpirationDate    *time.Time `json:"expiration_date"`
+	StorageLocationID *int       `json:"storage_location_id"`
+	Notes             string     `json:"notes"`
+}
+
+// ReceiptDiscrepancy is a difference between what was ordered and what arrived.
+type ReceiptDiscrepancy struct {
+	Type            string  `json:"type"`
+	OrderItemID     int     `json:"order_item_id"`
+	InventoryItemID int     `json:"inventory_item_id"` // The inventory item that arrived
+	ItemName        string  `json:"item_name"`         // The name of the ordered inventory item
+	SubstituteName  string  `json:"substitute_name,omitempty"`
+	Expected        float64 `json:"expected"` // Unit cost for price discrepancies, quantity otherwise
+	Received        float64 `json:"received"`
+	CostImpact      float64 `json:"cost_impact"` // The change in spend against the order caused by the discrepancy
+}
+
+// ReceiptReport is a receipt together with where it differs from the order.
+type ReceiptReport struct {
+	Receipt       models.Receipt       `json:"receipt"`
+	Order         models.Order         `json:"order"`
+	TotalCost     float64              `json:"total_cost"` // The cost of the goods received
+	Discrepancies []ReceiptDiscrepancy `json:"discrepancies"`
+	Outstanding   []models.OrderItem   `json:"outstanding"` // Items of the order not yet received in full
+}
+
+// ReceiveOrder records goods that arrived against an order.
+// Each line that received goods creates a delivery of the item that arrived, so stock,
+// lots and price history are updated as for any other delivery.
+//
+// Parameters:
+//   - order: The order being received; its status is updated in place
+//   - receipt: The receipt data; received time defaults to now
+//   - lines: What arrived for each item of the order checked in
+//
+// Returns:
+//   - *ReceiptReport: The receipt and its discrepancies
+//   - error: ErrOrderNotReceivable, an invalid line, or any error that occurred while saving
+//
+// Business rules:
+//   - Each line must reference a different item of the order, and quantities cannot be negative
+//   - A quantity of zero records that nothing arrived; items left out are still outstanding
+//   - Substitutes must belong to the order's account and count toward the ordered item
+//   - The order is marked "delivered" once every item was received in full, and "partially_received" otherwise
+func (s *Service) ReceiveOrder(order *models.Order, receipt *models.Receipt, lines []ReceiptLineInput) (*ReceiptReport, error) {
+	if !isReceivableOrderStatus(order.Status) {
+		return nil, ErrOrderNotReceivable
+	}
+	if len(lines) == 0 {
+		return nil, errors.New("receipt must contain at least one line")
+	}
+
+	items, err := s.orderItems.GetByOrderID(order.ID)
+	if err != nil {
+		return nil, err
+	}
+	itemsByID := make(map[int]*models.OrderItem, len(items))
+	for i := range items {
+		itemsByID[items[i].ID] = &items[i]
+	}
+
+	seen := make(map[int]bool, len(lines))
+	for _, line := range lines {
+		if itemsByID[line.OrderItemID] == nil {
+			return nil, fmt.Errorf("order item %d is not on the order", line.OrderItemID)
+		}
+		if seen[line.OrderItemID] {
+			return nil, fmt.Errorf("order item %d is received more than once", line.OrderItemID)
+		}
+		seen[line.OrderItemID] = true
+
+		if line.Quantity < 0 {
+			return nil, errors.New("received quantity cannot be negative")
+		}
+		if line.UnitCost != nil && *line.UnitCost < 0 {
+			return nil, errors.New("received unit cost cannot be negative")
+		}
+		if line.SubstituteItemID != nil {
+			substitute, err := s.inventoryItems.GetByID(*line.SubstituteItemID)
+			if err != nil || substitute.AccountID != order.AccountID {
+				return nil, errors.New("invalid substitute item ID")
+			}
+		}
+	}
+
+	receipt.ID = 0
+	receipt.AccountID = order.AccountID
+	receipt.OrderID = order.ID
+	if receipt.ReceivedAt.IsZero() {
+		receipt.ReceivedAt = time.Now()
+	}
+
+	err = s.withTransaction(func(tx *Service) error {
+		if err := tx.receipts.Create(receipt); err != nil {
+			return err
+		}
+
+		receipt.Lines = make([]models.ReceiptLine, 0, len(lines))
+		for _, input := range lines {
+			item := itemsByID[input.OrderItemID]
+			line := models.ReceiptLine{
+				ReceiptID:        receipt.ID,
+				OrderItemID:      item.ID,
+				OrderedItemID:    item.InventoryItemID,
+				InventoryItemID:  item.InventoryItemID,
+				ExpectedQuantity: math.Max(item.Quantity-item.ReceivedQuantity, 0),
+				ReceivedQuantity: input.Quantity,
+				ExpectedUnitCost: item.UnitCost,
+				UnitCost:         item.UnitCost,
+				Notes:            input.Notes,
+			}
+			if input.SubstituteItemID != nil {
+				line.InventoryItemID = *input.SubstituteItemID
+			}
+			if input.UnitCost != nil {
+				line.UnitCost = *input.UnitCost
+			}
+
+			if line.ReceivedQuantity > 0 {
+				delivery := &models.Delivery{
+					AccountID:         order.AccountID,
+					InventoryItemID:   line.InventoryItemID,
+					Vendor:            item.Vendor,
+					VendorID:          item.VendorID,
+					Quantity:          line.ReceivedQuantity,
+					DeliveryDate:      receipt.ReceivedAt,
+					Cost:              line.ReceivedQuantity * line.UnitCost,
+					LotNumber:         input.LotNumber,
+					Notes:             input.Notes,
+					ExpirationDate:    input.ExpirationDate,
+					StorageLocationID: input.StorageLocationID,
+					OrderItemID:       &item.ID,
+				}
+				if err := tx.CreateDelivery(delivery); err != nil {
+					return err
+				}
+				line.DeliveryID = &delivery.ID
+
+				item.ReceivedQuantity += line.ReceivedQuantity
+				if err := tx.orderItems.Update(item); err != nil {
+					return err
+				}
+			}
+
+			if err := tx.receiptLines.Create(&line); err != nil {
+				return err
+			}
+			receipt.Lines = append(receipt.Lines, line)
+		}
+
+		order.Status = "delivered"
+		for _, item := range items {
+			if item.ReceivedQuantity < item.Quantity {
+				order.Status = "partially_received"
+				break
+			}
+		}
+		return tx.orders.Update(order)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.buildReceiptReport(*receipt, *order, items)
+}
+
+// isReceivableOrderStatus checks if goods can be received against an order in a status.
+func isReceivableOrderStatus(status string) bool {
+	for _, receivableStatus := range receivableOrderStatuses {
+		if status == receivableStatus {
+			return true
+		}
+	}
+	return false
+}
+
+// GetOrderReceipts retrieves every receipt of an order together with its discrepancies.
+//
+// Parameters:
+//   - orderID: The unique identifier of the order
+//
+// Returns:
+//   - []ReceiptReport: The receipts, oldest first
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetOrderReceipts(orderID int) ([]ReceiptReport, error) {
+	order, err := s.orders.GetByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.orderItems.GetByOrderID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := s.receipts.GetByOrderID(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]ReceiptReport, 0, len(receipts))
+	for _, receipt := range receipts {
+		if receipt.Lines, err = s.receiptLines.GetByReceiptID(receipt.ID); err != nil {
+			return nil, err
+		}
+		report, err := s.buildReceiptReport(receipt, *order, items)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, *report)
+	}
+	return reports, nil
+}
+
+// buildReceiptReport compares the lines of a receipt with the order they were received against.
+// Outstanding items reflect the order as it is now rather than when the receipt was recorded.
+func (s *Service) buildReceiptReport(receipt models.Receipt, order models.Order, items []models.OrderItem) (*ReceiptReport, error) {
+	report := &ReceiptReport{
+		Receipt:       receipt,
+		Order:         order,
+		Discrepancies: []ReceiptDiscrepancy{},
+		Outstanding:   []models.OrderItem{},
+	}
+
+	names := make(map[int]string)
+	itemName := func(id int) string {
+		if name, ok := names[id]; ok {
+			return name
+		}
+		if item, err := s.inventoryItems.GetByID(id); err == nil {
+			names[id] = item.Name
+		}
+		return names[id]
+	}
+
+	for _, line := range receipt.Lines {
+		report.TotalCost += line.ReceivedQuantity * line.UnitCost
+
+		discrepancy := ReceiptDiscrepancy{
+			OrderItemID:     line.OrderItemID,
+			InventoryItemID: line.InventoryItemID,
+			ItemName:        itemName(line.OrderedItemID),
+		}
+		if line.ReceivedQuantity < line.ExpectedQuantity {
+			short := discrepancy
+			short.Type = DiscrepancyShort
+			short.Expected = line.ExpectedQuantity
+			short.Received = line.ReceivedQuantity
+			short.CostImpact = (line.ReceivedQuantity - line.ExpectedQuantity) * line.ExpectedUnitCost
+			report.Discrepancies = append(report.Discrepancies, short)
+		}
+		if line.ReceivedQuantity > line.ExpectedQuantity {
+			over := discrepancy
+			over.Type = DiscrepancyOver
+			over.Expected = line.ExpectedQuantity
+			over.Received = line.ReceivedQuantity
+			over.CostImpact = (line.ReceivedQuantity - line.ExpectedQuantity) * line.UnitCost
+			report.Discrepancies = append(report.Discrepancies, over)
+		}
+		if line.InventoryItemID != line.OrderedItemID && line.ReceivedQuantity > 0 {
+			substitution := discrepancy
+			substitution.Type = DiscrepancySubstitution
+			substitution.SubstituteName = itemName(line.InventoryItemID)
+			substitution.Expected = line.ExpectedQuantity
+			substitution.Received = line.ReceivedQuantity
+			report.Discrepancies = append(report.Discrepancies, substitution)
+		}
+		if math.Abs(line.UnitCost-line.ExpectedUnitCost) > receiptCostTolerance && line.ReceivedQuantity > 0 {
+			price := discrepancy
+			price.Type = DiscrepancyPrice
+			price.Expected = line.ExpectedUnitCost
+			price.Received = line.UnitCost
+			price.CostImpact = (line.UnitCost - line.ExpectedUnitCost) * math.Min(line.ReceivedQuantity, line.ExpectedQuantity)
+			report.Discrepancies = append(report.Discrepancies, price)
+		}
+	}
+
+	for _, item := range items {
+		if item.ReceivedQuantity < item.Quantity {
+			report.Outstanding = append(report.Outstanding, item)
+		}
+	}
+
+	return report, nil
+}
+
+// Invoice operations
+// These methods record vendor invoices and match them against what was ordered and
+// received, so only invoices for goods that arrived at the agreed price are paid.
+
+// ErrDuplicateInvoice is returned when a vendor's invoice number was already recorded
+var ErrDuplicateInvoice = errors.New("this invoice number was already recorded for the vendor")
+
+// ErrInvoiceAlreadyApproved is returned when matching or approving an invoice approved for payment
+var ErrInvoiceAlreadyApproved = errors.New("invoice is already approved for payment")
+
+// invoiceRoundingTolerance is the difference in unit cost accepted on top of the price tolerance
+const invoiceRoundingTolerance = 0.005
+
+// Invoice match issues
+const (
+	InvoiceIssueNotOrdered        = "not_ordered"         // The line is not linked to an order item
+	InvoiceIssueQuantity          = "quantity"            // The quantity invoiced differs from the quantity received and not yet billed
+	InvoiceIssuePrice             = "price"               // The unit cost invoiced differs from the ordered unit cost
+	InvoiceIssueDeliveredQuantity = "delivered_quantity"  // The quantity invoiced differs from the quantity of the delivery billed
+	InvoiceIssueDeliveredUnitCost = "delivered_unit_cost" // The unit cost invoiced differs from the unit cost of the delivery billed
+)
+
+// InvoiceLineMatch compares a line of an invoice with its order item and what was received.
+type InvoiceLineMatch struct {
+	InvoiceLineID     int      `json:"invoice_line_id"`
+	OrderItemID       *int     `json:"order_item_id"`
+	Description       string   `json:"description"`
+	OrderedQuantity   float64  `json:"ordered_quantity"`
+	ReceivedQuantity  float64  `json:"received_quantity"` // Received and not billed by other approved invoices
+	InvoicedQuantity  float64  `json:"invoiced_quantity"`
+	OrderedUnitCost   float64  `json:"ordered_unit_cost"`
+	InvoicedUnitCost  float64  `json:"invoiced_unit_cost"`
+	DeliveryID        *int     `json:"delivery_id"`
+	DeliveredQuantity float64  `json:"delivered_quantity"`  // Quantity of the delivery billed by the line
+	DeliveredUnitCost float64  `json:"delivered_unit_cost"` // Unit cost recorded by the delivery billed by the line
+	Status            string   `json:"status"`              // matched or mismatch
+	Issues            []string `json:"issues"`
+}
+
+// InvoiceMatch is the outcome of matching an invoice against its order and deliveries.
+type InvoiceMatch struct {
+	Invoice              models.Invoice     `json:"invoice"`
+	QuantityTolerancePct float64            `json:"quantity_tolerance_pct"`
+	PriceTolerancePct    float64            `json:"price_tolerance_pct"`
+	Lines                []InvoiceLineMatch `json:"lines"`
+}
+
+// CreateInvoice records a vendor invoice together with its lines and matches it.
+// This method links each line to the order item and delivery it bills, and computes
+// the line and invoice totals from quantities and unit costs.
+//
+// Parameters:
+//   - invoice: The invoice data to create
+//   - lines: The charges on the invoice
+//
+// Returns:
+//   - *InvoiceMatch: The outcome of matching the new invoice
+//   - error: ErrDuplicateInvoice, invalid invoice data, or any error that occurred while saving
+//
+// Business rules:
+//   - Account must exist, the invoice must have a number and at least one line
+//   - Quantities must be positive and unit costs cannot be negative
+//   - Lines billing a delivery received against an order are linked to its order item
+//   - Every order item billed must be on the same order, which becomes the invoice's order
+//   - The vendor defaults to the vendor of the first order item billed
+//   - A vendor's invoice number can only be recorded once
+func (s *Service) CreateInvoice(invoice *models.Invoice, lines []models.InvoiceLine) (*InvoiceMatch, error) {
+	// Validate that the account exists
+	_, err := s.accounts.GetByID(invoice.AccountID)
+	if err != nil {
+		return nil, errors.New("invalid account ID")
+	}
+
+	invoice.InvoiceNumber = strings.TrimSpace(invoice.InvoiceNumber)
+	if invoice.InvoiceNumber == "" {
+		return nil, errors.New("invoice number is required")
+	}
+	if len(lines) == 0 {
+		return nil, errors.New("invoice must contain at least one line")
+	}
+	if invoice.OrderID != nil {
+		order, err := s.orders.GetByID(*invoice.OrderID)
+		if err != nil || order.AccountID != invoice.AccountID {
+			return nil, errors.New("invalid order ID")
+		}
+	}
+
+	var billedItem *models.OrderItem // The first order item billed, for the default vendor
+	invoice.Total = 0
+	for i := range lines {
+		line := &lines[i]
+		if line.Quantity <= 0 {
+			return nil, errors.New("invoice line quantity must be positive")
+		}
+		if line.UnitCost < 0 {
+			return nil, errors.New("invoice line unit cost cannot be negative")
+		}
+
+		if line.DeliveryID != nil {
+			delivery, err := s.deliveries.GetByID(*line.DeliveryID)
+			if err != nil || delivery.AccountID != invoice.AccountID {
+				return nil, errors.New("invalid delivery ID")
+			}
+			if line.OrderItemID == nil {
+				line.OrderItemID = delivery.OrderItemID
+			} else if delivery.OrderItemID != nil && *delivery.OrderItemID != *line.OrderItemID {
+				return nil, errors.New("delivery was not received against the invoiced order item")
+			}
+		}
+
+		if line.OrderItemID != nil {
+			item, err := s.orderItems.GetByID(*line.OrderItemID)
+			if err != nil {
+				return nil, errors.New("invalid order item ID")
+			}
+			if invoice.OrderID == nil {
+				order, err := s.orders.GetByID(item.OrderID)
+				if err != nil || order.AccountID != invoice.AccountID {
+					return nil, errors.New("invalid order item ID")
+				}
+				invoice.OrderID = &order.ID
+			} else if item.OrderID != *invoice.OrderID {
+				return nil, errors.New("all invoiced order items must be on the invoice's order")
+			}
+			if billedItem == nil {
+				billedItem = item
+			}
+			if strings.TrimSpace(line.Description) == "" {
+				if inventoryItem, err := s.inventoryItems.GetByID(item.InventoryItemID); err == nil {
+					line.Description = inventoryItem.Name
+				}
+			}
+		}
+
+		line.ID = 0
+		line.MatchStatus = models.InvoiceLineMatchPending
+		line.TotalCost = line.Quantity * line.UnitCost
+		invoice.Total += line.TotalCost
+	}
+
+	// Fall back to the vendor the billed items were ordered from
+	if invoice.VendorID == nil && strings.TrimSpace(invoice.Vendor) == "" && billedItem != nil {
+		invoice.VendorID = billedItem.VendorID
+		invoice.Vendor = billedItem.Vendor
+	}
+	vendor, err := s.resolveVendor(invoice.AccountID, invoice.VendorID, invoice.Vendor)
+	if err != nil {
+		return nil, err
+	}
+	if vendor == nil {
+		return nil, errors.New("invoice vendor is required")
+	}
+	invoice.VendorID = &vendor.ID
+	invoice.Vendor = vendor.Name
+
+	if _, err := s.invoices.GetByNumber(invoice.AccountID, invoice.Vendor, invoice.InvoiceNumber); err == nil {
+		return nil, ErrDuplicateInvoice
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if invoice.InvoiceDate.IsZero() {
+		invoice.InvoiceDate = time.Now()
+	}
+	invoice.Status = models.InvoiceStatusPending
+	invoice.MatchedAt, invoice.ApprovedAt, invoice.ApprovedBy = nil, nil, nil
+
+	var match *InvoiceMatch
+	err = s.withTransaction(func(tx *Service) error {
+		if err := tx.invoices.Create(invoice); err != nil {
+			return err
+		}
+		for i := range lines {
+			lines[i].InvoiceID = invoice.ID
+			if err := tx.invoiceLines.Create(&lines[i]); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		match, err = tx.MatchInvoice(invoice)
+		return err
+	})
+	return match, err
+}
+
+// GetInvoice retrieves an invoice together with its lines.
+//
+// Parameters:
+//   - id: The unique identifier of the invoice to retrieve
+//
+// Returns:
+//   - *models.Invoice: The invoice data if found
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetInvoice(id int) (*models.Invoice, error) {
+	invoice, err := s.invoices.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Lines, err = s.invoiceLines.GetByInvoiceID(id); err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// GetInvoicesByAccount retrieves the invoices of an account, newest first.
+// Listing the invoices that need review gives the mismatches to resolve before payment.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//   - status: Only return invoices with this status; empty returns every invoice
+//
+// Returns:
+//   - []models.Invoice: The invoices, without their lines
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetInvoicesByAccount(accountID int, status string) ([]models.Invoice, error) {
+	if status == "" {
+		return s.invoices.GetByAccountID(accountID)
+	}
+	return s.invoices.GetByStatus(accountID, status)
+}
+
+// MatchInvoice performs a three-way match of an invoice against what was ordered and
+// received. Each line's quantity is compared with the quantity of its order item received
+// and not yet billed by other approved invoices, and its unit cost with the ordered unit
+// cost. Lines billing a delivery are also compared with the quantity and unit cost the
+// delivery recorded. Every comparison uses the account's tolerances. The invoice is approved
+// for payment when every line matches, and marked for review otherwise.
+//
+// Parameters:
+//   - invoice: The invoice to match; its status is updated in place
+//
+// Returns:
+//   - *InvoiceMatch: The outcome of the match for each line
+//   - error: ErrInvoiceAlreadyApproved, or any error that occurred during the match
+//
+// Business rules:
+//   - Tolerances apply in both directions, so undercharges are reviewed as well
+//   - Lines not linked to an order item, such as freight, always need review
+//   - Goods received count toward the quantity whether or not they were substitutes
+//   - A line billing a delivery is checked against that delivery even when the order matches
+func (s *Service) MatchInvoice(invoice *models.Invoice) (*InvoiceMatch, error) {
+	if invoice.Status == models.InvoiceStatusApproved {
+		return nil, ErrInvoiceAlreadyApproved
+	}
+	account, err := s.accounts.GetByID(invoice.AccountID)
+	if err != nil {
+		return nil, errors.New("invalid account ID")
+	}
+	lines, err := s.invoiceLines.GetByInvoiceID(invoice.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	match := &InvoiceMatch{
+		QuantityTolerancePct: account.InvoiceQuantityTolerancePct,
+		PriceTolerancePct:    account.InvoicePriceTolerancePct,
+		Lines:                make([]InvoiceLineMatch, 0, len(lines)),
+	}
+	billed := make(map[int]float64) // Quantity of each order item billed by earlier lines of this invoice
+	matched := true
+	for i := range lines {
+		line := &lines[i]
+		result := InvoiceLineMatch{
+			InvoiceLineID:    line.ID,
+			OrderItemID:      line.OrderItemID,
+			Description:      line.Description,
+			InvoicedQuantity: line.Quantity,
+			InvoicedUnitCost: line.UnitCost,
+			Issues:           []string{},
+		}
+
+		if line.OrderItemID == nil {
+			result.Issues = append(result.Issues, InvoiceIssueNotOrdered)
+		} else {
+			item, err := s.orderItems.GetByID(*line.OrderItemID)
+			if err != nil {
+				return nil, err
+			}
+			approved, err := s.invoiceLines.GetApprovedQuantity(item.ID, invoice.ID)
+			if err != nil {
+				return nil, err
+			}
+
+			result.OrderedQuantity = item.Quantity
+			result.OrderedUnitCost = item.UnitCost
+			result.ReceivedQuantity = math.Max(item.ReceivedQuantity-approved-billed[item.ID], 0)
+			billed[item.ID] += line.Quantity
+
+			quantityTolerance := result.ReceivedQuantity * account.InvoiceQuantityTolerancePct / 100
+			if math.Abs(line.Quantity-result.ReceivedQuantity) > quantityTolerance+1e-9 {
+				result.Issues = append(result.Issues, InvoiceIssueQuantity)
+			}
+			priceTolerance := item.UnitCost*account.InvoicePriceTolerancePct/100 + invoiceRoundingTolerance
+			if math.Abs(line.UnitCost-item.UnitCost) > priceTolerance {
+				result.Issues = append(result.Issues, InvoiceIssuePrice)
+			}
+		}
+
+		if line.DeliveryID != nil {
+			delivery, err := s.deliveries.GetByID(*line.DeliveryID)
+			if err != nil {
+				return nil, err
+			}
+			result.DeliveryID = line.DeliveryID
+			result.DeliveredQuantity = delivery.Quantity
+			if delivery.Quantity > 0 {
+				result.DeliveredUnitCost = delivery.Cost / delivery.Quantity
+			}
+
+			quantityTolerance := delivery.Quantity * account.InvoiceQuantityTolerancePct / 100
+			if math.Abs(line.Quantity-delivery.Quantity) > quantityTolerance+1e-9 {
+				result.Issues = append(result.Issues, InvoiceIssueDeliveredQuantity)
+			}
+			priceTolerance := result.DeliveredUnitCost*account.InvoicePriceTolerancePct/100 + invoiceRoundingTolerance
+			if math.Abs(line.UnitCost-result.DeliveredUnitCost) > priceTolerance {
+				result.Issues = append(result.Issues, InvoiceIssueDeliveredUnitCost)
+			}
+		}
+
+		line.MatchStatus = models.InvoiceLineMatchMatched
+		if len(result.Issues) > 0 {
+			line.MatchStatus = models.InvoiceLineMatchMismatch
+			matched = false
+		}
+		result.Status = line.MatchStatus
+		match.Lines = append(match.Lines, result)
+	}
+
+	now := time.Now()
+	invoice.MatchedAt = &now
+	invoice.Status = models.InvoiceStatusNeedsReview
+	if matched {
+		invoice.Status = models.InvoiceStatusApproved
+		invoice.ApprovedAt = &now
+		invoice.ApprovedBy = nil
+	}
+
+	err = s.withTransaction(func(tx *Service) error {
+		for i := range lines {
+			if err := tx.invoiceLines.Update(&lines[i]); err != nil {
+				return err
+			}
+		}
+		return tx.invoices.Update(invoice)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	invoice.Lines = lines
+	match.Invoice = *invoice
+	return match, nil
+}
+
+// ApproveInvoice approves an invoice for payment after its mismatches were reviewed.
+//
+// Parameters:
+//   - invoice: The invoice to approve; updated in place
+//   - userID: The user who reviewed the invoice
+//
+// Returns:
+//   - error: ErrInvoiceAlreadyApproved, or any error that occurred during the update
+func (s *Service) ApproveInvoice(invoice *models.Invoice, userID int) error {
+	if invoice.Status == models.InvoiceStatusApproved {
+		return ErrInvoiceAlreadyApproved
+	}
+
+	now := time.Now()
+	invoice.Status = models.InvoiceStatusApproved
+	invoice.ApprovedAt = &now
+	invoice.ApprovedBy = &userID
+	return s.invoices.Update(invoice)
+}
+
+// UpdateInvoiceMatchSettings changes how closely invoices must match what was ordered
+// and received to be approved for payment without review.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//   - quantityTolerancePct: The accepted difference from the quantity received, as a percentage
+//   - priceTolerancePct: The accepted difference from the ordered unit cost, as a percentage
+//
+// Returns:
+//   - *models.Account: The updated account
+//   - error: Any error that occurred during the update
+func (s *Service) UpdateInvoiceMatchSettings(accountID int, quantityTolerancePct, priceTolerancePct float64) (*models.Account, error) {
+	if quantityTolerancePct < 0 || priceTolerancePct < 0 {
+		return nil, errors.New("invoice match tolerances cannot be negative")
+	}
+
+	account, err := s.accounts.GetByID(accountID)
+	if err != nil {
+		return nil, errors.New("invalid account ID")
+	}
+
+	account.InvoiceQuantityTolerancePct = quantityTolerancePct
+	account.InvoicePriceTolerancePct = priceTolerancePct
+	if err := s.accounts.Update(account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// Vendor performance operations
+// These methods measure vendors against the orders placed with them.
+
+// VendorScorecard summarizes how a vendor performed over a reporting period.
+// Rates and drift values are percentages.
+type VendorScorecard struct {
+	VendorID          int                `json:"vendor_id"`
+	VendorName        string             `json:"vendor_name"`
+	OrderLines        int                `json:"order_lines"`         // Order items placed during the period
+	DeliveredLines    int                `json:"delivered_lines"`     // Order items with at least one matching delivery
+	OnTimeLines       int                `json:"on_time_lines"`       // Delivered lines that arrived by the expected date
+	LateLines         int                `json:"late_lines"`          // Delivered lines that arrived after the expected date
+	OnTimeRate        float64            `json:"on_time_rate"`        // On-time lines as a share of lines with an expected date
+	AverageDaysLate   float64            `json:"average_days_late"`   // Mean lateness of late lines, in days
+	QuantityOrdered   float64            `json:"quantity_ordered"`    // Total quantity ordered
+	QuantityReceived  float64            `json:"quantity_received"`   // Total quantity received against those orders
+	FillRate          float64            `json:"fill_rate"`           // Received quantity as a share of ordered quantity
+	AveragePriceDrift float64            `json:"average_price_drift"` // Mean unit price change across items
+	PriceDrift        []VendorPriceDrift `json:"price_drift"`
+}
+
+// VendorPriceDrift tracks how the unit price of one item from a vendor moved during a period.
+type VendorPriceDrift struct {
+	InventoryItemID int       `json:"inventory_item_id"`
+	ItemName        string    `json:"item_name"`
+	FirstUnitPrice  float64   `json:"first_unit_price"`
+	LastUnitPrice   float64   `json:"last_unit_price"`
+	FirstDate       time.Time `json:"first_date"`
+	LastDate        time.Time `json:"last_date"`
+	ChangePercent   float64   `json:"change_percent"`
+}
+
+// GetVendorScorecards builds a performance scorecard for each vendor an account
+// ordered from or received deliveries from during a period.
+//
+// Order items placed between startDate and endDate are matched to the deliveries
+// of the same item from the same vendor on or after the order date, oldest first,
+// with each delivery's quantity consumed only once. The first matched delivery is
+// compared to the order's expected date to measure timeliness, and the matched
+// quantity gives the fill rate. Price drift compares the first and last delivered
+// unit price (cost divided by quantity) of each item within the period.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//   - startDate: The start of the reporting period
+//   - endDate: The end of the reporting period
+//
+// Returns:
+//   - []VendorScorecard: Scorecards ordered by vendor name
+//   - error: Any error that occurred while building the report
+//
+// Business rules:
+//   - Cancelled orders are ignored
+//   - Lines without an expected date count toward fill rate but not timeliness
+//   - Records that cannot be tied to a vendor record are ignored
+func (s *Service) GetVendorScorecards(accountID int, startDate, endDate time.Time) ([]VendorScorecard, error) {
+	if endDate.Before(startDate) {
+		return nil, errors.New("end date must not be before start date")
+	}
+
+	vendors, err := s.vendors.GetByAccountID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	vendorsByID := make(map[int]models.Vendor, len(vendors))
+	vendorIDsByName := make(map[string]int, len(vendors))
+	for _, vendor := range vendors {
+		vendorsByID[vendor.ID] = vendor
+		vendorIDsByName[normalizeVendorName(vendor.Name)] = vendor.ID
+	}
+
+	// vendorKey resolves a record's vendor, falling back to its free-text name
+	vendorKey := func(vendorID *int, name string) (int, bool) {
+		if vendorID != nil {
+			if _, ok := vendorsByID[*vendorID]; ok {
+				return *vendorID, true
+			}
+		}
+		id, ok := vendorIDsByName[normalizeVendorName(name)]
+		return id, ok
+	}
+
+	scorecards := make(map[int]*VendorScorecard)
+	scorecardFor := func(vendorID int) *VendorScorecard {
+		scorecard, ok := scorecards[vendorID]
+		if !ok {
+			scorecard = &VendorScorecard{
+				VendorID:   vendorID,
+				VendorName: vendorsByID[vendorID].Name,
+				PriceDrift: []VendorPriceDrift{},
+			}
+			scorecards[vendorID] = scorecard
+		}
+		return scorecard
+	}
+
+	// Deliveries are consumed oldest first as they are matched to order lines
+	deliveries, err := s.deliveries.GetByAccountID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].DeliveryDate.Before(deliveries[j].DeliveryDate)
+	})
+	remaining := make(map[int]float64, len(deliveries))
+	for _, delivery := range deliveries {
+		remaining[delivery.ID] = delivery.Quantity
+	}
+
+	orders, err := s.orders.GetByAccountID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(orders, func(i, j int) bool {
+		return orders[i].OrderDate.Before(orders[j].OrderDate)
+	})
+
+	daysLate := make(map[int]float64)
+	for _, order := range orders {
+		if order.Status == "cancelled" || order.OrderDate.Before(startDate) || order.OrderDate.After(endDate) {
+			continue
+		}
+
+		items, err := s.orderItems.GetByOrderID(order.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range items {
+			vendorID, ok := vendorKey(item.VendorID, item.Vendor)
+			if !ok {
+				continue
+			}
+			scorecard := scorecardFor(vendorID)
+			scorecard.OrderLines++
+			scorecard.QuantityOrdered += item.Quantity
+
+			// Match deliveries of this item from this vendor until the line is filled
+			var received float64
+			var firstArrival *time.Time
+			for i := range deliveries {
+				delivery := &deliveries[i]
+				if received >= item.Quantity {
+					break
+				}
+				if delivery.InventoryItemID != item.InventoryItemID || remaining[delivery.ID] <= 0 {
+					continue
+				}
+				if dateOnly(delivery.DeliveryDate).Before(dateOnly(order.OrderDate)) {
+					continue
+				}
+				if deliveryVendorID, ok := vendorKey(delivery.VendorID, delivery.Vendor); !ok || deliveryVendorID != vendorID {
+					continue
+				}
+
+				quantity := math.Min(remaining[delivery.ID], item.Quantity-received)
+				remaining[delivery.ID] -= quantity
+				received += quantity
+				if firstArrival == nil {
+					firstArrival = &delivery.DeliveryDate
+				}
+			}
+
+			scorecard.QuantityReceived += received
+			if firstArrival == nil {
+				continue
+			}
+			scorecard.DeliveredLines++
+
+			if order.ExpectedDate.IsZero() {
+				continue
+			}
+			late := dateOnly(*firstArrival).Sub(dateOnly(order.ExpectedDate)).Hours() / 24
+			if late > 0 {
+				scorecard.LateLines++
+				daysLate[vendorID] += late
+			} else {
+				scorecard.OnTimeLines++
+			}
+		}
+	}
+
+	// Price drift is measured on deliveries received within the period
+	inventoryItems, err := s.inventoryItems.GetByAccountID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	itemNames := make(map[int]string, len(inventoryItems))
+	for _, item := range inventoryItems {
+		itemNames[item.ID] = item.Name
+	}
+
+	type driftKey struct{ vendorID, itemID int }
+	drifts := make(map[driftKey]*VendorPriceDrift)
+	var driftOrder []driftKey
+	for _, delivery := range deliveries {
+		if delivery.Quantity <= 0 || delivery.DeliveryDate.Before(startDate) || delivery.DeliveryDate.After(endDate) {
+			continue
+		}
+		vendorID, ok := vendorKey(delivery.VendorID, delivery.Vendor)
+		if !ok {
+			continue
+		}
+
+		unitPrice := delivery.Cost / delivery.Quantity
+		key := driftKey{vendorID, delivery.InventoryItemID}
+		drift, ok := drifts[key]
+		if !ok {
+			drifts[key] = &VendorPriceDrift{
+				InventoryItemID: delivery.InventoryItemID,
+				ItemName:        itemNames[delivery.InventoryItemID],
+				FirstUnitPrice:  unitPrice,
+				LastUnitPrice:   unitPrice,
+				FirstDate:       delivery.DeliveryDate,
+				LastDate:        delivery.DeliveryDate,
+			}
+			driftOrder = append(driftOrder, key)
+			continue
+		}
+		drift.LastUnitPrice = unitPrice
+		drift.LastDate = delivery.DeliveryDate
+	}
+
+	for _, key := range driftOrder {
+		drift := drifts[key]
+		scorecard := scorecardFor(key.vendorID)
+		if drift.FirstUnitPrice > 0 {
+			drift.ChangePercent = (drift.LastUnitPrice - drift.FirstUnitPrice) / drift.FirstUnitPrice * 100
+		}
+		scorecard.PriceDrift = append(scorecard.PriceDrift, *drift)
+	}
+
+	result := make([]VendorScorecard, 0, len(scorecards))
+	for vendorID, scorecard := range scorecards {
+		if timed := scorecard.OnTimeLines + scorecard.LateLines; timed > 0 {
+			scorecard.OnTimeRate = float64(scorecard.OnTimeLines) / float64(timed) * 100
+		}
+		if scorecard.LateLines > 0 {
+			scorecard.AverageDaysLate = daysLate[vendorID] / float64(scorecard.LateLines)
+		}
+		if scorecard.QuantityOrdered > 0 {
+			scorecard.FillRate = scorecard.QuantityReceived / scorecard.QuantityOrdered * 100
+		}
+		if len(scorecard.PriceDrift) > 0 {
+			var total float64
+			for _, drift := range scorecard.PriceDrift {
+				total += drift.ChangePercent
+			}
+			scorecard.AveragePriceDrift = total / float64(len(scorecard.PriceDrift))
+		}
+		result = append(result, *scorecard)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].VendorName < result[j].VendorName
+	})
+
+	return result, nil
+}
+
+// dateOnly truncates a timestamp to midnight so that comparisons ignore the time of day.
+func dateOnly(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// Pricing operations
+// These methods handle the price history recorded from deliveries and the
+// item costs derived from it.
+
+// movingAverageWindow is the number of most recent purchases averaged by the moving average costing method
+const movingAverageWindow = 5
+
+// PriceTrend summarizes the prices paid for an inventory item over a period.
+// Points are ordered oldest first so they can be charted directly.
+type PriceTrend struct {
+	InventoryItemID int                   `json:"inventory_item_id"`
+	ItemName        string                `json:"item_name"`
+	Points          []models.PriceHistory `json:"points"`
+	MinPrice        float64               `json:"min_price"`
+	MaxPrice        float64               `json:"max_price"`
+	AveragePrice    float64               `json:"average_price"`  // Weighted by quantity delivered
+	LatestPrice     float64               `json:"latest_price"`   // Most recent unit price in the period
+	ChangePercent   float64               `json:"change_percent"` // Change from the first to the latest price
+}
+
+// UpdateCostSettings changes how an account's item costs are computed and
+// when vendor price alerts are raised. All item costs in the account are
+// recomputed with the new costing method.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//   - costingMethod: One of "last", "moving_average", or "fifo"
+//   - priceAlertPct: The price change percentage that raises an alert (0 disables alerts)
+//
+// Returns:
+//   - *models.Account: The updated account
+//   - error: Any error that occurred during the update
+func (s *Service) UpdateCostSettings(accountID int, costingMethod string, priceAlertPct float64) (*models.Account, error) {
+	if !isValidCostingMethod(costingMethod) {
+		return nil, fmt.Errorf("invalid costing method: %s", costingMethod)
+	}
+	if priceAlertPct < 0 {
+		return nil, errors.New("price alert threshold cannot be negative")
+	}
+
+	account, err := s.accounts.GetByID(accountID)
+	if err != nil {
+		return nil, errors.New("invalid account ID")
+	}
+
+	account.CostingMethod = costingMethod
+	account.PriceAlertPct = priceAlertPct
+	if err := s.accounts.Update(account); err != nil {
+		return nil, err
+	}
+
+	items, err := s.inventoryItems.GetByAccountID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if _, err := s.RecomputeItemCost(item.ID, costingMethod); err != nil {
+			return nil, err
+		}
+	}
+
+	return account, nil
+}
+
+// GetPriceHistory retrieves the recorded prices for an inventory item, oldest first.
+// A zero start and end date returns the complete history.
+//
+// Parameters:
+//   - itemID: The unique identifier of the inventory item
+//   - startDate: The start of the period (optional)
+//   - endDate: The end of the period (optional)
+//
+// Returns:
+//   - []models.PriceHistory: The price entries in the period
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetPriceHistory(itemID int, startDate, endDate time.Time) ([]models.PriceHistory, error) {
+	if startDate.IsZero() && endDate.IsZero() {
+		return s.priceHistory.GetByItemID(itemID)
+	}
+	if endDate.IsZero() {
+		endDate = time.Now()
+	}
+	return s.priceHistory.GetByItemIDAndDateRange(itemID, startDate, endDate)
+}
+
+// GetPriceTrend summarizes the price history of an inventory item for charting.
+//
+// Parameters:
+//   - itemID: The unique identifier of the inventory item
+//   - startDate: The start of the period (optional)
+//   - endDate: The end of the period (optional)
+//
+// Returns:
+//   - *PriceTrend: The price points and summary statistics for the period
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetPriceTrend(itemID int, startDate, endDate time.Time) (*PriceTrend, error) {
+	item, err := s.inventoryItems.GetByID(itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	points, err := s.GetPriceHistory(itemID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	trend := &PriceTrend{
+		InventoryItemID: item.ID,
+		ItemName:        item.Name,
+		Points:          points,
+	}
+	if len(points) == 0 {
+		return trend, nil
+	}
+
+	trend.MinPrice = points[0].UnitPrice
+	trend.MaxPrice = points[0].UnitPrice
+	var totalCost, totalQuantity float64
+	for _, point := range points {
+		trend.MinPrice = math.Min(trend.MinPrice, point.UnitPrice)
+		trend.MaxPrice = math.Max(trend.MaxPrice, point.UnitPrice)
+		totalCost += point.UnitPrice * point.Quantity
+		totalQuantity += point.Quantity
+	}
+	if totalQuantity > 0 {
+		trend.AveragePrice = totalCost / totalQuantity
+	}
+
+	first := points[0].UnitPrice
+	trend.LatestPrice = points[len(points)-1].UnitPrice
+	if first > 0 {
+		trend.ChangePercent = (trend.LatestPrice - first) / first * 100
+	}
+
+	return trend, nil
+}
+
+// CalculateItemCost computes an inventory item's unit cost from its price history.
+// Items without any recorded prices keep their current cost.
+//
+// Costing methods:
+//   - last: the most recent unit price paid
+//   - moving_average: the quantity-weighted average of the last few purchases
+//   - fifo: the value of the stock on hand, assuming the oldest stock is used first
+//
+// Parameters:
+//   - item: The inventory item to cost
+//   - method: The costing method to apply
+//
+// Returns:
+//   - float64: The computed unit cost
+//   - error: Any error that occurred during the calculation
+func (s *Service) CalculateItemCost(item *models.InventoryItem, method string) (float64, error) {
+	if !isValidCostingMethod(method) {
+		return 0, fmt.Errorf("invalid costing method: %s", method)
+	}
+
+	history, err := s.priceHistory.GetByItemID(item.ID)
+	if err != nil {
+		return 0, err
+	}
+	if len(history) == 0 {
+		return item.CostPerUnit, nil
+	}
+	latest := history[len(history)-1]
+
+	switch method {
+	case models.CostingMethodMovingAverage:
+		window := history
+		if len(window) > movingAverageWindow {
+			window = window[len(window)-movingAverageWindow:]
+		}
+		return weightedUnitPrice(window), nil
+
+	case models.CostingMethodFIFO:
+		stock, err := s.currentStockForItem(item.AccountID, item.ID)
+		if err != nil {
+			return 0, err
+		}
+		return fifoUnitPrice(history, stock), nil
+
+	default:
+		return latest.UnitPrice, nil
+	}
+}
+
+// RecomputeItemCost recalculates and stores an inventory item's unit cost.
+//
+// Parameters:
+//   - itemID: The unique identifier of the inventory item
+//   - method: The costing method to apply; empty uses the account's costing method
+//
+// Returns:
+//   - *models.InventoryItem: The item with its updated cost
+//   - error: Any error that occurred during the update
+func (s *Service) RecomputeItemCost(itemID int, method string) (*models.InventoryItem, error) {
+	item, err := s.inventoryItems.GetByID(itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	if method == "" {
+		account, err := s.accounts.GetByID(item.AccountID)
+		if err != nil {
+			return nil, errors.New("invalid account ID")
+		}
+		method = accountCostingMethod(account)
+	}
+
+	cost, err := s.CalculateItemCost(item, method)
+	if err != nil {
+		return nil, err
+	}
+
+	item.CostPerUnit = cost
+	if err := s.inventoryItems.Update(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// GetPriceAlertsByAccount retrieves all price alerts for an account, newest first.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//
+// Returns:
+//   - []models.PriceAlert: List of price alerts belonging to the account
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetPriceAlertsByAccount(accountID int) ([]models.PriceAlert, error) {
+	return s.priceAlerts.GetByAccountID(accountID)
+}
+
+// GetUnnotifiedPriceAlerts retrieves the price alerts that have not been emailed yet.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//
+// Returns:
+//   - []models.PriceAlert: List of pending price alerts, oldest first
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetUnnotifiedPriceAlerts(accountID int) ([]models.PriceAlert, error) {
+	return s.priceAlerts.GetUnnotifiedByAccountID(accountID)
+}
+
+// MarkPriceAlertsNotified records that price alerts have been emailed.
+//
+// Parameters:
+//   - ids: The unique identifiers of the alerts
+//   - notifiedAt: When the alerts were sent
+//
+// Returns:
+//   - error: Any error that occurred during the update
+func (s *Service) MarkPriceAlertsNotified(ids []int, notifiedAt time.Time) error {
+	return s.priceAlerts.MarkNotified(ids, notifiedAt)
+}
+
+// recordDeliveryPrice adds a delivery's unit price to the item's price history,
+// raises a price alert if the vendor's price moved beyond the account threshold,
+// and recomputes the item's cost.
+func (s *Service) recordDeliveryPrice(delivery *models.Delivery) error {
+	if delivery.Quantity <= 0 {
+		return nil
+	}
+
+	account, err := s.accounts.GetByID(delivery.AccountID)
+	if err != nil {
+		return errors.New("invalid account ID")
+	}
+
+	entry := &models.PriceHistory{
+		AccountID:       delivery.AccountID,
+		InventoryItemID: delivery.InventoryItemID,
+		VendorID:        delivery.VendorID,
+		DeliveryID:      &delivery.ID,
+		Quantity:        delivery.Quantity,
+		UnitPrice:       delivery.Cost / delivery.Quantity,
+		RecordedAt:      delivery.DeliveryDate,
+	}
+
+	// Compare against the last price paid to the same vendor
+	if delivery.VendorID != nil && account.PriceAlertPct > 0 {
+		previous, err := s.priceHistory.GetLatestByItemAndVendor(delivery.InventoryItemID, *delivery.VendorID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil && previous.UnitPrice > 0 {
+			change := (entry.UnitPrice - previous.UnitPrice) / previous.UnitPrice * 100
+			if math.Abs(change) > account.PriceAlertPct {
+				alert := &models.PriceAlert{
+					AccountID:       delivery.AccountID,
+					InventoryItemID: delivery.InventoryItemID,
+					VendorID:        delivery.VendorID,
+					DeliveryID:      &delivery.ID,
+					PreviousPrice:   previous.UnitPrice,
+					NewPrice:        entry.UnitPrice,
+					ChangePercent:   change,
+				}
+				if err := s.priceAlerts.Create(alert); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if err := s.priceHistory.Create(entry); err != nil {
+		return err
+	}
+
+	_, err = s.RecomputeItemCost(delivery.InventoryItemID, accountCostingMethod(account))
+	return err
+}
+
+// syncDeliveryPrice brings the price history entry of a corrected delivery in line with
+// its new quantity and cost, or removes the entry when the delivery is deleted (nil),
+// and recomputes the cost of the items involved.
+func (s *Service) syncDeliveryPrice(previous, delivery *models.Delivery) error {
+	entry, err := s.priceHistory.GetByDeliveryID(previous.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	switch {
+	case entry == nil && delivery != nil && delivery.Quantity > 0:
+		// The delivery had no price until now; record it as a new delivery would
+		if err := s.recordDeliveryPrice(delivery); err != nil {
+			return err
+		}
+	case entry != nil && (delivery == nil || delivery.Quantity <= 0):
+		if err := s.priceHistory.Delete(entry.ID); err != nil {
+			return err
+		}
+	case entry != nil:
+		entry.InventoryItemID = delivery.InventoryItemID
+		entry.VendorID = delivery.VendorID
+		entry.Quantity = delivery.Quantity
+		entry.UnitPrice = delivery.Cost / delivery.Quantity
+		entry.RecordedAt = delivery.DeliveryDate
+		if err := s.priceHistory.Update(entry); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.RecomputeItemCost(previous.InventoryItemID, ""); err != nil {
+		return err
+	}
+	if delivery != nil && delivery.InventoryItemID != previous.InventoryItemID {
+		if _, err := s.RecomputeItemCost(delivery.InventoryItemID, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// currentStockForItem returns the calculated stock on hand for a single item.
+func (s *Service) currentStockForItem(accountID, itemID int) (float64, error) {
+	level, err := s.inventoryLevels.GetByItemID(itemID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return level.Quantity, nil
+}
+
+// weightedUnitPrice returns the quantity-weighted average price of price history entries.
+func weightedUnitPrice(entries []models.PriceHistory) float64 {
+	var totalCost, totalQuantity float64
+	for _, entry := range entries {
+		totalCost += entry.UnitPrice * entry.Quantity
+		totalQuantity += entry.Quantity
+	}
+	if totalQuantity == 0 {
+		return entries[len(entries)-1].UnitPrice
+	}
+	return totalCost / totalQuantity
+}
+
+// fifoUnitPrice returns the unit cost of stock under FIFO, where the newest purchases are
+// what remain on hand. Entries must be ordered oldest first; without stock the latest price is used.
+func fifoUnitPrice(entries []models.PriceHistory, stock float64) float64 {
+	if stock <= 0 {
+		return entries[len(entries)-1].UnitPrice
+	}
+
+	var value, covered float64
+	for i := len(entries) - 1; i >= 0 && covered < stock; i-- {
+		quantity := math.Min(entries[i].Quantity, stock-covered)
+		value += quantity * entries[i].UnitPrice
+		covered += quantity
+	}
+	// Stock older than the recorded history is valued at the oldest known price
+	if covered < stock {
+		value += (stock - covered) * entries[0].UnitPrice
+	}
+	return value / stock
+}
+
+// accountCostingMethod returns an account's costing method, defaulting to last price.
+func accountCostingMethod(account *models.Account) string {
+	if account.CostingMethod == "" {
+		return models.CostingMethodLast
+	}
+	return account.CostingMethod
+}
+
+// isValidCostingMethod checks if a method is a supported costing method.
+func isValidCostingMethod(method string) bool {
+	switch method {
+	case models.CostingMethodLast, models.CostingMethodMovingAverage, models.CostingMethodFIFO:
+		return true
+	}
+	return false
+}
+
+// Valuation operations
+// These methods value counted inventory at the end of an accounting period from the
+// prices paid for it, and derive the cost of goods sold over the period.
+
+// Valuation methods accepted by GetInventoryValuation
+const (
+	ValuationMethodFIFO            = models.CostingMethodFIFO
+	ValuationMethodWeightedAverage = "weighted_average"
+)
+
+// ValuationMethods lists the supported valuation methods
+var ValuationMethods = []string{ValuationMethodFIFO, ValuationMethodWeightedAverage}
+
+// ErrNoClosingCount is returned when no inventory snapshot was taken during a valuation period
+var ErrNoClosingCount = errors.New("no inventory snapshot was taken during the period")
+
+// InventoryValuation values an account's inventory over an accounting period.
+// Cost of goods sold is the opening value plus purchases less the closing value.
+type InventoryValuation struct {
+	AccountID         int             `json:"account_id"`
+	Method            string          `json:"method"`
+	PeriodStart       time.Time       `json:"period_start"`
+	PeriodEnd         time.Time       `json:"period_end"`
+	OpeningSnapshotID *int            `json:"opening_snapshot_id"` // Nil when nothing was counted before the period
+	OpeningCountedAt  *time.Time      `json:"opening_counted_at"`
+	ClosingSnapshotID int             `json:"closing_snapshot_id"`
+	ClosingCountedAt  time.Time       `json:"closing_counted_at"`
+	OpeningValue      float64         `json:"opening_value"`
+	Purchases         float64         `json:"purchases"` // Cost of deliveries between the two counts
+	ClosingValue      float64         `json:"closing_value"`
+	COGS              float64         `json:"cogs"`
+	Items             []ItemValuation `json:"items"`
+}
+
+// ItemValuation is the valuation of a single inventory item over a period.
+type ItemValuation struct {
+	InventoryItemID   int     `json:"inventory_item_id"`
+	ItemName          string  `json:"item_name"`
+	Unit              string  `json:"unit"`
+	OpeningQuantity   float64 `json:"opening_quantity"`
+	OpeningUnitCost   float64 `json:"opening_unit_cost"`
+	OpeningValue      float64 `json:"opening_value"`
+	PurchasedQuantity float64 `json:"purchased_quantity"`
+	Purchases         float64 `json:"purchases"`
+	ClosingQuantity   float64 `json:"closing_quantity"`
+	ClosingUnitCost   float64 `json:"closing_unit_cost"`
+	ClosingValue      float64 `json:"closing_value"`
+	COGS              float64 `json:"cogs"`
+}
+
+// GetInventoryValuation values an account's inventory at the end of a period and
+// computes the cost of goods sold during it.
+//
+// The closing stock is the latest inventory snapshot taken during the period, and the
+// opening stock is the latest snapshot taken at or before its start. Each count is
+// priced with the price history recorded up to the time of the count, so a report
+// for a past month is unaffected by what was paid afterwards. Purchases are the
+// deliveries received after the opening count, up to and including the closing count.
+//
+// Valuation methods:
+//   - fifo: the newest purchases are what remain on hand
+//   - weighted_average: the quantity-weighted average of every price paid
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//   - startDate: The start of the period
+//   - endDate: The end of the period
+//   - method: The valuation method; empty uses fifo for accounts costed by FIFO and weighted_average otherwise
+//
+// Returns:
+//   - *InventoryValuation: The opening and closing values, purchases, and COGS, with a line per item
+//   - error: ErrNoClosingCount, an invalid method or period, or any error that occurred during retrieval
+//
+// Business rules:
+//   - Without an opening snapshot the opening stock is zero and purchases start at the period start
+//   - Items that have never been delivered are valued at their current cost per unit
+func (s *Service) GetInventoryValuation(accountID int, startDate, endDate time.Time, method string) (*InventoryValuation, error) {
+	if endDate.Before(startDate) {
+		return nil, errors.New("end date must not be before start date")
+	}
+
+	if method == "" {
+		account, err := s.accounts.GetByID(accountID)
+		if err != nil {
+			return nil, errors.New("invalid account ID")
+		}
+		method = ValuationMethodWeightedAverage
+		if accountCostingMethod(account) == models.CostingMethodFIFO {
+			method = ValuationMethodFIFO
+		}
+	} else if !slices.Contains(ValuationMethods, method) {
+		return nil, fmt.Errorf("invalid valuation method: %s", method)
+	}
+
+	closing, err := s.inventorySnapshots.GetLatestAsOf(accountID, endDate)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNoClosingCount
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !closing.Timestamp.After(startDate) {
+		return nil, ErrNoClosingCount
+	}
+
+	opening, err := s.inventorySnapshots.GetLatestAsOf(accountID, startDate)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	valuation := &InventoryValuation{
+		AccountID:         accountID,
+		Method:            method,
+		PeriodStart:       startDate,
+		PeriodEnd:         endDate,
+		ClosingSnapshotID: closing.ID,
+		ClosingCountedAt:  closing.Timestamp,
+		Items:             []ItemValuation{},
+	}
+	purchasesFrom := startDate
+	if opening != nil {
+		valuation.OpeningSnapshotID = &opening.ID
+		valuation.OpeningCountedAt = &opening.Timestamp
+		purchasesFrom = opening.Timestamp
+	}
+
+	items, err := s.inventoryItems.GetByAccountID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	itemsByID := make(map[int]models.InventoryItem, len(items))
+	for _, item := range items {
+		itemsByID[item.ID] = item
+	}
+
+	history, err := s.priceHistory.GetByAccountIDAsOf(accountID, closing.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+	historyByItem := make(map[int][]models.PriceHistory)
+	for _, entry := range history {
+		historyByItem[entry.InventoryItemID] = append(historyByItem[entry.InventoryItemID], entry)
+	}
+
+	lines := make(map[int]*ItemValuation)
+	lineFor := func(itemID int) *ItemValuation {
+		line, ok := lines[itemID]
+		if !ok {
+			item := itemsByID[itemID]
+			line = &ItemValuation{InventoryItemID: itemID, ItemName: item.Name, Unit: item.Unit}
+			lines[itemID] = line
+		}
+		return line
+	}
+
+	deliveries, err := s.deliveries.GetByDateRange(accountID, purchasesFrom, closing.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+	for _, delivery := range deliveries {
+		// Deliveries received at the moment of the opening count are part of the opening stock
+		if opening != nil && !delivery.DeliveryDate.After(purchasesFrom) {
+			continue
+		}
+		line := lineFor(delivery.InventoryItemID)
+		line.PurchasedQuantity += delivery.Quantity
+		line.Purchases += delivery.Cost
+	}
+
+	if opening != nil {
+		for itemID, quantity := range opening.Counts {
+			line := lineFor(itemID)
+			line.OpeningQuantity = quantity
+			line.OpeningUnitCost = valuationUnitCost(historyByItem[itemID], opening.Timestamp, quantity, method, itemsByID[itemID].CostPerUnit)
+			line.OpeningValue = quantity * line.OpeningUnitCost
+		}
+	}
+	for itemID, quantity := range closing.Counts {
+		line := lineFor(itemID)
+		line.ClosingQuantity = quantity
+		line.ClosingUnitCost = valuationUnitCost(historyByItem[itemID], closing.Timestamp, quantity, method, itemsByID[itemID].CostPerUnit)
+		line.ClosingValue = quantity * line.ClosingUnitCost
+	}
+
+	for _, line := range lines {
+		line.COGS = line.OpeningValue + line.Purchases - line.ClosingValue
+		valuation.OpeningValue += line.OpeningValue
+		valuation.Purchases += line.Purchases
+		valuation.ClosingValue += line.ClosingValue
+		valuation.Items = append(valuation.Items, *line)
+	}
+	valuation.COGS = valuation.OpeningValue + valuation.Purchases - valuation.ClosingValue
+
+	sort.Slice(valuation.Items, func(i, j int) bool {
+		if valuation.Items[i].ItemName != valuation.Items[j].ItemName {
+			return valuation.Items[i].ItemName < valuation.Items[j].ItemName
+		}
+		return valuation.Items[i].InventoryItemID < valuation.Items[j].InventoryItemID
+	})
+
+	return valuation, nil
+}
+
+// valuationUnitCost prices stock counted at asOf with the purchases recorded up to then.
+// History must be ordered oldest first; items without purchases use the fallback cost.
+func valuationUnitCost(history []models.PriceHistory, asOf time.Time, quantity float64, method string, fallback float64) float64 {
+	history = history[:sort.Search(len(history), func(i int) bool {
+		return history[i].RecordedAt.After(asOf)
+	})]
+	if len(history) == 0 {
+		return fallback
+	}
+	if method == ValuationMethodFIFO {
+		return fifoUnitPrice(history, quantity)
+	}
+	return weightedUnitPrice(history)
+}
+
+// Inventory lot operations
+// These methods handle the lots created by deliveries.
+// Lots record when stock was received and when it expires, and are consumed oldest first.
+
+// ErrDeliveryLotConsumed is returned when changing the quantity of a delivery whose lot was already partly used
+var ErrDeliveryLotConsumed = errors.New("the delivery's lot was already partly used")
+
+// ExpiringSoonDays is how many days ahead a lot counts as expiring soon
+const ExpiringSoonDays = 3
+
+// ExpiringLot represents an inventory lot that is close to or past its expiration date
+type ExpiringLot struct {
+	models.InventoryLot
+	ItemName        string `json:"item_name"`
+	Unit            string `json:"unit"`
+	DaysUntilExpiry int    `json:"days_until_expiry"` // Negative once the lot has expired
+}
+
+// GetLotsByItem retrieves every lot of an inventory item, oldest first.
+//
+// Parameters:
+//   - itemID: The unique identifier of the inventory item
+//
+// Returns:
+//   - []models.InventoryLot: List of lots for the item, including used-up lots
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetLotsByItem(itemID int) ([]models.InventoryLot, error) {
+	return s.inventoryLots.GetByItemID(itemID)
+}
+
+// GetExpiringLots retrieves the lots in an account that still hold stock and
+// expire within the given number of days. Lots that have already expired are
+// included so they can be cleared.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//   - withinDays: How many days ahead to look (0 means today)
+//
+// Returns:
+//   - []ExpiringLot: Expiring lots ordered by expiration date
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetExpiringLots(accountID int, withinDays int) ([]ExpiringLot, error) {
+	if withinDays < 0 {
+		return nil, errors.New("days must not be negative")
+	}
+
+	today := dateOnly(time.Now())
+	before := today.AddDate(0, 0, withinDays+1).Add(-time.Nanosecond)
+
+	lots, err := s.inventoryLots.GetExpiringByAccountID(accountID, before)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.inventoryItems.GetByAccountID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	itemsByID := make(map[int]models.InventoryItem, len(items))
+	for _, item := range items {
+		itemsByID[item.ID] = item
+	}
+
+	result := make([]ExpiringLot, 0, len(lots))
+	for _, lot := range lots {
+		item := itemsByID[lot.InventoryItemID]
+		result = append(result, ExpiringLot{
+			InventoryLot:    lot,
+			ItemName:        item.Name,
+			Unit:            item.Unit,
+			DaysUntilExpiry: int(math.Round(dateOnly(*lot.ExpirationDate).Sub(today).Hours() / 24)),
+		})
+	}
+
+	return result, nil
+}
+
+// ConsumeFromLots draws a quantity of an inventory item from its lots,
+// emptying the oldest lot before moving on to the next.
+//
+// Parameters:
+//   - itemID: The unique identifier of the inventory item
+//   - quantity: The quantity consumed
+//
+// Returns:
+//   - float64: The part of the quantity that no lot could cover
+//   - error: Any error that occurred during the update
+func (s *Service) ConsumeFromLots(itemID int, quantity float64) (float64, error) {
+	remaining, _, err := s.consumeLots(itemID, quantity)
+	return remaining, err
+}
+
+// consumeLots consumes stock from an item's lots, oldest first, and also returns the
+// quantity taken from each lot, keyed by lot ID.
+func (s *Service) consumeLots(itemID int, quantity float64) (float64, map[int]float64, error) {
+	if quantity < 0 {
+		return 0, nil, errors.New("consumed quantity cannot be negative")
+	}
+
+	lots, err := s.inventoryLots.GetOpenByItemID(itemID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	remaining := quantity
+	taken := make(map[int]float64)
+	for i := range lots {
+		if remaining <= 0 {
+			break
+		}
+		lot := &lots[i]
+		used := math.Min(lot.RemainingQuantity, remaining)
+		lot.RemainingQuantity -= used
+		remaining -= used
+		taken[lot.ID] = used
+		if err := s.inventoryLots.Update(lot); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	return remaining, taken, nil
+}
+
+// createDeliveryLot records the lot received with a delivery.
+func (s *Service) createDeliveryLot(delivery *models.Delivery) error {
+	if delivery.Quantity <= 0 {
+		return nil
+	}
+
+	lotNumber := strings.TrimSpace(delivery.LotNumber)
+	if lotNumber == "" {
+		lotNumber = fmt.Sprintf("DEL-%d", delivery.ID)
+	}
+
+	receivedAt := delivery.DeliveryDate
+	if receivedAt.IsZero() {
+		receivedAt = time.Now()
+	}
+
+	lot := &models.InventoryLot{
+		AccountID:         delivery.AccountID,
+		InventoryItemID:   delivery.InventoryItemID,
+		DeliveryID:        &delivery.ID,
+		LotNumber:         lotNumber,
+		ReceivedAt:        receivedAt,
+		ExpirationDate:    delivery.ExpirationDate,
+		InitialQuantity:   delivery.Quantity,
+		RemainingQuantity: delivery.Quantity,
+	}
+	return s.inventoryLots.Create(lot)
+}
+
+// syncDeliveryLot resizes the lot received with a corrected delivery, or deletes it when
+// the delivery is deleted (nil). Lots that were already partly used keep their quantity
+// and item, since the stock taken from them can no longer be traced back.
+func (s *Service) syncDeliveryLot(previous, delivery *models.Delivery) error {
+	lot, err := s.inventoryLots.GetByDeliveryID(previous.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if delivery == nil {
+			return nil
+		}
+		return s.createDeliveryLot(delivery)
+	}
+	if err != nil {
+		return err
+	}
+
+	resized := delivery == nil || delivery.Quantity != lot.InitialQuantity || delivery.InventoryItemID != lot.InventoryItemID
+	if resized && lot.RemainingQuantity < lot.InitialQuantity {
+		return ErrDeliveryLotConsumed
+	}
+	if delivery == nil || delivery.Quantity <= 0 {
+		return s.inventoryLots.Delete(lot.ID)
+	}
+
+	if resized {
+		lot.InventoryItemID = delivery.InventoryItemID
+		lot.InitialQuantity = delivery.Quantity
+		lot.RemainingQuantity = delivery.Quantity
+	}
+	lot.ExpirationDate = delivery.ExpirationDate
+	if lotNumber := strings.TrimSpace(delivery.LotNumber); lotNumber != "" {
+		lot.LotNumber = lotNumber
+	}
+	if !delivery.DeliveryDate.IsZero() {
+		lot.ReceivedAt = delivery.DeliveryDate
+	}
+	return s.inventoryLots.Update(lot)
+}
+
+// reconcileLots consumes lot stock that a physical count shows is no longer on hand.
+// Counts above the lot total are left alone since that stock predates lot tracking.
+func (s *Service) reconcileLots(counts map[int]float64) error {
+	for itemID, count := range counts {
+		lots, err := s.inventoryLots.GetOpenByItemID(itemID)
+		if err != nil {
+			return err
+		}
+
+		var onHand float64
+		for _, lot := range lots {
+			onHand += lot.RemainingQuantity
+		}
+		if onHand > count {
+			if _, err := s.ConsumeFromLots(itemID, onHand-count); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Storage location operations
+// These methods handle the places within an account where stock is kept and the
+// transfers between them. Location stock is derived the same way as total stock:
+// the latest snapshot plus the movements recorded since.
+
+// unassignedLocation is the key used for stock that is not tied to a storage location
+const unassignedLocation = 0
+
+// LocationStock holds the stock of an item at one storage location.
+// A nil StorageLocationID is stock that is not assigned to any location.
+type LocationStock struct {
+	StorageLocationID *int    `json:"storage_location_id"`
+	LocationName      string  `json:"location_name"`
+	Quantity          float64 `json:"quantity"`
+}
+
+// InventoryItemWithLocationStock represents an inventory item with its current stock broken down by location
+type InventoryItemWithLocationStock struct {
+	InventoryItemWithStock
+	Locations []LocationStock `json:"locations"`
+}
+
+// CreateStorageLocation creates a new storage location for an account.
+//
+// Parameters:
+//   - location: The storage location data to create
+//
+// Returns:
+//   - error: Any error that occurred during creation
+//
+// Business rules:
+//   - Account must exist
+//   - Location names must be unique within an account (case- and whitespace-insensitive)
+//   - The first location of an account becomes its default location
+//   - Making a location the default clears the flag on the previous default
+func (s *Service) CreateStorageLocation(location *models.StorageLocation) error {
+	// Validate that the account exists
+	if _, err := s.accounts.GetByID(location.AccountID); err != nil {
+		return errors.New("invalid account ID")
+	}
+
+	locations, err := s.storageLocations.GetByAccountID(location.AccountID)
+	if err != nil {
+		return err
+	}
+	if err := validateStorageLocation(location, locations); err != nil {
+		return err
+	}
+	if len(locations) == 0 {
+		location.IsDefault = true
+	}
+
+	if err := s.storageLocations.Create(location); err != nil {
+		return err
+	}
+	return s.clearOtherDefaultLocations(location, locations)
+}
+
+// GetStorageLocation retrieves a storage location by its unique identifier.
+//
+// Parameters:
+//   - id: The unique identifier of the storage location
+//
+// Returns:
+//   - *models.StorageLocation: The storage location if found
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetStorageLocation(id int) (*models.StorageLocation, error) {
+	return s.storageLocations.GetByID(id)
+}
+
+// GetStorageLocationsByAccount retrieves the storage locations of an account in alphabetical order.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//
+// Returns:
+//   - []models.StorageLocation: List of storage locations
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetStorageLocationsByAccount(accountID int) ([]models.StorageLocation, error) {
+	return s.storageLocations.GetByAccountID(accountID)
+}
+
+// UpdateStorageLocation updates an existing storage location.
+//
+// Parameters:
+//   - location: The updated storage location data
+//
+// Returns:
+//   - error: Any error that occurred during the update
+//
+// Business rules:
+//   - Location names must stay unique within the account
+//   - The default location cannot be deactivated
+//   - Making a location the default clears the flag on the previous default
+func (s *Service) UpdateStorageLocation(location *models.StorageLocation) error {
+	if _, err := s.storageLocations.GetByID(location.ID); err != nil {
+		return err
+	}
+
+	locations, err := s.storageLocations.GetByAccountID(location.AccountID)
+	if err != nil {
+		return err
+	}
+	if err := validateStorageLocation(location, locations); err != nil {
+		return err
+	}
+	if location.IsDefault && !location.IsActive {
+		return errors.New("the default storage location cannot be deactivated")
+	}
+
+	if err := s.storageLocations.Update(location); err != nil {
+		return err
+	}
+	return s.clearOtherDefaultLocations(location, locations)
+}
+
+// DeleteStorageLocation deletes a storage location that no longer holds stock.
+// Locations that still hold stock should be emptied by a transfer or deactivated.
+//
+// Parameters:
+//   - id: The unique identifier of the storage location to delete
+//
+// Returns:
+//   - error: Any error that occurred during deletion
+func (s *Service) DeleteStorageLocation(id int) error {
+	location, err := s.storageLocations.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	stock, err := s.stockByLocation(location.AccountID)
+	if err != nil {
+		return err
+	}
+	for _, quantities := range stock {
+		if quantities[location.ID] != 0 {
+			return errors.New("cannot delete storage location: it still holds stock")
+		}
+	}
+
+	return s.storageLocations.Delete(id)
+}
+
+// TransferBetweenLocations moves stock of an item from one storage location to another.
+//
+// Parameters:
+//   - transfer: The transfer to record
+//
+// Returns:
+//   - error: Any error that occurred during validation or creation
+//
+// Business rules:
+//   - Quantity must be positive
+//   - Inventory item and both locations must belong to the transfer's account
+//   - Locations must be different and active
+//   - TransferredAt defaults to now
+func (s *Service) TransferBetweenLocations(transfer *models.LocationTransfer) error {
+	if transfer.Quantity <= 0 {
+		return errors.New("transfer quantity must be positive")
+	}
+	if transfer.FromLocationID == transfer.ToLocationID {
+		return errors.New("source and destination locations must be different")
+	}
+
+	item, err := s.inventoryItems.GetByID(transfer.InventoryItemID)
+	if err != nil || item.AccountID != transfer.AccountID {
+		return errors.New("invalid inventory item ID")
+	}
+	for _, locationID := range []int{transfer.FromLocationID, transfer.ToLocationID} {
+		if _, err := s.resolveStorageLocation(transfer.AccountID, &locationID); err != nil {
+			return err
+		}
+	}
+
+	if transfer.TransferredAt.IsZero() {
+		transfer.TransferredAt = time.Now()
+	}
+	return s.locationTransfers.Create(transfer)
+}
+
+// GetLocationTransfers retrieves the transfers between the storage locations of an account, newest first.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//
+// Returns:
+//   - []models.LocationTransfer: List of transfers
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetLocationTransfers(accountID int) ([]models.LocationTransfer, error) {
+	return s.locationTransfers.GetByAccountID(accountID)
+}
+
+// GetInventoryItemsWithLocationStock retrieves all inventory items of an account with
+// their current stock broken down by storage location. The total matches
+// GetInventoryItemsWithCurrentStock since transfers only move stock between locations.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//
+// Returns:
+//   - []InventoryItemWithLocationStock: Items with their stock at each location holding any
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetInventoryItemsWithLocationStock(accountID int) ([]InventoryItemWithLocationStock, error) {
+	items, err := s.inventoryItems.GetByAccountID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	return s.withLocationStock(accountID, items)
+}
+
+// ListInventoryItemsWithLocationStock retrieves one page of an account's inventory items,
+// filtered and sorted as requested, with their current stock broken down by storage location.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//   - list: The page, sort order and filters (search, category, vendor) to apply
+//
+// Returns:
+//   - []InventoryItemWithLocationStock: The page of items with their stock at each location
+//   - Page: Pagination metadata for the page
+//   - error: Any error that occurred during retrieval
+func (s *Service) ListInventoryItemsWithLocationStock(accountID int, list ListQuery) ([]InventoryItemWithLocationStock, Page, error) {
+	items, page, err := s.inventoryItems.List(accountID, list)
+	if err != nil {
+		return nil, Page{}, err
+	}
+	itemsWithStock, err := s.withLocationStock(accountID, items)
+	return itemsWithStock, page, err
+}
+
+// withLocationStock breaks down the current stock of inventory items of an account by location
+func (s *Service) withLocationStock(accountID int, items []models.InventoryItem) ([]InventoryItemWithLocationStock, error) {
+	locations, err := s.storageLocations.GetByAccountID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	locationNames := make(map[int]string, len(locations))
+	for _, location := range locations {
+		locationNames[location.ID] = location.Name
+	}
+
+	stock, err := s.stockByLocation(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]InventoryItemWithLocationStock, len(items))
+	for i, item := range items {
+		itemStock := InventoryItemWithLocationStock{
+			InventoryItemWithStock: InventoryItemWithStock{InventoryItem: item},
+			Locations:              []LocationStock{},
+		}
+
+		for locationKey, quantity := range stock[item.ID] {
+			itemStock.CurrentStock += quantity
+			if quantity == 0 {
+				continue
+			}
+
+			locationStock := LocationStock{Quantity: quantity}
+			if locationKey != unassignedLocation {
+				locationID := locationKey
+				locationStock.StorageLocationID = &locationID
+				locationStock.LocationName = locationNames[locationKey]
+			}
+			itemStock.Locations = append(itemStock.Locations, locationStock)
+		}
+
+		sort.Slice(itemStock.Locations, func(a, b int) bool {
+			return itemStock.Locations[a].LocationName < itemStock.Locations[b].LocationName
+		})
+		result[i] = itemStock
+	}
+
+	return result, nil
+}
+
+// stockByLocation calculates the current stock of every item at every location of an account.
+// The result maps item IDs to location IDs to quantities, with unassignedLocation
+// holding stock that is not tied to a location. Sales are drawn from the default location.
+func (s *Service) stockByLocation(accountID int) (map[int]map[int]float64, error) {
+	locations, err := s.storageLocations.GetByAccountID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[int]bool, len(locations))
+	defaultKey := unassignedLocation
+	for _, location := range locations {
+		known[location.ID] = true
+		if location.IsDefault {
+			defaultKey = location.ID
+		}
+	}
+
+	stock := make(map[int]map[int]float64)
+	add := func(itemID int, locationID *int, quantity float64) {
+		key := unassignedLocation
+		if locationID != nil && known[*locationID] {
+			key = *locationID
+		}
+		if stock[itemID] == nil {
+			stock[itemID] = make(map[int]float64)
+		}
+		stock[itemID][key] += quantity
+	}
+
+	// Start from the latest snapshot, where totals above the location breakdown are unassigned
+	snapshotTimestamp := time.Time{}
+	latestSnapshot, err := s.inventorySnapshots.GetLatestByAccountID(accountID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	if err == nil {
+		snapshotTimestamp = latestSnapshot.Timestamp
+		located := make(map[int]float64)
+		for locationID, counts := range latestSnapshot.LocationCounts {
+			for itemID, quantity := range counts {
+				locationID := locationID
+				add(itemID, &locationID, quantity)
+				located[itemID] += quantity
+			}
+		}
+		for itemID, quantity := range latestSnapshot.Counts {
+			add(itemID, nil, quantity-located[itemID])
+		}
+	}
+
+	deliveries, err := s.deliveries.GetByAccountIDAfterDate(accountID, snapshotTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	for _, delivery := range deliveries {
+		add(delivery.InventoryItemID, delivery.StorageLocationID, delivery.Quantity)
+	}
+
+	consumed, err := s.salesConsumptionSince(accountID, snapshotTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	for itemID, quantity := range consumed {
+		add(itemID, &defaultKey, -quantity)
+	}
+
+	wasteLogs, err := s.wasteLogs.GetByAccountIDAfterDate(accountID, snapshotTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	for _, wasteLog := range wasteLogs {
+		add(wasteLog.InventoryItemID, wasteLog.StorageLocationID, -wasteLog.Quantity)
+	}
+
+	adjustments, err := s.stockAdjustments.GetByAccountIDAfterDate(accountID, snapshotTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	for _, adjustment := range adjustments {
+		add(adjustment.InventoryItemID, adjustment.StorageLocationID, adjustment.Quantity)
+	}
+
+	transfers, err := s.locationTransfers.GetByAccountIDAfterDate(accountID, snapshotTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	for _, transfer := range transfers {
+		add(transfer.InventoryItemID, &transfer.FromLocationID, -transfer.Quantity)
+		add(transfer.InventoryItemID, &transfer.ToLocationID, transfer.Quantity)
+	}
+
+	sentTransfers, err := s.stockTransfers.GetSentAfterDate(accountID, snapshotTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	for _, transfer := range sentTransfers {
+		add(transfer.FromInventoryItemID, transfer.FromStorageLocationID, -transfer.Quantity)
+	}
+
+	receivedTransfers, err := s.stockTransfers.GetReceivedAfterDate(accountID, snapshotTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	for _, transfer := range receivedTransfers {
+		if transfer.ToInventoryItemID != nil {
+			add(*transfer.ToInventoryItemID, transfer.ToStorageLocationID, transfer.ReceivedQuantity)
+		}
+	}
+
+	return stock, nil
+}
+
+// salesConsumptionSince sums the quantity of each item consumed by sales through recipes since a date
+func (s *Service) salesConsumptionSince(accountID int, since time.Time) (map[int]float64, error) {
+	sales, err := s.sales.GetByAccountIDAfterDate(accountID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cache recipes so each menu item is only looked up once
+	recipes := make(map[uint][]models.RecipeIngredient)
+	consumed := make(map[int]float64)
+	for _, sale := range sales {
+		for _, saleItem := range sale.Items {
+			ingredients, ok := recipes[saleItem.MenuItemID]
+			if !ok {
+				ingredients, err = s.recipes.GetIngredientsByMenuItemID(saleItem.MenuItemID)
+				if err != nil {
+					log.Printf("Warning: could not get recipe for menu item %d: %v", saleItem.MenuItemID, err)
+				}
+				recipes[saleItem.MenuItemID] = ingredients
+			}
+
+			for _, ingredient := range ingredients {
+				consumed[ingredient.InventoryItemID] += ingredient.Quantity * float64(saleItem.Quantity)
+			}
+		}
+	}
+	return consumed, nil
+}
+
+// resolveStorageLocation validates a location reference for an account. When no
+// location is given the account's default location is returned, or nil if it has none.
+func (s *Service) resolveStorageLocation(accountID int, locationID *int) (*int, error) {
+	if locationID != nil {
+		location, err := s.storageLocations.GetByID(*locationID)
+		if err != nil || location.AccountID != accountID {
+			return nil, errors.New("invalid storage location ID")
+		}
+		if !location.IsActive {
+			return nil, fmt.Errorf("storage location %s is inactive", location.Name)
+		}
+		return &location.ID, nil
+	}
+
+	locations, err := s.storageLocations.GetByAccountID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	for _, location := range locations {
+		if location.IsDefault {
+			return &location.ID, nil
+		}
+	}
+	return nil, nil
+}
+
+// clearOtherDefaultLocations removes the default flag from every other location
+// of the account once location has become the default.
+func (s *Service) clearOtherDefaultLocations(location *models.StorageLocation, locations []models.StorageLocation) error {
+	if !location.IsDefault {
+		return nil
+	}
+	for i := range locations {
+		if locations[i].ID != location.ID && locations[i].IsDefault {
+			locations[i].IsDefault = false
+			if err := s.storageLocations.Update(&locations[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateStorageLocation normalizes a location's name and checks it against the
+// other locations of the account.
+func validateStorageLocation(location *models.StorageLocation, locations []models.StorageLocation) error {
+	location.Name = strings.Join(strings.Fields(location.Name), " ")
+	if location.Name == "" {
+		return errors.New("storage location name is required")
+	}
+
+	for _, other := range locations {
+		if other.ID != location.ID && strings.EqualFold(other.Name, location.Name) {
+			return fmt.Errorf("a storage location named %s already exists", other.Name)
+		}
+	}
+	return nil
+}
+
+// Stock transfer operations
+// These methods handle stock moved between accounts of the same organization, such
+// as a commissary supplying its stores. A transfer leaves the source account when it
+// is sent and reaches the destination account when it is received.
+
+// StockTransferReceipt holds what the receiving account records when a transfer arrives
+type StockTransferReceipt struct {
+	ReceivedQuantity    float64 `json:"received_quantity"`      // Defaults to the quantity sent
+	ToInventoryItemID   *int    `json:"to_inventory_item_id"`   // Defaults to the item with the same name
+	ToStorageLocationID *int    `json:"to_storage_location_id"` // Defaults to the default location
+}
+
+// SendStockTransfer sends stock from one account to another account of the same organization.
+//
+// Parameters:
+//   - transfer: The transfer to send; FromAccountID identifies the sending account
+//
+// Returns:
+//   - error: Any error that occurred during validation or creation
+//
+// Business rules:
+//   - Both accounts must exist, be different, and belong to the same organization
+//   - The source item must belong to the sending account; a destination item, if given, to the receiving account
+//   - Quantity must be positive
+//   - The transfer is valued at the source item's current cost per unit
+//   - Stock leaves the source account, and its oldest lots, as soon as the transfer is sent
+func (s *Service) SendStockTransfer(transfer *models.StockTransfer) error {
+	if transfer.Quantity <= 0 {
+		return errors.New("transfer quantity must be positive")
+	}
+	if transfer.FromAccountID == transfer.ToAccountID {
+		return errors.New("source and destination accounts must be different")
+	}
+
+	fromAccount, err := s.accounts.GetByID(transfer.FromAccountID)
+	if err != nil {
+		return errors.New("invalid source account ID")
+	}
+	toAccount, err := s.accounts.GetByID(transfer.ToAccountID)
+	if err != nil {
+		return errors.New("invalid destination account ID")
+	}
+	if fromAccount.OrganizationID == nil || toAccount.OrganizationID == nil || *fromAccount.OrganizationID != *toAccount.OrganizationID {
+		return errors.New("stock can only be transferred between accounts of the same organization")
+	}
+
+	item, err := s.inventoryItems.GetByID(transfer.FromInventoryItemID)
+	if err != nil || item.AccountID != transfer.FromAccountID {
+		return errors.New("invalid inventory item ID")
+	}
+	if transfer.ToInventoryItemID != nil {
+		if _, err := s.destinationItem(transfer, transfer.ToInventoryItemID); err != nil {
+			return err
+		}
+	}
+
+	if transfer.FromStorageLocationID, err = s.resolveStorageLocation(transfer.FromAccountID, transfer.FromStorageLocationID); err != nil {
+		return err
+	}
+
+	transfer.OrganizationID = *fromAccount.OrganizationID
+	transfer.UnitCost = item.CostPerUnit
+	transfer.Status = models.StockTransferStatusInTransit
+	transfer.ReceivedQuantity = 0
+	transfer.ToStorageLocationID = nil
+	transfer.ReceivedBy = nil
+	transfer.ReceivedAt = nil
+	if transfer.SentAt.IsZero() {
+		transfer.SentAt = time.Now()
+	}
+
+	return s.withTransaction(func(tx *Service) error {
+		if err := tx.stockTransfers.Create(transfer); err != nil {
+			return err
+		}
+		if err := tx.applyStockMovement(transfer.FromAccountID, item.ID, transfer.SentAt, -transfer.Quantity); err != nil {
+			return err
+		}
+
+		// Sent stock leaves the oldest lots first, like any other consumption, and the
+		// lots are recorded so that a cancelled transfer can put the stock back
+		_, taken, err := tx.consumeLots(item.ID, transfer.Quantity)
+		if err != nil {
+			return err
+		}
+		lotIDs := make([]int, 0, len(taken))
+		for lotID := range taken {
+			lotIDs = append(lotIDs, lotID)
+		}
+		sort.Ints(lotIDs)
+		for _, lotID := range lotIDs {
+			transferLot := &models.StockTransferLot{StockTransferID: transfer.ID, InventoryLotID: lotID, Quantity: taken[lotID]}
+			if err := tx.stockTransferLots.Create(transferLot); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ReceiveStockTransfer records the arrival of a transfer at its destination account.
+//
+// Parameters:
+//   - id: The unique identifier of the transfer
+//   - receipt: The received quantity, destination item and location; zero values use the defaults
+//   - userID: The user receiving the transfer
+//
+// Returns:
+//   - *models.StockTransfer: The received transfer
+//   - error: Any error that occurred during validation or the update
+//
+// Business rules:
+//   - Only transfers in transit can be received
+//   - The received quantity defaults to the quantity sent and cannot exceed it
+//   - Without a destination item, the receiving account's item with the same name is used
+//   - Received stock is added as a new lot at the destination
+func (s *Service) ReceiveStockTransfer(id int, receipt StockTransferReceipt, userID int) (*models.StockTransfer, error) {
+	transfer, err := s.stockTransfers.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.Status != models.StockTransferStatusInTransit {
+		return nil, fmt.Errorf("stock transfer is %s", transfer.Status)
+	}
+
+	quantity := receipt.ReceivedQuantity
+	if quantity == 0 {
+		quantity = transfer.Quantity
+	}
+	if quantity < 0 || quantity > transfer.Quantity {
+		return nil, errors.New("received quantity must be positive and no more than the quantity sent")
+	}
+
+	itemID := receipt.ToInventoryItemID
+	if itemID == nil {
+		itemID = transfer.ToInventoryItemID
+	}
+	item, err := s.destinationItem(transfer, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	locationID, err := s.resolveStorageLocation(transfer.ToAccountID, receipt.ToStorageLocationID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	transfer.ToInventoryItemID = &item.ID
+	transfer.ToStorageLocationID = locationID
+	transfer.ReceivedQuantity = quantity
+	transfer.Status = models.StockTransferStatusReceived
+	transfer.ReceivedBy = &userID
+	transfer.ReceivedAt = &now
+
+	lot := &models.InventoryLot{
+		AccountID:         transfer.ToAccountID,
+		InventoryItemID:   item.ID,
+		LotNumber:         fmt.Sprintf("TRF-%d", transfer.ID),
+		ReceivedAt:        now,
+		InitialQuantity:   quantity,
+		RemainingQuantity: quantity,
+	}
+	if item.ShelfLifeDays > 0 {
+		expirationDate := now.AddDate(0, 0, item.ShelfLifeDays)
+		lot.ExpirationDate = &expirationDate
+	}
+
+	err = s.withTransaction(func(tx *Service) error {
+		if err := tx.stockTransfers.Update(transfer); err != nil {
+			return err
+		}
+		if err := tx.applyStockMovement(transfer.ToAccountID, item.ID, now, quantity); err != nil {
+			return err
+		}
+		return tx.inventoryLots.Create(lot)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// CancelStockTransfer cancels a transfer that has not been received yet.
+// The stock counts as back at the source account, in the lots it was taken from.
+//
+// Parameters:
+//   - id: The unique identifier of the transfer
+//
+// Returns:
+//   - *models.StockTransfer: The cancelled transfer
+//   - error: Any error that occurred during the update
+func (s *Service) CancelStockTransfer(id int) (*models.StockTransfer, error) {
+	transfer, err := s.stockTransfers.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.Status != models.StockTransferStatusInTransit {
+		return nil, fmt.Errorf("stock transfer is %s", transfer.Status)
+	}
+
+	transfer.Status = models.StockTransferStatusCancelled
+	err = s.withTransaction(func(tx *Service) error {
+		if err := tx.stockTransfers.Update(transfer); err != nil {
+			return err
+		}
+		// The stock counts as never having left the source
+		if err := tx.applyStockMovement(transfer.FromAccountID, transfer.FromInventoryItemID, transfer.SentAt, transfer.Quantity); err != nil {
+			return err
+		}
+
+		transferLots, err := tx.stockTransferLots.GetByTransferID(transfer.ID)
+		if err != nil {
+			return err
+		}
+		for _, transferLot := range transferLots {
+			lot, err := tx.inventoryLots.GetByID(transferLot.InventoryLotID)
+			if err != nil {
+				return err
+			}
+			lot.RemainingQuantity += transferLot.Quantity
+			if err := tx.inventoryLots.Update(lot); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// GetStockTransfer retrieves a stock transfer by its unique identifier.
+//
+// Parameters:
+//   - id: The unique identifier of the transfer
+//
+// Returns:
+//   - *models.StockTransfer: The transfer if found
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetStockTransfer(id int) (*models.StockTransfer, error) {
+	return s.stockTransfers.GetByID(id)
+}
+
+// GetStockTransfersByAccount retrieves the transfers an account sent or received, newest first.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//
+// Returns:
+//   - []models.StockTransfer: List of transfers in every status
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetStockTransfersByAccount(accountID int) ([]models.StockTransfer, error) {
+	return s.stockTransfers.GetByAccountID(accountID)
+}
+
+// destinationItem resolves the receiving account's item for a transfer, either the
+// given item or the item with the same name as the one sent.
+func (s *Service) destinationItem(transfer *models.StockTransfer, itemID *int) (*models.InventoryItem, error) {
+	if itemID != nil {
+		item, err := s.inventoryItems.GetByID(*itemID)
+		if err != nil || item.AccountID != transfer.ToAccountID {
+			return nil, errors.New("invalid destination inventory item ID")
+		}
+		return item, nil
+	}
+
+	source, err := s.inventoryItems.GetByID(transfer.FromInventoryItemID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.inventoryItems.GetByAccountID(transfer.ToAccountID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		if strings.EqualFold(strings.TrimSpace(items[i].Name), strings.TrimSpace(source.Name)) {
+			return &items[i], nil
+		}
+	}
+	return nil, fmt.Errorf("destination account has no inventory item named %s", source.Name)
+}
+
+// Catalog operations
+// These methods handle an organization's master catalog of categories, inventory items,
+// and menu items with recipes. Accounts that subscribe to the catalog get local copies
+// that are kept in sync whenever the catalog changes, while cost and vendor stay local.
+
+// CatalogMenuItemWithRecipe is a catalog menu item together with its recipe
+type CatalogMenuItemWithRecipe struct {
+	models.CatalogMenuItem
+	Ingredients []models.CatalogRecipeIngredient `json:"ingredients"`
+}
+
+// CatalogSyncResult summarizes one sync of the catalog into an account
+type CatalogSyncResult struct {
+	AccountID int                          `json:"account_id"`
+	Created   int                          `json:"created"`
+	Updated   int                          `json:"updated"`
+	Conflicts []models.CatalogSyncConflict `json:"conflicts"`
+	SyncedAt  time.Time                    `json:"synced_at"`
+}
+
+// CreateCatalogCategory adds a category to an organization's catalog and pushes it to subscribed accounts.
+//
+// Parameters:
+//   - category: The catalog category to create
+//
+// Returns:
+//   - error: Any error that occurred during validation or creation
+//
+// Business rules:
+//   - Organization must exist
+//   - Category names must be unique within the catalog (case-insensitive)
+func (s *Service) CreateCatalogCategory(category *models.CatalogCategory) error {
+	if err := s.validateCatalogCategory(category); err != nil {
+		return err
+	}
+	if err := s.catalogCategories.Create(category); err != nil {
+		return err
+	}
+	s.pushCatalog(category.OrganizationID)
+	return nil
+}
+
+// UpdateCatalogCategory updates a catalog category and pushes the change to subscribed accounts.
+// Deactivating a category deactivates the linked local categories.
+//
+// Parameters:
+//   - category: The updated catalog category
+//
+// Returns:
+//   - error: Any error that occurred during validation or the update
+func (s *Service) UpdateCatalogCategory(category *models.CatalogCategory) error {
+	if _, err := s.catalogCategories.GetByID(category.ID); err != nil {
+		return err
+	}
+	if err := s.validateCatalogCategory(category); err != nil {
+		return err
+	}
+	if err := s.catalogCategories.Update(category); err != nil {
+		return err
+	}
+	s.pushCatalog(category.OrganizationID)
+	return nil
+}
+
+// GetCatalogCategory retrieves a catalog category by its unique identifier.
+//
+// Parameters:
+//   - id: The unique identifier of the catalog category
+//
+// Returns:
+//   - *models.CatalogCategory: The catalog category if found
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetCatalogCategory(id int) (*models.CatalogCategory, error) {
+	return s.catalogCategories.GetByID(id)
+}
+
+// GetCatalogCategories retrieves the categories of an organization's catalog in alphabetical order.
+//
+// Parameters:
+//   - organizationID: The unique identifier of the organization
+//
+// Returns:
+//   - []models.CatalogCategory: List of catalog categories
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetCatalogCategories(organizationID int) ([]models.CatalogCategory, error) {
+	return s.catalogCategories.GetByOrganizationID(organizationID)
+}
+
+// CreateCatalogItem adds an inventory item to an organization's catalog and pushes it to subscribed accounts.
+//
+// Parameters:
+//   - item: The catalog item to create
+//
+// Returns:
+//   - error: Any error that occurred during validation or creation
+//
+// Business rules:
+//   - Organization must exist
+//   - Name and unit are required; names must be unique within the catalog (case-insensitive)
+//   - CatalogCategoryID must reference a category of the same catalog
+//   - Cost and preferred vendor only seed new local items; accounts keep their own values afterwards
+func (s *Service) CreateCatalogItem(item *models.CatalogItem) error {
+	if err := s.validateCatalogItem(item); err != nil {
+		return err
+	}
+	if err := s.catalogItems.Create(item); err != nil {
+		return err
+	}
+	s.pushCatalog(item.OrganizationID)
+	return nil
+}
+
+// UpdateCatalogItem updates a catalog item and pushes the change to subscribed accounts.
+//
+// Parameters:
+//   - item: The updated catalog item
+//
+// Returns:
+//   - error: Any error that occurred during validation or the update
+func (s *Service) UpdateCatalogItem(item *models.CatalogItem) error {
+	if _, err := s.catalogItems.GetByID(item.ID); err != nil {
+		return err
+	}
+	if err := s.validateCatalogItem(item); err != nil {
+		return err
+	}
+	if err := s.catalogItems.Update(item); err != nil {
+		return err
+	}
+	s.pushCatalog(item.OrganizationID)
+	return nil
+}
+
+// GetCatalogItem retrieves a catalog item by its unique identifier.
+//
+// Parameters:
+//   - id: The unique identifier of the catalog item
+//
+// Returns:
+//   - *models.CatalogItem: The catalog item if found
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetCatalogItem(id int) (*models.CatalogItem, error) {
+	return s.catalogItems.GetByID(id)
+}
+
+// GetCatalogItems retrieves the inventory items of an organization's catalog in alphabetical order.
+//
+// Parameters:
+//   - organizationID: The unique identifier of the organization
+//
+// Returns:
+//   - []models.CatalogItem: List of catalog items
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetCatalogItems(organizationID int) ([]models.CatalogItem, error) {
+	return s.catalogItems.GetByOrganizationID(organizationID)
+}
+
+// CreateCatalogMenuItem adds a menu item and its recipe to an organization's catalog
+// and pushes it to subscribed accounts.
+//
+// Parameters:
+//   - item: The catalog menu item to create, with its recipe
+//
+// Returns:
+//   - error: Any error that occurred during validation or creation
+//
+// Business rules:
+//   - Organization must exist
+//   - Names must be unique within the catalog (case-insensitive)
+//   - Recipe ingredients must reference items of the same catalog with a positive quantity
+func (s *Service) CreateCatalogMenuItem(item *CatalogMenuItemWithRecipe) error {
+	if err := s.validateCatalogMenuItem(item); err != nil {
+		return err
+	}
+
+	err := s.withTransaction(func(tx *Service) error {
+		if err := tx.catalogMenuItems.Create(&item.CatalogMenuItem); err != nil {
+			return err
+		}
+		return tx.catalogRecipes.ReplaceIngredients(item.ID, item.Ingredients)
+	})
+	if err != nil {
+		return err
+	}
+	s.pushCatalog(item.OrganizationID)
+	return nil
+}
+
+// UpdateCatalogMenuItem updates a catalog menu item, replacing its recipe, and pushes
+// the change to subscribed accounts.
+//
+// Parameters:
+//   - item: The updated catalog menu item, with its complete recipe
+//
+// Returns:
+//   - error: Any error that occurred during validation or the update
+func (s *Service) UpdateCatalogMenuItem(item *CatalogMenuItemWithRecipe) error {
+	if _, err := s.catalogMenuItems.GetByID(item.ID); err != nil {
+		return err
+	}
+	if err := s.validateCatalogMenuItem(item); err != nil {
+		return err
+	}
+
+	err := s.withTransaction(func(tx *Service) error {
+		if err := tx.catalogMenuItems.Update(&item.CatalogMenuItem); err != nil {
+			return err
+		}
+		return tx.catalogRecipes.ReplaceIngredients(item.ID, item.Ingredients)
+	})
+	if err != nil {
+		return err
+	}
+	s.pushCatalog(item.OrganizationID)
+	return nil
+}
+
+// GetCatalogMenuItem retrieves a catalog menu item with its recipe.
+//
+// Parameters:
+//   - id: The unique identifier of the catalog menu item
+//
+// Returns:
+//   - *CatalogMenuItemWithRecipe: The catalog menu item if found
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetCatalogMenuItem(id int) (*CatalogMenuItemWithRecipe, error) {
+	item, err := s.catalogMenuItems.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	ingredients, err := s.catalogRecipes.GetByCatalogMenuItemID(item.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &CatalogMenuItemWithRecipe{CatalogMenuItem: *item, Ingredients: ingredients}, nil
+}
+
+// GetCatalogMenuItems retrieves the menu items of an organization's catalog with their recipes.
+//
+// Parameters:
+//   - organizationID: The unique identifier of the organization
+//
+// Returns:
+//   - []CatalogMenuItemWithRecipe: List of catalog menu items in alphabetical order
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetCatalogMenuItems(organizationID int) ([]CatalogMenuItemWithRecipe, error) {
+	items, err := s.catalogMenuItems.GetByOrganizationID(organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]CatalogMenuItemWithRecipe, len(items))
+	for i, item := range items {
+		ingredients, err := s.catalogRecipes.GetByCatalogMenuItemID(item.ID)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = CatalogMenuItemWithRecipe{CatalogMenuItem: item, Ingredients: ingredients}
+	}
+	return result, nil
+}
+
+// SubscribeToCatalog subscribes an account to its organization's catalog and runs the first sync.
+// Existing local entries with the same name as a catalog entry are adopted rather than duplicated.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//
+// Returns:
+//   - *CatalogSyncResult: The result of the first sync
+//   - error: Any error that occurred during subscription or sync
+//
+// Business rules:
+//   - The account must belong to an organization
+//   - Subscribing again reactivates an earlier subscription
+func (s *Service) SubscribeToCatalog(accountID int) (*CatalogSyncResult, error) {
+	account, err := s.accounts.GetByID(accountID)
+	if err != nil {
+		return nil, errors.New("invalid account ID")
+	}
+	if account.OrganizationID == nil {
+		return nil, errors.New("only accounts that belong to an organization can subscribe to a catalog")
+	}
+
+	subscription, err := s.catalogSubscriptions.GetByAccountID(accountID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if subscription == nil {
+		subscription = &models.CatalogSubscription{OrganizationID: *account.OrganizationID, AccountID: accountID, IsActive: true}
+		if err := s.catalogSubscriptions.Create(subscription); err != nil {
+			return nil, err
+		}
+	} else if !subscription.IsActive || subscription.OrganizationID != *account.OrganizationID {
+		subscription.OrganizationID = *account.OrganizationID
+		subscription.IsActive = true
+		if err := s.catalogSubscriptions.Update(subscription); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.SyncCatalog(accountID)
+}
+
+// UnsubscribeFromCatalog stops catalog updates for an account.
+// Local copies of catalog entries are kept and become ordinary local entries.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//
+// Returns:
+//   - error: Any error that occurred during the update
+func (s *Service) UnsubscribeFromCatalog(accountID int) error {
+	subscription, err := s.catalogSubscriptions.GetByAccountID(accountID)
+	if err != nil {
+		return err
+	}
+
+	subscription.IsActive = false
+	if err := s.catalogSubscriptions.Update(subscription); err != nil {
+		return err
+	}
+	return s.catalogSyncConflicts.DeleteByAccountID(accountID)
+}
+
+// GetCatalogSubscription retrieves the catalog subscription of an account.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//
+// Returns:
+//   - *models.CatalogSubscription: The subscription if the account ever subscribed
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetCatalogSubscription(accountID int) (*models.CatalogSubscription, error) {
+	return s.catalogSubscriptions.GetByAccountID(accountID)
+}
+
+// GetCatalogSyncConflicts retrieves the conflicts found by the latest sync of an account.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//
+// Returns:
+//   - []models.CatalogSyncConflict: List of conflicts ordered by entity type
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetCatalogSyncConflicts(accountID int) ([]models.CatalogSyncConflict, error) {
+	return s.catalogSyncConflicts.GetByAccountID(accountID)
+}
+
+// SyncCatalog brings an account's local categories, inventory items, and menu items in
+// line with its organization's catalog. The whole sync runs in one transaction.
+//
+// Parameters:
+//   - accountID: The unique identifier of the subscribed account
+//
+// Returns:
+//   - *CatalogSyncResult: Counts of created and updated entries and the conflicts found
+//   - error: Any error that occurred during the sync
+//
+// Business rules:
+//   - The account must have an active subscription
+//   - Local entries are matched by catalog link first, then by name
+//   - Cost and vendor of local items are never overwritten
+//   - Changes that cannot be applied are reported as conflicts and leave the local entry as is:
+//     name collisions with another local entry, unit changes, and recipes with ingredients
+//     that are not synced to the account
+//   - Inactive catalog entries are not created locally; inactive categories deactivate their local copy
+func (s *Service) SyncCatalog(accountID int) (*CatalogSyncResult, error) {
+	subscription, err := s.catalogSubscriptions.GetByAccountID(accountID)
+	if err != nil || !subscription.IsActive {
+		return nil, errors.New("account is not subscribed to a catalog")
+	}
+
+	result := &CatalogSyncResult{AccountID: accountID, Conflicts: []models.CatalogSyncConflict{}, SyncedAt: time.Now()}
+	err = s.withTransaction(func(tx *Service) error {
+		if err := tx.catalogSyncConflicts.DeleteByAccountID(accountID); err != nil {
+			return err
+		}
+
+		categoryIDs, err := tx.syncCatalogCategories(subscription, result)
+		if err != nil {
+			return err
+		}
+		itemIDs, err := tx.syncCatalogItems(subscription, categoryIDs, result)
+		if err != nil {
+			return err
+		}
+		if err := tx.syncCatalogMenuItems(subscription, categoryIDs, itemIDs, result); err != nil {
+			return err
+		}
+
+		for i := range result.Conflicts {
+			if err := tx.catalogSyncConflicts.Create(&result.Conflicts[i]); err != nil {
+				return err
+			}
+		}
+
+		subscription.LastSyncedAt = &result.SyncedAt
+		return tx.catalogSubscriptions.Update(subscription)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// syncCatalogCategories syncs the catalog categories into an account and returns the
+// local category ID of each catalog category.
+func (s *Service) syncCatalogCategories(subscription *models.CatalogSubscription, result *CatalogSyncResult) (map[int]int, error) {
+	catalog, err := s.catalogCategories.GetByOrganizationID(subscription.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	locals, err := s.categories.GetByAccountID(subscription.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	linked := make(map[int]*models.Category)
+	byName := make(map[string]*models.Category)
+	for i := range locals {
+		if locals[i].CatalogCategoryID != nil {
+			linked[*locals[i].CatalogCategoryID] = &locals[i]
+		}
+		byName[catalogKey(locals[i].Name)] = &locals[i]
+	}
+
+	localIDs := make(map[int]int)
+	for _, entry := range catalog {
+		local := linked[entry.ID]
+		namesake := byName[catalogKey(entry.Name)]
+
+		if local == nil {
+			if !entry.IsActive {
+				continue
+			}
+			if namesake != nil && namesake.CatalogCategoryID != nil {
+				result.addConflict(subscription, models.CatalogEntityCategory, entry.ID, &namesake.ID, "name", entry.Name, namesake.Name, "a local category with this name is linked to another catalog category")
+				continue
+			}
+			if namesake == nil {
+				category := &models.Category{AccountID: subscription.AccountID, Name: entry.Name, Description: entry.Description, Color: entry.Color, IsActive: true, CatalogCategoryID: &entry.ID}
+				if err := s.categories.Create(category); err != nil {
+					return nil, err
+				}
+				result.Created++
+				localIDs[entry.ID] = category.ID
+				continue
+			}
+			// Adopt the local category with the same name
+			local = namesake
+		}
+
+		before := *local
+		local.CatalogCategoryID = &entry.ID
+		if namesake != nil && namesake.ID != local.ID {
+			result.addConflict(subscription, models.CatalogEntityCategory, entry.ID, &local.ID, "name", entry.Name, local.Name, "another local category already uses this name")
+		} else {
+			local.Name = entry.Name
+		}
+		local.Description = entry.Description
+		local.Color = entry.Color
+		local.IsActive = entry.IsActive
+		if !reflect.DeepEqual(before, *local) {
+			if err := s.categories.Update(local); err != nil {
+				return nil, err
+			}
+			result.Updated++
+		}
+		localIDs[entry.ID] = local.ID
+	}
+	return localIDs, nil
+}
+
+// syncCatalogItems syncs the catalog inventory items into an account and returns the
+// local item ID of each catalog item.
+func (s *Service) syncCatalogItems(subscription *models.CatalogSubscription, categoryIDs map[int]int, result *CatalogSyncResult) (map[int]int, error) {
+	catalog, err := s.catalogItems.GetByOrganizationID(subscription.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	locals, err := s.inventoryItems.GetByAccountID(subscription.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	linked := make(map[int]*models.InventoryItem)
+	byName := make(map[string]*models.InventoryItem)
+	for i := range locals {
+		if locals[i].CatalogItemID != nil {
+			linked[*locals[i].CatalogItemID] = &locals[i]
+		}
+		byName[catalogKey(locals[i].Name)] = &locals[i]
+	}
+
+	localIDs := make(map[int]int)
+	for _, entry := range catalog {
+		local := linked[entry.ID]
+		namesake := byName[catalogKey(entry.Name)]
+
+		if local == nil {
+			if !entry.IsActive {
+				continue
+			}
+			if namesake != nil && namesake.CatalogItemID != nil {
+				result.addConflict(subscription, models.CatalogEntityItem, entry.ID, &namesake.ID, "name", entry.Name, namesake.Name, "a local item with this name is linked to another catalog item")
+				continue
+			}
+			if namesake != nil && namesake.Unit != entry.Unit {
+				result.addConflict(subscription, models.CatalogEntityItem, entry.ID, &namesake.ID, "unit", entry.Unit, namesake.Unit, "a local item with this name is counted in a different unit")
+				continue
+			}
+			if namesake == nil {
+				item := &models.InventoryItem{
+					AccountID:       subscription.AccountID,
+					Name:            entry.Name,
+					Unit:            entry.Unit,
+					CostPerUnit:     entry.CostPerUnit,
+					PreferredVendor: entry.PreferredVendor,
+					MinStockLevel:   entry.MinStockLevel,
+					MaxStockLevel:   entry.MaxStockLevel,
+					MinWeeksStock:   2,
+					MaxWeeksStock:   8,
+					CategoryID:      catalogLocalID(categoryIDs, entry.CatalogCategoryID),
+					ShelfLifeDays:   entry.ShelfLifeDays,
+					CatalogItemID:   &entry.ID,
+				}
+				if err := s.linkItemVendor(item); err != nil {
+					return nil, err
+				}
+				if err := s.inventoryItems.Create(item); err != nil {
+					return nil, err
+				}
+				result.Created++
+				localIDs[entry.ID] = item.ID
+				continue
+			}
+			// Adopt the local item with the same name, keeping its cost and vendor
+			local = namesake
+		}
+
+		before := *local
+		local.CatalogItemID = &entry.ID
+		if namesake != nil && namesake.ID != local.ID {
+			result.addConflict(subscription, models.CatalogEntityItem, entry.ID, &local.ID, "name", entry.Name, local.Name, "another local item already uses this name")
+		} else {
+			local.Name = entry.Name
+		}
+		if local.Unit != entry.Unit {
+			// Changing the unit would misstate every count and delivery already recorded
+			result.addConflict(subscription, models.CatalogEntityItem, entry.ID, &local.ID, "unit", entry.Unit, local.Unit, "the unit of an item with recorded stock cannot change")
+		}
+		local.CategoryID = catalogLocalID(categoryIDs, entry.CatalogCategoryID)
+		local.MinStockLevel = entry.MinStockLevel
+		local.MaxStockLevel = entry.MaxStockLevel
+		local.ShelfLifeDays = entry.ShelfLifeDays
+		if !reflect.DeepEqual(before, *local) {
+			if err := s.inventoryItems.Update(local); err != nil {
+				return nil, err
+			}
+			result.Updated++
+		}
+		localIDs[entry.ID] = local.ID
+	}
+	return localIDs, nil
+}
+
+// syncCatalogMenuItems syncs the catalog menu items and their recipes into an account.
+func (s *Service) syncCatalogMenuItems(subscription *models.CatalogSubscription, categoryIDs, itemIDs map[int]int, result *CatalogSyncResult) error {
+	catalog, err := s.catalogMenuItems.GetByOrganizationID(subscription.OrganizationID)
+	if err != nil {
+		return err
+	}
+	locals, err := s.menuItems.GetByAccountID(subscription.AccountID)
+	if err != nil {
+		return err
+	}
+	categories, err := s.catalogCategories.GetByOrganizationID(subscription.OrganizationID)
+	if err != nil {
+		return err
+	}
+	categoryNames := make(map[int]string, len(categories))
+	for _, category := range categories {
+		categoryNames[category.ID] = category.Name
+	}
+
+	linked := make(map[int]*models.MenuItem)
+	byName := make(map[string]*models.MenuItem)
+	for i := range locals {
+		if locals[i].CatalogMenuItemID != nil {
+			linked[*locals[i].CatalogMenuItemID] = &locals[i]
+		}
+		byName[catalogKey(locals[i].Name)] = &locals[i]
+	}
+
+	for _, entry := range catalog {
+		local := linked[entry.ID]
+		namesake := byName[catalogKey(entry.Name)]
+
+		if local == nil {
+			if !entry.IsActive {
+				continue
+			}
+			if namesake != nil && namesake.CatalogMenuItemID != nil {
+				result.addConflict(subscription, models.CatalogEntityMenuItem, entry.ID, &namesake.ID, "name", entry.Name, namesake.Name, "a local menu item with this name is linked to another catalog menu item")
+				continue
+			}
+			local = namesake
+		}
+
+		if local == nil {
+			local = &models.MenuItem{AccountID: subscription.AccountID, Name: entry.Name, CatalogMenuItemID: &entry.ID}
+			local.Price = entry.Price
+			local.CategoryID = catalogLocalID(categoryIDs, entry.CatalogCategoryID)
+			if entry.CatalogCategoryID != nil {
+				local.Category = categoryNames[*entry.CatalogCategoryID]
+			}
+			if err := s.menuItems.Create(local); err != nil {
+				return err
+			}
+			result.Created++
+		} else {
+			before := *local
+			local.CatalogMenuItemID = &entry.ID
+			if namesake != nil && namesake.ID != local.ID {
+				result.addConflict(subscription, models.CatalogEntityMenuItem, entry.ID, &local.ID, "name", entry.Name, local.Name, "another local menu item already uses this name")
+			} else {
+				local.Name = entry.Name
+			}
+			local.Price = entry.Price
+			local.CategoryID = catalogLocalID(categoryIDs, entry.CatalogCategoryID)
+			if entry.CatalogCategoryID != nil {
+				local.Category = categoryNames[*entry.CatalogCategoryID]
+			}
+			if !reflect.DeepEqual(before, *local) {
+				if err := s.menuItems.Update(local); err != nil {
+					return err
+				}
+				result.Updated++
+			}
+		}
+
+		if err := s.syncCatalogRecipe(subscription, entry.ID, local, itemIDs, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncCatalogRecipe replaces a local menu item's recipe with the catalog recipe, or
+// reports a conflict when an ingredient is not synced to the account.
+func (s *Service) syncCatalogRecipe(subscription *models.CatalogSubscription, catalogMenuItemID int, local *models.MenuItem, itemIDs map[int]int, result *CatalogSyncResult) error {
+	catalogIngredients, err := s.catalogRecipes.GetByCatalogMenuItemID(catalogMenuItemID)
+	if err != nil {
+		return err
+	}
+
+	recipe := make([]models.RecipeIngredient, 0, len(catalogIngredients))
+	for _, ingredient := range catalogIngredients {
+		itemID, ok := itemIDs[ingredient.CatalogItemID]
+		if !ok {
+			result.addConflict(subscription, models.CatalogEntityMenuItem, catalogMenuItemID, &local.ID, "recipe", strconv.Itoa(ingredient.CatalogItemID), "", "a recipe ingredient is not synced to this account")
+			return nil
+		}
+		recipe = append(recipe, models.RecipeIngredient{MenuItemID: local.ID, InventoryItemID: itemID, Quantity: ingredient.Quantity})
+	}
+
+	current, err := s.recipes.GetIngredientsByMenuItemID(uint(local.ID))
+	if err != nil {
+		return err
+	}
+	if sameRecipe(current, recipe) {
+		return nil
+	}
+	return s.recipes.ReplaceIngredients(local.ID, recipe)
+}
+
+// pushCatalog syncs an organization's catalog into every subscribed account.
+// A failed sync is logged and does not stop the other accounts from receiving the change.
+func (s *Service) pushCatalog(organizationID int) {
+	subscriptions, err := s.catalogSubscriptions.GetActiveByOrganizationID(organizationID)
+	if err != nil {
+		log.Printf("Warning: could not load catalog subscriptions for organization %d: %v", organizationID, err)
+		return
+	}
+	for _, subscription := range subscriptions {
+		if _, err := s.SyncCatalog(subscription.AccountID); err != nil {
+			log.Printf("Warning: could not sync catalog to account %d: %v", subscription.AccountID, err)
+		}
+	}
+}
+
+// validateCatalogCategory checks a catalog category against the rest of its catalog
+func (s *Service) validateCatalogCategory(category *models.CatalogCategory) error {
+	if _, err := s.organizations.GetByID(category.OrganizationID); err != nil {
+		return errors.New("invalid organization ID")
+	}
+	category.Name = strings.TrimSpace(category.Name)
+	if category.Name == "" {
+		return errors.New("category name is required")
+	}
+
+	existing, err := s.catalogCategories.GetByOrganizationID(category.OrganizationID)
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if other.ID != category.ID && catalogKey(other.Name) == catalogKey(category.Name) {
+			return errors.New("category name already exists in this catalog")
+		}
+	}
+	return nil
+}
+
+// validateCatalogItem checks a catalog item against the rest of its catalog
+func (s *Service) validateCatalogItem(item *models.CatalogItem) error {
+	if _, err := s.organizations.GetByID(item.OrganizationID); err != nil {
+		return errors.New("invalid organization ID")
+	}
+	item.Name = strings.TrimSpace(item.Name)
+	item.Unit = strings.TrimSpace(item.Unit)
+	if item.Name == "" {
+		return errors.New("item name is required")
+	}
+	if item.Unit == "" {
+		return errors.New("unit is required")
+	}
+	if err := s.validateCatalogCategoryRef(item.OrganizationID, item.CatalogCategoryID); err != nil {
+		return err
+	}
+
+	existing, err := s.catalogItems.GetByOrganizationID(item.OrganizationID)
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if other.ID != item.ID && catalogKey(other.Name) == catalogKey(item.Name) {
+			return errors.New("item name already exists in this catalog")
+		}
+	}
+	return nil
+}
+
+// validateCatalogMenuItem checks a catalog menu item and its recipe against the rest of its catalog
+func (s *Service) validateCatalogMenuItem(item *CatalogMenuItemWithRecipe) error {
+	if _, err := s.organizations.GetByID(item.OrganizationID); err != nil {
+		return errors.New("invalid organization ID")
+	}
+	item.Name = strings.TrimSpace(item.Name)
+	if item.Name == "" {
+		return errors.New("item name is required")
+	}
+	if err := s.validateCatalogCategoryRef(item.OrganizationID, item.CatalogCategoryID); err != nil {
+		return err
+	}
+
+	existing, err := s.catalogMenuItems.GetByOrganizationID(item.OrganizationID)
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if other.ID != item.ID && catalogKey(other.Name) == catalogKey(item.Name) {
+			return errors.New("item name already exists in this catalog")
+		}
+	}
+
+	for _, ingredient := range item.Ingredients {
+		if ingredient.Quantity <= 0 {
+			return errors.New("recipe quantities must be positive")
+		}
+		catalogItem, err := s.catalogItems.GetByID(ingredient.CatalogItemID)
+		if err != nil || catalogItem.OrganizationID != item.OrganizationID {
+			return fmt.Errorf("invalid catalog item ID: %d", ingredient.CatalogItemID)
+		}
+	}
+	return nil
+}
+
+// validateCatalogCategoryRef checks that an optional catalog category belongs to the organization
+func (s *Service) validateCatalogCategoryRef(organizationID int, categoryID *int) error {
+	if categoryID == nil {
+		return nil
+	}
+	category, err := s.catalogCategories.GetByID(*categoryID)
+	if err != nil || category.OrganizationID != organizationID {
+		return errors.New("invalid catalog category ID")
+	}
+	return nil
+}
+
+// addConflict records a catalog change that could not be applied to the account
+func (r *CatalogSyncResult) addConflict(subscription *models.CatalogSubscription, entityType string, catalogEntityID int, localEntityID *int, field, catalogValue, localValue, reason string) {
+	r.Conflicts = append(r.Conflicts, models.CatalogSyncConflict{
+		OrganizationID:  subscription.OrganizationID,
+		AccountID:       subscription.AccountID,
+		EntityType:      entityType,
+		CatalogEntityID: catalogEntityID,
+		LocalEntityID:   localEntityID,
+		Field:           field,
+		CatalogValue:    catalogValue,
+		LocalValue:      localValue,
+		Reason:          reason,
+		DetectedAt:      r.SyncedAt,
+	})
+}
+
+// catalogKey normalizes a name for matching catalog entries to local entries
+func catalogKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// catalogLocalID maps an optional catalog ID to the local ID synced from it
+func catalogLocalID(localIDs map[int]int, catalogID *int) *int {
+	if catalogID == nil {
+		return nil
+	}
+	if localID, ok := localIDs[*catalogID]; ok {
+		return &localID
+	}
+	return nil
+}
+
+// sameRecipe reports whether two recipes use the same quantities of the same items
+func sameRecipe(a, b []models.RecipeIngredient) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	quantities := make(map[int]float64, len(a))
+	for _, ingredient := range a {
+		quantities[ingredient.InventoryItemID] += ingredient.Quantity
+	}
+	for _, ingredient := range b {
+		quantities[ingredient.InventoryItemID] -= ingredient.Quantity
+	}
+	for _, difference := range quantities {
+		if difference != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Waste operations
+// These methods handle the stock that staff discard, which reduces current stock
+// and drives each item's wastage rate and the waste cost report.
+
+// wastageRateWindowDays is the trailing period an item's wastage rate is computed over
+const wastageRateWindowDays = 30
+
+// WasteCostReport summarizes the cost of waste over a reporting period
+type WasteCostReport struct {
+	StartDate  time.Time        `json:"start_date"`
+	EndDate    time.Time        `json:"end_date"`
+	Entries    int              `json:"entries"`
+	TotalCost  float64          `json:"total_cost"`
+	ByReason   []WasteCostGroup `json:"by_reason"`
+	ByCategory []WasteCostGroup `json:"by_category"`
+}
+
+// WasteCostGroup holds the waste cost of one reason or category
+type WasteCostGroup struct {
+	Name    string  `json:"name"`
+	Entries int     `json:"entries"`
+	Cost    float64 `json:"cost"`
+	Share   float64 `json:"share"` // Percentage of the report's total cost
+}
+
+// LogWaste records a discarded quantity of an inventory item.
+// The waste is valued at the item's current unit cost, drawn from the item's
+// oldest lots, and the item's wastage rate is recomputed.
+//
+// Parameters:
+//   - wasteLog: The waste entry to record
+//
+// Returns:
+//   - error: Any error that occurred during validation or creation
+//
+// Business rules:
+//   - Quantity must be positive
+//   - Reason must be expired, spilled, comped, or overproduced
+//   - Inventory item must exist and belong to the same account
+//   - WastedAt defaults to now if not provided
+func (s *Service) LogWaste(wasteLog *models.WasteLog) error {
+	if wasteLog.Quantity <= 0 {
+		return errors.New("waste quantity must be positive")
+	}
+
+	wasteLog.Reason = strings.ToLower(strings.TrimSpace(wasteLog.Reason))
+	if !isValidWasteReason(wasteLog.Reason) {
+		return fmt.Errorf("invalid waste reason: %s", wasteLog.Reason)
+	}
+
+	// Validate that the inventory item exists and belongs to the account
+	item, err := s.inventoryItems.GetByID(wasteLog.InventoryItemID)
+	if err != nil {
+		return errors.New("invalid inventory item ID")
+	}
+	if item.AccountID != wasteLog.AccountID {
+		return errors.New("inventory item does not belong to this account")
+	}
+
+	if wasteLog.WastedAt.IsZero() {
+		wasteLog.WastedAt = time.Now()
+	}
+	wasteLog.UnitCost = item.CostPerUnit
+
+	// Discard from the given location or the account's default location
+	if wasteLog.StorageLocationID, err = s.resolveStorageLocation(wasteLog.AccountID, wasteLog.StorageLocationID); err != nil {
+		return err
+	}
+
+	return s.withTransaction(func(tx *Service) error {
+		if err := tx.wasteLogs.Create(wasteLog); err != nil {
+			return err
+		}
+		if err := tx.applyStockMovement(wasteLog.AccountID, item.ID, wasteLog.WastedAt, -wasteLog.Quantity); err != nil {
+			return err
+		}
+
+		// Discarded stock leaves the oldest lots first; waste beyond the lots predates lot tracking
+		if _, err := tx.ConsumeFromLots(item.ID, wasteLog.Quantity); err != nil {
+			return err
+		}
+
+		_, err := tx.RecomputeWastageRate(item.ID)
+		return err
+	})
+}
+
+// GetWasteLog retrieves a waste entry by its ID.
+//
+// Parameters:
+//   - id: The unique identifier of the waste entry
+//
+// Returns:
+//   - *models.WasteLog: The waste entry if found
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetWasteLog(id int) (*models.WasteLog, error) {
+	return s.wasteLogs.GetByID(id)
+}
+
+// GetWasteLogs retrieves the waste entries of an account within a period, newest first.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//   - startDate: The start of the period
+//   - endDate: The end of the period
+//
+// Returns:
+//   - []models.WasteLog: List of waste entries in the period
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetWasteLogs(accountID int, startDate, endDate time.Time) ([]models.WasteLog, error) {
+	if endDate.Before(startDate) {
+		return nil, errors.New("end date must not be before start date")
+	}
+	return s.wasteLogs.GetByAccountIDAndDateRange(accountID, startDate, endDate)
+}
+
+// DeleteWasteLog removes a waste entry that was recorded in error and recomputes
+// the item's wastage rate. Lot quantities already drawn down are not restored;
+// the next stock count corrects them.
+//
+// Parameters:
+//   - id: The unique identifier of the waste entry to delete
+//
+// Returns:
+//   - error: Any error that occurred during deletion
+func (s *Service) DeleteWasteLog(id int) error {
+	wasteLog, err := s.wasteLogs.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	return s.withTransaction(func(tx *Service) error {
+		if err := tx.wasteLogs.Delete(id); err != nil {
+			return err
+		}
+		if err := tx.applyStockMovement(wasteLog.AccountID, wasteLog.InventoryItemID, wasteLog.WastedAt, wasteLog.Quantity); err != nil {
+			return err
+		}
+
+		_, err := tx.RecomputeWastageRate(wasteLog.InventoryItemID)
+		return err
+	})
+}
+
+// RecomputeWastageRate updates an item's wastage rate from the waste logged over
+// the last 30 days, measured against the item's usage over the same period.
+// Usage is the quantity consumed by sales through recipes, so the rate is
+// waste / (usage + waste) as a percentage. The stored rate is refreshed when waste
+// is logged or deleted; listings with current stock compute it afresh.
+//
+// Parameters:
+//   - itemID: The unique identifier of the inventory item
+//
+// Returns:
+//   - *models.InventoryItem: The item with its updated wastage rate
+//   - error: Any error that occurred during the calculation or update
+func (s *Service) RecomputeWastageRate(itemID int) (*models.InventoryItem, error) {
+	item, err := s.inventoryItems.GetByID(itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	since := time.Now().AddDate(0, 0, -wastageRateWindowDays)
+
+	wasteLogs, err := s.wasteLogs.GetByItemIDAfterDate(itemID, since)
+	if err != nil {
+		return nil, err
+	}
+	var wasted float64
+	for _, wasteLog := range wasteLogs {
+		wasted += wasteLog.Quantity
+	}
+
+	consumed, err := s.salesConsumptionSince(item.AccountID, since)
+	if err != nil {
+		return nil, err
+	}
+	used := consumed[itemID]
+
+	item.WastageRate = wastageRate(wasted, used)
+	if err := s.inventoryItems.Update(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// wastageRates computes the current wastage rate of every item of an account wasted over the
+// last wastageRateWindowDays days; items missing from the map have a rate of 0
+func (s *Service) wastageRates(accountID int) (map[int]float64, error) {
+	since := time.Now().AddDate(0, 0, -wastageRateWindowDays)
+	wasteLogs, err := s.wasteLogs.GetByAccountIDAfterDate(accountID, since)
+	if err != nil {
+		return nil, err
+	}
+	rates := make(map[int]float64)
+	if len(wasteLogs) == 0 {
+		return rates, nil
+	}
+
+	wasted := make(map[int]float64)
+	for _, wasteLog := range wasteLogs {
+		wasted[wasteLog.InventoryItemID] += wasteLog.Quantity
+	}
+	consumed, err := s.salesConsumptionSince(accountID, since)
+	if err != nil {
+		return nil, err
+	}
+	for itemID, quantity := range wasted {
+		rates[itemID] = wastageRate(quantity, consumed[itemID])
+	}
+	return rates, nil
+}
+
+// wastageRate is waste as a percentage of the stock consumed by usage and waste, rounded to two decimals
+func wastageRate(wasted, used float64) float64 {
+	if used+wasted <= 0 {
+		return 0
+	}
+	return math.Round(wasted/(used+wasted)*10000) / 100
+}
+
+// GetWasteCostReport totals the cost of waste in a period by reason and by item category.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//   - startDate: The start of the reporting period
+//   - endDate: The end of the reporting period
+//
+// Returns:
+//   - *WasteCostReport: The report with groups ordered by cost, highest first
+//   - error: Any error that occurred while building the report
+func (s *Service) GetWasteCostReport(accountID int, startDate, endDate time.Time) (*WasteCostReport, error) {
+	wasteLogs, err := s.GetWasteLogs(accountID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.inventoryItems.GetByAccountID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	categoryIDsByItem := make(map[int]*int, len(items))
+	for _, item := range items {
+		categoryIDsByItem[item.ID] = item.CategoryID
+	}
+
+	categories, err := s.categories.GetByAccountID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	categoryNames := make(map[int]string, len(categories))
+	for _, category := range categories {
+		categoryNames[category.ID] = category.Name
+	}
+
+	report := &WasteCostReport{StartDate: startDate, EndDate: endDate, Entries: len(wasteLogs)}
+	byReason := make(map[string]*WasteCostGroup)
+	byCategory := make(map[string]*WasteCostGroup)
+	addTo := func(groups map[string]*WasteCostGroup, name string, cost float64) {
+		group, ok := groups[name]
+		if !ok {
+			group = &WasteCostGroup{Name: name}
+			groups[name] = group
+		}
+		group.Entries++
+		group.Cost += cost
+	}
+
+	for _, wasteLog := range wasteLogs {
+		cost := wasteLog.Quantity * wasteLog.UnitCost
+		report.TotalCost += cost
+
+		categoryName := "Uncategorized"
+		if categoryID := categoryIDsByItem[wasteLog.InventoryItemID]; categoryID != nil {
+			if name, ok := categoryNames[*categoryID]; ok {
+				categoryName = name
+			}
+		}
+
+		addTo(byReason, wasteLog.Reason, cost)
+		addTo(byCategory, categoryName, cost)
+	}
+
+	report.ByReason = sortedWasteCostGroups(byReason, report.TotalCost)
+	report.ByCategory = sortedWasteCostGroups(byCategory, report.TotalCost)
+	return report, nil
+}
+
+// sortedWasteCostGroups orders waste cost groups by cost, highest first, and fills in their share of the total
+func sortedWasteCostGroups(groups map[string]*WasteCostGroup, totalCost float64) []WasteCostGroup {
+	result := make([]WasteCostGroup, 0, len(groups))
+	for _, group := range groups {
+		if totalCost > 0 {
+			group.Share = math.Round(group.Cost/totalCost*10000) / 100
+		}
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Cost != result[j].Cost {
+			return result[i].Cost > result[j].Cost
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// isValidWasteReason reports whether reason is a supported waste reason
+func isValidWasteReason(reason string) bool {
+	switch reason {
+	case models.WasteReasonExpired, models.WasteReasonSpilled, models.WasteReasonComped, models.WasteReasonOverproduced:
+		return true
+	}
+	return false
+}
+
+// Stock adjustment operations
+// These methods handle ad-hoc stock changes outside of counts, deliveries, sales,
+// and waste. Adjustments cannot be edited or deleted, so together they form an
+// audit trail of who changed stock, by how much, and why.
+
+// RecordStockAdjustment records an ad-hoc change to the stock of an inventory item.
+// The adjustment is valued at the item's current unit cost, and stock removed by
+// an adjustment is drawn from the item's oldest lots.
+//
+// Parameters:
+//   - adjustment: The adjustment to record; Quantity is the signed change in stock
+//
+// Returns:
+//   - error: Any error that occurred during validation or creation
+//
+// Business rules:
+//   - Quantity must not be zero
+//   - Reason must be count_correction, breakage, internal_use, or sample
+//   - Only count corrections may add stock; the other reasons must have a negative quantity
+//   - Inventory item must exist and belong to the same account
+//   - AdjustedAt defaults to now if not provided
+func (s *Service) RecordStockAdjustment(adjustment *models.StockAdjustment) error {
+	if adjustment.Quantity == 0 {
+		return errors.New("adjustment quantity must not be zero")
+	}
+
+	adjustment.Reason = strings.ToLower(strings.TrimSpace(adjustment.Reason))
+	if !isValidAdjustmentReason(adjustment.Reason) {
+		return fmt.Errorf("invalid adjustment reason: %s", adjustment.Reason)
+	}
+	if adjustment.Quantity > 0 && adjustment.Reason != models.AdjustmentReasonCountCorrection {
+		return fmt.Errorf("a %s adjustment must remove stock", adjustment.Reason)
+	}
+
+	// Validate that the inventory item exists and belongs to the account
+	item, err := s.inventoryItems.GetByID(adjustment.InventoryItemID)
+	if err != nil {
+		return errors.New("invalid inventory item ID")
+	}
+	if item.AccountID != adjustment.AccountID {
+		return errors.New("inventory item does not belong to this account")
+	}
+
+	if adjustment.AdjustedAt.IsZero() {
+		adjustment.AdjustedAt = time.Now()
+	}
+	adjustment.UnitCost = item.CostPerUnit
+
+	// Adjust the given location or the account's default location
+	if adjustment.StorageLocationID, err = s.resolveStorageLocation(adjustment.AccountID, adjustment.StorageLocationID); err != nil {
+		return err
+	}
+
+	return s.withTransaction(func(tx *Service) error {
+		if err := tx.stockAdjustments.Create(adjustment); err != nil {
+			return err
+		}
+		if err := tx.applyStockMovement(adjustment.AccountID, item.ID, adjustment.AdjustedAt, adjustment.Quantity); err != nil {
+			return err
+		}
+
+		// Removed stock leaves the oldest lots first; stock added by a correction has no lot
+		if adjustment.Quantity < 0 {
+			if _, err := tx.ConsumeFromLots(item.ID, -adjustment.Quantity); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetStockAdjustment retrieves a stock adjustment by its ID.
+//
+// Parameters:
+//   - id: The unique identifier of the adjustment
+//
+// Returns:
+//   - *models.StockAdjustment: The adjustment if found
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetStockAdjustment(id int) (*models.StockAdjustment, error) {
+	return s.stockAdjustments.GetByID(id)
+}
+
+// GetStockAdjustments retrieves the stock adjustments of an account within a period, newest first.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//   - startDate: The start of the period
+//   - endDate: The end of the period
+//
+// Returns:
+//   - []models.StockAdjustment: List of adjustments in the period
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetStockAdjustments(accountID int, startDate, endDate time.Time) ([]models.StockAdjustment, error) {
+	if endDate.Before(startDate) {
+		return nil, errors.New("end date must not be before start date")
+	}
+	return s.stockAdjustments.GetByAccountIDAndDateRange(accountID, startDate, endDate)
+}
+
+// isValidAdjustmentReason reports whether reason is a supported stock adjustment reason
+func isValidAdjustmentReason(reason string) bool {
+	switch reason {
+	case models.AdjustmentReasonCountCorrection, models.AdjustmentReasonBreakage,
+		models.AdjustmentReasonInternalUse, models.AdjustmentReasonSample:
+		return true
+	}
+	return false
+}
+
+// Count session operations
+// These methods handle guided physical counts. Staff record counts by zone while a
+// session is open, review them against expected stock, and finalize the session
+// into an inventory snapshot.
+
+// DefaultCountVarianceThresholdPct is the variance from expected stock, as a percentage,
+// above which a counted item is flagged for review
+const DefaultCountVarianceThresholdPct = 10.0
+
+// CountReview compares the counts of a session with the stock expected on hand
+type CountReview struct {
+	SessionID    int               `json:"session_id"`
+	Status       string            `json:"status"`
+	ThresholdPct float64           `json:"threshold_pct"`
+	Lines        []CountReviewLine `json:"lines"`
+	FlaggedLines int               `json:"flagged_lines"`
+	Uncounted    []CountReviewLine `json:"uncounted"` // Items with expected stock that nobody counted
+}
+
+// CountReviewLine holds the counted and expected quantity of one inventory item
+type CountReviewLine struct {
+	InventoryItemID int         `json:"inventory_item_id"`
+	ItemName        string      `json:"item_name"`
+	Unit            string      `json:"unit"`
+	Counted         float64     `json:"counted"`
+	Expected        float64     `json:"expected"`
+	Variance        float64     `json:"variance"`     // Counted minus expected
+	VariancePct     float64     `json:"variance_pct"` // Variance as a percentage of expected stock
+	Flagged         bool        `json:"flagged"`
+	Zones           []CountZone `json:"zones,omitempty"`
+}
+
+// CountZone holds the quantity of an item counted in one zone of a storage location
+type CountZone struct {
+	StorageLocationID *int    `json:"storage_location_id,omitempty"`
+	LocationName      string  `json:"location_name,omitempty"`
+	Zone              string  `json:"zone"`
+	Quantity          float64 `json:"quantity"`
+}
+
+// StartCountSession opens a new count session for an account.
+//
+// Parameters:
+//   - session: The count session to open
+//
+// Returns:
+//   - error: Any error that occurred during validation or creation
+//
+// Business rules:
+//   - Account must exist
+//   - The session always starts open; StartedAt defaults to now
+func (s *Service) StartCountSession(session *models.CountSession) error {
+	// Validate that the account exists
+	if _, err := s.accounts.GetByID(session.AccountID); err != nil {
+		return errors.New("invalid account ID")
+	}
+
+	session.Name = strings.TrimSpace(session.Name)
+	session.Status = models.CountSessionStatusOpen
+	session.ClosedBy = nil
+	session.ClosedAt = nil
+	session.SnapshotID = nil
+	if session.StartedAt.IsZero() {
+		session.StartedAt = time.Now()
+	}
+
+	return s.countSessions.Create(session)
+}
+
+// GetCountSession retrieves a count session by its ID.
+//
+// Parameters:
+//   - id: The unique identifier of the count session
+//
+// Returns:
+//   - *models.CountSession: The count session if found
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetCountSession(id int) (*models.CountSession, error) {
+	return s.countSessions.GetByID(id)
+}
+
+// GetCountSessionsByAccount retrieves the count sessions of an account, newest first.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//
+// Returns:
+//   - []models.CountSession: List of count sessions in every status
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetCountSessionsByAccount(accountID int) ([]models.CountSession, error) {
+	return s.countSessions.GetByAccountID(accountID)
+}
+
+// GetCountEntries retrieves the counts recorded in a session, ordered by zone.
+//
+// Parameters:
+//   - sessionID: The unique identifier of the count session
+//
+// Returns:
+//   - []models.CountEntry: List of count entries
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetCountEntries(sessionID int) ([]models.CountEntry, error) {
+	return s.countEntries.GetBySessionID(sessionID)
+}
+
+// RecordCount records the quantity of an item counted in one zone of an open session.
+// Several staff can count different zones of the same session; counting the same
+// item in the same location and zone again replaces the earlier count.
+//
+// Parameters:
+//   - entry: The count to record; CountSessionID identifies the session
+//
+// Returns:
+//   - error: Any error that occurred during validation or saving
+//
+// Business rules:
+//   - The session must exist and be open
+//   - Inventory item must belong to the session's account
+//   - Storage location defaults to the account's default location
+//   - Quantity cannot be negative
+func (s *Service) RecordCount(entry *models.CountEntry) error {
+	session, err := s.countSessions.GetByID(entry.CountSessionID)
+	if err != nil {
+		return errors.New("invalid count session ID")
+	}
+	if session.Status != models.CountSessionStatusOpen {
+		return fmt.Errorf("count session is %s", session.Status)
+	}
+
+	item, err := s.inventoryItems.GetByID(entry.InventoryItemID)
+	if err != nil || item.AccountID != session.AccountID {
+		return errors.New("invalid inventory item ID")
+	}
+	if entry.Quantity < 0 {
+		return errors.New("counted quantity cannot be negative")
+	}
+
+	if entry.StorageLocationID, err = s.resolveStorageLocation(session.AccountID, entry.StorageLocationID); err != nil {
+		return err
+	}
+
+	entry.AccountID = session.AccountID
+	entry.Zone = strings.TrimSpace(entry.Zone)
+	if entry.CountedAt.IsZero() {
+		entry.CountedAt = time.Now()
+	}
+
+	// A recount of the same item in the same place replaces the earlier count
+	existing, err := s.countEntries.GetBySessionItemAndPlace(session.ID, entry.InventoryItemID, entry.StorageLocationID, entry.Zone)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if existing != nil {
+		entry.ID = existing.ID
+		entry.CreatedAt = existing.CreatedAt
+		return s.countEntries.Update(entry)
+	}
+
+	entry.ID = 0
+	return s.countEntries.Create(entry)
+}
+
+// ReviewCountSession compares a session's counts with the stock expected on hand
+// and flags items whose variance exceeds the threshold.
+//
+// Parameters:
+//   - sessionID: The unique identifier of the count session
+//   - thresholdPct: The variance percentage that flags an item (0 or less uses the default of 10%)
+//
+// Returns:
+//   - *CountReview: Counted items ordered by name, and items that were not counted
+//   - error: Any error that occurred while building the review
+//
+// Business rules:
+//   - Counts for an item are summed across locations and zones
+//   - Counting stock that was not expected at all is always flagged
+func (s *Service) ReviewCountSession(sessionID int, thresholdPct float64) (*CountReview, error) {
+	session, err := s.countSessions.GetByID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if thresholdPct <= 0 {
+		thresholdPct = DefaultCountVarianceThresholdPct
+	}
+
+	entries, err := s.countEntries.GetBySessionID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.GetInventoryItemsWithCurrentStock(session.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	locations, err := s.storageLocations.GetByAccountID(session.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	locationNames := make(map[int]string, len(locations))
+	for _, location := range locations {
+		locationNames[location.ID] = location.Name
+	}
+
+	review := &CountReview{
+		SessionID:    session.ID,
+		Status:       session.Status,
+		ThresholdPct: thresholdPct,
+		Lines:        []CountReviewLine{},
+		Uncounted:    []CountReviewLine{},
+	}
+
+	zonesByItem := make(map[int][]CountZone)
+	for _, entry := range entries {
+		zone := CountZone{StorageLocationID: entry.StorageLocationID, Zone: entry.Zone, Quantity: entry.Quantity}
+		if entry.StorageLocationID != nil {
+			zone.LocationName = locationNames[*entry.StorageLocationID]
+		}
+		zonesByItem[entry.InventoryItemID] = append(zonesByItem[entry.InventoryItemID], zone)
+	}
+
+	for _, item := range items {
+		line := CountReviewLine{
+			InventoryItemID: item.ID,
+			ItemName:        item.Name,
+			Unit:            item.Unit,
+			Expected:        item.CurrentStock,
+		}
+
+		zones, counted := zonesByItem[item.ID]
+		if !counted {
+			if item.CurrentStock != 0 {
+				review.Uncounted = append(review.Uncounted, line)
+			}
+			continue
+		}
+
+		line.Zones = zones
+		for _, zone := range zones {
+			line.Counted += zone.Quantity
+		}
+		line.Variance = line.Counted - line.Expected
+		if line.Expected != 0 {
+			line.VariancePct = math.Round(line.Variance/math.Abs(line.Expected)*10000) / 100
+			line.Flagged = math.Abs(line.VariancePct) > thresholdPct
+		} else {
+			line.Flagged = line.Counted != 0
+		}
+		if line.Flagged {
+			review.FlaggedLines++
+		}
+		review.Lines = append(review.Lines, line)
+	}
+
+	sort.Slice(review.Lines, func(i, j int) bool { return review.Lines[i].ItemName < review.Lines[j].ItemName })
+	sort.Slice(review.Uncounted, func(i, j int) bool { return review.Uncounted[i].ItemName < review.Uncounted[j].ItemName })
+	return review, nil
+}
+
+// FinalizeCountSession closes an open session and produces its inventory snapshot.
+// The snapshot and the session update are written in one transaction, so a failed
+// finalize leaves the session open and no snapshot behind.
+//
+// Parameters:
+//   - sessionID: The unique identifier of the count session
+//   - userID: The user finalizing the session
+//
+// Returns:
+//   - *models.InventorySnapshot: The snapshot produced from the session
+//   - error: Any error that occurred during finalizing
+//
+// Business rules:
+//   - The session must be open and contain at least one count
+//   - Counts for an item are summed across zones; counts at a storage location also
+//     make up the snapshot's per-location counts
+//   - Items that were not counted keep their expected stock, by location, in the snapshot
+func (s *Service) FinalizeCountSession(sessionID, userID int) (*models.InventorySnapshot, error) {
+	var snapshot *models.InventorySnapshot
+	err := s.withTransaction(func(tx *Service) error {
+		session, err := tx.countSessions.GetByID(sessionID)
+		if err != nil {
+			return err
+		}
+		if session.Status != models.CountSessionStatusOpen {
+			return fmt.Errorf("count session is %s", session.Status)
+		}
+
+		entries, err := tx.countEntries.GetBySessionID(sessionID)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return errors.New("count session has no counts")
+		}
+
+		counts := make(models.CountsMap)
+		locationCounts := make(models.LocationCountsMap)
+		addLocationCount := func(locationID, itemID int, quantity float64) {
+			if locationCounts[locationID] == nil {
+				locationCounts[locationID] = make(models.CountsMap)
+			}
+			locationCounts[locationID][itemID] += quantity
+		}
+		for _, entry := range entries {
+			counts[entry.InventoryItemID] += entry.Quantity
+			if entry.StorageLocationID != nil {
+				addLocationCount(*entry.StorageLocationID, entry.InventoryItemID, entry.Quantity)
+			}
+		}
+
+		// Carry forward stock that was not part of this count
+		items, err := tx.GetInventoryItemsWithCurrentStock(session.AccountID)
+		if err != nil {
+			return err
+		}
+		stock, err := tx.stockByLocation(session.AccountID)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if _, counted := counts[item.ID]; counted || item.CurrentStock == 0 {
+				continue
+			}
+			counts[item.ID] = item.CurrentStock
+			if stock[item.ID][unassignedLocation] < 0 {
+				// The breakdown would exceed the total, so carry the item forward unassigned
+				continue
+			}
+			for locationID, quantity := range stock[item.ID] {
+				if locationID != unassignedLocation && quantity != 0 {
+					addLocationCount(locationID, item.ID, quantity)
+				}
+			}
+		}
+
+		snapshot = &models.InventorySnapshot{AccountID: session.AccountID, Counts: counts, LocationCounts: locationCounts}
+		if err := tx.CreateInventorySnapshot(snapshot); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		session.Status = models.CountSessionStatusFinalized
+		session.ClosedBy = &userID
+		session.ClosedAt = &now
+		session.SnapshotID = &snapshot.ID
+		return tx.countSessions.Update(session)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// AbandonCountSession closes an open session without producing a snapshot.
+// Its counts are kept for reference but never reach inventory history.
+//
+// Parameters:
+//   - sessionID: The unique identifier of the count session
+//   - userID: The user abandoning the session
+//
+// Returns:
+//   - *models.CountSession: The abandoned session
+//   - error: Any error that occurred during the update
+func (s *Service) AbandonCountSession(sessionID, userID int) (*models.CountSession, error) {
+	session, err := s.countSessions.GetByID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != models.CountSessionStatusOpen {
+		return nil, fmt.Errorf("count session is %s", session.Status)
+	}
+
+	now := time.Now()
+	session.Status = models.CountSessionStatusAbandoned
+	session.ClosedBy = &userID
+	session.ClosedAt = &now
+	if err := s.countSessions.Update(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Inventory Snapshot operations
+// These methods handle historical inventory tracking.
+// Inventory snapshots represent a point-in-time view of inventory levels.
+
+// CreateInventorySnapshot creates a new inventory snapshot.
+// This method validates that the account exists and ensures the snapshot has valid data.
+//
+// Parameters:
+//   - snapshot: The inventory snapshot data to create
+//
+// Returns:
+//   - error: Any error that occurred during creation
+//
+// Business rules:
+//   - Account must exist
+//   - Timestamp must be set (defaults to now if not provided)
+//   - Location counts must reference the account's storage locations and fill in missing item totals
+//   - Counts map must not be empty
+//   - The account's inventory levels are rebuilt from the new count
+func (s *Service) CreateInventorySnapshot(snapshot *models.InventorySnapshot) error {
+	// Validate that the account exists
+	_, err := s.accounts.GetByID(snapshot.AccountID)
+	if err != nil {
+		return errors.New("invalid account ID")
+	}
+
+	// Ensure timestamp is set
+	if snapshot.Timestamp.IsZero() {
+		snapshot.Timestamp = time.Now()
+	}
+
+	// Fill item totals from the location breakdown
+	if err := s.applyLocationCounts(snapshot); err != nil {
+		return err
+	}
+
+	// Validate that counts map is not empty
+	if len(snapshot.Counts) == 0 {
+		return errors.New("snapshot must contain at least one inventory count")
+	}
+
+	return s.withTransaction(func(tx *Service) error {
+		if err := tx.inventorySnapshots.Create(snapshot); err != nil {
+			return err
+		}
+
+		// Stock missing from the count has been used, so draw it from the oldest lots
+		if err := tx.reconcileLots(snapshot.Counts); err != nil {
+			return err
+		}
+
+		// The count replaces the stock on hand, along with any movements it covers
+		return tx.RebuildInventoryLevels(snapshot.AccountID)
+	})
+}
+
+// applyLocationCounts validates a snapshot's location breakdown and fills in the
+// item totals it implies. A total that is larger than the item's location counts
+// keeps the difference as unassigned stock; a smaller total is rejected.
+func (s *Service) applyLocationCounts(snapshot *models.InventorySnapshot) error {
+	if len(snapshot.LocationCounts) == 0 {
+		return nil
+	}
+
+	located := make(map[int]float64)
+	for locationID, counts := range snapshot.LocationCounts {
+		location, err := s.storageLocations.GetByID(locationID)
+		if err != nil || location.AccountID != snapshot.AccountID {
+			return fmt.Errorf("invalid storage location ID: %d", locationID)
+		}
+		for itemID, quantity := range counts {
+			located[itemID] += quantity
+		}
+	}
+
+	if snapshot.Counts == nil {
+		snapshot.Counts = models.CountsMap{}
+	}
+	for itemID, quantity := range located {
+		total, ok := snapshot.Counts[itemID]
+		if !ok {
+			snapshot.Counts[itemID] = quantity
+			continue
+		}
+		if total < quantity {
+			return fmt.Errorf("count for item %d is less than its location counts", itemID)
+		}
+	}
+	return nil
+}
+
+// GetInventorySnapshot retrieves an inventory snapshot by its unique identifier.
+// This method provides access to inventory snapshot details for validation
+// and business logic operations.
+//
+// Parameters:
+//   - id: The unique identifier of the inventory snapshot to retrieve
+//
+// Returns:
+//   - *models.InventorySnapshot: The inventory snapshot data if found
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetInventorySnapshot(id int) (*models.InventorySnapshot, error) {
+	return s.inventorySnapshots.GetByID(id)
+}
+
+// GetInventorySnapshotsByAccount retrieves all inventory snapshots for a specific account.
+// This method provides a way to enumerate all inventory snapshots within
+// a single account for management purposes.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//
+// Returns:
+//   - []models.InventorySnapshot: List of inventory snapshots belonging to the account
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetInventorySnapshotsByAccount(accountID int) ([]models.InventorySnapshot, error) {
+	return s.inventorySnapshots.GetByAccountID(accountID)
+}
+
+// ListInventorySnapshots retrieves one page of an account's inventory snapshots, newest
+// first unless another order is requested, optionally limited to a date range.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//   - list: The page, sort order and date range to apply
+//
+// Returns:
+//   - []models.InventorySnapshot: The page of inventory snapshots
+//   - Page: Pagination metadata for the page
+//   - error: Any error that occurred during retrieval
+func (s *Service) ListInventorySnapshots(accountID int, list ListQuery) ([]models.InventorySnapshot, Page, error) {
+	return s.inventorySnapshots.List(accountID, list)
+}
+
+// GetLatestInventorySnapshot retrieves the most recent inventory snapshot for an account.
+// This method provides a way to fetch the latest inventory snapshot for
+// a specific account for reporting and analysis.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//
+// Returns:
+//   - *models.InventorySnapshot: The most recent inventory snapshot
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetLatestInventorySnapshot(accountID int) (*models.InventorySnapshot, error) {
+	return s.inventorySnapshots.GetLatestByAccountID(accountID)
+}
+
+// GetInventorySnapshotsByDateRange retrieves inventory snapshots within a specific date range.
+// This method provides a way to filter inventory snapshots by their date.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//   - startDate: The start date of the range
+//   - endDate: The end date of the range
+//
+// Returns:
+//   - []models.InventorySnapshot: List of inventory snapshots within the date range
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetInventorySnapshotsByDateRange(accountID int, startDate, endDate time.Time) ([]models.InventorySnapshot, error) {
+	return s.inventorySnapshots.GetByDateRange(accountID, startDate, endDate)
+}
+
+// UpdateInventorySnapshot updates an existing inventory snapshot's information.
+// This method allows modification of inventory snapshot details while
+// maintaining data integrity and business rules.
+//
+// Parameters:
+//   - snapshot: The updated inventory snapshot data
+//
+// Returns:
+//   - error: Any error that occurred during the update
+func (s *Service) UpdateInventorySnapshot(snapshot *models.InventorySnapshot) error {
+	return s.withTransaction(func(tx *Service) error {
+		if err := tx.inventorySnapshots.Update(snapshot); err != nil {
+			return err
+		}
+		return tx.RebuildInventoryLevels(snapshot.AccountID)
+	})
+}
+
+// DeleteInventorySnapshot deletes an inventory snapshot by its unique identifier.
+// This method enforces referential integrity by preventing deletion of
+// inventory snapshots that still have active inventory items.
+//
+// Parameters:
+//   - id: The unique identifier of the inventory snapshot to delete
+//
+// Returns:
+//   - error: Any error that occurred during deletion
+//
+// Business rules:
+//   - Cannot delete inventory snapshots with existing inventory items
+//   - Maintains referential integrity across the system
+func (s *Service) DeleteInventorySnapshot(id int) error {
+	snapshot, err := s.inventorySnapshots.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	return s.withTransaction(func(tx *Service) error {
+		if err := tx.inventorySnapshots.Delete(id); err != nil {
+			return err
+		}
+		return tx.RebuildInventoryLevels(snapshot.AccountID)
+	})
+}
+
+// GetInventoryVariance calculates the variance in inventory levels between two snapshots.
+// This method is useful for identifying inventory discrepancies and losses.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//   - startDate: The start date of the range for the first snapshot
+//   - endDate: The end date of the range for the first snapshot
+//
+// Returns:
+//   - map[int]float64: A map of inventory item IDs to their variance
+//   - error: Any error that occurred during calculation
+//
+// Business logic:
+//   - This will be implemented when we add Toast POS integration
+//   - For now, it returns an empty map as a placeholder
+func (s *Service) GetInventoryVariance(accountID int, startDate, endDate time.Time) (map[int]float64, error) {
+	// This will be implemented when we add Toast POS integration
+	// For now, return empty map
+	return make(map[int]float64), nil
+}
+
+// Access Control operations
+// These methods handle user permissions and access validation.
+// Users are the primary actors in the system and are associated with accounts.
+
+// ValidateAccountAccess checks if a user has access to a specific account.
+// This method ensures that users can only access resources within their own account.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account to check access for
+//   - userAccountID: The unique identifier of the user's account
+//
+// Returns:
+//   - error: Any error that occurred during validation
+//
+// Business rules:
+//   - Users can only access resources within their own account
+//   - Access is denied if the user's account does not match the requested account
+func (s *Service) ValidateAccountAccess(accountID int, userAccountID int) error {
+	if accountID != userAccountID {
+		return errors.New("access denied: user can only access their own account")
+	}
+	return nil
+}
+
+// ValidateOrganizationAccess checks if a user has access to a specific organization.
+// This method ensures that users can access resources within their organization
+// if they have appropriate permissions.
+//
+// Parameters:
+//   - organizationID: The unique identifier of the organization to check access for
+//   - userID: The unique identifier of the user
+//
+// Returns:
+//   - bool: True if access is granted, false otherwise
+//   - error: Any error that occurred during validation
+//
+// Business rules:
+//   - Users can access resources within their organization if they have appropriate permissions
+//   - Access is denied if the user's account does not belong to the organization
+func (s *Service) ValidateOrganizationAccess(organizationID int, userID int) (bool, error) {
+	// Get the user
+	user, err := s.users.GetByID(userID)
+	if err != nil {
+		return false, err
+	}
+
+	// Get the user's account
+	account, err := s.accounts.GetByID(user.AccountID)
+	if err != nil {
+		return false, err
+	}
+
+	// Check if the user's account belongs to the organization
+	if account.OrganizationID == nil || *account.OrganizationID != organizationID {
+		return false, errors.New("access denied: user does not belong to this organization")
+	}
+
+	return true, nil
+}
+
+// IsOrganizationAdmin checks if a user is an organization admin.
+// Organization admins have elevated permissions across all accounts in their organization.
+//
+// Parameters:
+//   - userID: The unique identifier of the user
+//
+// Returns:
+//   - bool: True if the user is an organization admin, false otherwise
+//   - error: Any error that occurred during retrieval
+func (s *Service) IsOrganizationAdmin(userID int) (bool, error) {
 	user, err := s.users.GetByID(userID)
 	if err != nil {
-		return false, err
+		return false, err
+	}
+
+	return user.Role == "org_admin", nil
+}
+
+// Invitation operations
+// These methods handle user invitation management.
+
+// GetPendingInvitationByEmail retrieves a pending invitation for a given email.
+// This method is used during user registration to validate that the user has been invited.
+//
+// Parameters:
+//   - email: The email address to check for pending invitations
+//
+// Returns:
+//   - *models.AccountInvitation: The pending invitation if found
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetPendingInvitationByEmail(email string) (*models.AccountInvitation, error) {
+	return s.accountInvitations.GetPendingByEmail(email)
+}
+
+// UpdateInvitation updates an existing invitation.
+// This method is used to mark invitations as accepted when users register.
+//
+// Parameters:
+//   - invitation: The invitation data to update
+//
+// Returns:
+//   - error: Any error that occurred during the update
+func (s *Service) UpdateInvitation(invitation *models.AccountInvitation) error {
+	return s.accountInvitations.Update(invitation)
+}
+
+// CreateInvitation creates a new invitation for a user to join an account.
+// This method is used by account admins to invite new users.
+//
+// Parameters:
+//   - invitation: The invitation data to create
+//
+// Returns:
+//   - error: Any error that occurred during creation
+func (s *Service) CreateInvitation(invitation *models.AccountInvitation) error {
+	// Set default expiration to 7 days from now
+	if invitation.ExpiresAt.IsZero() {
+		invitation.ExpiresAt = time.Now().AddDate(0, 0, 7)
+	}
+
+	// Set default status to pending
+	if invitation.Status == "" {
+		invitation.Status = "pending"
+	}
+
+	return s.accountInvitations.Create(invitation)
+}
+
+// GetInvitationsByAccount retrieves all invitations for a specific account.
+// This method is used by account admins to manage invitations.
+//
+// Parameters:
+//   - accountID: The account ID to get invitations for
+//
+// Returns:
+//   - []models.AccountInvitation: List of invitations for the account
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetInvitationsByAccount(accountID int) ([]models.AccountInvitation, error) {
+	return s.accountInvitations.GetByAccountID(accountID)
+}
+
+// DeleteInvitation deletes an invitation.
+// This method is used by account admins to revoke invitations.
+//
+// Parameters:
+//   - id: The invitation ID to delete
+//
+// Returns:
+//   - error: Any error that occurred during deletion
+func (s *Service) DeleteInvitation(id int) error {
+	return s.accountInvitations.Delete(id)
+}
+
+// Category operations
+// These methods handle item categorization and organization.
+// Categories help organize inventory items and menu items for better management.
+
+// CreateCategory creates a new category for the specified account.
+// Categories help organize inventory items and menu items for better management
+// and reporting capabilities.
+//
+// Parameters:
+//   - category: The category data to create
+//
+// Returns:
+//   - error: Any error that occurred during creation
+//
+// Business rules:
+//   - Category names must be unique within an account
+//   - Categories are created with default timestamps
+//   - Categories are created as active by default
+func (s *Service) CreateCategory(category *models.Category) error {
+	// Validate that the category name is not empty
+	if category.Name == "" {
+		return errors.New("category name is required")
+	}
+
+	// Check if a category with the same name already exists in the account
+	existingCategories, err := s.categories.GetByAccountID(category.AccountID)
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range existingCategories {
+		if existing.Name == category.Name && existing.ID != category.ID {
+			return errors.New("category name already exists in this account")
+		}
+	}
+
+	return s.categories.Create(category)
+}
+
+// GetCategory retrieves a category by its unique identifier.
+// This method provides access to category details for validation
+// and business logic operations.
+//
+// Parameters:
+//   - id: The unique identifier of the category to retrieve
+//
+// Returns:
+//   - *models.Category: The category data if found
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetCategory(id int) (*models.Category, error) {
+	return s.categories.GetByID(id)
+}
+
+// GetCategoriesByAccount retrieves all categories for a specific account.
+// This method returns all categories associated with the account,
+// including both active and inactive categories.
+//
+// Parameters:
+//   - accountID: The account ID to retrieve categories for
+//
+// Returns:
+//   - []models.Category: List of categories for the account
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetCategoriesByAccount(accountID int) ([]models.Category, error) {
+	return s.categories.GetByAccountID(accountID)
+}
+
+// ListCategories retrieves one page of an account's categories, active and inactive,
+// filtered by name and sorted as requested.
+//
+// Parameters:
+//   - accountID: The account ID to retrieve categories for
+//   - list: The page, sort order and name search to apply
+//
+// Returns:
+//   - []models.Category: The page of categories
+//   - Page: Pagination metadata for the page
+//   - error: Any error that occurred during retrieval
+func (s *Service) ListCategories(accountID int, list ListQuery) ([]models.Category, Page, error) {
+	return s.categories.List(accountID, list)
+}
+
+// GetActiveCategoriesByAccount retrieves only active categories for a specific account.
+// This method returns only categories that are currently active,
+// which is useful for UI dropdowns and active item categorization.
+//
+// Parameters:
+//   - accountID: The account ID to retrieve active categories for
+//
+// Returns:
+//   - []models.Category: List of active categories for the account
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetActiveCategoriesByAccount(accountID int) ([]models.Category, error) {
+	return s.categories.GetActiveByAccountID(accountID)
+}
+
+// UpdateCategory updates an existing category in the system.
+// This method allows modification of category details while maintaining
+// data integrity and business rules.
+//
+// Parameters:
+//   - category: The updated category data
+//
+// Returns:
+//   - error: Any error that occurred during update
+//
+// Business rules:
+//   - Category names must remain unique within an account
+//   - Updated timestamps are automatically set
+func (s *Service) UpdateCategory(category *models.Category) error {
+	// Validate that the category name is not empty
+	if category.Name == "" {
+		return errors.New("category name is required")
+	}
+
+	// Check if a category with the same name already exists in the account
+	existingCategories, err := s.categories.GetByAccountID(category.AccountID)
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range existingCategories {
+		if existing.Name == category.Name && existing.ID != category.ID {
+			return errors.New("category name already exists in this account")
+		}
+	}
+
+	return s.categories.Update(category)
+}
+
+// DeleteCategory removes a category from the system.
+// This method handles the deletion of categories while ensuring
+// that no orphaned references remain in the system.
+//
+// Parameters:
+//   - id: The unique identifier of the category to delete
+//
+// Returns:
+//   - error: Any error that occurred during deletion
+//
+// Business rules:
+//   - Categories cannot be deleted if they are still referenced by items or budgets
+//   - Soft deletion is preferred over hard deletion for data integrity
+func (s *Service) DeleteCategory(id int) error {
+	// Check if any inventory items are using this category
+	inventoryItems, err := s.inventoryItems.GetByAccountID(0) // We'll need to get all items to check
+	if err != nil {
+		return err
+	}
+
+	for _, item := range inventoryItems {
+		if item.CategoryID != nil && *item.CategoryID == id {
+			return errors.New("cannot delete category: it is still assigned to inventory items")
+		}
+	}
+
+	// Check if any menu items are using this category
+	menuItems, err := s.menuItems.GetByAccountID(0) // We'll need to get all items to check
+	if err != nil {
+		return err
+	}
+
+	for _, item := range menuItems {
+		if item.CategoryID != nil && *item.CategoryID == id {
+			return errors.New("cannot delete category: it is still assigned to menu items")
+		}
+	}
+
+	// Check if the category still has budgets
+	budgets, err := s.budgets.GetByCategoryID(id)
+	if err != nil {
+		return err
+	}
+	if len(budgets) > 0 {
+		return errors.New("cannot delete category: it still has budgets")
+	}
+
+	return s.categories.Delete(id)
+}
+
+// GetInventoryItemsByCategory retrieves all inventory items in a specific category.
+// This method provides filtered access to inventory items based on their
+// category assignment for better organization and reporting.
+//
+// Parameters:
+//   - accountID: The account ID to scope the search
+//   - categoryID: The category ID to filter by
+//
+// Returns:
+//   - []models.InventoryItem: List of inventory items in the category
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetInventoryItemsByCategory(accountID int, categoryID int) ([]models.InventoryItem, error) {
+	// Get all inventory items for the account
+	items, err := s.inventoryItems.GetByAccountID(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Filter items by category
+	var filteredItems []models.InventoryItem
+	for _, item := range items {
+		if item.CategoryID != nil && *item.CategoryID == categoryID {
+			filteredItems = append(filteredItems, item)
+		}
+	}
+
+	return filteredItems, nil
+}
+
+// GetMenuItemsByCategoryID retrieves all menu items in a specific category.
+// This method provides filtered access to menu items based on their
+// category assignment for better organization and reporting.
+//
+// Parameters:
+//   - accountID: The account ID to scope the search
+//   - categoryID: The category ID to filter by
+//
+// Returns:
+//   - []models.MenuItem: List of menu items in the category
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetMenuItemsByCategoryID(accountID int, categoryID int) ([]models.MenuItem, error) {
+	// Get all menu items for the account
+	items, err := s.menuItems.GetByAccountID(accountID)
+	if err != nil {
+		return nil, err
 	}
 
-	return user.Role == "org_admin", nil
+	// Filter items by category
+	var filteredItems []models.MenuItem
+	for _, item := range items {
+		if item.CategoryID != nil && *item.CategoryID == categoryID {
+			filteredItems = append(filteredItems, item)
+		}
+	}
+
+	return filteredItems, nil
 }
 
-// Invitation operations
-// These methods handle user invitation management.
+// Budget operations
+// These methods handle the weekly or monthly purchasing budgets of categories, and
+// compare them with what was spent on the category's items.
+
+// defaultBudgetThresholds are the percentages of a budget that raise alerts unless others are set
+var defaultBudgetThresholds = models.ThresholdList{80, 100}
+
+// BudgetStatus compares a budget with the spend of its category in one period.
+type BudgetStatus struct {
+	Budget            models.Budget `json:"budget"`
+	CategoryName      string        `json:"category_name"`
+	PeriodStart       time.Time     `json:"period_start"`
+	PeriodEnd         time.Time     `json:"period_end"` // Exclusive
+	Spend             float64       `json:"spend"`
+	Remaining         float64       `json:"remaining"` // Negative once the budget is exceeded
+	PercentUsed       float64       `json:"percent_used"`
+	CrossedThresholds []float64     `json:"crossed_thresholds"` // The alert thresholds the spend reached
+}
 
-// GetPendingInvitationByEmail retrieves a pending invitation for a given email.
-// This method is used during user registration to validate that the user has been invited.
+// CreateBudget creates a purchasing budget for a category.
 //
 // Parameters:
-//   - email: The email address to check for pending invitations
+//   - budget: The budget data to create
 //
 // Returns:
-//   - *models.AccountInvitation: The pending invitation if found
+//   - error: Any error that occurred during creation
+//
+// Business rules:
+//   - The category must belong to the budget's account
+//   - A category has at most one weekly and one monthly budget
+//   - Period must be "weekly" or "monthly" and the amount must be positive
+//   - Spend is measured from deliveries unless "orders" is chosen
+//   - Alert thresholds default to 80% and 100%; an empty list disables alerts
+func (s *Service) CreateBudget(budget *models.Budget) error {
+	if err := s.validateBudget(budget); err != nil {
+		return err
+	}
+	return s.budgets.Create(budget)
+}
+
+// GetBudget retrieves a budget by its unique identifier.
+//
+// Parameters:
+//   - id: The unique identifier of the budget to retrieve
+//
+// Returns:
+//   - *models.Budget: The budget data if found
 //   - error: Any error that occurred during retrieval
-func (s *Service) GetPendingInvitationByEmail(email string) (*models.AccountInvitation, error) {
-	return s.accountInvitations.GetPendingByEmail(email)
+func (s *Service) GetBudget(id int) (*models.Budget, error) {
+	return s.budgets.GetByID(id)
 }
 
-// UpdateInvitation updates an existing invitation.
-// This method is used to mark invitations as accepted when users register.
+// GetBudgetsByAccount retrieves all budgets for a specific account.
 //
 // Parameters:
-//   - invitation: The invitation data to update
+//   - accountID: The unique identifier of the account
+//
+// Returns:
+//   - []models.Budget: List of budgets belonging to the account
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetBudgetsByAccount(accountID int) ([]models.Budget, error) {
+	return s.budgets.GetByAccountID(accountID)
+}
+
+// UpdateBudget updates an existing budget.
+// Alerts already raised in the current period are kept, so lowering a threshold
+// below the spend raises a new alert while raising it does not repeat old ones.
+//
+// Parameters:
+//   - budget: The updated budget data
 //
 // Returns:
 //   - error: Any error that occurred during the update
-func (s *Service) UpdateInvitation(invitation *models.AccountInvitation) error {
-	return s.accountInvitations.Update(invitation)
+//
+// Business rules:
+//   - The same rules apply as when creating a budget
+func (s *Service) UpdateBudget(budget *models.Budget) error {
+	if err := s.validateBudget(budget); err != nil {
+		return err
+	}
+	return s.budgets.Update(budget)
 }
 
-// CreateInvitation creates a new invitation for a user to join an account.
-// This method is used by account admins to invite new users.
+// DeleteBudget deletes a budget together with its alerts.
 //
 // Parameters:
-//   - invitation: The invitation data to create
+//   - id: The unique identifier of the budget to delete
 //
 // Returns:
-//   - error: Any error that occurred during creation
-func (s *Service) CreateInvitation(invitation *models.AccountInvitation) error {
-	// Set default expiration to 7 days from now
-	if invitation.ExpiresAt.IsZero() {
-		invitation.ExpiresAt = time.Now().AddDate(0, 0, 7)
+//   - error: Any error that occurred during deletion
+func (s *Service) DeleteBudget(id int) error {
+	return s.withTransaction(func(tx *Service) error {
+		if err := tx.budgetAlerts.DeleteByBudgetID(id); err != nil {
+			return err
+		}
+		return tx.budgets.Delete(id)
+	})
+}
+
+// validateBudget checks a budget before it is saved, filling in its defaults.
+func (s *Service) validateBudget(budget *models.Budget) error {
+	category, err := s.categories.GetByID(budget.CategoryID)
+	if err != nil || category.AccountID != budget.AccountID {
+		return errors.New("invalid category ID")
+	}
+	if budget.Period != models.BudgetPeriodWeekly && budget.Period != models.BudgetPeriodMonthly {
+		return fmt.Errorf("invalid budget period: %s", budget.Period)
+	}
+	if budget.Amount <= 0 {
+		return errors.New("budget amount must be positive")
 	}
 
-	// Set default status to pending
-	if invitation.Status == "" {
-		invitation.Status = "pending"
+	if budget.SpendSource == "" {
+		budget.SpendSource = models.BudgetSpendDeliveries
+	}
+	if budget.SpendSource != models.BudgetSpendDeliveries && budget.SpendSource != models.BudgetSpendOrders {
+		return fmt.Errorf("invalid budget spend source: %s", budget.SpendSource)
 	}
 
-	return s.accountInvitations.Create(invitation)
+	if budget.AlertThresholds == nil {
+		budget.AlertThresholds = append(models.ThresholdList{}, defaultBudgetThresholds...)
+	}
+	thresholds := models.ThresholdList{}
+	for _, threshold := range budget.AlertThresholds {
+		if threshold <= 0 {
+			return errors.New("budget alert thresholds must be positive")
+		}
+		if !containsThreshold(thresholds, threshold) {
+			thresholds = append(thresholds, threshold)
+		}
+	}
+	sort.Float64s(thresholds)
+	budget.AlertThresholds = thresholds
+
+	budgets, err := s.budgets.GetByCategoryID(budget.CategoryID)
+	if err != nil {
+		return err
+	}
+	for _, other := range budgets {
+		if other.ID != budget.ID && other.Period == budget.Period {
+			return fmt.Errorf("category already has a %s budget", budget.Period)
+		}
+	}
+	return nil
 }
 
-// GetInvitationsByAccount retrieves all invitations for a specific account.
-// This method is used by account admins to manage invitations.
+// containsThreshold checks if a threshold is in a list of thresholds.
+func containsThreshold(thresholds models.ThresholdList, threshold float64) bool {
+	for _, existing := range thresholds {
+		if existing == threshold {
+			return true
+		}
+	}
+	return false
+}
+
+// budgetPeriod returns the start and exclusive end of the week or month containing a date.
+// Weeks start on Monday.
+func budgetPeriod(period string, date time.Time) (time.Time, time.Time) {
+	if period == models.BudgetPeriodWeekly {
+		day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+		start := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return start, start.AddDate(0, 0, 7)
+	}
+	start := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	return start, start.AddDate(0, 1, 0)
+}
+
+// GetBudgetReport compares each budget of an account with the spend of its category
+// in the week or month containing a date.
 //
 // Parameters:
-//   - accountID: The account ID to get invitations for
+//   - accountID: The unique identifier of the account
+//   - date: A date in the periods to report on
 //
 // Returns:
-//   - []models.AccountInvitation: List of invitations for the account
+//   - []BudgetStatus: Budget against actual spend for each budget, ordered by category name and period
 //   - error: Any error that occurred during retrieval
-func (s *Service) GetInvitationsByAccount(accountID int) ([]models.AccountInvitation, error) {
-	return s.accountInvitations.GetByAccountID(accountID)
+//
+// Business rules:
+//   - Delivery spend is the cost of the category's items delivered during the period
+//   - Order spend is the cost of the category's items on orders placed during the period, except cancelled orders
+func (s *Service) GetBudgetReport(accountID int, date time.Time) ([]BudgetStatus, error) {
+	budgets, err := s.budgets.GetByAccountID(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	spendByPeriod := make(map[string]map[int]float64) // Category spend keyed by spend source and period
+	statuses := make([]BudgetStatus, 0, len(budgets))
+	for _, budget := range budgets {
+		start, end := budgetPeriod(budget.Period, date)
+		key := budget.SpendSource + "/" + budget.Period
+		spend, ok := spendByPeriod[key]
+		if !ok {
+			if spend, err = s.categorySpend(accountID, budget.SpendSource, start, end); err != nil {
+				return nil, err
+			}
+			spendByPeriod[key] = spend
+		}
+
+		status := BudgetStatus{
+			Budget:            budget,
+			PeriodStart:       start,
+			PeriodEnd:         end,
+			Spend:             spend[budget.CategoryID],
+			CrossedThresholds: []float64{},
+		}
+		if category, err := s.categories.GetByID(budget.CategoryID); err == nil {
+			status.CategoryName = category.Name
+		}
+		status.Remaining = budget.Amount - status.Spend
+		status.PercentUsed = status.Spend / budget.Amount * 100
+		for _, threshold := range budget.AlertThresholds {
+			if status.PercentUsed >= threshold {
+				status.CrossedThresholds = append(status.CrossedThresholds, threshold)
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].CategoryName != statuses[j].CategoryName {
+			return statuses[i].CategoryName < statuses[j].CategoryName
+		}
+		return statuses[i].Budget.Period > statuses[j].Budget.Period // Weekly before monthly
+	})
+	return statuses, nil
 }
 
-// DeleteInvitation deletes an invitation.
-// This method is used by account admins to revoke invitations.
+// categorySpend sums the spend of an account on the items of each category during a period.
+func (s *Service) categorySpend(accountID int, source string, start, end time.Time) (map[int]float64, error) {
+	items, err := s.inventoryItems.GetByAccountID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	categoryOf := make(map[int]int, len(items))
+	for _, item := range items {
+		if item.CategoryID != nil {
+			categoryOf[item.ID] = *item.CategoryID
+		}
+	}
+
+	spend := make(map[int]float64)
+	if source == models.BudgetSpendOrders {
+		orders, err := s.orders.GetByAccountID(accountID)
+		if err != nil {
+			return nil, err
+		}
+		for _, order := range orders {
+			if order.Status == "cancelled" || order.OrderDate.Before(start) || !order.OrderDate.Before(end) {
+				continue
+			}
+			orderItems, err := s.orderItems.GetByOrderID(order.ID)
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range orderItems {
+				if categoryID, ok := categoryOf[item.InventoryItemID]; ok {
+					spend[categoryID] += item.TotalCost
+				}
+			}
+		}
+		return spend, nil
+	}
+
+	deliveries, err := s.deliveries.GetByDateRange(accountID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	for _, delivery := range deliveries {
+		if !delivery.DeliveryDate.Before(end) {
+			continue
+		}
+		if categoryID, ok := categoryOf[delivery.InventoryItemID]; ok {
+			spend[categoryID] += delivery.Cost
+		}
+	}
+	return spend, nil
+}
+
+// CheckBudgetAlerts raises an alert for each budget threshold the spend of an account
+// has crossed in the current period and that was not alerted on yet.
 //
 // Parameters:
-//   - id: The invitation ID to delete
+//   - accountID: The unique identifier of the account
+//   - now: The time to check at, which selects the current periods
 //
 // Returns:
-//   - error: Any error that occurred during deletion
-func (s *Service) DeleteInvitation(id int) error {
-	return s.accountInvitations.Delete(id)
+//   - []models.BudgetAlert: The alerts raised by this check
+//   - error: Any error that occurred during the check
+func (s *Service) CheckBudgetAlerts(accountID int, now time.Time) ([]models.BudgetAlert, error) {
+	statuses, err := s.GetBudgetReport(accountID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var raised []models.BudgetAlert
+	for _, status := range statuses {
+		if len(status.CrossedThresholds) == 0 {
+			continue
+		}
+		existing, err := s.budgetAlerts.GetByBudgetPeriod(status.Budget.ID, status.PeriodStart)
+		if err != nil {
+			return nil, err
+		}
+		alerted := make(models.ThresholdList, 0, len(existing))
+		for _, alert := range existing {
+			alerted = append(alerted, alert.Threshold)
+		}
+
+		for _, threshold := range status.CrossedThresholds {
+			if containsThreshold(alerted, threshold) {
+				continue
+			}
+			alert := models.BudgetAlert{
+				AccountID:   accountID,
+				BudgetID:    status.Budget.ID,
+				PeriodStart: status.PeriodStart,
+				Threshold:   threshold,
+				Spend:       status.Spend,
+				Amount:      status.Budget.Amount,
+			}
+			if err := s.budgetAlerts.Create(&alert); err != nil {
+				return nil, err
+			}
+			raised = append(raised, alert)
+		}
+	}
+	return raised, nil
 }
 
-// Category operations
-// These methods handle item categorization and organization.
-// Categories help organize inventory items and menu items for better management.
+// GetUnnotifiedBudgetAlerts retrieves the budget alerts that have not been emailed yet.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//
+// Returns:
+//   - []models.BudgetAlert: List of pending budget alerts, oldest first
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetUnnotifiedBudgetAlerts(accountID int) ([]models.BudgetAlert, error) {
+	return s.budgetAlerts.GetUnnotifiedByAccountID(accountID)
+}
 
-// CreateCategory creates a new category for the specified account.
-// Categories help organize inventory items and menu items for better management
-// and reporting capabilities.
+// MarkBudgetAlertsNotified records that budget alerts have been emailed.
 //
 // Parameters:
-//   - category: The category data to create
+//   - ids: The unique identifiers of the alerts
+//   - notifiedAt: When the alerts were sent
 //
 // Returns:
-//   - error: Any error that occurred during creation
+//   - error: Any error that occurred during the update
+func (s *Service) MarkBudgetAlertsNotified(ids []int, notifiedAt time.Time) error {
+	return s.budgetAlerts.MarkNotified(ids, notifiedAt)
+}
+
+// Inventory import operations
+// These methods create and update inventory items in bulk from spreadsheet rows,
+// typically when onboarding a new store.
+
+// InventoryImportFields are the inventory item fields an import can set. The category
+// is given by name, and the name column identifies the item to create or update.
+var InventoryImportFields = []string{
+	"name", "unit", "cost_per_unit", "preferred_vendor", "category", "min_stock_level",
+	"max_stock_level", "min_weeks_stock", "max_weeks_stock", "wastage_rate", "shelf_life_days",
+}
+
+// Inventory import row action constants
+const (
+	InventoryImportCreate = "create"
+	InventoryImportUpdate = "update"
+)
+
+// InventoryImportReport describes the outcome of an inventory import
+type InventoryImportReport struct {
+	DryRun    bool                   `json:"dry_run"`
+	Committed bool                   `json:"committed"` // False for dry runs and imports with errors, which change nothing
+	Created   int                    `json:"created"`
+	Updated   int                    `json:"updated"`
+	Rows      []InventoryImportRow   `json:"rows"`
+	Errors    []InventoryImportError `json:"errors"`
+	Columns   map[string]string      `json:"columns"` // The column each field was read from
+}
+
+// InventoryImportRow is the action taken, or that would be taken, for a valid row
+type InventoryImportRow struct {
+	Row             int    `json:"row"` // Line number in the file, counting the header as line 1
+	Name            string `json:"name"`
+	Action          string `json:"action"` // "create" or "update"
+	InventoryItemID int    `json:"inventory_item_id,omitempty"`
+}
+
+// InventoryImportError is a validation error on a row of an import
+type InventoryImportError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// errImportRolledBack ends the import transaction without committing it
+var errImportRolledBack = errors.New("inventory import rolled back")
+
+// ImportInventoryItems creates or updates inventory items of an account from spreadsheet
+// rows, matching existing items by name. Every row is validated with the same rules as
+// CreateInventoryItem and UpdateInventoryItem, and all rows are written in one transaction:
+// the import is committed only when no row has an error and it is not a dry run.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//   - records: The rows of the file, starting with a header row of column names
+//   - mapping: The column to read each field from, by field name; fields not mapped are read
+//     from a column of the same name, ignoring case, spaces and dashes, when there is one
+//   - dryRun: Validate and report without changing anything
+//
+// Returns:
+//   - *InventoryImportReport: The action and errors for each row
+//   - error: An invalid mapping or header, or a database error
 //
 // Business rules:
-//   - Category names must be unique within an account
-//   - Categories are created with default timestamps
-//   - Categories are created as active by default
-func (s *Service) CreateCategory(category *models.Category) error {
-	// Validate that the category name is not empty
-	if category.Name == "" {
-		return errors.New("category name is required")
+//   - The name column is required; the unit is required for new items
+//   - Cells left empty keep the existing item's value
+//   - Categories are matched by name and must exist in the account
+//   - A name may appear only once in a file
+func (s *Service) ImportInventoryItems(accountID int, records [][]string, mapping map[string]string, dryRun bool) (*InventoryImportReport, error) {
+	if _, err := s.accounts.GetByID(accountID); err != nil {
+		return nil, errors.New("invalid account ID")
+	}
+	if len(records) == 0 {
+		return nil, errors.New("the file is empty")
+	}
+
+	columns, fieldColumns, err := inventoryImportColumns(records[0], mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.categories.GetByAccountID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	categoryIDs := make(map[string]int, len(categories))
+	for _, category := range categories {
+		categoryIDs[strings.ToLower(strings.TrimSpace(category.Name))] = category.ID
 	}
 
-	// Check if a category with the same name already exists in the account
-	existingCategories, err := s.categories.GetByAccountID(category.AccountID)
-	if err != nil {
-		return err
+	report := &InventoryImportReport{DryRun: dryRun, Rows: []InventoryImportRow{}, Errors: []InventoryImportError{}, Columns: fieldColumns}
+	err = s.withTransaction(func(tx *Service) error {
+		existingItems, err := tx.inventoryItems.GetByAccountID(accountID)
+		if err != nil {
+			return err
+		}
+		itemsByName := make(map[string]models.InventoryItem, len(existingItems))
+		for _, item := range existingItems {
+			itemsByName[item.Name] = item
+		}
+		firstRows := make(map[string]int)
+
+		for i, record := range records[1:] {
+			rowNumber := i + 2
+			cells := make(map[string]string, len(columns))
+			blank := true
+			for field, column := range columns {
+				if column < len(record) {
+					cells[field] = strings.TrimSpace(record[column])
+					blank = blank && cells[field] == ""
+				}
+			}
+			if blank {
+				continue
+			}
+			rowError := func(field, message string) {
+				report.Errors = append(report.Errors, InventoryImportError{Row: rowNumber, Field: field, Message: message})
+			}
+
+			name := cells["name"]
+			if name == "" {
+				rowError("name", "item name is required")
+				continue
+			}
+			if first, ok := firstRows[name]; ok {
+				rowError("name", fmt.Sprintf("item %q already appears on row %d", name, first))
+				continue
+			}
+			firstRows[name] = rowNumber
+
+			item, exists := itemsByName[name]
+			if !exists {
+				item = models.InventoryItem{AccountID: accountID, Name: name}
+			}
+			if !applyInventoryImportCells(&item, cells, categoryIDs, rowError) {
+				continue
+			}
+
+			row := InventoryImportRow{Row: rowNumber, Name: name, Action: InventoryImportCreate}
+			if exists {
+				row.Action = InventoryImportUpdate
+				err = tx.UpdateInventoryItem(&item)
+			} else {
+				err = tx.CreateInventoryItem(&item)
+			}
+			if err != nil {
+				rowError("", err.Error())
+				continue
+			}
+			row.InventoryItemID = item.ID
+			report.Rows = append(report.Rows, row)
+		}
+
+		if dryRun || len(report.Errors) > 0 {
+			return errImportRolledBack
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportRolledBack) {
+		return nil, err
+	}
+
+	report.Committed = err == nil
+	for i, row := range report.Rows {
+		if row.Action == InventoryImportCreate {
+			report.Created++
+			if !report.Committed {
+				report.Rows[i].InventoryItemID = 0 // the item was never saved
+			}
+		} else {
+			report.Updated++
+		}
+	}
+	return report, nil
+}
+
+// inventoryImportColumns resolves the column index of each mapped field from the header row.
+// It returns the indexes by field and, for the report, the header each field was read from.
+func inventoryImportColumns(header []string, mapping map[string]string) (map[string]int, map[string]string, error) {
+	normalize := func(name string) string {
+		name = strings.ToLower(strings.TrimSpace(name))
+		return strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+	}
+	headerIndex := make(map[string]int, len(header))
+	for i, name := range header {
+		if key := normalize(name); key != "" {
+			if _, duplicate := headerIndex[key]; !duplicate {
+				headerIndex[key] = i
+			}
+		}
 	}
 
-	for _, existing := range existingCategories {
-		if existing.Name == category.Name && existing.ID != category.ID {
-			return errors.New("category name already exists in this account")
+	columns := make(map[string]int)
+	fieldColumns := make(map[string]string)
+	for field, column := range mapping {
+		if !slices.Contains(InventoryImportFields, field) {
+			return nil, nil, fmt.Errorf("unknown field %q in mapping; fields are: %s", field, strings.Join(InventoryImportFields, ", "))
+		}
+		index, ok := headerIndex[normalize(column)]
+		if !ok {
+			return nil, nil, fmt.Errorf("column %q mapped to %s is not in the file", column, field)
+		}
+		columns[field] = index
+		fieldColumns[field] = header[index]
+	}
+	for _, field := range InventoryImportFields {
+		if _, mapped := mapping[field]; mapped {
+			continue
+		}
+		if index, ok := headerIndex[field]; ok {
+			columns[field] = index
+			fieldColumns[field] = header[index]
 		}
 	}
 
-	return s.categories.Create(category)
+	if _, ok := columns["name"]; !ok {
+		return nil, nil, errors.New("the file has no name column; map one with the name field")
+	}
+	return columns, fieldColumns, nil
 }
 
-// GetCategory retrieves a category by its unique identifier.
-// This method provides access to category details for validation
-// and business logic operations.
-//
-// Parameters:
-//   - id: The unique identifier of the category to retrieve
-//
-// Returns:
-//   - *models.Category: The category data if found
-//   - error: Any error that occurred during retrieval
-func (s *Service) GetCategory(id int) (*models.Category, error) {
-	return s.categories.GetByID(id)
+// applyInventoryImportCells copies the non-empty cells of an import row onto an item,
+// reporting each invalid cell through rowError. It returns false if any cell was invalid.
+func applyInventoryImportCells(item *models.InventoryItem, cells map[string]string, categoryIDs map[string]int, rowError func(field, message string)) bool {
+	valid := true
+	number := func(field string, target *float64) {
+		if cells[field] == "" {
+			return
+		}
+		value, err := strconv.ParseFloat(cells[field], 64)
+		if err != nil || value < 0 {
+			rowError(field, fmt.Sprintf("%s must be a non-negative number", field))
+			valid = false
+			return
+		}
+		*target = value
+	}
+
+	if cells["unit"] != "" {
+		item.Unit = cells["unit"]
+	}
+	if cells["preferred_vendor"] != "" {
+		item.PreferredVendor = cells["preferred_vendor"]
+		item.VendorID = nil // relinked by name when the item is saved
+	}
+	number("cost_per_unit", &item.CostPerUnit)
+	number("min_stock_level", &item.MinStockLevel)
+	number("max_stock_level", &item.MaxStockLevel)
+	number("min_weeks_stock", &item.MinWeeksStock)
+	number("max_weeks_stock", &item.MaxWeeksStock)
+	number("wastage_rate", &item.WastageRate)
+
+	if cells["shelf_life_days"] != "" {
+		days, err := strconv.Atoi(cells["shelf_life_days"])
+		if err != nil || days < 0 {
+			rowError("shelf_life_days", "shelf_life_days must be a non-negative whole number")
+			valid = false
+		} else {
+			item.ShelfLifeDays = days
+		}
+	}
+
+	if name := cells["category"]; name != "" {
+		categoryID, ok := categoryIDs[strings.ToLower(name)]
+		if !ok {
+			rowError("category", fmt.Sprintf("category %q does not exist in this account", name))
+			valid = false
+		} else {
+			item.CategoryID = &categoryID
+		}
+	}
+	return valid
 }
 
-// GetCategoriesByAccount retrieves all categories for a specific account.
-// This method returns all categories associated with the account,
-// including both active and inactive categories.
-//
-// Parameters:
-//   - accountID: The account ID to retrieve categories for
-//
-// Returns:
-//   - []models.Category: List of categories for the account
-//   - error: Any error that occurred during retrieval
-func (s *Service) GetCategoriesByAccount(accountID int) ([]models.Category, error) {
-	return s.categories.GetByAccountID(accountID)
+// Export operations
+// These methods stream an account's records as rows for spreadsheet exports, such as
+// the files an accountant needs for bookkeeping.
+
+// Export dataset constants
+const (
+	ExportDeliveries = "deliveries"
+	ExportSnapshots  = "snapshots"
+	ExportValuation  = "valuation"
+	ExportSales      = "sales"
+)
+
+// ExportDatasets lists the datasets that can be exported
+var ExportDatasets = []string{ExportDeliveries, ExportSnapshots, ExportValuation, ExportSales}
+
+// ErrUnknownExportDataset is returned when an export names a dataset that does not exist
+var ErrUnknownExportDataset = errors.New("unknown export dataset")
+
+// exportColumns holds the header of each dataset; rows hold their cells in the same order
+var exportColumns = map[string][]string{
+	ExportDeliveries: {"delivery_id", "delivery_date", "inventory_item_id", "item_name", "unit", "vendor", "quantity", "cost", "lot_number", "expiration_date", "notes"},
+	ExportSnapshots:  {"snapshot_id", "timestamp", "inventory_item_id", "item_name", "unit", "quantity"},
+	ExportValuation:  {"inventory_item_id", "item_name", "category", "unit", "quantity", "cost_per_unit", "value"},
+	ExportSales:      {"sale_id", "sale_date", "menu_item_id", "menu_item_name", "quantity", "price_at_sale", "cost_at_sale", "revenue", "cost", "profit", "notes"},
 }
 
-// GetActiveCategoriesByAccount retrieves only active categories for a specific account.
-// This method returns only categories that are currently active,
-// which is useful for UI dropdowns and active item categorization.
-//
-// Parameters:
-//   - accountID: The account ID to retrieve active categories for
-//
-// Returns:
-//   - []models.Category: List of active categories for the account
-//   - error: Any error that occurred during retrieval
-func (s *Service) GetActiveCategoriesByAccount(accountID int) ([]models.Category, error) {
-	return s.categories.GetActiveByAccountID(accountID)
+// ExportColumns returns the column names of a dataset's rows.
+func ExportColumns(dataset string) ([]string, error) {
+	columns, ok := exportColumns[dataset]
+	if !ok {
+		return nil, fmt.Errorf("%w %q: datasets are %s", ErrUnknownExportDataset, dataset, strings.Join(ExportDatasets, ", "))
+	}
+	return columns, nil
 }
 
-// UpdateCategory updates an existing category in the system.
-// This method allows modification of category details while maintaining
-// data integrity and business rules.
+// Export streams the rows of a dataset to write, oldest first, loading the records a batch
+// at a time so that exports of any size use little memory. Rows hold one cell per column of
+// ExportColumns: strings, ints, float64s, time.Times, or nil for an empty cell.
 //
 // Parameters:
-//   - category: The updated category data
+//   - accountID: The unique identifier of the account
+//   - dataset: One of ExportDatasets
+//   - startDate: Only records dated on or after this time; zero for no bound
+//   - endDate: Only records dated on or before this time; zero for no bound
+//   - write: Called with each row; an error stops the export and is returned
 //
 // Returns:
-//   - error: Any error that occurred during update
+//   - error: An unknown dataset, a database error, or an error from write
 //
 // Business rules:
-//   - Category names must remain unique within an account
-//   - Updated timestamps are automatically set
-func (s *Service) UpdateCategory(category *models.Category) error {
-	// Validate that the category name is not empty
-	if category.Name == "" {
-		return errors.New("category name is required")
+//   - Snapshots produce a row per counted item, ordered by item name within a snapshot
+//   - Sales produce a row per item sold; sales without items produce a single row of totals
+//   - The valuation is of current stock at each item's cost per unit, so the date range does not apply
+func (s *Service) Export(accountID int, dataset string, startDate, endDate time.Time, write func(row []interface{}) error) error {
+	if _, err := ExportColumns(dataset); err != nil {
+		return err
 	}
 
-	// Check if a category with the same name already exists in the account
-	existingCategories, err := s.categories.GetByAccountID(category.AccountID)
+	items, err := s.inventoryItems.GetByAccountID(accountID)
 	if err != nil {
 		return err
 	}
+	itemsByID := make(map[int]models.InventoryItem, len(items))
+	for _, item := range items {
+		itemsByID[item.ID] = item
+	}
+	// itemCells returns the name and unit cells of an item, which are empty if it was deleted
+	itemCells := func(itemID int) (interface{}, interface{}) {
+		item, ok := itemsByID[itemID]
+		if !ok {
+			return nil, nil
+		}
+		return item.Name, item.Unit
+	}
 
-	for _, existing := range existingCategories {
-		if existing.Name == category.Name && existing.ID != category.ID {
-			return errors.New("category name already exists in this account")
+	switch dataset {
+	case ExportDeliveries:
+		return s.deliveries.EachBatch(accountID, startDate, endDate, func(deliveries []models.Delivery) error {
+			for _, delivery := range deliveries {
+				name, unit := itemCells(delivery.InventoryItemID)
+				var expiration interface{}
+				if delivery.ExpirationDate != nil {
+					expiration = *delivery.ExpirationDate
+				}
+				row := []interface{}{delivery.ID, delivery.DeliveryDate, delivery.InventoryItemID, name, unit, delivery.Vendor,
+					delivery.Quantity, delivery.Cost, delivery.LotNumber, expiration, delivery.Notes}
+				if err := write(row); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+	case ExportSnapshots:
+		return s.inventorySnapshots.EachBatch(accountID, startDate, endDate, func(snapshots []models.InventorySnapshot) error {
+			for _, snapshot := range snapshots {
+				itemIDs := make([]int, 0, len(snapshot.Counts))
+				for itemID := range snapshot.Counts {
+					itemIDs = append(itemIDs, itemID)
+				}
+				sort.Slice(itemIDs, func(i, j int) bool {
+					a, b := itemsByID[itemIDs[i]].Name, itemsByID[itemIDs[j]].Name
+					if a != b {
+						return a < b
+					}
+					return itemIDs[i] < itemIDs[j]
+				})
+
+				for _, itemID := range itemIDs {
+					name, unit := itemCells(itemID)
+					if err := write([]interface{}{snapshot.ID, snapshot.Timestamp, itemID, name, unit, snapshot.Counts[itemID]}); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+
+	case ExportValuation:
+		stock, err := s.GetInventoryItemsWithCurrentStock(accountID)
+		if err != nil {
+			return err
 		}
+		categories, err := s.categories.GetByAccountID(accountID)
+		if err != nil {
+			return err
+		}
+		categoryNames := make(map[int]string, len(categories))
+		for _, category := range categories {
+			categoryNames[category.ID] = category.Name
+		}
+		sort.Slice(stock, func(i, j int) bool { return stock[i].Name < stock[j].Name })
+
+		for _, item := range stock {
+			var category interface{}
+			if item.CategoryID != nil {
+				category = categoryNames[*item.CategoryID]
+			}
+			value := math.Round(item.CurrentStock*item.CostPerUnit*100) / 100
+			if err := write([]interface{}{item.ID, item.Name, category, item.Unit, item.CurrentStock, item.CostPerUnit, value}); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default: // ExportSales
+		menuItems, err := s.menuItems.GetByAccountID(accountID)
+		if err != nil {
+			return err
+		}
+		menuItemNames := make(map[uint]string, len(menuItems))
+		for _, menuItem := range menuItems {
+			menuItemNames[uint(menuItem.ID)] = menuItem.Name
+		}
+
+		return s.sales.EachBatch(accountID, startDate, endDate, func(sales []models.Sale) error {
+			for _, sale := range sales {
+				if len(sale.Items) == 0 {
+					row := []interface{}{sale.ID, sale.SaleDate, nil, nil, nil, nil, nil, sale.TotalRevenue, sale.TotalCost, sale.TotalProfit, sale.Notes}
+					if err := write(row); err != nil {
+						return err
+					}
+					continue
+				}
+				for _, item := range sale.Items {
+					revenue := float64(item.Quantity) * item.PriceAtSale
+					cost := float64(item.Quantity) * item.CostAtSale
+					row := []interface{}{sale.ID, sale.SaleDate, item.MenuItemID, menuItemNames[item.MenuItemID], item.Quantity,
+						item.PriceAtSale, item.CostAtSale, revenue, cost, revenue - cost, sale.Notes}
+					if err := write(row); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
 	}
+}
 
-	return s.categories.Update(category)
+// Search operations
+// These methods find records of an account by the text they contain, across inventory
+// items, menu items, categories, vendors, and the notes on orders and deliveries.
+
+// Search result type constants
+const (
+	SearchResultInventoryItem = "inventory_item"
+	SearchResultMenuItem      = "menu_item"
+	SearchResultCategory      = "category"
+	SearchResultVendor        = "vendor"
+	SearchResultOrder         = "order"
+	SearchResultDelivery      = "delivery"
+)
+
+// Search result limits
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 50
+)
+
+// ErrInvalidSearchQuery is returned by Search for a query without any letters or digits
+var ErrInvalidSearchQuery = errors.New("search query must contain at least one letter or digit")
+
+// SearchResult is a single record matching a search
+type SearchResult struct {
+	Type    string  `json:"type"` // One of the SearchResult constants
+	ID      int     `json:"id"`
+	Title   string  `json:"title"`
+	Snippet string  `json:"snippet,omitempty"` // The matching text when it is not the title, such as notes
+	Score   float64 `json:"score"`             // Relevance from 0 to 1, highest first
 }
 
-// DeleteCategory removes a category from the system.
-// This method handles the deletion of categories while ensuring
-// that no orphaned references remain in the system.
+// searchField is a piece of text of a record considered when ranking it
+type searchField struct {
+	text   string
+	weight float64
+}
+
+// Search finds the records of an account matching a query and ranks them by relevance.
+// Every word of the query must appear in a record. Names that equal or start with the
+// query rank highest, and matches in secondary text such as vendors or notes rank lower.
 //
 // Parameters:
-//   - id: The unique identifier of the category to delete
+//   - accountID: The unique identifier of the account to search
+//   - query: The text to search for
+//   - limit: Maximum number of results; DefaultSearchLimit when not positive, at most MaxSearchLimit
 //
 // Returns:
-//   - error: Any error that occurred during deletion
-//
-// Business rules:
-//   - Categories cannot be deleted if they are still referenced by items
-//   - Soft deletion is preferred over hard deletion for data integrity
-func (s *Service) DeleteCategory(id int) error {
-	// Check if any inventory items are using this category
-	inventoryItems, err := s.inventoryItems.GetByAccountID(0) // We'll need to get all items to check
+//   - []SearchResult: Matching records, most relevant first
+//   - error: Any error that occurred during validation or the search
+func (s *Service) Search(accountID int, query string, limit int) ([]SearchResult, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, ErrInvalidSearchQuery
+	}
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+
+	candidates, err := s.search.Search(accountID, terms, limit)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	for _, item := range inventoryItems {
-		if item.CategoryID != nil && *item.CategoryID == id {
-			return errors.New("cannot delete category: it is still assigned to inventory items")
+	results := []SearchResult{}
+	add := func(resultType string, id int, title string, fields ...searchField) {
+		score, best := searchScore(fields, terms)
+		if score <= 0 {
+			return
 		}
+		result := SearchResult{Type: resultType, ID: id, Title: title, Score: score}
+		if best > 0 || fields[best].text != title {
+			result.Snippet = fields[best].text
+		}
+		results = append(results, result)
 	}
 
-	// Check if any menu items are using this category
-	menuItems, err := s.menuItems.GetByAccountID(0) // We'll need to get all items to check
-	if err != nil {
-		return err
+	for _, item := range candidates.InventoryItems {
+		add(SearchResultInventoryItem, item.ID, item.Name, searchField{item.Name, 1}, searchField{item.PreferredVendor, 0.5})
+	}
+	for _, item := range candidates.MenuItems {
+		add(SearchResultMenuItem, item.ID, item.Name, searchField{item.Name, 1}, searchField{item.Category, 0.5})
+	}
+	for _, category := range candidates.Categories {
+		add(SearchResultCategory, category.ID, category.Name, searchField{category.Name, 1}, searchField{category.Description, 0.5})
+	}
+	for _, vendor := range candidates.Vendors {
+		add(SearchResultVendor, vendor.ID, vendor.Name, searchField{vendor.Name, 1}, searchField{vendor.ContactName, 0.5}, searchField{vendor.Notes, 0.5})
+	}
+	for _, order := range candidates.Orders {
+		add(SearchResultOrder, order.ID, fmt.Sprintf("Order #%d", order.ID), searchField{order.Notes, 0.5})
+	}
+	for _, delivery := range candidates.Deliveries {
+		title := fmt.Sprintf("%s delivery on %s", delivery.Vendor, delivery.DeliveryDate.Format("2006-01-02"))
+		add(SearchResultDelivery, delivery.ID, title, searchField{delivery.Vendor, 0.5}, searchField{delivery.LotNumber, 0.5}, searchField{delivery.Notes, 0.5})
 	}
 
-	for _, item := range menuItems {
-		if item.CategoryID != nil && *item.CategoryID == id {
-			return errors.New("cannot delete category: it is still assigned to menu items")
+	sort.SliceStable(results, func(a, b int) bool {
+		if results[a].Score != results[b].Score {
+			return results[a].Score > results[b].Score
 		}
+		return results[a].Title < results[b].Title
+	})
+	if len(results) > limit {
+		results = results[:limit]
 	}
+	return results, nil
+}
 
-	return s.categories.Delete(id)
+// isSearchSeparator reports whether r separates the words of a search
+func isSearchSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
 }
 
-// GetInventoryItemsByCategory retrieves all inventory items in a specific category.
-// This method provides filtered access to inventory items based on their
-// category assignment for better organization and reporting.
-//
-// Parameters:
-//   - accountID: The account ID to scope the search
-//   - categoryID: The category ID to filter by
-//
-// Returns:
-//   - []models.InventoryItem: List of inventory items in the category
-//   - error: Any error that occurred during retrieval
-func (s *Service) GetInventoryItemsByCategory(accountID int, categoryID int) ([]models.InventoryItem, error) {
-	// Get all inventory items for the account
-	items, err := s.inventoryItems.GetByAccountID(accountID)
-	if err != nil {
-		return nil, err
-	}
+// searchTerms splits a search query into lower-case words of letters and digits
+func searchTerms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), isSearchSeparator)
+}
 
-	// Filter items by category
-	var filteredItems []models.InventoryItem
-	for _, item := range items {
-		if item.CategoryID != nil && *item.CategoryID == categoryID {
-			filteredItems = append(filteredItems, item)
+// searchScore rates how well the fields of a record match the search terms, from 0 when a
+// term appears in none of them to 1 when a field of full weight is exactly the query, and
+// returns the index of the field that matched best. A field starting with the query scores
+// 0.8 of its weight. Otherwise each term is rated by the best field it appears in, at that
+// field's weight, and the terms' average is scaled to at most 0.6. Every record the
+// repository matched has each term in one of its fields, so every one of them is scored.
+func searchScore(fields []searchField, terms []string) (float64, int) {
+	query := strings.Join(terms, " ")
+	phraseScore, phraseField := 0.0, -1
+	termScores := make([]float64, len(terms))   // The best score of each term across the fields
+	fieldScores := make([]float64, len(fields)) // The total score of the terms in each field
+	for i, field := range fields {
+		words := searchTerms(field.text)
+		if len(words) == 0 {
+			continue
 		}
-	}
 
-	return filteredItems, nil
-}
+		phrase, score := strings.Join(words, " "), 0.0
+		if phrase == query {
+			score = field.weight
+		} else if strings.HasPrefix(phrase, query) {
+			score = 0.8 * field.weight
+		}
+		if score > phraseScore {
+			phraseScore, phraseField = score, i
+		}
 
-// GetMenuItemsByCategoryID retrieves all menu items in a specific category.
-// This method provides filtered access to menu items based on their
-// category assignment for better organization and reporting.
-//
-// Parameters:
-//   - accountID: The account ID to scope the search
-//   - categoryID: The category ID to filter by
-//
-// Returns:
-//   - []models.MenuItem: List of menu items in the category
-//   - error: Any error that occurred during retrieval
-func (s *Service) GetMenuItemsByCategoryID(accountID int, categoryID int) ([]models.MenuItem, error) {
-	// Get all menu items for the account
-	items, err := s.menuItems.GetByAccountID(accountID)
-	if err != nil {
-		return nil, err
+		for j, term := range terms {
+			score := field.weight * searchTermScore(words, term)
+			fieldScores[i] += score
+			termScores[j] = max(termScores[j], score)
+		}
 	}
 
-	// Filter items by category
-	var filteredItems []models.MenuItem
-	for _, item := range items {
-		if item.CategoryID != nil && *item.CategoryID == categoryID {
-			filteredItems = append(filteredItems, item)
+	total := 0.0
+	for _, score := range termScores {
+		if score == 0 {
+			return 0, -1
+		}
+		total += score
+	}
+	score := 0.6 * total / float64(len(terms))
+	if phraseScore >= score {
+		return phraseScore, phraseField
+	}
+	best := 0
+	for i := range fieldScores {
+		if fieldScores[i] > fieldScores[best] {
+			best = i
 		}
 	}
+	return score, best
+}
 
-	return filteredItems, nil
+// searchTermScore rates how well a term matches a field's words: 1 for a whole word, 0.75
+// for the start of a word, 0.5 inside a word, and 0 when it does not appear
+func searchTermScore(words []string, term string) float64 {
+	best := 0.0
+	for _, word := range words {
+		switch {
+		case word == term:
+			return 1
+		case strings.HasPrefix(word, term):
+			best = max(best, 0.75)
+		case strings.Contains(word, term):
+			best = max(best, 0.5)
+		}
+	}
+	return best
 }
 
 // Email schedule operations
@@ -1633,4 +8331,91 @@ func (s *Service) UpdateEmailScheduleLastSent(id int, lastSentAt time.Time) erro
 	return s.emailSchedules.UpdateLastSentAt(id, lastSentAt)
 }
 
+// Email template operations
+// These methods handle the templates accounts use in place of the built-in email templates.
+// Validating template sources is left to the email package, which owns the template data.
+
+// ErrEmailTemplateNotFound is returned when an account has not overridden a template in a language
+var ErrEmailTemplateNotFound = errors.New("email template override not found")
+
+// SaveEmailTemplate creates an account's override of a template in a language, or
+// replaces the source of the existing override.
+//
+// Parameters:
+//   - emailTemplate: The override, identified by its account, name, and locale
+//
+// Returns:
+//   - error: Any error that occurred while saving
+//
+// Business rules:
+//   - An account has at most one override of each template in each language
+func (s *Service) SaveEmailTemplate(emailTemplate *models.EmailTemplate) error {
+	existing, err := s.emailTemplates.GetByAccountIDNameAndLocale(emailTemplate.AccountID, emailTemplate.Name, emailTemplate.Locale)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return s.emailTemplates.Create(emailTemplate)
+	}
+	if err != nil {
+		return err
+	}
+
+	existing.Source = emailTemplate.Source
+	if err := s.emailTemplates.Update(existing); err != nil {
+		return err
+	}
+	*emailTemplate = *existing
+	return nil
+}
+
+// GetEmailTemplatesByAccount retrieves all template overrides of an account.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//
+// Returns:
+//   - []models.EmailTemplate: The overrides, ordered by template name and locale
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetEmailTemplatesByAccount(accountID int) ([]models.EmailTemplate, error) {
+	return s.emailTemplates.GetByAccountID(accountID)
+}
+
+// GetEmailTemplateOverride retrieves an account's override of a template in a language.
+// It satisfies the email package's TemplateStore, so email services can render overrides.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//   - name: The template name, e.g. "weekly_stock_report"
+//   - locale: The language of the override, e.g. "es"
+//
+// Returns:
+//   - *models.EmailTemplate: The override, or nil when the account has none
+//   - error: Any error that occurred during retrieval
+func (s *Service) GetEmailTemplateOverride(accountID int, name, locale string) (*models.EmailTemplate, error) {
+	emailTemplate, err := s.emailTemplates.GetByAccountIDNameAndLocale(accountID, name, locale)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return emailTemplate, err
+}
+
+// DeleteEmailTemplate removes an account's override of a template in a language, so the
+// built-in template is used again.
+//
+// Parameters:
+//   - accountID: The unique identifier of the account
+//   - name: The template name
+//   - locale: The language of the override
+//
+// Returns:
+//   - error: ErrEmailTemplateNotFound if the account has no such override, or any deletion error
+func (s *Service) DeleteEmailTemplate(accountID int, name, locale string) error {
+	emailTemplate, err := s.emailTemplates.GetByAccountIDNameAndLocale(accountID, name, locale)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrEmailTemplateNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return s.emailTemplates.Delete(emailTemplate.ID)
+}
+
 // Business logic functions
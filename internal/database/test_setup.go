@@ -129,9 +129,36 @@ func runTestMigrations(gormDB *gorm.DB, config *TestDBConfig) error {
 		&models.SaleItem{},
 		&models.Order{},
 		&models.OrderItem{},
+		&models.OrderDispatch{},
+		&models.Receipt{},
+		&models.ReceiptLine{},
+		&models.Invoice{},
+		&models.InvoiceLine{},
 		&models.Delivery{},
 		&models.AccountInvitation{},
 		&models.EmailSchedule{},
+		&models.EmailTemplate{},
+		&models.Vendor{},
+		&models.PriceHistory{},
+		&models.PriceAlert{},
+		&models.Budget{},
+		&models.BudgetAlert{},
+		&models.InventoryLot{},
+		&models.WasteLog{},
+		&models.CountSession{},
+		&models.CountEntry{},
+		&models.StorageLocation{},
+		&models.LocationTransfer{},
+		&models.StockTransfer{},
+		&models.StockTransferLot{},
+		&models.CatalogCategory{},
+		&models.CatalogItem{},
+		&models.CatalogMenuItem{},
+		&models.CatalogRecipeIngredient{},
+		&models.CatalogSubscription{},
+		&models.CatalogSyncConflict{},
+		&models.StockAdjustment{},
+		&models.InventoryLevel{},
 	}
 
 	// Run migrations with context
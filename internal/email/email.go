@@ -1,12 +1,11 @@
 package email
 
 import (
-	"bytes"
 	"crypto/tls"
 	"fmt"
-	"html/template"
 	"net/smtp"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/mnadev/pantryos/internal/models"
@@ -15,6 +14,11 @@ import (
 // EmailService handles all email operations
 type EmailService struct {
 	config *EmailConfig
+	// store holds account overrides of the built-in templates; nil renders built-in templates only
+	store TemplateStore
+	// templates caches parsed overrides by account, name, and locale
+	templates   map[string]cachedTemplate
+	templatesMu sync.RWMutex
 }
 
 // EmailConfig holds email server configuration
@@ -36,8 +40,13 @@ type EmailData struct {
 	VerificationURL   string
 	StockReport       *StockReportData
 	SupplyChainReport *SupplyChainData
+	VendorScorecard   *VendorScorecardData
+	Valuation         *InventoryValuationData
+	PriceAlerts       []PriceAlertItemData
+	BudgetAlerts      []BudgetAlertItemData
 	LowStockItems     []models.InventoryItem
-	ExpiringItems     []models.InventoryItem
+	ExpiringItems     []ExpiringItemData
+	PurchaseOrder     *PurchaseOrderData
 }
 
 // StockReportData holds data for weekly stock reports
@@ -91,6 +100,98 @@ type SupplyChainItemData struct {
 	LastDeliveryDate  *time.Time
 }
 
+// VendorScorecardData holds data for monthly vendor scorecard reports
+type VendorScorecardData struct {
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Vendors     []VendorScorecardItemData
+}
+
+// VendorScorecardItemData holds individual vendor data for scorecard reports
+type VendorScorecardItemData struct {
+	Name              string
+	OrderLines        int
+	OnTimeRate        float64 // percent
+	AverageDaysLate   float64
+	FillRate          float64 // percent
+	AveragePriceDrift float64 // percent
+}
+
+// InventoryValuationData holds data for monthly inventory valuation reports
+type InventoryValuationData struct {
+	PeriodStart  time.Time
+	PeriodEnd    time.Time
+	Method       string // "fifo" or "weighted_average"
+	OpeningValue float64
+	Purchases    float64
+	ClosingValue float64
+	COGS         float64
+	Items        []InventoryValuationItemData
+}
+
+// InventoryValuationItemData holds individual item data for inventory valuation reports
+type InventoryValuationItemData struct {
+	Name            string
+	Unit            string
+	ClosingQuantity float64
+	ClosingUnitCost float64
+	ClosingValue    float64
+	COGS            float64
+}
+
+// PriceAlertItemData holds a single vendor price change for price alert emails
+type PriceAlertItemData struct {
+	ItemName      string
+	VendorName    string
+	PreviousPrice float64
+	NewPrice      float64
+	ChangePercent float64
+}
+
+// BudgetAlertItemData holds a single budget threshold crossed for budget alert emails
+type BudgetAlertItemData struct {
+	CategoryName string
+	Period       string // weekly, monthly
+	PeriodStart  time.Time
+	Threshold    float64 // The percentage of the budget crossed
+	Spend        float64
+	Amount       float64
+	PercentUsed  float64
+}
+
+// ExpiringItemData holds a single inventory lot for expiring items emails
+type ExpiringItemData struct {
+	Name           string
+	LotNumber      string
+	Quantity       float64
+	Unit           string
+	ExpirationDate time.Time
+	DaysLeft       int // Negative once the lot has expired
+}
+
+// PurchaseOrderData holds data for purchase order emails sent to a vendor
+type PurchaseOrderData struct {
+	Number          string // e.g., "PO-42-1"
+	VendorName      string
+	ContactName     string
+	OrderDate       time.Time
+	ExpectedDate    time.Time // Zero when no delivery date was requested
+	DeliveryAddress string
+	Notes           string
+	Total           float64
+	Lines           []PurchaseOrderLineData
+}
+
+// PurchaseOrderLineData holds a single item on a purchase order email
+type PurchaseOrderLineData struct {
+	ItemName  string
+	Unit      string
+	Quantity  float64
+	UnitCost  float64
+	TotalCost float64
+	Notes     string
+}
+
 // NewEmailService creates a new email service with configuration
 func NewEmailService() *EmailService {
 	config := &EmailConfig{
@@ -104,7 +205,8 @@ func NewEmailService() *EmailService {
 	}
 
 	return &EmailService{
-		config: config,
+		config:    config,
+		templates: make(map[string]cachedTemplate),
 	}
 }
 
@@ -117,8 +219,7 @@ func (es *EmailService) SendVerificationEmail(user models.User, account models.A
 		VerificationURL: verificationURL,
 	}
 
-	subject := "Verify Your PantryOS Account"
-	body, err := es.renderTemplate("verification", data)
+	subject, body, err := es.renderTemplate(account.ID, "verification", user.Language, data)
 	if err != nil {
 		return fmt.Errorf("failed to render verification template: %w", err)
 	}
@@ -126,28 +227,14 @@ func (es *EmailService) SendVerificationEmail(user models.User, account models.A
 	return es.sendEmail(user.Email, subject, body)
 }
 
-// SendWeeklyStockReport sends weekly stock report email
-func (es *EmailService) SendWeeklyStockReport(account models.Account, users []models.User, stockData *StockReportData) error {
+// SendWeeklyStockReport sends weekly stock report email, with optional attachments such as the PDF report
+func (es *EmailService) SendWeeklyStockReport(account models.Account, users []models.User, stockData *StockReportData, attachments ...Attachment) error {
 	data := EmailData{
 		AccountName: account.Name,
 		StockReport: stockData,
 	}
 
-	subject := fmt.Sprintf("Weekly Stock Report - %s", account.Name)
-	body, err := es.renderTemplate("weekly_stock_report", data)
-	if err != nil {
-		return fmt.Errorf("failed to render weekly stock report template: %w", err)
-	}
-
-	// Send to all users in the account
-	for _, user := range users {
-		if err := es.sendEmail(user.Email, subject, body); err != nil {
-			// Log error but continue with other users
-			fmt.Printf("Failed to send weekly stock report to %s: %v\n", user.Email, err)
-		}
-	}
-
-	return nil
+	return es.sendToUsers(account, users, "weekly_stock_report", data, attachments)
 }
 
 // SendLowStockAlert sends low stock alert email
@@ -157,72 +244,154 @@ func (es *EmailService) SendLowStockAlert(account models.Account, users []models
 		LowStockItems: lowStockItems,
 	}
 
-	subject := fmt.Sprintf("Low Stock Alert - %s", account.Name)
-	body, err := es.renderTemplate("low_stock_alert", data)
-	if err != nil {
-		return fmt.Errorf("failed to render low stock alert template: %w", err)
+	return es.sendToUsers(account, users, "low_stock_alert", data, nil)
+}
+
+// SendWeeklySupplyChainReport sends weekly supply chain report email, with optional attachments
+func (es *EmailService) SendWeeklySupplyChainReport(account models.Account, users []models.User, supplyChainData *SupplyChainData, attachments ...Attachment) error {
+	data := EmailData{
+		AccountName:       account.Name,
+		SupplyChainReport: supplyChainData,
 	}
 
-	// Send to all users in the account
-	for _, user := range users {
-		if err := es.sendEmail(user.Email, subject, body); err != nil {
-			// Log error but continue with other users
-			fmt.Printf("Failed to send low stock alert to %s: %v\n", user.Email, err)
-		}
+	return es.sendToUsers(account, users, "weekly_supply_chain_report", data, attachments)
+}
+
+// SendVendorScorecard sends monthly vendor scorecard email, with optional attachments
+func (es *EmailService) SendVendorScorecard(account models.Account, users []models.User, scorecardData *VendorScorecardData, attachments ...Attachment) error {
+	data := EmailData{
+		AccountName:     account.Name,
+		VendorScorecard: scorecardData,
 	}
 
-	return nil
+	return es.sendToUsers(account, users, "monthly_vendor_scorecard", data, attachments)
 }
 
-// SendWeeklySupplyChainReport sends weekly supply chain report email
-func (es *EmailService) SendWeeklySupplyChainReport(account models.Account, users []models.User, supplyChainData *SupplyChainData) error {
+// SendInventoryValuation sends monthly inventory valuation email, with optional attachments such as a CSV export
+func (es *EmailService) SendInventoryValuation(account models.Account, users []models.User, valuationData *InventoryValuationData, attachments ...Attachment) error {
 	data := EmailData{
-		AccountName:       account.Name,
-		SupplyChainReport: supplyChainData,
+		AccountName: account.Name,
+		Valuation:   valuationData,
+	}
+
+	return es.sendToUsers(account, users, "monthly_inventory_valuation", data, attachments)
+}
+
+// SendPriceAlert sends vendor price change alert email
+func (es *EmailService) SendPriceAlert(account models.Account, users []models.User, alerts []PriceAlertItemData) error {
+	data := EmailData{
+		AccountName: account.Name,
+		PriceAlerts: alerts,
 	}
 
-	subject := fmt.Sprintf("Weekly Supply Chain Report - %s", account.Name)
-	body, err := es.renderTemplate("weekly_supply_chain_report", data)
+	return es.sendToUsers(account, users, "price_alert", data, nil)
+}
+
+// SendBudgetAlert sends an alert listing category budgets whose spend crossed an alert threshold
+func (es *EmailService) SendBudgetAlert(account models.Account, users []models.User, alerts []BudgetAlertItemData) error {
+	data := EmailData{
+		AccountName:  account.Name,
+		BudgetAlerts: alerts,
+	}
+
+	return es.sendToUsers(account, users, "budget_alert", data, nil)
+}
+
+// SendExpiringItemsAlert sends an alert listing inventory lots that expire soon
+func (es *EmailService) SendExpiringItemsAlert(account models.Account, users []models.User, items []ExpiringItemData) error {
+	data := EmailData{
+		AccountName:   account.Name,
+		ExpiringItems: items,
+	}
+
+	return es.sendToUsers(account, users, "expiring_items", data, nil)
+}
+
+// SendPurchaseOrder sends a purchase order to a vendor, rendered in DefaultLocale with the
+// account's overrides, with optional attachments such as the purchase order PDF.
+//
+// Parameters:
+//   - account: The account placing the order
+//   - to: The vendor's email address
+//   - replyTo: Where the vendor's replies go, e.g. the account's email; empty for the sender
+//   - purchaseOrder: The purchase order
+//
+// Returns:
+//   - string: The subject of the email, for logging
+//   - error: A rendering or delivery error
+func (es *EmailService) SendPurchaseOrder(account models.Account, to, replyTo string, purchaseOrder *PurchaseOrderData, attachments ...Attachment) (string, error) {
+	data := EmailData{
+		AccountName:   account.Name,
+		PurchaseOrder: purchaseOrder,
+	}
+
+	subject, body, err := es.renderTemplate(account.ID, models.EmailTypePurchaseOrder, DefaultLocale, data)
 	if err != nil {
-		return fmt.Errorf("failed to render weekly supply chain report template: %w", err)
+		return "", fmt.Errorf("failed to render purchase order template: %w", err)
+	}
+
+	message := es.newMessage(to, subject, body, attachments)
+	message.ReplyTo = replyTo
+	return subject, es.send(message)
+}
+
+// sendToUsers renders a template in the language of each user and sends it to them.
+// Every language is rendered before anything is sent, so a template error sends nothing;
+// a failed delivery is logged and the other users still receive the email.
+func (es *EmailService) sendToUsers(account models.Account, users []models.User, templateName string, data EmailData, attachments []Attachment) error {
+	type rendered struct{ subject, body string }
+	byLocale := make(map[string]rendered)
+	for _, user := range users {
+		if _, ok := byLocale[user.Language]; ok {
+			continue
+		}
+		subject, body, err := es.renderTemplate(account.ID, templateName, user.Language, data)
+		if err != nil {
+			return fmt.Errorf("failed to render %s template: %w", templateName, err)
+		}
+		byLocale[user.Language] = rendered{subject, body}
 	}
 
 	// Send to all users in the account
 	for _, user := range users {
-		if err := es.sendEmail(user.Email, subject, body); err != nil {
+		email := byLocale[user.Language]
+		if err := es.sendEmail(user.Email, email.subject, email.body, attachments...); err != nil {
 			// Log error but continue with other users
-			fmt.Printf("Failed to send weekly supply chain report to %s: %v\n", user.Email, err)
+			fmt.Printf("Failed to send %s to %s: %v\n", templateName, user.Email, err)
 		}
 	}
 
 	return nil
 }
 
-// sendEmail sends an email using SMTP
-func (es *EmailService) sendEmail(to, subject, body string) error {
+// sendEmail sends a multipart email with plain text and HTML versions of the body,
+// and any attachments
+func (es *EmailService) sendEmail(to, subject, body string, attachments ...Attachment) error {
+	return es.send(es.newMessage(to, subject, body, attachments))
+}
+
+// send delivers a message through the configured SMTP server
+func (es *EmailService) send(msg *Message) error {
 	if es.config.SMTPUsername == "" || es.config.SMTPPassword == "" {
 		return fmt.Errorf("SMTP credentials not configured")
 	}
 
 	// Create email message
-	message := fmt.Sprintf("From: %s <%s>\r\n", es.config.FromName, es.config.FromEmail)
-	message += fmt.Sprintf("To: %s\r\n", to)
-	message += fmt.Sprintf("Subject: %s\r\n", subject)
-	message += "MIME-Version: 1.0\r\n"
-	message += "Content-Type: text/html; charset=UTF-8\r\n"
-	message += "\r\n"
-	message += body
+	to := msg.To
+	message, err := msg.Bytes()
+	if err != nil {
+		return fmt.Errorf("failed to build email message: %w", err)
+	}
 
 	// Connect to SMTP server
 	auth := smtp.PlainAuth("", es.config.SMTPUsername, es.config.SMTPPassword, es.config.SMTPHost)
 
 	addr := fmt.Sprintf("%s:%s", es.config.SMTPHost, es.config.SMTPPort)
 
-	var err error
 	if es.config.UseTLS {
 		err = es.sendEmailWithTLS(to, message, addr, auth)
 	} else {
-		err = smtp.SendMail(addr, auth, es.config.FromEmail, []string{to}, []byte(message))
+		err = smtp.SendMail(addr, auth, es.config.FromEmail, []string{to}, message)
 	}
 
 	if err != nil {
@@ -233,7 +402,7 @@ func (es *EmailService) sendEmail(to, subject, body string) error {
 }
 
 // sendEmailWithTLS sends email with TLS encryption
-func (es *EmailService) sendEmailWithTLS(to, message, addr string, auth smtp.Auth) error {
+func (es *EmailService) sendEmailWithTLS(to string, message []byte, addr string, auth smtp.Auth) error {
 	// Connect to SMTP server
 	conn, err := smtp.Dial(addr)
 	if err != nil {
@@ -265,7 +434,7 @@ func (es *EmailService) sendEmailWithTLS(to, message, addr string, auth smtp.Aut
 		return err
 	}
 
-	_, err = writer.Write([]byte(message))
+	_, err = writer.Write(message)
 	if err != nil {
 		return err
 	}
@@ -278,334 +447,6 @@ func (es *EmailService) sendEmailWithTLS(to, message, addr string, auth smtp.Aut
 	return conn.Quit()
 }
 
-// renderTemplate renders an email template with the given data
-func (es *EmailService) renderTemplate(templateName string, data EmailData) (string, error) {
-	tmpl, err := template.New(templateName).Parse(getEmailTemplate(templateName))
-	if err != nil {
-		return "", fmt.Errorf("failed to parse template %s: %w", templateName, err)
-	}
-
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("failed to execute template %s: %w", templateName, err)
-	}
-
-	return buf.String(), nil
-}
-
-// getEmailTemplate returns the HTML template for the given template name
-func getEmailTemplate(templateName string) string {
-	templates := map[string]string{
-		"verification": `
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Verify Your Account</title>
-    <style>
-        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
-        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
-        .header { background-color: #4F46E5; color: white; padding: 20px; text-align: center; }
-        .content { padding: 20px; background-color: #f9f9f9; }
-        .button { display: inline-block; padding: 12px 24px; background-color: #4F46E5; color: white; text-decoration: none; border-radius: 5px; margin: 20px 0; }
-        .footer { text-align: center; padding: 20px; color: #666; font-size: 12px; }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="header">
-            		<h1>Welcome to PantryOS!</h1>
-        </div>
-        <div class="content">
-            <h2>Verify Your Account</h2>
-            <p>Hello,</p>
-            		<p>Thank you for joining <strong>{{.AccountName}}</strong> on PantryOS! To complete your account setup, please verify your email address.</p>
-            <p>Click the button below to verify your account:</p>
-            <div style="text-align: center;">
-                <a href="{{.VerificationURL}}" class="button">Verify Account</a>
-            </div>
-            <p>If the button doesn't work, you can copy and paste this link into your browser:</p>
-            <p style="word-break: break-all; color: #666;">{{.VerificationURL}}</p>
-            <p>This link will expire in 24 hours for security reasons.</p>
-            		<p>If you didn't create an account with PantryOS, please ignore this email.</p>
-        </div>
-        <div class="footer">
-            <p>© 2024 PantryOS Inventory System. All rights reserved.</p>
-        </div>
-    </div>
-</body>
-</html>`,
-
-		"weekly_stock_report": `
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Weekly Stock Report</title>
-    <style>
-        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
-        .container { max-width: 800px; margin: 0 auto; padding: 20px; }
-        .header { background-color: #4F46E5; color: white; padding: 20px; text-align: center; }
-        .content { padding: 20px; background-color: #f9f9f9; }
-        .summary { background-color: white; padding: 20px; margin: 20px 0; border-radius: 5px; }
-        .summary-grid { display: grid; grid-template-columns: repeat(auto-fit, minmax(200px, 1fr)); gap: 20px; margin: 20px 0; }
-        .summary-item { text-align: center; padding: 15px; background-color: #f8f9fa; border-radius: 5px; }
-        .summary-number { font-size: 24px; font-weight: bold; color: #4F46E5; }
-        .table { width: 100%; border-collapse: collapse; margin: 20px 0; }
-        .table th, .table td { padding: 12px; text-align: left; border-bottom: 1px solid #ddd; }
-        .table th { background-color: #f8f9fa; font-weight: bold; }
-        .status-normal { color: #28a745; }
-        .status-low { color: #ffc107; }
-        .status-out { color: #dc3545; }
-        .footer { text-align: center; padding: 20px; color: #666; font-size: 12px; }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="header">
-            <h1>Weekly Stock Report</h1>
-            <p>{{.AccountName}}</p>
-        </div>
-        <div class="content">
-            <h2>Stock Report for {{.StockReport.ReportDate.Format "January 2, 2006"}}</h2>
-            
-            <div class="summary">
-                <h3>Summary</h3>
-                <div class="summary-grid">
-                    <div class="summary-item">
-                        <div class="summary-number">{{.StockReport.TotalItems}}</div>
-                        <div>Total Items</div>
-                    </div>
-                    <div class="summary-item">
-                        <div class="summary-number">{{.StockReport.LowStockItems}}</div>
-                        <div>Low Stock Items</div>
-                    </div>
-                    <div class="summary-item">
-                        <div class="summary-number">{{.StockReport.OutOfStockItems}}</div>
-                        <div>Out of Stock</div>
-                    </div>
-                    <div class="summary-item">
-                        <div class="summary-number">${{printf "%.2f" .StockReport.TotalValue}}</div>
-                        <div>Total Value</div>
-                    </div>
-                </div>
-            </div>
-
-            <h3>Item Details</h3>
-            <table class="table">
-                <thead>
-                    <tr>
-                        <th>Item</th>
-                        <th>Category</th>
-                        <th>Current Stock</th>
-                        <th>Min Stock</th>
-                        <th>Max Stock</th>
-                        <th>Status</th>
-                    </tr>
-                </thead>
-                <tbody>
-                    {{range .StockReport.Items}}
-                    <tr>
-                        <td>{{.Name}}</td>
-                        <td>{{.Category}}</td>
-                        <td>{{.CurrentStock}} {{.Unit}}</td>
-                        <td>{{.MinStock}} {{.Unit}}</td>
-                        <td>{{.MaxStock}} {{.Unit}}</td>
-                        <td class="status-{{.Status}}">{{.Status}}</td>
-                    </tr>
-                    {{end}}
-                </tbody>
-            </table>
-        </div>
-        <div class="footer">
-            <p>© 2024 PantryOS Inventory System. All rights reserved.</p>
-        </div>
-    </div>
-</body>
-</html>`,
-
-		"low_stock_alert": `
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Low Stock Alert</title>
-    <style>
-        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
-        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
-        .header { background-color: #dc3545; color: white; padding: 20px; text-align: center; }
-        .content { padding: 20px; background-color: #f9f9f9; }
-        .alert { background-color: #fff3cd; border: 1px solid #ffeaa7; padding: 15px; margin: 20px 0; border-radius: 5px; }
-        .table { width: 100%; border-collapse: collapse; margin: 20px 0; }
-        .table th, .table td { padding: 12px; text-align: left; border-bottom: 1px solid #ddd; }
-        .table th { background-color: #f8f9fa; font-weight: bold; }
-        .footer { text-align: center; padding: 20px; color: #666; font-size: 12px; }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="header">
-            <h1>⚠️ Low Stock Alert</h1>
-            <p>{{.AccountName}}</p>
-        </div>
-        <div class="content">
-            <div class="alert">
-                <h3>Action Required</h3>
-                <p>The following items are running low on stock and may need to be reordered soon:</p>
-            </div>
-
-            <table class="table">
-                <thead>
-                    <tr>
-                        <th>Item</th>
-                        <th>Current Stock</th>
-                        <th>Min Stock Level</th>
-                        <th>Unit</th>
-                    </tr>
-                </thead>
-                <tbody>
-                    {{range .LowStockItems}}
-                    <tr>
-                        <td>{{.Name}}</td>
-                        <td>{{.MinStockLevel}}</td>
-                        <td>{{.MinStockLevel}}</td>
-                        <td>{{.Unit}}</td>
-                    </tr>
-                    {{end}}
-                </tbody>
-            </table>
-
-            <p><strong>Please review these items and place orders as needed to maintain adequate stock levels.</strong></p>
-        </div>
-        <div class="footer">
-            <p>© 2024 PantryOS Inventory System. All rights reserved.</p>
-        </div>
-    </div>
-</body>
-</html>`,
-
-		"weekly_supply_chain_report": `
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Weekly Supply Chain Report</title>
-    <style>
-        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
-        .container { max-width: 900px; margin: 0 auto; padding: 20px; }
-        .header { background-color: #17a2b8; color: white; padding: 20px; text-align: center; }
-        .content { padding: 20px; background-color: #f9f9f9; }
-        .summary { background-color: white; padding: 20px; margin: 20px 0; border-radius: 5px; }
-        .summary-grid { display: grid; grid-template-columns: repeat(auto-fit, minmax(180px, 1fr)); gap: 20px; margin: 20px 0; }
-        .summary-item { text-align: center; padding: 15px; background-color: #f8f9fa; border-radius: 5px; }
-        .summary-number { font-size: 24px; font-weight: bold; color: #17a2b8; }
-        .table { width: 100%; border-collapse: collapse; margin: 20px 0; }
-        .table th, .table td { padding: 12px; text-align: left; border-bottom: 1px solid #ddd; }
-        .table th { background-color: #f8f9fa; font-weight: bold; }
-        .status-normal { color: #28a745; }
-        .status-low { color: #ffc107; }
-        .status-critical { color: #fd7e14; }
-        .status-out { color: #dc3545; }
-        .vendor-info { background-color: #e3f2fd; padding: 10px; border-radius: 5px; margin: 10px 0; }
-        .footer { text-align: center; padding: 20px; color: #666; font-size: 12px; }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="header">
-            <h1>📊 Weekly Supply Chain Report</h1>
-            <p>{{.AccountName}}</p>
-        </div>
-        <div class="content">
-            <h2>Supply Chain Report for {{.SupplyChainReport.ReportDate.Format "January 2, 2006"}}</h2>
-            
-            <div class="summary">
-                <h3>Supply Chain Summary</h3>
-                <div class="summary-grid">
-                    <div class="summary-item">
-                        <div class="summary-number">{{.SupplyChainReport.TotalItems}}</div>
-                        <div>Total Items</div>
-                    </div>
-                    <div class="summary-item">
-                        <div class="summary-number">{{.SupplyChainReport.LowStockItems}}</div>
-                        <div>Low Stock Items</div>
-                    </div>
-                    <div class="summary-item">
-                        <div class="summary-number">{{.SupplyChainReport.CriticalItems}}</div>
-                        <div>Critical Items</div>
-                    </div>
-                    <div class="summary-item">
-                        <div class="summary-number">{{.SupplyChainReport.OutOfStockItems}}</div>
-                        <div>Out of Stock</div>
-                    </div>
-                    <div class="summary-item">
-                        <div class="summary-number">${{printf "%.2f" .SupplyChainReport.TotalValue}}</div>
-                        <div>Total Value</div>
-                    </div>
-                    <div class="summary-item">
-                        <div class="summary-number">${{printf "%.2f" .SupplyChainReport.EstimatedReorders}}</div>
-                        <div>Est. Reorder Cost</div>
-                    </div>
-                </div>
-            </div>
-
-            <h3>Supply Chain Details</h3>
-            <table class="table">
-                <thead>
-                    <tr>
-                        <th>Item</th>
-                        <th>Category</th>
-                        <th>Current Stock</th>
-                        <th>Min Stock</th>
-                        <th>Status</th>
-                        <th>Vendor</th>
-                        <th>Cost/Unit</th>
-                        <th>Reorder Qty</th>
-                        <th>Days Until Stockout</th>
-                    </tr>
-                </thead>
-                <tbody>
-                    {{range .SupplyChainReport.Items}}
-                    <tr>
-                        <td>{{.Name}}</td>
-                        <td>{{.Category}}</td>
-                        <td>{{.CurrentStock}} {{.Unit}}</td>
-                        <td>{{.MinStock}} {{.Unit}}</td>
-                        <td class="status-{{.Status}}">{{.Status}}</td>
-                        <td>{{.PreferredVendor}}</td>
-                        <td>${{printf "%.2f" .CostPerUnit}}</td>
-                        <td>{{.ReorderQuantity}} {{.Unit}}</td>
-                        <td>{{.DaysUntilStockout}} days</td>
-                    </tr>
-                    {{end}}
-                </tbody>
-            </table>
-
-            <div class="vendor-info">
-                <h4>📋 Supply Chain Recommendations</h4>
-                <ul>
-                    <li><strong>Critical Items:</strong> Items with {{.SupplyChainReport.CriticalItems}} critical status need immediate attention</li>
-                    <li><strong>Low Stock Items:</strong> {{.SupplyChainReport.LowStockItems}} items are below minimum stock levels</li>
-                    <li><strong>Estimated Reorder Cost:</strong> ${{printf "%.2f" .SupplyChainReport.EstimatedReorders}} for recommended reorders</li>
-                    <li><strong>Vendor Management:</strong> Review preferred vendors for optimal pricing and delivery</li>
-                </ul>
-            </div>
-        </div>
-        <div class="footer">
-            <p>© 2024 PantryOS Inventory System. All rights reserved.</p>
-        </div>
-    </div>
-</body>
-</html>`,
-	}
-
-	return templates[templateName]
-}
-
 // getEnvOrDefault returns environment variable value or default
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
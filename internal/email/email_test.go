@@ -1,6 +1,14 @@
 package email
 
 import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
 	"testing"
 	"time"
 
@@ -29,7 +37,7 @@ func TestEmailTemplates(t *testing.T) {
 		VerificationURL: "https://example.com/verify?token=test123",
 	}
 
-	body, err := service.renderTemplate("verification", data)
+	_, body, err := service.renderTemplate(0, "verification", "", data)
 	if err != nil {
 		t.Fatalf("Failed to render verification template: %v", err)
 	}
@@ -60,7 +68,7 @@ func TestEmailTemplates(t *testing.T) {
 	}
 
 	data.StockReport = stockData
-	body, err = service.renderTemplate("weekly_stock_report", data)
+	_, body, err = service.renderTemplate(0, "weekly_stock_report", "", data)
 	if err != nil {
 		t.Fatalf("Failed to render weekly stock report template: %v", err)
 	}
@@ -85,7 +93,7 @@ func TestEmailTemplates(t *testing.T) {
 	}
 
 	data.LowStockItems = lowStockItems
-	body, err = service.renderTemplate("low_stock_alert", data)
+	_, body, err = service.renderTemplate(0, "low_stock_alert", "", data)
 	if err != nil {
 		t.Fatalf("Failed to render low stock alert template: %v", err)
 	}
@@ -93,6 +101,146 @@ func TestEmailTemplates(t *testing.T) {
 	if body == "" {
 		t.Fatal("Expected non-empty email body")
 	}
+
+	// Test monthly vendor scorecard template
+	data.VendorScorecard = &VendorScorecardData{
+		PeriodStart: time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2024, time.May, 31, 23, 59, 59, 0, time.UTC),
+		Vendors: []VendorScorecardItemData{
+			{
+				Name:              "Local Dairy",
+				OrderLines:        4,
+				OnTimeRate:        75.0,
+				AverageDaysLate:   2.0,
+				FillRate:          95.5,
+				AveragePriceDrift: 3.2,
+			},
+		},
+	}
+	_, body, err = service.renderTemplate(0, "monthly_vendor_scorecard", "", data)
+	if err != nil {
+		t.Fatalf("Failed to render vendor scorecard template: %v", err)
+	}
+
+	if !strings.Contains(body, "Local Dairy") || !strings.Contains(body, "3.2%") {
+		t.Fatal("Expected vendor scorecard body to list the vendor and its price drift")
+	}
+
+	// Test monthly inventory valuation template
+	data.Valuation = &InventoryValuationData{
+		PeriodStart:  time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:    time.Date(2024, time.May, 31, 23, 59, 59, 0, time.UTC),
+		Method:       "fifo",
+		OpeningValue: 120,
+		Purchases:    80,
+		ClosingValue: 50,
+		COGS:         150,
+		Items: []InventoryValuationItemData{
+			{Name: "Whole Milk", Unit: "liters", ClosingQuantity: 20, ClosingUnitCost: 2.5, ClosingValue: 50, COGS: 150},
+		},
+	}
+	_, body, err = service.renderTemplate(0, "monthly_inventory_valuation", "", data)
+	if err != nil {
+		t.Fatalf("Failed to render inventory valuation template: %v", err)
+	}
+
+	if !strings.Contains(body, "FIFO") || !strings.Contains(body, "$150.00") || !strings.Contains(body, "Whole Milk") {
+		t.Fatal("Expected inventory valuation body to show the method, COGS, and items")
+	}
+
+	// Test price alert template
+	data.PriceAlerts = []PriceAlertItemData{
+		{
+			ItemName:      "Whole Milk",
+			VendorName:    "Local Dairy",
+			PreviousPrice: 2.0,
+			NewPrice:      2.5,
+			ChangePercent: 25.0,
+		},
+	}
+	_, body, err = service.renderTemplate(0, "price_alert", "", data)
+	if err != nil {
+		t.Fatalf("Failed to render price alert template: %v", err)
+	}
+
+	if !strings.Contains(body, "Whole Milk") || !strings.Contains(body, "$2.50") {
+		t.Fatal("Expected price alert body to list the item and its new price")
+	}
+
+	// Test budget alert template
+	data.BudgetAlerts = []BudgetAlertItemData{
+		{
+			CategoryName: "Dairy",
+			Period:       models.BudgetPeriodMonthly,
+			PeriodStart:  time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC),
+			Threshold:    100,
+			Spend:        525,
+			Amount:       500,
+			PercentUsed:  105,
+		},
+	}
+	subject, body, err := service.renderTemplate(0, "budget_alert", "", data)
+	if err != nil {
+		t.Fatalf("Failed to render budget alert template: %v", err)
+	}
+
+	if !strings.Contains(subject, "Budget Alert") || !strings.Contains(body, "Month of Mar 1, 2026") || !strings.Contains(body, "$525.00") || !strings.Contains(body, "alert at 100%") {
+		t.Fatal("Expected budget alert body to list the category period, spend, and threshold")
+	}
+
+	// Test expiring items template
+	data.ExpiringItems = []ExpiringItemData{
+		{
+			Name:           "Whole Milk",
+			LotNumber:      "MILK-001",
+			Quantity:       6,
+			Unit:           "liters",
+			ExpirationDate: time.Date(2024, time.June, 7, 0, 0, 0, 0, time.UTC),
+			DaysLeft:       2,
+		},
+		{
+			Name:           "Cream",
+			LotNumber:      "DEL-12",
+			Quantity:       1,
+			Unit:           "liters",
+			ExpirationDate: time.Date(2024, time.June, 4, 0, 0, 0, 0, time.UTC),
+			DaysLeft:       -1,
+		},
+	}
+	_, body, err = service.renderTemplate(0, "expiring_items", "", data)
+	if err != nil {
+		t.Fatalf("Failed to render expiring items template: %v", err)
+	}
+
+	if !strings.Contains(body, "MILK-001") || !strings.Contains(body, "Jun 7, 2024") || !strings.Contains(body, "Expired") {
+		t.Fatal("Expected expiring items body to list the lots and flag expired stock")
+	}
+}
+
+func TestPurchaseOrderTemplate(t *testing.T) {
+	service := NewEmailService()
+	data := EmailData{AccountName: "Main Street Cafe", PurchaseOrder: &PurchaseOrderData{
+		Number:     "PO-7-2",
+		VendorName: "Mill Co",
+		OrderDate:  time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC),
+		Total:      12,
+		Lines:      []PurchaseOrderLineData{{ItemName: "Flour", Unit: "kg", Quantity: 10, UnitCost: 1.2, TotalCost: 12}},
+	}}
+
+	subject, body, err := service.renderTemplate(0, models.EmailTypePurchaseOrder, "", data)
+	if err != nil {
+		t.Fatalf("Failed to render purchase order template: %v", err)
+	}
+	if subject != "Purchase Order PO-7-2 from Main Street Cafe" {
+		t.Fatalf("Unexpected subject %q", subject)
+	}
+	if !strings.Contains(body, "Flour") || !strings.Contains(body, "$12.00") || strings.Contains(body, "Requested delivery") {
+		t.Fatal("Expected purchase order body to list the lines, without a delivery date when none was requested")
+	}
+
+	if _, err := service.SendPurchaseOrder(models.Account{Name: "Main Street Cafe"}, "orders@mill.example.com", "", data.PurchaseOrder); err == nil {
+		t.Fatal("Expected sending without SMTP credentials to fail")
+	}
 }
 
 func TestGetEmailTemplate(t *testing.T) {
@@ -121,6 +269,301 @@ func TestGetEmailTemplate(t *testing.T) {
 	}
 }
 
+// fakeTemplateStore serves template overrides from memory, keyed by "name.locale"
+type fakeTemplateStore map[string]*models.EmailTemplate
+
+func (s fakeTemplateStore) GetEmailTemplateOverride(accountID int, name, locale string) (*models.EmailTemplate, error) {
+	if override := s[name+"."+locale]; override != nil && override.AccountID == accountID {
+		return override, nil
+	}
+	return nil, nil
+}
+
+func TestTemplateLocales(t *testing.T) {
+	service := NewEmailService()
+	data := EmailData{
+		AccountName:       "Main Street Cafe",
+		StockReport:       &StockReportData{ReportDate: time.Now()},
+		SupplyChainReport: &SupplyChainData{ReportDate: time.Now()},
+	}
+
+	subject, body, err := service.renderTemplate(0, "weekly_stock_report", "es-MX", data)
+	if err != nil {
+		t.Fatalf("Failed to render Spanish template: %v", err)
+	}
+	if subject != "Informe semanal de existencias - Main Street Cafe" || !strings.Contains(body, `lang="es"`) {
+		t.Fatalf("Expected es-MX to fall back to the Spanish template, got subject %q", subject)
+	}
+
+	subject, _, err = service.renderTemplate(0, "weekly_supply_chain_report", "es", data)
+	if err != nil {
+		t.Fatalf("Failed to render untranslated template: %v", err)
+	}
+	if subject != "Weekly Supply Chain Report - Main Street Cafe" {
+		t.Fatalf("Expected untranslated template to fall back to English, got subject %q", subject)
+	}
+
+	if _, _, err := service.renderTemplate(0, "non_existent", "", data); err == nil {
+		t.Fatal("Expected unknown template to be rejected")
+	}
+
+	if locales := BuiltinLocales("weekly_stock_report"); len(locales) != 2 || locales[0] != DefaultLocale || locales[1] != "es" {
+		t.Fatalf("Unexpected built-in locales %v", locales)
+	}
+	if locale, err := NormalizeLocale("pt_BR"); err != nil || locale != "pt-br" {
+		t.Fatalf("Expected pt_BR to normalize to pt-br, got %q (%v)", locale, err)
+	}
+	if _, err := NormalizeLocale("../en"); err == nil {
+		t.Fatal("Expected invalid locale to be rejected")
+	}
+}
+
+func TestTemplateOverrides(t *testing.T) {
+	service := NewEmailService()
+	store := fakeTemplateStore{
+		"low_stock_alert.en": {AccountID: 1, Name: "low_stock_alert", Locale: "en", UpdatedAt: time.Unix(1, 0),
+			Source: `{{define "subject"}}Reorder now &amp; save - {{.AccountName}}{{end}}<p>{{len .LowStockItems}} items for {{.AccountName}}</p>`},
+		"verification.en": {AccountID: 1, Name: "verification", Locale: "en", UpdatedAt: time.Unix(1, 0),
+			Source: `<p>{{.Missing.Field}}</p>`},
+	}
+	service.SetTemplateStore(store)
+	data := EmailData{AccountName: "Main Street Cafe", LowStockItems: []models.InventoryItem{{Name: "Whole Milk"}}}
+
+	subject, body, err := service.renderTemplate(1, "low_stock_alert", "", data)
+	if err != nil {
+		t.Fatalf("Failed to render override: %v", err)
+	}
+	if subject != "Reorder now & save - Main Street Cafe" || body != "<p>1 items for Main Street Cafe</p>" {
+		t.Fatalf("Expected the account's override, got subject %q and body %q", subject, body)
+	}
+
+	// The Spanish built-in template is preferred over the English override for Spanish speakers
+	subject, _, err = service.renderTemplate(1, "low_stock_alert", "es", data)
+	if err != nil || !strings.HasPrefix(subject, "Alerta de existencias bajas") {
+		t.Fatalf("Expected the Spanish template, got subject %q (%v)", subject, err)
+	}
+
+	// Other accounts keep the built-in template
+	if subject, _, _ = service.renderTemplate(2, "low_stock_alert", "", data); subject != "Low Stock Alert - Main Street Cafe" {
+		t.Fatalf("Expected the built-in template for another account, got subject %q", subject)
+	}
+
+	// A parsed override is reused until it changes
+	cached, _ := service.parseOverride(store["low_stock_alert.en"])
+	if again, _ := service.parseOverride(store["low_stock_alert.en"]); again != cached {
+		t.Fatal("Expected the parsed override to be cached")
+	}
+	store["low_stock_alert.en"].UpdatedAt = time.Unix(2, 0)
+	if changed, _ := service.parseOverride(store["low_stock_alert.en"]); changed == cached {
+		t.Fatal("Expected a changed override to be parsed again")
+	}
+
+	// An override that fails to execute falls back to the built-in template
+	subject, _, err = service.renderTemplate(1, "verification", "", EmailData{AccountName: "Main Street Cafe"})
+	if err != nil || subject != "Verify Your PantryOS Account" {
+		t.Fatalf("Expected the built-in template after a broken override, got subject %q (%v)", subject, err)
+	}
+}
+
+func TestValidateAndPreviewTemplate(t *testing.T) {
+	if err := ValidateTemplate("low_stock_alert", `{{define "subject"}}Low - {{.AccountName}}{{end}}{{range .LowStockItems}}{{.Name}}{{end}}`); err != nil {
+		t.Fatalf("Expected valid template, got %v", err)
+	}
+	if err := ValidateTemplate("low_stock_alert", `{{range .LowStockItems}}`); err == nil {
+		t.Fatal("Expected unparseable template to be rejected")
+	}
+	if err := ValidateTemplate("low_stock_alert", `{{.NoSuchField}}`); err == nil {
+		t.Fatal("Expected template with unknown fields to be rejected")
+	}
+	if err := ValidateTemplate("non_existent", `<p></p>`); err == nil {
+		t.Fatal("Expected unknown template to be rejected")
+	}
+
+	service := NewEmailService()
+	for _, name := range TemplateNames {
+		for _, locale := range BuiltinLocales(name) {
+			preview, err := service.PreviewTemplate(models.Account{Name: "Sample Cafe"}, name, locale)
+			if err != nil {
+				t.Fatalf("Failed to preview %s.%s: %v", name, locale, err)
+			}
+			if preview.Subject == "" || preview.HTML == "" || preview.Text == "" {
+				t.Fatalf("Expected %s.%s preview to have a subject and bodies", name, locale)
+			}
+		}
+	}
+}
+
+func TestMessageBytes(t *testing.T) {
+	service := NewEmailService()
+	html := `<html><head><style>.a { color: red; }</style></head><body><h1>Stock Report</h1><p>Caf&eacute; ready</p></body></html>`
+	attachment := Attachment{Filename: "report.pdf", ContentType: "application/pdf", Data: bytes.Repeat([]byte("%PDF-1.4 data"), 20)}
+
+	encoded, err := service.newMessage("manager@example.com", "Rapport hebdomadaire – Café", html, []Attachment{attachment}).Bytes()
+	if err != nil {
+		t.Fatalf("Failed to build message: %v", err)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(encoded))
+	if err != nil {
+		t.Fatalf("Failed to parse message: %v", err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != "Rapport hebdomadaire – Café" {
+		t.Fatalf("Expected RFC 2047 encoded subject to round trip, got %q (%v)", subject, err)
+	}
+	if strings.ContainsAny(msg.Header.Get("Subject"), "–é") {
+		t.Fatal("Expected non-ASCII subject to be encoded")
+	}
+	if _, err := msg.Header.Date(); err != nil {
+		t.Fatalf("Expected a valid Date header: %v", err)
+	}
+	if id := msg.Header.Get("Message-ID"); !strings.HasPrefix(id, "<") || !strings.HasSuffix(id, "@pantryos.com>") {
+		t.Fatalf("Expected Message-ID in the sender's domain, got %q", id)
+	}
+	if msg.Header.Get("MIME-Version") != "1.0" {
+		t.Fatal("Expected MIME-Version header")
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("Expected multipart/mixed message, got %q (%v)", mediaType, err)
+	}
+	mixed := multipart.NewReader(msg.Body, params["boundary"])
+
+	// The first part holds the alternative renderings of the body
+	part, err := mixed.NextPart()
+	if err != nil {
+		t.Fatalf("Failed to read body part: %v", err)
+	}
+	mediaType, params, _ = mime.ParseMediaType(part.Header.Get("Content-Type"))
+	if mediaType != "multipart/alternative" {
+		t.Fatalf("Expected multipart/alternative body, got %q", mediaType)
+	}
+	alternative := multipart.NewReader(part, params["boundary"])
+	var bodies []string
+	var contentTypes []string
+	for {
+		body, err := alternative.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read alternative part: %v", err)
+		}
+		if body.Header.Get("Content-Transfer-Encoding") != "quoted-printable" {
+			t.Fatal("Expected quoted-printable bodies")
+		}
+		content, err := io.ReadAll(quotedprintable.NewReader(body))
+		if err != nil {
+			t.Fatalf("Failed to decode body: %v", err)
+		}
+		contentTypes = append(contentTypes, body.Header.Get("Content-Type"))
+		bodies = append(bodies, string(content))
+	}
+	if len(bodies) != 2 || !strings.HasPrefix(contentTypes[0], "text/plain") || !strings.HasPrefix(contentTypes[1], "text/html") {
+		t.Fatalf("Expected plain text then HTML parts, got %v", contentTypes)
+	}
+	if bodies[0] != "Stock Report\r\n\r\nCafé ready\r\n" {
+		t.Fatalf("Unexpected plain text body %q", bodies[0])
+	}
+	if bodies[1] != html {
+		t.Fatal("Expected HTML body to round trip")
+	}
+
+	// The attachment follows, base64 encoded in short lines
+	part, err = mixed.NextPart()
+	if err != nil {
+		t.Fatalf("Failed to read attachment part: %v", err)
+	}
+	if part.FileName() != "report.pdf" || part.Header.Get("Content-Type") != "application/pdf" {
+		t.Fatalf("Unexpected attachment headers %v", part.Header)
+	}
+	raw, _ := io.ReadAll(part)
+	for _, line := range strings.Split(strings.TrimSpace(string(raw)), "\r\n") {
+		if len(line) > 76 {
+			t.Fatalf("Expected base64 lines of at most 76 characters, got %d", len(line))
+		}
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(raw), "\r\n", ""))
+	if err != nil || !bytes.Equal(data, attachment.Data) {
+		t.Fatalf("Expected attachment to round trip (%v)", err)
+	}
+	if _, err := mixed.NextPart(); err != io.EOF {
+		t.Fatal("Expected no further parts")
+	}
+
+	// Without attachments the body is the multipart/alternative itself
+	encoded, err = service.newMessage("manager@example.com", "Plain subject", html, nil).Bytes()
+	if err != nil {
+		t.Fatalf("Failed to build message: %v", err)
+	}
+	msg, _ = mail.ReadMessage(bytes.NewReader(encoded))
+	if msg.Header.Get("Subject") != "Plain subject" {
+		t.Fatal("Expected ASCII subject to be left unencoded")
+	}
+	if _, ok := msg.Header["Reply-To"]; ok {
+		t.Fatal("Expected no Reply-To header unless one is set")
+	}
+	if mediaType, _, _ := mime.ParseMediaType(msg.Header.Get("Content-Type")); mediaType != "multipart/alternative" {
+		t.Fatalf("Expected multipart/alternative message, got %q", mediaType)
+	}
+
+	// Replies can be directed away from the sender
+	message := service.newMessage("orders@vendor.example.com", "Purchase Order", html, nil)
+	message.ReplyTo = "owner@cafe.example.com"
+	encoded, _ = message.Bytes()
+	msg, _ = mail.ReadMessage(bytes.NewReader(encoded))
+	if msg.Header.Get("Reply-To") != "owner@cafe.example.com" {
+		t.Fatalf("Expected Reply-To header, got %q", msg.Header.Get("Reply-To"))
+	}
+
+	// Line breaks in header values cannot inject headers
+	encoded, _ = service.newMessage("manager@example.com\r\nBcc: spy@example.com", "Hi", html, nil).Bytes()
+	msg, _ = mail.ReadMessage(bytes.NewReader(encoded))
+	if msg.Header.Get("Bcc") != "" {
+		t.Fatal("Expected header injection to be prevented")
+	}
+}
+
+func TestHTMLToText(t *testing.T) {
+	service := NewEmailService()
+	_, body, err := service.renderTemplate(0, "weekly_stock_report", "", EmailData{
+		AccountName: "Main Street Cafe",
+		StockReport: &StockReportData{
+			ReportDate: time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC),
+			TotalItems: 1,
+			Items:      []StockItemData{{Name: "Coffee Beans", Category: "Coffee", CurrentStock: 10.5, MinStock: 5, MaxStock: 20, Unit: "kg", Status: "normal"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to render weekly stock report template: %v", err)
+	}
+
+	text := htmlToText(body)
+	for _, expected := range []string{
+		"Weekly Stock Report\n\nMain Street Cafe",
+		"Stock Report for May 6, 2024",
+		"Item | Category | Current Stock | Min Stock | Max Stock | Status\n",
+		"Coffee Beans | Coffee | 10.5 kg | 5 kg | 20 kg | normal\n",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Fatalf("Expected plain text to contain %q, got:\n%s", expected, text)
+		}
+	}
+	if strings.Contains(text, "font-family") || strings.Contains(text, "<") {
+		t.Fatalf("Expected styles and tags to be removed, got:\n%s", text)
+	}
+	if strings.Contains(text, "\n\n\n") {
+		t.Fatal("Expected runs of blank lines to be collapsed")
+	}
+
+	links := htmlToText(`<p>Please <a href="https://example.com/verify?token=a&amp;b=1">verify your account</a>.</p><ul><li>One</li><li>Two</li></ul>`)
+	if links != "Please verify your account (https://example.com/verify?token=a&b=1).\n\n- One\n- Two\n" {
+		t.Fatalf("Unexpected plain text %q", links)
+	}
+}
+
 func TestGetEnvOrDefault(t *testing.T) {
 	// Test with default value
 	result := getEnvOrDefault("NON_EXISTENT_ENV", "default_value")
@@ -0,0 +1,196 @@
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// Attachment is a file attached to an email, such as a PDF or CSV report
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Message is an email ready to be encoded for SMTP delivery
+type Message struct {
+	From        mail.Address
+	To          string
+	ReplyTo     string // Optional
+	Subject     string
+	Date        time.Time
+	MessageID   string
+	HTMLBody    string
+	TextBody    string
+	Attachments []Attachment
+}
+
+// base64LineLength is the maximum length of base64 encoded lines (RFC 2045)
+const base64LineLength = 76
+
+// newMessage builds a message from the configured sender, with a plain text
+// alternative rendered from the HTML body.
+func (es *EmailService) newMessage(to, subject, htmlBody string, attachments []Attachment) *Message {
+	return &Message{
+		From:        mail.Address{Name: es.config.FromName, Address: es.config.FromEmail},
+		To:          to,
+		Subject:     subject,
+		Date:        time.Now(),
+		MessageID:   newMessageID(es.config.FromEmail),
+		HTMLBody:    htmlBody,
+		TextBody:    htmlToText(htmlBody),
+		Attachments: attachments,
+	}
+}
+
+// Bytes encodes the message as MIME. The body is a multipart/alternative of the
+// plain text and HTML renderings, wrapped in multipart/mixed when the message
+// has attachments.
+//
+// Returns:
+//   - []byte: The encoded message, with CRLF line endings
+//   - error: Any error that occurred while encoding the message
+func (m *Message) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+
+	writeHeader(&buf, "From", m.From.String())
+	writeHeader(&buf, "To", m.To)
+	if m.ReplyTo != "" {
+		writeHeader(&buf, "Reply-To", m.ReplyTo)
+	}
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	writeHeader(&buf, "Date", m.Date.Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", m.MessageID)
+	writeHeader(&buf, "MIME-Version", "1.0")
+
+	contentType, body, err := m.alternative()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(m.Attachments) == 0 {
+		writeHeader(&buf, "Content-Type", contentType)
+		buf.WriteString("\r\n")
+		buf.Write(body)
+		return buf.Bytes(), nil
+	}
+
+	mixed := multipart.NewWriter(&buf)
+	writeHeader(&buf, "Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": mixed.Boundary()}))
+	buf.WriteString("\r\n")
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType)
+	part, err := mixed.CreatePart(header)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(body); err != nil {
+		return nil, err
+	}
+	for _, attachment := range m.Attachments {
+		if err := writeAttachment(mixed, attachment); err != nil {
+			return nil, err
+		}
+	}
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// alternative encodes the plain text and HTML renderings as a multipart/alternative
+// body, in order of increasing preference.
+func (m *Message) alternative() (string, []byte, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	bodies := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", m.TextBody},
+		{"text/html; charset=utf-8", m.HTMLBody},
+	}
+	for _, body := range bodies {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", body.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return "", nil, err
+		}
+		encoder := quotedprintable.NewWriter(part)
+		if _, err := encoder.Write([]byte(body.content)); err != nil {
+			return "", nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return "", nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return "", nil, err
+	}
+
+	contentType := mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": writer.Boundary()})
+	return contentType, buf.Bytes(), nil
+}
+
+// writeAttachment writes a base64 encoded attachment part
+func writeAttachment(mixed *multipart.Writer, attachment Attachment) error {
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Transfer-Encoding", "base64")
+	header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	part, err := mixed.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+	for len(encoded) > 0 {
+		n := min(base64LineLength, len(encoded))
+		if _, err := fmt.Fprintf(part, "%s\r\n", encoded[:n]); err != nil {
+			return err
+		}
+		encoded = encoded[n:]
+	}
+	return nil
+}
+
+// writeHeader writes a header line, dropping line breaks that would inject headers
+func writeHeader(buf *bytes.Buffer, key, value string) {
+	value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+	fmt.Fprintf(buf, "%s: %s\r\n", key, value)
+}
+
+// newMessageID returns a unique Message-ID in the domain of the sender address
+func newMessageID(from string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 && at < len(from)-1 {
+		domain = from[at+1:]
+	}
+
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		// crypto/rand does not fail on supported platforms; fall back to the clock
+		return fmt.Sprintf("<%d@%s>", time.Now().UnixNano(), domain)
+	}
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().Unix(), hex.EncodeToString(random), domain)
+}
@@ -0,0 +1,109 @@
+package email
+
+import (
+	"time"
+
+	"github.com/mnadev/pantryos/internal/models"
+)
+
+// SampleData returns template data for previews, with every field of every template filled in.
+//
+// Parameters:
+//   - accountName: The account name shown in the sample emails
+//
+// Returns:
+//   - EmailData: Sample data, dated relative to the current week
+func SampleData(accountName string) EmailData {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 9, 0, 0, 0, now.Location())
+	lastDelivery := today.AddDate(0, 0, -4)
+	periodStart, periodEnd := sampleMonth(today)
+
+	return EmailData{
+		AccountName:     accountName,
+		UserName:        "manager@example.com",
+		UserEmail:       "manager@example.com",
+		VerificationURL: "https://app.pantryos.com/verify?token=sample",
+		StockReport: &StockReportData{
+			ReportDate:      today,
+			TotalItems:      3,
+			LowStockItems:   1,
+			OutOfStockItems: 1,
+			TotalValue:      412.75,
+			Items: []StockItemData{
+				{ID: 1, Name: "Coffee Beans", Category: "Coffee", CurrentStock: 12, MinStock: 5, MaxStock: 30, Unit: "kg", Status: "normal"},
+				{ID: 2, Name: "Whole Milk", Category: "Dairy", CurrentStock: 4, MinStock: 10, MaxStock: 40, Unit: "liters", Status: "low"},
+				{ID: 3, Name: "Vanilla Syrup", Category: "Syrups", CurrentStock: 0, MinStock: 2, MaxStock: 8, Unit: "bottles", Status: "out"},
+			},
+		},
+		SupplyChainReport: &SupplyChainData{
+			ReportDate:        today,
+			TotalItems:        3,
+			LowStockItems:     1,
+			OutOfStockItems:   1,
+			CriticalItems:     1,
+			TotalValue:        412.75,
+			EstimatedReorders: 186.4,
+			Items: []SupplyChainItemData{
+				{ID: 1, Name: "Coffee Beans", Category: "Coffee", CurrentStock: 12, MinStock: 5, MaxStock: 30, Unit: "kg", Status: "normal", PreferredVendor: "Roasters Co", CostPerUnit: 18.5, DaysUntilStockout: 14, LastDeliveryDate: &lastDelivery},
+				{ID: 2, Name: "Whole Milk", Category: "Dairy", CurrentStock: 4, MinStock: 10, MaxStock: 40, Unit: "liters", Status: "critical", PreferredVendor: "Local Dairy", CostPerUnit: 1.2, ReorderQuantity: 36, DaysUntilStockout: 1, LastDeliveryDate: &lastDelivery},
+				{ID: 3, Name: "Vanilla Syrup", Category: "Syrups", MinStock: 2, MaxStock: 8, Unit: "bottles", Status: "out", PreferredVendor: "Flavor Supply", CostPerUnit: 9.75, ReorderQuantity: 8},
+			},
+		},
+		VendorScorecard: &VendorScorecardData{
+			PeriodStart: periodStart,
+			PeriodEnd:   periodEnd,
+			Vendors: []VendorScorecardItemData{
+				{Name: "Roasters Co", OrderLines: 12, OnTimeRate: 91.7, AverageDaysLate: 0.2, FillRate: 100, AveragePriceDrift: 1.5},
+				{Name: "Local Dairy", OrderLines: 20, OnTimeRate: 75, AverageDaysLate: 0.8, FillRate: 95.5, AveragePriceDrift: -0.4},
+			},
+		},
+		Valuation: &InventoryValuationData{
+			PeriodStart:  periodStart,
+			PeriodEnd:    periodEnd,
+			Method:       models.CostingMethodFIFO,
+			OpeningValue: 380.25,
+			Purchases:    1240,
+			ClosingValue: 412.75,
+			COGS:         1207.5,
+			Items: []InventoryValuationItemData{
+				{Name: "Coffee Beans", Unit: "kg", ClosingQuantity: 12, ClosingUnitCost: 18.5, ClosingValue: 222, COGS: 740},
+				{Name: "Whole Milk", Unit: "liters", ClosingQuantity: 4, ClosingUnitCost: 1.2, ClosingValue: 4.8, COGS: 467.5},
+			},
+		},
+		PriceAlerts: []PriceAlertItemData{
+			{ItemName: "Coffee Beans", VendorName: "Roasters Co", PreviousPrice: 17, NewPrice: 18.5, ChangePercent: 8.82},
+		},
+		BudgetAlerts: []BudgetAlertItemData{
+			{CategoryName: "Dairy", Period: models.BudgetPeriodWeekly, PeriodStart: today.AddDate(0, 0, -2), Threshold: 80, Spend: 412, Amount: 500, PercentUsed: 82.4},
+		},
+		LowStockItems: []models.InventoryItem{
+			{ID: 2, Name: "Whole Milk", Unit: "liters", MinStockLevel: 10, MaxStockLevel: 40},
+			{ID: 3, Name: "Vanilla Syrup", Unit: "bottles", MinStockLevel: 2, MaxStockLevel: 8},
+		},
+		ExpiringItems: []ExpiringItemData{
+			{Name: "Whole Milk", LotNumber: "MILK-0412", Quantity: 4, Unit: "liters", ExpirationDate: today.AddDate(0, 0, 2), DaysLeft: 2},
+			{Name: "Cream", LotNumber: "CRM-0398", Quantity: 1, Unit: "liters", ExpirationDate: today.AddDate(0, 0, -1), DaysLeft: -1},
+		},
+		PurchaseOrder: &PurchaseOrderData{
+			Number:          "PO-42-1",
+			VendorName:      "Local Dairy",
+			ContactName:     "Sam Rivera",
+			OrderDate:       today,
+			ExpectedDate:    today.AddDate(0, 0, 2),
+			DeliveryAddress: "123 Main St",
+			Notes:           "Please deliver before 10am.",
+			Total:           51.2,
+			Lines: []PurchaseOrderLineData{
+				{ItemName: "Whole Milk", Unit: "liters", Quantity: 36, UnitCost: 1.2, TotalCost: 43.2},
+				{ItemName: "Cream", Unit: "liters", Quantity: 4, UnitCost: 2, TotalCost: 8, Notes: "Heavy cream"},
+			},
+		},
+	}
+}
+
+// sampleMonth returns the calendar month before today, as monthly reports cover
+func sampleMonth(today time.Time) (time.Time, time.Time) {
+	end := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location()).Add(-time.Nanosecond)
+	return time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, today.Location()), end
+}
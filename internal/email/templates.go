@@ -0,0 +1,338 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mnadev/pantryos/internal/models"
+)
+
+// Email templates
+// The built-in templates are embedded from templates/<name>.html, with translations in
+// templates/<name>.<locale>.html. Accounts can override any template in any language; the
+// overrides are stored in the database and read through a TemplateStore. A template may
+// define a "subject" template for the subject line of the emails it renders.
+
+//go:embed templates/*.html
+var templateFiles embed.FS
+
+// DefaultLocale is the language of templates/<name>.html, used when a user's language
+// has no template
+const DefaultLocale = "en"
+
+// TemplateNames lists the email templates, in the order they are presented to users
+var TemplateNames = []string{
+	models.EmailTypeVerification,
+	models.EmailTypeWeeklyReport,
+	models.EmailTypeWeeklySupplyChain,
+	models.EmailTypeLowStockAlert,
+	models.EmailTypeVendorScorecard,
+	models.EmailTypeInventoryValuation,
+	models.EmailTypePriceAlert,
+	models.EmailTypeBudgetAlert,
+	models.EmailTypeExpiringItems,
+	models.EmailTypePurchaseOrder,
+}
+
+// TemplateStore looks up the templates accounts use in place of the built-in ones
+type TemplateStore interface {
+	// GetEmailTemplateOverride returns an account's override of a template in a
+	// language, or nil when the account has none
+	GetEmailTemplateOverride(accountID int, name, locale string) (*models.EmailTemplate, error)
+}
+
+// localePattern matches normalized language tags such as "en", "es" or "pt-br"
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// builtinTemplates holds the parsed built-in templates by name and locale, e.g.
+// "weekly_stock_report.en". They are parsed once, when the program starts.
+var builtinTemplates = parseBuiltinTemplates()
+
+// parseBuiltinTemplates parses the embedded templates, panicking if any of them is invalid
+func parseBuiltinTemplates() map[string]*template.Template {
+	files, err := fs.Glob(templateFiles, "templates/*.html")
+	if err != nil {
+		panic(err)
+	}
+
+	templates := make(map[string]*template.Template, len(files))
+	for _, file := range files {
+		name, locale, _ := strings.Cut(strings.TrimSuffix(path.Base(file), ".html"), ".")
+		if locale == "" {
+			locale = DefaultLocale
+		}
+		source, err := templateFiles.ReadFile(file)
+		if err != nil {
+			panic(err)
+		}
+		templates[name+"."+locale] = template.Must(template.New(name).Parse(string(source)))
+	}
+	return templates
+}
+
+// cachedTemplate is a parsed override with the time its source last changed
+type cachedTemplate struct {
+	updatedAt time.Time
+	template  *template.Template
+}
+
+// candidateTemplate is one of the templates a render may use, in order of preference
+type candidateTemplate struct {
+	description string
+	template    *template.Template
+	override    bool
+}
+
+// IsTemplate reports whether name is one of the email templates
+func IsTemplate(name string) bool {
+	_, ok := builtinTemplates[name+"."+DefaultLocale]
+	return ok
+}
+
+// BuiltinLocales returns the languages a template has built-in translations in, starting
+// with DefaultLocale.
+func BuiltinLocales(name string) []string {
+	var locales []string
+	for key := range builtinTemplates {
+		if templateName, locale, _ := strings.Cut(key, "."); templateName == name && locale != DefaultLocale {
+			locales = append(locales, locale)
+		}
+	}
+	sort.Strings(locales)
+	return append([]string{DefaultLocale}, locales...)
+}
+
+// NormalizeLocale converts a language tag such as "es_MX" or "pt-BR" to the lowercase,
+// hyphenated form templates are stored under. An empty locale is DefaultLocale.
+//
+// Returns:
+//   - string: The normalized locale
+//   - error: The locale is not a language tag
+func NormalizeLocale(locale string) (string, error) {
+	locale = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(locale)), "_", "-")
+	if locale == "" {
+		return DefaultLocale, nil
+	}
+	if !localePattern.MatchString(locale) {
+		return "", fmt.Errorf("invalid locale %q; use a language tag such as \"en\" or \"es-MX\"", locale)
+	}
+	return locale, nil
+}
+
+// localeCandidates returns the locales to look for templates in, from the most to the
+// least specific: "es-mx" is tried as "es-mx", then "es", then DefaultLocale.
+func localeCandidates(locale string) []string {
+	locale, err := NormalizeLocale(locale)
+	if err != nil {
+		return []string{DefaultLocale}
+	}
+
+	var candidates []string
+	for locale != "" && locale != DefaultLocale {
+		candidates = append(candidates, locale)
+		cut := strings.LastIndex(locale, "-")
+		if cut < 0 {
+			break
+		}
+		locale = locale[:cut]
+	}
+	return append(candidates, DefaultLocale)
+}
+
+// getEmailTemplate returns the source of the built-in HTML template for the given
+// template name in DefaultLocale, or "" for unknown names
+func getEmailTemplate(templateName string) string {
+	source, err := templateFiles.ReadFile("templates/" + path.Base(templateName) + ".html")
+	if err != nil {
+		return ""
+	}
+	return string(source)
+}
+
+// SetTemplateStore makes the service render the overrides in store in place of the
+// built-in templates.
+func (es *EmailService) SetTemplateStore(store TemplateStore) {
+	es.store = store
+}
+
+// candidateTemplates returns the templates a render may use, in order of preference: for
+// each of the locale's candidates, the account's override and then the built-in template.
+// The built-in template in DefaultLocale is always last.
+func (es *EmailService) candidateTemplates(accountID int, name, locale string) ([]candidateTemplate, error) {
+	if !IsTemplate(name) {
+		return nil, fmt.Errorf("unknown email template %q", name)
+	}
+
+	var candidates []candidateTemplate
+	for _, candidate := range localeCandidates(locale) {
+		if es.store != nil && accountID != 0 {
+			override, err := es.store.GetEmailTemplateOverride(accountID, name, candidate)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load template override %s.%s: %w", name, candidate, err)
+			}
+			if override != nil {
+				tmpl, err := es.parseOverride(override)
+				if err != nil {
+					// A broken override never stops emails; the built-in template is used instead
+					fmt.Printf("Skipping email template override %s.%s of account %d: %v\n", name, candidate, accountID, err)
+				} else {
+					candidates = append(candidates, candidateTemplate{fmt.Sprintf("override %s.%s", name, candidate), tmpl, true})
+				}
+			}
+		}
+		if tmpl, ok := builtinTemplates[name+"."+candidate]; ok {
+			candidates = append(candidates, candidateTemplate{name + "." + candidate, tmpl, false})
+		}
+	}
+	return candidates, nil
+}
+
+// parseOverride parses an account's template, reusing the parsed template until the
+// override changes
+func (es *EmailService) parseOverride(override *models.EmailTemplate) (*template.Template, error) {
+	key := fmt.Sprintf("%d/%s.%s", override.AccountID, override.Name, override.Locale)
+
+	es.templatesMu.RLock()
+	cached, ok := es.templates[key]
+	es.templatesMu.RUnlock()
+	if ok && cached.updatedAt.Equal(override.UpdatedAt) {
+		return cached.template, nil
+	}
+
+	tmpl, err := template.New(override.Name).Parse(override.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	es.templatesMu.Lock()
+	es.templates[key] = cachedTemplate{updatedAt: override.UpdatedAt, template: tmpl}
+	es.templatesMu.Unlock()
+	return tmpl, nil
+}
+
+// renderTemplate renders an email template in a language, using the account's overrides
+// in place of the built-in templates.
+//
+// Parameters:
+//   - accountID: The account whose overrides are used; 0 for the built-in templates only
+//   - templateName: One of TemplateNames
+//   - locale: The language of the recipient; unknown languages use DefaultLocale
+//   - data: The template data
+//
+// Returns:
+//   - string: The subject, from the first template in the language fallback order that defines one
+//   - string: The HTML body
+//   - error: An unknown template, or an error executing the built-in template
+func (es *EmailService) renderTemplate(accountID int, templateName, locale string, data EmailData) (string, string, error) {
+	candidates, err := es.candidateTemplates(accountID, templateName, locale)
+	if err != nil {
+		return "", "", err
+	}
+
+	var body bytes.Buffer
+	for i, candidate := range candidates {
+		body.Reset()
+		if err = candidate.template.Execute(&body, data); err != nil {
+			if candidate.override {
+				fmt.Printf("Skipping email template %s of account %d: %v\n", candidate.description, accountID, err)
+				continue
+			}
+			return "", "", fmt.Errorf("failed to execute template %s: %w", candidate.description, err)
+		}
+
+		subject, err := renderSubject(candidates[i:], data)
+		if err != nil {
+			return "", "", err
+		}
+		return subject, body.String(), nil
+	}
+	return "", "", fmt.Errorf("failed to execute template %s: %w", templateName, err)
+}
+
+// renderSubject renders the subject template of the first candidate that defines one
+func renderSubject(candidates []candidateTemplate, data EmailData) (string, error) {
+	for _, candidate := range candidates {
+		subject := candidate.template.Lookup("subject")
+		if subject == nil {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := subject.Execute(&buf, data); err != nil {
+			return "", fmt.Errorf("failed to execute subject of template %s: %w", candidate.description, err)
+		}
+		// The subject is plain text, so undo the HTML escaping and keep it on one line
+		return strings.Join(strings.Fields(html.UnescapeString(buf.String())), " "), nil
+	}
+	return "", nil
+}
+
+// ValidateTemplate checks that a template source parses and renders the sample data of
+// a preview, so broken overrides are rejected before they are saved.
+//
+// Parameters:
+//   - name: One of TemplateNames
+//   - source: The html/template source
+//
+// Returns:
+//   - error: Why the template cannot be used, or nil
+func ValidateTemplate(name, source string) error {
+	if !IsTemplate(name) {
+		return fmt.Errorf("unknown email template %q", name)
+	}
+
+	tmpl, err := template.New(name).Parse(source)
+	if err != nil {
+		return err
+	}
+	data := SampleData("Sample Cafe")
+	if err := tmpl.Execute(io.Discard, data); err != nil {
+		return err
+	}
+	if subject := tmpl.Lookup("subject"); subject != nil {
+		return subject.Execute(io.Discard, data)
+	}
+	return nil
+}
+
+// Preview is a template rendered with sample data
+type Preview struct {
+	Name    string `json:"name"`
+	Locale  string `json:"locale"`
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
+
+// PreviewTemplate renders a template with sample data, as an account's users would
+// receive it in a language.
+//
+// Parameters:
+//   - account: The account whose name and overrides are used
+//   - name: One of TemplateNames
+//   - locale: The language to render
+//
+// Returns:
+//   - *Preview: The subject, HTML body, and plain text body
+//   - error: An unknown template or locale, or a rendering error
+func (es *EmailService) PreviewTemplate(account models.Account, name, locale string) (*Preview, error) {
+	locale, err := NormalizeLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, body, err := es.renderTemplate(account.ID, name, locale, SampleData(account.Name))
+	if err != nil {
+		return nil, err
+	}
+	return &Preview{Name: name, Locale: locale, Subject: subject, HTML: body, Text: htmlToText(body)}, nil
+}
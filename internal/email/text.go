@@ -0,0 +1,136 @@
+package email
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// htmlTag matches an HTML tag, capturing the closing slash, the tag name and the attributes
+var htmlTag = regexp.MustCompile(`(?s)<(/?)([a-zA-Z0-9!]+)([^>]*)>`)
+
+// hrefAttribute matches the link target of an anchor tag
+var hrefAttribute = regexp.MustCompile(`(?i)href\s*=\s*["']([^"']*)["']`)
+
+// paragraphTags are separated from the surrounding text by blank lines
+var paragraphTags = map[string]bool{
+	"p": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+// blockTags start and end on their own line in the plain text rendering
+var blockTags = map[string]bool{"div": true, "br": true, "tr": true, "table": true, "ul": true, "ol": true}
+
+// hiddenTags have content that is not shown to the reader
+var hiddenTags = map[string]bool{"head": true, "style": true, "script": true}
+
+// htmlToText renders an HTML email body as plain text for mail clients that do not
+// display HTML. Headings and paragraphs are separated by blank lines, table cells
+// by " | ", list items are prefixed with "- " and links are followed by their URL.
+//
+// Parameters:
+//   - body: The rendered HTML template
+//
+// Returns:
+//   - string: The plain text rendering
+func htmlToText(body string) string {
+	var out strings.Builder
+	hidden := ""
+	firstCell := true
+	href := ""
+	space := false
+
+	// Runs of whitespace collapse to a single space between words
+	writeText := func(text string) {
+		text = html.UnescapeString(text)
+		words := strings.Fields(text)
+		if len(words) == 0 {
+			space = space || text != ""
+			return
+		}
+		if space || strings.TrimLeftFunc(text, unicode.IsSpace) != text {
+			out.WriteString(" ")
+		}
+		out.WriteString(strings.Join(words, " "))
+		space = strings.TrimRightFunc(text, unicode.IsSpace) != text
+	}
+
+	position := 0
+	for _, match := range htmlTag.FindAllStringSubmatchIndex(body, -1) {
+		if hidden == "" {
+			writeText(body[position:match[0]])
+		}
+		position = match[1]
+
+		closing := match[3] > match[2]
+		name := strings.ToLower(body[match[4]:match[5]])
+		attributes := body[match[6]:match[7]]
+
+		if hidden != "" {
+			if closing && name == hidden {
+				hidden = ""
+			}
+			continue
+		}
+		if hiddenTags[name] && !closing {
+			hidden = name
+			continue
+		}
+
+		switch {
+		case name == "td" || name == "th":
+			if !closing {
+				if !firstCell {
+					out.WriteString(" | ")
+					space = false
+				}
+				firstCell = false
+			}
+		case name == "li":
+			if !closing {
+				out.WriteString("\n- ")
+				space = false
+			}
+		case name == "a":
+			if !closing {
+				href = ""
+				if link := hrefAttribute.FindStringSubmatch(attributes); link != nil {
+					href = html.UnescapeString(link[1])
+				}
+			} else if strings.HasPrefix(href, "http") {
+				out.WriteString(" (" + href + ")")
+				href = ""
+			}
+		case paragraphTags[name]:
+			out.WriteString("\n\n")
+			space = false
+		case blockTags[name]:
+			if name == "tr" {
+				firstCell = true
+			}
+			out.WriteString("\n")
+			space = false
+		}
+	}
+	if hidden == "" {
+		writeText(body[position:])
+	}
+
+	// Trim every line and collapse runs of blank lines
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(out.String(), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			blank = len(lines) > 0
+			continue
+		}
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
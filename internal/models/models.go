@@ -45,6 +45,72 @@ func (c *CountsMap) Scan(value interface{}) error {
 	return json.Unmarshal(bytes, c)
 }
 
+// LocationCountsMap represents inventory quantities broken down by storage location
+// It maps storage location IDs to the quantities counted at that location
+type LocationCountsMap map[int]CountsMap
+
+// Value implements the driver.Valuer interface for JSON serialization
+func (l LocationCountsMap) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	bytes, err := json.Marshal(l)
+	return string(bytes), err
+}
+
+// Scan implements the sql.Scanner interface for JSON deserialization
+func (l *LocationCountsMap) Scan(value interface{}) error {
+	if value == nil {
+		*l = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case string:
+		bytes = []byte(v)
+	case []byte:
+		bytes = v
+	default:
+		return errors.New("cannot scan non-string value into LocationCountsMap")
+	}
+
+	return json.Unmarshal(bytes, l)
+}
+
+// ThresholdList represents percentages of a limit at which alerts are raised
+// It implements JSON serialization for database storage
+type ThresholdList []float64
+
+// Value implements the driver.Valuer interface for JSON serialization
+func (t ThresholdList) Value() (driver.Value, error) {
+	if t == nil {
+		return nil, nil
+	}
+	bytes, err := json.Marshal(t)
+	return string(bytes), err
+}
+
+// Scan implements the sql.Scanner interface for JSON deserialization
+func (t *ThresholdList) Scan(value interface{}) error {
+	if value == nil {
+		*t = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case string:
+		bytes = []byte(v)
+	case []byte:
+		bytes = v
+	default:
+		return errors.New("cannot scan non-string value into ThresholdList")
+	}
+
+	return json.Unmarshal(bytes, t)
+}
+
 // Organization represents a parent entity that can contain multiple accounts
 // This is the top-level entity in the multi-tenant architecture
 // Each organization can have multiple business locations (accounts)
@@ -65,10 +131,13 @@ type Organization struct {
 type User struct {
 	ID         int       `json:"id" gorm:"primaryKey;autoIncrement"`
 	Email      string    `json:"email" gorm:"uniqueIndex;not null"`
-	Password   string    `json:"-" gorm:"not null"` // Omit from JSON responses for security
+	Password   string    `json:"-" gorm:"not null"`                   // Omit from JSON responses for security
+	AccountID  int       `json:"account_id" gorm:"index"`             // The account the user works in
+	Role       string    `json:"role" gorm:"not null;default:'user'"` // user, manager, admin, org_admin
 	FirstName  string    `json:"first_name" gorm:"not null"`
 	LastName   string    `json:"last_name" gorm:"not null"`
 	IsVerified bool      `json:"is_verified" gorm:"not null;default:false"`
+	Language   string    `json:"language"`                                // Preferred email language, e.g. "en" or "es-MX"; empty for the default
 	Status     string    `json:"status" gorm:"not null;default:'active'"` // active, inactive, suspended
 	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime"`
@@ -87,8 +156,14 @@ type Account struct {
 	Email          string    `json:"email"`
 	BusinessType   string    `json:"business_type" gorm:"not null;default:'single_location'"` // single_location, multi_location, enterprise
 	Status         string    `json:"status" gorm:"not null;default:'active'"`                 // active, inactive, suspended
+	CostingMethod  string    `json:"costing_method" gorm:"not null;default:'last'"`           // last, moving_average, fifo
+	PriceAlertPct  float64   `json:"price_alert_pct" gorm:"not null;default:10"`              // Alert when a vendor's price moves more than this percentage; 0 disables
 	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	// Invoices are approved for payment when their quantities and prices are within these percentages
+	// of what was received and ordered
+	InvoiceQuantityTolerancePct float64 `json:"invoice_quantity_tolerance_pct" gorm:"not null;default:0"`
+	InvoicePriceTolerancePct    float64 `json:"invoice_price_tolerance_pct" gorm:"not null;default:2"`
 	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
 }
 
@@ -149,17 +224,192 @@ type RolePermission struct {
 // Categories help organize items for better management and reporting
 // Each category belongs to a specific account for proper scoping
 type Category struct {
+	ID          int    `json:"id" gorm:"primaryKey;autoIncrement"`
+	AccountID   int    `json:"account_id" gorm:"not null;index"`
+	Name        string `json:"name" gorm:"not null"`
+	Description string `json:"description"`
+	Color       string `json:"color" gorm:"default:'#6B7280'"` // Hex color for UI display
+	IsActive    bool   `json:"is_active" gorm:"not null;default:true"`
+	// CatalogCategoryID links the category to the organization catalog it is synced from
+	CatalogCategoryID *int      `json:"catalog_category_id" gorm:"index"`
+	CreatedAt         time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
+// Vendor represents a supplier that delivers inventory items to an account
+// Vendors replace the free-text vendor names previously stored on items, deliveries,
+// and order items so that spelling differences no longer split a supplier's history
+type Vendor struct {
+	ID                int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	AccountID         int       `json:"account_id" gorm:"not null;index"`
+	Name              string    `json:"name" gorm:"not null"` // e.g., "Coffee Supply Co."
+	ContactName       string    `json:"contact_name"`
+	Email             string    `json:"email"`
+	Phone             string    `json:"phone"`
+	LeadTimeDays      int       `json:"lead_time_days" gorm:"default:0"`      // Days between placing an order and delivery
+	DeliveryDays      string    `json:"delivery_days" gorm:"default:''"`      // Comma-separated weekdays, e.g., "mon,thu"
+	MinimumOrderValue float64   `json:"minimum_order_value" gorm:"default:0"` // Minimum order total accepted by the vendor
+	Notes             string    `json:"notes"`
+	IsActive          bool      `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt         time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
+// StorageLocation represents a place within an account where stock is kept
+// e.g., "Walk-in", "Dry Storage", "Front Bar"
+// The default location receives deliveries without a location and supplies sales
+type StorageLocation struct {
 	ID          int       `json:"id" gorm:"primaryKey;autoIncrement"`
 	AccountID   int       `json:"account_id" gorm:"not null;index"`
 	Name        string    `json:"name" gorm:"not null"`
 	Description string    `json:"description"`
-	Color       string    `json:"color" gorm:"default:'#6B7280'"` // Hex color for UI display
+	IsDefault   bool      `json:"is_default" gorm:"not null;default:false"`
 	IsActive    bool      `json:"is_active" gorm:"not null;default:true"`
 	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
 }
 
+// LocationTransfer records stock moved between two storage locations of the same account
+// Transfers change the stock at each location but not the account's total stock
+type LocationTransfer struct {
+	ID              int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	AccountID       int       `json:"account_id" gorm:"not null;index"`
+	InventoryItemID int       `json:"inventory_item_id" gorm:"not null;index" binding:"required"`
+	FromLocationID  int       `json:"from_location_id" gorm:"not null;index" binding:"required"`
+	ToLocationID    int       `json:"to_location_id" gorm:"not null;index" binding:"required"`
+	Quantity        float64   `json:"quantity" gorm:"not null" binding:"required"`
+	Notes           string    `json:"notes"`
+	TransferredBy   *int      `json:"transferred_by"`
+	TransferredAt   time.Time `json:"transferred_at" gorm:"not null;index"`
+	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
+// StockTransfer moves inventory from one account to another account of the same organization
+// Stock leaves the source when the transfer is sent and arrives at the destination when it is
+// received, valued at the source item's cost per unit at the time it was sent
+type StockTransfer struct {
+	ID                    int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrganizationID        int        `json:"organization_id" gorm:"not null;index"`
+	FromAccountID         int        `json:"from_account_id" gorm:"not null;index"`
+	ToAccountID           int        `json:"to_account_id" gorm:"not null;index" binding:"required"`
+	FromInventoryItemID   int        `json:"from_inventory_item_id" gorm:"not null;index" binding:"required"`
+	ToInventoryItemID     *int       `json:"to_inventory_item_id" gorm:"index"` // Matched by name on receipt when not set
+	FromStorageLocationID *int       `json:"from_storage_location_id"`
+	ToStorageLocationID   *int       `json:"to_storage_location_id"`
+	Quantity              float64    `json:"quantity" gorm:"not null" binding:"required"`
+	ReceivedQuantity      float64    `json:"received_quantity"`                           // Less than Quantity when stock was lost in transit
+	UnitCost              float64    `json:"unit_cost"`                                   // Source item's cost per unit when sent
+	Status                string     `json:"status" gorm:"not null;default:'in_transit'"` // in_transit, received, cancelled
+	Notes                 string     `json:"notes"`
+	SentBy                *int       `json:"sent_by"`
+	SentAt                time.Time  `json:"sent_at" gorm:"not null;index"`
+	ReceivedBy            *int       `json:"received_by"`
+	ReceivedAt            *time.Time `json:"received_at" gorm:"index"`
+	CreatedAt             time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt             time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
+// StockTransferLot records how much of a source lot a stock transfer took when it was sent
+// Cancelling the transfer returns the quantity to the same lots
+type StockTransferLot struct {
+	ID              int     `json:"id" gorm:"primaryKey;autoIncrement"`
+	StockTransferID int     `json:"stock_transfer_id" gorm:"not null;index"`
+	InventoryLotID  int     `json:"inventory_lot_id" gorm:"not null;index"`
+	Quantity        float64 `json:"quantity" gorm:"not null;default:0"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
+// CatalogCategory is a category in an organization's master catalog
+// Subscribed accounts get a local Category linked to each active catalog category
+type CatalogCategory struct {
+	ID             int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrganizationID int       `json:"organization_id" gorm:"not null;index"`
+	Name           string    `json:"name" gorm:"not null" binding:"required"`
+	Description    string    `json:"description"`
+	Color          string    `json:"color" gorm:"default:'#6B7280'"`
+	IsActive       bool      `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
+// CatalogItem is an inventory item in an organization's master catalog
+// Cost and preferred vendor only seed new local items; each account keeps its own values afterwards
+type CatalogItem struct {
+	ID                int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrganizationID    int       `json:"organization_id" gorm:"not null;index"`
+	CatalogCategoryID *int      `json:"catalog_category_id" gorm:"index"`
+	Name              string    `json:"name" gorm:"not null" binding:"required"`
+	Unit              string    `json:"unit" gorm:"not null" binding:"required"`
+	CostPerUnit       float64   `json:"cost_per_unit" gorm:"not null;default:0"`
+	PreferredVendor   string    `json:"preferred_vendor" gorm:"default:''"`
+	MinStockLevel     float64   `json:"min_stock_level" gorm:"default:0"`
+	MaxStockLevel     float64   `json:"max_stock_level" gorm:"default:0"`
+	ShelfLifeDays     int       `json:"shelf_life_days" gorm:"default:0"`
+	IsActive          bool      `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt         time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
+// CatalogMenuItem is a menu item in an organization's master catalog
+// Its recipe is stored as CatalogRecipeIngredient rows referencing catalog items
+type CatalogMenuItem struct {
+	ID                int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrganizationID    int       `json:"organization_id" gorm:"not null;index"`
+	CatalogCategoryID *int      `json:"catalog_category_id" gorm:"index"`
+	Name              string    `json:"name" gorm:"not null" binding:"required"`
+	Price             float64   `json:"price" gorm:"not null;default:0"`
+	IsActive          bool      `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt         time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
+// CatalogRecipeIngredient links a catalog menu item to the catalog items it uses
+type CatalogRecipeIngredient struct {
+	ID                int     `json:"id" gorm:"primaryKey;autoIncrement"`
+	CatalogMenuItemID int     `json:"catalog_menu_item_id" gorm:"not null;index"`
+	CatalogItemID     int     `json:"catalog_item_id" gorm:"not null;index" binding:"required"`
+	Quantity          float64 `json:"quantity" gorm:"not null;default:0" binding:"required"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
+// CatalogSubscription subscribes an account to its organization's master catalog
+// Subscribed accounts receive catalog changes as they are made
+type CatalogSubscription struct {
+	ID             int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrganizationID int        `json:"organization_id" gorm:"not null;index"`
+	AccountID      int        `json:"account_id" gorm:"not null;uniqueIndex"`
+	IsActive       bool       `json:"is_active" gorm:"not null;default:true"`
+	LastSyncedAt   *time.Time `json:"last_synced_at"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
+// CatalogSyncConflict records a catalog change that could not be applied to an account
+// Conflicts are replaced on every sync, so they always describe the latest sync
+type CatalogSyncConflict struct {
+	ID              int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrganizationID  int       `json:"organization_id" gorm:"not null;index"`
+	AccountID       int       `json:"account_id" gorm:"not null;index"`
+	EntityType      string    `json:"entity_type" gorm:"not null"` // category, item, menu_item
+	CatalogEntityID int       `json:"catalog_entity_id" gorm:"not null"`
+	LocalEntityID   *int      `json:"local_entity_id"`
+	Field           string    `json:"field" gorm:"not null"` // e.g., "name", "unit", "recipe"
+	CatalogValue    string    `json:"catalog_value"`
+	LocalValue      string    `json:"local_value"`
+	Reason          string    `json:"reason"`
+	DetectedAt      time.Time `json:"detected_at" gorm:"not null"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
 // InventoryItem represents a physical item that can be tracked in inventory
 // Each item belongs to a specific account and has stock level management
 // Items can be ingredients, supplies, or any consumable resource
@@ -170,12 +420,15 @@ type InventoryItem struct {
 	Unit            string  `json:"unit" gorm:"not null"` // e.g., "kg", "liters", "pieces"
 	CostPerUnit     float64 `json:"cost_per_unit" gorm:"not null;default:0"`
 	PreferredVendor string  `json:"preferred_vendor" gorm:"default:''"` // Default supplier for this item
+	VendorID        *int    `json:"vendor_id" gorm:"index"`             // Optional link to the preferred Vendor
 	MinStockLevel   float64 `json:"min_stock_level" gorm:"default:0"`   // Alert when stock goes below this
 	MaxStockLevel   float64 `json:"max_stock_level" gorm:"default:0"`   // Don't order more than this
 	MinWeeksStock   float64 `json:"min_weeks_stock" gorm:"default:2"`   // Minimum weeks of stock to maintain
 	MaxWeeksStock   float64 `json:"max_weeks_stock" gorm:"default:8"`   // Maximum weeks of stock to maintain
 	CategoryID      *int    `json:"category_id" gorm:"index"`           // Optional category assignment
 	WastageRate     float64 `json:"wastage_rate" gorm:"default:0"`      // Wastage rate as a percentage
+	ShelfLifeDays   int     `json:"shelf_life_days" gorm:"default:0"`   // Default expiration for new lots; 0 means the item does not expire
+	CatalogItemID   *int    `json:"catalog_item_id" gorm:"index"`       // Organization catalog item this item is synced from
 	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
 }
 
@@ -189,6 +442,8 @@ type MenuItem struct {
 	Price      float64 `json:"price" gorm:"not null;default:0"`
 	Category   string  `json:"category"`                 // e.g., "drinks", "food", "desserts"
 	CategoryID *int    `json:"category_id" gorm:"index"` // Optional category assignment
+	// CatalogMenuItemID links the menu item to the organization catalog it is synced from
+	CatalogMenuItemID *int `json:"catalog_menu_item_id" gorm:"index"`
 	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
 }
 
@@ -211,6 +466,20 @@ type InventorySnapshot struct {
 	AccountID int       `json:"account_id" gorm:"not null;index"`
 	Timestamp time.Time `json:"timestamp" gorm:"not null;index"`
 	Counts    CountsMap `json:"counts" gorm:"type:text"` // map[InventoryItemID]quantity - stored as JSON
+	// LocationCounts breaks the counts down by storage location; Counts always holds the item totals
+	LocationCounts LocationCountsMap `json:"location_counts,omitempty" gorm:"type:text"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
+// InventoryLevel holds the current stock of an inventory item
+// Levels are maintained by every stock movement so current stock can be read without
+// replaying history; they can be rebuilt from snapshots and movements at any time
+type InventoryLevel struct {
+	ID              int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	AccountID       int       `json:"account_id" gorm:"not null;index"`
+	InventoryItemID int       `json:"inventory_item_id" gorm:"not null;uniqueIndex"`
+	Quantity        float64   `json:"quantity" gorm:"not null;default:0"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
 }
 
@@ -218,13 +487,173 @@ type InventorySnapshot struct {
 // This tracks when items are received and their associated costs
 // Used for inventory replenishment and cost tracking
 type Delivery struct {
+	ID              int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	AccountID       int        `json:"account_id" gorm:"not null;index"`
+	InventoryItemID int        `json:"inventory_item_id" gorm:"not null;index"`
+	Vendor          string     `json:"vendor" gorm:"not null"` // e.g., "Coffee Supply Co.", "Local Dairy"
+	VendorID        *int       `json:"vendor_id" gorm:"index"` // Optional link to the Vendor record
+	Quantity        float64    `json:"quantity" gorm:"not null;default:0"`
+	DeliveryDate    time.Time  `json:"delivery_date" gorm:"not null;index"`
+	Cost            float64    `json:"cost" gorm:"not null;default:0"`
+	LotNumber       string     `json:"lot_number"`      // Supplier lot or batch number, if printed on the goods
+	Notes           string     `json:"notes"`           // Free-text remarks made when receiving the goods
+	ExpirationDate  *time.Time `json:"expiration_date"` // Defaults to the delivery date plus the item's shelf life
+	// StorageLocationID is where the delivery was put away; defaults to the account's default location
+	StorageLocationID *int `json:"storage_location_id" gorm:"index"`
+	// OrderItemID is the order item the delivery was received against, if any
+	OrderItemID *int `json:"order_item_id" gorm:"index"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
+// InventoryLot represents a quantity of an inventory item received in a single delivery
+// Lots are consumed oldest first so that expiring stock can be tracked and used before it spoils
+type InventoryLot struct {
+	ID                int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	AccountID         int        `json:"account_id" gorm:"not null;index"`
+	InventoryItemID   int        `json:"inventory_item_id" gorm:"not null;index"`
+	DeliveryID        *int       `json:"delivery_id" gorm:"index"`
+	LotNumber         string     `json:"lot_number" gorm:"not null"`
+	ReceivedAt        time.Time  `json:"received_at" gorm:"not null;index"`
+	ExpirationDate    *time.Time `json:"expiration_date" gorm:"index"`
+	InitialQuantity   float64    `json:"initial_quantity" gorm:"not null;default:0"`
+	RemainingQuantity float64    `json:"remaining_quantity" gorm:"not null;default:0"`
+	CreatedAt         time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
+// CountSession represents a guided physical stock count
+// Staff record partial counts by zone while the session is open; finalizing the
+// session produces an InventorySnapshot, and abandoned sessions produce nothing
+type CountSession struct {
+	ID         int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	AccountID  int        `json:"account_id" gorm:"not null;index"`
+	Name       string     `json:"name"`
+	Notes      string     `json:"notes"`
+	Status     string     `json:"status" gorm:"not null;default:'open';index"` // "open", "finalized", "abandoned"
+	StartedBy  *int       `json:"started_by"`
+	StartedAt  time.Time  `json:"started_at" gorm:"not null"`
+	ClosedBy   *int       `json:"closed_by"`   // User who finalized or abandoned the session
+	ClosedAt   *time.Time `json:"closed_at"`   // When the session was finalized or abandoned
+	SnapshotID *int       `json:"snapshot_id"` // Snapshot produced when the session was finalized
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
+// CountEntry records the quantity of one inventory item counted in one zone during a count session
+// Recounting the same item in the same zone replaces the earlier entry
+type CountEntry struct {
+	ID              int    `json:"id" gorm:"primaryKey;autoIncrement"`
+	CountSessionID  int    `json:"count_session_id" gorm:"not null;index"`
+	AccountID       int    `json:"account_id" gorm:"not null;index"`
+	InventoryItemID int    `json:"inventory_item_id" gorm:"not null;index" binding:"required"`
+	Zone            string `json:"zone"` // Area counted within the location, e.g. "top shelf"
+	// StorageLocationID is the location counted; counts without a location are unassigned stock
+	StorageLocationID *int      `json:"storage_location_id" gorm:"index"`
+	Quantity          float64   `json:"quantity" gorm:"not null;default:0"`
+	CountedBy         *int      `json:"counted_by"`
+	CountedAt         time.Time `json:"counted_at" gorm:"not null"`
+	CreatedAt         time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
+// WasteLog records a quantity of an inventory item that was discarded and why
+// Waste reduces current stock and feeds the item's wastage rate and the waste cost report
+type WasteLog struct {
+	ID              int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	AccountID       int       `json:"account_id" gorm:"not null;index"`
+	InventoryItemID int       `json:"inventory_item_id" gorm:"not null;index" binding:"required"`
+	Quantity        float64   `json:"quantity" gorm:"not null" binding:"required"`
+	Reason          string    `json:"reason" gorm:"not null;index" binding:"required"` // "expired", "spilled", "comped", "overproduced"
+	Notes           string    `json:"notes"`
+	UnitCost        float64   `json:"unit_cost" gorm:"not null;default:0"` // Item cost per unit when the waste was recorded
+	RecordedBy      *int      `json:"recorded_by"`                         // User who recorded the waste
+	WastedAt        time.Time `json:"wasted_at" gorm:"not null;index"`
+	// StorageLocationID is where the stock was discarded from; defaults to the account's default location
+	StorageLocationID *int      `json:"storage_location_id" gorm:"index"`
+	CreatedAt         time.Time `json:"created_at" gorm:"autoCreateTime"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
+// StockAdjustment records an ad-hoc change to the stock of an inventory item outside of
+// counts, deliveries, sales, and waste. Adjustments are never edited or deleted; a mistake
+// is corrected by recording an opposite adjustment, so the entries form an audit trail
+type StockAdjustment struct {
+	ID              int     `json:"id" gorm:"primaryKey;autoIncrement"`
+	AccountID       int     `json:"account_id" gorm:"not null;index"`
+	InventoryItemID int     `json:"inventory_item_id" gorm:"not null;index" binding:"required"`
+	Quantity        float64 `json:"quantity" gorm:"not null" binding:"required"`     // Signed change in stock, negative when stock is removed
+	Reason          string  `json:"reason" gorm:"not null;index" binding:"required"` // "count_correction", "breakage", "internal_use", "sample"
+	Notes           string  `json:"notes"`
+	UnitCost        float64 `json:"unit_cost" gorm:"not null;default:0"` // Item cost per unit when the adjustment was recorded
+	AdjustedBy      *int    `json:"adjusted_by"`                         // User who recorded the adjustment
+	// StorageLocationID is where the stock changed; defaults to the account's default location
+	StorageLocationID *int      `json:"storage_location_id" gorm:"index"`
+	AdjustedAt        time.Time `json:"adjusted_at" gorm:"not null;index"`
+	CreatedAt         time.Time `json:"created_at" gorm:"autoCreateTime"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
+// PriceHistory records the unit price paid for an inventory item on a delivery
+// Item costs are recomputed from this history using the account's costing method
+type PriceHistory struct {
 	ID              int       `json:"id" gorm:"primaryKey;autoIncrement"`
 	AccountID       int       `json:"account_id" gorm:"not null;index"`
 	InventoryItemID int       `json:"inventory_item_id" gorm:"not null;index"`
-	Vendor          string    `json:"vendor" gorm:"not null"` // e.g., "Coffee Supply Co.", "Local Dairy"
+	VendorID        *int      `json:"vendor_id" gorm:"index"`
+	DeliveryID      *int      `json:"delivery_id" gorm:"index"`
 	Quantity        float64   `json:"quantity" gorm:"not null;default:0"`
-	DeliveryDate    time.Time `json:"delivery_date" gorm:"not null;index"`
-	Cost            float64   `json:"cost" gorm:"not null;default:0"`
+	UnitPrice       float64   `json:"unit_price" gorm:"not null;default:0"`
+	RecordedAt      time.Time `json:"recorded_at" gorm:"not null;index"`
+	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
+// PriceAlert flags a delivery whose unit price moved beyond the account's alert threshold
+// compared to the previous price paid to the same vendor for the same item
+type PriceAlert struct {
+	ID              int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	AccountID       int        `json:"account_id" gorm:"not null;index"`
+	InventoryItemID int        `json:"inventory_item_id" gorm:"not null;index"`
+	VendorID        *int       `json:"vendor_id" gorm:"index"`
+	DeliveryID      *int       `json:"delivery_id"`
+	PreviousPrice   float64    `json:"previous_price" gorm:"not null"`
+	NewPrice        float64    `json:"new_price" gorm:"not null"`
+	ChangePercent   float64    `json:"change_percent" gorm:"not null"`
+	NotifiedAt      *time.Time `json:"notified_at"` // Set once the alert has been emailed
+	CreatedAt       time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
+// Budget represents the purchasing budget of a category for each week or month
+// Spend is measured from the deliveries received or the orders placed for the category's items
+type Budget struct {
+	ID              int           `json:"id" gorm:"primaryKey;autoIncrement"`
+	AccountID       int           `json:"account_id" gorm:"not null;uniqueIndex:idx_budget_category"`
+	CategoryID      int           `json:"category_id" gorm:"not null;uniqueIndex:idx_budget_category"`
+	Period          string        `json:"period" gorm:"not null;uniqueIndex:idx_budget_category"` // weekly, monthly
+	Amount          float64       `json:"amount" gorm:"not null"`
+	SpendSource     string        `json:"spend_source" gorm:"not null;default:'deliveries'"` // deliveries, orders
+	AlertThresholds ThresholdList `json:"alert_thresholds" gorm:"type:text"`                 // Percentages of the amount that raise an alert, e.g. [80, 100]
+	CreatedAt       time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
+// BudgetAlert records a category's spend crossing a threshold of its budget during a period
+// Each threshold raises at most one alert per period
+type BudgetAlert struct {
+	ID          int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	AccountID   int        `json:"account_id" gorm:"not null;index"`
+	BudgetID    int        `json:"budget_id" gorm:"not null;index"`
+	PeriodStart time.Time  `json:"period_start" gorm:"not null"`
+	Threshold   float64    `json:"threshold" gorm:"not null"` // The percentage of the budget crossed
+	Spend       float64    `json:"spend" gorm:"not null"`     // Spend in the period when the alert was raised
+	Amount      float64    `json:"amount" gorm:"not null"`    // The budget amount when the alert was raised
+	NotifiedAt  *time.Time `json:"notified_at"`               // Set once the alert has been emailed
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
 	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
 }
 
@@ -252,17 +681,37 @@ type SaleItem struct {
 // Orders go through various statuses from pending to delivered
 // They can be created by users and approved by managers
 type Order struct {
-	ID           int       `json:"id" gorm:"primaryKey;autoIncrement"`
-	AccountID    int       `json:"account_id" gorm:"not null;index"`
-	Status       string    `json:"status" gorm:"not null;default:'pending'"` // pending, approved, ordered, delivered, cancelled
-	OrderDate    time.Time `json:"order_date" gorm:"not null"`
-	ExpectedDate time.Time `json:"expected_date"`
-	TotalCost    float64   `json:"total_cost" gorm:"not null;default:0"`
-	Notes        string    `json:"notes"`
-	CreatedBy    int       `json:"created_by" gorm:"not null"`
-	ApprovedBy   *int      `json:"approved_by"`
-	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	ID           int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	AccountID    int        `json:"account_id" gorm:"not null;index"`
+	Status       string     `json:"status" gorm:"not null;default:'pending'"` // pending, approved, ordered, partially_received, delivered, cancelled
+	OrderDate    time.Time  `json:"order_date" gorm:"not null"`
+	ExpectedDate time.Time  `json:"expected_date"`
+	TotalCost    float64    `json:"total_cost" gorm:"not null;default:0"`
+	Notes        string     `json:"notes"`
+	CreatedBy    int        `json:"created_by" gorm:"not null"`
+	ApprovedBy   *int       `json:"approved_by"`
+	DispatchedAt *time.Time `json:"dispatched_at"` // When the purchase orders were last emailed to the vendors
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
+// OrderDispatch records a purchase order emailed to a vendor
+// An order placed with several vendors has one dispatch per vendor each time it is sent
+// Purchase orders that could not be sent, e.g. to vendors without an email address, are recorded as failed
+type OrderDispatch struct {
+	ID       int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrderID  int       `json:"order_id" gorm:"not null;index"`
+	VendorID *int      `json:"vendor_id" gorm:"index"` // Null for items ordered without a vendor record
+	Vendor   string    `json:"vendor" gorm:"not null"`
+	Number   string    `json:"number" gorm:"not null"` // Purchase order number, e.g., "PO-42-1"
+	ToEmail  string    `json:"to_email"`
+	ReplyTo  string    `json:"reply_to"`
+	Subject  string    `json:"subject"`
+	Status   string    `json:"status" gorm:"not null"` // sent, failed
+	ErrorMsg string    `json:"error_msg"`
+	SentBy   int       `json:"sent_by" gorm:"not null"`
+	SentAt   time.Time `json:"sent_at" gorm:"autoCreateTime"`
 	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
 }
 
@@ -277,7 +726,80 @@ type OrderItem struct {
 	UnitCost        float64 `json:"unit_cost" gorm:"not null;default:0"`
 	TotalCost       float64 `json:"total_cost" gorm:"not null;default:0"`
 	Vendor          string  `json:"vendor" gorm:"not null"`
+	VendorID        *int    `json:"vendor_id" gorm:"index"` // Optional link to the Vendor record
 	Notes           string  `json:"notes"`
+	// ReceivedQuantity is the quantity received against this item so far, including substitutes
+	ReceivedQuantity float64 `json:"received_quantity" gorm:"not null;default:0"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
+// Receipt records goods received against an order
+// An order can be received in several receipts when part of it arrives later
+// Each line of a receipt creates a delivery of what actually arrived
+type Receipt struct {
+	ID         int           `json:"id" gorm:"primaryKey;autoIncrement"`
+	AccountID  int           `json:"account_id" gorm:"not null;index"`
+	OrderID    int           `json:"order_id" gorm:"not null;index"`
+	ReceivedAt time.Time     `json:"received_at" gorm:"not null;index"`
+	ReceivedBy int           `json:"received_by" gorm:"not null"`
+	Notes      string        `json:"notes"`
+	Lines      []ReceiptLine `json:"lines" gorm:"-"`
+	CreatedAt  time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
+// ReceiptLine records what arrived for one item of an order
+// The expected quantity and unit cost are what was outstanding and agreed when the goods arrived
+type ReceiptLine struct {
+	ID               int     `json:"id" gorm:"primaryKey;autoIncrement"`
+	ReceiptID        int     `json:"receipt_id" gorm:"not null;index"`
+	OrderItemID      int     `json:"order_item_id" gorm:"not null;index"`
+	OrderedItemID    int     `json:"ordered_item_id" gorm:"not null"`   // The inventory item on the order
+	InventoryItemID  int     `json:"inventory_item_id" gorm:"not null"` // The inventory item received; differs for substitutions
+	ExpectedQuantity float64 `json:"expected_quantity" gorm:"not null;default:0"`
+	ReceivedQuantity float64 `json:"received_quantity" gorm:"not null;default:0"`
+	ExpectedUnitCost float64 `json:"expected_unit_cost" gorm:"not null;default:0"`
+	UnitCost         float64 `json:"unit_cost" gorm:"not null;default:0"`
+	DeliveryID       *int    `json:"delivery_id" gorm:"index"` // Null when nothing arrived
+	Notes            string  `json:"notes"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
+// Invoice represents a vendor's bill for goods ordered and received
+// Invoices are matched against their order and deliveries before they are paid
+type Invoice struct {
+	ID            int           `json:"id" gorm:"primaryKey;autoIncrement"`
+	AccountID     int           `json:"account_id" gorm:"not null;index"`
+	Vendor        string        `json:"vendor" gorm:"not null"`
+	VendorID      *int          `json:"vendor_id" gorm:"index"`
+	InvoiceNumber string        `json:"invoice_number" gorm:"not null;index"` // The vendor's invoice number
+	OrderID       *int          `json:"order_id" gorm:"index"`                // The order the invoice bills, if any
+	InvoiceDate   time.Time     `json:"invoice_date" gorm:"not null"`
+	DueDate       *time.Time    `json:"due_date"`
+	Total         float64       `json:"total" gorm:"not null;default:0"`
+	Status        string        `json:"status" gorm:"not null;default:'pending';index"` // pending, approved, needs_review
+	MatchedAt     *time.Time    `json:"matched_at"`                                     // When the invoice was last matched
+	ApprovedAt    *time.Time    `json:"approved_at"`
+	ApprovedBy    *int          `json:"approved_by"` // Null when the match approved the invoice
+	Notes         string        `json:"notes"`
+	CreatedBy     int           `json:"created_by" gorm:"not null"`
+	Lines         []InvoiceLine `json:"lines" gorm:"-"`
+	CreatedAt     time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
+// InvoiceLine represents a single charge on an invoice
+type InvoiceLine struct {
+	ID          int     `json:"id" gorm:"primaryKey;autoIncrement"`
+	InvoiceID   int     `json:"invoice_id" gorm:"not null;index"`
+	OrderItemID *int    `json:"order_item_id" gorm:"index"` // The order item billed
+	DeliveryID  *int    `json:"delivery_id" gorm:"index"`   // The delivery billed
+	Description string  `json:"description"`
+	Quantity    float64 `json:"quantity" gorm:"not null"`
+	UnitCost    float64 `json:"unit_cost" gorm:"not null;default:0"`
+	TotalCost   float64 `json:"total_cost" gorm:"not null;default:0"`
+	MatchStatus string  `json:"match_status" gorm:"not null;default:'pending'"` // pending, matched, mismatch
 	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
 }
 
@@ -434,7 +956,7 @@ type EmailLog struct {
 type EmailSchedule struct {
 	ID         int        `json:"id" gorm:"primaryKey;autoIncrement"`
 	AccountID  int        `json:"account_id" gorm:"not null;index"`
-	EmailType  string     `json:"email_type" gorm:"not null"`  // "weekly_stock_report", "low_stock_alert"
+	EmailType  string     `json:"email_type" gorm:"not null"`  // "weekly_stock_report", "low_stock_alert", "monthly_vendor_scorecard"
 	Frequency  string     `json:"frequency" gorm:"not null"`   // "weekly", "daily", "monthly"
 	DayOfWeek  *int       `json:"day_of_week"`                 // 0-6 (Sunday-Saturday) for weekly
 	DayOfMonth *int       `json:"day_of_month"`                // 1-31 for monthly
@@ -446,6 +968,19 @@ type EmailSchedule struct {
 	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
 }
 
+// EmailTemplate is an account's replacement for one of the built-in email templates in a
+// language. Templates are html/template sources that may define a "subject" template.
+type EmailTemplate struct {
+	ID        int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	AccountID int       `json:"account_id" gorm:"not null;uniqueIndex:idx_email_template"`
+	Name      string    `json:"name" gorm:"not null;uniqueIndex:idx_email_template"`   // e.g. "weekly_stock_report"
+	Locale    string    `json:"locale" gorm:"not null;uniqueIndex:idx_email_template"` // e.g. "en", "es"
+	Source    string    `json:"source" gorm:"type:text;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	// Note: Foreign key relationships are handled in application logic for ramsql compatibility
+}
+
 // Email status constants
 const (
 	EmailStatusSent    = "sent"
@@ -455,13 +990,89 @@ const (
 
 // Email type constants
 const (
-	EmailTypeVerification      = "verification"
-	EmailTypeWeeklyReport      = "weekly_stock_report"
-	EmailTypeWeeklySupplyChain = "weekly_supply_chain_report"
-	EmailTypeLowStockAlert     = "low_stock_alert"
-	EmailTypePasswordReset     = "password_reset"
-	EmailTypeAccountInvite     = "account_invite"
-	EmailTypeOrgInvite         = "organization_invite"
+	EmailTypeVerification       = "verification"
+	EmailTypeWeeklyReport       = "weekly_stock_report"
+	EmailTypeWeeklySupplyChain  = "weekly_supply_chain_report"
+	EmailTypeLowStockAlert      = "low_stock_alert"
+	EmailTypeVendorScorecard    = "monthly_vendor_scorecard"
+	EmailTypeInventoryValuation = "monthly_inventory_valuation"
+	EmailTypePriceAlert         = "price_alert"
+	EmailTypeBudgetAlert        = "budget_alert"
+	EmailTypeExpiringItems      = "expiring_items"
+	EmailTypePurchaseOrder      = "purchase_order"
+	EmailTypePasswordReset      = "password_reset"
+	EmailTypeAccountInvite      = "account_invite"
+	EmailTypeOrgInvite          = "organization_invite"
+)
+
+// Costing method constants
+const (
+	CostingMethodLast          = "last"
+	CostingMethodMovingAverage = "moving_average"
+	CostingMethodFIFO          = "fifo"
+)
+
+// Count session status constants
+const (
+	CountSessionStatusOpen      = "open"
+	CountSessionStatusFinalized = "finalized"
+	CountSessionStatusAbandoned = "abandoned"
+)
+
+// Stock transfer status constants
+const (
+	StockTransferStatusInTransit = "in_transit"
+	StockTransferStatusReceived  = "received"
+	StockTransferStatusCancelled = "cancelled"
+)
+
+// Budget period constants
+const (
+	BudgetPeriodWeekly  = "weekly"
+	BudgetPeriodMonthly = "monthly"
+)
+
+// Budget spend source constants
+const (
+	BudgetSpendDeliveries = "deliveries" // Spend is the cost of deliveries received in the period
+	BudgetSpendOrders     = "orders"     // Spend is the cost of orders placed in the period
+)
+
+// Invoice status constants
+const (
+	InvoiceStatusPending     = "pending"
+	InvoiceStatusApproved    = "approved" // Approved for payment
+	InvoiceStatusNeedsReview = "needs_review"
+)
+
+// Invoice line match status constants
+const (
+	InvoiceLineMatchPending  = "pending"
+	InvoiceLineMatchMatched  = "matched"
+	InvoiceLineMatchMismatch = "mismatch"
+)
+
+// Catalog entity type constants used by sync conflicts
+const (
+	CatalogEntityCategory = "category"
+	CatalogEntityItem     = "item"
+	CatalogEntityMenuItem = "menu_item"
+)
+
+// Waste reason constants
+const (
+	WasteReasonExpired      = "expired"
+	WasteReasonSpilled      = "spilled"
+	WasteReasonComped       = "comped"
+	WasteReasonOverproduced = "overproduced"
+)
+
+// Stock adjustment reason constants
+const (
+	AdjustmentReasonCountCorrection = "count_correction"
+	AdjustmentReasonBreakage        = "breakage"
+	AdjustmentReasonInternalUse     = "internal_use"
+	AdjustmentReasonSample          = "sample"
 )
 
 // Token type constants
@@ -0,0 +1,394 @@
+// Package pdf renders reports and purchase orders as PDF documents.
+// Documents only use the standard Helvetica fonts that every PDF reader provides,
+// so no font files are embedded and no external libraries are needed.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Page geometry, in points. Pages are US Letter with the same margin on every side.
+const (
+	pageWidth    = 612.0
+	pageHeight   = 792.0
+	margin       = 50.0
+	contentWidth = pageWidth - 2*margin
+	footerHeight = 20.0
+)
+
+// Font is one of the standard fonts available to every document
+type Font struct {
+	resource string
+	name     string
+	widths   *[95]int // Advance widths of the printable ASCII characters, in thousandths of the font size
+}
+
+// The fonts documents are written with
+var (
+	Regular = Font{resource: "F1", name: "Helvetica", widths: &helveticaWidths}
+	Bold    = Font{resource: "F2", name: "Helvetica-Bold", widths: &helveticaBoldWidths}
+)
+
+// helveticaWidths are the widths of characters 32 to 126 in Helvetica
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// helveticaBoldWidths are the widths of characters 32 to 126 in Helvetica-Bold
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
+
+// defaultWidth is used for characters outside printable ASCII
+const defaultWidth = 556
+
+// ellipsis is the WinAnsi code of "…", which shortened text ends with
+const ellipsis = 0x85
+
+// TextWidth returns the width of text set in a font at a size, in points
+func TextWidth(text string, font Font, size float64) float64 {
+	var total int
+	for _, b := range encode(text) {
+		switch {
+		case b >= 32 && b <= 126:
+			total += font.widths[b-32]
+		case b == ellipsis:
+			total += 1000
+		default:
+			total += defaultWidth
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// Document is a PDF document laid out top to bottom. Content flows onto a new page
+// when the current one is full, and every page gets a footer with the document
+// title and page number when the document is written.
+type Document struct {
+	title string
+	pages []*bytes.Buffer
+	page  *bytes.Buffer
+	y     float64 // Distance of the next line from the top of the page
+}
+
+// NewDocument starts a document with a single empty page.
+//
+// Parameters:
+//   - title: The document title, shown in page footers and the reader's title bar
+//
+// Returns:
+//   - *Document: The document, ready for content
+func NewDocument(title string) *Document {
+	d := &Document{title: title}
+	d.AddPage()
+	return d
+}
+
+// AddPage starts a new page; further content is placed at its top
+func (d *Document) AddPage() {
+	d.page = &bytes.Buffer{}
+	d.pages = append(d.pages, d.page)
+	d.y = margin
+}
+
+// PageCount returns the number of pages in the document
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// ensureSpace starts a new page unless height points still fit on the current one.
+// It reports whether a page was added.
+func (d *Document) ensureSpace(height float64) bool {
+	if d.y+height <= pageHeight-margin-footerHeight || d.y == margin {
+		return false
+	}
+	d.AddPage()
+	return true
+}
+
+// Title adds the large heading at the top of a document
+func (d *Document) Title(text string) {
+	d.ensureSpace(28)
+	d.y += 18
+	d.text(margin, d.y, Bold, 18, fit(text, Bold, 18, contentWidth))
+	d.y += 10
+}
+
+// Heading adds a section heading
+func (d *Document) Heading(text string) {
+	d.ensureSpace(40)
+	d.y += 16
+	d.text(margin, d.y, Bold, 12, fit(text, Bold, 12, contentWidth))
+	d.y += 8
+}
+
+// Paragraph adds text wrapped to the width of the page
+func (d *Document) Paragraph(text string) {
+	for _, line := range wrap(text, Regular, 10, contentWidth) {
+		d.ensureSpace(14)
+		d.y += 12
+		d.text(margin, d.y, Regular, 10, line)
+		d.y += 2
+	}
+}
+
+// Field adds a line with a bold label followed by its value
+func (d *Document) Field(label, value string) {
+	d.ensureSpace(14)
+	d.y += 12
+	label += ": "
+	width := TextWidth(label, Bold, 10)
+	d.text(margin, d.y, Bold, 10, label)
+	d.text(margin+width, d.y, Regular, 10, fit(value, Regular, 10, contentWidth-width))
+	d.y += 2
+}
+
+// Spacer adds empty vertical space
+func (d *Document) Spacer(height float64) {
+	d.y += height
+}
+
+// Column describes a table column
+type Column struct {
+	Header string
+	Width  float64 // Share of the table width, relative to the other columns
+	Right  bool    // Right-align the column, for numbers
+}
+
+// Table adds a table that spans the width of the page. The header row is repeated
+// on every page the table continues onto, and cells too wide for their column are
+// shortened with an ellipsis.
+func (d *Document) Table(columns []Column, rows [][]string) {
+	const (
+		size      = 9.0
+		rowHeight = 16.0
+		padding   = 4.0
+	)
+
+	var totalWeight float64
+	for _, column := range columns {
+		totalWeight += column.Width
+	}
+	widths := make([]float64, len(columns))
+	for i, column := range columns {
+		widths[i] = contentWidth * column.Width / totalWeight
+	}
+
+	drawRow := func(cells []string, font Font) {
+		x := margin
+		for i, column := range columns {
+			if i < len(cells) {
+				text := fit(cells[i], font, size, widths[i]-2*padding)
+				left := x + padding
+				if column.Right {
+					left = x + widths[i] - padding - TextWidth(text, font, size)
+				}
+				d.text(left, d.y+rowHeight-5, font, size, text)
+			}
+			x += widths[i]
+		}
+		d.y += rowHeight
+	}
+	drawHeader := func() {
+		d.fillRect(margin, d.y, contentWidth, rowHeight, 0.92)
+		headers := make([]string, len(columns))
+		for i, column := range columns {
+			headers[i] = column.Header
+		}
+		drawRow(headers, Bold)
+	}
+
+	d.ensureSpace(2 * rowHeight)
+	d.y += padding
+	drawHeader()
+	for _, row := range rows {
+		if d.ensureSpace(rowHeight) {
+			drawHeader()
+		}
+		drawRow(row, Regular)
+		d.line(margin, d.y, margin+contentWidth, d.y)
+	}
+	d.y += padding
+}
+
+// text draws text with its baseline y points from the top of the page
+func (d *Document) text(x, y float64, font Font, size float64, text string) {
+	fmt.Fprintf(d.page, "BT /%s %s Tf %s %s Td (%s) Tj ET\n", font.resource, number(size), number(x), number(pageHeight-y), escape(encode(text)))
+}
+
+// fillRect fills a rectangle whose top edge is y points from the top of the page
+func (d *Document) fillRect(x, y, width, height, gray float64) {
+	fmt.Fprintf(d.page, "q %s g %s %s %s %s re f Q\n", number(gray), number(x), number(pageHeight-y-height), number(width), number(height))
+}
+
+// line draws a thin light rule
+func (d *Document) line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(d.page, "q 0.8 G 0.5 w %s %s m %s %s l S Q\n", number(x1), number(pageHeight-y1), number(x2), number(pageHeight-y2))
+}
+
+// Bytes returns the finished document
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := d.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteTo writes the finished document, adding the page footers.
+// The document can be written more than once.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(format string, args ...interface{}) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n", len(offsets))
+		fmt.Fprintf(&out, format, args...)
+		out.WriteString("\nendobj\n")
+	}
+
+	// Objects 1 to 5 come first, followed by a page object and its contents for each page
+	const firstPageObject = 6
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPageObject+2*i)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", Regular.name)
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", Bold.name)
+	object("<< /Title (%s) /Producer (PantryOS) >>", escape(encode(d.title)))
+
+	for i, page := range d.pages {
+		var content bytes.Buffer
+		content.Write(page.Bytes())
+		footer := &Document{page: &content}
+		footer.text(margin, pageHeight-margin+footerHeight, Regular, 8, fit(d.title, Regular, 8, contentWidth-80))
+		pageNumber := fmt.Sprintf("Page %d of %d", i+1, len(d.pages))
+		footer.text(pageWidth-margin-TextWidth(pageNumber, Regular, 8), pageHeight-margin+footerHeight, Regular, 8, pageNumber)
+
+		var compressed bytes.Buffer
+		writer := zlib.NewWriter(&compressed)
+		if _, err := writer.Write(content.Bytes()); err != nil {
+			return 0, err
+		}
+		if err := writer.Close(); err != nil {
+			return 0, err
+		}
+
+		object("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			number(pageWidth), number(pageHeight), firstPageObject+2*i+1)
+		object("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", compressed.Len(), compressed.Bytes())
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.WriteTo(w)
+}
+
+// winAnsi maps the characters of Windows-1252 outside Latin-1 to their codes
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b,
+	'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+// encode converts text to the WinAnsi encoding of the standard fonts.
+// Line breaks become spaces and characters the fonts cannot show become "?".
+func encode(text string) []byte {
+	encoded := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r == '\n' || r == '\r' || r == '\t':
+			encoded = append(encoded, ' ')
+		case r >= 32 && r <= 126, r >= 0xa0 && r <= 0xff:
+			encoded = append(encoded, byte(r))
+		case winAnsi[r] != 0:
+			encoded = append(encoded, winAnsi[r])
+		default:
+			encoded = append(encoded, '?')
+		}
+	}
+	return encoded
+}
+
+// escape escapes encoded text for use in a PDF string literal
+func escape(encoded []byte) string {
+	var escaped strings.Builder
+	for _, b := range encoded {
+		if b == '\\' || b == '(' || b == ')' {
+			escaped.WriteByte('\\')
+		}
+		escaped.WriteByte(b)
+	}
+	return escaped.String()
+}
+
+// number formats a coordinate or size without trailing zeros
+func number(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// fit shortens text with an ellipsis until it fits within width
+func fit(text string, font Font, size, width float64) string {
+	if TextWidth(text, font, size) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		shortened := strings.TrimRight(string(runes), " ") + "…"
+		if TextWidth(shortened, font, size) <= width {
+			return shortened
+		}
+	}
+	return ""
+}
+
+// wrap breaks text into lines no wider than width, splitting at spaces.
+// Words wider than a whole line are shortened with an ellipsis.
+func wrap(text string, font Font, size, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if TextWidth(candidate, font, size) <= width || line == "" {
+				line = candidate
+				continue
+			}
+			lines = append(lines, fit(line, font, size, width))
+			line = word
+		}
+		if line != "" || strings.TrimSpace(paragraph) == "" {
+			lines = append(lines, fit(line, font, size, width))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,156 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/email"
+	"github.com/mnadev/pantryos/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pageContents checks the structure of a document and returns the decompressed
+// content stream of each page
+func pageContents(t *testing.T, document []byte) []string {
+	require.True(t, bytes.HasPrefix(document, []byte("%PDF-1.4\n")))
+	require.True(t, bytes.HasSuffix(document, []byte("%%EOF\n")))
+
+	// Every cross-reference entry must point at the start of its object
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(document)
+	require.NotNil(t, startxref)
+	xref, err := strconv.Atoi(string(startxref[1]))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(document[xref:], []byte("xref\n")))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(document[xref:], -1)
+	for i, entry := range entries {
+		offset, err := strconv.Atoi(string(entry[1]))
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(document[offset:], []byte(fmt.Sprintf("%d 0 obj\n", i+1))), "object %d", i+1)
+	}
+
+	var pages []string
+	streams := regexp.MustCompile(`(?s)/Length (\d+) /Filter /FlateDecode >>\nstream\n`).FindAllSubmatchIndex(document, -1)
+	for _, match := range streams {
+		length, err := strconv.Atoi(string(document[match[2]:match[3]]))
+		require.NoError(t, err)
+		reader, err := zlib.NewReader(bytes.NewReader(document[match[1] : match[1]+length]))
+		require.NoError(t, err)
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		pages = append(pages, string(content))
+	}
+	return pages
+}
+
+func TestDocument(t *testing.T) {
+	doc := NewDocument("Inventory (Main)")
+	doc.Title("Inventory")
+	doc.Paragraph("Counted by the morning crew, including the walk-in and dry storage. Values are at cost.")
+
+	rows := make([][]string, 80)
+	for i := range rows {
+		rows[i] = []string{fmt.Sprintf("Item %d", i+1), "A very long description that cannot fit in its narrow column", "12"}
+	}
+	doc.Table([]Column{{Header: "Item", Width: 2}, {Header: "Notes", Width: 1}, {Header: "Qty", Width: 1, Right: true}}, rows)
+	require.Equal(t, 3, doc.PageCount())
+
+	document, err := doc.Bytes()
+	require.NoError(t, err)
+	pages := pageContents(t, document)
+	require.Len(t, pages, 3)
+
+	assert.Contains(t, pages[0], "(Inventory) Tj")
+	assert.Contains(t, pages[0], "(Item 1) Tj")
+	assert.Contains(t, pages[0], "(Page 1 of 3) Tj")
+	assert.Contains(t, pages[0], "(Inventory \\(Main\\)) Tj", "footer title is escaped")
+	assert.Contains(t, pages[1], "(Qty) Tj", "header row repeats on continued pages")
+	assert.Contains(t, pages[2], "(Item 80) Tj")
+	assert.Contains(t, pages[0], "\x85) Tj", "long cells end with an ellipsis")
+	assert.Contains(t, string(document), "/Title (Inventory \\(Main\\))")
+}
+
+func TestEncode(t *testing.T) {
+	assert.Equal(t, []byte("Caf\xe9 \x80 5 \x96 6 ?"), encode("Café € 5 – 6 ☕"))
+	assert.Equal(t, []byte("a b"), encode("a\nb"))
+	assert.Equal(t, `\(a\\b\)`, escape([]byte(`(a\b)`)))
+}
+
+func TestLayoutText(t *testing.T) {
+	assert.InDelta(t, 22.78, TextWidth("Hello", Regular, 10), 0.001)
+	assert.Greater(t, TextWidth("Hello", Bold, 10), TextWidth("Hello", Regular, 10))
+
+	assert.Equal(t, "Hello", fit("Hello", Regular, 10, 100))
+	shortened := fit("Hello world, this is long", Regular, 10, 50)
+	assert.True(t, strings.HasSuffix(shortened, "…"))
+	assert.LessOrEqual(t, TextWidth(shortened, Regular, 10), 50.0)
+
+	lines := wrap("one two three four five six", Regular, 10, 60)
+	assert.Greater(t, len(lines), 1)
+	assert.Equal(t, "one two three four five six", strings.Join(lines, " "))
+}
+
+func TestReports(t *testing.T) {
+	delivered := time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)
+
+	t.Run("Stock Report", func(t *testing.T) {
+		document, err := StockReport("Main Street Cafe", &email.StockReportData{
+			ReportDate: time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC),
+			TotalItems: 1,
+			TotalValue: 37.5,
+			Items:      []email.StockItemData{{Name: "Whole Milk", Category: "Dairy", CurrentStock: 15, MinStock: 5, MaxStock: 40, Unit: "liters", Status: "normal"}},
+		})
+		require.NoError(t, err)
+		content := strings.Join(pageContents(t, document), "")
+		assert.Contains(t, content, "(Main Street Cafe - May 6, 2024) Tj")
+		assert.Contains(t, content, "($37.50) Tj")
+		assert.Contains(t, content, "(15 liters) Tj")
+		assert.Contains(t, content, "(Normal) Tj")
+	})
+
+	t.Run("Supply Chain Report", func(t *testing.T) {
+		document, err := SupplyChainReport("Main Street Cafe", &email.SupplyChainData{
+			ReportDate: time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC),
+			Items: []email.SupplyChainItemData{{
+				Name: "Coffee Beans", CurrentStock: 2, Unit: "kg", Status: "critical", PreferredVendor: "Roasters Co",
+				CostPerUnit: 18, ReorderQuantity: 8, DaysUntilStockout: 3, LastDeliveryDate: &delivered,
+			}},
+		})
+		require.NoError(t, err)
+		content := strings.Join(pageContents(t, document), "")
+		assert.Contains(t, content, "(Roasters Co) Tj")
+		assert.Contains(t, content, "(Critical) Tj")
+		assert.Contains(t, content, "(May 2, 2024) Tj")
+	})
+
+	t.Run("Purchase Orders", func(t *testing.T) {
+		order := models.Order{ID: 42, OrderDate: delivered, Notes: "Deliver to the back door"}
+		account := models.Account{Name: "Main Street Cafe", Location: "1 Main St"}
+		document, err := PurchaseOrders([]database.PurchaseOrder{
+			{
+				Number: "PO-42-1", Order: order, Account: account,
+				Vendor: &models.Vendor{Name: "Local Dairy", Email: "orders@dairy.example"},
+				Lines:  []database.PurchaseOrderLine{{ItemName: "Whole Milk", Unit: "liters", Quantity: 20, UnitCost: 1.25, TotalCost: 25}},
+				Total:  25,
+			},
+			{Number: "PO-42-2", Order: order, Account: account, Lines: []database.PurchaseOrderLine{{ItemName: "Napkins", Quantity: 1}}},
+		})
+		require.NoError(t, err)
+		pages := pageContents(t, document)
+		require.Len(t, pages, 2, "each purchase order starts on a new page")
+		assert.Contains(t, pages[0], "(Purchase Order PO-42-1) Tj")
+		assert.Contains(t, pages[0], "(orders@dairy.example) Tj")
+		assert.Contains(t, pages[0], "(1 Main St) Tj")
+		assert.Contains(t, pages[0], "($25.00) Tj")
+		assert.Contains(t, pages[0], "(Deliver to the back door) Tj")
+		assert.Contains(t, pages[1], "(No vendor assigned) Tj")
+	})
+}
@@ -0,0 +1,216 @@
+package pdf
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/email"
+)
+
+// ContentType is the MIME type of PDF documents
+const ContentType = "application/pdf"
+
+// dateLayout is how dates are printed in documents
+const dateLayout = "January 2, 2006"
+
+// StockReport renders the weekly stock report of an account.
+//
+// Parameters:
+//   - accountName: The name of the account the report is for
+//   - data: The report data, as sent in the weekly stock report email
+//
+// Returns:
+//   - []byte: The PDF document
+//   - error: Any error that occurred while writing the document
+func StockReport(accountName string, data *email.StockReportData) ([]byte, error) {
+	doc := NewDocument(fmt.Sprintf("Weekly Stock Report - %s", accountName))
+	doc.Title("Weekly Stock Report")
+	doc.Paragraph(fmt.Sprintf("%s - %s", accountName, data.ReportDate.Format(dateLayout)))
+
+	doc.Heading("Summary")
+	doc.Field("Total Items", strconv.Itoa(data.TotalItems))
+	doc.Field("Low Stock Items", strconv.Itoa(data.LowStockItems))
+	doc.Field("Out of Stock Items", strconv.Itoa(data.OutOfStockItems))
+	doc.Field("Total Inventory Value", money(data.TotalValue))
+
+	doc.Heading("Inventory")
+	rows := make([][]string, 0, len(data.Items))
+	for _, item := range data.Items {
+		rows = append(rows, []string{
+			item.Name,
+			item.Category,
+			quantity(item.CurrentStock) + " " + item.Unit,
+			quantity(item.MinStock),
+			quantity(item.MaxStock),
+			status(item.Status),
+		})
+	}
+	doc.Table([]Column{
+		{Header: "Item", Width: 3},
+		{Header: "Category", Width: 2},
+		{Header: "Current Stock", Width: 2, Right: true},
+		{Header: "Min", Width: 1, Right: true},
+		{Header: "Max", Width: 1, Right: true},
+		{Header: "Status", Width: 1.2},
+	}, rows)
+
+	return doc.Bytes()
+}
+
+// SupplyChainReport renders the weekly supply chain report of an account.
+//
+// Parameters:
+//   - accountName: The name of the account the report is for
+//   - data: The report data, as sent in the weekly supply chain email
+//
+// Returns:
+//   - []byte: The PDF document
+//   - error: Any error that occurred while writing the document
+func SupplyChainReport(accountName string, data *email.SupplyChainData) ([]byte, error) {
+	doc := NewDocument(fmt.Sprintf("Weekly Supply Chain Report - %s", accountName))
+	doc.Title("Weekly Supply Chain Report")
+	doc.Paragraph(fmt.Sprintf("%s - %s", accountName, data.ReportDate.Format(dateLayout)))
+
+	doc.Heading("Summary")
+	doc.Field("Total Items", strconv.Itoa(data.TotalItems))
+	doc.Field("Critical Items", strconv.Itoa(data.CriticalItems))
+	doc.Field("Low Stock Items", strconv.Itoa(data.LowStockItems))
+	doc.Field("Out of Stock Items", strconv.Itoa(data.OutOfStockItems))
+	doc.Field("Total Inventory Value", money(data.TotalValue))
+	doc.Field("Estimated Reorder Cost", money(data.EstimatedReorders))
+
+	doc.Heading("Inventory")
+	rows := make([][]string, 0, len(data.Items))
+	for _, item := range data.Items {
+		daysLeft := ""
+		if item.Status == "low" || item.Status == "critical" {
+			daysLeft = strconv.Itoa(item.DaysUntilStockout)
+		}
+		lastDelivery := ""
+		if item.LastDeliveryDate != nil {
+			lastDelivery = item.LastDeliveryDate.Format("Jan 2, 2006")
+		}
+		rows = append(rows, []string{
+			item.Name,
+			quantity(item.CurrentStock) + " " + item.Unit,
+			status(item.Status),
+			item.PreferredVendor,
+			money(item.CostPerUnit),
+			quantity(item.ReorderQuantity),
+			daysLeft,
+			lastDelivery,
+		})
+	}
+	doc.Table([]Column{
+		{Header: "Item", Width: 2.6},
+		{Header: "Stock", Width: 1.6, Right: true},
+		{Header: "Status", Width: 1.2},
+		{Header: "Vendor", Width: 2},
+		{Header: "Unit Cost", Width: 1.3, Right: true},
+		{Header: "Reorder", Width: 1.2, Right: true},
+		{Header: "Days Left", Width: 1.1, Right: true},
+		{Header: "Last Delivery", Width: 1.6},
+	}, rows)
+
+	return doc.Bytes()
+}
+
+// PurchaseOrders renders purchase orders for vendors, each starting on a new page.
+//
+// Parameters:
+//   - purchaseOrders: The purchase orders to include
+//
+// Returns:
+//   - []byte: The PDF document
+//   - error: Any error that occurred while writing the document
+func PurchaseOrders(purchaseOrders []database.PurchaseOrder) ([]byte, error) {
+	title := "Purchase Order"
+	if len(purchaseOrders) == 1 {
+		title = fmt.Sprintf("Purchase Order %s - %s", purchaseOrders[0].Number, purchaseOrders[0].Account.Name)
+	}
+	doc := NewDocument(title)
+
+	for i, purchaseOrder := range purchaseOrders {
+		if i > 0 {
+			doc.AddPage()
+		}
+		order := purchaseOrder.Order
+		account := purchaseOrder.Account
+
+		doc.Title("Purchase Order " + purchaseOrder.Number)
+		doc.Field("Order Date", order.OrderDate.Format(dateLayout))
+		if !order.ExpectedDate.IsZero() {
+			doc.Field("Requested Delivery", order.ExpectedDate.Format(dateLayout))
+		}
+
+		doc.Heading("Vendor")
+		if vendor := purchaseOrder.Vendor; vendor != nil {
+			doc.Paragraph(vendor.Name)
+			for _, detail := range []string{vendor.ContactName, vendor.Email, vendor.Phone} {
+				if detail != "" {
+					doc.Paragraph(detail)
+				}
+			}
+		} else {
+			doc.Paragraph("No vendor assigned")
+		}
+
+		doc.Heading("Ship To")
+		doc.Paragraph(account.Name)
+		for _, detail := range []string{account.Location, account.Phone, account.Email} {
+			if detail != "" {
+				doc.Paragraph(detail)
+			}
+		}
+
+		doc.Heading("Items")
+		rows := make([][]string, 0, len(purchaseOrder.Lines)+1)
+		for _, line := range purchaseOrder.Lines {
+			rows = append(rows, []string{
+				line.ItemName,
+				line.Notes,
+				quantity(line.Quantity),
+				line.Unit,
+				money(line.UnitCost),
+				money(line.TotalCost),
+			})
+		}
+		rows = append(rows, []string{"Total", "", "", "", "", money(purchaseOrder.Total)})
+		doc.Table([]Column{
+			{Header: "Item", Width: 3},
+			{Header: "Notes", Width: 2.5},
+			{Header: "Quantity", Width: 1.2, Right: true},
+			{Header: "Unit", Width: 1},
+			{Header: "Unit Cost", Width: 1.3, Right: true},
+			{Header: "Total", Width: 1.4, Right: true},
+		}, rows)
+
+		if order.Notes != "" {
+			doc.Heading("Notes")
+			doc.Paragraph(order.Notes)
+		}
+	}
+
+	return doc.Bytes()
+}
+
+// money formats an amount in dollars
+func money(amount float64) string {
+	return fmt.Sprintf("$%.2f", amount)
+}
+
+// quantity formats a quantity with at most two decimals and no trailing zeros
+func quantity(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
+
+// status capitalizes a stock status for display
+func status(value string) string {
+	if value == "" {
+		return ""
+	}
+	return strings.ToUpper(value[:1]) + value[1:]
+}
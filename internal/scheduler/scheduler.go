@@ -1,13 +1,17 @@
 package scheduler
 
 import (
+	"bytes"
 	"fmt"
 	"log"
+	"math"
 	"time"
 
 	"github.com/mnadev/pantryos/internal/database"
 	"github.com/mnadev/pantryos/internal/email"
 	"github.com/mnadev/pantryos/internal/models"
+	"github.com/mnadev/pantryos/internal/pdf"
+	"github.com/mnadev/pantryos/internal/spreadsheet"
 )
 
 // Scheduler handles automated tasks like sending weekly stock reports
@@ -20,10 +24,13 @@ type Scheduler struct {
 
 // NewScheduler creates a new scheduler instance
 func NewScheduler(db *database.DB) *Scheduler {
+	service := database.NewService(db)
+	emailService := email.NewEmailService()
+	emailService.SetTemplateStore(service)
 	return &Scheduler{
 		db:           db,
-		service:      database.NewService(db),
-		emailService: email.NewEmailService(),
+		service:      service,
+		emailService: emailService,
 		stopChan:     make(chan bool),
 	}
 }
@@ -41,6 +48,21 @@ func (s *Scheduler) Start() {
 	// Start low stock alert scheduler
 	go s.scheduleLowStockAlerts()
 
+	// Start monthly vendor scorecard scheduler
+	go s.scheduleVendorScorecards()
+
+	// Start monthly inventory valuation scheduler
+	go s.scheduleInventoryValuations()
+
+	// Start vendor price alert scheduler
+	go s.schedulePriceAlerts()
+
+	// Start category budget alert scheduler
+	go s.scheduleBudgetAlerts()
+
+	// Start expiring items alert scheduler
+	go s.scheduleExpiringItemsAlerts()
+
 	log.Println("Email scheduler started successfully")
 }
 
@@ -95,6 +117,81 @@ func (s *Scheduler) scheduleWeeklySupplyChainReports() {
 	}
 }
 
+// scheduleVendorScorecards schedules monthly vendor scorecard emails
+func (s *Scheduler) scheduleVendorScorecards() {
+	ticker := time.NewTicker(time.Hour) // Check hourly so the scheduled hour is not missed
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			s.sendVendorScorecards()
+		}
+	}
+}
+
+// scheduleInventoryValuations schedules monthly inventory valuation emails
+func (s *Scheduler) scheduleInventoryValuations() {
+	ticker := time.NewTicker(time.Hour) // Check hourly so the scheduled hour is not missed
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			s.sendInventoryValuations()
+		}
+	}
+}
+
+// schedulePriceAlerts schedules vendor price alert emails
+func (s *Scheduler) schedulePriceAlerts() {
+	ticker := time.NewTicker(time.Hour) // Check every hour
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			s.sendPriceAlerts()
+		}
+	}
+}
+
+// scheduleBudgetAlerts schedules category budget alert emails
+func (s *Scheduler) scheduleBudgetAlerts() {
+	ticker := time.NewTicker(time.Hour) // Check every hour
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			s.sendBudgetAlerts()
+		}
+	}
+}
+
+// scheduleExpiringItemsAlerts schedules expiring items alert emails
+func (s *Scheduler) scheduleExpiringItemsAlerts() {
+	ticker := time.NewTicker(time.Hour) // Check hourly so the scheduled hour is not missed
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			s.sendExpiringItemsAlerts()
+		}
+	}
+}
+
 // sendWeeklyStockReports sends weekly stock reports to all accounts
 func (s *Scheduler) sendWeeklyStockReports() {
 	log.Println("Checking for weekly stock reports to send...")
@@ -161,6 +258,92 @@ func (s *Scheduler) sendWeeklySupplyChainReports() {
 	}
 }
 
+// sendVendorScorecards sends monthly vendor scorecards to accounts that have opted in
+func (s *Scheduler) sendVendorScorecards() {
+	log.Println("Checking for vendor scorecards to send...")
+
+	// Get all accounts
+	accounts, err := s.service.GetAllAccounts()
+	if err != nil {
+		log.Printf("Failed to get accounts for vendor scorecards: %v", err)
+		return
+	}
+
+	for _, account := range accounts {
+		if s.shouldSendVendorScorecard(account.ID, time.Now()) {
+			s.sendVendorScorecardForAccount(account)
+		}
+	}
+}
+
+// sendInventoryValuations sends monthly inventory valuations to accounts that have opted in
+func (s *Scheduler) sendInventoryValuations() {
+	log.Println("Checking for inventory valuations to send...")
+
+	// Get all accounts
+	accounts, err := s.service.GetAllAccounts()
+	if err != nil {
+		log.Printf("Failed to get accounts for inventory valuations: %v", err)
+		return
+	}
+
+	for _, account := range accounts {
+		if s.shouldSendInventoryValuation(account.ID, time.Now()) {
+			s.sendInventoryValuationForAccount(account)
+		}
+	}
+}
+
+// sendPriceAlerts sends pending vendor price alerts to all accounts
+func (s *Scheduler) sendPriceAlerts() {
+	log.Println("Checking for price alerts to send...")
+
+	// Get all accounts
+	accounts, err := s.service.GetAllAccounts()
+	if err != nil {
+		log.Printf("Failed to get accounts for price alerts: %v", err)
+		return
+	}
+
+	for _, account := range accounts {
+		s.sendPriceAlertForAccount(account)
+	}
+}
+
+// sendBudgetAlerts checks every account's budgets and sends the alerts they raise
+func (s *Scheduler) sendBudgetAlerts() {
+	log.Println("Checking for budget alerts to send...")
+
+	// Get all accounts
+	accounts, err := s.service.GetAllAccounts()
+	if err != nil {
+		log.Printf("Failed to get accounts for budget alerts: %v", err)
+		return
+	}
+
+	for _, account := range accounts {
+		s.sendBudgetAlertForAccount(account, time.Now())
+	}
+}
+
+// sendExpiringItemsAlerts sends expiring items alerts to all accounts
+func (s *Scheduler) sendExpiringItemsAlerts() {
+	log.Println("Checking for expiring items alerts to send...")
+
+	// Get all accounts
+	accounts, err := s.service.GetAllAccounts()
+	if err != nil {
+		log.Printf("Failed to get accounts for expiring items alerts: %v", err)
+		return
+	}
+
+	for _, account := range accounts {
+		if s.shouldSendExpiringItemsAlert(account.ID, time.Now()) {
+			s.sendExpiringItemsAlertForAccount(account)
+		}
+	}
+}
+
 // shouldSendWeeklyReport checks if it's time to send a weekly report for an account
 func (s *Scheduler) shouldSendWeeklyReport(accountID int) bool {
 	// Get account-specific email schedule
@@ -297,6 +480,85 @@ func (s *Scheduler) shouldSendWeeklySupplyChainReport(accountID int) bool {
 	return true
 }
 
+// shouldSendVendorScorecard checks if it's time to send a monthly vendor scorecard for an account.
+// Scorecards are optional, so nothing is sent unless the account has an active schedule.
+func (s *Scheduler) shouldSendVendorScorecard(accountID int, now time.Time) bool {
+	return s.shouldSendMonthlyReport(accountID, models.EmailTypeVendorScorecard, now)
+}
+
+// shouldSendInventoryValuation checks if it's time to send a monthly inventory valuation for an account.
+// Valuations are optional, so nothing is sent unless the account has an active schedule.
+func (s *Scheduler) shouldSendInventoryValuation(accountID int, now time.Time) bool {
+	return s.shouldSendMonthlyReport(accountID, models.EmailTypeInventoryValuation, now)
+}
+
+// shouldSendMonthlyReport checks an account's schedule for an opt-in monthly email type.
+func (s *Scheduler) shouldSendMonthlyReport(accountID int, emailType string, now time.Time) bool {
+	schedule, err := s.service.GetEmailScheduleByAccountAndType(accountID, emailType)
+	if err != nil || !schedule.IsActive {
+		return false
+	}
+
+	// Check if it's the right day of month (defaults to the 1st)
+	dayOfMonth := 1
+	if schedule.DayOfMonth != nil {
+		dayOfMonth = *schedule.DayOfMonth
+	}
+	if now.Day() != dayOfMonth {
+		return false
+	}
+
+	// Check if it's the right time of day (defaults to 9 AM)
+	expectedHour := 9
+	if schedule.TimeOfDay != "" {
+		if expectedTime, err := time.Parse("15:04", schedule.TimeOfDay); err == nil {
+			expectedHour = expectedTime.Hour()
+		}
+	}
+	if now.Hour() != expectedHour {
+		return false
+	}
+
+	// Check if we've already sent an email recently (within the last 23 hours)
+	if schedule.LastSentAt != nil && now.Sub(*schedule.LastSentAt) < 23*time.Hour {
+		return false
+	}
+
+	return true
+}
+
+// shouldSendExpiringItemsAlert checks if it's time to send the daily expiring items alert for an account.
+// Accounts without a schedule receive the alert every day at 8 AM.
+func (s *Scheduler) shouldSendExpiringItemsAlert(accountID int, now time.Time) bool {
+	schedule, err := s.service.GetEmailScheduleByAccountAndType(accountID, models.EmailTypeExpiringItems)
+	if err != nil {
+		return now.Hour() == 8
+	}
+
+	// Check if schedule is active
+	if !schedule.IsActive {
+		return false
+	}
+
+	// Check if it's the right time of day (defaults to 8 AM)
+	expectedHour := 8
+	if schedule.TimeOfDay != "" {
+		if expectedTime, err := time.Parse("15:04", schedule.TimeOfDay); err == nil {
+			expectedHour = expectedTime.Hour()
+		}
+	}
+	if now.Hour() != expectedHour {
+		return false
+	}
+
+	// Check if we've already sent an email recently (within the last 23 hours)
+	if schedule.LastSentAt != nil && now.Sub(*schedule.LastSentAt) < 23*time.Hour {
+		return false
+	}
+
+	return true
+}
+
 // sendWeeklyStockReportForAccount sends a weekly stock report for a specific account
 func (s *Scheduler) sendWeeklyStockReportForAccount(account models.Account) {
 	log.Printf("Sending weekly stock report for account: %s", account.Name)
@@ -320,8 +582,12 @@ func (s *Scheduler) sendWeeklyStockReportForAccount(account models.Account) {
 		return
 	}
 
+	// Attach the report as a PDF for mail clients that do not display HTML
+	document, err := pdf.StockReport(account.Name, stockData)
+	attachments := reportAttachments(account.ID, "stock-report-"+stockData.ReportDate.Format("2006-01-02")+".pdf", pdf.ContentType, document, err)
+
 	// Send weekly stock report
-	if err := s.emailService.SendWeeklyStockReport(account, users, stockData); err != nil {
+	if err := s.emailService.SendWeeklyStockReport(account, users, stockData, attachments...); err != nil {
 		log.Printf("Failed to send weekly stock report for account %d: %v", account.ID, err)
 		return
 	}
@@ -404,8 +670,12 @@ func (s *Scheduler) sendWeeklySupplyChainReportForAccount(account models.Account
 		return
 	}
 
+	// Attach the report as a PDF for mail clients that do not display HTML
+	document, err := pdf.SupplyChainReport(account.Name, supplyChainData)
+	attachments := reportAttachments(account.ID, "supply-chain-report-"+supplyChainData.ReportDate.Format("2006-01-02")+".pdf", pdf.ContentType, document, err)
+
 	// Send weekly supply chain report
-	if err := s.emailService.SendWeeklySupplyChainReport(account, users, supplyChainData); err != nil {
+	if err := s.emailService.SendWeeklySupplyChainReport(account, users, supplyChainData, attachments...); err != nil {
 		log.Printf("Failed to send weekly supply chain report for account %d: %v", account.ID, err)
 		return
 	}
@@ -427,6 +697,247 @@ func (s *Scheduler) sendWeeklySupplyChainReportForAccount(account models.Account
 	log.Printf("Successfully sent weekly supply chain report for account: %s", account.Name)
 }
 
+// sendVendorScorecardForAccount sends the previous month's vendor scorecard for a specific account
+func (s *Scheduler) sendVendorScorecardForAccount(account models.Account) {
+	log.Printf("Sending vendor scorecard for account: %s", account.Name)
+
+	// Get all users in the account
+	users, err := s.service.GetUsersByAccount(account.ID)
+	if err != nil {
+		log.Printf("Failed to get users for account %d: %v", account.ID, err)
+		return
+	}
+
+	if len(users) == 0 {
+		log.Printf("No users found for account %d", account.ID)
+		return
+	}
+
+	// Generate scorecard data for the previous calendar month
+	scorecardData, err := s.generateVendorScorecardData(account.ID, time.Now())
+	if err != nil {
+		log.Printf("Failed to generate vendor scorecard for account %d: %v", account.ID, err)
+		return
+	}
+
+	// Send vendor scorecard
+	if err := s.emailService.SendVendorScorecard(account, users, scorecardData); err != nil {
+		log.Printf("Failed to send vendor scorecard for account %d: %v", account.ID, err)
+		return
+	}
+
+	// Update the LastSentAt timestamp for the email schedule
+	schedule, err := s.service.GetEmailScheduleByAccountAndType(account.ID, models.EmailTypeVendorScorecard)
+	if err == nil && schedule != nil {
+		now := time.Now()
+		if err := s.service.UpdateEmailScheduleLastSent(schedule.ID, now); err != nil {
+			log.Printf("Failed to update LastSentAt for email schedule %d: %v", schedule.ID, err)
+		}
+	}
+
+	// Log successful email sending for each user
+	for _, user := range users {
+		s.logEmailSuccess(account.ID, &user.ID, user.Email, fmt.Sprintf("Monthly Vendor Scorecard - %s", account.Name), models.EmailTypeVendorScorecard)
+	}
+
+	log.Printf("Successfully sent vendor scorecard for account: %s", account.Name)
+}
+
+// sendInventoryValuationForAccount sends the previous month's inventory valuation for a specific account
+func (s *Scheduler) sendInventoryValuationForAccount(account models.Account) {
+	log.Printf("Sending inventory valuation for account: %s", account.Name)
+
+	// Get all users in the account
+	users, err := s.service.GetUsersByAccount(account.ID)
+	if err != nil {
+		log.Printf("Failed to get users for account %d: %v", account.ID, err)
+		return
+	}
+
+	if len(users) == 0 {
+		log.Printf("No users found for account %d", account.ID)
+		return
+	}
+
+	// Value the inventory at the end of the previous calendar month
+	valuationData, err := s.generateInventoryValuationData(account.ID, time.Now())
+	if err != nil {
+		log.Printf("Failed to generate inventory valuation for account %d: %v", account.ID, err)
+		return
+	}
+
+	// Attach the item valuations as a CSV for the accountants
+	rows, err := inventoryValuationCSV(valuationData)
+	attachments := reportAttachments(account.ID, "inventory-valuation-"+valuationData.PeriodStart.Format("2006-01")+".csv", spreadsheet.ContentType(spreadsheet.FormatCSV), rows, err)
+
+	// Send inventory valuation
+	if err := s.emailService.SendInventoryValuation(account, users, valuationData, attachments...); err != nil {
+		log.Printf("Failed to send inventory valuation for account %d: %v", account.ID, err)
+		return
+	}
+
+	// Update the LastSentAt timestamp for the email schedule
+	schedule, err := s.service.GetEmailScheduleByAccountAndType(account.ID, models.EmailTypeInventoryValuation)
+	if err == nil && schedule != nil {
+		now := time.Now()
+		if err := s.service.UpdateEmailScheduleLastSent(schedule.ID, now); err != nil {
+			log.Printf("Failed to update LastSentAt for email schedule %d: %v", schedule.ID, err)
+		}
+	}
+
+	// Log successful email sending for each user
+	for _, user := range users {
+		s.logEmailSuccess(account.ID, &user.ID, user.Email, fmt.Sprintf("Monthly Inventory Valuation - %s", account.Name), models.EmailTypeInventoryValuation)
+	}
+
+	log.Printf("Successfully sent inventory valuation for account: %s", account.Name)
+}
+
+// sendPriceAlertForAccount emails the pending price alerts for a specific account
+func (s *Scheduler) sendPriceAlertForAccount(account models.Account) {
+	alerts, err := s.service.GetUnnotifiedPriceAlerts(account.ID)
+	if err != nil {
+		log.Printf("Failed to get price alerts for account %d: %v", account.ID, err)
+		return
+	}
+
+	if len(alerts) == 0 {
+		return // No pending alerts
+	}
+
+	// Get all users in the account
+	users, err := s.service.GetUsersByAccount(account.ID)
+	if err != nil {
+		log.Printf("Failed to get users for account %d: %v", account.ID, err)
+		return
+	}
+
+	if len(users) == 0 {
+		return
+	}
+
+	// Send price alert
+	alertData := s.generatePriceAlertData(alerts)
+	if err := s.emailService.SendPriceAlert(account, users, alertData); err != nil {
+		log.Printf("Failed to send price alert for account %d: %v", account.ID, err)
+		return
+	}
+
+	// Mark the alerts as sent so they are not emailed again
+	ids := make([]int, 0, len(alerts))
+	for _, alert := range alerts {
+		ids = append(ids, alert.ID)
+	}
+	if err := s.service.MarkPriceAlertsNotified(ids, time.Now()); err != nil {
+		log.Printf("Failed to mark price alerts as sent for account %d: %v", account.ID, err)
+	}
+
+	// Log successful email sending for each user
+	for _, user := range users {
+		s.logEmailSuccess(account.ID, &user.ID, user.Email, fmt.Sprintf("Vendor Price Alert - %s", account.Name), models.EmailTypePriceAlert)
+	}
+
+	log.Printf("Successfully sent price alert for account: %s (%d alerts)", account.Name, len(alerts))
+}
+
+// sendBudgetAlertForAccount raises alerts for the budget thresholds an account's spend
+// crossed and emails the pending ones
+func (s *Scheduler) sendBudgetAlertForAccount(account models.Account, now time.Time) {
+	if _, err := s.service.CheckBudgetAlerts(account.ID, now); err != nil {
+		log.Printf("Failed to check budgets for account %d: %v", account.ID, err)
+		return
+	}
+
+	alerts, err := s.service.GetUnnotifiedBudgetAlerts(account.ID)
+	if err != nil {
+		log.Printf("Failed to get budget alerts for account %d: %v", account.ID, err)
+		return
+	}
+
+	if len(alerts) == 0 {
+		return // No pending alerts
+	}
+
+	// Get all users in the account
+	users, err := s.service.GetUsersByAccount(account.ID)
+	if err != nil {
+		log.Printf("Failed to get users for account %d: %v", account.ID, err)
+		return
+	}
+
+	if len(users) == 0 {
+		return
+	}
+
+	// Send budget alert
+	alertData := s.generateBudgetAlertData(alerts)
+	if err := s.emailService.SendBudgetAlert(account, users, alertData); err != nil {
+		log.Printf("Failed to send budget alert for account %d: %v", account.ID, err)
+		return
+	}
+
+	// Mark the alerts as sent so they are not emailed again
+	ids := make([]int, 0, len(alerts))
+	for _, alert := range alerts {
+		ids = append(ids, alert.ID)
+	}
+	if err := s.service.MarkBudgetAlertsNotified(ids, time.Now()); err != nil {
+		log.Printf("Failed to mark budget alerts as sent for account %d: %v", account.ID, err)
+	}
+
+	// Log successful email sending for each user
+	for _, user := range users {
+		s.logEmailSuccess(account.ID, &user.ID, user.Email, fmt.Sprintf("Budget Alert - %s", account.Name), models.EmailTypeBudgetAlert)
+	}
+
+	log.Printf("Successfully sent budget alert for account: %s (%d alerts)", account.Name, len(alerts))
+}
+
+// sendExpiringItemsAlertForAccount emails the lots that expire soon for a specific account
+func (s *Scheduler) sendExpiringItemsAlertForAccount(account models.Account) {
+	expiringItems, err := s.generateExpiringItemsData(account.ID)
+	if err != nil {
+		log.Printf("Failed to get expiring items for account %d: %v", account.ID, err)
+		return
+	}
+
+	if len(expiringItems) == 0 {
+		return // Nothing is expiring
+	}
+
+	// Get all users in the account
+	users, err := s.service.GetUsersByAccount(account.ID)
+	if err != nil {
+		log.Printf("Failed to get users for account %d: %v", account.ID, err)
+		return
+	}
+
+	if len(users) == 0 {
+		return
+	}
+
+	// Send expiring items alert
+	if err := s.emailService.SendExpiringItemsAlert(account, users, expiringItems); err != nil {
+		log.Printf("Failed to send expiring items alert for account %d: %v", account.ID, err)
+		return
+	}
+
+	// Update the LastSentAt timestamp for the email schedule
+	schedule, err := s.service.GetEmailScheduleByAccountAndType(account.ID, models.EmailTypeExpiringItems)
+	if err == nil && schedule != nil {
+		if err := s.service.UpdateEmailScheduleLastSent(schedule.ID, time.Now()); err != nil {
+			log.Printf("Failed to update LastSentAt for email schedule %d: %v", schedule.ID, err)
+		}
+	}
+
+	// Log successful email sending for each user
+	for _, user := range users {
+		s.logEmailSuccess(account.ID, &user.ID, user.Email, fmt.Sprintf("Expiring Items Alert - %s", account.Name), models.EmailTypeExpiringItems)
+	}
+
+	log.Printf("Successfully sent expiring items alert for account: %s (%d lots)", account.Name, len(expiringItems))
+}
+
 // generateStockReportData generates stock report data for an account
 func (s *Scheduler) generateStockReportData(accountID int) (*email.StockReportData, error) {
 	// Get all inventory items for the account
@@ -609,6 +1120,191 @@ func (s *Scheduler) generateSupplyChainReportData(accountID int) (*email.SupplyC
 	return supplyChainData, nil
 }
 
+// generateVendorScorecardData generates vendor scorecard data for the calendar month before now
+func (s *Scheduler) generateVendorScorecardData(accountID int, now time.Time) (*email.VendorScorecardData, error) {
+	periodStart, periodEnd := previousMonth(now)
+
+	scorecards, err := s.service.GetVendorScorecards(accountID, periodStart, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	scorecardData := &email.VendorScorecardData{
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		Vendors:     make([]email.VendorScorecardItemData, 0, len(scorecards)),
+	}
+
+	for _, scorecard := range scorecards {
+		scorecardData.Vendors = append(scorecardData.Vendors, email.VendorScorecardItemData{
+			Name:              scorecard.VendorName,
+			OrderLines:        scorecard.OrderLines,
+			OnTimeRate:        scorecard.OnTimeRate,
+			AverageDaysLate:   scorecard.AverageDaysLate,
+			FillRate:          scorecard.FillRate,
+			AveragePriceDrift: scorecard.AveragePriceDrift,
+		})
+	}
+
+	return scorecardData, nil
+}
+
+// generateInventoryValuationData values inventory for the calendar month before now,
+// using the valuation method that matches the account's costing method
+func (s *Scheduler) generateInventoryValuationData(accountID int, now time.Time) (*email.InventoryValuationData, error) {
+	periodStart, periodEnd := previousMonth(now)
+
+	valuation, err := s.service.GetInventoryValuation(accountID, periodStart, periodEnd, "")
+	if err != nil {
+		return nil, err
+	}
+
+	valuationData := &email.InventoryValuationData{
+		PeriodStart:  periodStart,
+		PeriodEnd:    periodEnd,
+		Method:       valuation.Method,
+		OpeningValue: valuation.OpeningValue,
+		Purchases:    valuation.Purchases,
+		ClosingValue: valuation.ClosingValue,
+		COGS:         valuation.COGS,
+		Items:        make([]email.InventoryValuationItemData, 0, len(valuation.Items)),
+	}
+
+	for _, item := range valuation.Items {
+		valuationData.Items = append(valuationData.Items, email.InventoryValuationItemData{
+			Name:            item.ItemName,
+			Unit:            item.Unit,
+			ClosingQuantity: item.ClosingQuantity,
+			ClosingUnitCost: item.ClosingUnitCost,
+			ClosingValue:    item.ClosingValue,
+			COGS:            item.COGS,
+		})
+	}
+
+	return valuationData, nil
+}
+
+// reportAttachments wraps a rendered report as an email attachment. A report that
+// failed to render is logged and left out, so the email is still sent without it.
+func reportAttachments(accountID int, filename, contentType string, data []byte, err error) []email.Attachment {
+	if err != nil {
+		log.Printf("Failed to render %s for account %d: %v", filename, accountID, err)
+		return nil
+	}
+	return []email.Attachment{{Filename: filename, ContentType: contentType, Data: data}}
+}
+
+// inventoryValuationCSV writes the item valuations of a monthly inventory valuation as CSV
+func inventoryValuationCSV(data *email.InventoryValuationData) ([]byte, error) {
+	var buf bytes.Buffer
+	header := []string{"Item", "Unit", "Closing Quantity", "Closing Unit Cost", "Closing Value", "COGS"}
+	writer, err := spreadsheet.NewWriter(&buf, spreadsheet.FormatCSV, "Valuation", header)
+	if err != nil {
+		return nil, err
+	}
+
+	cents := func(value float64) float64 { return math.Round(value*100) / 100 }
+	for _, item := range data.Items {
+		row := []interface{}{item.Name, item.Unit, item.ClosingQuantity, cents(item.ClosingUnitCost), cents(item.ClosingValue), cents(item.COGS)}
+		if err := writer.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Write([]interface{}{"Total", nil, nil, nil, cents(data.ClosingValue), cents(data.COGS)}); err != nil {
+		return nil, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// previousMonth returns the first and last instants of the calendar month before now
+func previousMonth(now time.Time) (time.Time, time.Time) {
+	periodEnd := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).Add(-time.Nanosecond)
+	periodStart := time.Date(periodEnd.Year(), periodEnd.Month(), 1, 0, 0, 0, 0, now.Location())
+	return periodStart, periodEnd
+}
+
+// generatePriceAlertData resolves the item and vendor names for price alerts
+func (s *Scheduler) generatePriceAlertData(alerts []models.PriceAlert) []email.PriceAlertItemData {
+	alertData := make([]email.PriceAlertItemData, 0, len(alerts))
+
+	for _, alert := range alerts {
+		itemName := ""
+		if item, err := s.service.GetInventoryItem(alert.InventoryItemID); err == nil {
+			itemName = item.Name
+		}
+
+		vendorName := ""
+		if alert.VendorID != nil {
+			if vendor, err := s.service.GetVendor(*alert.VendorID); err == nil {
+				vendorName = vendor.Name
+			}
+		}
+
+		alertData = append(alertData, email.PriceAlertItemData{
+			ItemName:      itemName,
+			VendorName:    vendorName,
+			PreviousPrice: alert.PreviousPrice,
+			NewPrice:      alert.NewPrice,
+			ChangePercent: alert.ChangePercent,
+		})
+	}
+
+	return alertData
+}
+
+// generateBudgetAlertData resolves the category and period of budget alerts
+func (s *Scheduler) generateBudgetAlertData(alerts []models.BudgetAlert) []email.BudgetAlertItemData {
+	alertData := make([]email.BudgetAlertItemData, 0, len(alerts))
+
+	for _, alert := range alerts {
+		item := email.BudgetAlertItemData{
+			PeriodStart: alert.PeriodStart,
+			Threshold:   alert.Threshold,
+			Spend:       alert.Spend,
+			Amount:      alert.Amount,
+		}
+		if alert.Amount > 0 {
+			item.PercentUsed = alert.Spend / alert.Amount * 100
+		}
+		if budget, err := s.service.GetBudget(alert.BudgetID); err == nil {
+			item.Period = budget.Period
+			if category, err := s.service.GetCategory(budget.CategoryID); err == nil {
+				item.CategoryName = category.Name
+			}
+		}
+
+		alertData = append(alertData, item)
+	}
+
+	return alertData
+}
+
+// generateExpiringItemsData collects the lots of an account that expire soon
+func (s *Scheduler) generateExpiringItemsData(accountID int) ([]email.ExpiringItemData, error) {
+	lots, err := s.service.GetExpiringLots(accountID, database.ExpiringSoonDays)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]email.ExpiringItemData, 0, len(lots))
+	for _, lot := range lots {
+		items = append(items, email.ExpiringItemData{
+			Name:           lot.ItemName,
+			LotNumber:      lot.LotNumber,
+			Quantity:       lot.RemainingQuantity,
+			Unit:           lot.Unit,
+			ExpirationDate: *lot.ExpirationDate,
+			DaysLeft:       lot.DaysUntilExpiry,
+		})
+	}
+
+	return items, nil
+}
+
 // logEmailSuccess logs a successful email send
 func (s *Scheduler) logEmailSuccess(accountID int, userID *int, toEmail, subject, emailType string) {
 	emailLog := models.EmailLog{
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/mnadev/pantryos/internal/database"
+	"github.com/mnadev/pantryos/internal/email"
 	"github.com/mnadev/pantryos/internal/models"
 )
 
@@ -188,3 +189,247 @@ func TestCreateDefaultEmailSchedules(t *testing.T) {
 		t.Error("Weekly supply chain schedule not found")
 	}
 }
+
+func TestShouldSendVendorScorecard(t *testing.T) {
+	// Initialize test database using SQLite
+	db, cleanup := database.SetupTestDBLegacy(t)
+	defer cleanup()
+
+	// Create scheduler
+	scheduler := NewScheduler(db)
+
+	// Test case 1: Scorecards are opt-in, so nothing is sent without a schedule
+	firstOfMonth := time.Date(2024, time.June, 1, 9, 0, 0, 0, time.Local)
+	if scheduler.shouldSendVendorScorecard(1, firstOfMonth) {
+		t.Error("Expected shouldSend to be false with no schedule")
+	}
+
+	// Test case 2: Create a monthly schedule on the 5th at 8 AM
+	schedule := &models.EmailSchedule{
+		AccountID:  1,
+		EmailType:  models.EmailTypeVendorScorecard,
+		Frequency:  "monthly",
+		DayOfMonth: &[]int{5}[0],
+		TimeOfDay:  "08:00",
+		IsActive:   true,
+	}
+
+	if err := scheduler.service.CreateEmailSchedule(schedule); err != nil {
+		t.Fatalf("Failed to create test schedule: %v", err)
+	}
+
+	if !scheduler.shouldSendVendorScorecard(1, time.Date(2024, time.June, 5, 8, 30, 0, 0, time.Local)) {
+		t.Error("Expected shouldSend to be true on the 5th at 8 AM")
+	}
+	if scheduler.shouldSendVendorScorecard(1, time.Date(2024, time.June, 5, 9, 0, 0, 0, time.Local)) {
+		t.Error("Expected shouldSend to be false outside the scheduled hour")
+	}
+	if scheduler.shouldSendVendorScorecard(1, firstOfMonth) {
+		t.Error("Expected shouldSend to be false on the wrong day of month")
+	}
+
+	// Test case 3: Inactive schedule
+	schedule.IsActive = false
+	if err := scheduler.service.UpdateEmailSchedule(schedule); err != nil {
+		t.Fatalf("Failed to update test schedule: %v", err)
+	}
+
+	if scheduler.shouldSendVendorScorecard(1, time.Date(2024, time.June, 5, 8, 30, 0, 0, time.Local)) {
+		t.Error("Expected shouldSend to be false with inactive schedule")
+	}
+}
+
+func TestShouldSendExpiringItemsAlert(t *testing.T) {
+	// Initialize test database using SQLite
+	db, cleanup := database.SetupTestDBLegacy(t)
+	defer cleanup()
+
+	// Create scheduler
+	scheduler := NewScheduler(db)
+
+	// Test case 1: Without a schedule the alert goes out daily at 8 AM
+	if !scheduler.shouldSendExpiringItemsAlert(1, time.Date(2024, time.June, 3, 8, 15, 0, 0, time.Local)) {
+		t.Error("Expected shouldSend to be true at 8 AM with no schedule")
+	}
+	if scheduler.shouldSendExpiringItemsAlert(1, time.Date(2024, time.June, 3, 9, 0, 0, 0, time.Local)) {
+		t.Error("Expected shouldSend to be false outside 8 AM with no schedule")
+	}
+
+	// Test case 2: A schedule moves the alert and suppresses repeats
+	lastSent := time.Date(2024, time.June, 3, 6, 0, 0, 0, time.Local)
+	schedule := &models.EmailSchedule{
+		AccountID:  1,
+		EmailType:  models.EmailTypeExpiringItems,
+		Frequency:  "daily",
+		TimeOfDay:  "06:00",
+		IsActive:   true,
+		LastSentAt: &lastSent,
+	}
+
+	if err := scheduler.service.CreateEmailSchedule(schedule); err != nil {
+		t.Fatalf("Failed to create test schedule: %v", err)
+	}
+
+	if scheduler.shouldSendExpiringItemsAlert(1, time.Date(2024, time.June, 3, 6, 30, 0, 0, time.Local)) {
+		t.Error("Expected shouldSend to be false when already sent today")
+	}
+	if !scheduler.shouldSendExpiringItemsAlert(1, time.Date(2024, time.June, 4, 6, 0, 0, 0, time.Local)) {
+		t.Error("Expected shouldSend to be true at the scheduled hour the next day")
+	}
+
+	// Test case 3: Inactive schedule
+	schedule.IsActive = false
+	if err := scheduler.service.UpdateEmailSchedule(schedule); err != nil {
+		t.Fatalf("Failed to update test schedule: %v", err)
+	}
+
+	if scheduler.shouldSendExpiringItemsAlert(1, time.Date(2024, time.June, 4, 6, 0, 0, 0, time.Local)) {
+		t.Error("Expected shouldSend to be false with inactive schedule")
+	}
+}
+
+func TestGenerateVendorScorecardData(t *testing.T) {
+	// Initialize test database using SQLite
+	db, cleanup := database.SetupTestDBLegacy(t)
+	defer cleanup()
+
+	// Create scheduler
+	scheduler := NewScheduler(db)
+
+	// The report covers the calendar month before the send date
+	data, err := scheduler.generateVendorScorecardData(1, time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Failed to generate vendor scorecard data: %v", err)
+	}
+
+	if !data.PeriodStart.Equal(time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected period to start on February 1, got %v", data.PeriodStart)
+	}
+	if data.PeriodEnd.Month() != time.February || data.PeriodEnd.Day() != 29 {
+		t.Errorf("Expected period to end on February 29, got %v", data.PeriodEnd)
+	}
+	if len(data.Vendors) != 0 {
+		t.Errorf("Expected no vendors for an empty account, got %d", len(data.Vendors))
+	}
+}
+
+func TestGenerateInventoryValuationData(t *testing.T) {
+	// Initialize test database using SQLite
+	db, cleanup := database.SetupTestDBLegacy(t)
+	defer cleanup()
+
+	// Create scheduler
+	scheduler := NewScheduler(db)
+	service := database.NewService(db)
+
+	account := &models.Account{Name: "Valuation Cafe", Status: "active"}
+	if err := service.CreateAccount(account); err != nil {
+		t.Fatalf("Failed to create account: %v", err)
+	}
+	item := &models.InventoryItem{AccountID: account.ID, Name: "Coffee Beans", Unit: "kg", CostPerUnit: 12}
+	if err := service.CreateInventoryItem(item); err != nil {
+		t.Fatalf("Failed to create inventory item: %v", err)
+	}
+
+	// Nothing was counted, so there is nothing to value
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	if _, err := scheduler.generateInventoryValuationData(account.ID, now); err == nil {
+		t.Error("Expected an error without a count during the period")
+	}
+
+	snapshot := &models.InventorySnapshot{
+		AccountID: account.ID,
+		Timestamp: time.Date(2024, time.February, 29, 22, 0, 0, 0, time.UTC),
+		Counts:    models.CountsMap{item.ID: 4},
+	}
+	if err := service.CreateInventorySnapshot(snapshot); err != nil {
+		t.Fatalf("Failed to create snapshot: %v", err)
+	}
+
+	// The report values the count taken during the calendar month before the send date
+	data, err := scheduler.generateInventoryValuationData(account.ID, now)
+	if err != nil {
+		t.Fatalf("Failed to generate inventory valuation data: %v", err)
+	}
+	if data.PeriodEnd.Month() != time.February || data.PeriodEnd.Day() != 29 {
+		t.Errorf("Expected period to end on February 29, got %v", data.PeriodEnd)
+	}
+	if data.Method != database.ValuationMethodWeightedAverage {
+		t.Errorf("Expected the weighted average method for an account costed by last price, got %s", data.Method)
+	}
+	if len(data.Items) != 1 || data.ClosingValue != 48 {
+		t.Errorf("Expected one item valued at 48, got %d items valued at %v", len(data.Items), data.ClosingValue)
+	}
+}
+
+func TestInventoryValuationCSV(t *testing.T) {
+	rows, err := inventoryValuationCSV(&email.InventoryValuationData{
+		ClosingValue: 18.5,
+		COGS:         12.345,
+		Items: []email.InventoryValuationItemData{
+			{Name: "Milk, Whole", Unit: "liters", ClosingQuantity: 7.4, ClosingUnitCost: 2.5, ClosingValue: 18.5, COGS: 12.345},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to write CSV: %v", err)
+	}
+
+	expected := "Item,Unit,Closing Quantity,Closing Unit Cost,Closing Value,COGS\n" +
+		"\"Milk, Whole\",liters,7.4,2.5,18.5,12.35\n" +
+		"Total,,,,18.5,12.35\n"
+	if string(rows) != expected {
+		t.Errorf("Unexpected CSV:\n%s", rows)
+	}
+}
+
+func TestGenerateBudgetAlertData(t *testing.T) {
+	// Initialize test database using SQLite
+	db, cleanup := database.SetupTestDBLegacy(t)
+	defer cleanup()
+
+	// Create scheduler
+	scheduler := NewScheduler(db)
+	service := database.NewService(db)
+
+	account := &models.Account{Name: "Budget Cafe", Status: "active"}
+	if err := service.CreateAccount(account); err != nil {
+		t.Fatalf("Failed to create account: %v", err)
+	}
+	dairy := &models.Category{AccountID: account.ID, Name: "Dairy"}
+	if err := service.CreateCategory(dairy); err != nil {
+		t.Fatalf("Failed to create category: %v", err)
+	}
+	milk := &models.InventoryItem{AccountID: account.ID, Name: "Milk", Unit: "liters", CategoryID: &dairy.ID}
+	if err := service.CreateInventoryItem(milk); err != nil {
+		t.Fatalf("Failed to create inventory item: %v", err)
+	}
+	budget := &models.Budget{AccountID: account.ID, CategoryID: dairy.ID, Period: models.BudgetPeriodWeekly, Amount: 200}
+	if err := service.CreateBudget(budget); err != nil {
+		t.Fatalf("Failed to create budget: %v", err)
+	}
+
+	now := time.Date(2024, time.March, 6, 9, 0, 0, 0, time.UTC)
+	delivery := &models.Delivery{AccountID: account.ID, InventoryItemID: milk.ID, Vendor: "Local Dairy", Quantity: 60, Cost: 170, DeliveryDate: now}
+	if err := service.CreateDelivery(delivery); err != nil {
+		t.Fatalf("Failed to create delivery: %v", err)
+	}
+
+	alerts, err := service.CheckBudgetAlerts(account.ID, now)
+	if err != nil {
+		t.Fatalf("Failed to check budget alerts: %v", err)
+	}
+
+	data := scheduler.generateBudgetAlertData(alerts)
+	if len(data) != 1 {
+		t.Fatalf("Expected one alert for the 80%% threshold, got %d", len(data))
+	}
+	if data[0].CategoryName != "Dairy" || data[0].Period != models.BudgetPeriodWeekly {
+		t.Errorf("Expected the weekly Dairy budget, got %s %s", data[0].Period, data[0].CategoryName)
+	}
+	if data[0].Threshold != 80 || data[0].PercentUsed != 85 {
+		t.Errorf("Expected 85%% used against the 80%% threshold, got %v%% against %v%%", data[0].PercentUsed, data[0].Threshold)
+	}
+	if !data[0].PeriodStart.Equal(time.Date(2024, time.March, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the week to start on Monday March 4, got %v", data[0].PeriodStart)
+	}
+}
@@ -0,0 +1,253 @@
+// Package spreadsheet reads tabular files uploaded by users, such as inventory imports,
+// and writes the files users download, such as data exports. It reads CSV and the first
+// worksheet of XLSX workbooks as rows of cell text so callers can treat both formats alike,
+// and streams CSV, XLSX and JSON files a row at a time.
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// maxPartSize limits how much of each XLSX part is decompressed, guarding against zip bombs
+const maxPartSize = 64 << 20
+
+// Read parses a CSV or XLSX file, choosing the format by the file name's extension.
+// Row i of the result is line i+1 of the file; blank rows are kept as empty rows.
+//
+// Parameters:
+//   - filename: The uploaded file's name, ending in .csv or .xlsx
+//   - data: The file contents
+//
+// Returns:
+//   - [][]string: The cell text of each row
+//   - error: An unsupported format or a malformed file
+func Read(filename string, data []byte) ([][]string, error) {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".csv":
+		return ReadCSV(bytes.NewReader(data))
+	case ".xlsx":
+		return ReadXLSX(bytes.NewReader(data), int64(len(data)))
+	default:
+		return nil, fmt.Errorf("unsupported file type %q: upload a .csv or .xlsx file", path.Ext(filename))
+	}
+}
+
+// ReadCSV parses comma-separated values. Rows may have different numbers of cells, and
+// the byte order mark that spreadsheet programs put at the start of the file is dropped.
+func ReadCSV(r io.Reader) ([][]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV file: %w", err)
+	}
+	if len(rows) > 0 && len(rows[0]) > 0 {
+		rows[0][0] = strings.TrimPrefix(rows[0][0], "\ufeff")
+	}
+	return rows, nil
+}
+
+// xlsxWorkbook lists the sheets of a workbook in display order
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelationshipID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// xlsxRelationships maps relationship IDs to the parts of a package
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxText is a shared or inline string, either plain or split into formatted runs
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	text := t.Text
+	for _, run := range t.Runs {
+		text += run.Text
+	}
+	return text
+}
+
+// xlsxSharedStrings holds the strings cells refer to by index
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+// xlsxWorksheet holds the rows of a worksheet; empty rows and cells are omitted
+type xlsxWorksheet struct {
+	Rows []struct {
+		Number int `xml:"r,attr"`
+		Cells  []struct {
+			Reference string   `xml:"r,attr"`
+			Type      string   `xml:"t,attr"`
+			Value     string   `xml:"v"`
+			Inline    xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ReadXLSX parses the first worksheet of an Office Open XML workbook. Cells hold their
+// displayed text for strings, the stored value for numbers, and TRUE or FALSE for booleans.
+func ReadXLSX(r io.ReaderAt, size int64) ([][]string, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid XLSX file: %w", err)
+	}
+	parts := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		parts[file.Name] = file
+	}
+
+	sheetPath, err := firstSheetPath(parts)
+	if err != nil {
+		return nil, err
+	}
+
+	var sharedStrings xlsxSharedStrings
+	if _, ok := parts["xl/sharedStrings.xml"]; ok {
+		if err := decodePart(parts, "xl/sharedStrings.xml", &sharedStrings); err != nil {
+			return nil, err
+		}
+	}
+
+	var sheet xlsxWorksheet
+	if err := decodePart(parts, sheetPath, &sheet); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for _, sheetRow := range sheet.Rows {
+		number := sheetRow.Number
+		if number == 0 {
+			number = len(rows) + 1
+		}
+		if number < len(rows)+1 {
+			return nil, fmt.Errorf("invalid XLSX file: row %d is out of order", number)
+		}
+		for len(rows) < number {
+			rows = append(rows, nil)
+		}
+
+		var cells []string
+		for _, cell := range sheetRow.Cells {
+			column := len(cells)
+			if cell.Reference != "" {
+				if column, err = columnIndex(cell.Reference); err != nil {
+					return nil, err
+				}
+			}
+
+			value := cell.Value
+			switch cell.Type {
+			case "s":
+				index, err := strconv.Atoi(strings.TrimSpace(cell.Value))
+				if err != nil || index < 0 || index >= len(sharedStrings.Items) {
+					return nil, fmt.Errorf("invalid XLSX file: cell %s refers to a missing string", cell.Reference)
+				}
+				value = sharedStrings.Items[index].String()
+			case "inlineStr":
+				value = cell.Inline.String()
+			case "b":
+				value = "FALSE"
+				if cell.Value == "1" {
+					value = "TRUE"
+				}
+			}
+
+			for len(cells) < column {
+				cells = append(cells, "")
+			}
+			if column < len(cells) {
+				cells[column] = value
+			} else {
+				cells = append(cells, value)
+			}
+		}
+		rows[number-1] = cells
+	}
+	return rows, nil
+}
+
+// firstSheetPath finds the part holding the workbook's first worksheet
+func firstSheetPath(parts map[string]*zip.File) (string, error) {
+	var workbook xlsxWorkbook
+	if err := decodePart(parts, "xl/workbook.xml", &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", errors.New("invalid XLSX file: the workbook has no sheets")
+	}
+
+	var relationships xlsxRelationships
+	if err := decodePart(parts, "xl/_rels/workbook.xml.rels", &relationships); err != nil {
+		return "", err
+	}
+	for _, relationship := range relationships.Relationships {
+		if relationship.ID != workbook.Sheets[0].RelationshipID {
+			continue
+		}
+		// Targets are relative to the xl folder unless they start at the package root
+		if strings.HasPrefix(relationship.Target, "/") {
+			return strings.TrimPrefix(relationship.Target, "/"), nil
+		}
+		return path.Join("xl", relationship.Target), nil
+	}
+	return "", errors.New("invalid XLSX file: the first sheet could not be found")
+}
+
+// decodePart unmarshals an XML part of the package into v
+func decodePart(parts map[string]*zip.File, name string, v interface{}) error {
+	file, ok := parts[name]
+	if !ok {
+		return fmt.Errorf("invalid XLSX file: %s is missing", name)
+	}
+	reader, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("invalid XLSX file: %w", err)
+	}
+	defer reader.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(reader, maxPartSize)).Decode(v); err != nil {
+		return fmt.Errorf("invalid XLSX file: %s: %w", name, err)
+	}
+	return nil
+}
+
+// columnIndex converts the column letters of a cell reference such as "AB12" to a
+// zero-based column index
+func columnIndex(reference string) (int, error) {
+	index := 0
+	letters := 0
+	for _, r := range strings.ToUpper(reference) {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		index = index*26 + int(r-'A'+1)
+		letters++
+	}
+	if letters == 0 || letters > 3 {
+		return 0, fmt.Errorf("invalid XLSX file: bad cell reference %q", reference)
+	}
+	return index - 1, nil
+}
@@ -0,0 +1,156 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildXLSX packages worksheet and shared string XML into a minimal workbook
+func buildXLSX(t *testing.T, sheet, sharedStrings string) []byte {
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Items" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId2" Target="sharedStrings.xml"/><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/worksheets/sheet1.xml": sheet,
+	}
+	if sharedStrings != "" {
+		parts["xl/sharedStrings.xml"] = sharedStrings
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range parts {
+		part, err := archive.Create(name)
+		require.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, archive.Close())
+	return buf.Bytes()
+}
+
+func TestRead(t *testing.T) {
+	t.Run("CSV With Byte Order Mark", func(t *testing.T) {
+		rows, err := Read("items.CSV", []byte("\ufeffname,unit\nFlour,kg\nMilk\n"))
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"name", "unit"}, {"Flour", "kg"}, {"Milk"}}, rows)
+	})
+
+	t.Run("XLSX", func(t *testing.T) {
+		sheet := `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="inlineStr"><is><t>cost</t></is></c></row>` +
+			`<row r="3"><c r="A3" t="s"><v>2</v></c><c r="C3"><v>1.25</v></c><c r="D3" t="b"><v>1</v></c></row>` +
+			`</sheetData></worksheet>`
+		sharedStrings := `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<si><t>name</t></si><si><t>unit</t></si><si><r><t>Whole </t></r><r><t>Milk</t></r></si></sst>`
+
+		rows, err := Read("items.xlsx", buildXLSX(t, sheet, sharedStrings))
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{
+			{"name", "unit", "cost"},
+			nil,
+			{"Whole Milk", "", "1.25", "TRUE"},
+		}, rows)
+	})
+
+	t.Run("XLSX Missing Shared String", func(t *testing.T) {
+		sheet := `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>4</v></c></row></sheetData></worksheet>`
+		_, err := Read("items.xlsx", buildXLSX(t, sheet, ""))
+		assert.Error(t, err)
+	})
+
+	t.Run("Invalid XLSX", func(t *testing.T) {
+		_, err := Read("items.xlsx", []byte("name,unit\n"))
+		assert.Error(t, err)
+	})
+
+	t.Run("Unsupported Type", func(t *testing.T) {
+		_, err := Read("items.xls", []byte("data"))
+		assert.ErrorContains(t, err, "unsupported file type")
+	})
+}
+
+func TestColumnIndex(t *testing.T) {
+	for reference, want := range map[string]int{"A1": 0, "Z9": 25, "AA10": 26, "ab3": 27} {
+		index, err := columnIndex(reference)
+		require.NoError(t, err)
+		assert.Equal(t, want, index, reference)
+	}
+	_, err := columnIndex("12")
+	assert.Error(t, err)
+}
+
+func TestWriter(t *testing.T) {
+	header := []string{"id", "name", "quantity", "received", "notes"}
+	received := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	rows := [][]interface{}{
+		{1, "Milk", 12.5, received, nil},
+		{2, "Beans <Arabica> & co", 3.0, received, "\"quoted\", with comma"},
+	}
+	write := func(format string) []byte {
+		var buf bytes.Buffer
+		writer, err := NewWriter(&buf, format, "deliveries", header)
+		require.NoError(t, err)
+		for _, row := range rows {
+			require.NoError(t, writer.Write(row))
+		}
+		require.NoError(t, writer.Close())
+		return buf.Bytes()
+	}
+	want := [][]string{
+		header,
+		{"1", "Milk", "12.5", "2024-03-01T09:30:00Z"},
+		{"2", "Beans <Arabica> & co", "3", "2024-03-01T09:30:00Z", "\"quoted\", with comma"},
+	}
+
+	t.Run("CSV", func(t *testing.T) {
+		records, err := Read("export.csv", write(FormatCSV))
+		require.NoError(t, err)
+		want := append([][]string{}, want...)
+		want[1] = append(want[1], "")
+		assert.Equal(t, want, records)
+	})
+
+	t.Run("XLSX", func(t *testing.T) {
+		records, err := Read("export.xlsx", write(FormatXLSX))
+		require.NoError(t, err)
+		assert.Equal(t, want, records)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		var objects []map[string]interface{}
+		require.NoError(t, json.Unmarshal(write(FormatJSON), &objects))
+		require.Len(t, objects, 2)
+		assert.Equal(t, map[string]interface{}{"id": 1.0, "name": "Milk", "quantity": 12.5, "received": "2024-03-01T09:30:00Z", "notes": nil}, objects[0])
+	})
+
+	t.Run("Empty JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer, err := NewWriter(&buf, FormatJSON, "", header)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+		assert.JSONEq(t, "[]", buf.String())
+	})
+
+	t.Run("Unsupported Format", func(t *testing.T) {
+		_, err := NewWriter(&bytes.Buffer{}, "pdf", "", header)
+		assert.Error(t, err)
+	})
+}
+
+func TestColumnName(t *testing.T) {
+	for index, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		assert.Equal(t, want, columnName(index))
+		got, err := columnIndex(want + "1")
+		require.NoError(t, err)
+		assert.Equal(t, index, got)
+	}
+}
@@ -0,0 +1,277 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported output formats
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+	FormatJSON = "json"
+)
+
+// Formats lists the output formats NewWriter accepts
+var Formats = []string{FormatCSV, FormatXLSX, FormatJSON}
+
+// ContentType returns the MIME type of files in the given format
+func ContentType(format string) string {
+	switch format {
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatJSON:
+		return "application/json"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// Writer writes rows to a file one at a time, so large files never have to be held in memory.
+// Cells may be strings, ints, float64s, bools, time.Times, or nil for an empty cell.
+type Writer interface {
+	// Write adds a row, with one cell per header column
+	Write(row []interface{}) error
+	// Close finishes the file; it must be called for the file to be complete
+	Close() error
+}
+
+// NewWriter starts a file in the given format and writes its header row.
+// JSON files are an array holding an object per row, keyed by the header.
+//
+// Parameters:
+//   - w: Where the file is written
+//   - format: One of Formats
+//   - sheetName: The worksheet name of XLSX files
+//   - header: The column names
+//
+// Returns:
+//   - Writer: The writer to add rows with
+//   - error: An unsupported format, or an error writing the header
+func NewWriter(w io.Writer, format, sheetName string, header []string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		writer := &csvWriter{writer: csv.NewWriter(w)}
+		return writer, writer.writer.Write(header)
+	case FormatXLSX:
+		writer := &xlsxWriter{archive: zip.NewWriter(w)}
+		return writer, writer.start(sheetName, header)
+	case FormatJSON:
+		writer := &jsonWriter{writer: bufio.NewWriter(w), header: header}
+		_, err := writer.writer.WriteString("[")
+		return writer, err
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// cellText formats a cell for the text-based formats
+func cellText(cell interface{}) string {
+	switch value := cell.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case time.Time:
+		return value.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// csvWriter writes comma-separated values
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func (w *csvWriter) Write(row []interface{}) error {
+	record := make([]string, len(row))
+	for i, cell := range row {
+		record[i] = cellText(cell)
+	}
+	return w.writer.Write(record)
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// jsonWriter writes an array of objects
+type jsonWriter struct {
+	writer *bufio.Writer
+	header []string
+	rows   int
+}
+
+func (w *jsonWriter) Write(row []interface{}) error {
+	separator := "\n{"
+	if w.rows > 0 {
+		separator = ",\n{"
+	}
+	w.rows++
+	if _, err := w.writer.WriteString(separator); err != nil {
+		return err
+	}
+
+	for i, column := range w.header {
+		var cell interface{}
+		if i < len(row) {
+			cell = row[i]
+		}
+		if t, ok := cell.(time.Time); ok {
+			cell = t.Format(time.RFC3339)
+		}
+		key, err := json.Marshal(column)
+		if err != nil {
+			return err
+		}
+		value, err := json.Marshal(cell)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			w.writer.WriteByte(',')
+		}
+		w.writer.Write(key)
+		w.writer.WriteByte(':')
+		if _, err := w.writer.Write(value); err != nil {
+			return err
+		}
+	}
+	_, err := w.writer.WriteString("}")
+	return err
+}
+
+func (w *jsonWriter) Close() error {
+	if _, err := w.writer.WriteString("\n]\n"); err != nil {
+		return err
+	}
+	return w.writer.Flush()
+}
+
+// xlsxWriter writes a workbook with a single worksheet. The worksheet is the last part of
+// the package, so its rows can be compressed and written out as they arrive.
+type xlsxWriter struct {
+	archive *zip.Writer
+	sheet   *bufio.Writer
+	rows    int
+}
+
+// xlsxParts are the fixed parts of a workbook written by xlsxWriter; %s is the sheet name
+var xlsxParts = []struct{ name, content string }{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// maxSheetNameLength is the longest worksheet name spreadsheet programs accept
+const maxSheetNameLength = 31
+
+func (w *xlsxWriter) start(sheetName string, header []string) error {
+	if sheetName == "" {
+		sheetName = "Sheet1"
+	}
+	if runes := []rune(sheetName); len(runes) > maxSheetNameLength {
+		sheetName = string(runes[:maxSheetNameLength])
+	}
+
+	for _, part := range xlsxParts {
+		file, err := w.archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		content := part.content
+		if part.name == "xl/workbook.xml" {
+			content = fmt.Sprintf(content, escapeXML(sheetName))
+		}
+		if _, err := io.WriteString(file, content); err != nil {
+			return err
+		}
+	}
+
+	file, err := w.archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	w.sheet = bufio.NewWriter(file)
+	w.sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	row := make([]interface{}, len(header))
+	for i, column := range header {
+		row[i] = column
+	}
+	return w.Write(row)
+}
+
+func (w *xlsxWriter) Write(row []interface{}) error {
+	w.rows++
+	fmt.Fprintf(w.sheet, `<row r="%d">`, w.rows)
+	for i, cell := range row {
+		reference := columnName(i) + strconv.Itoa(w.rows)
+		switch value := cell.(type) {
+		case nil:
+			continue
+		case int, int64, uint, float64:
+			fmt.Fprintf(w.sheet, `<c r="%s"><v>%s</v></c>`, reference, cellText(value))
+		case bool:
+			flag := "0"
+			if value {
+				flag = "1"
+			}
+			fmt.Fprintf(w.sheet, `<c r="%s" t="b"><v>%s</v></c>`, reference, flag)
+		default:
+			fmt.Fprintf(w.sheet, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, reference, escapeXML(cellText(value)))
+		}
+	}
+	_, err := w.sheet.WriteString("</row>")
+	return err
+}
+
+func (w *xlsxWriter) Close() error {
+	if _, err := w.sheet.WriteString("</sheetData></worksheet>"); err != nil {
+		return err
+	}
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+	return w.archive.Close()
+}
+
+// escapeXML escapes text for use in XML, replacing characters XML cannot hold
+func escapeXML(text string) string {
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(text))
+	return escaped.String()
+}
+
+// columnName converts a zero-based column index to its letters, such as "AB" for 27
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}